@@ -8,6 +8,7 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"os"
 
@@ -17,8 +18,10 @@ import (
 
 func main() {
 	if err := cli.Execute(); err != nil {
-		output.Current.PrintError(err)
-		os.Exit(1)
+		if !errors.Is(err, cli.ErrSilentExit) {
+			output.Current.PrintError(cli.Annotate(err))
+		}
+		os.Exit(cli.ExitCode(err))
 	}
 	fmt.Println()
 }
@@ -0,0 +1,76 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ravi-technologies/sunday-cli/internal/config"
+)
+
+// EnvAPIKey supplies a long-lived API key, e.g. a service account's, so
+// automation can use the CLI without the interactive login. When set it
+// takes precedence over the stored login.
+const EnvAPIKey = "SUNDAY_API_KEY"
+
+// APIKeyFromEnv returns the API key from SUNDAY_API_KEY, or "" if none is
+// set.
+func APIKeyFromEnv() string {
+	return strings.TrimSpace(os.Getenv(EnvAPIKey))
+}
+
+// UsesAPIKey reports whether the client authenticates with an API key.
+func (c *Client) UsesAPIKey() bool {
+	return c.apiKey != ""
+}
+
+// newAPIKeyClient returns a client that authenticates with key. Only the
+// encryption keys are kept from cfg: the tokens and signing secret belong
+// to the stored login. Tokens obtained for the key stay in memory, so CI
+// runs never write credentials to disk.
+func newAPIKeyClient(baseURL string, cfg *config.Config, key string) *Client {
+	keyCfg := &config.Config{
+		PINSalt:    cfg.PINSalt,
+		PublicKey:  cfg.PublicKey,
+		PrivateKey: cfg.PrivateKey,
+		API:        cfg.API,
+		Security:   cfg.Security,
+	}
+	c := NewClientForURL(baseURL, keyCfg, nil)
+	c.apiKey = key
+	return c
+}
+
+// exchangeAPIKey trades the API key for a short-lived access token. The
+// caller must hold refreshMu.
+func (c *Client) exchangeAPIKey(ctx context.Context) error {
+	req := APIKeyTokenRequest{GrantType: "client_credentials", APIKey: c.apiKey}
+
+	resp, err := c.doRequestContext(ctx, http.MethodPost, PathAPIKeyToken, req, false)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var result APIKeyTokenResponse
+	if err := c.parseResponse(resp, &result); err != nil {
+		return err
+	}
+
+	// Renew a minute early, as refreshed login tokens are.
+	expiry := TokenExpiryBuffer
+	if result.ExpiresIn > 0 {
+		expiry = max(time.Duration(result.ExpiresIn)*time.Second-time.Minute, 0)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.config.AccessToken = result.Access
+	c.config.ExpiresAt = time.Now().Add(expiry)
+	if result.User.Email != "" {
+		c.config.UserEmail = result.User.Email
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ravi-technologies/sunday-cli/internal/config"
+	"github.com/ravi-technologies/sunday-cli/internal/version"
+)
+
+// TestAPIKey_ExchangeAndReuse verifies that SUNDAY_API_KEY is exchanged
+// for an access token that authenticates requests, that the stored login
+// is ignored, and that nothing is written to disk.
+func TestAPIKey_ExchangeAndReuse(t *testing.T) {
+	tmpDir, cleanup := withTempHome(t)
+	defer cleanup()
+
+	if err := config.Save(&config.Config{AccessToken: "stored", RefreshToken: "stored-refresh", PublicKey: "pub", PrivateKey: "priv"}); err != nil {
+		t.Fatalf("config.Save() error = %v", err)
+	}
+	configPath := filepath.Join(tmpDir, ".sunday", "config.json")
+	before, _ := os.ReadFile(configPath)
+
+	var exchanges atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case PathAPIKeyToken:
+			exchanges.Add(1)
+			var req APIKeyTokenRequest
+			json.NewDecoder(r.Body).Decode(&req)
+			if req.APIKey != "sk_test" || req.GrantType != "client_credentials" {
+				t.Errorf("exchange request = %+v", req)
+			}
+			w.Write([]byte(`{"access":"key-token","expires_in":3600,"user":{"email":"ci@example.com"}}`))
+		case PathIdentities:
+			if got := r.Header.Get("Authorization"); got != "Bearer key-token" {
+				t.Errorf("Authorization = %q, want Bearer key-token", got)
+			}
+			w.Write([]byte(`[]`))
+		default:
+			t.Errorf("unexpected request %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	orig := version.APIBaseURL
+	version.APIBaseURL = server.URL
+	defer func() { version.APIBaseURL = orig }()
+	t.Setenv(EnvAPIKey, "sk_test")
+
+	client, err := NewClient(nil)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	if !client.UsesAPIKey() || !client.IsAuthenticated() {
+		t.Error("client should be authenticated with the API key")
+	}
+	for range 2 {
+		if _, err := client.ListIdentities(); err != nil {
+			t.Fatalf("ListIdentities() error = %v", err)
+		}
+	}
+
+	if n := exchanges.Load(); n != 1 {
+		t.Errorf("API key exchanged %d times, want 1", n)
+	}
+	if got := client.GetUserEmail(); got != "ci@example.com" {
+		t.Errorf("GetUserEmail() = %q, want ci@example.com", got)
+	}
+	if got := client.currentConfig().PrivateKey; got != "priv" {
+		t.Errorf("PrivateKey = %q, want the stored key", got)
+	}
+	after, _ := os.ReadFile(configPath)
+	if string(after) != string(before) {
+		t.Error("config file changed during an API key session")
+	}
+}
+
+// TestAPIKey_ReexchangeOn401 verifies that a rejected access token is
+// replaced by exchanging the key again.
+func TestAPIKey_ReexchangeOn401(t *testing.T) {
+	_, cleanup := withTempHome(t)
+	defer cleanup()
+
+	var exchanges, calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case PathAPIKeyToken:
+			exchanges.Add(1)
+			w.Write([]byte(`{"access":"key-token","expires_in":3600}`))
+		default:
+			if calls.Add(1) == 1 {
+				w.WriteHeader(http.StatusUnauthorized)
+				w.Write([]byte(`{"detail":"token expired"}`))
+				return
+			}
+			w.Write([]byte(`[]`))
+		}
+	}))
+	defer server.Close()
+
+	orig := version.APIBaseURL
+	version.APIBaseURL = server.URL
+	defer func() { version.APIBaseURL = orig }()
+	t.Setenv(EnvAPIKey, "sk_test")
+
+	client, err := NewClient(nil)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	if _, err := client.ListIdentities(); err != nil {
+		t.Fatalf("ListIdentities() error = %v", err)
+	}
+	if n := exchanges.Load(); n != 2 {
+		t.Errorf("API key exchanged %d times, want 2", n)
+	}
+}
@@ -1,15 +1,32 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
+	"net/url"
 )
 
 // RequestDeviceCode initiates the device code flow
 func (c *Client) RequestDeviceCode() (*DeviceCodeResponse, error) {
-	resp, err := c.doRequest(http.MethodPost, PathDeviceCode, nil, false)
+	return c.RequestDeviceCodeWith(DeviceCodeRequest{})
+}
+
+// RequestDeviceCodeWith initiates the device code flow with options such
+// as an identity provider hint. An empty request sends no body.
+func (c *Client) RequestDeviceCodeWith(req DeviceCodeRequest) (*DeviceCodeResponse, error) {
+	return c.RequestDeviceCodeWithContext(context.Background(), req)
+}
+
+// RequestDeviceCodeWithContext is RequestDeviceCodeWith with a context
+// that cancels the request.
+func (c *Client) RequestDeviceCodeWithContext(ctx context.Context, req DeviceCodeRequest) (*DeviceCodeResponse, error) {
+	var body interface{}
+	if req != (DeviceCodeRequest{}) {
+		body = req
+	}
+	resp, err := c.doRequestContext(ctx, http.MethodPost, PathDeviceCode, body, false)
 	if err != nil {
 		return nil, err
 	}
@@ -27,17 +44,23 @@ func (c *Client) RequestDeviceCode() (*DeviceCodeResponse, error) {
 // Returns (token_response, error_code, error)
 // error_code is "authorization_pending" or "expired_token" on expected errors
 func (c *Client) PollForToken(deviceCode string) (*DeviceTokenResponse, string, error) {
+	return c.PollForTokenContext(context.Background(), deviceCode)
+}
+
+// PollForTokenContext is PollForToken with a context that cancels the
+// request.
+func (c *Client) PollForTokenContext(ctx context.Context, deviceCode string) (*DeviceTokenResponse, string, error) {
 	req := DeviceTokenRequest{DeviceCode: deviceCode}
 
-	resp, err := c.doRequest(http.MethodPost, PathDeviceToken, req, false)
+	resp, err := c.doRequestContext(ctx, http.MethodPost, PathDeviceToken, req, false)
 	if err != nil {
 		return nil, "", err
 	}
 	defer resp.Body.Close()
 
-	bodyBytes, err := io.ReadAll(resp.Body)
+	bodyBytes, err := c.readBody(resp)
 	if err != nil {
-		return nil, "", fmt.Errorf("failed to read response: %w", err)
+		return nil, "", err
 	}
 
 	// Check for error response (400 status)
@@ -60,3 +83,66 @@ func (c *Client) PollForToken(deviceCode string) (*DeviceTokenResponse, string,
 
 	return nil, "", fmt.Errorf("unexpected status code: %d", resp.StatusCode)
 }
+
+// AuthorizeURL returns the page that starts the browser login flow. The
+// server redirects to redirectURI with the authorization code and state.
+// A non-empty identityProvider pre-selects that SSO provider.
+func (c *Client) AuthorizeURL(redirectURI, state, codeChallenge, identityProvider string) string {
+	params := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {CLIClientID},
+		"redirect_uri":          {redirectURI},
+		"state":                 {state},
+		"code_challenge":        {codeChallenge},
+		"code_challenge_method": {"S256"},
+	}
+	if identityProvider != "" {
+		params.Set("idp", identityProvider)
+	}
+	return c.BuildURL(PathAuthorize, params)
+}
+
+// ExchangeAuthCode exchanges an authorization code from the browser login
+// flow, together with its PKCE verifier, for tokens.
+func (c *Client) ExchangeAuthCode(code, codeVerifier, redirectURI string) (*DeviceTokenResponse, error) {
+	return c.ExchangeAuthCodeContext(context.Background(), code, codeVerifier, redirectURI)
+}
+
+// ExchangeAuthCodeContext is ExchangeAuthCode with a context that cancels
+// the request.
+func (c *Client) ExchangeAuthCodeContext(ctx context.Context, code, codeVerifier, redirectURI string) (*DeviceTokenResponse, error) {
+	req := AuthCodeTokenRequest{
+		GrantType:    "authorization_code",
+		ClientID:     CLIClientID,
+		Code:         code,
+		CodeVerifier: codeVerifier,
+		RedirectURI:  redirectURI,
+	}
+
+	resp, err := c.doRequestContext(ctx, http.MethodPost, PathAuthCodeToken, req, false)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusBadRequest {
+		bodyBytes, err := c.readBody(resp)
+		if err != nil {
+			return nil, err
+		}
+		var tokenErr DeviceTokenError
+		if err := json.Unmarshal(bodyBytes, &tokenErr); err == nil && tokenErr.Error != "" {
+			if tokenErr.ErrorDescription != "" {
+				return nil, fmt.Errorf("exchanging authorization code: %s: %s", tokenErr.Error, tokenErr.ErrorDescription)
+			}
+			return nil, fmt.Errorf("exchanging authorization code: %s", tokenErr.Error)
+		}
+		return nil, fmt.Errorf("exchanging authorization code: %s", string(bodyBytes))
+	}
+
+	var result DeviceTokenResponse
+	if err := c.parseResponse(resp, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
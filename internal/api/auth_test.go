@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"strings"
 	"testing"
 
 	"github.com/ravi-technologies/sunday-cli/internal/config"
@@ -122,7 +124,7 @@ func TestRequestDeviceCode_Error(t *testing.T) {
 	}
 
 	// Error message should contain the API error detail
-	if err.Error() != "API error: Internal server error" {
+	if !strings.HasPrefix(err.Error(), "API error: Internal server error (request ID ") {
 		t.Errorf("Error message = %q, want to contain 'Internal server error'", err.Error())
 	}
 }
@@ -346,3 +348,123 @@ func TestPollForToken_InvalidCode(t *testing.T) {
 		t.Errorf("PollForToken() errorCode = %q, want %q", errorCode, "invalid_grant")
 	}
 }
+
+// TestAuthorizeURL verifies that the browser login URL carries the PKCE
+// challenge, state and redirect URI.
+func TestAuthorizeURL(t *testing.T) {
+	client := setupTestClient(t, "https://sunday.example")
+
+	u, err := url.Parse(client.AuthorizeURL("http://127.0.0.1:5555/callback", "st", "ch", ""))
+	if err != nil {
+		t.Fatalf("AuthorizeURL() is not a URL: %v", err)
+	}
+	if u.Path != PathAuthorize {
+		t.Errorf("path = %q, want %q", u.Path, PathAuthorize)
+	}
+	q := u.Query()
+	want := map[string]string{
+		"response_type":         "code",
+		"client_id":             CLIClientID,
+		"redirect_uri":          "http://127.0.0.1:5555/callback",
+		"state":                 "st",
+		"code_challenge":        "ch",
+		"code_challenge_method": "S256",
+	}
+	for k, v := range want {
+		if got := q.Get(k); got != v {
+			t.Errorf("%s = %q, want %q", k, got, v)
+		}
+	}
+}
+
+// TestAuthorizeURL_IdentityProvider verifies that an identity provider
+// hint is passed as the idp parameter, and omitted when empty.
+func TestAuthorizeURL_IdentityProvider(t *testing.T) {
+	client := setupTestClient(t, "https://sunday.example")
+
+	u, err := url.Parse(client.AuthorizeURL("http://127.0.0.1:5555/callback", "st", "ch", "okta"))
+	if err != nil {
+		t.Fatalf("AuthorizeURL() is not a URL: %v", err)
+	}
+	if got := u.Query().Get("idp"); got != "okta" {
+		t.Errorf("idp = %q, want %q", got, "okta")
+	}
+
+	u, _ = url.Parse(client.AuthorizeURL("http://127.0.0.1:5555/callback", "st", "ch", ""))
+	if u.Query().Has("idp") {
+		t.Errorf("idp present without a provider: %s", u)
+	}
+}
+
+// TestRequestDeviceCodeWith_IdentityProvider verifies that the identity
+// provider hint is sent in the request body.
+func TestRequestDeviceCodeWith_IdentityProvider(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req DeviceCodeRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("decoding request: %v", err)
+		}
+		if req.IdentityProvider != "okta" {
+			t.Errorf("IdentityProvider = %q, want %q", req.IdentityProvider, "okta")
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(DeviceCodeResponse{DeviceCode: "dc", UserCode: "UC"})
+	}))
+	defer server.Close()
+
+	client := setupTestClient(t, server.URL)
+
+	result, err := client.RequestDeviceCodeWith(DeviceCodeRequest{IdentityProvider: "okta"})
+	if err != nil {
+		t.Fatalf("RequestDeviceCodeWith() unexpected error: %v", err)
+	}
+	if result.DeviceCode != "dc" {
+		t.Errorf("DeviceCode = %q, want %q", result.DeviceCode, "dc")
+	}
+}
+
+// TestExchangeAuthCode_Success verifies that the code and verifier are
+// posted and the tokens returned.
+func TestExchangeAuthCode_Success(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != PathAuthCodeToken {
+			t.Errorf("Expected path %s, got %s", PathAuthCodeToken, r.URL.Path)
+		}
+		var req AuthCodeTokenRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("decoding request: %v", err)
+		}
+		if req.GrantType != "authorization_code" || req.Code != "code-1" || req.CodeVerifier != "verifier" || req.RedirectURI != "http://127.0.0.1/cb" {
+			t.Errorf("request = %+v", req)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(DeviceTokenResponse{Access: "a", Refresh: "r", User: User{Email: "me@example.com"}})
+	}))
+	defer server.Close()
+
+	client := setupTestClient(t, server.URL)
+	resp, err := client.ExchangeAuthCode("code-1", "verifier", "http://127.0.0.1/cb")
+	if err != nil {
+		t.Fatalf("ExchangeAuthCode() error = %v", err)
+	}
+	if resp.Access != "a" || resp.Refresh != "r" || resp.User.Email != "me@example.com" {
+		t.Errorf("ExchangeAuthCode() = %+v", resp)
+	}
+}
+
+// TestExchangeAuthCode_InvalidGrant verifies that an OAuth error response
+// is reported with its description.
+func TestExchangeAuthCode_InvalidGrant(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error":"invalid_grant","error_description":"code verifier mismatch"}`))
+	}))
+	defer server.Close()
+
+	client := setupTestClient(t, server.URL)
+	_, err := client.ExchangeAuthCode("code-1", "verifier", "http://127.0.0.1/cb")
+	if err == nil || !strings.Contains(err.Error(), "code verifier mismatch") {
+		t.Errorf("ExchangeAuthCode() error = %v, want invalid_grant description", err)
+	}
+}
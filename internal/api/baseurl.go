@@ -0,0 +1,46 @@
+package api
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+
+	"github.com/ravi-technologies/sunday-cli/internal/config"
+	"github.com/ravi-technologies/sunday-cli/internal/version"
+)
+
+// EnvAPIURL names the environment variable pointing the CLI at another
+// API, such as staging, without rebuilding it.
+const EnvAPIURL = "SUNDAY_API_URL"
+
+// APIURL overrides the API base URL. The CLI sets it for --api-url.
+var APIURL string
+
+// BaseURL returns the API base URL for clients configured by cfg: APIURL,
+// $SUNDAY_API_URL, the api.base_url setting or the URL built in, in that
+// order. cfg may be nil.
+func BaseURL(cfg *config.Config) (string, error) {
+	candidates := []struct{ source, value string }{
+		{"--api-url", APIURL},
+		{EnvAPIURL, os.Getenv(EnvAPIURL)},
+	}
+	if cfg != nil {
+		candidates = append(candidates, struct{ source, value string }{"api.base_url", cfg.API.BaseURL})
+	}
+	for _, c := range candidates {
+		if c.value == "" {
+			continue
+		}
+		u, err := url.Parse(c.value)
+		if err != nil || u.Scheme != "https" && u.Scheme != "http" || u.Host == "" {
+			return "", fmt.Errorf("invalid %s %q: want an http or https URL such as https://api.sunday.app", c.source, c.value)
+		}
+		return c.value, nil
+	}
+
+	baseURL, err := version.GetAPIBaseURL()
+	if err != nil {
+		return "", fmt.Errorf("%w, or set %s or api.base_url", err, EnvAPIURL)
+	}
+	return baseURL, nil
+}
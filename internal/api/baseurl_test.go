@@ -0,0 +1,60 @@
+package api
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ravi-technologies/sunday-cli/internal/config"
+)
+
+// TestBaseURL verifies the precedence of the ways to choose the API:
+// --api-url, then $SUNDAY_API_URL, then api.base_url, then the URL built in.
+func TestBaseURL(t *testing.T) {
+	defer withAPIBaseURL(t, "https://built-in.example")()
+	cfg := &config.Config{API: config.APISettings{BaseURL: "https://config.example"}}
+
+	check := func(want string) {
+		t.Helper()
+		got, err := BaseURL(cfg)
+		if err != nil || got != want {
+			t.Errorf("BaseURL() = %q, %v; want %q", got, err, want)
+		}
+	}
+
+	APIURL = "https://flag.example"
+	t.Cleanup(func() { APIURL = "" })
+	t.Setenv(EnvAPIURL, "https://env.example")
+	check("https://flag.example")
+
+	APIURL = ""
+	check("https://env.example")
+
+	t.Setenv(EnvAPIURL, "")
+	check("https://config.example")
+
+	cfg.API.BaseURL = ""
+	check("https://built-in.example")
+}
+
+// TestBaseURL_Invalid verifies that a malformed override is rejected,
+// naming where it came from, rather than falling through to the next.
+func TestBaseURL_Invalid(t *testing.T) {
+	defer withAPIBaseURL(t, "https://built-in.example")()
+	t.Setenv(EnvAPIURL, "staging.example")
+
+	_, err := BaseURL(nil)
+	if err == nil || !strings.Contains(err.Error(), "invalid "+EnvAPIURL) {
+		t.Errorf("BaseURL() error = %v, want invalid %s", err, EnvAPIURL)
+	}
+}
+
+// TestBaseURL_Unset verifies the error when no URL is configured anywhere.
+func TestBaseURL_Unset(t *testing.T) {
+	defer withAPIBaseURL(t, "")()
+	t.Setenv(EnvAPIURL, "")
+
+	_, err := BaseURL(&config.Config{})
+	if err == nil || !strings.Contains(err.Error(), EnvAPIURL) {
+		t.Errorf("BaseURL() error = %v, want a mention of %s", err, EnvAPIURL)
+	}
+}
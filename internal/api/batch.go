@@ -0,0 +1,100 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// BatchError reports the items of a batch fetch that failed. The items
+// that succeeded are still returned alongside it.
+type BatchError struct {
+	// Total is how many items were requested.
+	Total int
+
+	// Failed maps the ID of each failed item to its error.
+	Failed map[string]error
+}
+
+func (e *BatchError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d of %d could not be fetched", len(e.Failed), e.Total)
+	for _, id := range slices.Sorted(maps.Keys(e.Failed)) {
+		fmt.Fprintf(&b, "\n  %s: %v", id, e.Failed[id])
+	}
+	return b.String()
+}
+
+// Unwrap returns the individual errors, so errors.Is and errors.As see
+// through a BatchError.
+func (e *BatchError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Failed))
+	for _, err := range e.Failed {
+		errs = append(errs, err)
+	}
+	return errs
+}
+
+// fetchBatch calls fetch for every ID with at most BatchConcurrency calls
+// in flight. The results are in the order of ids, with nil for the IDs
+// that failed; if any did, the error is a *BatchError.
+func fetchBatch[T any](ctx context.Context, ids []string, fetch func(context.Context, string) (*T, error)) ([]*T, error) {
+	results := make([]*T, len(ids))
+	errs := make([]error, len(ids))
+
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for range min(BatchConcurrency, len(ids)) {
+		wg.Go(func() {
+			for i := range next {
+				results[i], errs[i] = fetch(ctx, ids[i])
+			}
+		})
+	}
+	for i := range ids {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+
+	batchErr := &BatchError{Total: len(ids), Failed: map[string]error{}}
+	for i, err := range errs {
+		if err != nil {
+			batchErr.Failed[ids[i]] = err
+		}
+	}
+	if len(batchErr.Failed) > 0 {
+		return results, batchErr
+	}
+	return results, nil
+}
+
+// GetEmailThreads fetches several email threads at once. See
+// GetEmailThreadsContext.
+func (c *Client) GetEmailThreads(threadIDs []string) ([]*EmailThreadDetail, error) {
+	return c.GetEmailThreadsContext(context.Background(), threadIDs)
+}
+
+// GetEmailThreadsContext fetches several email threads concurrently. The
+// threads are in the order of threadIDs, with nil for any that couldn't
+// be fetched; if there are some, the error is a *BatchError listing them.
+func (c *Client) GetEmailThreadsContext(ctx context.Context, threadIDs []string) ([]*EmailThreadDetail, error) {
+	return fetchBatch(ctx, threadIDs, c.GetEmailThreadContext)
+}
+
+// GetSMSConversations fetches several SMS conversations at once. See
+// GetSMSConversationsContext.
+func (c *Client) GetSMSConversations(conversationIDs []string) ([]*SMSConversationDetail, error) {
+	return c.GetSMSConversationsContext(context.Background(), conversationIDs)
+}
+
+// GetSMSConversationsContext fetches several SMS conversations
+// concurrently. The conversations are in the order of conversationIDs,
+// with nil for any that couldn't be fetched; if there are some, the error
+// is a *BatchError listing them.
+func (c *Client) GetSMSConversationsContext(ctx context.Context, conversationIDs []string) ([]*SMSConversationDetail, error) {
+	return fetchBatch(ctx, conversationIDs, c.GetSMSConversationContext)
+}
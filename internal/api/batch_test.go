@@ -0,0 +1,89 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestGetEmailThreads verifies that a batch fetch returns threads in the
+// order asked for and reports the ones that failed.
+func TestGetEmailThreads(t *testing.T) {
+	var inFlight, maxInFlight atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			m := maxInFlight.Load()
+			if n <= m || maxInFlight.CompareAndSwap(m, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+
+		id := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, PathEmailInbox), "/")
+		if id == "missing" {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"detail":"Not found."}`))
+			return
+		}
+		w.Write([]byte(`{"thread_id":"` + id + `"}`))
+	}))
+	defer server.Close()
+
+	ids := []string{"t1", "missing", "t3"}
+	for i := range 2 * BatchConcurrency {
+		ids = append(ids, "extra"+string(rune('a'+i)))
+	}
+
+	threads, err := newTestClient(server.URL).GetEmailThreads(ids)
+	var batchErr *BatchError
+	if !errors.As(err, &batchErr) {
+		t.Fatalf("GetEmailThreads() error = %v, want a *BatchError", err)
+	}
+	if batchErr.Total != len(ids) || len(batchErr.Failed) != 1 || batchErr.Failed["missing"] == nil {
+		t.Errorf("BatchError = %+v, want only missing failed", batchErr)
+	}
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound {
+		t.Errorf("errors.As(APIError) = %v, want the 404", apiErr)
+	}
+
+	if len(threads) != len(ids) {
+		t.Fatalf("got %d results, want %d", len(threads), len(ids))
+	}
+	for i, id := range ids {
+		if id == "missing" {
+			if threads[i] != nil {
+				t.Errorf("result %d = %+v, want nil", i, threads[i])
+			}
+		} else if threads[i] == nil || threads[i].ThreadID != id {
+			t.Errorf("result %d = %+v, want thread %s", i, threads[i], id)
+		}
+	}
+	if maxInFlight.Load() > BatchConcurrency {
+		t.Errorf("%d requests in flight, want at most %d", maxInFlight.Load(), BatchConcurrency)
+	}
+}
+
+// TestGetSMSConversations_AllSucceed verifies that a batch with no
+// failures returns no error.
+func TestGetSMSConversations_AllSucceed(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"conversation_id":"c"}`))
+	}))
+	defer server.Close()
+
+	conversations, err := newTestClient(server.URL).GetSMSConversationsContext(context.Background(), []string{"1_+15551234567", "1_+15557654321"})
+	if err != nil {
+		t.Fatalf("GetSMSConversationsContext() error = %v", err)
+	}
+	if len(conversations) != 2 || conversations[0] == nil || conversations[1] == nil {
+		t.Errorf("conversations = %v, want two", conversations)
+	}
+}
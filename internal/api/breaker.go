@@ -0,0 +1,86 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned (wrapped) when the circuit breaker is refusing
+// requests because the backend has failed repeatedly.
+var ErrCircuitOpen = errors.New("backend unavailable")
+
+// circuitBreaker short-circuits requests after a run of consecutive backend
+// failures (network errors, timeouts or 5xx responses) so long-running modes
+// don't hammer a struggling server. Once the cool-down elapses the breaker
+// half-opens: a single trial request goes through while others keep failing
+// fast, and its outcome either closes the circuit or opens it for another
+// cool-down.
+type circuitBreaker struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	failures  int
+	openUntil time.Time
+	halfOpen  bool
+	now       func() time.Time
+}
+
+// newCircuitBreaker creates a breaker that opens after threshold consecutive
+// failures and stays open for cooldown.
+func newCircuitBreaker(threshold int, cooldown time.Duration) *circuitBreaker {
+	return &circuitBreaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+		now:       time.Now,
+	}
+}
+
+// allow returns an error wrapping ErrCircuitOpen if requests are currently
+// being short-circuited. A nil breaker always allows requests.
+func (b *circuitBreaker) allow() error {
+	if b == nil {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.openUntil.IsZero() {
+		return nil
+	}
+	if b.now().Before(b.openUntil) {
+		return fmt.Errorf("%w after %d consecutive failures, retrying at %s",
+			ErrCircuitOpen, b.failures, b.openUntil.Format("15:04:05"))
+	}
+
+	// Cool-down elapsed: let this request through as the trial. The others
+	// wait another cool-down, which also re-arms the trial should this one
+	// never report back (say, because its context was cancelled).
+	b.halfOpen = true
+	b.openUntil = b.now().Add(b.cooldown)
+	return nil
+}
+
+// record updates the breaker with the outcome of a request.
+func (b *circuitBreaker) record(resp *http.Response, err error) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if err == nil && resp.StatusCode < http.StatusInternalServerError {
+		b.failures = 0
+		b.openUntil = time.Time{}
+		b.halfOpen = false
+		return
+	}
+
+	b.failures++
+	if b.halfOpen || b.failures >= b.threshold {
+		b.openUntil = b.now().Add(b.cooldown)
+		b.halfOpen = false
+	}
+}
@@ -0,0 +1,146 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestCircuitBreaker_OpensAfterThreshold verifies that the breaker refuses
+// requests once the failure threshold is reached.
+func TestCircuitBreaker_OpensAfterThreshold(t *testing.T) {
+	b := newCircuitBreaker(3, time.Minute)
+	failed := &http.Response{StatusCode: http.StatusBadGateway}
+
+	for i := 0; i < 2; i++ {
+		b.record(failed, nil)
+		if err := b.allow(); err != nil {
+			t.Fatalf("allow() after %d failures error = %v, want nil", i+1, err)
+		}
+	}
+
+	b.record(failed, nil)
+	err := b.allow()
+	if !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("allow() error = %v, want ErrCircuitOpen", err)
+	}
+}
+
+// TestCircuitBreaker_SuccessResetsCount verifies that a successful response
+// resets the consecutive failure count.
+func TestCircuitBreaker_SuccessResetsCount(t *testing.T) {
+	b := newCircuitBreaker(2, time.Minute)
+
+	b.record(nil, errors.New("connection refused"))
+	b.record(&http.Response{StatusCode: http.StatusNotFound}, nil)
+	b.record(nil, errors.New("connection refused"))
+
+	if err := b.allow(); err != nil {
+		t.Errorf("allow() error = %v, want nil (4xx should reset the count)", err)
+	}
+}
+
+// TestCircuitBreaker_ClosesAfterCooldown verifies that requests are allowed
+// again once the cool-down period has elapsed.
+func TestCircuitBreaker_ClosesAfterCooldown(t *testing.T) {
+	now := time.Now()
+	b := newCircuitBreaker(1, 30*time.Second)
+	b.now = func() time.Time { return now }
+
+	b.record(&http.Response{StatusCode: http.StatusServiceUnavailable}, nil)
+	if err := b.allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("allow() error = %v, want ErrCircuitOpen", err)
+	}
+
+	now = now.Add(31 * time.Second)
+	if err := b.allow(); err != nil {
+		t.Errorf("allow() after cool-down error = %v, want nil", err)
+	}
+}
+
+// TestCircuitBreaker_HalfOpen verifies that after the cool-down only one
+// trial request goes through, and that its outcome decides whether the
+// circuit closes or opens again straight away.
+func TestCircuitBreaker_HalfOpen(t *testing.T) {
+	now := time.Now()
+	b := newCircuitBreaker(3, 30*time.Second)
+	b.now = func() time.Time { return now }
+	failed := &http.Response{StatusCode: http.StatusServiceUnavailable}
+
+	for range 3 {
+		b.record(failed, nil)
+	}
+	now = now.Add(31 * time.Second)
+	if err := b.allow(); err != nil {
+		t.Fatalf("trial allow() error = %v, want nil", err)
+	}
+	if err := b.allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("allow() during trial error = %v, want ErrCircuitOpen", err)
+	}
+
+	// A failed trial reopens the circuit without waiting for the threshold.
+	b.record(nil, errors.New("timeout"))
+	now = now.Add(time.Second)
+	err := b.allow()
+	if !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("allow() after failed trial error = %v, want ErrCircuitOpen", err)
+	}
+	want := now.Add(29 * time.Second).Format("15:04:05")
+	if !strings.Contains(err.Error(), "retrying at "+want) {
+		t.Errorf("allow() error = %q, want it to mention retrying at %s", err, want)
+	}
+
+	// A successful trial closes it.
+	now = now.Add(30 * time.Second)
+	if err := b.allow(); err != nil {
+		t.Fatalf("second trial allow() error = %v, want nil", err)
+	}
+	b.record(&http.Response{StatusCode: http.StatusOK}, nil)
+	for range 2 {
+		if err := b.allow(); err != nil {
+			t.Errorf("allow() after successful trial error = %v, want nil", err)
+		}
+	}
+}
+
+// TestCircuitBreaker_Nil verifies that a nil breaker is a no-op.
+func TestCircuitBreaker_Nil(t *testing.T) {
+	var b *circuitBreaker
+	b.record(nil, errors.New("boom"))
+	if err := b.allow(); err != nil {
+		t.Errorf("nil breaker allow() error = %v, want nil", err)
+	}
+}
+
+// TestDoRequest_CircuitBreakerShortCircuits verifies that doRequest stops
+// contacting the server once the breaker has opened.
+func TestDoRequest_CircuitBreakerShortCircuits(t *testing.T) {
+	hits := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	client := newTestClient(server.URL)
+	client.breaker = newCircuitBreaker(2, time.Minute)
+
+	for i := 0; i < 2; i++ {
+		resp, err := client.doRequest(http.MethodGet, "/test", nil, false)
+		if err != nil {
+			t.Fatalf("doRequest() #%d error = %v", i+1, err)
+		}
+		resp.Body.Close()
+	}
+
+	_, err := client.doRequest(http.MethodGet, "/test", nil, false)
+	if !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("doRequest() error = %v, want ErrCircuitOpen", err)
+	}
+	if hits != 2 {
+		t.Errorf("server hits = %d, want 2", hits)
+	}
+}
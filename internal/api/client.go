@@ -2,94 +2,282 @@ package api
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/ravi-technologies/sunday-cli/internal/config"
+	"github.com/ravi-technologies/sunday-cli/internal/timing"
 	"github.com/ravi-technologies/sunday-cli/internal/version"
 )
 
+// DefaultTransport is the RoundTripper used by clients created with NewClient.
+// The CLI replaces it to layer in process-wide instrumentation such as HAR
+// capture. Requests still reach the network through the client's own
+// transport if it has one (see baseTransport).
+var DefaultTransport http.RoundTripper = baseTransport{}
+
+// DisableCache makes clients bypass local caches and ask intermediaries for
+// fresh responses. The CLI sets it for --no-cache; any cache added to the
+// client must check it.
+var DisableCache bool
+
 type Client struct {
 	httpClient *http.Client
 	baseURL    string
-	config     *config.Config
+	breaker    *circuitBreaker
+
+	// mu guards config, which a background token refresh may replace
+	// while requests are in flight, and rateLimit. refreshMu serializes
+	// refreshes.
+	mu        sync.RWMutex
+	config    *config.Config
+	rateLimit *RateLimit
+	refreshMu sync.Mutex
+
+	// requestHook is told about each request sent (see OnRequestDone).
+	// It is guarded by mu.
+	requestHook RequestHook
+
+	// serverInfo is the server's description, fetched once by
+	// GetServerInfo. It is guarded by mu.
+	serverInfo *ServerInfo
+
+	// flightMu guards refreshing, the refresh that goroutines finding
+	// the access token stale are waiting for (see refreshStale).
+	flightMu   sync.Mutex
+	refreshing *refreshCall
+
+	// saveConfig persists the config after a token refresh. Nil means
+	// config.Save (write to ~/.sunday/config.json).
+	saveConfig func(*config.Config) error
+
+	// watcher reloads the config when another process rewrites it. It is
+	// only set for clients whose config was loaded from disk.
+	watcher *config.Watcher
+
+	// apiKey is the SUNDAY_API_KEY the client authenticates with instead
+	// of the stored login, if any.
+	apiKey string
+
+	// transport sends the client's requests when mutual TLS, a custom CA
+	// or a proxy is configured. Nil means http.DefaultTransport.
+	transport http.RoundTripper
+
+	// retry says how to retry transient failures. The zero value doesn't.
+	retry retryPolicy
+
+	// cache makes GET requests conditional on a cached copy. It is only
+	// set for clients created with NewClient, when the config holds a key
+	// to encrypt it with.
+	cache *httpCache
 }
 
 // NewClient creates a new API client. If cfg is nil, attempts to load from disk.
 func NewClient(cfg *config.Config) (*Client, error) {
-	baseURL, err := version.GetAPIBaseURL()
-	if err != nil {
-		return nil, err
-	}
-
+	var watcher *config.Watcher
+	var apiKey string
 	if cfg == nil {
+		var err error
 		cfg, err = config.Load()
 		if err != nil {
 			return nil, fmt.Errorf("failed to load config: %w", err)
 		}
+		apiKey = APIKeyFromEnv()
+		if apiKey == "" {
+			watcher = config.NewWatcher()
+		}
+	}
+
+	baseURL, err := BaseURL(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	transport, err := newTransport(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	cache := newHTTPCache(cfg)
+	if apiKey != "" {
+		c := newAPIKeyClient(baseURL, cfg, apiKey)
+		c.transport = transport
+		c.cache = cache
+		return c, nil
 	}
 
 	return &Client{
-		httpClient: &http.Client{Timeout: 30 * time.Second},
+		httpClient: &http.Client{Timeout: DefaultTimeout, Transport: DefaultTransport},
 		baseURL:    strings.TrimSuffix(baseURL, "/"),
 		config:     cfg,
+		breaker:    newCircuitBreaker(CircuitBreakerThreshold, CircuitBreakerCooldown),
+		watcher:    watcher,
+		transport:  transport,
+		retry:      currentRetryPolicy(),
+		cache:      cache,
 	}, nil
 }
 
+// NewClientForURL creates an API client for an explicit base URL and
+// in-memory credentials, bypassing the build-time URL. It never touches the
+// config file: after a token refresh the updated config is passed to save,
+// which may be nil to keep refreshed tokens in memory only.
+func NewClientForURL(baseURL string, cfg *config.Config, save func(*config.Config) error) *Client {
+	if cfg == nil {
+		cfg = &config.Config{}
+	}
+	if save == nil {
+		save = func(*config.Config) error { return nil }
+	}
+
+	return &Client{
+		httpClient: &http.Client{Timeout: DefaultTimeout, Transport: DefaultTransport},
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		config:     cfg,
+		breaker:    newCircuitBreaker(CircuitBreakerThreshold, CircuitBreakerCooldown),
+		saveConfig: save,
+		retry:      currentRetryPolicy(),
+	}
+}
+
 // doRequest performs an HTTP request with optional authentication
 func (c *Client) doRequest(method, path string, body interface{}, auth bool) (*http.Response, error) {
+	return c.doRequestContext(context.Background(), method, path, body, auth)
+}
+
+// doRequestContext is doRequest with a context that cancels the request.
+// Transient failures of idempotent requests are retried with backoff, and
+// any request refused with 429 is retried once the server allows.
+func (c *Client) doRequestContext(ctx context.Context, method, path string, body interface{}, auth bool) (*http.Response, error) {
 	fullURL := c.baseURL + path
 
-	var bodyReader io.Reader
+	var jsonBody []byte
 	if body != nil {
-		jsonBody, err := json.Marshal(body)
+		var err error
+		jsonBody, err = json.Marshal(body)
 		if err != nil {
 			return nil, fmt.Errorf("failed to marshal request body: %w", err)
 		}
+	}
+
+	// Retries are the same request, so they keep its ID.
+	requestID := newRequestID()
+	for attempt := 0; ; attempt++ {
+		resp, err := c.send(ctx, method, fullURL, jsonBody, auth, requestID)
+		c.observeRateLimit(resp)
+		if attempt >= c.retry.max || ctx.Err() != nil {
+			return resp, err
+		}
+		wait, ok := c.retryWait(method, resp, err, attempt)
+		if !ok {
+			return resp, err
+		}
+		discard(resp)
+		if err := sleep(ctx, wait); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// send makes one attempt at a request. jsonBody is nil for a request
+// without a body.
+func (c *Client) send(ctx context.Context, method, fullURL string, jsonBody []byte, auth bool, requestID string) (*http.Response, error) {
+	req, err := c.newRequest(ctx, method, fullURL, jsonBody, auth, requestID)
+	if err != nil {
+		return nil, err
+	}
+
+	cacheFile := c.cacheFile(method, fullURL)
+	if Offline {
+		return c.cache.offline(req, cacheFile)
+	}
+	cached := c.cache.load(cacheFile)
+	cached.addConditions(req)
+
+	hc, err := c.httpClientFor(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.breaker.allow(); err != nil {
+		return nil, err
+	}
+
+	resp, err := c.do(hc, req)
+	if ctx.Err() == nil {
+		// A cancelled request says nothing about the server's health.
+		c.breaker.record(resp, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return c.cache.update(cacheFile, cached, gunzip(resp), c.maxResponseBytes()), nil
+}
+
+// newRequest builds a request to the API with the headers every request
+// carries: content negotiation, the User-Agent, the request ID, the
+// access token if auth is set, and the signature if request signing is on.
+func (c *Client) newRequest(ctx context.Context, method, fullURL string, jsonBody []byte, auth bool, requestID string) (*http.Request, error) {
+	var bodyReader io.Reader
+	if jsonBody != nil {
 		bodyReader = bytes.NewReader(jsonBody)
 	}
 
-	req, err := http.NewRequest(method, fullURL, bodyReader)
+	req, err := http.NewRequestWithContext(c.withTransport(ctx), method, fullURL, bodyReader)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Accept", "application/json")
-
-	if auth && c.config.AccessToken != "" {
-		req.Header.Set("Authorization", "Bearer "+c.config.AccessToken)
+	req.Header.Set("Accept-Encoding", "gzip")
+	req.Header.Set("User-Agent", version.UserAgent())
+	req.Header.Set(HeaderRequestID, requestID)
+	if DisableCache {
+		req.Header.Set("Cache-Control", "no-cache")
 	}
 
-	return c.httpClient.Do(req)
+	cfg := c.currentConfig()
+	if auth && cfg.AccessToken != "" {
+		req.Header.Set("Authorization", "Bearer "+cfg.AccessToken)
+	}
+	if cfg.SigningSecret != "" {
+		signRequest(req, jsonBody, cfg.SigningSecret, time.Now())
+	}
+	return req, nil
 }
 
 // doAuthenticatedRequest performs a request with authentication and auto token refresh
 func (c *Client) doAuthenticatedRequest(method, path string, body interface{}, result interface{}) error {
-	// Check if token is expired and refresh if needed
-	if time.Now().After(c.config.ExpiresAt) && c.config.RefreshToken != "" {
-		if err := c.RefreshAccessToken(); err != nil {
-			return fmt.Errorf("token refresh failed: %w", err)
-		}
+	return c.doAuthenticatedRequestContext(context.Background(), method, path, body, result)
+}
+
+// doAuthenticatedRequestContext is doAuthenticatedRequest with a context
+// that cancels the request and any token refresh it needs.
+func (c *Client) doAuthenticatedRequestContext(ctx context.Context, method, path string, body interface{}, result interface{}) error {
+	if err := c.ensureFreshToken(ctx); err != nil {
+		return err
 	}
 
-	resp, err := c.doRequest(method, path, body, true)
+	token := c.currentConfig().AccessToken
+	resp, err := c.doRequestContext(ctx, method, path, body, true)
 	if err != nil {
 		return err
 	}
 	defer resp.Body.Close()
 
 	// If 401, try to refresh token and retry once
-	if resp.StatusCode == http.StatusUnauthorized && c.config.RefreshToken != "" {
-		if err := c.RefreshAccessToken(); err != nil {
+	if resp.StatusCode == http.StatusUnauthorized && c.canRefresh(c.currentConfig()) {
+		if err := c.refreshStale(ctx, token); err != nil {
 			return fmt.Errorf("authentication failed: %w", err)
 		}
-		resp, err = c.doRequest(method, path, body, true)
+		resp, err = c.doRequestContext(ctx, method, path, body, true)
 		if err != nil {
 			return err
 		}
@@ -99,22 +287,62 @@ func (c *Client) doAuthenticatedRequest(method, path string, body interface{}, r
 	return c.parseResponse(resp, result)
 }
 
+// ensureFreshToken picks up tokens saved by other processes and refreshes
+// the access token if it has expired.
+func (c *Client) ensureFreshToken(ctx context.Context) error {
+	c.reloadConfig()
+
+	// Check if token is expired and refresh if needed. Offline, cached
+	// responses are served whatever the token's age.
+	if cfg := c.currentConfig(); !Offline && time.Now().After(cfg.ExpiresAt) && c.canRefresh(cfg) {
+		if err := c.refreshStale(ctx, cfg.AccessToken); err != nil {
+			return fmt.Errorf("token refresh failed: %w", err)
+		}
+	}
+	return nil
+}
+
+// AccessToken returns a current access token for calling the API
+// directly, refreshing it first if it has expired or forceRefresh is set.
+func (c *Client) AccessToken(forceRefresh bool) (string, error) {
+	return c.AccessTokenContext(context.Background(), forceRefresh)
+}
+
+// AccessTokenContext is AccessToken with a context that cancels the request.
+func (c *Client) AccessTokenContext(ctx context.Context, forceRefresh bool) (string, error) {
+	if forceRefresh && c.canRefresh(c.currentConfig()) {
+		if err := c.RefreshAccessTokenContext(ctx); err != nil {
+			return "", fmt.Errorf("token refresh failed: %w", err)
+		}
+	} else if err := c.ensureFreshToken(ctx); err != nil {
+		return "", err
+	}
+	return c.currentConfig().AccessToken, nil
+}
+
 // parseResponse parses the HTTP response into the result struct
 func (c *Client) parseResponse(resp *http.Response, result interface{}) error {
-	bodyBytes, err := io.ReadAll(resp.Body)
+	bodyBytes, err := c.readBody(resp)
 	if err != nil {
-		return fmt.Errorf("failed to read response: %w", err)
+		return err
 	}
 
 	if resp.StatusCode >= 400 {
-		var apiErr Error
-		if json.Unmarshal(bodyBytes, &apiErr) == nil && apiErr.Detail != "" {
-			return fmt.Errorf("API error: %s", apiErr.Detail)
+		apiErr := &APIError{StatusCode: resp.StatusCode, Body: string(bodyBytes), RequestID: responseRequestID(resp)}
+		if resp.StatusCode == http.StatusTooManyRequests {
+			apiErr.RetryAfter, _ = retryAfter(resp.Header, time.Now())
 		}
-		return fmt.Errorf("API error (status %d): %s", resp.StatusCode, string(bodyBytes))
+		var detail Error
+		if json.Unmarshal(bodyBytes, &detail) == nil {
+			apiErr.Detail, apiErr.Code = detail.Detail, detail.Code
+		}
+		return apiErr
 	}
 
 	if result != nil && len(bodyBytes) > 0 {
+		if err := checkJSONDepth(bodyBytes, MaxJSONDepth); err != nil {
+			return err
+		}
 		if err := json.Unmarshal(bodyBytes, result); err != nil {
 			return fmt.Errorf("failed to parse response: %w", err)
 		}
@@ -125,9 +353,23 @@ func (c *Client) parseResponse(resp *http.Response, result interface{}) error {
 
 // RefreshAccessToken refreshes the access token using the refresh token
 func (c *Client) RefreshAccessToken() error {
-	req := RefreshRequest{Refresh: c.config.RefreshToken}
+	return c.RefreshAccessTokenContext(context.Background())
+}
+
+// RefreshAccessTokenContext is RefreshAccessToken with a context that
+// cancels the request.
+func (c *Client) RefreshAccessTokenContext(ctx context.Context) error {
+	c.refreshMu.Lock()
+	defer c.refreshMu.Unlock()
+	defer timing.Start("auth refresh")()
+
+	if c.apiKey != "" {
+		return c.exchangeAPIKey(ctx)
+	}
+
+	req := RefreshRequest{Refresh: c.currentConfig().RefreshToken}
 
-	resp, err := c.doRequest(http.MethodPost, PathTokenRefresh, req, false)
+	resp, err := c.doRequestContext(ctx, http.MethodPost, PathTokenRefresh, req, false)
 	if err != nil {
 		return err
 	}
@@ -135,31 +377,134 @@ func (c *Client) RefreshAccessToken() error {
 
 	var result RefreshResponse
 	if err := c.parseResponse(resp, &result); err != nil {
+		if isSessionExpired(err) {
+			return fmt.Errorf("%w: %w", ErrSessionExpired, err)
+		}
 		return err
 	}
 
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
 	c.config.AccessToken = result.Access
 	if result.Refresh != "" {
 		c.config.RefreshToken = result.Refresh
 	}
 	c.config.ExpiresAt = time.Now().Add(TokenExpiryBuffer) // Assume 5 min expiry, refresh at 4
 
-	return config.Save(c.config)
+	return c.persistConfig()
 }
 
-// IsAuthenticated returns true if the client has valid auth tokens
+// persistConfig saves the config after the client changed its tokens. On
+// disk only the session's tokens and identity are replaced, so that
+// whatever else was saved since the client loaded the config, such as the
+// PIN lockout, is kept. The caller must hold mu.
+func (c *Client) persistConfig() error {
+	if c.saveConfig != nil {
+		return c.saveConfig(c.config)
+	}
+	session := *c.config
+	err := config.Update(func(cfg *config.Config) error {
+		cfg.AccessToken, cfg.RefreshToken, cfg.ExpiresAt = session.AccessToken, session.RefreshToken, session.ExpiresAt
+		cfg.IdentityName, cfg.IdentityUUID = session.IdentityName, session.IdentityUUID
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	c.watcher.Mark()
+	return nil
+}
+
+// canRefresh reports whether a new access token can be obtained without
+// the user: with the refresh token in cfg, or by exchanging the API key.
+func (c *Client) canRefresh(cfg config.Config) bool {
+	return c.apiKey != "" || cfg.RefreshToken != ""
+}
+
+// currentConfig returns a snapshot of the client's config.
+func (c *Client) currentConfig() config.Config {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.config == nil {
+		return config.Config{}
+	}
+	return *c.config
+}
+
+// reloadConfig picks up a config rewritten by another process since the
+// client last looked, e.g. new tokens after a re-login elsewhere. Reload
+// errors are ignored and the in-memory config is kept.
+func (c *Client) reloadConfig() {
+	cfg, err := c.watcher.Reload()
+	if err != nil || cfg == nil {
+		return
+	}
+	c.mu.Lock()
+	c.config = cfg
+	c.mu.Unlock()
+}
+
+// Ping checks that the API is reachable.
+func (c *Client) Ping() error {
+	return c.PingContext(context.Background())
+}
+
+// PingContext is Ping with a context that cancels the request. Any HTTP
+// response counts as reachable, even an error status.
+func (c *Client) PingContext(ctx context.Context) error {
+	resp, err := c.doRequestContext(ctx, http.MethodHead, "/", nil, false)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+// IsAuthenticated returns true if the client has valid auth tokens or an
+// API key
 func (c *Client) IsAuthenticated() bool {
-	return c.config.AccessToken != "" && c.config.RefreshToken != ""
+	if c.apiKey != "" {
+		return true
+	}
+	cfg := c.currentConfig()
+	return cfg.AccessToken != "" && cfg.RefreshToken != ""
 }
 
 // GetUserEmail returns the stored user email
 func (c *Client) GetUserEmail() string {
-	return c.config.UserEmail
+	return c.currentConfig().UserEmail
+}
+
+// TokenExpiry returns when the client will next refresh its access token.
+func (c *Client) TokenExpiry() time.Time {
+	return c.currentConfig().ExpiresAt
+}
+
+// SessionExpiry returns when the stored refresh token expires, read from
+// its JWT exp claim without verifying it. It is zero if the token isn't a
+// JWT with an expiry, and for API keys, whose sessions don't expire.
+func (c *Client) SessionExpiry() time.Time {
+	if c.apiKey != "" {
+		return time.Time{}
+	}
+	return jwtExpiry(c.currentConfig().RefreshToken)
 }
 
 // GetIdentityName returns the stored identity name (empty if unbound)
 func (c *Client) GetIdentityName() string {
-	return c.config.IdentityName
+	return c.currentConfig().IdentityName
+}
+
+// GetScopes returns the scopes the stored session is limited to (empty
+// for full access)
+func (c *Client) GetScopes() []string {
+	return c.currentConfig().Scopes
+}
+
+// GetIdentityUUID returns the stored identity UUID (empty if unbound, or
+// bound by a version that didn't record it)
+func (c *Client) GetIdentityUUID() string {
+	return c.currentConfig().IdentityUUID
 }
 
 // BuildURL builds a full URL with query parameters
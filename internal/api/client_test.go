@@ -1,7 +1,10 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"os"
@@ -34,6 +37,15 @@ func withTempHome(t *testing.T) (tmpDir string, cleanup func()) {
 		t.Fatalf("Failed to set %s: %v", homeEnvVar, err)
 	}
 
+	// Keep tokens in the temp config file rather than the OS keyring.
+	t.Setenv(config.EnvTokenStore, config.TokenStoreFile)
+	t.Setenv(config.EnvProfile, "")
+	t.Setenv(config.EnvAccount, "")
+	t.Setenv(EnvAPIKey, "")
+	t.Setenv(EnvClientCert, "")
+	t.Setenv(EnvClientKey, "")
+	t.Setenv(EnvCABundle, "")
+
 	cleanup = func() {
 		os.Setenv(homeEnvVar, originalHome)
 	}
@@ -517,6 +529,7 @@ func TestRefreshAccessToken_Failure(t *testing.T) {
 		statusCode     int
 		responseBody   interface{}
 		wantErrContain string
+		wantExpired    bool
 	}{
 		{
 			name:       "invalid refresh token",
@@ -525,6 +538,14 @@ func TestRefreshAccessToken_Failure(t *testing.T) {
 				Detail: "Token is invalid or expired",
 			},
 			wantErrContain: "Token is invalid or expired",
+			wantExpired:    true,
+		},
+		{
+			name:           "rejected refresh token with 400",
+			statusCode:     http.StatusBadRequest,
+			responseBody:   map[string]string{"code": "token_not_valid"},
+			wantErrContain: "token_not_valid",
+			wantExpired:    true,
 		},
 		{
 			name:           "server error",
@@ -566,6 +587,9 @@ func TestRefreshAccessToken_Failure(t *testing.T) {
 			if !strings.Contains(err.Error(), tc.wantErrContain) {
 				t.Errorf("RefreshAccessToken() error = %v, want error containing %q", err, tc.wantErrContain)
 			}
+			if got := errors.Is(err, ErrSessionExpired); got != tc.wantExpired {
+				t.Errorf("errors.Is(err, ErrSessionExpired) = %v, want %v", got, tc.wantExpired)
+			}
 		})
 	}
 }
@@ -951,6 +975,28 @@ func TestDoRequest_AcceptHeader(t *testing.T) {
 	}
 }
 
+// TestDoRequest_UserAgent verifies that doRequest identifies the CLI
+// version and platform in the User-Agent header.
+func TestDoRequest_UserAgent(t *testing.T) {
+	var receivedUserAgent string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		receivedUserAgent = r.Header.Get("User-Agent")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	resp, err := newTestClient(server.URL).doRequest(http.MethodGet, "/test", nil, false)
+	if err != nil {
+		t.Fatalf("doRequest() error = %v", err)
+	}
+	defer resp.Body.Close()
+
+	if receivedUserAgent != version.UserAgent() {
+		t.Errorf("User-Agent header = %v, want %v", receivedUserAgent, version.UserAgent())
+	}
+}
+
 // TestNewClient_BaseURLTrailingSlash verifies that trailing slashes are trimmed from base URL.
 func TestNewClient_BaseURLTrailingSlash(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -1120,3 +1166,239 @@ func TestNewClient_NilConfig_NoConfigFile(t *testing.T) {
 		t.Errorf("client.config.RefreshToken = %v, want empty", client.config.RefreshToken)
 	}
 }
+
+// TestParseResponse_APIError verifies that error responses are returned as
+// *APIError carrying the status code.
+func TestParseResponse_APIError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(Error{Detail: "Token is invalid or expired", Code: "token_not_valid"})
+	}))
+	defer server.Close()
+
+	client := newTestClient(server.URL)
+
+	resp, err := client.doRequest(http.MethodGet, "/test", nil, false)
+	if err != nil {
+		t.Fatalf("doRequest() error = %v", err)
+	}
+	defer resp.Body.Close()
+
+	err = client.parseResponse(resp, nil)
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("parseResponse() error = %T, want *APIError", err)
+	}
+	if apiErr.StatusCode != http.StatusUnauthorized {
+		t.Errorf("StatusCode = %d, want %d", apiErr.StatusCode, http.StatusUnauthorized)
+	}
+	if apiErr.Code != "token_not_valid" {
+		t.Errorf("Code = %q, want token_not_valid", apiErr.Code)
+	}
+	if want := "API error: Token is invalid or expired (request ID " + apiErr.RequestID + ")"; apiErr.Error() != want {
+		t.Errorf("Error() = %q, want %q", apiErr.Error(), want)
+	}
+}
+
+// TestAPIError_Is verifies that API errors match the sentinel for their
+// status, through wrapping, and no other.
+func TestAPIError_Is(t *testing.T) {
+	sentinels := []error{ErrUnauthorized, ErrNotFound, ErrRateLimited, ErrServer}
+	tests := []struct {
+		status int
+		want   error
+	}{
+		{http.StatusUnauthorized, ErrUnauthorized},
+		{http.StatusNotFound, ErrNotFound},
+		{http.StatusTooManyRequests, ErrRateLimited},
+		{http.StatusInternalServerError, ErrServer},
+		{http.StatusServiceUnavailable, ErrServer},
+		{http.StatusBadRequest, nil},
+	}
+	for _, tt := range tests {
+		err := fmt.Errorf("wrapped: %w", &APIError{StatusCode: tt.status, Detail: "x"})
+		for _, sentinel := range sentinels {
+			if got := errors.Is(err, sentinel); got != (sentinel == tt.want) {
+				t.Errorf("errors.Is(%d, %v) = %v", tt.status, sentinel, got)
+			}
+		}
+	}
+}
+
+// TestAPIError_ErrorDetails verifies that API errors are reported as a
+// structured object, with a JSON body kept as JSON.
+func TestAPIError_ErrorDetails(t *testing.T) {
+	tests := []struct {
+		name string
+		err  *APIError
+		want string
+	}{
+		{
+			name: "json body",
+			err:  &APIError{StatusCode: 400, Code: "invalid", Detail: "Bad phone number", Body: `{"detail":"Bad phone number","code":"invalid"}`, RequestID: "req-1"},
+			want: `{"status":400,"code":"invalid","detail":"Bad phone number","request_id":"req-1","body":{"detail":"Bad phone number","code":"invalid"}}`,
+		},
+		{
+			name: "text body",
+			err:  &APIError{StatusCode: 429, Body: "slow down", RetryAfter: 30 * time.Second},
+			want: `{"status":429,"retry_after_seconds":30,"body":"slow down"}`,
+		},
+		{
+			name: "no body",
+			err:  &APIError{StatusCode: 502},
+			want: `{"status":502}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := json.Marshal(tt.err.ErrorDetails())
+			if err != nil {
+				t.Fatalf("Marshal() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("ErrorDetails() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestNewClientForURL_SaveHook verifies that clients created for an explicit
+// URL hand refreshed tokens to the save hook instead of the config file.
+func TestNewClientForURL_SaveHook(t *testing.T) {
+	tmpDir, cleanupHome := withTempHome(t)
+	defer cleanupHome()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(RefreshResponse{Access: "hooked-access"})
+	}))
+	defer server.Close()
+
+	var saved *config.Config
+	client := NewClientForURL(server.URL+"/", &config.Config{RefreshToken: "r"}, func(cfg *config.Config) error {
+		saved = cfg
+		return nil
+	})
+
+	if err := client.RefreshAccessToken(); err != nil {
+		t.Fatalf("RefreshAccessToken() error = %v", err)
+	}
+	if saved == nil || saved.AccessToken != "hooked-access" {
+		t.Errorf("save hook got %+v, want AccessToken hooked-access", saved)
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, ".sunday", "config.json")); !os.IsNotExist(err) {
+		t.Error("config file should not be written by NewClientForURL clients")
+	}
+}
+
+// TestDoAuthenticatedRequest_ReloadsConfig verifies that a client loaded from
+// disk picks up tokens written by another process.
+func TestDoAuthenticatedRequest_ReloadsConfig(t *testing.T) {
+	_, cleanup := withTempHome(t)
+	defer cleanup()
+
+	var gotAuth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	future := time.Now().Add(time.Hour)
+	if err := config.Save(&config.Config{AccessToken: "old", RefreshToken: "r", ExpiresAt: future}); err != nil {
+		t.Fatalf("config.Save() error = %v", err)
+	}
+	client := newTestClient(server.URL)
+	client.watcher = config.NewWatcher()
+	client.config, _ = config.Load()
+
+	if err := config.Save(&config.Config{AccessToken: "relogged-in", RefreshToken: "r2", ExpiresAt: future}); err != nil {
+		t.Fatalf("config.Save() error = %v", err)
+	}
+	if err := client.doAuthenticatedRequest(http.MethodGet, "/test", nil, nil); err != nil {
+		t.Fatalf("doAuthenticatedRequest() error = %v", err)
+	}
+	if gotAuth != "Bearer relogged-in" {
+		t.Errorf("Authorization = %q, want Bearer relogged-in", gotAuth)
+	}
+}
+
+// TestDoRequest_DisableCache verifies that --no-cache asks intermediaries for
+// a fresh response.
+func TestDoRequest_DisableCache(t *testing.T) {
+	var got string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Get("Cache-Control")
+	}))
+	defer server.Close()
+
+	defer func() { DisableCache = false }()
+	DisableCache = true
+
+	resp, err := newTestClient(server.URL).doRequest(http.MethodGet, "/test", nil, false)
+	if err != nil {
+		t.Fatalf("doRequest() error = %v", err)
+	}
+	resp.Body.Close()
+	if got != "no-cache" {
+		t.Errorf("Cache-Control = %q, want no-cache", got)
+	}
+}
+
+// TestAccessToken verifies that AccessToken returns the stored token while
+// it is valid, and refreshes when it has expired or a refresh is forced.
+func TestAccessToken(t *testing.T) {
+	refreshes := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		refreshes++
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"access":"access-%d"}`, refreshes)
+	}))
+	defer server.Close()
+
+	cfg := &config.Config{AccessToken: "current", RefreshToken: "refresh", ExpiresAt: time.Now().Add(time.Hour)}
+	client := NewClientForURL(server.URL, cfg, nil)
+
+	if token, err := client.AccessToken(false); err != nil || token != "current" {
+		t.Errorf("AccessToken(false) = %q, %v; want current token", token, err)
+	}
+	if token, err := client.AccessToken(true); err != nil || token != "access-1" {
+		t.Errorf("AccessToken(true) = %q, %v; want refreshed token", token, err)
+	}
+
+	cfg.ExpiresAt = time.Now().Add(-time.Minute)
+	if token, err := client.AccessToken(false); err != nil || token != "access-2" {
+		t.Errorf("AccessToken(false) after expiry = %q, %v; want refreshed token", token, err)
+	}
+}
+
+// TestContextVariants verifies that the ...Context methods stop at the
+// caller's deadline, including a token refresh made on their behalf.
+func TestContextVariants(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	client := newTestClient(server.URL)
+	client.config.ExpiresAt = time.Now().Add(time.Hour)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := client.ListPasswordsContext(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("ListPasswordsContext() error = %v, want context.DeadlineExceeded", err)
+	}
+
+	client.config.ExpiresAt = time.Time{}
+	client.config.RefreshToken = "refresh"
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	if _, err := client.GetEmailThreadContext(ctx, "t1"); !errors.Is(err, context.Canceled) {
+		t.Errorf("GetEmailThreadContext() with expired token error = %v, want context.Canceled", err)
+	}
+}
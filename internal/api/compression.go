@@ -0,0 +1,72 @@
+package api
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// The client asks for gzip itself, rather than leaving it to
+// http.Transport, so that compression works whatever transport a client
+// has and instrumentation below the client (HAR capture, --debug) sees
+// the bytes actually transferred.
+
+// isGzip reports whether a response body with header h is gzip-encoded.
+func isGzip(h http.Header) bool {
+	return strings.EqualFold(h.Get("Content-Encoding"), "gzip")
+}
+
+// gunzip replaces a gzip-encoded response body with the decoded one, as
+// http.Transport does when it asked for gzip itself.
+func gunzip(resp *http.Response) *http.Response {
+	if !isGzip(resp.Header) {
+		return resp
+	}
+	resp.Body = &gzipBody{body: resp.Body}
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Del("Content-Length")
+	resp.ContentLength = -1
+	resp.Uncompressed = true
+	return resp
+}
+
+// gzipBody decodes a gzip-encoded body. The decoder is created on the
+// first read, so an empty body (e.g. of a 304) is only an error if read.
+type gzipBody struct {
+	body io.ReadCloser
+	zr   *gzip.Reader
+	err  error
+}
+
+func (b *gzipBody) Read(p []byte) (int, error) {
+	if b.zr == nil && b.err == nil {
+		b.zr, b.err = gzip.NewReader(b.body)
+	}
+	if b.err != nil {
+		return 0, b.err
+	}
+	return b.zr.Read(p)
+}
+
+func (b *gzipBody) Close() error {
+	return b.body.Close()
+}
+
+// decodedBody returns body as it reads once decoded, for display in HAR
+// captures and traces. A body that isn't valid gzip is returned as it is.
+func decodedBody(h http.Header, body []byte) []byte {
+	if !isGzip(h) || len(body) == 0 {
+		return body
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(body))
+	if err != nil {
+		return body
+	}
+	decoded, err := io.ReadAll(io.LimitReader(zr, DefaultMaxResponseBytes))
+	if err != nil {
+		return body
+	}
+	return decoded
+}
@@ -0,0 +1,82 @@
+package api
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// gzipServer serves body gzip-compressed to clients that ask for it, and
+// records how many bytes it sent.
+func gzipServer(t *testing.T, body []byte, sent *int) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if !strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") {
+			*sent = len(body)
+			w.Write(body)
+			return
+		}
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		zw.Write(body)
+		zw.Close()
+		*sent = buf.Len()
+		w.Header().Set("Content-Encoding", "gzip")
+		w.Write(buf.Bytes())
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// TestGzipResponses verifies that list responses are requested gzipped
+// and decoded transparently, transferring far fewer bytes.
+func TestGzipResponses(t *testing.T) {
+	threads := make([]EmailThread, 200)
+	for i := range threads {
+		threads[i] = EmailThread{ThreadID: fmt.Sprintf("thread-%d", i), Subject: "Your weekly digest", FromEmail: "news@example.com"}
+	}
+	body, _ := json.Marshal(threads)
+	var sent int
+	server := gzipServer(t, body, &sent)
+
+	got, err := newTestClient(server.URL).ListEmailThreads(false)
+	if err != nil {
+		t.Fatalf("ListEmailThreads() error = %v", err)
+	}
+	if len(got) != len(threads) || got[199].ThreadID != "thread-199" {
+		t.Fatalf("ListEmailThreads() returned %d threads, want %d", len(got), len(threads))
+	}
+	if sent*4 > len(body) {
+		t.Errorf("server sent %d bytes for a %d byte list, want it compressed", sent, len(body))
+	}
+}
+
+// TestGzipResponses_HAR verifies that HAR captures record the decoded
+// body and how much compression saved.
+func TestGzipResponses_HAR(t *testing.T) {
+	body := []byte(`{"detail":"` + strings.Repeat("a", 1000) + `"}`)
+	var sent int
+	server := gzipServer(t, body, &sent)
+
+	recorder := NewHARRecorder(nil)
+	client := newTestClient(server.URL)
+	client.httpClient.Transport = recorder
+	resp, err := client.doRequest(http.MethodGet, "/test", nil, false)
+	if err != nil {
+		t.Fatalf("doRequest() error = %v", err)
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+
+	content := recorder.entries[0].Response.Content
+	if content.Size != len(body) || content.Compression != len(body)-sent || !strings.HasPrefix(content.Text, `{"detail":"aaa`) {
+		t.Errorf("HAR content = size %d, compression %d; want %d, %d with the decoded text", content.Size, content.Compression, len(body), len(body)-sent)
+	}
+}
@@ -3,25 +3,105 @@ package api
 import "time"
 
 const (
+	// DefaultTimeout is how long a request may take, including reading the
+	// response, unless --timeout or the api.timeout setting says otherwise.
+	DefaultTimeout = 30 * time.Second
+
 	// TokenExpiryBuffer is the time before actual expiry to trigger refresh.
 	// Backend issues 5-minute tokens; we refresh at 4 minutes for safety.
 	TokenExpiryBuffer = 4 * time.Minute
+
+	// CircuitBreakerThreshold is the number of consecutive network errors or
+	// 5xx responses after which requests are short-circuited.
+	CircuitBreakerThreshold = 5
+
+	// CircuitBreakerCooldown is how long requests are short-circuited once
+	// the breaker has opened.
+	CircuitBreakerCooldown = 30 * time.Second
+
+	// TokenPreRefreshLead is how long before the access token expires the
+	// background refresher renews it.
+	TokenPreRefreshLead = 30 * time.Second
+
+	// TokenPreRefreshRetry is how long the background refresher waits
+	// before retrying a failed refresh.
+	TokenPreRefreshRetry = 15 * time.Second
+
+	// DefaultMaxRetries is how many times a transiently failed idempotent
+	// request is retried unless --retries says otherwise.
+	DefaultMaxRetries = 2
+
+	// DefaultRetryDelay is the wait before the first retry unless
+	// --retry-delay says otherwise.
+	DefaultRetryDelay = 500 * time.Millisecond
+
+	// MaxRetryDelay caps the exponential backoff between retries.
+	MaxRetryDelay = 10 * time.Second
+
+	// BatchConcurrency is how many requests a batch fetch such as
+	// GetEmailThreads has in flight at once.
+	BatchConcurrency = 8
+
+	// InboxReconnectDelay is how long SubscribeInbox waits before reopening
+	// a dropped stream, unless the server said otherwise. It doubles while
+	// reconnecting keeps failing, up to MaxRetryDelay.
+	InboxReconnectDelay = 3 * time.Second
+
+	// DefaultMaxIdleConns is how many idle connections to the API are kept
+	// for reuse unless the api.max_idle_conns setting says otherwise. It
+	// covers a full batch fetch with room to spare.
+	DefaultMaxIdleConns = 2 * BatchConcurrency
+
+	// DefaultIdleConnTimeout is how long an idle connection is kept open
+	// unless the api.idle_conn_timeout setting says otherwise.
+	DefaultIdleConnTimeout = 90 * time.Second
+
+	// DefaultTLSHandshakeTimeout is how long a TLS handshake may take
+	// unless the api.tls_handshake_timeout setting says otherwise.
+	DefaultTLSHandshakeTimeout = 10 * time.Second
+
+	// MaxRateLimitWait is the longest Retry-After a rate-limited request
+	// waits for before retrying. Longer waits fail with the 429 instead.
+	MaxRateLimitWait = time.Minute
+)
+
+const (
+	// DefaultMaxResponseBytes caps a single API response body unless
+	// overridden by the api.max_response_bytes config setting.
+	DefaultMaxResponseBytes = 32 << 20 // 32 MiB
+
+	// MaxJSONDepth is the deepest JSON nesting accepted from the API.
+	MaxJSONDepth = 64
+
+	// MaxInboxEventBytes caps a single line of the inbox event stream.
+	MaxInboxEventBytes = 1 << 20 // 1 MiB
 )
 
+// CLIClientID identifies the CLI to the authorization server in the
+// browser login flow.
+const CLIClientID = "sunday-cli"
+
 const (
 	// API endpoint paths
 	PathDeviceCode    = "/api/auth/device/"
 	PathDeviceToken   = "/api/auth/device/token/"
 	PathTokenRefresh  = "/api/auth/token/refresh/"
+	PathAuthorize     = "/oauth/authorize/"
+	PathAuthCodeToken = "/api/auth/code/token/"
+	PathAPIKeyToken   = "/api/auth/api-key/token/"
 	PathEmailInbox    = "/api/email-inbox/"
 	PathSMSInbox      = "/api/sms-inbox/"
+	PathInboxEvents   = "/api/inbox/events/"
 	PathPhone         = "/api/phone/"
 	PathEmail         = "/api/email/"
 	PathMessages      = "/api/messages/"
 	PathEmailMessages = "/api/email-messages/"
 	PathEncryption    = "/api/encryption/"
+	PathPublicKeys    = "/api/encryption/public-keys/"
 	PathOwner         = "/api/me/"
 	PathVault         = "/api/vault/"
 	PathIdentities    = "/api/identities/"
 	PathBindIdentity  = "/api/auth/bind-identity/"
+	PathSessions      = "/api/auth/sessions/"
+	PathMeta          = "/api/v1/meta/"
 )
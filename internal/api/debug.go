@@ -0,0 +1,137 @@
+package api
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+)
+
+// EnvDebug enables request tracing like --debug when set to a true value
+// such as 1.
+const EnvDebug = "SUNDAY_DEBUG"
+
+// debugBodyLimit is how much of each body a trace shows.
+const debugBodyLimit = 4 << 10
+
+// debugCaptureLimit is how much of each response body is kept to be
+// decoded and redacted for a trace. Longer bodies are noted by size only.
+const debugCaptureLimit = 256 << 10
+
+// DebugTransport is an http.RoundTripper that writes a trace of every
+// request and response to w: method, URL, status, duration, headers and
+// bodies, with credentials redacted as in HAR captures. A response is
+// traced when its headers arrive, and its body once the caller has read
+// it.
+type DebugTransport struct {
+	base http.RoundTripper
+	mu   sync.Mutex
+	w    io.Writer
+}
+
+// NewDebugTransport wraps base (http.DefaultTransport if nil) with tracing
+// to w.
+func NewDebugTransport(base http.RoundTripper, w io.Writer) *DebugTransport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &DebugTransport{base: base, w: w}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (d *DebugTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var reqBody []byte
+	if req.Body != nil {
+		var err error
+		reqBody, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("reading request body: %w", err)
+		}
+		req.Body = io.NopCloser(bytes.NewReader(reqBody))
+	}
+
+	var trace bytes.Buffer
+	fmt.Fprintf(&trace, "--> %s %s\n", req.Method, req.URL)
+	writeDebugHeaders(&trace, req.Header)
+	writeDebugBody(&trace, reqBody)
+
+	start := time.Now()
+	resp, err := d.base.RoundTrip(req)
+	elapsed := time.Since(start).Round(time.Millisecond)
+	if err != nil {
+		fmt.Fprintf(&trace, "<-- error after %s: %v\n", elapsed, err)
+		d.write(trace.Bytes())
+		return nil, err
+	}
+
+	fmt.Fprintf(&trace, "<-- %s (%s)\n", resp.Status, elapsed)
+	writeDebugHeaders(&trace, resp.Header)
+	if isEventStream(resp.Header) {
+		// An event stream never ends, so its body is left to the caller.
+		fmt.Fprintf(&trace, "    (event stream, not traced)\n")
+		d.write(trace.Bytes())
+		return resp, nil
+	}
+	d.write(trace.Bytes())
+
+	// The client strips Content-Encoding once it decodes the body, so keep
+	// the header as it arrived.
+	header := resp.Header.Clone()
+	resp.Body = newTeeBody(resp.Body, debugCaptureLimit, func(body []byte, size int) {
+		if size == 0 {
+			return
+		}
+		var trace bytes.Buffer
+		fmt.Fprintf(&trace, "<-- body of %s %s\n", req.Method, req.URL)
+		switch content := decodedBody(header, body); {
+		case size > len(body):
+			// A cut-short body can't be redacted, so none of it is shown.
+			fmt.Fprintf(&trace, "    (%d bytes, too long to trace)\n", size)
+		case len(content) != len(body):
+			fmt.Fprintf(&trace, "    (%d bytes gzipped to %d)\n", len(content), len(body))
+			writeDebugBody(&trace, content)
+		default:
+			writeDebugBody(&trace, content)
+		}
+		d.write(trace.Bytes())
+	})
+	return resp, nil
+}
+
+// write prints one complete trace, so that traces of concurrent requests
+// don't interleave.
+func (d *DebugTransport) write(p []byte) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.w.Write(p)
+}
+
+// writeDebugHeaders writes h sorted by name, redacting credentials.
+func writeDebugHeaders(w io.Writer, h http.Header) {
+	for _, name := range slices.Sorted(maps.Keys(h)) {
+		for _, v := range h[name] {
+			if harSensitiveHeaders[http.CanonicalHeaderKey(name)] {
+				v = harRedacted
+			}
+			fmt.Fprintf(w, "    %s: %s\n", name, v)
+		}
+	}
+}
+
+// writeDebugBody writes body with sensitive JSON fields redacted, cut
+// short after debugBodyLimit bytes.
+func writeDebugBody(w io.Writer, body []byte) {
+	if len(body) == 0 {
+		return
+	}
+	text := redactBody(body)
+	if len(text) > debugBodyLimit {
+		text = fmt.Sprintf("%s... (%d bytes)", text[:debugBodyLimit], len(body))
+	}
+	fmt.Fprintf(w, "    %s\n", text)
+}
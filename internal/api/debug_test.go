@@ -0,0 +1,131 @@
+package api
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestDebugTransport verifies that a trace shows the exchange with
+// credentials redacted and leaves the bodies readable by the caller.
+func TestDebugTransport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Remaining", "9")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"access": "new-access-token", "user": {"email": "a@example.com"}}`))
+	}))
+	defer server.Close()
+
+	var trace bytes.Buffer
+	client := &http.Client{Transport: NewDebugTransport(nil, &trace)}
+	req, _ := http.NewRequest(http.MethodPost, server.URL+"/api/test/?x=1", strings.NewReader(`{"refresh": "old-refresh-token", "note": "hi"}`))
+	req.Header.Set("Authorization", "Bearer secret-token")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if !strings.Contains(string(body), "new-access-token") {
+		t.Errorf("response body = %s, want it untouched for the caller", body)
+	}
+
+	out := trace.String()
+	for _, want := range []string{
+		"--> POST " + server.URL + "/api/test/?x=1",
+		"Authorization: REDACTED",
+		`"note":"hi"`,
+		"<-- 201 Created (",
+		"X-Ratelimit-Remaining: 9",
+		`"access":"REDACTED"`,
+		"a@example.com",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("trace missing %q:\n%s", want, out)
+		}
+	}
+	for _, secret := range []string{"secret-token", "old-refresh-token", "new-access-token"} {
+		if strings.Contains(out, secret) {
+			t.Errorf("trace leaks %q:\n%s", secret, out)
+		}
+	}
+}
+
+// TestDebugTransport_EventStream verifies that an event stream reaches the
+// caller as it arrives, with its headers traced but not its body.
+func TestDebugTransport_EventStream(t *testing.T) {
+	done := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Write([]byte("event: email\ndata: {}\n\n"))
+		w.(http.Flusher).Flush()
+		<-done
+	}))
+	defer server.Close()
+	defer close(done)
+
+	var trace syncBuffer
+	client := &http.Client{Transport: NewDebugTransport(nil, &trace)}
+
+	lines := make(chan string)
+	go func() {
+		resp, err := client.Get(server.URL)
+		if err != nil {
+			lines <- "error: " + err.Error()
+			return
+		}
+		defer resp.Body.Close()
+		line, _ := bufio.NewReader(resp.Body).ReadString('\n')
+		lines <- line
+	}()
+
+	select {
+	case line := <-lines:
+		if line != "event: email\n" {
+			t.Fatalf("first line = %q, want the event", line)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("event stream didn't reach the caller")
+	}
+	if out := trace.String(); !strings.Contains(out, "<-- 200 OK") || !strings.Contains(out, "event stream, not traced") {
+		t.Errorf("trace = %q, want the response headers and no body", out)
+	}
+}
+
+// syncBuffer is a bytes.Buffer safe to write and read from different
+// goroutines.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// TestWriteDebugBody verifies that long bodies are cut short.
+func TestWriteDebugBody(t *testing.T) {
+	var buf bytes.Buffer
+	writeDebugBody(&buf, bytes.Repeat([]byte("x"), debugBodyLimit+100))
+	if got := buf.Len(); got > debugBodyLimit+64 {
+		t.Errorf("trace of a long body is %d bytes, want about %d", got, debugBodyLimit)
+	}
+	if !strings.Contains(buf.String(), "(4196 bytes)") {
+		t.Errorf("trace = %q, want the full size noted", buf.String()[debugBodyLimit:])
+	}
+}
@@ -1,17 +1,51 @@
 package api
 
-import "net/http"
+import (
+	"context"
+	"net/http"
+	"net/url"
+)
 
 // GetEncryptionMeta fetches the user's encryption metadata.
 func (c *Client) GetEncryptionMeta() (*EncryptionMeta, error) {
+	return c.GetEncryptionMetaContext(context.Background())
+}
+
+// GetEncryptionMetaContext is GetEncryptionMeta with a context that
+// cancels the request.
+func (c *Client) GetEncryptionMetaContext(ctx context.Context) (*EncryptionMeta, error) {
 	var result EncryptionMeta
-	if err := c.doAuthenticatedRequest(http.MethodGet, PathEncryption, nil, &result); err != nil {
+	if err := c.doAuthenticatedRequestContext(ctx, http.MethodGet, PathEncryption, nil, &result); err != nil {
 		return nil, err
 	}
 	return &result, nil
 }
 
-// UpdateEncryptionMeta updates the user's encryption metadata (salt, verifier, public_key).
+// UpdateEncryptionMeta updates the user's encryption metadata (salt, verifier, public_key,
+// and the pending_ ones of a key change under way).
 func (c *Client) UpdateEncryptionMeta(data map[string]string) error {
-	return c.doAuthenticatedRequest(http.MethodPatch, PathEncryption, data, nil)
+	return c.UpdateEncryptionMetaContext(context.Background(), data)
+}
+
+// UpdateEncryptionMetaContext is UpdateEncryptionMeta with a context that
+// cancels the request.
+func (c *Client) UpdateEncryptionMetaContext(ctx context.Context, data map[string]string) error {
+	return c.doAuthenticatedRequestContext(ctx, http.MethodPatch, PathEncryption, data, nil)
+}
+
+// GetRecipientKey fetches the public key of the user with the given email,
+// for sharing with them.
+func (c *Client) GetRecipientKey(email string) (*RecipientKey, error) {
+	return c.GetRecipientKeyContext(context.Background(), email)
+}
+
+// GetRecipientKeyContext is GetRecipientKey with a context that cancels
+// the request.
+func (c *Client) GetRecipientKeyContext(ctx context.Context, email string) (*RecipientKey, error) {
+	path := PathPublicKeys + "?" + url.Values{"email": {email}}.Encode()
+	var result RecipientKey
+	if err := c.doAuthenticatedRequestContext(ctx, http.MethodGet, path, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
 }
@@ -391,3 +391,29 @@ func TestGetEncryptionMeta_AuthorizationHeader(t *testing.T) {
 		t.Errorf("Authorization header = %q, want %q", receivedAuthHeader, expectedAuth)
 	}
 }
+
+// TestGetRecipientKey verifies that GetRecipientKey asks for the key by
+// email and parses the response.
+func TestGetRecipientKey(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != PathPublicKeys {
+			t.Errorf("Expected path %s, got %s", PathPublicKeys, r.URL.Path)
+		}
+		email := r.URL.Query().Get("email")
+		if email != "bob+team@example.com" {
+			t.Errorf("email = %q, want bob+team@example.com", email)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(RecipientKey{Email: email, PublicKey: "cHVibGlj"})
+	}))
+	defer server.Close()
+
+	client := newTestClient(server.URL)
+	key, err := client.GetRecipientKey("bob+team@example.com")
+	if err != nil {
+		t.Fatalf("GetRecipientKey() error = %v", err)
+	}
+	if key.PublicKey != "cHVibGlj" {
+		t.Errorf("PublicKey = %q, want cHVibGlj", key.PublicKey)
+	}
+}
@@ -0,0 +1,138 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ErrSessionExpired is returned (wrapped) when the refresh token has
+// expired or been revoked, so only a new login can restore access.
+var ErrSessionExpired = errors.New("session expired")
+
+// Sentinel errors for common API failures. An *APIError with a matching
+// status is errors.Is each of them, so callers can branch without
+// inspecting the status code or the message:
+//
+//	if errors.Is(err, api.ErrNotFound) { ... }
+var (
+	// ErrUnauthorized matches a 401: the credentials were rejected.
+	ErrUnauthorized = errors.New("unauthorized")
+
+	// ErrNotFound matches a 404.
+	ErrNotFound = errors.New("not found")
+
+	// ErrRateLimited matches a 429 that retrying didn't get past.
+	ErrRateLimited = errors.New("rate limited")
+
+	// ErrServer matches any 5xx: the failure is on the server's side.
+	ErrServer = errors.New("server error")
+)
+
+// APIError is returned when the backend responds with a 4xx or 5xx status.
+// Callers can match it against ErrUnauthorized and the other sentinels
+// with errors.Is, or inspect StatusCode with errors.As.
+type APIError struct {
+	StatusCode int
+
+	// Code is the machine-readable error code the server gave, such as
+	// "token_not_valid", if any; Detail is its human-readable message.
+	Code   string
+	Detail string
+
+	// Body is the raw response body.
+	Body string
+
+	// RetryAfter is how long a 429 response asked the client to wait, if
+	// it said.
+	RetryAfter time.Duration
+
+	// RequestID identifies the failed request to the server's support:
+	// the ID the server assigned, or else the one the client sent.
+	RequestID string
+}
+
+// Error implements the error interface.
+func (e *APIError) Error() string {
+	msg := fmt.Sprintf("API error (status %d): %s", e.StatusCode, e.Body)
+	if e.Detail != "" {
+		msg = fmt.Sprintf("API error: %s", e.Detail)
+	}
+	if e.StatusCode == http.StatusTooManyRequests && e.RetryAfter > 0 {
+		msg += fmt.Sprintf(" (rate limited; retry after %s)", e.RetryAfter)
+	}
+	if e.RequestID != "" {
+		msg += fmt.Sprintf(" (request ID %s)", e.RequestID)
+	}
+	return msg
+}
+
+// Is reports whether the error's status matches target, one of the
+// sentinel errors such as ErrNotFound.
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests
+	case ErrServer:
+		return e.StatusCode >= http.StatusInternalServerError
+	}
+	return false
+}
+
+// RequestIdentifier returns the request ID, for output.RequestIdentifier.
+func (e *APIError) RequestIdentifier() string {
+	return e.RequestID
+}
+
+// apiErrorDetails is how an APIError is reported under --json.
+type apiErrorDetails struct {
+	Status     int    `json:"status"`
+	Code       string `json:"code,omitempty"`
+	Detail     string `json:"detail,omitempty"`
+	RequestID  string `json:"request_id,omitempty"`
+	RetryAfter int    `json:"retry_after_seconds,omitempty"`
+	Body       any    `json:"body,omitempty"`
+}
+
+// ErrorDetails returns the error as a structured object, for
+// output.ErrorDetailer. A JSON body is included as JSON rather than as a
+// string.
+func (e *APIError) ErrorDetails() any {
+	d := apiErrorDetails{
+		Status:     e.StatusCode,
+		Code:       e.Code,
+		Detail:     e.Detail,
+		RequestID:  e.RequestID,
+		RetryAfter: int(e.RetryAfter.Seconds()),
+	}
+	if json.Valid([]byte(e.Body)) {
+		d.Body = json.RawMessage(e.Body)
+	} else if e.Body != "" {
+		d.Body = e.Body
+	}
+	return d
+}
+
+// isSessionExpired reports whether err from a token refresh means the
+// refresh token itself is no longer accepted, rather than a transient
+// failure.
+func isSessionExpired(err error) bool {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	switch apiErr.StatusCode {
+	case http.StatusUnauthorized:
+		return true
+	case http.StatusBadRequest, http.StatusForbidden:
+		return strings.Contains(apiErr.Body, "token_not_valid") || strings.Contains(apiErr.Body, "invalid_grant")
+	}
+	return false
+}
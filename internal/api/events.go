@@ -0,0 +1,202 @@
+package api
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"iter"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Inbox event types: the SSE event name, and InboxMessage.Type.
+const (
+	InboxEventEmail = "email"
+	InboxEventSMS   = "sms"
+)
+
+// SubscribeInbox streams new inbox messages from the server as they
+// arrive, over server-sent events, until ctx is cancelled.
+//
+// A dropped stream is reopened after a delay, resuming from the last event
+// seen so nothing is missed; a 401 refreshes the token first. Only a
+// failure to open the stream the first time, or an error response, is
+// yielded, after which the sequence ends. Long-running callers should
+// also call StartTokenRefresher.
+func (c *Client) SubscribeInbox(ctx context.Context) iter.Seq2[InboxMessage, error] {
+	return func(yield func(InboxMessage, error) bool) {
+		var s inboxStream
+		delay := InboxReconnectDelay
+		for connected := false; ctx.Err() == nil; {
+			err := c.openInboxStream(ctx, &s)
+			if err == nil {
+				connected = true
+				delay = InboxReconnectDelay
+				if !s.read(ctx, yield) {
+					return
+				}
+			}
+			if ctx.Err() != nil {
+				return
+			}
+			var apiErr *APIError
+			if err != nil && (!connected || errors.As(err, &apiErr) || errors.Is(err, ErrOffline)) {
+				yield(InboxMessage{}, err)
+				return
+			}
+			if s.retry > 0 {
+				delay = s.retry
+			}
+			slog.Info("inbox stream closed, reconnecting", "error", err, "wait", delay)
+			if sleep(ctx, delay) != nil {
+				return
+			}
+			delay = min(delay*2, MaxRetryDelay)
+		}
+	}
+}
+
+// inboxStream is an open inbox event stream and what is remembered
+// across reconnects.
+type inboxStream struct {
+	resp *http.Response
+
+	// lastID is the ID of the last event, sent back as Last-Event-ID.
+	lastID string
+
+	// retry is the reconnection delay the server asked for, if any.
+	retry time.Duration
+}
+
+// openInboxStream opens the stream, refreshing the access token and
+// trying again once if it is rejected.
+func (c *Client) openInboxStream(ctx context.Context, s *inboxStream) error {
+	if Offline {
+		return fmt.Errorf("%w: watching the inbox needs the network", ErrOffline)
+	}
+	if err := c.ensureFreshToken(ctx); err != nil {
+		return err
+	}
+	token := c.currentConfig().AccessToken
+	resp, err := c.sendInboxStream(ctx, s.lastID)
+	if err == nil && resp.StatusCode == http.StatusUnauthorized && c.canRefresh(c.currentConfig()) {
+		discard(resp)
+		if err := c.refreshStale(ctx, token); err != nil {
+			return fmt.Errorf("authentication failed: %w", err)
+		}
+		resp, err = c.sendInboxStream(ctx, s.lastID)
+	}
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		return c.parseResponse(resp, nil)
+	}
+	s.resp = resp
+	return nil
+}
+
+// sendInboxStream requests the event stream. Unlike other requests it has
+// no timeout, isn't cached and isn't compressed, since the body never ends.
+func (c *Client) sendInboxStream(ctx context.Context, lastID string) (*http.Response, error) {
+	req, err := c.newRequest(ctx, http.MethodGet, c.baseURL+PathInboxEvents, nil, true, newRequestID())
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	req.Header.Set("Cache-Control", "no-cache")
+	req.Header.Del("Accept-Encoding")
+	if lastID != "" {
+		req.Header.Set("Last-Event-ID", lastID)
+	}
+
+	hc, err := c.httpClientFor(WithTimeout(ctx, 0))
+	if err != nil {
+		return nil, err
+	}
+	if err := c.breaker.allow(); err != nil {
+		return nil, err
+	}
+	resp, err := c.do(hc, req)
+	if ctx.Err() == nil {
+		c.breaker.record(resp, err)
+	}
+	return resp, err
+}
+
+// read yields the messages on the open stream until it ends. It returns
+// false if the consumer stopped the iteration.
+func (s *inboxStream) read(ctx context.Context, yield func(InboxMessage, error) bool) bool {
+	defer s.resp.Body.Close()
+
+	scanner := bufio.NewScanner(s.resp.Body)
+	scanner.Buffer(nil, MaxInboxEventBytes)
+	var event, id string
+	var data strings.Builder
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			// A blank line dispatches the event.
+			if id != "" {
+				s.lastID = id
+			}
+			if msg, ok := decodeInboxEvent(event, data.String()); ok && !yield(msg, nil) {
+				return false
+			}
+			event, id = "", ""
+			data.Reset()
+			continue
+		}
+
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "event":
+			event = value
+		case "data":
+			if data.Len() > 0 {
+				data.WriteByte('\n')
+			}
+			data.WriteString(value)
+		case "id":
+			id = value
+		case "retry":
+			if ms, err := strconv.Atoi(value); err == nil {
+				s.retry = time.Duration(ms) * time.Millisecond
+			}
+		}
+		// Lines starting with ":" are comments, sent as keep-alives.
+	}
+	if err := scanner.Err(); err != nil && ctx.Err() == nil {
+		slog.Debug("reading inbox stream failed", "error", err)
+	}
+	return true
+}
+
+// decodeInboxEvent decodes the data of an event, reporting false for
+// events that aren't new messages, such as keep-alive pings.
+func decodeInboxEvent(event, data string) (InboxMessage, bool) {
+	msg := InboxMessage{Type: event}
+	var err error
+	switch event {
+	case InboxEventEmail:
+		msg.Email = &SundayEmailMessage{}
+		err = json.Unmarshal([]byte(data), msg.Email)
+	case InboxEventSMS:
+		msg.SMS = &SundayPhoneMessage{}
+		err = json.Unmarshal([]byte(data), msg.SMS)
+	default:
+		return InboxMessage{}, false
+	}
+	if err != nil {
+		slog.Debug("skipping malformed inbox event", "event", event, "error", err)
+		return InboxMessage{}, false
+	}
+	return msg, true
+}
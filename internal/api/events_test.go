@@ -0,0 +1,93 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestSubscribeInbox verifies that new messages are decoded from the
+// event stream, that other events and comments are skipped, and that a
+// dropped stream is resumed from the last event ID.
+func TestSubscribeInbox(t *testing.T) {
+	waits := stubSleep(t)
+	var mu sync.Mutex
+	var lastIDs []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != PathInboxEvents || r.Header.Get("Accept") != "text/event-stream" {
+			t.Errorf("request = %s Accept %q, want the event stream", r.URL.Path, r.Header.Get("Accept"))
+		}
+		mu.Lock()
+		lastIDs = append(lastIDs, r.Header.Get("Last-Event-ID"))
+		first := len(lastIDs) == 1
+		mu.Unlock()
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		if first {
+			fmt.Fprint(w, ": keep-alive\n\nretry: 1500\n\nevent: ping\ndata: {}\n\n")
+			fmt.Fprint(w, "id: 7\nevent: email\ndata: {\"id\":1,\"subject\":\"Hello\",\n")
+			fmt.Fprint(w, "data: \"from_email\":\"a@example.com\"}\n\n")
+			return // drop the connection
+		}
+		fmt.Fprint(w, "id: 8\nevent: sms\ndata: {\"id\":2,\"body\":\"Your code is 123456\"}\n\n")
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	var got []InboxMessage
+	for msg, err := range newTestClient(server.URL).SubscribeInbox(ctx) {
+		if err != nil {
+			t.Fatalf("SubscribeInbox() error = %v", err)
+		}
+		got = append(got, msg)
+		if len(got) == 2 {
+			break
+		}
+	}
+
+	if len(got) != 2 {
+		t.Fatalf("SubscribeInbox() yielded %d messages, want 2", len(got))
+	}
+	if got[0].Type != InboxEventEmail || got[0].Email.Subject != "Hello" || got[0].Email.FromEmail != "a@example.com" {
+		t.Errorf("first message = %+v, want the email", got[0])
+	}
+	if got[1].Type != InboxEventSMS || got[1].SMS.Body != "Your code is 123456" {
+		t.Errorf("second message = %+v, want the SMS", got[1])
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(lastIDs) != 2 || lastIDs[0] != "" || lastIDs[1] != "7" {
+		t.Errorf("Last-Event-ID = %q, want none then 7", lastIDs)
+	}
+	if len(*waits) != 1 || (*waits)[0] != 1500*time.Millisecond {
+		t.Errorf("reconnect waits = %v, want the server's retry of 1.5s", *waits)
+	}
+}
+
+// TestSubscribeInbox_Error verifies that an error response ends the
+// subscription with an *APIError.
+func TestSubscribeInbox_Error(t *testing.T) {
+	stubSleep(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`{"detail":"Not allowed"}`))
+	}))
+	defer server.Close()
+
+	var errs []error
+	for _, err := range newTestClient(server.URL).SubscribeInbox(context.Background()) {
+		errs = append(errs, err)
+	}
+	var apiErr *APIError
+	if len(errs) != 1 || !errors.As(errs[0], &apiErr) || apiErr.StatusCode != http.StatusForbidden {
+		t.Errorf("SubscribeInbox() errors = %v, want one 403", errs)
+	}
+}
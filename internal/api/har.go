@@ -0,0 +1,376 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/ravi-technologies/sunday-cli/internal/version"
+)
+
+// harRedacted replaces sensitive header and body values in HAR captures.
+const harRedacted = "REDACTED"
+
+// harBodyLimit is how much of each response body a HAR capture keeps.
+// Longer bodies are noted but not recorded.
+const harBodyLimit = 8 << 20
+
+// harSensitiveHeaders lists headers whose values are never written to a HAR file.
+var harSensitiveHeaders = map[string]bool{
+	"Authorization": true,
+	"Cookie":        true,
+	"Set-Cookie":    true,
+}
+
+// harSensitiveFields lists JSON body keys whose values are never written to a
+// HAR file. E2E-encrypted fields are safe to keep, but tokens and any
+// plaintext secrets are not.
+var harSensitiveFields = map[string]bool{
+	"access":             true,
+	"refresh":            true,
+	"device_code":        true,
+	"password":           true,
+	"private_key":        true,
+	"managed_master_key": true,
+	"signing_secret":     true,
+	"api_key":            true,
+	"code":               true,
+	"code_verifier":      true,
+}
+
+// HARRecorder is an http.RoundTripper that records every request/response
+// pair in HTTP Archive (HAR 1.2) format, with credentials redacted.
+type HARRecorder struct {
+	base    http.RoundTripper
+	mu      sync.Mutex
+	entries []harEntry
+}
+
+// NewHARRecorder wraps base (http.DefaultTransport if nil) with HAR capture.
+func NewHARRecorder(base http.RoundTripper) *HARRecorder {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &HARRecorder{base: base}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (h *HARRecorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	var reqBody []byte
+	if req.Body != nil {
+		var err error
+		reqBody, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("reading request body: %w", err)
+		}
+		req.Body = io.NopCloser(bytes.NewReader(reqBody))
+	}
+
+	start := time.Now()
+	resp, err := h.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	wait := time.Since(start)
+
+	h.mu.Lock()
+	i := len(h.entries)
+	h.entries = append(h.entries, newHAREntry(req, reqBody, resp, start, wait))
+	h.mu.Unlock()
+
+	// An event stream never ends, so its body is left to the caller.
+	if isEventStream(resp.Header) {
+		return resp, nil
+	}
+	// The client strips Content-Encoding once it decodes the body, so keep
+	// the header as it arrived.
+	header := resp.Header.Clone()
+	resp.Body = newTeeBody(resp.Body, harBodyLimit, func(body []byte, size int) {
+		total := time.Since(start)
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		h.entries[i].setResponseBody(header, body, size, wait, total)
+	})
+	return resp, nil
+}
+
+// WriteFile writes the captured traffic to path as a HAR document.
+func (h *HARRecorder) WriteFile(path string) error {
+	h.mu.Lock()
+	entries := append([]harEntry{}, h.entries...)
+	h.mu.Unlock()
+
+	doc := harDocument{Log: harLog{
+		Version: "1.2",
+		Creator: harCreator{Name: "sunday-cli", Version: version.Version},
+		Entries: entries,
+	}}
+
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding HAR: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("writing HAR file: %w", err)
+	}
+	return nil
+}
+
+// newHAREntry records req and the headers of resp. The response body is
+// added by setResponseBody once the caller has read it.
+func newHAREntry(req *http.Request, reqBody []byte, resp *http.Response, start time.Time, wait time.Duration) harEntry {
+	entry := harEntry{
+		StartedDateTime: start.Format(time.RFC3339Nano),
+		Time:            durationMillis(wait),
+		Request: harRequest{
+			Method:      req.Method,
+			URL:         req.URL.String(),
+			HTTPVersion: req.Proto,
+			Headers:     harHeaders(req.Header),
+			QueryString: []harNameValue{},
+			Cookies:     []harNameValue{},
+			HeadersSize: -1,
+			BodySize:    len(reqBody),
+		},
+		Response: harResponse{
+			Status:      resp.StatusCode,
+			StatusText:  http.StatusText(resp.StatusCode),
+			HTTPVersion: resp.Proto,
+			Headers:     harHeaders(resp.Header),
+			Cookies:     []harNameValue{},
+			Content: harContent{
+				MimeType: resp.Header.Get("Content-Type"),
+			},
+			HeadersSize: -1,
+			BodySize:    -1,
+		},
+		Cache: struct{}{},
+		Timings: harTimings{
+			Send: 0,
+			Wait: durationMillis(wait),
+		},
+	}
+	if isEventStream(resp.Header) {
+		entry.Response.Content.Comment = "event stream, not recorded"
+	}
+
+	for name, values := range req.URL.Query() {
+		for _, v := range values {
+			entry.Request.QueryString = append(entry.Request.QueryString, harNameValue{Name: name, Value: v})
+		}
+	}
+
+	if len(reqBody) > 0 {
+		entry.Request.PostData = &harPostData{
+			MimeType: req.Header.Get("Content-Type"),
+			Text:     redactBody(reqBody),
+		}
+	}
+
+	return entry
+}
+
+// setResponseBody records the response body, size bytes of which the
+// caller read, the first of them in body.
+func (e *harEntry) setResponseBody(h http.Header, body []byte, size int, wait, total time.Duration) {
+	e.Time = durationMillis(total)
+	e.Timings.Receive = durationMillis(total - wait)
+	e.Response.BodySize = size
+	if size > len(body) {
+		// A cut-short body can't be redacted, so none of it is kept.
+		e.Response.Content.Comment = fmt.Sprintf("body of %d bytes not recorded, over the %d byte limit", size, harBodyLimit)
+		return
+	}
+	content := decodedBody(h, body)
+	e.Response.Content.Size = len(content)
+	e.Response.Content.Compression = len(content) - len(body)
+	e.Response.Content.Text = redactBody(content)
+}
+
+// isEventStream reports whether h is the header of a server-sent event
+// stream, whose body never ends.
+func isEventStream(h http.Header) bool {
+	mediaType, _, _ := mime.ParseMediaType(h.Get("Content-Type"))
+	return mediaType == "text/event-stream"
+}
+
+// teeBody wraps a response body, copying up to limit bytes of what the
+// caller reads. Once the caller reads to the end or closes the body, done
+// is called with the copy and how many bytes were read in all. Nothing is
+// read ahead of the caller, so streams aren't held up and the client's
+// own size limit still applies.
+type teeBody struct {
+	rc    io.ReadCloser
+	limit int
+	done  func(body []byte, size int)
+
+	mu       sync.Mutex
+	buf      bytes.Buffer
+	size     int
+	finished bool
+}
+
+func newTeeBody(rc io.ReadCloser, limit int, done func(body []byte, size int)) *teeBody {
+	return &teeBody{rc: rc, limit: limit, done: done}
+}
+
+func (t *teeBody) Read(p []byte) (int, error) {
+	n, err := t.rc.Read(p)
+	t.mu.Lock()
+	if room := t.limit - t.buf.Len(); room > 0 {
+		t.buf.Write(p[:min(n, room)])
+	}
+	t.size += n
+	t.mu.Unlock()
+	if err != nil {
+		t.finish()
+	}
+	return n, err
+}
+
+func (t *teeBody) Close() error {
+	err := t.rc.Close()
+	t.finish()
+	return err
+}
+
+// finish calls done the first time the body is read to the end or closed.
+func (t *teeBody) finish() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.finished {
+		return
+	}
+	t.finished = true
+	t.done(t.buf.Bytes(), t.size)
+}
+
+// harHeaders converts headers to HAR name/value pairs, redacting credentials.
+func harHeaders(h http.Header) []harNameValue {
+	out := []harNameValue{}
+	for name, values := range h {
+		for _, v := range values {
+			if harSensitiveHeaders[http.CanonicalHeaderKey(name)] {
+				v = harRedacted
+			}
+			out = append(out, harNameValue{Name: name, Value: v})
+		}
+	}
+	return out
+}
+
+// redactBody returns body as text with sensitive JSON fields replaced.
+// Non-JSON bodies are returned unchanged.
+func redactBody(body []byte) string {
+	var v interface{}
+	if err := json.Unmarshal(body, &v); err != nil {
+		return string(body)
+	}
+	redacted, err := json.Marshal(redactValue(v))
+	if err != nil {
+		return string(body)
+	}
+	return string(redacted)
+}
+
+func redactValue(v interface{}) interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for k, val := range t {
+			if harSensitiveFields[k] {
+				t[k] = harRedacted
+				continue
+			}
+			t[k] = redactValue(val)
+		}
+	case []interface{}:
+		for i := range t {
+			t[i] = redactValue(t[i])
+		}
+	}
+	return v
+}
+
+func durationMillis(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// HAR 1.2 document structure. See http://www.softwareishard.com/blog/har-12-spec/.
+type harDocument struct {
+	Log harLog `json:"log"`
+}
+
+type harLog struct {
+	Version string     `json:"version"`
+	Creator harCreator `json:"creator"`
+	Entries []harEntry `json:"entries"`
+}
+
+type harCreator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type harEntry struct {
+	StartedDateTime string      `json:"startedDateTime"`
+	Time            float64     `json:"time"`
+	Request         harRequest  `json:"request"`
+	Response        harResponse `json:"response"`
+	Cache           struct{}    `json:"cache"`
+	Timings         harTimings  `json:"timings"`
+}
+
+type harRequest struct {
+	Method      string         `json:"method"`
+	URL         string         `json:"url"`
+	HTTPVersion string         `json:"httpVersion"`
+	Headers     []harNameValue `json:"headers"`
+	QueryString []harNameValue `json:"queryString"`
+	Cookies     []harNameValue `json:"cookies"`
+	PostData    *harPostData   `json:"postData,omitempty"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+type harResponse struct {
+	Status      int            `json:"status"`
+	StatusText  string         `json:"statusText"`
+	HTTPVersion string         `json:"httpVersion"`
+	Headers     []harNameValue `json:"headers"`
+	Cookies     []harNameValue `json:"cookies"`
+	Content     harContent     `json:"content"`
+	RedirectURL string         `json:"redirectURL"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+type harNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type harPostData struct {
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+}
+
+type harContent struct {
+	Size        int    `json:"size"`
+	Compression int    `json:"compression,omitempty"`
+	MimeType    string `json:"mimeType"`
+	Text        string `json:"text"`
+	Comment     string `json:"comment,omitempty"`
+}
+
+type harTimings struct {
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+}
@@ -0,0 +1,183 @@
+package api
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestHARRecorder_RecordsAndRedacts verifies that traffic is captured in HAR
+// format with tokens and Authorization headers redacted.
+func TestHARRecorder_RecordsAndRedacts(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(RefreshResponse{Access: "new-access-token", Refresh: "new-refresh-token"})
+	}))
+	defer server.Close()
+
+	recorder := NewHARRecorder(nil)
+	client := newTestClient(server.URL)
+	client.httpClient.Transport = recorder
+
+	var result RefreshResponse
+	resp, err := client.doRequest(http.MethodPost, PathTokenRefresh+"?x=1", RefreshRequest{Refresh: "old-refresh-token"}, true)
+	if err != nil {
+		t.Fatalf("doRequest() error = %v", err)
+	}
+	defer resp.Body.Close()
+	if err := client.parseResponse(resp, &result); err != nil {
+		t.Fatalf("parseResponse() error = %v", err)
+	}
+
+	// The caller still sees the real response body.
+	if result.Access != "new-access-token" {
+		t.Errorf("result.Access = %q, want new-access-token", result.Access)
+	}
+
+	path := filepath.Join(t.TempDir(), "out.har")
+	if err := recorder.WriteFile(path); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading HAR file: %v", err)
+	}
+
+	for _, secret := range []string{"test-token", "old-refresh-token", "new-access-token", "new-refresh-token"} {
+		if strings.Contains(string(data), secret) {
+			t.Errorf("HAR file contains unredacted secret %q", secret)
+		}
+	}
+
+	var doc harDocument
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("HAR file is not valid JSON: %v", err)
+	}
+	if doc.Log.Version != "1.2" {
+		t.Errorf("log.version = %q, want 1.2", doc.Log.Version)
+	}
+	if len(doc.Log.Entries) != 1 {
+		t.Fatalf("len(entries) = %d, want 1", len(doc.Log.Entries))
+	}
+
+	entry := doc.Log.Entries[0]
+	if entry.Request.Method != http.MethodPost {
+		t.Errorf("request.method = %q, want POST", entry.Request.Method)
+	}
+	if entry.Response.Status != http.StatusOK {
+		t.Errorf("response.status = %d, want 200", entry.Response.Status)
+	}
+	if len(entry.Request.QueryString) != 1 || entry.Request.QueryString[0].Name != "x" {
+		t.Errorf("request.queryString = %v, want [x=1]", entry.Request.QueryString)
+	}
+	if entry.Request.PostData == nil || !strings.Contains(entry.Request.PostData.Text, harRedacted) {
+		t.Errorf("request.postData = %v, want redacted refresh token", entry.Request.PostData)
+	}
+}
+
+// TestHARRecorder_EventStream verifies that an event stream reaches the
+// caller as it arrives, rather than being read to an end that never comes,
+// and is noted in the capture without its body.
+func TestHARRecorder_EventStream(t *testing.T) {
+	done := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Write([]byte("event: email\ndata: {}\n\n"))
+		w.(http.Flusher).Flush()
+		<-done
+	}))
+	defer server.Close()
+	defer close(done)
+
+	recorder := NewHARRecorder(nil)
+	client := &http.Client{Transport: recorder}
+
+	lines := make(chan string)
+	go func() {
+		resp, err := client.Get(server.URL)
+		if err != nil {
+			lines <- "error: " + err.Error()
+			return
+		}
+		defer resp.Body.Close()
+		line, _ := bufio.NewReader(resp.Body).ReadString('\n')
+		lines <- line
+	}()
+
+	select {
+	case line := <-lines:
+		if line != "event: email\n" {
+			t.Fatalf("first line = %q, want the event", line)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("event stream didn't reach the caller")
+	}
+
+	path := filepath.Join(t.TempDir(), "out.har")
+	if err := recorder.WriteFile(path); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	data, _ := os.ReadFile(path)
+	var doc harDocument
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("HAR file is not valid JSON: %v", err)
+	}
+	if len(doc.Log.Entries) != 1 {
+		t.Fatalf("len(entries) = %d, want 1", len(doc.Log.Entries))
+	}
+	if content := doc.Log.Entries[0].Response.Content; content.Text != "" || content.Comment == "" {
+		t.Errorf("response.content = %+v, want no body and a comment", content)
+	}
+}
+
+// TestHARRecorder_LargeBody verifies that a body over harBodyLimit is
+// noted by size but not recorded.
+func TestHARRecorder_LargeBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"access": "` + strings.Repeat("x", harBodyLimit) + `"}`))
+	}))
+	defer server.Close()
+
+	recorder := NewHARRecorder(nil)
+	client := &http.Client{Transport: recorder}
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+
+	entry := recorder.entries[0]
+	if entry.Response.Content.Text != "" {
+		t.Errorf("response.content.text is %d bytes, want none", len(entry.Response.Content.Text))
+	}
+	if want := harBodyLimit + len(`{"access": ""}`); entry.Response.BodySize != want {
+		t.Errorf("response.bodySize = %d, want %d", entry.Response.BodySize, want)
+	}
+}
+
+// TestRedactBody_NonJSON verifies that non-JSON bodies are kept verbatim.
+func TestRedactBody_NonJSON(t *testing.T) {
+	if got := redactBody([]byte("Bad Gateway")); got != "Bad Gateway" {
+		t.Errorf("redactBody() = %q, want %q", got, "Bad Gateway")
+	}
+}
+
+// TestRedactBody_Nested verifies that sensitive keys are redacted at any depth.
+func TestRedactBody_Nested(t *testing.T) {
+	got := redactBody([]byte(`[{"uuid":"a","password":"hunter2","meta":{"private_key":"k","code":"auth-code"}}]`))
+	if strings.Contains(got, "hunter2") || strings.Contains(got, `"k"`) || strings.Contains(got, "auth-code") {
+		t.Errorf("redactBody() = %s, want sensitive fields redacted", got)
+	}
+	if !strings.Contains(got, `"uuid":"a"`) {
+		t.Errorf("redactBody() = %s, want non-sensitive fields kept", got)
+	}
+}
@@ -0,0 +1,41 @@
+package api
+
+import (
+	"net/http"
+	"time"
+)
+
+// RequestHook is told about each request a client sends: its method, URL
+// path, response status (0 if no response arrived) and how long the
+// server took to respond, up to the response headers. A retried request
+// is reported once per attempt. It may be called from several goroutines
+// at once.
+type RequestHook func(method, path string, status int, d time.Duration)
+
+// OnRequestDone sets hook to be called after each request the client
+// sends over the network, e.g. to record latency histograms and error
+// rates. Responses served from the cache without asking the server are
+// not reported. A nil hook removes it.
+func (c *Client) OnRequestDone(hook RequestHook) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.requestHook = hook
+}
+
+// do sends req with hc and reports it to the request hook, if any.
+func (c *Client) do(hc *http.Client, req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := hc.Do(req)
+
+	c.mu.RLock()
+	hook := c.requestHook
+	c.mu.RUnlock()
+	if hook != nil {
+		status := 0
+		if resp != nil {
+			status = resp.StatusCode
+		}
+		hook(req.Method, req.URL.Path, status, time.Since(start))
+	}
+	return resp, err
+}
@@ -0,0 +1,73 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestOnRequestDone verifies that the hook is told the method, path and
+// status of each request, and that a nil hook removes it.
+func TestOnRequestDone(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing/" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	type call struct {
+		method, path string
+		status       int
+	}
+	var mu sync.Mutex
+	var calls []call
+	client := newTestClient(server.URL)
+	client.OnRequestDone(func(method, path string, status int, d time.Duration) {
+		mu.Lock()
+		defer mu.Unlock()
+		calls = append(calls, call{method, path, status})
+		if d <= 0 {
+			t.Errorf("duration = %v, want > 0", d)
+		}
+	})
+
+	client.doAuthenticatedRequest(http.MethodGet, "/found/?page=2", nil, nil)
+	client.doAuthenticatedRequest(http.MethodDelete, "/missing/", nil, nil)
+	client.OnRequestDone(nil)
+	client.doAuthenticatedRequest(http.MethodGet, "/found/", nil, nil)
+
+	want := []call{
+		{http.MethodGet, "/found/", http.StatusOK},
+		{http.MethodDelete, "/missing/", http.StatusNotFound},
+	}
+	if len(calls) != len(want) {
+		t.Fatalf("calls = %+v, want %+v", calls, want)
+	}
+	for i := range want {
+		if calls[i] != want[i] {
+			t.Errorf("call %d = %+v, want %+v", i, calls[i], want[i])
+		}
+	}
+}
+
+// TestOnRequestDone_NetworkError verifies that a request that got no
+// response is reported with status 0.
+func TestOnRequestDone_NetworkError(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+
+	status := -1
+	client := newTestClient(server.URL)
+	client.OnRequestDone(func(method, path string, s int, d time.Duration) { status = s })
+	if _, err := client.doRequest(http.MethodGet, "/", nil, false); err == nil {
+		t.Fatal("doRequest() succeeded against a closed server")
+	}
+	if status != 0 {
+		t.Errorf("status = %d, want 0", status)
+	}
+}
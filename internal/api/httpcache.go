@@ -0,0 +1,226 @@
+package api
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ravi-technologies/sunday-cli/internal/config"
+	"github.com/ravi-technologies/sunday-cli/internal/crypto"
+)
+
+// cacheDirName is the directory, under the profile's data directory, that
+// holds cached API responses.
+const cacheDirName = "cache"
+
+// CacheDir returns the directory holding the active profile's cached API
+// responses (~/.sunday/cache for the default profile).
+func CacheDir() string {
+	return filepath.Join(config.Dir(), cacheDirName)
+}
+
+// ClearCache removes the active profile's cached API responses, e.g. on
+// logout.
+func ClearCache() error {
+	return os.RemoveAll(CacheDir())
+}
+
+// httpCache keeps GET response bodies on disk with their ETag and
+// Last-Modified validators, so a repeat request can be made conditional
+// and a 304 answered from disk. Every use is revalidated with the server;
+// the cache saves bandwidth, never a round trip, except that Offline
+// serves the last copy without asking.
+type httpCache struct {
+	dir string
+
+	// cipher encrypts the entries. Clients created by NewClient always
+	// have one, since responses include plaintext such as addresses and
+	// domains; only tests leave it nil.
+	cipher *crypto.CacheCipher
+}
+
+// newHTTPCache returns the response cache for cfg, or nil if cfg holds no
+// private key to encrypt it with: nothing is cached rather than stored in
+// plaintext. A key protected by a hardware token isn't used either, since
+// unwrapping it would need a touch for every command.
+func newHTTPCache(cfg *config.Config) *httpCache {
+	protector := crypto.ProtectorFor(cfg.PrivateKey)
+	if _, ok := protector.(crypto.SoftwareProtector); !ok || cfg.PrivateKey == "" {
+		return nil
+	}
+	priv, err := protector.Unwrap(cfg.PrivateKey)
+	if err != nil {
+		return nil
+	}
+	defer crypto.Wipe(priv[:])
+	cipher, err := crypto.NewCacheCipher(&crypto.KeyPair{PrivateKey: priv})
+	if err != nil {
+		return nil
+	}
+	return &httpCache{dir: CacheDir(), cipher: cipher}
+}
+
+// cacheEntry is a cached response, stored as one JSON file per URL.
+type cacheEntry struct {
+	ETag         string    `json:"etag,omitempty"`
+	LastModified string    `json:"last_modified,omitempty"`
+	ContentType  string    `json:"content_type,omitempty"`
+	Stored       time.Time `json:"stored"`
+	Body         []byte    `json:"body"`
+}
+
+// cacheFile returns the file caching a request to fullURL, or "" if the
+// request isn't cached. Entries are keyed by who is asking as well as the
+// URL, since the same path returns each account's own data.
+func (c *Client) cacheFile(method, fullURL string) string {
+	if c.cache == nil || method != http.MethodGet || DisableCache {
+		return ""
+	}
+	cfg := c.currentConfig()
+	scope := cfg.UserEmail + "\x00" + cfg.IdentityUUID
+	if c.apiKey != "" {
+		scope = "key\x00" + c.apiKey
+	}
+	sum := sha256.Sum256([]byte(scope + "\x00" + fullURL))
+	return filepath.Join(c.cache.dir, hex.EncodeToString(sum[:])+".json")
+}
+
+// load returns the entry cached in file, or nil if there is none. An
+// entry that can't be decrypted, because the key has changed or it was
+// stored before entries were encrypted, is removed.
+func (h *httpCache) load(file string) *cacheEntry {
+	if file == "" {
+		return nil
+	}
+	var data []byte
+	var err error
+	if h.cipher != nil {
+		data, err = h.cipher.DecryptFile(file)
+		if errors.Is(err, crypto.ErrCacheKey) {
+			os.Remove(file)
+		}
+	} else {
+		data, err = os.ReadFile(file)
+	}
+	if err != nil {
+		return nil
+	}
+	var e cacheEntry
+	if err := json.Unmarshal(data, &e); err != nil {
+		return nil
+	}
+	return &e
+}
+
+// addConditions makes req conditional on the cached entry still being
+// current.
+func (e *cacheEntry) addConditions(req *http.Request) {
+	if e == nil {
+		return
+	}
+	if e.ETag != "" {
+		req.Header.Set("If-None-Match", e.ETag)
+	}
+	if e.LastModified != "" {
+		req.Header.Set("If-Modified-Since", e.LastModified)
+	}
+}
+
+// update returns the response to hand back for a request cached in file:
+// a 304 is replaced with the cached body, and a 200 is stored for next
+// time (only one with validators can be revalidated, but any can be shown
+// offline). limit caps the size of a body worth caching.
+func (h *httpCache) update(file string, cached *cacheEntry, resp *http.Response, limit int64) *http.Response {
+	if file == "" {
+		return resp
+	}
+
+	if resp.StatusCode == http.StatusNotModified && cached != nil {
+		discard(resp)
+		slog.Debug("response served from cache", "url", resp.Request.URL.Redacted())
+		// The copy is now known to be current, which is what offline
+		// mode reports its age from.
+		cached.Stored = time.Now()
+		if err := h.store(file, cached); err != nil {
+			slog.Debug("caching response failed", "error", err)
+		}
+		hit := *resp
+		hit.StatusCode, hit.Status = http.StatusOK, "200 OK"
+		hit.Header = resp.Header.Clone()
+		if cached.ContentType != "" {
+			hit.Header.Set("Content-Type", cached.ContentType)
+		}
+		hit.Body = io.NopCloser(bytes.NewReader(cached.Body))
+		hit.ContentLength = int64(len(cached.Body))
+		return &hit
+	}
+	if resp.StatusCode != http.StatusOK {
+		return resp
+	}
+
+	entry := cacheEntry{
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+		ContentType:  resp.Header.Get("Content-Type"),
+		Stored:       time.Now(),
+	}
+	if strings.Contains(resp.Header.Get("Cache-Control"), "no-store") {
+		if cached != nil {
+			os.Remove(file)
+		}
+		return resp
+	}
+
+	// Read the body to store it, then give the caller the same bytes
+	// followed by anything past the limit.
+	data, err := io.ReadAll(io.LimitReader(resp.Body, limit+1))
+	resp.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(data), resp.Body), resp.Body}
+	if err != nil || int64(len(data)) > limit {
+		return resp
+	}
+	entry.Body = data
+	if err := h.store(file, &entry); err != nil {
+		slog.Debug("caching response failed", "error", err)
+	}
+	return resp
+}
+
+// store writes entry to file, replacing it atomically so a concurrent
+// command never reads half an entry.
+func (h *httpCache) store(file string, entry *cacheEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(h.dir, 0700); err != nil {
+		return err
+	}
+	if h.cipher != nil {
+		return h.cipher.EncryptFile(file, data)
+	}
+	tmp, err := os.CreateTemp(h.dir, ".entry-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), file)
+}
@@ -0,0 +1,135 @@
+package api
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ravi-technologies/sunday-cli/internal/config"
+	"github.com/ravi-technologies/sunday-cli/internal/crypto"
+)
+
+// TestHTTPCache verifies that a repeat GET is sent conditionally and a 304
+// is answered with the cached body.
+func TestHTTPCache(t *testing.T) {
+	var conditional []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conditional = append(conditional, r.Header.Get("If-None-Match"))
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"threads":[]}`))
+	}))
+	defer server.Close()
+
+	client := newTestClient(server.URL)
+	client.cache = &httpCache{dir: t.TempDir()}
+	get := func() (int, string) {
+		t.Helper()
+		resp, err := client.doRequest(http.MethodGet, "/api/email-inbox/", nil, true)
+		if err != nil {
+			t.Fatalf("doRequest() error = %v", err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return resp.StatusCode, string(body)
+	}
+
+	for i := range 2 {
+		if status, body := get(); status != http.StatusOK || body != `{"threads":[]}` {
+			t.Fatalf("request %d = %d %s, want 200 with the body", i+1, status, body)
+		}
+	}
+	if conditional[0] != "" || conditional[1] != `"v1"` {
+		t.Errorf("If-None-Match = %q, want none then the cached ETag", conditional)
+	}
+
+	DisableCache = true
+	defer func() { DisableCache = false }()
+	get()
+	if conditional[2] != "" {
+		t.Errorf("If-None-Match with DisableCache = %q, want none", conditional[2])
+	}
+}
+
+// TestHTTPCache_Scope verifies that cached responses aren't shared
+// between identities, and that a response without validators replaces an
+// entry without being revalidated later.
+func TestHTTPCache_Scope(t *testing.T) {
+	client := newTestClient("https://api.sunday.example")
+	client.cache = &httpCache{dir: t.TempDir()}
+	client.config.UserEmail = "a@example.com"
+
+	url := client.baseURL + "/api/email-inbox/"
+	first := client.cacheFile(http.MethodGet, url)
+	client.config.IdentityUUID = "other-identity"
+	if client.cacheFile(http.MethodGet, url) == first {
+		t.Error("cacheFile() is the same for another identity")
+	}
+	if f := client.cacheFile(http.MethodPost, url); f != "" {
+		t.Errorf("cacheFile(POST) = %q, want none", f)
+	}
+
+	if err := client.cache.store(first, &cacheEntry{ETag: `"v1"`, Body: []byte("{}")}); err != nil {
+		t.Fatal(err)
+	}
+	req, _ := http.NewRequest(http.MethodGet, url, nil)
+	resp := &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: http.NoBody, Request: req}
+	client.cache.update(first, client.cache.load(first), resp, DefaultMaxResponseBytes)
+	if e := client.cache.load(first); e == nil || e.ETag != "" {
+		t.Errorf("entry after a response without validators = %+v, want one without an ETag", e)
+	}
+
+	resp = &http.Response{StatusCode: http.StatusOK, Header: http.Header{"Cache-Control": {"no-store"}}, Body: http.NoBody, Request: req}
+	client.cache.update(first, client.cache.load(first), resp, DefaultMaxResponseBytes)
+	if _, err := os.Stat(first); !os.IsNotExist(err) {
+		t.Errorf("entry still cached after a no-store response (stat error %v)", err)
+	}
+}
+
+// TestNewHTTPCache verifies that the cache is only kept, encrypted, when
+// the config holds a software-protected private key, and that entries
+// written under another key are dropped.
+func TestNewHTTPCache(t *testing.T) {
+	if c := newHTTPCache(&config.Config{}); c != nil {
+		t.Error("newHTTPCache() without a private key returned a cache")
+	}
+
+	var priv [32]byte
+	priv[0] = 1
+	stored, _ := crypto.SoftwareProtector{}.Wrap(priv)
+	cache := newHTTPCache(&config.Config{PrivateKey: stored})
+	if cache == nil || cache.cipher == nil {
+		t.Fatal("newHTTPCache() with a private key returned no encrypted cache")
+	}
+	cache.dir = t.TempDir()
+
+	file := filepath.Join(cache.dir, "entry.json")
+	if err := cache.store(file, &cacheEntry{ETag: `"v1"`, Body: []byte(`{"domain":"example.com"}`)}); err != nil {
+		t.Fatalf("store() error = %v", err)
+	}
+	if data, _ := os.ReadFile(file); bytes.Contains(data, []byte("example.com")) {
+		t.Error("cache file holds plaintext")
+	}
+	if e := cache.load(file); e == nil || e.ETag != `"v1"` {
+		t.Errorf("load() = %+v, want the stored entry", e)
+	}
+
+	priv[0] = 2
+	stored, _ = crypto.SoftwareProtector{}.Wrap(priv)
+	other := newHTTPCache(&config.Config{PrivateKey: stored})
+	other.dir = cache.dir
+	if e := other.load(file); e != nil {
+		t.Errorf("load() under another key = %+v, want none", e)
+	}
+	if _, err := os.Stat(file); !os.IsNotExist(err) {
+		t.Error("entry under another key wasn't removed")
+	}
+}
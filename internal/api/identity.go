@@ -1,11 +1,23 @@
 package api
 
-import "net/http"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
 
 // ListIdentities returns all identities for the authenticated user.
 func (c *Client) ListIdentities() ([]Identity, error) {
+	return c.ListIdentitiesContext(context.Background())
+}
+
+// ListIdentitiesContext is ListIdentities with a context that cancels the
+// request.
+func (c *Client) ListIdentitiesContext(ctx context.Context) ([]Identity, error) {
 	var identities []Identity
-	if err := c.doAuthenticatedRequest(http.MethodGet, PathIdentities, nil, &identities); err != nil {
+	if err := c.doAuthenticatedRequestContext(ctx, http.MethodGet, PathIdentities, nil, &identities); err != nil {
 		return nil, err
 	}
 	return identities, nil
@@ -14,12 +26,49 @@ func (c *Client) ListIdentities() ([]Identity, error) {
 // BindIdentity exchanges an unbound token pair for one with the given
 // identity UUID baked into the JWT claims.
 func (c *Client) BindIdentity(identityUUID string) (*BindIdentityResponse, error) {
+	return c.BindIdentityContext(context.Background(), identityUUID)
+}
+
+// BindIdentityContext is BindIdentity with a context that cancels the
+// request.
+func (c *Client) BindIdentityContext(ctx context.Context, identityUUID string) (*BindIdentityResponse, error) {
 	req := BindIdentityRequest{
 		Identity: identityUUID,
 	}
 	var resp BindIdentityResponse
-	if err := c.doAuthenticatedRequest(http.MethodPost, PathBindIdentity, req, &resp); err != nil {
+	if err := c.doAuthenticatedRequestContext(ctx, http.MethodPost, PathBindIdentity, req, &resp); err != nil {
 		return nil, err
 	}
 	return &resp, nil
 }
+
+// SwitchIdentity binds the current session to another of the user's
+// identities and saves the new tokens, without a new login.
+func (c *Client) SwitchIdentity(identity Identity) error {
+	return c.SwitchIdentityContext(context.Background(), identity)
+}
+
+// SwitchIdentityContext is SwitchIdentity with a context that cancels the
+// request.
+func (c *Client) SwitchIdentityContext(ctx context.Context, identity Identity) error {
+	if c.apiKey != "" {
+		return errors.New("cannot switch identity when authenticating with an API key")
+	}
+
+	bound, err := c.BindIdentityContext(ctx, identity.UUID)
+	if err != nil {
+		return fmt.Errorf("binding identity: %w", err)
+	}
+	if bound.Access == "" || bound.Refresh == "" {
+		return fmt.Errorf("binding identity: server returned empty tokens")
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.config.AccessToken = bound.Access
+	c.config.RefreshToken = bound.Refresh
+	c.config.ExpiresAt = time.Now().Add(TokenExpiryBuffer)
+	c.config.IdentityName = identity.Name
+	c.config.IdentityUUID = identity.UUID
+	return c.persistConfig()
+}
@@ -0,0 +1,68 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ravi-technologies/sunday-cli/internal/config"
+)
+
+// TestSwitchIdentity verifies that switching binds the new identity and
+// saves the returned tokens with the identity's name and UUID.
+func TestSwitchIdentity(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != PathBindIdentity {
+			t.Errorf("unexpected request %s", r.URL.Path)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer old-access" {
+			t.Errorf("Authorization = %q, want the current session", got)
+		}
+		var req BindIdentityRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		if req.Identity != "uuid-2" {
+			t.Errorf("bind identity = %q, want uuid-2", req.Identity)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"access":"new-access","refresh":"new-refresh"}`))
+	}))
+	defer server.Close()
+
+	var saved *config.Config
+	cfg := &config.Config{
+		AccessToken:  "old-access",
+		RefreshToken: "old-refresh",
+		ExpiresAt:    time.Now().Add(time.Hour),
+		IdentityName: "Personal",
+		IdentityUUID: "uuid-1",
+	}
+	client := NewClientForURL(server.URL, cfg, func(c *config.Config) error {
+		copied := *c
+		saved = &copied
+		return nil
+	})
+
+	if err := client.SwitchIdentity(Identity{UUID: "uuid-2", Name: "Work"}); err != nil {
+		t.Fatalf("SwitchIdentity() error = %v", err)
+	}
+	if saved == nil {
+		t.Fatal("SwitchIdentity() did not save the config")
+	}
+	if saved.AccessToken != "new-access" || saved.RefreshToken != "new-refresh" || saved.IdentityName != "Work" || saved.IdentityUUID != "uuid-2" {
+		t.Errorf("saved config = %+v", saved)
+	}
+	if got := client.GetIdentityUUID(); got != "uuid-2" {
+		t.Errorf("GetIdentityUUID() = %q, want uuid-2", got)
+	}
+}
+
+// TestSwitchIdentity_APIKey verifies that API key sessions can't switch.
+func TestSwitchIdentity_APIKey(t *testing.T) {
+	client := NewClientForURL("https://sunday.example", nil, nil)
+	client.apiKey = "sk_test"
+	if err := client.SwitchIdentity(Identity{UUID: "uuid-2"}); err == nil {
+		t.Error("SwitchIdentity() with an API key succeeded, want error")
+	}
+}
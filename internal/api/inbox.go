@@ -1,12 +1,19 @@
 package api
 
 import (
+	"context"
 	"net/http"
 	"net/url"
 )
 
 // ListEmailThreads fetches email threads
 func (c *Client) ListEmailThreads(unreadOnly bool) ([]EmailThread, error) {
+	return c.ListEmailThreadsContext(context.Background(), unreadOnly)
+}
+
+// ListEmailThreadsContext is ListEmailThreads with a context that cancels
+// the request.
+func (c *Client) ListEmailThreadsContext(ctx context.Context, unreadOnly bool) ([]EmailThread, error) {
 	params := url.Values{}
 	if unreadOnly {
 		params.Set("has_unread", "true")
@@ -18,21 +25,36 @@ func (c *Client) ListEmailThreads(unreadOnly bool) ([]EmailThread, error) {
 	}
 
 	var result []EmailThread
-	if err := c.doAuthenticatedRequest(http.MethodGet, path, nil, &result); err != nil {
+	if err := c.doAuthenticatedRequestContext(ctx, http.MethodGet, path, nil, &result); err != nil {
 		return nil, err
 	}
 
 	return result, nil
 }
 
+// ListEmailThreadsPage fetches one page of email threads.
+func (c *Client) ListEmailThreadsPage(ctx context.Context, unreadOnly bool, opts PageOptions) (*Page[EmailThread], error) {
+	params := url.Values{}
+	if unreadOnly {
+		params.Set("has_unread", "true")
+	}
+	return listPage[EmailThread](ctx, c, PathEmailInbox, params, opts)
+}
+
 // GetEmailThread fetches a specific email thread by ID
 func (c *Client) GetEmailThread(threadID string) (*EmailThreadDetail, error) {
+	return c.GetEmailThreadContext(context.Background(), threadID)
+}
+
+// GetEmailThreadContext is GetEmailThread with a context that cancels the
+// request.
+func (c *Client) GetEmailThreadContext(ctx context.Context, threadID string) (*EmailThreadDetail, error) {
 	// URL encode the thread ID (it may contain special chars like < > @)
 	encodedID := url.PathEscape(threadID)
 	path := PathEmailInbox + encodedID + "/"
 
 	var result EmailThreadDetail
-	if err := c.doAuthenticatedRequest(http.MethodGet, path, nil, &result); err != nil {
+	if err := c.doAuthenticatedRequestContext(ctx, http.MethodGet, path, nil, &result); err != nil {
 		return nil, err
 	}
 
@@ -41,6 +63,12 @@ func (c *Client) GetEmailThread(threadID string) (*EmailThreadDetail, error) {
 
 // ListSMSConversations fetches SMS conversations
 func (c *Client) ListSMSConversations(unreadOnly bool) ([]SMSConversation, error) {
+	return c.ListSMSConversationsContext(context.Background(), unreadOnly)
+}
+
+// ListSMSConversationsContext is ListSMSConversations with a context that
+// cancels the request.
+func (c *Client) ListSMSConversationsContext(ctx context.Context, unreadOnly bool) ([]SMSConversation, error) {
 	params := url.Values{}
 	if unreadOnly {
 		params.Set("has_unread", "true")
@@ -52,21 +80,36 @@ func (c *Client) ListSMSConversations(unreadOnly bool) ([]SMSConversation, error
 	}
 
 	var result []SMSConversation
-	if err := c.doAuthenticatedRequest(http.MethodGet, path, nil, &result); err != nil {
+	if err := c.doAuthenticatedRequestContext(ctx, http.MethodGet, path, nil, &result); err != nil {
 		return nil, err
 	}
 
 	return result, nil
 }
 
+// ListSMSConversationsPage fetches one page of SMS conversations.
+func (c *Client) ListSMSConversationsPage(ctx context.Context, unreadOnly bool, opts PageOptions) (*Page[SMSConversation], error) {
+	params := url.Values{}
+	if unreadOnly {
+		params.Set("has_unread", "true")
+	}
+	return listPage[SMSConversation](ctx, c, PathSMSInbox, params, opts)
+}
+
 // GetSMSConversation fetches a specific SMS conversation by ID
 func (c *Client) GetSMSConversation(conversationID string) (*SMSConversationDetail, error) {
+	return c.GetSMSConversationContext(context.Background(), conversationID)
+}
+
+// GetSMSConversationContext is GetSMSConversation with a context that
+// cancels the request.
+func (c *Client) GetSMSConversationContext(ctx context.Context, conversationID string) (*SMSConversationDetail, error) {
 	// URL encode the conversation ID (it may contain + in phone numbers)
 	encodedID := url.PathEscape(conversationID)
 	path := PathSMSInbox + encodedID + "/"
 
 	var result SMSConversationDetail
-	if err := c.doAuthenticatedRequest(http.MethodGet, path, nil, &result); err != nil {
+	if err := c.doAuthenticatedRequestContext(ctx, http.MethodGet, path, nil, &result); err != nil {
 		return nil, err
 	}
 
@@ -0,0 +1,33 @@
+package api
+
+import (
+	"encoding/base64"
+	"testing"
+	"time"
+)
+
+// TestJWTExpiry verifies that the exp claim is read from a JWT, and that
+// anything else yields the zero time.
+func TestJWTExpiry(t *testing.T) {
+	jwt := func(payload string) string {
+		return "eyJhbGciOiJIUzI1NiJ9." + base64.RawURLEncoding.EncodeToString([]byte(payload)) + ".sig"
+	}
+	tests := []struct {
+		name  string
+		token string
+		want  time.Time
+	}{
+		{"exp claim", jwt(`{"exp":1767225600,"user_id":1}`), time.Unix(1767225600, 0)},
+		{"no exp claim", jwt(`{"user_id":1}`), time.Time{}},
+		{"not a JWT", "opaque-refresh-token", time.Time{}},
+		{"bad payload", "a.!!!.c", time.Time{}},
+		{"empty", "", time.Time{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := jwtExpiry(tt.token); !got.Equal(tt.want) {
+				t.Errorf("jwtExpiry() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package api
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// readBody reads the response body, refusing to buffer more than the
+// client's configured limit so a misbehaving endpoint cannot make the CLI
+// allocate unbounded memory.
+func (c *Client) readBody(resp *http.Response) ([]byte, error) {
+	limit := c.maxResponseBytes()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, limit+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if int64(len(data)) > limit {
+		return nil, fmt.Errorf("response body exceeds the %d byte limit", limit)
+	}
+	return data, nil
+}
+
+// maxResponseBytes returns the configured response size limit, falling back
+// to DefaultMaxResponseBytes.
+func (c *Client) maxResponseBytes() int64 {
+	if limit := c.currentConfig().API.MaxResponseBytes; limit > 0 {
+		return limit
+	}
+	return DefaultMaxResponseBytes
+}
+
+// checkJSONDepth rejects JSON documents nested deeper than max levels before
+// they reach encoding/json, which recurses once per level.
+func checkJSONDepth(data []byte, max int) error {
+	depth := 0
+	inString := false
+	escaped := false
+
+	for _, b := range data {
+		if inString {
+			switch {
+			case escaped:
+				escaped = false
+			case b == '\\':
+				escaped = true
+			case b == '"':
+				inString = false
+			}
+			continue
+		}
+
+		switch b {
+		case '"':
+			inString = true
+		case '{', '[':
+			depth++
+			if depth > max {
+				return fmt.Errorf("response JSON exceeds maximum nesting depth of %d", max)
+			}
+		case '}', ']':
+			depth--
+		}
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// TestParseResponse_BodyTooLarge verifies that oversized responses are rejected.
+func TestParseResponse_BodyTooLarge(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"detail":"` + strings.Repeat("x", 200) + `"}`))
+	}))
+	defer server.Close()
+
+	client := newTestClient(server.URL)
+	client.config.API.MaxResponseBytes = 100
+
+	resp, err := client.doRequest(http.MethodGet, "/test", nil, false)
+	if err != nil {
+		t.Fatalf("doRequest() error = %v", err)
+	}
+	defer resp.Body.Close()
+
+	var result map[string]string
+	err = client.parseResponse(resp, &result)
+	if err == nil || !strings.Contains(err.Error(), "100 byte limit") {
+		t.Errorf("parseResponse() error = %v, want size limit error", err)
+	}
+}
+
+// TestParseResponse_WithinLimit verifies that a body exactly at the limit is accepted.
+func TestParseResponse_WithinLimit(t *testing.T) {
+	body := `{"detail":"ok"}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	client := newTestClient(server.URL)
+	client.config.API.MaxResponseBytes = int64(len(body))
+
+	resp, err := client.doRequest(http.MethodGet, "/test", nil, false)
+	if err != nil {
+		t.Fatalf("doRequest() error = %v", err)
+	}
+	defer resp.Body.Close()
+
+	var result map[string]string
+	if err := client.parseResponse(resp, &result); err != nil {
+		t.Fatalf("parseResponse() error = %v", err)
+	}
+	if result["detail"] != "ok" {
+		t.Errorf("result = %v, want detail=ok", result)
+	}
+}
+
+// TestParseResponse_TooDeep verifies that deeply nested JSON is rejected
+// before decoding.
+func TestParseResponse_TooDeep(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(strings.Repeat("[", MaxJSONDepth+1) + strings.Repeat("]", MaxJSONDepth+1)))
+	}))
+	defer server.Close()
+
+	client := newTestClient(server.URL)
+
+	resp, err := client.doRequest(http.MethodGet, "/test", nil, false)
+	if err != nil {
+		t.Fatalf("doRequest() error = %v", err)
+	}
+	defer resp.Body.Close()
+
+	var result interface{}
+	err = client.parseResponse(resp, &result)
+	if err == nil || !strings.Contains(err.Error(), "nesting depth") {
+		t.Errorf("parseResponse() error = %v, want nesting depth error", err)
+	}
+}
+
+// TestCheckJSONDepth verifies depth counting, including brackets inside strings.
+func TestCheckJSONDepth(t *testing.T) {
+	testCases := []struct {
+		name    string
+		input   string
+		max     int
+		wantErr bool
+	}{
+		{name: "flat object", input: `{"a":1}`, max: 1, wantErr: false},
+		{name: "nested over limit", input: `{"a":{"b":[1]}}`, max: 2, wantErr: true},
+		{name: "nested at limit", input: `{"a":{"b":[1]}}`, max: 3, wantErr: false},
+		{name: "brackets in string", input: `{"a":"[[[[{{{{"}`, max: 1, wantErr: false},
+		{name: "escaped quote in string", input: `{"a":"\"[[["}`, max: 1, wantErr: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := checkJSONDepth([]byte(tc.input), tc.max)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("checkJSONDepth(%s, %d) error = %v, wantErr %v", tc.input, tc.max, err, tc.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package api
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// Offline makes clients answer GET requests from the response cache
+// instead of the network, and refuse everything else with ErrOffline. The
+// CLI sets it for --offline.
+var Offline bool
+
+// ErrOffline is returned (wrapped) for a request that can't be answered
+// while Offline is set.
+var ErrOffline = errors.New("offline")
+
+var (
+	offlineMu     sync.Mutex
+	offlineOldest time.Time
+)
+
+// OfflineSince returns when the oldest response served from the cache
+// while offline was fetched, or the zero time if none has been. Callers
+// use it to say how stale the data they show may be.
+func OfflineSince() time.Time {
+	offlineMu.Lock()
+	defer offlineMu.Unlock()
+	return offlineOldest
+}
+
+// noteOffline records that a response cached at stored was served offline.
+func noteOffline(stored time.Time) {
+	offlineMu.Lock()
+	defer offlineMu.Unlock()
+	if offlineOldest.IsZero() || stored.Before(offlineOldest) {
+		offlineOldest = stored
+	}
+}
+
+// offline answers req, which is cached in file, without the network.
+func (h *httpCache) offline(req *http.Request, file string) (*http.Response, error) {
+	if req.Method != http.MethodGet {
+		return nil, fmt.Errorf("%w: %s %s needs the network", ErrOffline, req.Method, req.URL.Path)
+	}
+	cached := h.load(file)
+	if cached == nil {
+		return nil, fmt.Errorf("%w: nothing cached for %s, run the command once online first", ErrOffline, req.URL.Path)
+	}
+	stored := cached.Stored
+	if stored.IsZero() {
+		// Entries cached before the time was recorded.
+		if info, err := os.Stat(file); err == nil {
+			stored = info.ModTime()
+		}
+	}
+	noteOffline(stored)
+
+	header := http.Header{}
+	if cached.ContentType != "" {
+		header.Set("Content-Type", cached.ContentType)
+	}
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(cached.Body)),
+		ContentLength: int64(len(cached.Body)),
+		Request:       req,
+	}, nil
+}
@@ -0,0 +1,61 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// TestOffline verifies that offline clients answer GETs from the cache
+// without contacting the server, report how old the data is, and refuse
+// requests they can't answer.
+func TestOffline(t *testing.T) {
+	hits := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`[{"thread_id":"t1"}]`))
+	}))
+	defer server.Close()
+
+	client := newTestClient(server.URL)
+	client.cache = &httpCache{dir: t.TempDir()}
+	before := time.Now()
+	if _, err := client.ListEmailThreads(false); err != nil {
+		t.Fatalf("online ListEmailThreads() error = %v", err)
+	}
+
+	Offline = true
+	t.Cleanup(func() {
+		Offline = false
+		offlineOldest = time.Time{}
+	})
+	client.config.ExpiresAt = time.Now().Add(-time.Hour) // no refresh offline
+	client.config.RefreshToken = "refresh"
+
+	threads, err := client.ListEmailThreads(false)
+	if err != nil {
+		t.Fatalf("offline ListEmailThreads() error = %v", err)
+	}
+	if len(threads) != 1 || threads[0].ThreadID != "t1" {
+		t.Errorf("offline ListEmailThreads() = %+v, want the cached thread", threads)
+	}
+	if hits != 1 {
+		t.Errorf("server hits = %d, want 1", hits)
+	}
+	if since := OfflineSince(); since.Before(before) || since.After(time.Now()) {
+		t.Errorf("OfflineSince() = %v, want when the list was fetched", since)
+	}
+
+	if _, err := client.ListSMSConversations(false); !errors.Is(err, ErrOffline) {
+		t.Errorf("uncached ListSMSConversations() error = %v, want ErrOffline", err)
+	}
+	if _, err := client.doRequest(http.MethodPost, "/api/email-inbox/", nil, true); !errors.Is(err, ErrOffline) {
+		t.Errorf("offline POST error = %v, want ErrOffline", err)
+	}
+	if hits != 1 {
+		t.Errorf("server hits = %d, want 1", hits)
+	}
+}
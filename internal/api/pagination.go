@@ -0,0 +1,189 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// PageOptions selects one page of a list endpoint. The zero value asks
+// for the first page at the server's default size.
+type PageOptions struct {
+	// Limit is the page size. Zero means the server's default.
+	Limit int
+
+	// Page is the page number, counting from 1. Zero means the first.
+	Page int
+
+	// Cursor continues from a previous page's Next and takes precedence
+	// over Page.
+	Cursor string
+}
+
+// Page is one page of results from a list endpoint.
+type Page[T any] struct {
+	Items []T
+
+	// Count is the total number of items across all pages, or -1 if the
+	// server didn't say.
+	Count int
+
+	// Next is the cursor for the following page, or "" on the last one.
+	Next string
+}
+
+// pageEnvelope is the body of a paginated response.
+type pageEnvelope[T any] struct {
+	Count   *int    `json:"count"`
+	Next    *string `json:"next"`
+	Results []T     `json:"results"`
+}
+
+// listPage fetches one page of the list at path, filtered by params. A
+// server that doesn't paginate returns a plain array, which is treated as
+// the only page.
+func listPage[T any](ctx context.Context, c *Client, path string, params url.Values, opts PageOptions) (*Page[T], error) {
+	if opts.Cursor != "" {
+		var err error
+		if path, err = c.cursorPath(opts.Cursor); err != nil {
+			return nil, err
+		}
+	} else {
+		params = cloneValues(params)
+		if opts.Limit > 0 {
+			params.Set("page_size", strconv.Itoa(opts.Limit))
+		}
+		if opts.Page > 0 {
+			params.Set("page", strconv.Itoa(opts.Page))
+		}
+		if len(params) > 0 {
+			path += "?" + params.Encode()
+		}
+	}
+
+	var raw json.RawMessage
+	if err := c.doAuthenticatedRequestContext(ctx, http.MethodGet, path, nil, &raw); err != nil {
+		return nil, err
+	}
+	return decodePage[T](raw)
+}
+
+// decodePage parses either a paginated envelope or a plain array.
+func decodePage[T any](raw json.RawMessage) (*Page[T], error) {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 || raw[0] == '[' {
+		var items []T
+		if len(raw) > 0 {
+			if err := json.Unmarshal(raw, &items); err != nil {
+				return nil, fmt.Errorf("failed to parse response: %w", err)
+			}
+		}
+		return &Page[T]{Items: items, Count: len(items)}, nil
+	}
+
+	var env pageEnvelope[T]
+	if err := json.Unmarshal(raw, &env); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+	page := &Page[T]{Items: env.Results, Count: -1}
+	if env.Count != nil {
+		page.Count = *env.Count
+	}
+	if env.Next != nil {
+		page.Next = *env.Next
+	}
+	return page, nil
+}
+
+// cursorPath turns a next-page cursor, the URL the server returned, into
+// a request path. It must point at the client's own server, so a
+// malicious response can't redirect authenticated requests elsewhere.
+func (c *Client) cursorPath(cursor string) (string, error) {
+	u, err := url.Parse(cursor)
+	if err != nil {
+		return "", fmt.Errorf("invalid page cursor: %w", err)
+	}
+	base, err := url.Parse(c.baseURL)
+	if err != nil {
+		return "", err
+	}
+	if u.Host != "" && (u.Host != base.Host || u.Scheme != base.Scheme) {
+		return "", fmt.Errorf("page cursor %q points at another server", cursor)
+	}
+	path := strings.TrimPrefix(u.EscapedPath(), base.EscapedPath())
+	if !strings.HasPrefix(path, "/") {
+		return "", fmt.Errorf("invalid page cursor %q", cursor)
+	}
+	if u.RawQuery != "" {
+		path += "?" + u.RawQuery
+	}
+	return path, nil
+}
+
+func cloneValues(v url.Values) url.Values {
+	out := make(url.Values, len(v))
+	for k, vs := range v {
+		out[k] = append([]string(nil), vs...)
+	}
+	return out
+}
+
+// errCursorLoop is returned when a server hands back a cursor it already
+// returned, which would otherwise page forever.
+var errCursorLoop = errors.New("pagination cursor repeated")
+
+// Paginator walks a list endpoint page by page, following each page's
+// Next cursor.
+type Paginator[T any] struct {
+	fetch func(context.Context, PageOptions) (*Page[T], error)
+	opts  PageOptions
+	seen  map[string]bool
+	done  bool
+}
+
+// NewPaginator returns a Paginator that fetches pages with fetch, starting
+// from the page opts selects.
+func NewPaginator[T any](fetch func(context.Context, PageOptions) (*Page[T], error), opts PageOptions) *Paginator[T] {
+	return &Paginator[T]{fetch: fetch, opts: opts, seen: map[string]bool{}}
+}
+
+// Next fetches the next page. It returns nil once the last page has been
+// returned.
+func (p *Paginator[T]) Next(ctx context.Context) (*Page[T], error) {
+	if p.done {
+		return nil, nil
+	}
+	page, err := p.fetch(ctx, p.opts)
+	if err != nil {
+		return nil, err
+	}
+	if page.Next == "" {
+		p.done = true
+	} else if p.seen[page.Next] {
+		return nil, errCursorLoop
+	}
+	p.seen[page.Next] = true
+	p.opts.Cursor = page.Next
+	return page, nil
+}
+
+// All fetches every remaining page and returns their items together.
+func (p *Paginator[T]) All(ctx context.Context) ([]T, error) {
+	var all []T
+	for {
+		page, err := p.Next(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if page == nil {
+			return all, nil
+		}
+		all = append(all, page.Items...)
+	}
+}
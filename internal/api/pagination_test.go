@@ -0,0 +1,103 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestListPage verifies the page parameters sent and that the envelope's
+// next URL is followed by the paginator.
+func TestListPage(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != PathVault {
+			t.Errorf("path = %s, want %s", r.URL.Path, PathVault)
+		}
+		switch r.URL.Query().Get("page") {
+		case "", "1":
+			if got := r.URL.Query().Get("page_size"); got != "2" {
+				t.Errorf("page_size = %q, want 2", got)
+			}
+			fmt.Fprintf(w, `{"count": 3, "next": "%s%s?page=2&page_size=2", "results": [{"uuid": "a"}, {"uuid": "b"}]}`, server.URL, PathVault)
+		case "2":
+			w.Write([]byte(`{"count": 3, "next": null, "results": [{"uuid": "c"}]}`))
+		default:
+			t.Errorf("unexpected page %q", r.URL.Query().Get("page"))
+		}
+	}))
+	defer server.Close()
+	client := newTestClient(server.URL)
+
+	page, err := client.ListPasswordsPage(context.Background(), PageOptions{Limit: 2})
+	if err != nil {
+		t.Fatalf("ListPasswordsPage() error = %v", err)
+	}
+	if len(page.Items) != 2 || page.Count != 3 || page.Next == "" {
+		t.Errorf("first page = %+v, want 2 of 3 items with a next cursor", page)
+	}
+
+	all, err := NewPaginator(client.ListPasswordsPage, PageOptions{Limit: 2}).All(context.Background())
+	if err != nil {
+		t.Fatalf("All() error = %v", err)
+	}
+	if len(all) != 3 || all[2].UUID != "c" {
+		t.Errorf("All() = %+v, want a, b, c", all)
+	}
+}
+
+// TestListPage_PlainArray verifies that an unpaginated response is one
+// complete page.
+func TestListPage_PlainArray(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("has_unread") != "true" {
+			t.Errorf("query = %q, want has_unread=true", r.URL.RawQuery)
+		}
+		w.Write([]byte(`[{"thread_id": "t1"}, {"thread_id": "t2"}]`))
+	}))
+	defer server.Close()
+
+	page, err := newTestClient(server.URL).ListEmailThreadsPage(context.Background(), true, PageOptions{Page: 1})
+	if err != nil {
+		t.Fatalf("ListEmailThreadsPage() error = %v", err)
+	}
+	if len(page.Items) != 2 || page.Count != 2 || page.Next != "" {
+		t.Errorf("page = %+v, want both items and no next cursor", page)
+	}
+}
+
+// TestCursorPath verifies that cursors can't point requests at another
+// server.
+func TestCursorPath(t *testing.T) {
+	client := newTestClient("https://api.sunday.app")
+	tests := []struct {
+		cursor  string
+		want    string
+		wantErr bool
+	}{
+		{"https://api.sunday.app/api/vault/?page=2", "/api/vault/?page=2", false},
+		{"/api/vault/?cursor=abc", "/api/vault/?cursor=abc", false},
+		{"https://evil.example/api/vault/?page=2", "", true},
+		{"http://api.sunday.app/api/vault/", "", true},
+		{"page=2", "", true},
+	}
+	for _, tc := range tests {
+		got, err := client.cursorPath(tc.cursor)
+		if (err != nil) != tc.wantErr || got != tc.want {
+			t.Errorf("cursorPath(%q) = %q, %v; want %q, error %v", tc.cursor, got, err, tc.want, tc.wantErr)
+		}
+	}
+}
+
+// TestPaginator_CursorLoop verifies that a repeated cursor stops paging.
+func TestPaginator_CursorLoop(t *testing.T) {
+	fetch := func(ctx context.Context, opts PageOptions) (*Page[int], error) {
+		return &Page[int]{Items: []int{1}, Next: "/again"}, nil
+	}
+	if _, err := NewPaginator(fetch, PageOptions{}).All(context.Background()); !errors.Is(err, errCursorLoop) {
+		t.Errorf("All() error = %v, want errCursorLoop", err)
+	}
+}
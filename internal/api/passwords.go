@@ -1,6 +1,7 @@
 package api
 
 import (
+	"context"
 	"net/http"
 	"net/url"
 	"strconv"
@@ -8,18 +9,34 @@ import (
 
 // ListPasswords fetches all password entries for the authenticated user.
 func (c *Client) ListPasswords() ([]PasswordEntry, error) {
+	return c.ListPasswordsContext(context.Background())
+}
+
+// ListPasswordsContext is ListPasswords with a context that cancels the
+// request.
+func (c *Client) ListPasswordsContext(ctx context.Context) ([]PasswordEntry, error) {
 	var result []PasswordEntry
-	if err := c.doAuthenticatedRequest(http.MethodGet, PathVault, nil, &result); err != nil {
+	if err := c.doAuthenticatedRequestContext(ctx, http.MethodGet, PathVault, nil, &result); err != nil {
 		return nil, err
 	}
 	return result, nil
 }
 
+// ListPasswordsPage fetches one page of password entries.
+func (c *Client) ListPasswordsPage(ctx context.Context, opts PageOptions) (*Page[PasswordEntry], error) {
+	return listPage[PasswordEntry](ctx, c, PathVault, nil, opts)
+}
+
 // GetPassword fetches a single password entry by UUID.
 func (c *Client) GetPassword(uuid string) (*PasswordEntry, error) {
+	return c.GetPasswordContext(context.Background(), uuid)
+}
+
+// GetPasswordContext is GetPassword with a context that cancels the request.
+func (c *Client) GetPasswordContext(ctx context.Context, uuid string) (*PasswordEntry, error) {
 	path := PathVault + uuid + "/"
 	var result PasswordEntry
-	if err := c.doAuthenticatedRequest(http.MethodGet, path, nil, &result); err != nil {
+	if err := c.doAuthenticatedRequestContext(ctx, http.MethodGet, path, nil, &result); err != nil {
 		return nil, err
 	}
 	return &result, nil
@@ -27,8 +44,14 @@ func (c *Client) GetPassword(uuid string) (*PasswordEntry, error) {
 
 // CreatePassword creates a new password entry.
 func (c *Client) CreatePassword(entry PasswordEntry) (*PasswordEntry, error) {
+	return c.CreatePasswordContext(context.Background(), entry)
+}
+
+// CreatePasswordContext is CreatePassword with a context that cancels the
+// request.
+func (c *Client) CreatePasswordContext(ctx context.Context, entry PasswordEntry) (*PasswordEntry, error) {
 	var result PasswordEntry
-	if err := c.doAuthenticatedRequest(http.MethodPost, PathVault, entry, &result); err != nil {
+	if err := c.doAuthenticatedRequestContext(ctx, http.MethodPost, PathVault, entry, &result); err != nil {
 		return nil, err
 	}
 	return &result, nil
@@ -36,9 +59,15 @@ func (c *Client) CreatePassword(entry PasswordEntry) (*PasswordEntry, error) {
 
 // UpdatePassword partially updates a password entry by UUID.
 func (c *Client) UpdatePassword(uuid string, fields map[string]interface{}) (*PasswordEntry, error) {
+	return c.UpdatePasswordContext(context.Background(), uuid, fields)
+}
+
+// UpdatePasswordContext is UpdatePassword with a context that cancels the
+// request.
+func (c *Client) UpdatePasswordContext(ctx context.Context, uuid string, fields map[string]interface{}) (*PasswordEntry, error) {
 	path := PathVault + uuid + "/"
 	var result PasswordEntry
-	if err := c.doAuthenticatedRequest(http.MethodPatch, path, fields, &result); err != nil {
+	if err := c.doAuthenticatedRequestContext(ctx, http.MethodPatch, path, fields, &result); err != nil {
 		return nil, err
 	}
 	return &result, nil
@@ -46,12 +75,41 @@ func (c *Client) UpdatePassword(uuid string, fields map[string]interface{}) (*Pa
 
 // DeletePassword deletes a password entry by UUID.
 func (c *Client) DeletePassword(uuid string) error {
+	return c.DeletePasswordContext(context.Background(), uuid)
+}
+
+// DeletePasswordContext is DeletePassword with a context that cancels the
+// request.
+func (c *Client) DeletePasswordContext(ctx context.Context, uuid string) error {
 	path := PathVault + uuid + "/"
-	return c.doAuthenticatedRequest(http.MethodDelete, path, nil, nil)
+	return c.doAuthenticatedRequestContext(ctx, http.MethodDelete, path, nil, nil)
+}
+
+// SharePassword shares a password entry by UUID, replacing its fields with
+// ones encrypted to every recipient.
+func (c *Client) SharePassword(uuid string, share PasswordShare) (*PasswordEntry, error) {
+	return c.SharePasswordContext(context.Background(), uuid, share)
+}
+
+// SharePasswordContext is SharePassword with a context that cancels the
+// request.
+func (c *Client) SharePasswordContext(ctx context.Context, uuid string, share PasswordShare) (*PasswordEntry, error) {
+	path := PathVault + uuid + "/share/"
+	var result PasswordEntry
+	if err := c.doAuthenticatedRequestContext(ctx, http.MethodPost, path, share, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
 }
 
 // GeneratePassword calls the server-side password generator.
 func (c *Client) GeneratePassword(opts PasswordGenOpts) (*GeneratedPassword, error) {
+	return c.GeneratePasswordContext(context.Background(), opts)
+}
+
+// GeneratePasswordContext is GeneratePassword with a context that cancels
+// the request.
+func (c *Client) GeneratePasswordContext(ctx context.Context, opts PasswordGenOpts) (*GeneratedPassword, error) {
 	params := url.Values{}
 	if opts.Length > 0 {
 		params.Set("length", strconv.Itoa(opts.Length))
@@ -80,7 +138,7 @@ func (c *Client) GeneratePassword(opts PasswordGenOpts) (*GeneratedPassword, err
 	}
 
 	var result GeneratedPassword
-	if err := c.doAuthenticatedRequest(http.MethodGet, path, nil, &result); err != nil {
+	if err := c.doAuthenticatedRequestContext(ctx, http.MethodGet, path, nil, &result); err != nil {
 		return nil, err
 	}
 	return &result, nil
@@ -158,6 +158,36 @@ func TestUpdatePassword_Success(t *testing.T) {
 	}
 }
 
+func TestSharePassword_Success(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Errorf("Expected POST, got %s", r.Method)
+		}
+		expectedPath := PathVault + "share-uuid/share/"
+		if r.URL.Path != expectedPath {
+			t.Errorf("Expected path %s, got %s", expectedPath, r.URL.Path)
+		}
+		var share PasswordShare
+		if err := json.NewDecoder(r.Body).Decode(&share); err != nil {
+			t.Errorf("decoding request: %v", err)
+		}
+
+		result := PasswordEntry{UUID: "share-uuid", Password: share.Password, SharedWith: share.Recipients}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(result)
+	}))
+	defer server.Close()
+
+	client := newTestClient(server.URL)
+	result, err := client.SharePassword("share-uuid", PasswordShare{Recipients: []string{"bob@example.com"}, Password: "e2em::abc"})
+	if err != nil {
+		t.Fatalf("SharePassword() error = %v", err)
+	}
+	if result.Password != "e2em::abc" || len(result.SharedWith) != 1 || result.SharedWith[0] != "bob@example.com" {
+		t.Errorf("SharePassword() = %+v, want the shared entry", result)
+	}
+}
+
 func TestDeletePassword_Success(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodDelete {
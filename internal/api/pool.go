@@ -0,0 +1,70 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/ravi-technologies/sunday-cli/internal/config"
+)
+
+// transports holds the transport built for each distinct set of settings,
+// shared by every client in the process that uses them.
+var (
+	transportsMu sync.Mutex
+	transports   = map[transportSettings]*http.Transport{}
+)
+
+// transportSettings is everything a transport is built from.
+type transportSettings struct {
+	clientCert, clientKey, caBundle string
+	pins                            string
+	proxyURL                        string
+
+	maxIdleConns        int
+	idleConnTimeout     time.Duration
+	tlsHandshakeTimeout time.Duration
+}
+
+// transportSettingsFor returns the transport settings for cfg, with the
+// environment overrides applied and the pool defaults filled in.
+func transportSettingsFor(cfg *config.Config) (transportSettings, error) {
+	s := transportSettings{
+		pins:                cfg.API.PinSHA256,
+		proxyURL:            cfg.API.ProxyURL,
+		maxIdleConns:        DefaultMaxIdleConns,
+		idleConnTimeout:     DefaultIdleConnTimeout,
+		tlsHandshakeTimeout: DefaultTLSHandshakeTimeout,
+	}
+	s.clientCert, s.clientKey, s.caBundle = tlsFiles(cfg)
+	if v := os.Getenv(EnvPinSHA256); v != "" {
+		s.pins = v
+	}
+
+	if n := cfg.API.MaxIdleConns; n > 0 {
+		s.maxIdleConns = n
+	}
+	var err error
+	if s.idleConnTimeout, err = durationSetting("api.idle_conn_timeout", cfg.API.IdleConnTimeout, s.idleConnTimeout); err != nil {
+		return s, err
+	}
+	if s.tlsHandshakeTimeout, err = durationSetting("api.tls_handshake_timeout", cfg.API.TLSHandshakeTimeout, s.tlsHandshakeTimeout); err != nil {
+		return s, err
+	}
+	return s, nil
+}
+
+// durationSetting parses the duration setting name, returning def if it
+// is unset.
+func durationSetting(name, value string, def time.Duration) (time.Duration, error) {
+	if value == "" {
+		return def, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid %s %q: want a positive duration such as 90s", name, value)
+	}
+	return d, nil
+}
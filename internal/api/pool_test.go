@@ -0,0 +1,61 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ravi-technologies/sunday-cli/internal/config"
+)
+
+// TestNewTransport_Shared verifies that clients with the same settings
+// share one transport, and clients with different ones don't.
+func TestNewTransport_Shared(t *testing.T) {
+	first, err := newTransport(&config.Config{})
+	if err != nil {
+		t.Fatalf("newTransport() error = %v", err)
+	}
+	second, err := newTransport(&config.Config{})
+	if err != nil {
+		t.Fatalf("newTransport() error = %v", err)
+	}
+	if first != second {
+		t.Error("newTransport() built a second transport for the same settings")
+	}
+
+	other, err := newTransport(&config.Config{API: config.APISettings{MaxIdleConns: 3}})
+	if err != nil {
+		t.Fatalf("newTransport() error = %v", err)
+	}
+	if other == first {
+		t.Error("newTransport() shared a transport between different settings")
+	}
+}
+
+// TestNewTransport_Pool verifies that the pool settings are applied, with
+// defaults for those left unset.
+func TestNewTransport_Pool(t *testing.T) {
+	rt, err := newTransport(&config.Config{API: config.APISettings{
+		MaxIdleConns:    4,
+		IdleConnTimeout: "5m",
+	}})
+	if err != nil {
+		t.Fatalf("newTransport() error = %v", err)
+	}
+	transport := rt.(*http.Transport)
+	if transport.MaxIdleConns != 4 || transport.MaxIdleConnsPerHost != 4 {
+		t.Errorf("idle connections = %d, %d per host; want 4", transport.MaxIdleConns, transport.MaxIdleConnsPerHost)
+	}
+	if transport.IdleConnTimeout != 5*time.Minute {
+		t.Errorf("IdleConnTimeout = %v, want 5m", transport.IdleConnTimeout)
+	}
+	if transport.TLSHandshakeTimeout != DefaultTLSHandshakeTimeout {
+		t.Errorf("TLSHandshakeTimeout = %v, want %v", transport.TLSHandshakeTimeout, DefaultTLSHandshakeTimeout)
+	}
+
+	_, err = newTransport(&config.Config{API: config.APISettings{TLSHandshakeTimeout: "soon"}})
+	if err == nil || !strings.Contains(err.Error(), "invalid api.tls_handshake_timeout") {
+		t.Errorf("newTransport() error = %v, want invalid api.tls_handshake_timeout", err)
+	}
+}
@@ -0,0 +1,72 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+
+	"github.com/ravi-technologies/sunday-cli/internal/config"
+)
+
+// proxySchemes are the proxy URL schemes net/http can use. socks5h
+// resolves host names on the proxy.
+var proxySchemes = []string{"http", "https", "socks5", "socks5h"}
+
+// proxyPorts are the ports assumed for a proxy URL without one.
+var proxyPorts = map[string]string{"http": "80", "https": "443", "socks5": "1080", "socks5h": "1080"}
+
+// proxyFunc returns how a client picks its proxy: the api.proxy_url
+// setting if it is set, otherwise HTTPS_PROXY, HTTP_PROXY and NO_PROXY.
+func proxyFunc(cfg *config.Config) (func(*http.Request) (*url.URL, error), error) {
+	if cfg.API.ProxyURL == "" {
+		return http.ProxyFromEnvironment, nil
+	}
+	u, err := parseProxyURL(cfg.API.ProxyURL)
+	if err != nil {
+		return nil, err
+	}
+	return http.ProxyURL(u), nil
+}
+
+// parseProxyURL parses and checks the api.proxy_url setting.
+func parseProxyURL(s string) (*url.URL, error) {
+	u, err := url.Parse(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid api.proxy_url: %w", err)
+	}
+	if !slices.Contains(proxySchemes, u.Scheme) || u.Host == "" {
+		return nil, fmt.Errorf("invalid api.proxy_url %q: want a URL such as http://proxy:3128 or socks5://proxy:1080", s)
+	}
+	return u, nil
+}
+
+// ProxyFor returns the proxy that requests to target go through under
+// cfg, or nil if they go direct.
+func ProxyFor(cfg *config.Config, target string) (*url.URL, error) {
+	proxy, err := proxyFunc(cfg)
+	if err != nil {
+		return nil, err
+	}
+	u, err := url.Parse(target)
+	if err != nil {
+		return nil, err
+	}
+	return proxy(&http.Request{URL: u})
+}
+
+// DialProxy checks that the proxy at u accepts connections.
+func DialProxy(ctx context.Context, u *url.URL) error {
+	addr := u.Host
+	if u.Port() == "" {
+		addr = net.JoinHostPort(u.Hostname(), proxyPorts[u.Scheme])
+	}
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return fmt.Errorf("proxy %s is unreachable: %w", u.Redacted(), err)
+	}
+	return conn.Close()
+}
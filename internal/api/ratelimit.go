@@ -0,0 +1,88 @@
+package api
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RateLimit is the request quota the server last reported in its
+// X-RateLimit-* (or RateLimit-*) headers.
+type RateLimit struct {
+	Limit     int
+	Remaining int
+
+	// Reset is when the quota refills. It is zero if the server didn't say.
+	Reset time.Time
+}
+
+// RateLimit returns the quota reported with the client's most recent
+// response, and false if no response has carried one.
+func (c *Client) RateLimit() (RateLimit, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.rateLimit == nil {
+		return RateLimit{}, false
+	}
+	return *c.rateLimit, true
+}
+
+// observeRateLimit records the quota reported with resp, if any.
+func (c *Client) observeRateLimit(resp *http.Response) {
+	if resp == nil {
+		return
+	}
+	rl, ok := parseRateLimit(resp.Header, time.Now())
+	if !ok {
+		return
+	}
+	c.mu.Lock()
+	c.rateLimit = &rl
+	c.mu.Unlock()
+	slog.Debug("api rate limit", "path", resp.Request.URL.Path,
+		"limit", rl.Limit, "remaining", rl.Remaining, "reset", rl.Reset)
+}
+
+// parseRateLimit reads the quota headers of a response. Reset may be
+// given as seconds from now or as a Unix time.
+func parseRateLimit(h http.Header, now time.Time) (RateLimit, bool) {
+	header := func(name string) string {
+		if v := h.Get("X-RateLimit-" + name); v != "" {
+			return v
+		}
+		return h.Get("RateLimit-" + name)
+	}
+	remaining, err := strconv.Atoi(strings.TrimSpace(header("Remaining")))
+	if err != nil {
+		return RateLimit{}, false
+	}
+	rl := RateLimit{Remaining: remaining}
+	rl.Limit, _ = strconv.Atoi(strings.TrimSpace(header("Limit")))
+	if reset, err := strconv.ParseInt(strings.TrimSpace(header("Reset")), 10, 64); err == nil {
+		// Values this large can only be timestamps, not delays.
+		if reset > 1e9 {
+			rl.Reset = time.Unix(reset, 0)
+		} else {
+			rl.Reset = now.Add(time.Duration(reset) * time.Second)
+		}
+	}
+	return rl, true
+}
+
+// retryAfter returns how long a Retry-After header asks the client to
+// wait, given either in seconds or as an HTTP date.
+func retryAfter(h http.Header, now time.Time) (time.Duration, bool) {
+	v := strings.TrimSpace(h.Get("Retry-After"))
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(v); err == nil {
+		return max(time.Duration(secs)*time.Second, 0), true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		return max(t.Sub(now), 0), true
+	}
+	return 0, false
+}
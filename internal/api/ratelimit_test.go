@@ -0,0 +1,79 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// TestParseRateLimit verifies both header spellings and both forms of the
+// reset time.
+func TestParseRateLimit(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	tests := []struct {
+		name   string
+		header http.Header
+		want   RateLimit
+		wantOK bool
+	}{
+		{"none", http.Header{}, RateLimit{}, false},
+		{
+			"x-prefixed with delay",
+			http.Header{"X-Ratelimit-Limit": {"100"}, "X-Ratelimit-Remaining": {"7"}, "X-Ratelimit-Reset": {"30"}},
+			RateLimit{Limit: 100, Remaining: 7, Reset: now.Add(30 * time.Second)},
+			true,
+		},
+		{
+			"unprefixed with timestamp",
+			http.Header{"Ratelimit-Remaining": {"0"}, "Ratelimit-Reset": {"1700000060"}},
+			RateLimit{Remaining: 0, Reset: time.Unix(1_700_000_060, 0)},
+			true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, ok := parseRateLimit(tc.header, now)
+			if ok != tc.wantOK || got.Limit != tc.want.Limit || got.Remaining != tc.want.Remaining || !got.Reset.Equal(tc.want.Reset) {
+				t.Errorf("parseRateLimit() = %+v, %v; want %+v, %v", got, ok, tc.want, tc.wantOK)
+			}
+		})
+	}
+}
+
+// TestRetryAfter verifies Retry-After in seconds and as an HTTP date.
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	if d, ok := retryAfter(http.Header{"Retry-After": {"15"}}, now); !ok || d != 15*time.Second {
+		t.Errorf("retryAfter(15) = %s, %v; want 15s", d, ok)
+	}
+	date := now.Add(time.Minute).Format(http.TimeFormat)
+	if d, ok := retryAfter(http.Header{"Retry-After": {date}}, now); !ok || d != time.Minute {
+		t.Errorf("retryAfter(%q) = %s, %v; want 1m", date, d, ok)
+	}
+	if _, ok := retryAfter(http.Header{"Retry-After": {"soon"}}, now); ok {
+		t.Error("retryAfter(soon) ok = true, want false")
+	}
+}
+
+// TestClient_RateLimit verifies that the quota of the latest response is
+// kept on the client.
+func TestClient_RateLimit(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Limit", "60")
+		w.Header().Set("X-RateLimit-Remaining", "59")
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	client := newTestClient(server.URL)
+	if _, ok := client.RateLimit(); ok {
+		t.Fatal("RateLimit() ok = true before any request")
+	}
+	if err := client.doAuthenticatedRequest(http.MethodGet, "/test", nil, nil); err != nil {
+		t.Fatalf("doAuthenticatedRequest() error = %v", err)
+	}
+	if rl, ok := client.RateLimit(); !ok || rl.Limit != 60 || rl.Remaining != 59 {
+		t.Errorf("RateLimit() = %+v, %v; want 59 of 60", rl, ok)
+	}
+}
@@ -0,0 +1,79 @@
+package api
+
+import (
+	"context"
+	"time"
+)
+
+// StartTokenRefresher renews the access token on a background goroutine
+// shortly before it expires, so long-running commands (watch and streaming
+// modes) never stall on a refresh in the middle of an operation. It returns
+// immediately and stops when ctx is cancelled. Failed refreshes are retried;
+// requests still fall back to refreshing on demand.
+func (c *Client) StartTokenRefresher(ctx context.Context) {
+	go func() {
+		for {
+			cfg := c.currentConfig()
+			if cfg.RefreshToken == "" {
+				return
+			}
+
+			timer := time.NewTimer(max(time.Until(cfg.ExpiresAt)-TokenPreRefreshLead, 0))
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+
+			if err := c.refreshStale(ctx, cfg.AccessToken); err != nil {
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(TokenPreRefreshRetry):
+				}
+			}
+		}
+	}()
+}
+
+// refreshCall is a token refresh that several goroutines may wait for.
+type refreshCall struct {
+	done chan struct{}
+	err  error
+}
+
+// refreshStale refreshes the access token, which the caller found to be
+// stale, unless it has already been replaced. Concurrent callers share one
+// refresh and its result, so a rotated refresh token is only ever used
+// once. The refresh isn't cancelled with ctx, since others may be waiting
+// for it; ctx only stops this caller waiting.
+func (c *Client) refreshStale(ctx context.Context, stale string) error {
+	c.flightMu.Lock()
+	call := c.refreshing
+	if call == nil {
+		if c.currentConfig().AccessToken != stale {
+			// Refreshed since the caller looked, here or by another
+			// process (see reloadConfig).
+			c.flightMu.Unlock()
+			return nil
+		}
+		call = &refreshCall{done: make(chan struct{})}
+		c.refreshing = call
+		go func() {
+			call.err = c.RefreshAccessTokenContext(context.WithoutCancel(ctx))
+			c.flightMu.Lock()
+			c.refreshing = nil
+			c.flightMu.Unlock()
+			close(call.done)
+		}()
+	}
+	c.flightMu.Unlock()
+
+	select {
+	case <-call.done:
+		return call.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,128 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ravi-technologies/sunday-cli/internal/config"
+)
+
+// TestStartTokenRefresher verifies that a token close to expiry is renewed
+// in the background without any request being made.
+func TestStartTokenRefresher(t *testing.T) {
+	var refreshes atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != PathTokenRefresh {
+			t.Errorf("unexpected request to %s", r.URL.Path)
+		}
+		refreshes.Add(1)
+		w.Write([]byte(`{"access":"fresh","refresh":"rotated"}`))
+	}))
+	defer server.Close()
+
+	client := NewClientForURL(server.URL, &config.Config{
+		AccessToken:  "stale",
+		RefreshToken: "refresh",
+		ExpiresAt:    time.Now().Add(time.Second),
+	}, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	client.StartTokenRefresher(ctx)
+
+	deadline := time.Now().Add(2 * time.Second)
+	for client.currentConfig().AccessToken != "fresh" {
+		if time.Now().After(deadline) {
+			t.Fatal("access token was not refreshed in the background")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	cancel()
+
+	if got := client.currentConfig().RefreshToken; got != "rotated" {
+		t.Errorf("RefreshToken = %q, want rotated", got)
+	}
+	// The renewed token is good for minutes, so there is no second refresh.
+	time.Sleep(50 * time.Millisecond)
+	if n := refreshes.Load(); n != 1 {
+		t.Errorf("refreshes = %d, want 1", n)
+	}
+}
+
+// TestStartTokenRefresher_NoRefreshToken verifies that the refresher exits
+// when there is nothing to refresh with.
+func TestStartTokenRefresher_NoRefreshToken(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request to %s", r.URL.Path)
+	}))
+	defer server.Close()
+
+	client := NewClientForURL(server.URL, &config.Config{AccessToken: "token"}, nil)
+	client.StartTokenRefresher(context.Background())
+	time.Sleep(50 * time.Millisecond)
+}
+
+// TestRefresh_Singleflight verifies that concurrent requests finding the
+// token expired share one refresh, so the rotated refresh token is never
+// reused.
+func TestRefresh_Singleflight(t *testing.T) {
+	var refreshes atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != PathTokenRefresh {
+			if r.Header.Get("Authorization") != "Bearer fresh" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.Write([]byte(`{}`))
+			return
+		}
+		// A refresh token is only good once.
+		if refreshes.Add(1) > 1 {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"code":"token_not_valid"}`))
+			return
+		}
+		time.Sleep(20 * time.Millisecond)
+		w.Write([]byte(`{"access":"fresh","refresh":"rotated"}`))
+	}))
+	defer server.Close()
+
+	client := NewClientForURL(server.URL, &config.Config{
+		AccessToken:  "expired",
+		RefreshToken: "refresh",
+		ExpiresAt:    time.Now().Add(-time.Minute),
+	}, nil)
+
+	errs := make(chan error, 10)
+	for range cap(errs) {
+		go func() {
+			errs <- client.doAuthenticatedRequest(http.MethodGet, "/api/me/", nil, nil)
+		}()
+	}
+	for range cap(errs) {
+		if err := <-errs; err != nil {
+			t.Errorf("doAuthenticatedRequest() error = %v", err)
+		}
+	}
+	if n := refreshes.Load(); n != 1 {
+		t.Errorf("refreshes = %d, want 1", n)
+	}
+}
+
+// TestRefreshStale_AlreadyRefreshed verifies that a caller holding a
+// token that has since been replaced doesn't refresh again.
+func TestRefreshStale_AlreadyRefreshed(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request to %s", r.URL.Path)
+	}))
+	defer server.Close()
+
+	client := NewClientForURL(server.URL, &config.Config{AccessToken: "new", RefreshToken: "refresh"}, nil)
+	if err := client.refreshStale(context.Background(), "old"); err != nil {
+		t.Errorf("refreshStale() error = %v", err)
+	}
+}
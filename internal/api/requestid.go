@@ -0,0 +1,33 @@
+package api
+
+import (
+	"crypto/rand"
+	"fmt"
+	"net/http"
+)
+
+// HeaderRequestID carries the ID of a request. The client sends one with
+// every request so the server's logs can be matched to it, and the server
+// may answer with its own.
+const HeaderRequestID = "X-Request-ID"
+
+// newRequestID returns a random UUID (version 4) to identify a request.
+func newRequestID() string {
+	var b [16]byte
+	rand.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+// responseRequestID returns the ID of the request resp answers: the one
+// the server assigned, or else the one the client sent.
+func responseRequestID(resp *http.Response) string {
+	if id := resp.Header.Get(HeaderRequestID); id != "" {
+		return id
+	}
+	if resp.Request != nil {
+		return resp.Request.Header.Get(HeaderRequestID)
+	}
+	return ""
+}
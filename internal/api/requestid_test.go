@@ -0,0 +1,63 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+)
+
+// TestRequestID verifies that every call sends a fresh request ID, kept
+// across its retries.
+func TestRequestID(t *testing.T) {
+	stubSleep(t)
+	var ids []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ids = append(ids, r.Header.Get(HeaderRequestID))
+		if len(ids) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	client := newTestClient(server.URL)
+	client.retry = retryPolicy{max: 1}
+	for range 2 {
+		resp, err := client.doRequest(http.MethodGet, "/test", nil, false)
+		if err != nil {
+			t.Fatalf("doRequest() error = %v", err)
+		}
+		resp.Body.Close()
+	}
+
+	uuid := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+	if len(ids) != 3 || !uuid.MatchString(ids[0]) {
+		t.Fatalf("request IDs = %q, want 3 UUIDs", ids)
+	}
+	if ids[0] != ids[1] {
+		t.Errorf("retry sent ID %s, want the original %s", ids[1], ids[0])
+	}
+	if ids[2] == ids[0] {
+		t.Errorf("second call reused request ID %s", ids[2])
+	}
+}
+
+// TestRequestID_InAPIError verifies that an API error names the request
+// ID the server assigned.
+func TestRequestID_InAPIError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(HeaderRequestID, "srv-42")
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	err := newTestClient(server.URL).doAuthenticatedRequest(http.MethodGet, "/test", nil, nil)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.RequestID != "srv-42" {
+		t.Fatalf("error = %v, want an APIError with request ID srv-42", err)
+	}
+	if got := apiErr.Error(); got != "API error (status 400):  (request ID srv-42)" {
+		t.Errorf("Error() = %q", got)
+	}
+}
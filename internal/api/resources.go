@@ -1,6 +1,7 @@
 package api
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"net/url"
@@ -9,8 +10,13 @@ import (
 // GetPhone fetches the user's assigned Sunday phone number.
 // Returns the first phone number associated with the authenticated user.
 func (c *Client) GetPhone() (*SundayPhone, error) {
+	return c.GetPhoneContext(context.Background())
+}
+
+// GetPhoneContext is GetPhone with a context that cancels the request.
+func (c *Client) GetPhoneContext(ctx context.Context) (*SundayPhone, error) {
 	var result []SundayPhone
-	if err := c.doAuthenticatedRequest(http.MethodGet, PathPhone, nil, &result); err != nil {
+	if err := c.doAuthenticatedRequestContext(ctx, http.MethodGet, PathPhone, nil, &result); err != nil {
 		return nil, err
 	}
 
@@ -24,8 +30,13 @@ func (c *Client) GetPhone() (*SundayPhone, error) {
 // GetEmail fetches the user's assigned Sunday email address.
 // Returns the first email address associated with the authenticated user.
 func (c *Client) GetEmail() (*SundayEmail, error) {
+	return c.GetEmailContext(context.Background())
+}
+
+// GetEmailContext is GetEmail with a context that cancels the request.
+func (c *Client) GetEmailContext(ctx context.Context) (*SundayEmail, error) {
 	var result []SundayEmail
-	if err := c.doAuthenticatedRequest(http.MethodGet, PathEmail, nil, &result); err != nil {
+	if err := c.doAuthenticatedRequestContext(ctx, http.MethodGet, PathEmail, nil, &result); err != nil {
 		return nil, err
 	}
 
@@ -36,10 +47,16 @@ func (c *Client) GetEmail() (*SundayEmail, error) {
 	return &result[0], nil
 }
 
-// GetOwner fetches the account owner's profile information.
+// GetOwner fetches the account owner's profile information. As it needs
+// a valid session, it also confirms the server still accepts the token.
 func (c *Client) GetOwner() (*Owner, error) {
+	return c.GetOwnerContext(context.Background())
+}
+
+// GetOwnerContext is GetOwner with a context that cancels the request.
+func (c *Client) GetOwnerContext(ctx context.Context) (*Owner, error) {
 	var result Owner
-	if err := c.doAuthenticatedRequest(http.MethodGet, PathOwner, nil, &result); err != nil {
+	if err := c.doAuthenticatedRequestContext(ctx, http.MethodGet, PathOwner, nil, &result); err != nil {
 		return nil, err
 	}
 
@@ -48,6 +65,12 @@ func (c *Client) GetOwner() (*Owner, error) {
 
 // ListSMSMessages fetches all SMS messages (flat list, not grouped by conversation).
 func (c *Client) ListSMSMessages(unreadOnly bool) ([]SundayPhoneMessage, error) {
+	return c.ListSMSMessagesContext(context.Background(), unreadOnly)
+}
+
+// ListSMSMessagesContext is ListSMSMessages with a context that cancels
+// the request.
+func (c *Client) ListSMSMessagesContext(ctx context.Context, unreadOnly bool) ([]SundayPhoneMessage, error) {
 	params := url.Values{}
 	if unreadOnly {
 		params.Set("is_read", "false")
@@ -59,19 +82,53 @@ func (c *Client) ListSMSMessages(unreadOnly bool) ([]SundayPhoneMessage, error)
 	}
 
 	var result []SundayPhoneMessage
-	if err := c.doAuthenticatedRequest(http.MethodGet, path, nil, &result); err != nil {
+	if err := c.doAuthenticatedRequestContext(ctx, http.MethodGet, path, nil, &result); err != nil {
 		return nil, err
 	}
 
 	return result, nil
 }
 
+// ListSMSMessagesPage fetches one page of SMS messages.
+func (c *Client) ListSMSMessagesPage(ctx context.Context, unreadOnly bool, opts PageOptions) (*Page[SundayPhoneMessage], error) {
+	params := url.Values{}
+	if unreadOnly {
+		params.Set("is_read", "false")
+	}
+	return listPage[SundayPhoneMessage](ctx, c, PathMessages, params, opts)
+}
+
 // GetSMSMessage fetches a specific SMS message by ID.
 func (c *Client) GetSMSMessage(messageID string) (*SundayPhoneMessage, error) {
+	return c.GetSMSMessageContext(context.Background(), messageID)
+}
+
+// GetSMSMessageContext is GetSMSMessage with a context that cancels the
+// request.
+func (c *Client) GetSMSMessageContext(ctx context.Context, messageID string) (*SundayPhoneMessage, error) {
+	path := PathMessages + messageID + "/"
+
+	var result SundayPhoneMessage
+	if err := c.doAuthenticatedRequestContext(ctx, http.MethodGet, path, nil, &result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// UpdateSMSMessage partially updates an SMS message by ID, e.g. to store
+// its body re-encrypted after a PIN change.
+func (c *Client) UpdateSMSMessage(messageID string, fields map[string]interface{}) (*SundayPhoneMessage, error) {
+	return c.UpdateSMSMessageContext(context.Background(), messageID, fields)
+}
+
+// UpdateSMSMessageContext is UpdateSMSMessage with a context that cancels
+// the request.
+func (c *Client) UpdateSMSMessageContext(ctx context.Context, messageID string, fields map[string]interface{}) (*SundayPhoneMessage, error) {
 	path := PathMessages + messageID + "/"
 
 	var result SundayPhoneMessage
-	if err := c.doAuthenticatedRequest(http.MethodGet, path, nil, &result); err != nil {
+	if err := c.doAuthenticatedRequestContext(ctx, http.MethodPatch, path, fields, &result); err != nil {
 		return nil, err
 	}
 
@@ -80,6 +137,12 @@ func (c *Client) GetSMSMessage(messageID string) (*SundayPhoneMessage, error) {
 
 // ListEmailMessages fetches all email messages (flat list, not grouped by thread).
 func (c *Client) ListEmailMessages(unreadOnly bool) ([]SundayEmailMessage, error) {
+	return c.ListEmailMessagesContext(context.Background(), unreadOnly)
+}
+
+// ListEmailMessagesContext is ListEmailMessages with a context that
+// cancels the request.
+func (c *Client) ListEmailMessagesContext(ctx context.Context, unreadOnly bool) ([]SundayEmailMessage, error) {
 	params := url.Values{}
 	if unreadOnly {
 		params.Set("is_read", "false")
@@ -91,19 +154,53 @@ func (c *Client) ListEmailMessages(unreadOnly bool) ([]SundayEmailMessage, error
 	}
 
 	var result []SundayEmailMessage
-	if err := c.doAuthenticatedRequest(http.MethodGet, path, nil, &result); err != nil {
+	if err := c.doAuthenticatedRequestContext(ctx, http.MethodGet, path, nil, &result); err != nil {
 		return nil, err
 	}
 
 	return result, nil
 }
 
+// ListEmailMessagesPage fetches one page of email messages.
+func (c *Client) ListEmailMessagesPage(ctx context.Context, unreadOnly bool, opts PageOptions) (*Page[SundayEmailMessage], error) {
+	params := url.Values{}
+	if unreadOnly {
+		params.Set("is_read", "false")
+	}
+	return listPage[SundayEmailMessage](ctx, c, PathEmailMessages, params, opts)
+}
+
 // GetEmailMessage fetches a specific email message by ID.
 func (c *Client) GetEmailMessage(messageID string) (*SundayEmailMessage, error) {
+	return c.GetEmailMessageContext(context.Background(), messageID)
+}
+
+// GetEmailMessageContext is GetEmailMessage with a context that cancels
+// the request.
+func (c *Client) GetEmailMessageContext(ctx context.Context, messageID string) (*SundayEmailMessage, error) {
+	path := PathEmailMessages + messageID + "/"
+
+	var result SundayEmailMessage
+	if err := c.doAuthenticatedRequestContext(ctx, http.MethodGet, path, nil, &result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// UpdateEmailMessage partially updates an email message by ID, e.g. to
+// store its content re-encrypted after a PIN change.
+func (c *Client) UpdateEmailMessage(messageID string, fields map[string]interface{}) (*SundayEmailMessage, error) {
+	return c.UpdateEmailMessageContext(context.Background(), messageID, fields)
+}
+
+// UpdateEmailMessageContext is UpdateEmailMessage with a context that
+// cancels the request.
+func (c *Client) UpdateEmailMessageContext(ctx context.Context, messageID string, fields map[string]interface{}) (*SundayEmailMessage, error) {
 	path := PathEmailMessages + messageID + "/"
 
 	var result SundayEmailMessage
-	if err := c.doAuthenticatedRequest(http.MethodGet, path, nil, &result); err != nil {
+	if err := c.doAuthenticatedRequestContext(ctx, http.MethodPatch, path, fields, &result); err != nil {
 		return nil, err
 	}
 
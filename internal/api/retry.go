@@ -0,0 +1,111 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"time"
+)
+
+// MaxRetries is how many times clients retry an idempotent request that
+// failed transiently (a network error, timeout, 502, 503 or 504). The CLI
+// sets it for --retries; clients read it when created.
+var MaxRetries = DefaultMaxRetries
+
+// RetryDelay is the wait before the first retry. It doubles for each
+// later one, up to MaxRetryDelay. The CLI sets it for --retry-delay.
+var RetryDelay = DefaultRetryDelay
+
+// retryPolicy is how a client retries failed requests. The zero value
+// never retries.
+type retryPolicy struct {
+	max   int
+	delay time.Duration
+}
+
+// currentRetryPolicy returns the policy set by MaxRetries and RetryDelay.
+func currentRetryPolicy() retryPolicy {
+	return retryPolicy{max: MaxRetries, delay: RetryDelay}
+}
+
+// backoff returns how long to wait before retry n (counting from 0): the
+// delay doubled n times and capped, with jitter so that many clients
+// failing together don't retry in lockstep.
+func (p retryPolicy) backoff(n int) time.Duration {
+	d := p.delay
+	for range n {
+		if d >= MaxRetryDelay {
+			break
+		}
+		d *= 2
+	}
+	d = min(d, MaxRetryDelay)
+	return d/2 + rand.N(d/2+1)
+}
+
+// retryWait returns how long to wait before retrying a request that ended
+// with resp and err, and false if it shouldn't be retried. A 429 means the
+// request wasn't processed, so it is retried whatever the method, after
+// the server's Retry-After; a wait longer than MaxRateLimitWait gives up.
+func (c *Client) retryWait(method string, resp *http.Response, err error, attempt int) (time.Duration, bool) {
+	if err == nil && resp.StatusCode == http.StatusTooManyRequests {
+		wait, ok := retryAfter(resp.Header, time.Now())
+		if !ok {
+			wait = c.retry.backoff(attempt)
+		}
+		if wait > MaxRateLimitWait {
+			return 0, false
+		}
+		slog.Info("api rate limited, waiting to retry", "path", resp.Request.URL.Path, "wait", wait)
+		return wait, true
+	}
+	if !retryable(method, resp, err) {
+		return 0, false
+	}
+	return c.retry.backoff(attempt), true
+}
+
+// retryable reports whether a request that ended with resp and err may be
+// sent again. Only idempotent methods are retried, so a request the server
+// did act on is never repeated with a different effect.
+func retryable(method string, resp *http.Response, err error) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+	default:
+		return false
+	}
+	if err != nil {
+		return !errors.Is(err, ErrCircuitOpen) && !errors.Is(err, ErrPinMismatch) && !errors.Is(err, ErrOffline)
+	}
+	switch resp.StatusCode {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// discard drains and closes the body of a response that is being retried,
+// so its connection can be reused.
+func discard(resp *http.Response) {
+	if resp == nil {
+		return
+	}
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	resp.Body.Close()
+}
+
+// sleep waits for d, returning early with ctx's error if it is done.
+// Tests replace it.
+var sleep = func(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
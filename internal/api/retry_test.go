@@ -0,0 +1,182 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// stubSleep replaces sleep for the duration of a test and returns the
+// waits it was asked for.
+func stubSleep(t *testing.T) *[]time.Duration {
+	t.Helper()
+	var waits []time.Duration
+	orig := sleep
+	sleep = func(ctx context.Context, d time.Duration) error {
+		waits = append(waits, d)
+		return ctx.Err()
+	}
+	t.Cleanup(func() { sleep = orig })
+	return &waits
+}
+
+// failingServer answers the first failures requests with status and the
+// rest with 200, counting every request.
+func failingServer(t *testing.T, failures int32, status int) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) <= failures {
+			w.WriteHeader(status)
+			return
+		}
+		w.Write([]byte(`{}`))
+	}))
+	t.Cleanup(server.Close)
+	return server, &calls
+}
+
+// TestRetry_Transient verifies that idempotent requests are retried after
+// a 503, with growing backoff, until they succeed.
+func TestRetry_Transient(t *testing.T) {
+	waits := stubSleep(t)
+	server, calls := failingServer(t, 2, http.StatusServiceUnavailable)
+
+	client := newTestClient(server.URL)
+	client.retry = retryPolicy{max: 3, delay: 100 * time.Millisecond}
+
+	resp, err := client.doRequest(http.MethodGet, "/test", nil, false)
+	if err != nil {
+		t.Fatalf("doRequest() error = %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || calls.Load() != 3 {
+		t.Errorf("status = %d after %d calls, want 200 after 3", resp.StatusCode, calls.Load())
+	}
+	if len(*waits) != 2 {
+		t.Fatalf("waited %d times, want 2", len(*waits))
+	}
+	if w := (*waits)[0]; w < 50*time.Millisecond || w > 100*time.Millisecond {
+		t.Errorf("first wait = %s, want between 50ms and 100ms", w)
+	}
+	if w := (*waits)[1]; w < 100*time.Millisecond || w > 200*time.Millisecond {
+		t.Errorf("second wait = %s, want between 100ms and 200ms", w)
+	}
+}
+
+// TestRetry_GivesUp verifies that the last failure is returned once the
+// retries are used up.
+func TestRetry_GivesUp(t *testing.T) {
+	stubSleep(t)
+	server, calls := failingServer(t, 10, http.StatusBadGateway)
+
+	client := newTestClient(server.URL)
+	client.retry = retryPolicy{max: 2, delay: time.Millisecond}
+
+	resp, err := client.doRequest(http.MethodGet, "/test", nil, false)
+	if err != nil {
+		t.Fatalf("doRequest() error = %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadGateway || calls.Load() != 3 {
+		t.Errorf("status = %d after %d calls, want 502 after 3", resp.StatusCode, calls.Load())
+	}
+}
+
+// TestRetry_NotRetried verifies that POSTs, other errors and clients
+// without a policy are sent once.
+func TestRetry_NotRetried(t *testing.T) {
+	stubSleep(t)
+	tests := []struct {
+		name   string
+		method string
+		status int
+		policy retryPolicy
+	}{
+		{"post", http.MethodPost, http.StatusServiceUnavailable, retryPolicy{max: 2, delay: time.Millisecond}},
+		{"client error", http.MethodGet, http.StatusNotFound, retryPolicy{max: 2, delay: time.Millisecond}},
+		{"internal error", http.MethodGet, http.StatusInternalServerError, retryPolicy{max: 2, delay: time.Millisecond}},
+		{"no policy", http.MethodGet, http.StatusServiceUnavailable, retryPolicy{}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			server, calls := failingServer(t, 10, tc.status)
+			client := newTestClient(server.URL)
+			client.retry = tc.policy
+
+			resp, err := client.doRequest(tc.method, "/test", nil, false)
+			if err != nil {
+				t.Fatalf("doRequest() error = %v", err)
+			}
+			resp.Body.Close()
+			if calls.Load() != 1 {
+				t.Errorf("server called %d times, want 1", calls.Load())
+			}
+		})
+	}
+}
+
+// TestRetryPolicy_Backoff verifies the backoff is capped at MaxRetryDelay
+// and that a zero delay retries at once.
+func TestRetryPolicy_Backoff(t *testing.T) {
+	p := retryPolicy{max: 100, delay: time.Second}
+	for n := range 100 {
+		if d := p.backoff(n); d <= 0 || d > MaxRetryDelay {
+			t.Fatalf("backoff(%d) = %s, want within (0, %s]", n, d, MaxRetryDelay)
+		}
+	}
+	if d := (retryPolicy{max: 1}).backoff(3); d != 0 {
+		t.Errorf("backoff with no delay = %s, want 0", d)
+	}
+}
+
+// TestRetry_RateLimited verifies that a 429 is retried after Retry-After,
+// even for a POST, and that a wait past MaxRateLimitWait gives up.
+func TestRetry_RateLimited(t *testing.T) {
+	waits := stubSleep(t)
+	retryAfterHeader := "2"
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.Header().Set("Retry-After", retryAfterHeader)
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	client := newTestClient(server.URL)
+	client.retry = retryPolicy{max: 2, delay: time.Millisecond}
+
+	resp, err := client.doRequest(http.MethodPost, "/test", map[string]string{"k": "v"}, false)
+	if err != nil {
+		t.Fatalf("doRequest() error = %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || calls.Load() != 2 {
+		t.Errorf("status = %d after %d calls, want 200 after 2", resp.StatusCode, calls.Load())
+	}
+	if len(*waits) != 1 || (*waits)[0] != 2*time.Second {
+		t.Errorf("waits = %v, want [2s]", *waits)
+	}
+
+	calls.Store(0)
+	retryAfterHeader = "3600"
+	err = client.doAuthenticatedRequest(http.MethodGet, "/test", nil, nil)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusTooManyRequests || apiErr.RetryAfter != time.Hour {
+		t.Fatalf("doAuthenticatedRequest() error = %v, want 429 with RetryAfter 1h", err)
+	}
+	if calls.Load() != 1 {
+		t.Errorf("server called %d times, want 1 when Retry-After is too long", calls.Load())
+	}
+	if !strings.Contains(err.Error(), "retry after 1h") {
+		t.Errorf("error = %q, want it to say when to retry", err)
+	}
+}
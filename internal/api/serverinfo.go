@@ -0,0 +1,73 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+)
+
+// Optional features a server may report in ServerInfo.Features.
+const (
+	// FeatureInboxEvents is the live inbox stream (see SubscribeInbox).
+	FeatureInboxEvents = "inbox_events"
+
+	// FeaturePagination is paginated list endpoints (see PageOptions).
+	FeaturePagination = "pagination"
+
+	// FeatureSearch is searching messages on the server.
+	FeatureSearch = "search"
+
+	// FeatureVaultSharing is sharing password entries (see SharePassword).
+	// A shared entry's fields are encrypted to several keys in the format
+	// described at crypto.RecipientsPrefix, which only servers reporting
+	// this feature, and their dashboards, can read: it must not be written
+	// to any other.
+	FeatureVaultSharing = "vault_sharing"
+)
+
+// Supports reports whether the server supports feature, one of the
+// Feature constants.
+func (i *ServerInfo) Supports(feature string) bool {
+	return slices.Contains(i.Features, feature)
+}
+
+// GetServerInfo returns the server's version and the optional features it
+// supports, so callers can adapt to what it offers rather than fail on a
+// missing endpoint. A server too old to describe itself reports no version
+// and no features. The result is fetched once per client.
+func (c *Client) GetServerInfo() (*ServerInfo, error) {
+	return c.GetServerInfoContext(context.Background())
+}
+
+// GetServerInfoContext is GetServerInfo with a context that cancels the
+// request.
+func (c *Client) GetServerInfoContext(ctx context.Context) (*ServerInfo, error) {
+	c.mu.RLock()
+	info := c.serverInfo
+	c.mu.RUnlock()
+	if info != nil {
+		return info, nil
+	}
+
+	// The endpoint is public, so this works before logging in.
+	resp, err := c.doRequestContext(ctx, http.MethodGet, PathMeta, nil, false)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	info = &ServerInfo{}
+	err = c.parseResponse(resp, info)
+	if errors.Is(err, ErrNotFound) {
+		info, err = &ServerInfo{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	c.serverInfo = info
+	c.mu.Unlock()
+	return info, nil
+}
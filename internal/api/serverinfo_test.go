@@ -0,0 +1,54 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestGetServerInfo verifies that the server's description is decoded and
+// fetched only once per client.
+func TestGetServerInfo(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Path != PathMeta {
+			t.Errorf("path = %s, want %s", r.URL.Path, PathMeta)
+		}
+		if auth := r.Header.Get("Authorization"); auth != "" {
+			t.Errorf("Authorization = %q, want none", auth)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"version": "2.4.0", "features": ["pagination", "inbox_events"]}`))
+	}))
+	defer server.Close()
+
+	client := newTestClient(server.URL)
+	for range 2 {
+		info, err := client.GetServerInfo()
+		if err != nil {
+			t.Fatalf("GetServerInfo() error = %v", err)
+		}
+		if info.Version != "2.4.0" || !info.Supports(FeatureInboxEvents) || info.Supports(FeatureSearch) {
+			t.Errorf("GetServerInfo() = %+v", info)
+		}
+	}
+	if requests != 1 {
+		t.Errorf("requests = %d, want 1", requests)
+	}
+}
+
+// TestGetServerInfo_Legacy verifies that a server without the endpoint is
+// described as supporting no optional features, rather than failing.
+func TestGetServerInfo_Legacy(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	info, err := newTestClient(server.URL).GetServerInfo()
+	if err != nil {
+		t.Fatalf("GetServerInfo() error = %v", err)
+	}
+	if info.Version != "" || info.Supports(FeaturePagination) {
+		t.Errorf("GetServerInfo() = %+v, want no version or features", info)
+	}
+}
@@ -0,0 +1,36 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+)
+
+// ListSessions returns the user's active CLI sessions, including the one
+// making the request.
+func (c *Client) ListSessions() ([]Session, error) {
+	return c.ListSessionsContext(context.Background())
+}
+
+// ListSessionsContext is ListSessions with a context that cancels the
+// request.
+func (c *Client) ListSessionsContext(ctx context.Context) ([]Session, error) {
+	var sessions []Session
+	if err := c.doAuthenticatedRequestContext(ctx, http.MethodGet, PathSessions, nil, &sessions); err != nil {
+		return nil, err
+	}
+	return sessions, nil
+}
+
+// RevokeSession revokes a CLI session by ID, invalidating its refresh
+// token. The machine holding it must log in again.
+func (c *Client) RevokeSession(id string) error {
+	return c.RevokeSessionContext(context.Background(), id)
+}
+
+// RevokeSessionContext is RevokeSession with a context that cancels the
+// request.
+func (c *Client) RevokeSessionContext(ctx context.Context, id string) error {
+	path := PathSessions + url.PathEscape(id) + "/"
+	return c.doAuthenticatedRequestContext(ctx, http.MethodDelete, path, nil, nil)
+}
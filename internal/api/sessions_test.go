@@ -0,0 +1,59 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ravi-technologies/sunday-cli/internal/config"
+)
+
+// TestListSessions verifies that sessions are fetched and decoded.
+func TestListSessions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || r.URL.Path != PathSessions {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`[{"id":"s1","device_name":"laptop","current":true,"last_used_dt":"2026-01-02T03:04:05Z"},{"id":"s2","device_name":"ci-runner"}]`))
+	}))
+	defer server.Close()
+
+	cfg := &config.Config{AccessToken: "access", RefreshToken: "refresh", ExpiresAt: time.Now().Add(time.Hour)}
+	client := NewClientForURL(server.URL, cfg, nil)
+
+	sessions, err := client.ListSessions()
+	if err != nil {
+		t.Fatalf("ListSessions() error = %v", err)
+	}
+	if len(sessions) != 2 {
+		t.Fatalf("ListSessions() returned %d sessions, want 2", len(sessions))
+	}
+	if !sessions[0].Current || sessions[0].DeviceName != "laptop" || sessions[0].LastUsedDt.Year() != 2026 {
+		t.Errorf("sessions[0] = %+v", sessions[0])
+	}
+	if sessions[1].Current {
+		t.Errorf("sessions[1].Current = true, want false")
+	}
+}
+
+// TestRevokeSession verifies that revoking deletes the session by ID.
+func TestRevokeSession(t *testing.T) {
+	var got string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Method + " " + r.URL.Path
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	cfg := &config.Config{AccessToken: "access", RefreshToken: "refresh", ExpiresAt: time.Now().Add(time.Hour)}
+	client := NewClientForURL(server.URL, cfg, nil)
+
+	if err := client.RevokeSession("s2"); err != nil {
+		t.Fatalf("RevokeSession() error = %v", err)
+	}
+	if want := "DELETE " + PathSessions + "s2/"; got != want {
+		t.Errorf("request = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,37 @@
+package api
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Request signing headers. When the backend issues a per-device signing
+// secret at login, every request carries a timestamp and an HMAC over the
+// request, so a captured bearer token can't be replayed on its own.
+const (
+	HeaderTimestamp = "X-Sunday-Timestamp"
+	HeaderSignature = "X-Sunday-Signature"
+)
+
+// signRequest adds the timestamp and signature headers to req. The
+// signature is HMAC-SHA256, keyed by secret, over:
+//
+//	METHOD \n PATH?QUERY \n TIMESTAMP \n hex(SHA256(body))
+func signRequest(req *http.Request, body []byte, secret string, now time.Time) {
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	req.Header.Set(HeaderTimestamp, timestamp)
+	req.Header.Set(HeaderSignature, "v1="+requestSignature(req.Method, req.URL.RequestURI(), timestamp, body, secret))
+}
+
+// requestSignature computes the hex-encoded v1 signature.
+func requestSignature(method, uri, timestamp string, body []byte, secret string) string {
+	bodyHash := sha256.Sum256(body)
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(method + "\n" + uri + "\n" + timestamp + "\n" + hex.EncodeToString(bodyHash[:])))
+	return hex.EncodeToString(mac.Sum(nil))
+}
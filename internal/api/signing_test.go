@@ -0,0 +1,67 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ravi-technologies/sunday-cli/internal/config"
+)
+
+// TestRequestSignature verifies the signature against a known vector so
+// the wire format can't drift unnoticed.
+func TestRequestSignature(t *testing.T) {
+	got := requestSignature(http.MethodPost, "/api/vault/?x=1", "1700000000", []byte(`{"a":1}`), "secret")
+	want := "7521c6c5bd81b6dff3e4a8ddec446b7131f6ef0a60ffce66f1e4f3d2197ec330"
+	if got != want {
+		t.Fatalf("requestSignature() = %q, want %q", got, want)
+	}
+
+	for name, other := range map[string]string{
+		"method":    requestSignature(http.MethodPut, "/api/vault/?x=1", "1700000000", []byte(`{"a":1}`), "secret"),
+		"path":      requestSignature(http.MethodPost, "/api/vault/?x=2", "1700000000", []byte(`{"a":1}`), "secret"),
+		"timestamp": requestSignature(http.MethodPost, "/api/vault/?x=1", "1700000001", []byte(`{"a":1}`), "secret"),
+		"body":      requestSignature(http.MethodPost, "/api/vault/?x=1", "1700000000", []byte(`{"a":2}`), "secret"),
+		"secret":    requestSignature(http.MethodPost, "/api/vault/?x=1", "1700000000", []byte(`{"a":1}`), "other"),
+	} {
+		if other == got {
+			t.Errorf("changing the %s did not change the signature", name)
+		}
+	}
+}
+
+// TestDoRequest_Signing verifies that requests are signed only when the
+// config holds a signing secret.
+func TestDoRequest_Signing(t *testing.T) {
+	var headers http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers = r.Header.Clone()
+	}))
+	defer server.Close()
+
+	client := newTestClient(server.URL)
+	resp, err := client.doRequest(http.MethodGet, "/test", nil, true)
+	if err != nil {
+		t.Fatalf("doRequest() error = %v", err)
+	}
+	resp.Body.Close()
+	if headers.Get(HeaderSignature) != "" {
+		t.Error("unsigned client sent a signature header")
+	}
+
+	client.config = &config.Config{AccessToken: "token", SigningSecret: "device-secret"}
+	resp, err = client.doRequest(http.MethodPost, "/test", map[string]string{"k": "v"}, true)
+	if err != nil {
+		t.Fatalf("doRequest() error = %v", err)
+	}
+	resp.Body.Close()
+
+	ts := headers.Get(HeaderTimestamp)
+	if ts == "" {
+		t.Fatal("signed request has no timestamp header")
+	}
+	want := "v1=" + requestSignature(http.MethodPost, "/test", ts, []byte(`{"k":"v"}`), "device-secret")
+	if got := headers.Get(HeaderSignature); got != want {
+		t.Errorf("%s = %q, want %q", HeaderSignature, got, want)
+	}
+}
@@ -0,0 +1,62 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Timeout overrides how long every client waits for a request, including
+// reading the response. The CLI sets it for --timeout. Zero means use the
+// api.timeout config setting, or DefaultTimeout.
+var Timeout time.Duration
+
+// timeoutKey is the context key under which WithTimeout stores a
+// per-call timeout.
+type timeoutKey struct{}
+
+// WithTimeout returns a context whose requests use timeout d instead of
+// the client's, e.g. a longer one for a large export. Zero means no
+// timeout; cancelling the context still stops the request.
+func WithTimeout(ctx context.Context, d time.Duration) context.Context {
+	return context.WithValue(ctx, timeoutKey{}, d)
+}
+
+// SetTimeout changes how long the client's requests may take, including
+// reading the response. Zero means no timeout.
+func (c *Client) SetTimeout(d time.Duration) {
+	c.httpClient.Timeout = d
+}
+
+// httpClientFor returns the http.Client to send a request made with ctx:
+// the client's own, or a copy with the timeout that applies instead.
+func (c *Client) httpClientFor(ctx context.Context) (*http.Client, error) {
+	d, ok, err := c.timeout(ctx)
+	if err != nil || !ok || d == c.httpClient.Timeout {
+		return c.httpClient, err
+	}
+	hc := *c.httpClient
+	hc.Timeout = d
+	return &hc, nil
+}
+
+// timeout returns the timeout for a request made with ctx, from
+// WithTimeout, Timeout or the api.timeout setting in that order. ok is
+// false if none is set.
+func (c *Client) timeout(ctx context.Context) (d time.Duration, ok bool, err error) {
+	if d, ok := ctx.Value(timeoutKey{}).(time.Duration); ok {
+		return d, true, nil
+	}
+	if Timeout > 0 {
+		return Timeout, true, nil
+	}
+	if s := c.currentConfig().API.Timeout; s != "" {
+		d, err := time.ParseDuration(s)
+		if err != nil || d <= 0 {
+			return 0, false, fmt.Errorf("invalid api.timeout %q: want a positive duration such as 2m", s)
+		}
+		return d, true, nil
+	}
+	return 0, false, nil
+}
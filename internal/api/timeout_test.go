@@ -0,0 +1,78 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestClientTimeout verifies where a request's timeout comes from:
+// WithTimeout, then Timeout, then the api.timeout setting.
+func TestClientTimeout(t *testing.T) {
+	orig := Timeout
+	t.Cleanup(func() { Timeout = orig })
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		global  time.Duration
+		setting string
+		want    time.Duration
+		wantOK  bool
+		wantErr string
+	}{
+		{name: "none", ctx: context.Background()},
+		{name: "setting", ctx: context.Background(), setting: "2m", want: 2 * time.Minute, wantOK: true},
+		{name: "flag over setting", ctx: context.Background(), global: time.Minute, setting: "2m", want: time.Minute, wantOK: true},
+		{name: "per call over flag", ctx: WithTimeout(context.Background(), 5*time.Minute), global: time.Minute, want: 5 * time.Minute, wantOK: true},
+		{name: "per call without timeout", ctx: WithTimeout(context.Background(), 0), global: time.Minute, wantOK: true},
+		{name: "invalid setting", ctx: context.Background(), setting: "soon", wantErr: `invalid api.timeout "soon"`},
+		{name: "negative setting", ctx: context.Background(), setting: "-1s", wantErr: "invalid api.timeout"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			Timeout = tt.global
+			client := newTestClient("http://example.invalid")
+			client.config.API.Timeout = tt.setting
+
+			got, ok, err := client.timeout(tt.ctx)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("timeout() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || got != tt.want || ok != tt.wantOK {
+				t.Errorf("timeout() = %v, %v, %v; want %v, %v", got, ok, err, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+// TestWithTimeout_Request verifies that a per-call timeout applies to the
+// request without changing the client's own.
+func TestWithTimeout_Request(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	client := newTestClient(server.URL)
+	ctx := WithTimeout(context.Background(), 20*time.Millisecond)
+	if _, err := client.doRequestContext(ctx, http.MethodPost, "/slow", nil, false); err == nil {
+		t.Fatal("doRequestContext() error = nil, want a timeout")
+	}
+
+	resp, err := client.doRequestContext(context.Background(), http.MethodPost, "/slow", nil, false)
+	if err != nil {
+		t.Fatalf("doRequestContext() without override error = %v", err)
+	}
+	resp.Body.Close()
+	if client.httpClient.Timeout != 5*time.Second {
+		t.Errorf("client timeout = %v, want it unchanged", client.httpClient.Timeout)
+	}
+}
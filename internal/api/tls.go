@@ -0,0 +1,200 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/ravi-technologies/sunday-cli/internal/config"
+)
+
+// Environment variables for mutual TLS and certificate pinning,
+// overriding the api.client_cert, api.client_key, api.ca_bundle and
+// api.pin_sha256 config settings.
+const (
+	EnvClientCert = "SUNDAY_CLIENT_CERT"
+	EnvClientKey  = "SUNDAY_CLIENT_KEY"
+	EnvCABundle   = "SUNDAY_CA_BUNDLE"
+	EnvPinSHA256  = "SUNDAY_PIN_SHA256"
+)
+
+// ErrPinMismatch is returned (wrapped) when the server's certificate key
+// matches none of the pinned hashes. The connection is closed before any
+// request is sent.
+var ErrPinMismatch = errors.New("server certificate does not match the pinned key")
+
+// transportKey is the context key under which a client passes its own
+// transport to baseTransport.
+type transportKey struct{}
+
+// baseTransport is the innermost RoundTripper of DefaultTransport. It
+// sends each request with the transport of the client that made it, e.g.
+// one presenting a client certificate, or http.DefaultTransport. This lets
+// instrumentation wrap DefaultTransport once for every client.
+type baseTransport struct{}
+
+func (baseTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t, ok := req.Context().Value(transportKey{}).(http.RoundTripper); ok {
+		return t.RoundTrip(req)
+	}
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+// tlsFiles returns the client certificate, key and CA bundle paths from
+// the environment, falling back to cfg.
+func tlsFiles(cfg *config.Config) (cert, key, caBundle string) {
+	cert, key, caBundle = cfg.API.ClientCert, cfg.API.ClientKey, cfg.API.CABundle
+	if v := os.Getenv(EnvClientCert); v != "" {
+		cert = v
+	}
+	if v := os.Getenv(EnvClientKey); v != "" {
+		key = v
+	}
+	if v := os.Getenv(EnvCABundle); v != "" {
+		caBundle = v
+	}
+	return cert, key, caBundle
+}
+
+// newTransport returns the transport for clients configured by cfg: one
+// presenting the configured client certificate, trusting the configured CA
+// bundle, going through the configured proxy and pooling connections as
+// configured. Clients with the same settings share one transport, and so
+// reuse each other's connections.
+func newTransport(cfg *config.Config) (http.RoundTripper, error) {
+	key, err := transportSettingsFor(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	transportsMu.Lock()
+	defer transportsMu.Unlock()
+	if t, ok := transports[key]; ok {
+		return t, nil
+	}
+
+	tlsCfg, err := newTLSConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	proxy, err := proxyFunc(cfg)
+	if err != nil {
+		return nil, err
+	}
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	if tlsCfg != nil {
+		t.TLSClientConfig = tlsCfg
+	}
+	t.Proxy = proxy
+	// Every request goes to the one API host, so the per-host limit is
+	// the overall one.
+	t.MaxIdleConns, t.MaxIdleConnsPerHost = key.maxIdleConns, key.maxIdleConns
+	t.IdleConnTimeout = key.idleConnTimeout
+	t.TLSHandshakeTimeout = key.tlsHandshakeTimeout
+	transports[key] = t
+	return t, nil
+}
+
+// newTLSConfig returns a TLS config presenting the configured client
+// certificate, trusting the configured CA bundle and checking the
+// configured pins, or nil if none of them is configured.
+func newTLSConfig(cfg *config.Config) (*tls.Config, error) {
+	certFile, keyFile, caFile := tlsFiles(cfg)
+	pins, err := tlsPins(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if certFile == "" && keyFile == "" && caFile == "" && len(pins) == 0 {
+		return nil, nil
+	}
+
+	tlsCfg := &tls.Config{MinVersion: tls.VersionTLS12}
+	if certFile != "" || keyFile != "" {
+		if certFile == "" || keyFile == "" {
+			return nil, fmt.Errorf("mutual TLS needs both a client certificate and a key (%s and %s)", EnvClientCert, EnvClientKey)
+		}
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("loading client certificate: %w", err)
+		}
+		tlsCfg.Certificates = []tls.Certificate{cert}
+	}
+	if caFile != "" {
+		pem, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("reading CA bundle: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("reading CA bundle: no certificates found in %s", caFile)
+		}
+		tlsCfg.RootCAs = pool
+	}
+	if len(pins) > 0 {
+		tlsCfg.VerifyConnection = verifyPins(pins)
+	}
+	return tlsCfg, nil
+}
+
+// tlsPins returns the pinned SPKI hashes from the environment, falling
+// back to cfg. Pins are comma-separated base64 SHA-256 hashes of the
+// server's public key, optionally prefixed with "sha256/" as curl writes
+// them; listing the next key too lets the server rotate without an outage.
+func tlsPins(cfg *config.Config) ([][]byte, error) {
+	setting := cfg.API.PinSHA256
+	if v := os.Getenv(EnvPinSHA256); v != "" {
+		setting = v
+	}
+
+	var pins [][]byte
+	for pin := range strings.SplitSeq(setting, ",") {
+		pin = strings.TrimPrefix(strings.TrimSpace(pin), "sha256/")
+		if pin == "" {
+			continue
+		}
+		hash, err := base64.StdEncoding.DecodeString(pin)
+		if err != nil || len(hash) != sha256.Size {
+			return nil, fmt.Errorf("invalid api.pin_sha256 %q: want the base64 SHA-256 of the server's public key", pin)
+		}
+		pins = append(pins, hash)
+	}
+	return pins, nil
+}
+
+// verifyPins returns a check that the server's leaf certificate has one
+// of the pinned keys. It runs after the usual chain verification, so a
+// pin narrows what is trusted rather than replacing it.
+func verifyPins(pins [][]byte) func(tls.ConnectionState) error {
+	return func(cs tls.ConnectionState) error {
+		if len(cs.PeerCertificates) == 0 {
+			return ErrPinMismatch
+		}
+		hash := sha256.Sum256(cs.PeerCertificates[0].RawSubjectPublicKeyInfo)
+		for _, pin := range pins {
+			if bytes.Equal(pin, hash[:]) {
+				return nil
+			}
+		}
+		return fmt.Errorf("%w (the server key is sha256/%s)", ErrPinMismatch, base64.StdEncoding.EncodeToString(hash[:]))
+	}
+}
+
+// withTransport returns ctx carrying the client's own transport, if it
+// has one, for baseTransport.
+func (c *Client) withTransport(ctx context.Context) context.Context {
+	if c.transport == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, transportKey{}, c.transport)
+}
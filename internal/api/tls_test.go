@@ -0,0 +1,193 @@
+package api
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ravi-technologies/sunday-cli/internal/config"
+)
+
+// writeClientCert writes a self-signed client certificate and its key to
+// dir, returning their paths.
+func writeClientCert(t *testing.T, dir string) (certFile, keyFile string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "sunday-cli-test"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	certFile = filepath.Join(dir, "client.pem")
+	keyFile = filepath.Join(dir, "client.key")
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return certFile, keyFile
+}
+
+// TestNewClient_MutualTLS verifies that the configured client certificate
+// is presented and the configured CA bundle trusted.
+func TestNewClient_MutualTLS(t *testing.T) {
+	var peer string
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(r.TLS.PeerCertificates) > 0 {
+			peer = r.TLS.PeerCertificates[0].Subject.CommonName
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"device_code":"dc"}`))
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	server.StartTLS()
+	defer server.Close()
+
+	_, cleanup := withTempHome(t)
+	defer cleanup()
+	cleanupURL := withAPIBaseURL(t, server.URL)
+	defer cleanupURL()
+
+	dir := t.TempDir()
+	certFile, keyFile := writeClientCert(t, dir)
+	caFile := filepath.Join(dir, "ca.pem")
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(caFile, caPEM, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &config.Config{API: config.APISettings{ClientCert: certFile, ClientKey: keyFile, CABundle: caFile}}
+	client, err := NewClient(cfg)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	if _, err := client.RequestDeviceCode(); err != nil {
+		t.Fatalf("RequestDeviceCode() error = %v", err)
+	}
+	if peer != "sunday-cli-test" {
+		t.Errorf("server saw client certificate %q, want sunday-cli-test", peer)
+	}
+}
+
+// TestNewClient_MutualTLSErrors verifies that incomplete or unreadable
+// TLS settings fail NewClient rather than silently sending no certificate.
+func TestNewClient_MutualTLSErrors(t *testing.T) {
+	_, cleanup := withTempHome(t)
+	defer cleanup()
+	cleanupURL := withAPIBaseURL(t, "https://sunday.example")
+	defer cleanupURL()
+
+	dir := t.TempDir()
+	certFile, _ := writeClientCert(t, dir)
+	tests := []struct {
+		name string
+		api  config.APISettings
+	}{
+		{"cert without key", config.APISettings{ClientCert: certFile}},
+		{"missing cert file", config.APISettings{ClientCert: filepath.Join(dir, "nope.pem"), ClientKey: filepath.Join(dir, "nope.key")}},
+		{"CA bundle without certificates", config.APISettings{CABundle: filepath.Join(dir, "client.key")}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewClient(&config.Config{API: tt.api}); err == nil {
+				t.Error("NewClient() error = nil, want an error")
+			}
+		})
+	}
+}
+
+// TestNewClient_PinnedKey verifies that a matching pin lets requests
+// through and any other pin fails closed before a request is sent.
+func TestNewClient_PinnedKey(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"device_code":"dc"}`))
+	}))
+	defer server.Close()
+
+	_, cleanup := withTempHome(t)
+	defer cleanup()
+	cleanupURL := withAPIBaseURL(t, server.URL)
+	defer cleanupURL()
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(caFile, caPEM, 0600); err != nil {
+		t.Fatal(err)
+	}
+	hash := sha256.Sum256(server.Certificate().RawSubjectPublicKeyInfo)
+	pin := "sha256/" + base64.StdEncoding.EncodeToString(hash[:])
+	otherPin := base64.StdEncoding.EncodeToString(make([]byte, sha256.Size))
+
+	tests := []struct {
+		name    string
+		pins    string
+		wantErr bool
+	}{
+		{"matching pin", pin, false},
+		{"matching backup pin", otherPin + ", " + pin, false},
+		{"mismatched pin", otherPin, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests.Store(0)
+			client, err := NewClient(&config.Config{API: config.APISettings{CABundle: caFile, PinSHA256: tt.pins}})
+			if err != nil {
+				t.Fatalf("NewClient() error = %v", err)
+			}
+			_, err = client.RequestDeviceCode()
+			if !tt.wantErr {
+				if err != nil {
+					t.Fatalf("RequestDeviceCode() error = %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, ErrPinMismatch) {
+				t.Fatalf("RequestDeviceCode() error = %v, want ErrPinMismatch", err)
+			}
+			if !strings.Contains(err.Error(), pin) {
+				t.Errorf("error = %v, want it to name the server's key %s", err, pin)
+			}
+			if requests.Load() != 0 {
+				t.Errorf("server got %d requests, want none", requests.Load())
+			}
+		})
+	}
+
+	if _, err := NewClient(&config.Config{API: config.APISettings{PinSHA256: "not-a-hash"}}); err == nil {
+		t.Error("NewClient() with an invalid pin error = nil, want an error")
+	}
+}
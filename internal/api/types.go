@@ -3,18 +3,28 @@ package api
 import "time"
 
 // DeviceCodeRequest represents the request body for initiating the OAuth device code flow.
-// It is empty as no parameters are required to start the flow.
-type DeviceCodeRequest struct{}
+// All fields are optional.
+type DeviceCodeRequest struct {
+	// IdentityProvider names the SSO provider the verification page should
+	// pre-select, for organisations that log in through OIDC/SAML.
+	IdentityProvider string `json:"idp,omitempty"`
+
+	// Scope, if set, asks for a token limited to these space-separated
+	// scopes (e.g. "read:inbox read:passwords") instead of full access.
+	Scope string `json:"scope,omitempty"`
+}
 
 // DeviceCodeResponse contains the device code and user code returned by the server
 // when initiating the OAuth device code flow. The user must visit VerificationURI
-// and enter the UserCode to authorize the device.
+// and enter the UserCode to authorize the device. VerificationURIComplete, when
+// the server sends it, already carries the code (RFC 8628 section 3.3.1).
 type DeviceCodeResponse struct {
-	DeviceCode      string `json:"device_code"`
-	UserCode        string `json:"user_code"`
-	VerificationURI string `json:"verification_uri"`
-	ExpiresIn       int    `json:"expires_in"`
-	Interval        int    `json:"interval"`
+	DeviceCode              string `json:"device_code"`
+	UserCode                string `json:"user_code"`
+	VerificationURI         string `json:"verification_uri"`
+	VerificationURIComplete string `json:"verification_uri_complete,omitempty"`
+	ExpiresIn               int    `json:"expires_in"`
+	Interval                int    `json:"interval"`
 }
 
 // DeviceTokenRequest represents the polling request to exchange a device code
@@ -29,6 +39,41 @@ type DeviceTokenResponse struct {
 	Access  string `json:"access"`
 	Refresh string `json:"refresh"`
 	User    User   `json:"user"`
+
+	// SigningSecret is a per-device HMAC key. It is only issued when the
+	// backend has request signing enabled.
+	SigningSecret string `json:"signing_secret,omitempty"`
+
+	// Scope lists the space-separated scopes granted, when the token is
+	// limited. It is empty for a full-access token.
+	Scope string `json:"scope,omitempty"`
+}
+
+// AuthCodeTokenRequest exchanges an authorization code from the browser
+// login flow for tokens. CodeVerifier is the PKCE secret whose challenge
+// was sent with the authorization request.
+type AuthCodeTokenRequest struct {
+	GrantType    string `json:"grant_type"`
+	ClientID     string `json:"client_id"`
+	Code         string `json:"code"`
+	CodeVerifier string `json:"code_verifier"`
+	RedirectURI  string `json:"redirect_uri"`
+}
+
+// APIKeyTokenRequest exchanges a long-lived API key for a short-lived
+// access token (a client credentials grant).
+type APIKeyTokenRequest struct {
+	GrantType string `json:"grant_type"`
+	APIKey    string `json:"api_key"`
+}
+
+// APIKeyTokenResponse contains the access token issued for an API key.
+// API key sessions have no refresh token; the key is exchanged again when
+// the access token expires.
+type APIKeyTokenResponse struct {
+	Access    string `json:"access"`
+	ExpiresIn int    `json:"expires_in"`
+	User      User   `json:"user"`
 }
 
 // DeviceTokenError represents an error response during device token polling,
@@ -134,12 +179,25 @@ type SMSMessage struct {
 type Owner struct {
 	FirstName string `json:"first_name"`
 	LastName  string `json:"last_name"`
+
+	// Email and Identity are only returned by servers that report the
+	// session's account and bound identity.
+	Email    string    `json:"email,omitempty"`
+	Identity *Identity `json:"identity,omitempty"`
+}
+
+// ServerInfo describes the server: its version and the optional features
+// it supports (see Supports).
+type ServerInfo struct {
+	Version  string   `json:"version"`
+	Features []string `json:"features"`
 }
 
 // Error represents an error response from the API, containing a human-readable
 // error message in the Detail field.
 type Error struct {
 	Detail string `json:"detail"`
+	Code   string `json:"code,omitempty"`
 }
 
 // EncryptionMeta holds the user's E2E encryption metadata from the server.
@@ -149,6 +207,37 @@ type EncryptionMeta struct {
 	Verifier         string `json:"verifier"`
 	PublicKey        string `json:"public_key"`
 	ManagedMasterKey string `json:"managed_master_key"`
+
+	// PendingSalt, PendingVerifier and PendingPublicKey describe the new
+	// key of a PIN change or key rotation that is under way: they are
+	// registered before anything is re-encrypted to it, and cleared when
+	// the key record is replaced by them. Empty when none is.
+	PendingSalt      string `json:"pending_salt,omitempty"`
+	PendingVerifier  string `json:"pending_verifier,omitempty"`
+	PendingPublicKey string `json:"pending_public_key,omitempty"`
+
+	// KDF holds the Argon2id parameters used for PIN derivation. It is
+	// zero when the server doesn't advertise them.
+	KDF KDFMeta `json:"kdf,omitzero"`
+	// PINPolicy says what a PIN chosen for the account may be. It is zero
+	// when the server doesn't advertise one, meaning a 6-digit PIN.
+	PINPolicy PINPolicyMeta `json:"pin_policy,omitzero"`
+}
+
+// PINPolicyMeta describes the PINs an account accepts: Kind is "digits"
+// for a 6-digit PIN, or "passphrase" for one of at least MinLength
+// characters.
+type PINPolicyMeta struct {
+	Kind      string `json:"kind"`
+	MinLength int    `json:"min_length,omitempty"`
+}
+
+// KDFMeta describes the key derivation function parameters, using
+// libsodium's crypto_pwhash naming (memlimit is in bytes).
+type KDFMeta struct {
+	Algorithm string `json:"algorithm"`
+	OpsLimit  uint64 `json:"opslimit"`
+	MemLimit  uint64 `json:"memlimit"`
 }
 
 // SundayPhone represents the user's assigned Sunday phone number.
@@ -197,6 +286,14 @@ type SundayEmailMessage struct {
 	CreatedDt   time.Time `json:"created_dt"`
 }
 
+// InboxMessage is a new message announced on the inbox event stream (see
+// SubscribeInbox). Type says which of Email and SMS is set.
+type InboxMessage struct {
+	Type  string              `json:"type"`
+	Email *SundayEmailMessage `json:"email,omitempty"`
+	SMS   *SundayPhoneMessage `json:"sms,omitempty"`
+}
+
 // PasswordEntry represents a stored website credential.
 type PasswordEntry struct {
 	UUID      string `json:"uuid"`
@@ -207,6 +304,25 @@ type PasswordEntry struct {
 	Notes     string `json:"notes"`
 	CreatedDt string `json:"created_dt"`
 	UpdatedDt string `json:"updated_dt"`
+	// SharedWith lists the emails of the users the entry is shared with,
+	// whose keys its fields are encrypted to as well as the owner's.
+	SharedWith []string `json:"shared_with,omitempty"`
+}
+
+// PasswordShare shares a password entry with Recipients, by email. Its
+// fields, encrypted to the owner and every recipient, replace the entry's,
+// and Recipients replaces the list it is shared with.
+type PasswordShare struct {
+	Recipients []string `json:"recipients"`
+	Username   string   `json:"username"`
+	Password   string   `json:"password"`
+	Notes      string   `json:"notes"`
+}
+
+// RecipientKey is another user's public key, for encrypting to them.
+type RecipientKey struct {
+	Email     string `json:"email"`
+	PublicKey string `json:"public_key"`
 }
 
 // GeneratedPassword is the response from the password generator endpoint.
@@ -246,3 +362,17 @@ type BindIdentityResponse struct {
 	Access  string `json:"access"`
 	Refresh string `json:"refresh"`
 }
+
+// Session is a CLI login: a refresh token issued to one machine.
+type Session struct {
+	ID         string    `json:"id"`
+	DeviceName string    `json:"device_name"`
+	UserAgent  string    `json:"user_agent"`
+	IPAddress  string    `json:"ip_address"`
+	Identity   string    `json:"identity_name"`
+	CreatedDt  time.Time `json:"created_dt"`
+	LastUsedDt time.Time `json:"last_used_dt"`
+
+	// Current is true for the session making the request.
+	Current bool `json:"current"`
+}
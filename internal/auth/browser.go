@@ -0,0 +1,133 @@
+package auth
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// platformWSL is the pseudo-GOOS browserCommand uses for Linux running
+// under the Windows Subsystem for Linux.
+const platformWSL = "wsl"
+
+// wslReleaseFile holds the kernel release string, which contains
+// "microsoft" under WSL. Tests point it elsewhere.
+var wslReleaseFile = "/proc/sys/kernel/osrelease"
+
+// lookPath is exec.LookPath, replaceable in tests.
+var lookPath = exec.LookPath
+
+// openBrowser opens the given URL in the browser named by $BROWSER, or
+// else the platform's default browser.
+func openBrowser(url string) error {
+	name, args, ok := envBrowserCommand(os.Getenv("BROWSER"), url)
+	if !ok {
+		platform := runtime.GOOS
+		if platform == "linux" && isWSL() {
+			platform = platformWSL
+		}
+		var err error
+		if name, args, err = browserCommand(platform, url); err != nil {
+			return err
+		}
+	}
+	return exec.Command(name, args...).Start()
+}
+
+// envBrowserCommand returns the command for the first installed browser
+// in spec, a $BROWSER list separated like $PATH. As with xdg-open, an
+// entry containing %s has the URL substituted there; otherwise the URL is
+// appended. ok is false when no entry is usable.
+func envBrowserCommand(spec, url string) (name string, args []string, ok bool) {
+	for _, entry := range filepath.SplitList(spec) {
+		fields := strings.Fields(entry)
+		if len(fields) == 0 {
+			continue
+		}
+		if _, err := lookPath(fields[0]); err != nil {
+			continue
+		}
+		substituted := false
+		for _, f := range fields[1:] {
+			if strings.Contains(f, "%s") {
+				f = strings.ReplaceAll(f, "%s", url)
+				substituted = true
+			}
+			args = append(args, f)
+		}
+		if !substituted {
+			args = append(args, url)
+		}
+		return fields[0], args, true
+	}
+	return "", nil, false
+}
+
+// isWSL reports whether we are running under the Windows Subsystem for Linux.
+func isWSL() bool {
+	if os.Getenv("WSL_DISTRO_NAME") != "" {
+		return true
+	}
+	release, err := os.ReadFile(wslReleaseFile)
+	if err != nil {
+		return false
+	}
+	return strings.Contains(strings.ToLower(string(release)), "microsoft")
+}
+
+// browserCommand returns the command and arguments that open url in the
+// default browser on goos.
+func browserCommand(goos, url string) (string, []string, error) {
+	switch goos {
+	case "darwin":
+		return "open", []string{url}, nil
+	case "linux":
+		// Containers and minimal installs often lack xdg-utils.
+		if _, err := lookPath("xdg-open"); err != nil {
+			return "", nil, fmt.Errorf("no browser launcher found (xdg-open is not installed)")
+		}
+		return "xdg-open", []string{url}, nil
+	case "windows":
+		// "cmd /c start" treats & in the URL as a command separator, which
+		// truncates query strings. rundll32 passes the URL through intact.
+		return "rundll32", []string{"url.dll,FileProtocolHandler", url}, nil
+	case platformWSL:
+		// xdg-open usually has no browser to hand off to inside WSL. Prefer
+		// wslview (from wslu) and otherwise call into Windows via interop.
+		if _, err := lookPath("wslview"); err == nil {
+			return "wslview", []string{url}, nil
+		}
+		return "rundll32.exe", []string{"url.dll,FileProtocolHandler", url}, nil
+	case "freebsd", "openbsd", "netbsd", "dragonfly":
+		// xdg-utils is an optional package on the BSDs; Debian-style
+		// sensible-browser is the usual alternative.
+		for _, name := range []string{"xdg-open", "sensible-browser"} {
+			if _, err := lookPath(name); err == nil {
+				return name, []string{url}, nil
+			}
+		}
+		return "", nil, fmt.Errorf("no browser launcher found (install xdg-utils)")
+	default:
+		return "", nil, fmt.Errorf("unsupported platform")
+	}
+}
+
+// spinnerCharSet picks a spinner pattern the terminal on goos can render.
+func spinnerCharSet(goos string) int {
+	if goos == "windows" {
+		return ASCIISpinnerCharSet
+	}
+	return DefaultSpinnerCharSet
+}
+
+// printOpenManually tells the user to open url themselves after the
+// browser could not be launched.
+func printOpenManually(url string, err error) {
+	fmt.Printf("Could not open a browser: %v\n", err)
+	fmt.Println("Open this URL to continue:")
+	fmt.Printf("  %s\n", url)
+	fmt.Println()
+}
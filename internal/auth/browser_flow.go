@@ -0,0 +1,203 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"html"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/ravi-technologies/sunday-cli/internal/api"
+)
+
+// BrowserLoginTimeout is how long the browser flow waits for the user to
+// approve the login before giving up.
+const BrowserLoginTimeout = 5 * time.Minute
+
+// callbackPath is the path of the loopback redirect URI.
+const callbackPath = "/callback"
+
+// launchBrowser opens a URL in the user's browser. Tests replace it to
+// play the part of the browser.
+var launchBrowser = openBrowser
+
+// BrowserFlow handles the authorization code flow with PKCE (RFC 7636):
+// the browser is sent to the Sunday login page, which redirects back to a
+// one-off server on 127.0.0.1 with a code the CLI exchanges for tokens.
+// It is quicker than the device flow on a desktop, as there is no code to
+// type in.
+type BrowserFlow struct {
+	client *api.Client
+
+	// Timeout is how long to wait for the browser to complete the login.
+	// NewBrowserFlow sets it to BrowserLoginTimeout.
+	Timeout time.Duration
+
+	// IdentityProvider, if set, asks the login page to pre-select that
+	// SSO provider.
+	IdentityProvider string
+
+	// Identity, if set, names the identity to bind (by name or UUID)
+	// instead of prompting when there are several.
+	Identity string
+}
+
+// NewBrowserFlow creates a new browser flow handler
+func NewBrowserFlow() (*BrowserFlow, error) {
+	client, err := api.NewClient(nil)
+	if err != nil {
+		return nil, err
+	}
+	return &BrowserFlow{client: client, Timeout: BrowserLoginTimeout}, nil
+}
+
+// callbackResult is what the loopback server received from the browser.
+type callbackResult struct {
+	code string
+	err  error
+}
+
+// Run executes the browser flow. Ctrl+C while waiting for the browser, or
+// cancelling ctx, ends it with ErrLoginCancelled.
+func (b *BrowserFlow) Run(ctx context.Context) error {
+	verifier, challenge, err := newPKCE()
+	if err != nil {
+		return err
+	}
+	state, err := randomToken()
+	if err != nil {
+		return err
+	}
+
+	// Only bind to loopback: the redirect carries the authorization code.
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return fmt.Errorf("starting login callback server: %w", err)
+	}
+	redirectURI := fmt.Sprintf("http://%s%s", listener.Addr(), callbackPath)
+
+	results := make(chan callbackResult, 1)
+	server := &http.Server{
+		Handler:           callbackHandler(state, results),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go server.Serve(listener)
+	defer func() {
+		// Let the browser receive the result page before shutting down.
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		server.Shutdown(ctx)
+	}()
+
+	authURL := b.client.AuthorizeURL(redirectURI, state, challenge, b.IdentityProvider)
+
+	fmt.Println()
+	fmt.Println("To authenticate, visit:")
+	fmt.Printf("  %s\n", authURL)
+	fmt.Println()
+	if err := launchBrowser(authURL); err != nil {
+		// Not a fatal error, user can manually visit URL
+		printOpenManually(authURL, err)
+	}
+	fmt.Println("Waiting for authorization in the browser...")
+
+	waitCtx, stopTrap := trapInterrupt(ctx)
+	defer stopTrap()
+
+	var result callbackResult
+	select {
+	case result = <-results:
+	case <-waitCtx.Done():
+		return ErrLoginCancelled
+	case <-time.After(b.Timeout):
+		return fmt.Errorf("authentication timed out after %s", b.Timeout)
+	}
+	stopTrap()
+	if result.err != nil {
+		return result.err
+	}
+
+	tokenResp, err := b.client.ExchangeAuthCodeContext(ctx, result.code, verifier, redirectURI)
+	if err != nil {
+		return err
+	}
+	return (&login{client: b.client, identity: b.Identity}).complete(ctx, tokenResp)
+}
+
+// callbackHandler serves the redirect URI. The first request carrying the
+// expected state completes the flow; anything else is rejected so that a
+// page forging a redirect to 127.0.0.1 can't inject its own code.
+func callbackHandler(state string, results chan<- callbackResult) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc(callbackPath, func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if subtle.ConstantTimeCompare([]byte(q.Get("state")), []byte(state)) != 1 {
+			writeCallbackPage(w, http.StatusBadRequest, "Login failed", "The login response did not match this request. Run sunday auth login again.")
+			return
+		}
+
+		var result callbackResult
+		switch {
+		case q.Get("error") != "":
+			msg := q.Get("error")
+			if desc := q.Get("error_description"); desc != "" {
+				msg += ": " + desc
+			}
+			result.err = fmt.Errorf("authorization denied: %s", msg)
+			writeCallbackPage(w, http.StatusOK, "Login cancelled", "You can close this window.")
+		case q.Get("code") == "":
+			result.err = errors.New("authorization response did not include a code")
+			writeCallbackPage(w, http.StatusBadRequest, "Login failed", "No authorization code was received.")
+		default:
+			result.code = q.Get("code")
+			writeCallbackPage(w, http.StatusOK, "Logged in to Sunday", "You can close this window and return to the terminal.")
+		}
+
+		select {
+		case results <- result:
+		default:
+			// A result was already delivered; ignore repeats.
+		}
+	})
+	return mux
+}
+
+// writeCallbackPage writes the small page shown in the browser once the
+// redirect arrives.
+func writeCallbackPage(w http.ResponseWriter, status int, title, message string) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	fmt.Fprintf(w, "<!doctype html><title>%s</title><h1>%s</h1><p>%s</p>\n",
+		html.EscapeString(title), html.EscapeString(title), html.EscapeString(message))
+}
+
+// newPKCE returns a PKCE code verifier and its S256 challenge.
+func newPKCE() (verifier, challenge string, err error) {
+	verifier, err = randomToken()
+	if err != nil {
+		return "", "", err
+	}
+	return verifier, pkceChallenge(verifier), nil
+}
+
+// pkceChallenge returns the S256 code challenge for verifier.
+func pkceChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// randomToken returns 32 random bytes, base64url-encoded without padding
+// (43 characters, the shortest verifier RFC 7636 allows at full strength).
+func randomToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generating random value: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,177 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ravi-technologies/sunday-cli/internal/api"
+	"github.com/ravi-technologies/sunday-cli/internal/config"
+)
+
+// withBrowser replaces launchBrowser for the duration of a test.
+func withBrowser(t *testing.T, browse func(authURL string) error) {
+	t.Helper()
+	orig := launchBrowser
+	launchBrowser = browse
+	t.Cleanup(func() { launchBrowser = orig })
+}
+
+// redirectBack plays the browser: it follows the authorize URL's redirect
+// URI with the given query, as the login page would after approval.
+func redirectBack(t *testing.T, authURL string, query func(state string) url.Values) error {
+	t.Helper()
+	u, err := url.Parse(authURL)
+	if err != nil {
+		return err
+	}
+	q := u.Query()
+	resp, err := http.Get(q.Get("redirect_uri") + "?" + query(q.Get("state")).Encode())
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// TestBrowserFlow_Run verifies a full browser login: the code from the
+// loopback redirect is exchanged with the matching PKCE verifier and the
+// session is saved.
+func TestBrowserFlow_Run(t *testing.T) {
+	var challenge string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case api.PathAuthCodeToken:
+			var req api.AuthCodeTokenRequest
+			json.NewDecoder(r.Body).Decode(&req)
+			if req.Code != "the-code" {
+				t.Errorf("code = %q, want the-code", req.Code)
+			}
+			if pkceChallenge(req.CodeVerifier) != challenge {
+				t.Errorf("code verifier does not match the challenge")
+			}
+			fmt.Fprint(w, `{"access":"a1","refresh":"r1","user":{"email":"me@example.com"}}`)
+		case api.PathIdentities:
+			fmt.Fprint(w, `[{"uuid":"1","name":"Personal"}]`)
+		case api.PathBindIdentity:
+			fmt.Fprint(w, `{"access":"a2","refresh":"r2"}`)
+		case api.PathEncryption:
+			fmt.Fprint(w, `{}`)
+		default:
+			t.Errorf("unexpected request %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	_, cleanupHome := withTempHome(t)
+	defer cleanupHome()
+	cleanupURL := withAPIBaseURL(t, server.URL)
+	defer cleanupURL()
+
+	withBrowser(t, func(authURL string) error {
+		u, _ := url.Parse(authURL)
+		challenge = u.Query().Get("code_challenge")
+		if !strings.HasPrefix(u.Query().Get("redirect_uri"), "http://127.0.0.1:") {
+			t.Errorf("redirect_uri = %q, want loopback", u.Query().Get("redirect_uri"))
+		}
+		go redirectBack(t, authURL, func(state string) url.Values {
+			return url.Values{"code": {"the-code"}, "state": {state}}
+		})
+		return nil
+	})
+
+	flow, err := NewBrowserFlow()
+	if err != nil {
+		t.Fatalf("NewBrowserFlow() error = %v", err)
+	}
+	if err := flow.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("config.Load() error = %v", err)
+	}
+	if cfg.AccessToken != "a2" || cfg.RefreshToken != "r2" || cfg.UserEmail != "me@example.com" || cfg.IdentityName != "Personal" {
+		t.Errorf("saved config = %+v", cfg)
+	}
+}
+
+// TestBrowserFlow_Denied verifies that an error redirect ends the flow
+// with the server's reason and nothing is saved.
+func TestBrowserFlow_Denied(t *testing.T) {
+	_, cleanupHome := withTempHome(t)
+	defer cleanupHome()
+	cleanupURL := withAPIBaseURL(t, "https://sunday.example")
+	defer cleanupURL()
+
+	withBrowser(t, func(authURL string) error {
+		go redirectBack(t, authURL, func(state string) url.Values {
+			return url.Values{"error": {"access_denied"}, "error_description": {"user cancelled"}, "state": {state}}
+		})
+		return nil
+	})
+
+	flow, err := NewBrowserFlow()
+	if err != nil {
+		t.Fatalf("NewBrowserFlow() error = %v", err)
+	}
+	err = flow.Run(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "user cancelled") {
+		t.Errorf("Run() error = %v, want access_denied", err)
+	}
+	if cfg, _ := config.Load(); cfg.AccessToken != "" {
+		t.Error("tokens saved after denied login")
+	}
+}
+
+// TestCallbackHandler_WrongState verifies that a redirect with the wrong
+// state is rejected and doesn't complete the flow.
+func TestCallbackHandler_WrongState(t *testing.T) {
+	results := make(chan callbackResult, 1)
+	rec := httptest.NewRecorder()
+	callbackHandler("expected", results).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, callbackPath+"?code=c&state=forged", nil))
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+	select {
+	case r := <-results:
+		t.Errorf("forged redirect delivered result %+v", r)
+	default:
+	}
+}
+
+// TestBrowserFlow_Timeout verifies that the flow gives up if the browser
+// never redirects back.
+func TestBrowserFlow_Timeout(t *testing.T) {
+	cleanupURL := withAPIBaseURL(t, "https://sunday.example")
+	defer cleanupURL()
+	withBrowser(t, func(string) error { return nil })
+
+	client, err := api.NewClient(&config.Config{})
+	if err != nil {
+		t.Fatalf("api.NewClient() error = %v", err)
+	}
+	flow := &BrowserFlow{client: client, Timeout: 50 * time.Millisecond}
+	if err := flow.Run(context.Background()); err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Errorf("Run() error = %v, want timeout", err)
+	}
+}
+
+// TestPKCEChallenge verifies the S256 transform against the example in
+// RFC 7636 appendix B.
+func TestPKCEChallenge(t *testing.T) {
+	got := pkceChallenge("dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk")
+	if want := "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"; got != want {
+		t.Errorf("pkceChallenge() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,181 @@
+package auth
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestBrowserCommand verifies the command selected for each platform,
+// including that the URL is passed as a single argument.
+func TestBrowserCommand(t *testing.T) {
+	url := "https://sunday.app/device?user_code=ABCD-1234&next=/cli"
+	origLookPath := lookPath
+	defer func() { lookPath = origLookPath }()
+	lookPath = func(file string) (string, error) { return "/usr/local/bin/" + file, nil }
+
+	for _, tc := range getBrowserCommandTestCases() {
+		t.Run(tc.goos, func(t *testing.T) {
+			name, args, err := browserCommand(tc.goos, url)
+
+			if tc.shouldError {
+				if err == nil {
+					t.Fatalf("browserCommand(%q) error = nil, want error", tc.goos)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("browserCommand(%q) error = %v", tc.goos, err)
+			}
+			if name != tc.expectedCommand {
+				t.Errorf("command = %q, want %q", name, tc.expectedCommand)
+			}
+
+			wantArgs := append(append([]string{}, tc.expectedArgs...), url)
+			if len(args) != len(wantArgs) {
+				t.Fatalf("args = %v, want %v", args, wantArgs)
+			}
+			for i := range args {
+				if args[i] != wantArgs[i] {
+					t.Errorf("args[%d] = %q, want %q", i, args[i], wantArgs[i])
+				}
+			}
+		})
+	}
+}
+
+// TestBrowserCommand_BSD verifies the launcher fallback order on the BSDs.
+func TestBrowserCommand_BSD(t *testing.T) {
+	origLookPath := lookPath
+	defer func() { lookPath = origLookPath }()
+
+	lookPath = func(file string) (string, error) {
+		if file == "sensible-browser" {
+			return "/usr/local/bin/sensible-browser", nil
+		}
+		return "", exec.ErrNotFound
+	}
+	if name, _, err := browserCommand("openbsd", "https://sunday.app"); err != nil || name != "sensible-browser" {
+		t.Errorf("browserCommand(openbsd) = %q, %v; want sensible-browser", name, err)
+	}
+
+	lookPath = func(file string) (string, error) { return "", exec.ErrNotFound }
+	if _, _, err := browserCommand("freebsd", "https://sunday.app"); err == nil {
+		t.Error("browserCommand(freebsd) with no launcher installed error = nil, want error")
+	}
+}
+
+// TestBrowserCommand_LinuxNoLauncher verifies that a Linux system without
+// xdg-open, such as a container, reports why the browser didn't open.
+func TestBrowserCommand_LinuxNoLauncher(t *testing.T) {
+	origLookPath := lookPath
+	defer func() { lookPath = origLookPath }()
+	lookPath = func(file string) (string, error) { return "", exec.ErrNotFound }
+
+	_, _, err := browserCommand("linux", "https://sunday.app")
+	if err == nil || !strings.Contains(err.Error(), "xdg-open") {
+		t.Errorf("browserCommand(linux) error = %v, want one naming xdg-open", err)
+	}
+}
+
+// TestEnvBrowserCommand verifies parsing of $BROWSER: skipping entries
+// that aren't installed, substituting %s and otherwise appending the URL.
+func TestEnvBrowserCommand(t *testing.T) {
+	origLookPath := lookPath
+	defer func() { lookPath = origLookPath }()
+	lookPath = func(file string) (string, error) {
+		if file == "missing" {
+			return "", exec.ErrNotFound
+		}
+		return "/usr/bin/" + file, nil
+	}
+
+	url := "https://sunday.app/device?user_code=ABCD"
+	sep := string(os.PathListSeparator)
+	tests := []struct {
+		spec     string
+		wantName string
+		wantArgs []string
+		wantOK   bool
+	}{
+		{"", "", nil, false},
+		{"missing", "", nil, false},
+		{"firefox", "firefox", []string{url}, true},
+		{"missing" + sep + "w3m -o x", "w3m", []string{"-o", "x", url}, true},
+		{"lynx --url=%s -dump", "lynx", []string{"--url=" + url, "-dump"}, true},
+	}
+	for _, tc := range tests {
+		name, args, ok := envBrowserCommand(tc.spec, url)
+		if ok != tc.wantOK || name != tc.wantName || strings.Join(args, " ") != strings.Join(tc.wantArgs, " ") {
+			t.Errorf("envBrowserCommand(%q) = %q, %v, %v; want %q, %v, %v",
+				tc.spec, name, args, ok, tc.wantName, tc.wantArgs, tc.wantOK)
+		}
+	}
+}
+
+// TestSpinnerCharSet verifies that Windows gets an ASCII spinner.
+func TestSpinnerCharSet(t *testing.T) {
+	if got := spinnerCharSet("windows"); got != ASCIISpinnerCharSet {
+		t.Errorf("spinnerCharSet(windows) = %d, want %d", got, ASCIISpinnerCharSet)
+	}
+	for _, goos := range []string{"darwin", "linux"} {
+		if got := spinnerCharSet(goos); got != DefaultSpinnerCharSet {
+			t.Errorf("spinnerCharSet(%s) = %d, want %d", goos, got, DefaultSpinnerCharSet)
+		}
+	}
+}
+
+// TestBrowserCommand_WSL verifies that WSL prefers wslview and otherwise
+// falls back to the Windows URL handler.
+func TestBrowserCommand_WSL(t *testing.T) {
+	origLookPath := lookPath
+	defer func() { lookPath = origLookPath }()
+	url := "https://sunday.app/device?user_code=ABCD-1234"
+
+	lookPath = func(file string) (string, error) { return "/usr/bin/" + file, nil }
+	if name, _, _ := browserCommand(platformWSL, url); name != "wslview" {
+		t.Errorf("command with wslview installed = %q, want wslview", name)
+	}
+
+	lookPath = func(file string) (string, error) { return "", exec.ErrNotFound }
+	name, args, err := browserCommand(platformWSL, url)
+	if err != nil {
+		t.Fatalf("browserCommand(wsl) error = %v", err)
+	}
+	if name != "rundll32.exe" || len(args) != 2 || args[1] != url {
+		t.Errorf("fallback command = %q %v, want rundll32.exe url.dll,FileProtocolHandler %s", name, args, url)
+	}
+}
+
+// TestIsWSL verifies WSL detection from the kernel release string.
+func TestIsWSL(t *testing.T) {
+	origFile := wslReleaseFile
+	defer func() { wslReleaseFile = origFile }()
+	t.Setenv("WSL_DISTRO_NAME", "")
+
+	tests := []struct {
+		release string
+		want    bool
+	}{
+		{"5.15.153.1-microsoft-standard-WSL2", true},
+		{"4.4.0-19041-Microsoft", true},
+		{"6.8.0-45-generic", false},
+	}
+	for _, tt := range tests {
+		wslReleaseFile = filepath.Join(t.TempDir(), "osrelease")
+		if err := os.WriteFile(wslReleaseFile, []byte(tt.release+"\n"), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if got := isWSL(); got != tt.want {
+			t.Errorf("isWSL() with release %q = %v, want %v", tt.release, got, tt.want)
+		}
+	}
+
+	wslReleaseFile = filepath.Join(t.TempDir(), "missing")
+	t.Setenv("WSL_DISTRO_NAME", "Ubuntu")
+	if !isWSL() {
+		t.Error("isWSL() with WSL_DISTRO_NAME set = false, want true")
+	}
+}
@@ -1,34 +1,121 @@
 package auth
 
 import (
-	"bufio"
-	"encoding/base64"
+	"context"
+	"errors"
 	"fmt"
+	"net/url"
 	"os"
-	"os/exec"
+	"os/signal"
 	"runtime"
-	"strconv"
 	"strings"
 	"time"
 
 	"github.com/briandowns/spinner"
 	"github.com/ravi-technologies/sunday-cli/internal/api"
-	"github.com/ravi-technologies/sunday-cli/internal/config"
-	"github.com/ravi-technologies/sunday-cli/internal/crypto"
 	"github.com/ravi-technologies/sunday-cli/internal/output"
+	"github.com/ravi-technologies/sunday-cli/internal/qr"
 )
 
 const (
 	// DefaultSpinnerCharSet is the Braille spinner pattern (index 14 in yacspin).
 	DefaultSpinnerCharSet = 14
+
+	// ASCIISpinnerCharSet is the |/-\ spinner pattern (index 9 in yacspin),
+	// used on Windows where legacy consoles can't render Braille glyphs.
+	ASCIISpinnerCharSet = 9
+)
+
+// slowDownIncrement is how much the polling interval grows each time the
+// server answers slow_down (RFC 8628 §3.5).
+const slowDownIncrement = 5 * time.Second
+
+// A pre-issued device code comes without the server's polling schedule,
+// so the RFC 8628 default interval and a conservative lifetime are used.
+const (
+	preIssuedPollInterval = 5 * time.Second
+	preIssuedExpiry       = 15 * time.Minute
 )
 
+// EnvDeviceCode supplies a pre-issued device code to auth login, like its
+// --device-code flag.
+const EnvDeviceCode = "SUNDAY_DEVICE_CODE"
+
+// ErrLoginCancelled is returned when the user interrupts a login with
+// Ctrl+C, or its context is cancelled, before authorization completes.
+var ErrLoginCancelled = errors.New("login cancelled")
+
+// ErrDeviceCodeExpired is returned by PollDeviceToken when the server says
+// the device code has expired.
+var ErrDeviceCodeExpired = errors.New("device code expired")
+
+// ErrAuthorizationTimeout is returned by PollDeviceToken when the time it
+// was given runs out before the user authorizes the device.
+var ErrAuthorizationTimeout = errors.New("authorization timed out")
+
+// wait pauses between polls, returning early with ctx's error if ctx is
+// done first. Tests replace it.
+var wait = func(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// trapInterrupt returns a context that is cancelled on Ctrl+C, so a flow
+// can stop its spinner and exit cleanly instead of being killed. Call stop
+// to restore the default Ctrl+C behaviour.
+func trapInterrupt(ctx context.Context) (context.Context, context.CancelFunc) {
+	return signal.NotifyContext(ctx, os.Interrupt)
+}
+
 // DeviceFlow handles the device code authentication flow
 type DeviceFlow struct {
 	client  *api.Client
 	spinner *spinner.Spinner
+
+	// interactive is false when stdout is not a terminal, in which case
+	// plain progress lines are printed instead of the spinner.
+	interactive bool
+
+	// NoBrowser skips opening a browser and shows the verification URL as
+	// a QR code as well, for sessions (e.g. over SSH) with no local
+	// browser.
+	NoBrowser bool
+
+	// IdentityProvider, if set, asks the server to pre-select that SSO
+	// provider on the verification page.
+	IdentityProvider string
+
+	// Timeout, if set, gives up waiting for authorization sooner than the
+	// device code expires.
+	Timeout time.Duration
+
+	// Interval, if set, polls less often than the server asks. It can't
+	// be shorter than the server's interval.
+	Interval time.Duration
+
+	// DeviceCode, if set, is a device code that was issued and approved
+	// elsewhere, e.g. by a provisioning system. Run skips requesting a
+	// code and showing instructions and only polls for the tokens.
+	DeviceCode string
+
+	// Identity, if set, names the identity to bind (by name or UUID)
+	// instead of prompting when there are several.
+	Identity string
+
+	// Scopes, if set, requests a token limited to these scopes.
+	Scopes []string
 }
 
+// stdinIsTerminal reports whether stdin can be used for interactive
+// prompts. Tests replace it.
+var stdinIsTerminal = func() bool { return output.IsTerminal(os.Stdin) }
+
 // NewDeviceFlow creates a new device flow handler
 func NewDeviceFlow() (*DeviceFlow, error) {
 	client, err := api.NewClient(nil)
@@ -36,202 +123,170 @@ func NewDeviceFlow() (*DeviceFlow, error) {
 		return nil, err
 	}
 
-	s := spinner.New(spinner.CharSets[DefaultSpinnerCharSet], 100*time.Millisecond)
+	s := spinner.New(spinner.CharSets[spinnerCharSet(runtime.GOOS)], 100*time.Millisecond)
 	s.Suffix = " Waiting for authorization..."
 
 	return &DeviceFlow{
-		client:  client,
-		spinner: s,
+		client:      client,
+		spinner:     s,
+		interactive: output.IsTerminal(os.Stdout),
 	}, nil
 }
 
-// Run executes the device code flow
-func (d *DeviceFlow) Run() error {
-	// Request device code
-	codeResp, err := d.client.RequestDeviceCode()
-	if err != nil {
-		return fmt.Errorf("failed to request device code: %w", err)
+// Run executes the device code flow. Ctrl+C while waiting for
+// authorization, or cancelling ctx, ends it with ErrLoginCancelled.
+func (d *DeviceFlow) Run(ctx context.Context) error {
+	var codeResp *api.DeviceCodeResponse
+	if d.DeviceCode != "" {
+		// Pre-authorized centrally: nothing to show, just collect the tokens.
+		codeResp = &api.DeviceCodeResponse{
+			DeviceCode: d.DeviceCode,
+			Interval:   int(preIssuedPollInterval / time.Second),
+			ExpiresIn:  int(preIssuedExpiry / time.Second),
+		}
+	} else {
+		var err error
+		codeResp, err = d.client.RequestDeviceCodeWithContext(ctx, api.DeviceCodeRequest{
+			IdentityProvider: d.IdentityProvider,
+			Scope:            strings.Join(d.Scopes, " "),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to request device code: %w", err)
+		}
+		d.showInstructions(codeResp)
 	}
 
-	// Display instructions
-	fmt.Println()
-	fmt.Println("To authenticate, visit:")
-	fmt.Printf("  %s\n", codeResp.VerificationURI)
-	fmt.Println()
-	fmt.Println("And enter the code:")
-	fmt.Printf("  %s\n", codeResp.UserCode)
-	fmt.Println()
+	// Ctrl+C only ends the login cleanly while polling. The trap is lifted
+	// before the PIN prompt, where it should interrupt at once.
+	pollCtx, stopTrap := trapInterrupt(ctx)
+	defer stopTrap()
+
+	// Start polling with spinner. A pre-issued code polls silently.
+	switch {
+	case d.DeviceCode != "":
+	case d.interactive:
+		d.spinner.Start()
+		defer d.spinner.Stop()
+	default:
+		fmt.Println("Waiting for authorization...")
+	}
 
-	// Try to open browser
-	if err := openBrowser(codeResp.VerificationURI + "?user_code=" + codeResp.UserCode); err != nil {
-		// Not a fatal error, user can manually visit URL
-		fmt.Println("(Could not open browser automatically)")
+	interval, timeout := d.pollSchedule(codeResp)
+	tokenResp, err := PollDeviceToken(pollCtx, d.client, codeResp.DeviceCode, interval, timeout)
+	switch {
+	case pollCtx.Err() != nil:
+		return ErrLoginCancelled
+	case errors.Is(err, ErrDeviceCodeExpired):
+		return fmt.Errorf("device code expired. Please try again")
+	case errors.Is(err, ErrAuthorizationTimeout):
+		return fmt.Errorf("authentication timed out after %s", timeout)
+	case err != nil:
+		return err
 	}
 
-	// Start polling with spinner
-	d.spinner.Start()
-	defer d.spinner.Stop()
+	// Success! Finish the login with the issued tokens.
+	d.spinner.Stop()
+	stopTrap()
+	return (&login{client: d.client, identity: d.Identity, scopes: d.Scopes}).complete(ctx, tokenResp)
+}
 
-	interval := time.Duration(codeResp.Interval) * time.Second
-	deadline := time.Now().Add(time.Duration(codeResp.ExpiresIn) * time.Second)
+// PollDeviceToken polls for the tokens of deviceCode every interval until
+// the user authorizes it, the server refuses it, timeout passes, or ctx is
+// done, in which case it returns ctx's error. When the server answers
+// slow_down the interval grows for all later polls. It performs no
+// terminal I/O, so that the SDK can wait the same way as auth login.
+func PollDeviceToken(ctx context.Context, client *api.Client, deviceCode string, interval, timeout time.Duration) (*api.DeviceTokenResponse, error) {
+	deadline := time.Now().Add(timeout)
 
 	for time.Now().Before(deadline) {
-		tokenResp, errCode, err := d.client.PollForToken(codeResp.DeviceCode)
+		tokenResp, errCode, err := client.PollForTokenContext(ctx, deviceCode)
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
 		if err != nil {
-			return fmt.Errorf("polling error: %w", err)
+			return nil, fmt.Errorf("polling error: %w", err)
 		}
 
-		// Check error codes
 		switch errCode {
+		case "":
+			return tokenResp, nil
 		case "authorization_pending":
 			// Still waiting, continue polling
-			time.Sleep(interval)
-			continue
+		case "slow_down":
+			// Polling too fast; back off for this and all later polls.
+			interval += slowDownIncrement
 		case "expired_token":
-			return fmt.Errorf("device code expired. Please try again")
-		case "":
-			// Success! Save tokens
-			d.spinner.Stop()
-
-			cfg := &config.Config{
-				AccessToken:  tokenResp.Access,
-				RefreshToken: tokenResp.Refresh,
-				ExpiresAt:    time.Now().Add(api.TokenExpiryBuffer), // Assume ~5 min expiry
-				UserEmail:    tokenResp.User.Email,
-			}
-
-			output.Current.PrintMessage(fmt.Sprintf("Authenticated as %s", tokenResp.User.Email))
-
-			// Recreate client with the new tokens (in memory only)
-			// so authenticated requests work before we persist.
-			d.client, err = api.NewClient(cfg)
-			if err != nil {
-				return fmt.Errorf("failed to reinitialize client: %w", err)
-			}
-
-			// Select and bind an identity to this CLI session.
-			if err := d.selectAndBindIdentity(cfg); err != nil {
-				return fmt.Errorf("identity selection failed: %w", err)
-			}
-
-			// Prompt for PIN to unlock E2E decryption.
-			// If the user exits here (Ctrl+C), nothing is saved to disk.
-			if err := d.unlockEncryption(cfg); err != nil {
-				return fmt.Errorf("encryption unlock failed: %w", err)
-			}
-
-			// Save only after auth + identity + PIN are all complete.
-			if err := config.Save(cfg); err != nil {
-				return fmt.Errorf("failed to save config: %w", err)
-			}
-
-			return nil
+			return nil, ErrDeviceCodeExpired
 		default:
-			return fmt.Errorf("authentication error: %s", errCode)
+			return nil, fmt.Errorf("authentication error: %s", errCode)
 		}
-	}
-
-	return fmt.Errorf("authentication timed out")
-}
 
-// unlockEncryption fetches the user's encryption metadata, prompts for their
-// PIN, verifies it, and persists the derived private key in the config file
-// so subsequent commands can decrypt without re-prompting.
-func (d *DeviceFlow) unlockEncryption(cfg *config.Config) error {
-	meta, err := d.client.GetEncryptionMeta()
-	if err != nil {
-		return fmt.Errorf("fetching encryption metadata: %w", err)
+		if err := wait(ctx, min(interval, time.Until(deadline))); err != nil {
+			return nil, err
+		}
 	}
 
-	if meta.PublicKey == "" {
-		// User hasn't completed PIN setup on the dashboard yet.
-		// This is OK — CLI will error on commands that need decryption.
-		fmt.Println("\nEncryption not set up yet. Complete PIN setup on the dashboard to enable E2E decryption.")
-		return nil
-	}
+	return nil, ErrAuthorizationTimeout
+}
 
+// showInstructions tells the user where to approve the login, opening a
+// browser or showing a QR code as well.
+func (d *DeviceFlow) showInstructions(codeResp *api.DeviceCodeResponse) {
+	fmt.Println()
+	fmt.Println("To authenticate, visit:")
+	fmt.Printf("  %s\n", codeResp.VerificationURI)
+	fmt.Println()
+	fmt.Println("And enter the code:")
+	fmt.Printf("  %s\n", codeResp.UserCode)
 	fmt.Println()
-	kp, err := crypto.GetOrPromptKeyPair(meta.Salt, meta.Verifier)
-	if err != nil {
-		return err
-	}
 
-	// Verify that the locally-derived public key matches the server record.
-	derivedPub := base64.StdEncoding.EncodeToString(kp.PublicKey[:])
-	if derivedPub != meta.PublicKey {
-		return fmt.Errorf("derived public key does not match server record — possible data corruption")
+	completeURI := verificationURIComplete(codeResp)
+	if d.NoBrowser {
+		fmt.Println("Or scan this code with your phone:")
+		fmt.Println()
+		if err := qr.WriteTerminal(os.Stdout, completeURI); err != nil {
+			fmt.Printf("(Could not render QR code: %v)\n", err)
+		}
+		fmt.Println()
+	} else if err := openBrowser(completeURI); err != nil {
+		// Not a fatal error, user can manually visit URL
+		printOpenManually(completeURI, err)
 	}
-
-	cfg.PINSalt = meta.Salt
-	cfg.PublicKey = meta.PublicKey
-	cfg.PrivateKey = base64.StdEncoding.EncodeToString(kp.PrivateKey[:])
-
-	output.Current.PrintMessage("Encryption unlocked")
-	return nil
 }
 
-// selectAndBindIdentity lists the user's identities and binds the chosen one
-// to the JWT session. The identity is then locked into all future API calls.
-func (d *DeviceFlow) selectAndBindIdentity(cfg *config.Config) error {
-	identities, err := d.client.ListIdentities()
-	if err != nil {
-		return fmt.Errorf("listing identities: %w", err)
-	}
-
-	if len(identities) == 0 {
-		return fmt.Errorf("no identities found — complete setup on the dashboard first")
+// verificationURIComplete returns the URL that approves the login without
+// typing the code: the server's verification_uri_complete when it sends
+// one, else VerificationURI with user_code added to its query.
+func verificationURIComplete(codeResp *api.DeviceCodeResponse) string {
+	if codeResp.VerificationURIComplete != "" {
+		return codeResp.VerificationURIComplete
 	}
-
-	var selected api.Identity
-
-	if len(identities) == 1 {
-		selected = identities[0]
-		output.Current.PrintMessage(fmt.Sprintf("Using identity: %s", identityLabel(selected)))
-	} else {
-		fmt.Println("\nSelect an identity for this CLI session:")
-		for i, id := range identities {
-			fmt.Printf("  %d) %s\n", i+1, identityLabel(id))
-		}
-		fmt.Print("> ")
-
-		reader := bufio.NewReader(os.Stdin)
-		line, err := reader.ReadString('\n')
-		if err != nil {
-			return fmt.Errorf("reading input: %w", err)
-		}
-		trimmed := strings.TrimSpace(line)
-		choice, err := strconv.Atoi(trimmed)
-		if err != nil {
-			return fmt.Errorf("invalid selection %q — enter a number between 1 and %d", trimmed, len(identities))
-		}
-		if choice < 1 || choice > len(identities) {
-			return fmt.Errorf("selection %d out of range — enter a number between 1 and %d", choice, len(identities))
-		}
-		selected = identities[choice-1]
-	}
-
-	// Bind the identity to the JWT.
-	bound, err := d.client.BindIdentity(selected.UUID)
+	u, err := url.Parse(codeResp.VerificationURI)
 	if err != nil {
-		return fmt.Errorf("binding identity: %w", err)
-	}
-	if bound.Access == "" || bound.Refresh == "" {
-		return fmt.Errorf("binding identity: server returned empty tokens")
+		return codeResp.VerificationURI + "?user_code=" + url.QueryEscape(codeResp.UserCode)
 	}
+	q := u.Query()
+	q.Set("user_code", codeResp.UserCode)
+	u.RawQuery = q.Encode()
+	return u.String()
+}
 
-	cfg.AccessToken = bound.Access
-	cfg.RefreshToken = bound.Refresh
-	cfg.ExpiresAt = time.Now().Add(api.TokenExpiryBuffer)
-	cfg.IdentityName = selected.Name
-
-	// Recreate client with the bound tokens.
-	d.client, err = api.NewClient(cfg)
-	if err != nil {
-		return fmt.Errorf("reinitializing client after bind: %w", err)
+// pollSchedule returns how often to poll and how long to wait in total:
+// the server's interval and code lifetime, adjusted by d.Interval and
+// d.Timeout within the bounds the server allows.
+func (d *DeviceFlow) pollSchedule(codeResp *api.DeviceCodeResponse) (interval, timeout time.Duration) {
+	interval = time.Duration(codeResp.Interval) * time.Second
+	if d.Interval > interval {
+		interval = d.Interval
+	} else if d.Interval > 0 && d.Interval < interval {
+		fmt.Printf("(Polling every %s, the server's minimum)\n", interval)
 	}
 
-	output.Current.PrintMessage(fmt.Sprintf("Bound to identity: %s", identityLabel(selected)))
-	return nil
+	timeout = time.Duration(codeResp.ExpiresIn) * time.Second
+	if d.Timeout > 0 && d.Timeout < timeout {
+		timeout = d.Timeout
+	}
+	return interval, timeout
 }
 
 // identityLabel returns a human-readable label for an identity
@@ -246,19 +301,3 @@ func identityLabel(id api.Identity) string {
 	}
 	return id.Name
 }
-
-// openBrowser opens the default browser to the given URL
-func openBrowser(url string) error {
-	var cmd *exec.Cmd
-	switch runtime.GOOS {
-	case "darwin":
-		cmd = exec.Command("open", url)
-	case "linux":
-		cmd = exec.Command("xdg-open", url)
-	case "windows":
-		cmd = exec.Command("cmd", "/c", "start", url)
-	default:
-		return fmt.Errorf("unsupported platform")
-	}
-	return cmd.Start()
-}
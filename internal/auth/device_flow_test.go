@@ -1,12 +1,21 @@
 package auth
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"runtime"
+	"slices"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/ravi-technologies/sunday-cli/internal/api"
+	"github.com/ravi-technologies/sunday-cli/internal/config"
 	"github.com/ravi-technologies/sunday-cli/internal/version"
 )
 
@@ -29,6 +38,12 @@ func withTempHome(t *testing.T) (tmpDir string, cleanup func()) {
 		t.Fatalf("Failed to set %s: %v", homeEnvVar, err)
 	}
 
+	// Keep tokens in the temp config file rather than the OS keyring.
+	t.Setenv(config.EnvTokenStore, config.TokenStoreFile)
+	t.Setenv(config.EnvProfile, "")
+	t.Setenv(config.EnvAccount, "")
+	t.Setenv(api.EnvAPIKey, "")
+
 	cleanup = func() {
 		os.Setenv(homeEnvVar, originalHome)
 	}
@@ -142,21 +157,21 @@ func getBrowserCommandTestCases() []browserCommandTestCase {
 		},
 		{
 			goos:            "windows",
-			expectedCommand: "cmd",
-			expectedArgs:    []string{"/c", "start"},
+			expectedCommand: "rundll32",
+			expectedArgs:    []string{"url.dll,FileProtocolHandler"},
 			shouldError:     false,
 		},
 		{
 			goos:            "freebsd",
-			expectedCommand: "",
-			expectedArgs:    nil,
-			shouldError:     true,
+			expectedCommand: "xdg-open",
+			expectedArgs:    []string{},
+			shouldError:     false,
 		},
 		{
 			goos:            "openbsd",
-			expectedCommand: "",
-			expectedArgs:    nil,
-			shouldError:     true,
+			expectedCommand: "xdg-open",
+			expectedArgs:    []string{},
+			shouldError:     false,
 		},
 	}
 }
@@ -213,7 +228,7 @@ func TestOpenBrowser_Linux(t *testing.T) {
 	}
 }
 
-// TestOpenBrowser_Windows verifies that the "cmd /c start" command is used on Windows.
+// TestOpenBrowser_Windows verifies that "rundll32 url.dll,FileProtocolHandler" is used on Windows.
 //
 // Since we cannot mock runtime.GOOS directly, this test verifies the expected behavior
 // by documenting what the openBrowser function should do on Windows.
@@ -224,11 +239,11 @@ func TestOpenBrowser_Windows(t *testing.T) {
 	if tc.goos != "windows" {
 		t.Fatalf("Test case mismatch: expected windows, got %s", tc.goos)
 	}
-	if tc.expectedCommand != "cmd" {
-		t.Errorf("Expected command for windows = %q, want %q", tc.expectedCommand, "cmd")
+	if tc.expectedCommand != "rundll32" {
+		t.Errorf("Expected command for windows = %q, want %q", tc.expectedCommand, "rundll32")
 	}
-	if len(tc.expectedArgs) != 2 || tc.expectedArgs[0] != "/c" || tc.expectedArgs[1] != "start" {
-		t.Errorf("Expected args for windows = %v, want [/c start]", tc.expectedArgs)
+	if len(tc.expectedArgs) != 1 || tc.expectedArgs[0] != "url.dll,FileProtocolHandler" {
+		t.Errorf("Expected args for windows = %v, want [url.dll,FileProtocolHandler]", tc.expectedArgs)
 	}
 	if tc.shouldError {
 		t.Error("windows should not error")
@@ -249,7 +264,7 @@ func TestOpenBrowser_Windows(t *testing.T) {
 // The test case data confirms the implementation handles unsupported platforms correctly.
 func TestOpenBrowser_Unsupported(t *testing.T) {
 	// Verify test cases for unsupported platforms
-	unsupportedPlatforms := []string{"freebsd", "openbsd", "plan9", "js", "aix", "illumos"}
+	unsupportedPlatforms := []string{"plan9", "js", "aix", "illumos"}
 
 	for _, platform := range unsupportedPlatforms {
 		t.Run(platform, func(t *testing.T) {
@@ -268,7 +283,7 @@ func TestOpenBrowser_Unsupported(t *testing.T) {
 			// If platform is in our unsupported list but not in test cases,
 			// verify it's not one of the supported platforms
 			if !found {
-				supportedPlatforms := []string{"darwin", "linux", "windows"}
+				supportedPlatforms := []string{"darwin", "linux", "windows", "freebsd", "openbsd", "netbsd", "dragonfly"}
 				for _, supported := range supportedPlatforms {
 					if platform == supported {
 						t.Errorf("Platform %q is marked as unsupported but is a supported platform", platform)
@@ -279,7 +294,7 @@ func TestOpenBrowser_Unsupported(t *testing.T) {
 	}
 
 	// If we're running on an unsupported platform, test the actual behavior
-	if runtime.GOOS != "darwin" && runtime.GOOS != "linux" && runtime.GOOS != "windows" {
+	if !slices.Contains([]string{"darwin", "linux", "windows", "freebsd", "openbsd", "netbsd", "dragonfly"}, runtime.GOOS) {
 		err := openBrowser("https://example.com")
 		if err == nil {
 			t.Errorf("openBrowser() on %s should return error, got nil", runtime.GOOS)
@@ -295,7 +310,7 @@ func TestOpenBrowser_Unsupported(t *testing.T) {
 // NOTE: We do NOT actually call openBrowser() as it would open a real browser.
 func TestOpenBrowser_CurrentPlatform(t *testing.T) {
 	switch runtime.GOOS {
-	case "darwin", "linux", "windows":
+	case "darwin", "linux", "windows", "freebsd", "openbsd", "netbsd", "dragonfly":
 		t.Logf("Running on supported platform: %s (browser not opened to avoid side effects)", runtime.GOOS)
 	default:
 		// On unsupported platforms, we can safely test that an error is returned
@@ -348,3 +363,385 @@ func TestDeviceFlowStruct(t *testing.T) {
 		t.Error("flow.spinner should be non-nil")
 	}
 }
+
+// TestNewDeviceFlow_NonInteractive verifies that the spinner is disabled when
+// stdout is not a terminal, as it is under `go test`.
+func TestNewDeviceFlow_NonInteractive(t *testing.T) {
+	_, cleanupHome := withTempHome(t)
+	defer cleanupHome()
+	cleanupURL := withAPIBaseURL(t, "https://sunday.example")
+	defer cleanupURL()
+
+	flow, err := NewDeviceFlow()
+	if err != nil {
+		t.Fatalf("NewDeviceFlow() error = %v", err)
+	}
+	if flow.interactive {
+		t.Error("flow.interactive = true with redirected stdout, want false")
+	}
+}
+
+// TestSelectAndBindIdentity_NonInteractive verifies that identity selection
+// fails with the available choices instead of prompting when stdin is not a
+// terminal.
+func TestSelectAndBindIdentity_NonInteractive(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`[{"uuid":"1","name":"Work"},{"uuid":"2","name":"Personal"}]`))
+	}))
+	defer server.Close()
+
+	_, cleanupHome := withTempHome(t)
+	defer cleanupHome()
+	cleanupURL := withAPIBaseURL(t, server.URL)
+	defer cleanupURL()
+
+	origStdin := stdinIsTerminal
+	defer func() { stdinIsTerminal = origStdin }()
+	stdinIsTerminal = func() bool { return false }
+
+	cfg := &config.Config{AccessToken: "token", ExpiresAt: time.Now().Add(time.Hour)}
+	client, err := api.NewClient(cfg)
+	if err != nil {
+		t.Fatalf("api.NewClient() error = %v", err)
+	}
+	flow := &login{client: client}
+
+	err = flow.selectAndBindIdentity(context.Background(), cfg)
+	if err == nil {
+		t.Fatal("selectAndBindIdentity() error = nil, want error")
+	}
+	for _, name := range []string{"Work", "Personal"} {
+		if !strings.Contains(err.Error(), name) {
+			t.Errorf("error %q does not list identity %q", err, name)
+		}
+	}
+}
+
+// TestSelectAndBindIdentity_Named verifies that a named identity is bound
+// without prompting, even when stdin is not a terminal.
+func TestSelectAndBindIdentity_Named(t *testing.T) {
+	var bound string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case api.PathIdentities:
+			w.Write([]byte(`[{"uuid":"1","name":"Work"},{"uuid":"2","name":"Personal"}]`))
+		case api.PathBindIdentity:
+			var req api.BindIdentityRequest
+			json.NewDecoder(r.Body).Decode(&req)
+			bound = req.Identity
+			w.Write([]byte(`{"access":"bound-access","refresh":"bound-refresh"}`))
+		}
+	}))
+	defer server.Close()
+
+	_, cleanupHome := withTempHome(t)
+	defer cleanupHome()
+	cleanupURL := withAPIBaseURL(t, server.URL)
+	defer cleanupURL()
+
+	origStdin := stdinIsTerminal
+	defer func() { stdinIsTerminal = origStdin }()
+	stdinIsTerminal = func() bool { return false }
+
+	cfg := &config.Config{AccessToken: "token", ExpiresAt: time.Now().Add(time.Hour)}
+	client, err := api.NewClient(cfg)
+	if err != nil {
+		t.Fatalf("api.NewClient() error = %v", err)
+	}
+	flow := &login{client: client, identity: "personal"}
+
+	if err := flow.selectAndBindIdentity(context.Background(), cfg); err != nil {
+		t.Fatalf("selectAndBindIdentity() error = %v", err)
+	}
+	if bound != "2" || cfg.IdentityUUID != "2" || cfg.AccessToken != "bound-access" {
+		t.Errorf("bound %q, config identity %q token %q; want identity 2 with bound tokens", bound, cfg.IdentityUUID, cfg.AccessToken)
+	}
+}
+
+// TestMatchIdentity verifies matching by UUID or name, and the errors for
+// unknown and ambiguous names.
+func TestMatchIdentity(t *testing.T) {
+	identities := []api.Identity{
+		{UUID: "u1", Name: "Work"},
+		{UUID: "u2", Name: "Personal"},
+		{UUID: "u3", Name: "Shared"},
+		{UUID: "u4", Name: "shared"},
+	}
+	tests := []struct {
+		want     string
+		wantUUID string
+		wantErr  string
+	}{
+		{"u2", "u2", ""},
+		{"work", "u1", ""},
+		{"Nope", "", "no identity named"},
+		{"Shared", "", "pass a UUID"},
+	}
+	for _, tt := range tests {
+		got, err := matchIdentity(identities, tt.want)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("matchIdentity(%q) error = %v, want %q", tt.want, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil || got.UUID != tt.wantUUID {
+			t.Errorf("matchIdentity(%q) = %q, %v, want %q", tt.want, got.UUID, err, tt.wantUUID)
+		}
+	}
+}
+
+// TestDeviceFlow_SlowDown verifies that slow_down increases the polling
+// interval by 5 seconds for every later poll.
+func TestDeviceFlow_SlowDown(t *testing.T) {
+	polls := []string{"slow_down", "authorization_pending", "slow_down", "expired_token"}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case api.PathDeviceCode:
+			w.Write([]byte(`{"device_code":"dc","user_code":"ABCD-1234","verification_uri":"https://sunday.example/device","expires_in":600,"interval":1}`))
+		case api.PathDeviceToken:
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprintf(w, `{"error":%q}`, polls[0])
+			polls = polls[1:]
+		}
+	}))
+	defer server.Close()
+
+	_, cleanupHome := withTempHome(t)
+	defer cleanupHome()
+	cleanupURL := withAPIBaseURL(t, server.URL)
+	defer cleanupURL()
+
+	var waits []time.Duration
+	origWait := wait
+	wait = func(_ context.Context, d time.Duration) error {
+		waits = append(waits, d)
+		return nil
+	}
+	defer func() { wait = origWait }()
+
+	flow, err := NewDeviceFlow()
+	if err != nil {
+		t.Fatalf("NewDeviceFlow() error = %v", err)
+	}
+	flow.NoBrowser = true
+
+	if err := flow.Run(context.Background()); err == nil || !strings.Contains(err.Error(), "expired") {
+		t.Fatalf("Run() error = %v, want device code expired", err)
+	}
+	want := []time.Duration{6 * time.Second, 6 * time.Second, 11 * time.Second}
+	if !slices.Equal(waits, want) {
+		t.Errorf("poll waits = %v, want %v", waits, want)
+	}
+}
+
+// TestDeviceFlow_Cancelled verifies that cancelling the context while
+// waiting for authorization ends the flow with ErrLoginCancelled.
+func TestDeviceFlow_Cancelled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case api.PathDeviceCode:
+			w.Write([]byte(`{"device_code":"dc","user_code":"ABCD-1234","verification_uri":"https://sunday.example/device","expires_in":600,"interval":60}`))
+		case api.PathDeviceToken:
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error":"authorization_pending"}`))
+		}
+	}))
+	defer server.Close()
+
+	_, cleanupHome := withTempHome(t)
+	defer cleanupHome()
+	cleanupURL := withAPIBaseURL(t, server.URL)
+	defer cleanupURL()
+
+	flow, err := NewDeviceFlow()
+	if err != nil {
+		t.Fatalf("NewDeviceFlow() error = %v", err)
+	}
+	flow.NoBrowser = true
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+	start := time.Now()
+	if err := flow.Run(ctx); !errors.Is(err, ErrLoginCancelled) {
+		t.Fatalf("Run() error = %v, want ErrLoginCancelled", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Run() took %s to notice cancellation", elapsed)
+	}
+}
+
+// TestDeviceFlow_PollSchedule verifies that Interval and Timeout adjust
+// the server's schedule but can't poll faster than the server allows.
+func TestDeviceFlow_PollSchedule(t *testing.T) {
+	codeResp := &api.DeviceCodeResponse{Interval: 5, ExpiresIn: 600}
+	tests := []struct {
+		name         string
+		flow         DeviceFlow
+		wantInterval time.Duration
+		wantTimeout  time.Duration
+	}{
+		{"server defaults", DeviceFlow{}, 5 * time.Second, 10 * time.Minute},
+		{"slower polling", DeviceFlow{Interval: 20 * time.Second}, 20 * time.Second, 10 * time.Minute},
+		{"below server minimum", DeviceFlow{Interval: 2 * time.Second}, 5 * time.Second, 10 * time.Minute},
+		{"shorter timeout", DeviceFlow{Timeout: time.Minute}, 5 * time.Second, time.Minute},
+		{"timeout beyond expiry", DeviceFlow{Timeout: time.Hour}, 5 * time.Second, 10 * time.Minute},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			interval, timeout := tt.flow.pollSchedule(codeResp)
+			if interval != tt.wantInterval || timeout != tt.wantTimeout {
+				t.Errorf("pollSchedule() = %s, %s, want %s, %s", interval, timeout, tt.wantInterval, tt.wantTimeout)
+			}
+		})
+	}
+}
+
+// TestDeviceFlow_Timeout verifies that the flow gives up once Timeout has
+// passed, without waiting out a full poll interval.
+func TestDeviceFlow_Timeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case api.PathDeviceCode:
+			w.Write([]byte(`{"device_code":"dc","user_code":"ABCD-1234","verification_uri":"https://sunday.example/device","expires_in":600,"interval":60}`))
+		case api.PathDeviceToken:
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error":"authorization_pending"}`))
+		}
+	}))
+	defer server.Close()
+
+	_, cleanupHome := withTempHome(t)
+	defer cleanupHome()
+	cleanupURL := withAPIBaseURL(t, server.URL)
+	defer cleanupURL()
+
+	flow, err := NewDeviceFlow()
+	if err != nil {
+		t.Fatalf("NewDeviceFlow() error = %v", err)
+	}
+	flow.NoBrowser = true
+	flow.Timeout = 50 * time.Millisecond
+
+	start := time.Now()
+	if err := flow.Run(context.Background()); err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Fatalf("Run() error = %v, want timed out", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Run() took %s, want it to stop near the 50ms timeout", elapsed)
+	}
+}
+
+// TestPollDeviceToken verifies the error PollDeviceToken returns for each
+// way a device code fails to be authorized.
+func TestPollDeviceToken(t *testing.T) {
+	tests := []struct {
+		name    string
+		errCode string
+		check   func(error) bool
+	}{
+		{"expired", "expired_token", func(err error) bool { return errors.Is(err, ErrDeviceCodeExpired) }},
+		{"timed out", "authorization_pending", func(err error) bool { return errors.Is(err, ErrAuthorizationTimeout) }},
+		{"denied", "access_denied", func(err error) bool { return err != nil && strings.Contains(err.Error(), "access_denied") }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusBadRequest)
+				fmt.Fprintf(w, `{"error":%q}`, tt.errCode)
+			}))
+			defer server.Close()
+
+			client := api.NewClientForURL(server.URL, nil, nil)
+			_, err := PollDeviceToken(context.Background(), client, "dc", time.Millisecond, 50*time.Millisecond)
+			if !tt.check(err) {
+				t.Errorf("PollDeviceToken() error = %v", err)
+			}
+		})
+	}
+}
+
+// TestDeviceFlow_PreIssuedCode verifies that a pre-issued device code is
+// polled for directly, without requesting a new one.
+func TestDeviceFlow_PreIssuedCode(t *testing.T) {
+	var polled string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case api.PathDeviceCode:
+			t.Error("requested a device code despite a pre-issued one")
+		case api.PathDeviceToken:
+			var req api.DeviceTokenRequest
+			json.NewDecoder(r.Body).Decode(&req)
+			polled = req.DeviceCode
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error":"expired_token"}`))
+		}
+	}))
+	defer server.Close()
+
+	_, cleanupHome := withTempHome(t)
+	defer cleanupHome()
+	cleanupURL := withAPIBaseURL(t, server.URL)
+	defer cleanupURL()
+
+	flow, err := NewDeviceFlow()
+	if err != nil {
+		t.Fatalf("NewDeviceFlow() error = %v", err)
+	}
+	flow.DeviceCode = "pre-issued"
+
+	if err := flow.Run(context.Background()); err == nil || !strings.Contains(err.Error(), "expired") {
+		t.Fatalf("Run() error = %v, want device code expired", err)
+	}
+	if polled != "pre-issued" {
+		t.Errorf("polled device code = %q, want %q", polled, "pre-issued")
+	}
+}
+
+// TestVerificationURIComplete verifies that the server's complete URI is
+// preferred and that the fallback adds user_code to any existing query.
+func TestVerificationURIComplete(t *testing.T) {
+	tests := []struct {
+		resp api.DeviceCodeResponse
+		want string
+	}{
+		{
+			api.DeviceCodeResponse{VerificationURI: "https://sunday.app/device", UserCode: "ABCD-1234", VerificationURIComplete: "https://sunday.app/d/ABCD1234"},
+			"https://sunday.app/d/ABCD1234",
+		},
+		{
+			api.DeviceCodeResponse{VerificationURI: "https://sunday.app/device", UserCode: "ABCD-1234"},
+			"https://sunday.app/device?user_code=ABCD-1234",
+		},
+		{
+			api.DeviceCodeResponse{VerificationURI: "https://sunday.app/device?lang=en", UserCode: "AB CD"},
+			"https://sunday.app/device?lang=en&user_code=AB+CD",
+		},
+	}
+	for _, tc := range tests {
+		if got := verificationURIComplete(&tc.resp); got != tc.want {
+			t.Errorf("verificationURIComplete(%+v) = %q, want %q", tc.resp, got, tc.want)
+		}
+	}
+}
+
+// TestGrantedScopes verifies that the scopes in the token response win and
+// that the requested ones are assumed when the server doesn't list any.
+func TestGrantedScopes(t *testing.T) {
+	if got := grantedScopes("read:inbox", []string{"read:inbox", "read:passwords"}); !slices.Equal(got, []string{"read:inbox"}) {
+		t.Errorf("grantedScopes(granted) = %v, want [read:inbox]", got)
+	}
+	if got := grantedScopes("", []string{"read:passwords"}); !slices.Equal(got, []string{"read:passwords"}) {
+		t.Errorf("grantedScopes(none listed) = %v, want [read:passwords]", got)
+	}
+	if got := grantedScopes("", nil); len(got) != 0 {
+		t.Errorf("grantedScopes(full access) = %v, want empty", got)
+	}
+}
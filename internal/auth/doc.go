@@ -9,4 +9,12 @@
 //
 // This flow is ideal for CLI tools as it doesn't require the application
 // to handle user credentials directly.
+//
+// BrowserFlow is an alternative for desktops: an authorization code flow
+// with PKCE that receives the code on a loopback redirect, so the user
+// doesn't have to enter a code. Both flows finish the same way, binding an
+// identity and unlocking encryption before saving the config.
+//
+// Automation doesn't log in at all: with SUNDAY_API_KEY set, api.NewClient
+// exchanges the key for access tokens itself (see api.EnvAPIKey).
 package auth
@@ -0,0 +1,263 @@
+package auth
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ravi-technologies/sunday-cli/internal/api"
+	"github.com/ravi-technologies/sunday-cli/internal/config"
+	"github.com/ravi-technologies/sunday-cli/internal/crypto"
+	"github.com/ravi-technologies/sunday-cli/internal/output"
+)
+
+// login finishes a login once a flow has obtained tokens: it binds an
+// identity, unlocks encryption and saves the config. It is shared by the
+// device and browser flows.
+type login struct {
+	client *api.Client
+
+	// identity, if set, names the identity to bind (by name or UUID)
+	// instead of prompting for one.
+	identity string
+
+	// scopes are the scopes the login asked for, if it was limited.
+	scopes []string
+}
+
+// complete turns the tokens issued by a login flow into a saved session.
+func (l *login) complete(ctx context.Context, tokenResp *api.DeviceTokenResponse) error {
+	// Start from the saved settings, if any, so they survive the login.
+	cfg := &config.Config{}
+	var prevKey string
+	if prev, err := config.Load(); err == nil {
+		cfg = prev.Settings()
+		prevKey = prev.PrivateKey
+	}
+	cfg.AccessToken = tokenResp.Access
+	cfg.RefreshToken = tokenResp.Refresh
+	cfg.ExpiresAt = time.Now().Add(api.TokenExpiryBuffer) // Assume ~5 min expiry
+	cfg.UserEmail = tokenResp.User.Email
+	cfg.SigningSecret = tokenResp.SigningSecret
+	cfg.Scopes = grantedScopes(tokenResp.Scope, l.scopes)
+
+	output.Current.PrintMessage(fmt.Sprintf("Authenticated as %s", tokenResp.User.Email))
+
+	// Recreate client with the new tokens (in memory only)
+	// so authenticated requests work before we persist.
+	var err error
+	l.client, err = api.NewClient(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to reinitialize client: %w", err)
+	}
+
+	// Select and bind an identity to this CLI session.
+	if err := l.selectAndBindIdentity(ctx, cfg); err != nil {
+		return fmt.Errorf("identity selection failed: %w", err)
+	}
+
+	// Prompt for PIN to unlock E2E decryption.
+	// If the user exits here (Ctrl+C), nothing is saved to disk.
+	if err := l.unlockEncryption(ctx, cfg); err != nil {
+		return fmt.Errorf("encryption unlock failed: %w", err)
+	}
+
+	// Save only after auth + identity + PIN are all complete.
+	if err := config.Save(cfg); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+	if prevKey != cfg.PrivateKey {
+		if err := crypto.DiscardWrapped(prevKey); err != nil {
+			slog.Warn("deleting the previous session's private key", "error", err)
+		}
+	}
+
+	return nil
+}
+
+// grantedScopes returns the scopes a token was issued with. As in OAuth,
+// a response that doesn't list them granted exactly what was requested.
+func grantedScopes(granted string, requested []string) []string {
+	if scopes := strings.Fields(granted); len(scopes) > 0 {
+		return scopes
+	}
+	return slices.Clone(requested)
+}
+
+// unlockEncryption fetches the user's encryption metadata, prompts for their
+// PIN, verifies it, and persists the derived private key in the config file
+// so subsequent commands can decrypt without re-prompting.
+func (l *login) unlockEncryption(ctx context.Context, cfg *config.Config) error {
+	meta, err := l.client.GetEncryptionMetaContext(ctx)
+	if err != nil {
+		return fmt.Errorf("fetching encryption metadata: %w", err)
+	}
+
+	if crypto.UsesManagedKey(meta.ManagedMasterKey, meta.PublicKey, meta.Verifier) {
+		// The CLI can't unlock a managed master key yet; like an account
+		// without encryption, commands that need decryption will error.
+		fmt.Printf("\n%s.\n", crypto.ErrManagedKey)
+		return nil
+	}
+	if meta.PublicKey == "" {
+		// User hasn't completed PIN setup on the dashboard yet.
+		// This is OK — CLI will error on commands that need decryption.
+		fmt.Println("\nEncryption not set up yet. Run `sunday crypto setup` or complete PIN setup on the dashboard to enable E2E decryption.")
+		return nil
+	}
+
+	fmt.Println()
+	params, err := crypto.NewKDFParams(meta.KDF.Algorithm, meta.KDF.OpsLimit, meta.KDF.MemLimit)
+	if err != nil {
+		return fmt.Errorf("rejecting server key derivation parameters: %w", err)
+	}
+	policy, err := crypto.NewPINPolicy(meta.PINPolicy.Kind, meta.PINPolicy.MinLength)
+	if err != nil {
+		return fmt.Errorf("rejecting server PIN policy: %w", err)
+	}
+	kp, err := crypto.GetOrPromptKeyPair(meta.Salt, meta.Verifier, params, policy)
+	if err != nil {
+		return err
+	}
+
+	// Verify that the locally-derived public key matches the server record.
+	derivedPub := base64.StdEncoding.EncodeToString(kp.PublicKey[:])
+	if derivedPub != meta.PublicKey {
+		return fmt.Errorf("derived public key does not match server record — possible data corruption")
+	}
+
+	// Unlocking updated the PIN lockout on disk; don't write back the one
+	// cfg was loaded with.
+	if onDisk, err := config.Load(); err == nil {
+		cfg.PINLockout = onDisk.PINLockout
+	}
+	cfg.PINSalt = meta.Salt
+	cfg.PublicKey = derivedPub
+	name, err := cfg.KeyProtectorName()
+	if err != nil {
+		return err
+	}
+	protector, err := crypto.NewKeyProtector(name, cfg.Crypto.YubiKeySlot)
+	if err != nil {
+		return err
+	}
+	if cfg.PrivateKey, err = protector.Wrap(kp.PrivateKey); err != nil {
+		return err
+	}
+	cfg.KeyUsedAt = time.Now()
+
+	output.Current.PrintMessage("Encryption unlocked")
+	return nil
+}
+
+// selectAndBindIdentity lists the user's identities and binds the chosen one
+// to the JWT session. The identity is then locked into all future API calls.
+func (l *login) selectAndBindIdentity(ctx context.Context, cfg *config.Config) error {
+	identities, err := l.client.ListIdentitiesContext(ctx)
+	if err != nil {
+		return fmt.Errorf("listing identities: %w", err)
+	}
+
+	if len(identities) == 0 {
+		return fmt.Errorf("no identities found — complete setup on the dashboard first")
+	}
+
+	var selected api.Identity
+
+	if l.identity != "" {
+		selected, err = matchIdentity(identities, l.identity)
+		if err != nil {
+			return err
+		}
+		output.Current.PrintMessage(fmt.Sprintf("Using identity: %s", identityLabel(selected)))
+	} else if len(identities) == 1 {
+		selected = identities[0]
+		output.Current.PrintMessage(fmt.Sprintf("Using identity: %s", identityLabel(selected)))
+	} else if !stdinIsTerminal() {
+		labels := make([]string, len(identities))
+		for i, id := range identities {
+			labels[i] = identityLabel(id)
+		}
+		return fmt.Errorf("multiple identities available (%s) but stdin is not a terminal — pass --identity or run `sunday auth login` interactively to choose one",
+			strings.Join(labels, ", "))
+	} else {
+		fmt.Println("\nSelect an identity for this CLI session:")
+		for i, id := range identities {
+			fmt.Printf("  %d) %s\n", i+1, identityLabel(id))
+		}
+		fmt.Print("> ")
+
+		reader := bufio.NewReader(os.Stdin)
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return fmt.Errorf("reading input: %w", err)
+		}
+		trimmed := strings.TrimSpace(line)
+		choice, err := strconv.Atoi(trimmed)
+		if err != nil {
+			return fmt.Errorf("invalid selection %q — enter a number between 1 and %d", trimmed, len(identities))
+		}
+		if choice < 1 || choice > len(identities) {
+			return fmt.Errorf("selection %d out of range — enter a number between 1 and %d", choice, len(identities))
+		}
+		selected = identities[choice-1]
+	}
+
+	// Bind the identity to the JWT.
+	bound, err := l.client.BindIdentityContext(ctx, selected.UUID)
+	if err != nil {
+		return fmt.Errorf("binding identity: %w", err)
+	}
+	if bound.Access == "" || bound.Refresh == "" {
+		return fmt.Errorf("binding identity: server returned empty tokens")
+	}
+
+	cfg.AccessToken = bound.Access
+	cfg.RefreshToken = bound.Refresh
+	cfg.ExpiresAt = time.Now().Add(api.TokenExpiryBuffer)
+	cfg.IdentityName = selected.Name
+	cfg.IdentityUUID = selected.UUID
+
+	// Recreate client with the bound tokens.
+	l.client, err = api.NewClient(cfg)
+	if err != nil {
+		return fmt.Errorf("reinitializing client after bind: %w", err)
+	}
+
+	output.Current.PrintMessage(fmt.Sprintf("Bound to identity: %s", identityLabel(selected)))
+	return nil
+}
+
+// matchIdentity returns the identity whose UUID is want or, failing that,
+// the only one whose name matches want ignoring case.
+func matchIdentity(identities []api.Identity, want string) (api.Identity, error) {
+	var byName []api.Identity
+	for _, id := range identities {
+		if id.UUID == want {
+			return id, nil
+		}
+		if strings.EqualFold(id.Name, want) {
+			byName = append(byName, id)
+		}
+	}
+
+	labels := make([]string, len(identities))
+	for i, id := range identities {
+		labels[i] = fmt.Sprintf("%s [%s]", identityLabel(id), id.UUID)
+	}
+	switch len(byName) {
+	case 1:
+		return byName[0], nil
+	case 0:
+		return api.Identity{}, fmt.Errorf("no identity named %q — available: %s", want, strings.Join(labels, ", "))
+	default:
+		return api.Identity{}, fmt.Errorf("several identities are named %q — pass a UUID instead: %s", want, strings.Join(labels, ", "))
+	}
+}
@@ -0,0 +1,20 @@
+package biometric
+
+import "errors"
+
+var (
+	// ErrUnavailable is returned when the system has no usable biometric
+	// sensor, or the binary was built without support for it.
+	ErrUnavailable = errors.New("Touch ID is not available on this system")
+
+	// ErrFailed is returned when the user cancels or fails the prompt.
+	ErrFailed = errors.New("Touch ID authentication failed")
+
+	// ErrNotFound is returned when no Touch ID-protected secret is stored
+	// under the account.
+	ErrNotFound = errors.New("no Touch ID-protected secret stored")
+)
+
+// keychainService is the Keychain service Touch ID-protected secrets are
+// stored under.
+const keychainService = "sunday-cli.touchid"
@@ -0,0 +1,64 @@
+//go:build darwin && cgo
+
+package biometric
+
+/*
+#cgo CFLAGS: -x objective-c -fobjc-arc
+#cgo LDFLAGS: -framework Foundation -framework LocalAuthentication
+#import <Foundation/Foundation.h>
+#import <LocalAuthentication/LocalAuthentication.h>
+#include <stdlib.h>
+
+static int biometricAvailable(void) {
+	@autoreleasepool {
+		LAContext *ctx = [[LAContext alloc] init];
+		return [ctx canEvaluatePolicy:LAPolicyDeviceOwnerAuthenticationWithBiometrics error:nil] ? 1 : 0;
+	}
+}
+
+// biometricAuthenticate returns 0 on success, 1 if biometrics are
+// unavailable, and 2 if the user failed or cancelled the prompt.
+static int biometricAuthenticate(const char *reason) {
+	@autoreleasepool {
+		LAContext *ctx = [[LAContext alloc] init];
+		if (![ctx canEvaluatePolicy:LAPolicyDeviceOwnerAuthenticationWithBiometrics error:nil]) {
+			return 1;
+		}
+
+		__block int result = 2;
+		dispatch_semaphore_t done = dispatch_semaphore_create(0);
+		[ctx evaluatePolicy:LAPolicyDeviceOwnerAuthenticationWithBiometrics
+		    localizedReason:[NSString stringWithUTF8String:reason]
+		              reply:^(BOOL success, NSError *error) {
+			result = success ? 0 : 2;
+			dispatch_semaphore_signal(done);
+		}];
+		dispatch_semaphore_wait(done, DISPATCH_TIME_FOREVER);
+		return result;
+	}
+}
+*/
+import "C"
+
+import "unsafe"
+
+// Available reports whether Touch ID can be used on this machine.
+func Available() bool {
+	return C.biometricAvailable() == 1
+}
+
+// Authenticate shows the Touch ID prompt with the given reason and blocks
+// until the user responds.
+func Authenticate(reason string) error {
+	cReason := C.CString(reason)
+	defer C.free(unsafe.Pointer(cReason))
+
+	switch C.biometricAuthenticate(cReason) {
+	case 0:
+		return nil
+	case 1:
+		return ErrUnavailable
+	default:
+		return ErrFailed
+	}
+}
@@ -0,0 +1,14 @@
+//go:build !darwin || !cgo
+
+package biometric
+
+// Available reports whether Touch ID can be used on this machine. It is
+// always false on this platform or build.
+func Available() bool {
+	return false
+}
+
+// Authenticate always returns ErrUnavailable on this platform or build.
+func Authenticate(reason string) error {
+	return ErrUnavailable
+}
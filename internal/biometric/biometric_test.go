@@ -0,0 +1,19 @@
+//go:build !darwin || !cgo
+
+package biometric
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestAuthenticate_Unavailable verifies that builds without Touch ID support
+// fail closed.
+func TestAuthenticate_Unavailable(t *testing.T) {
+	if Available() {
+		t.Error("Available() = true, want false")
+	}
+	if err := Authenticate("test"); !errors.Is(err, ErrUnavailable) {
+		t.Errorf("Authenticate() error = %v, want ErrUnavailable", err)
+	}
+}
@@ -0,0 +1,10 @@
+// Package biometric gates sensitive operations behind the platform's
+// biometric prompt.
+//
+// On macOS builds with cgo enabled it uses the LocalAuthentication framework
+// to request Touch ID, and the Security framework to keep secrets in
+// Keychain items whose access control requires Touch ID to read them, so
+// that the prompt is enforced by the system rather than by the caller.
+// Everywhere else Available reports false and the other functions return
+// ErrUnavailable, so callers that require biometrics fail closed.
+package biometric
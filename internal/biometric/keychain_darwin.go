@@ -0,0 +1,148 @@
+//go:build darwin && cgo
+
+package biometric
+
+/*
+#cgo CFLAGS: -x objective-c -fobjc-arc
+#cgo LDFLAGS: -framework Foundation -framework LocalAuthentication -framework Security
+#import <Foundation/Foundation.h>
+#import <LocalAuthentication/LocalAuthentication.h>
+#import <Security/Security.h>
+#include <stdlib.h>
+#include <string.h>
+
+static NSMutableDictionary *keychainQuery(const char *service, const char *account) {
+	return [@{
+		(__bridge id)kSecClass: (__bridge id)kSecClassGenericPassword,
+		(__bridge id)kSecAttrService: [NSString stringWithUTF8String:service],
+		(__bridge id)kSecAttrAccount: [NSString stringWithUTF8String:account],
+		(__bridge id)kSecUseDataProtectionKeychain: @YES,
+	} mutableCopy];
+}
+
+// keychainStore adds an item that can only be read after Touch ID, with
+// the fingerprints enrolled now. It is lost if the passcode is removed.
+static OSStatus keychainStore(const char *service, const char *account, const void *data, int len) {
+	@autoreleasepool {
+		SecAccessControlRef access = SecAccessControlCreateWithFlags(NULL,
+			kSecAttrAccessibleWhenPasscodeSetThisDeviceOnly, kSecAccessControlBiometryCurrentSet, NULL);
+		if (access == NULL) {
+			return errSecParam;
+		}
+		NSMutableDictionary *query = keychainQuery(service, account);
+		query[(__bridge id)kSecAttrAccessControl] = (__bridge_transfer id)access;
+		query[(__bridge id)kSecValueData] = [NSData dataWithBytes:data length:len];
+		return SecItemAdd((__bridge CFDictionaryRef)query, NULL);
+	}
+}
+
+// keychainLoad reads an item stored by keychainStore, which shows the
+// Touch ID prompt. *out is malloc'd and must be wiped and freed.
+static OSStatus keychainLoad(const char *service, const char *account, const char *reason, void **out, int *outLen) {
+	@autoreleasepool {
+		LAContext *ctx = [[LAContext alloc] init];
+		ctx.localizedReason = [NSString stringWithUTF8String:reason];
+		NSMutableDictionary *query = keychainQuery(service, account);
+		query[(__bridge id)kSecReturnData] = @YES;
+		query[(__bridge id)kSecMatchLimit] = (__bridge id)kSecMatchLimitOne;
+		query[(__bridge id)kSecUseAuthenticationContext] = ctx;
+
+		CFTypeRef result = NULL;
+		OSStatus status = SecItemCopyMatching((__bridge CFDictionaryRef)query, &result);
+		if (status != errSecSuccess) {
+			return status;
+		}
+		NSData *data = (__bridge_transfer NSData *)result;
+		*outLen = (int)data.length;
+		*out = malloc(data.length);
+		memcpy(*out, data.bytes, data.length);
+		return errSecSuccess;
+	}
+}
+
+static OSStatus keychainDelete(const char *service, const char *account) {
+	@autoreleasepool {
+		return SecItemDelete((__bridge CFDictionaryRef)keychainQuery(service, account));
+	}
+}
+*/
+import "C"
+
+import (
+	"errors"
+	"fmt"
+	"unsafe"
+)
+
+// StoreSecret stores secret in a Keychain item that only Touch ID can
+// read, under account.
+func StoreSecret(account string, secret []byte) error {
+	if len(secret) == 0 {
+		return fmt.Errorf("storing Touch ID-protected secret: empty secret")
+	}
+	cService, cAccount := C.CString(keychainService), C.CString(account)
+	defer C.free(unsafe.Pointer(cService))
+	defer C.free(unsafe.Pointer(cAccount))
+
+	status := C.keychainStore(cService, cAccount, unsafe.Pointer(&secret[0]), C.int(len(secret)))
+	if err := keychainError(int(status)); err != nil {
+		return fmt.Errorf("storing Touch ID-protected secret: %w", err)
+	}
+	return nil
+}
+
+// LoadSecret shows the Touch ID prompt with the given reason and, once the
+// user passes it, returns the secret stored under account.
+func LoadSecret(account, reason string) ([]byte, error) {
+	cService, cAccount, cReason := C.CString(keychainService), C.CString(account), C.CString(reason)
+	defer C.free(unsafe.Pointer(cService))
+	defer C.free(unsafe.Pointer(cAccount))
+	defer C.free(unsafe.Pointer(cReason))
+
+	var out unsafe.Pointer
+	var n C.int
+	if err := keychainError(int(C.keychainLoad(cService, cAccount, cReason, &out, &n))); err != nil {
+		return nil, err
+	}
+	defer C.free(out)
+	defer C.memset(out, 0, C.size_t(n))
+	return C.GoBytes(out, n), nil
+}
+
+// DeleteSecret deletes the item stored under account. Deleting one that
+// doesn't exist is not an error.
+func DeleteSecret(account string) error {
+	cService, cAccount := C.CString(keychainService), C.CString(account)
+	defer C.free(unsafe.Pointer(cService))
+	defer C.free(unsafe.Pointer(cAccount))
+
+	if err := keychainError(int(C.keychainDelete(cService, cAccount))); err != nil && !errors.Is(err, ErrNotFound) {
+		return fmt.Errorf("deleting Touch ID-protected secret: %w", err)
+	}
+	return nil
+}
+
+// Security framework result codes keychainError knows.
+const (
+	errSecSuccess            = 0
+	errSecUserCanceled       = -128
+	errSecAuthFailed         = -25293
+	errSecNotAvailable       = -25291
+	errSecItemNotFound       = -25300
+	errSecMissingEntitlement = -34018
+)
+
+// keychainError maps a Security framework result code to an error.
+func keychainError(status int) error {
+	switch status {
+	case errSecSuccess:
+		return nil
+	case errSecItemNotFound:
+		return ErrNotFound
+	case errSecUserCanceled, errSecAuthFailed:
+		return ErrFailed
+	case errSecNotAvailable, errSecMissingEntitlement:
+		return ErrUnavailable
+	}
+	return fmt.Errorf("keychain error %d", status)
+}
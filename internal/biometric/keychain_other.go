@@ -0,0 +1,18 @@
+//go:build !darwin || !cgo
+
+package biometric
+
+// StoreSecret always returns ErrUnavailable on this platform or build.
+func StoreSecret(account string, secret []byte) error {
+	return ErrUnavailable
+}
+
+// LoadSecret always returns ErrUnavailable on this platform or build.
+func LoadSecret(account, reason string) ([]byte, error) {
+	return nil, ErrUnavailable
+}
+
+// DeleteSecret always returns ErrUnavailable on this platform or build.
+func DeleteSecret(account string) error {
+	return ErrUnavailable
+}
@@ -0,0 +1,161 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"time"
+)
+
+// EnvAccount selects the active account, like the --account flag.
+const EnvAccount = "SUNDAY_ACCOUNT"
+
+// account is the account set with SetAccount. Empty means use
+// SUNDAY_ACCOUNT or the main account.
+var account string
+
+// Account holds the credentials of one named account. A config file can
+// hold several alongside the main account's credentials at the top level,
+// so one profile can stay logged in to more than one Sunday account.
+type Account struct {
+	AccessToken   string    `json:"access_token"`
+	RefreshToken  string    `json:"refresh_token"`
+	ExpiresAt     time.Time `json:"expires_at"`
+	UserEmail     string    `json:"user_email,omitempty"`
+	IdentityName  string    `json:"identity_name,omitempty"`
+	IdentityUUID  string    `json:"identity_uuid,omitempty"`
+	PINSalt       string    `json:"pin_salt,omitempty"`
+	PublicKey     string    `json:"public_key,omitempty"`
+	PrivateKey    string    `json:"private_key,omitempty"`
+	KeyUsedAt     time.Time `json:"key_used_at,omitzero"`
+	SigningSecret string    `json:"signing_secret,omitempty"`
+	KeyringTokens bool      `json:"keyring_tokens,omitempty"`
+	Scopes        []string  `json:"scopes,omitempty"`
+}
+
+// SetAccount selects the named account for the rest of the process: Load
+// and Save then read and write its credentials instead of the main
+// account's. An empty name restores the default selection.
+func SetAccount(name string) {
+	account = name
+}
+
+// ValidateAccountName reports whether name can be used as an account name.
+func ValidateAccountName(name string) error {
+	if !profileNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid account name %q: use letters, digits, '-' and '_'", name)
+	}
+	return nil
+}
+
+// ActiveAccount returns the account in use: the one set with SetAccount,
+// else SUNDAY_ACCOUNT, else "" for the main account.
+func ActiveAccount() string {
+	if account != "" {
+		return account
+	}
+	return os.Getenv(EnvAccount)
+}
+
+// AccountExists reports whether the active profile's config holds the
+// named account.
+func AccountExists(name string) (bool, error) {
+	cfg, err := readFile()
+	if err != nil {
+		return false, err
+	}
+	_, ok := cfg.Accounts[name]
+	return ok, nil
+}
+
+// ListAccounts returns the names of the accounts in the active profile's
+// config, sorted, not including the main account.
+func ListAccounts() ([]string, error) {
+	cfg, err := readFile()
+	if err != nil {
+		return nil, err
+	}
+	return slices.Sorted(maps.Keys(cfg.Accounts)), nil
+}
+
+// accountOf returns the credentials held at the top level of cfg.
+func accountOf(cfg *Config) *Account {
+	return &Account{
+		AccessToken:   cfg.AccessToken,
+		RefreshToken:  cfg.RefreshToken,
+		ExpiresAt:     cfg.ExpiresAt,
+		UserEmail:     cfg.UserEmail,
+		IdentityName:  cfg.IdentityName,
+		IdentityUUID:  cfg.IdentityUUID,
+		PINSalt:       cfg.PINSalt,
+		PublicKey:     cfg.PublicKey,
+		PrivateKey:    cfg.PrivateKey,
+		KeyUsedAt:     cfg.KeyUsedAt,
+		SigningSecret: cfg.SigningSecret,
+		KeyringTokens: cfg.KeyringTokens,
+		Scopes:        cfg.Scopes,
+	}
+}
+
+// setCredentials replaces the credentials at the top level of cfg with
+// a's; a nil a clears them.
+func (c *Config) setCredentials(a *Account) {
+	if a == nil {
+		a = &Account{}
+	}
+	c.AccessToken = a.AccessToken
+	c.RefreshToken = a.RefreshToken
+	c.ExpiresAt = a.ExpiresAt
+	c.UserEmail = a.UserEmail
+	c.IdentityName = a.IdentityName
+	c.IdentityUUID = a.IdentityUUID
+	c.PINSalt = a.PINSalt
+	c.PublicKey = a.PublicKey
+	c.PrivateKey = a.PrivateKey
+	c.KeyUsedAt = a.KeyUsedAt
+	c.SigningSecret = a.SigningSecret
+	c.KeyringTokens = a.KeyringTokens
+	c.Scopes = a.Scopes
+}
+
+// mergeAccounts returns the config to write when saving onDisk, the
+// active account's credentials as they should appear in the file. Other
+// accounts are taken from the file as it is, so saving one account never
+// overwrites another.
+func mergeAccounts(onDisk *Config) (*Config, error) {
+	current, err := readFile()
+	if err != nil && ActiveAccount() != "" {
+		return nil, err
+	}
+	if err != nil {
+		// An unreadable file is replaced, as it was before accounts.
+		current = &Config{}
+	}
+	merged := *onDisk
+	merged.Accounts = maps.Clone(current.Accounts)
+
+	name := ActiveAccount()
+	if name == "" {
+		return &merged, nil
+	}
+	merged.setCredentials(accountOf(current))
+	if merged.Accounts == nil {
+		merged.Accounts = make(map[string]*Account)
+	}
+	merged.Accounts[name] = accountOf(onDisk)
+	return &merged, nil
+}
+
+// removeAccount deletes the named account from the config file.
+func removeAccount(name string) error {
+	cfg, err := readFile()
+	if err != nil {
+		return err
+	}
+	if _, ok := cfg.Accounts[name]; !ok {
+		return nil
+	}
+	delete(cfg.Accounts, name)
+	return writeFile(cfg)
+}
@@ -0,0 +1,135 @@
+package config
+
+import (
+	"slices"
+	"testing"
+)
+
+// TestAccountsKeepSeparateCredentials verifies that saving a named account
+// leaves the main account's credentials alone, and that Load returns the
+// selected account's.
+func TestAccountsKeepSeparateCredentials(t *testing.T) {
+	_, cleanup := withTempHome(t)
+	defer cleanup()
+	defer SetAccount("")
+
+	if err := Save(&Config{AccessToken: "main-token", UserEmail: "me@example.com", API: APISettings{MaxResponseBytes: 1024}}); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	SetAccount("work")
+	if exists, _ := AccountExists("work"); exists {
+		t.Error("AccountExists(work) = true before logging in")
+	}
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.AccessToken != "" || cfg.API.MaxResponseBytes != 1024 {
+		t.Errorf("new account = %+v, want no credentials but the shared settings", cfg)
+	}
+	cfg.AccessToken, cfg.UserEmail = "work-token", "me@work.example"
+	if err := Save(cfg); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.AccessToken != "work-token" || cfg.UserEmail != "me@work.example" {
+		t.Errorf("work account = %q/%q, want work-token/me@work.example", cfg.AccessToken, cfg.UserEmail)
+	}
+
+	SetAccount("")
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.AccessToken != "main-token" {
+		t.Errorf("main AccessToken = %q, want main-token", cfg.AccessToken)
+	}
+
+	// Saving the main account keeps the others.
+	if err := Save(&Config{AccessToken: "main-token-2"}); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if names, _ := ListAccounts(); !slices.Equal(names, []string{"work"}) {
+		t.Errorf("ListAccounts() = %v, want [work]", names)
+	}
+}
+
+// TestClear_Account verifies that logging out of a named account removes
+// only that account.
+func TestClear_Account(t *testing.T) {
+	_, cleanup := withTempHome(t)
+	defer cleanup()
+	defer SetAccount("")
+
+	if err := Save(&Config{AccessToken: "main-token"}); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	for _, name := range []string{"work", "side"} {
+		SetAccount(name)
+		if err := Save(&Config{AccessToken: name + "-token"}); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+	}
+
+	SetAccount("work")
+	if err := Clear(); err != nil {
+		t.Fatalf("Clear() error = %v", err)
+	}
+	SetAccount("")
+	if names, _ := ListAccounts(); !slices.Equal(names, []string{"side"}) {
+		t.Errorf("ListAccounts() = %v, want [side]", names)
+	}
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.AccessToken != "main-token" {
+		t.Errorf("main AccessToken = %q, want main-token", cfg.AccessToken)
+	}
+
+	// Logging out of the main account keeps the named ones.
+	if err := Clear(); err != nil {
+		t.Fatalf("Clear() error = %v", err)
+	}
+	if names, _ := ListAccounts(); !slices.Equal(names, []string{"side"}) {
+		t.Errorf("after main logout ListAccounts() = %v, want [side]", names)
+	}
+}
+
+// TestAccounts_Keyring verifies that each account's tokens get their own
+// keyring entry.
+func TestAccounts_Keyring(t *testing.T) {
+	mem := withKeyring(t, TokenStoreAuto)
+	defer SetAccount("")
+
+	if err := Save(&Config{AccessToken: "main-token", RefreshToken: "main-refresh"}); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	SetAccount("work")
+	if err := Save(&Config{AccessToken: "work-token", RefreshToken: "work-refresh"}); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if len(mem.secrets) != 2 {
+		t.Errorf("keyring has %d entries, want 2", len(mem.secrets))
+	}
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.AccessToken != "work-token" {
+		t.Errorf("work AccessToken = %q, want work-token", cfg.AccessToken)
+	}
+	SetAccount("")
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.AccessToken != "main-token" {
+		t.Errorf("main AccessToken = %q, want main-token", cfg.AccessToken)
+	}
+}
@@ -3,8 +3,11 @@ package config
 import (
 	"encoding/json"
 	"fmt"
+	"log/slog"
 	"os"
 	"path/filepath"
+	"runtime"
+	"slices"
 	"time"
 )
 
@@ -22,68 +25,485 @@ type Config struct {
 	ExpiresAt    time.Time `json:"expires_at"`
 	UserEmail    string    `json:"user_email,omitempty"`
 	IdentityName string    `json:"identity_name,omitempty"`
+	IdentityUUID string    `json:"identity_uuid,omitempty"`
 	PINSalt      string    `json:"pin_salt,omitempty"`
 	PublicKey    string    `json:"public_key,omitempty"`
 	PrivateKey   string    `json:"private_key,omitempty"`
+
+	// KeyUsedAt is when the private key was last used, for locking it
+	// after inactivity (see CryptoSettings.UnlockTTL).
+	KeyUsedAt time.Time `json:"key_used_at,omitzero"`
+
+	// SigningSecret is the per-device key used to sign API requests, if
+	// the backend issued one at login.
+	SigningSecret string `json:"signing_secret,omitempty"`
+
+	// KeyringTokens records that the tokens above were saved to the OS
+	// keyring rather than this file. Load restores them transparently.
+	KeyringTokens bool `json:"keyring_tokens,omitempty"`
+
+	// Scopes lists the scopes the session's token was limited to at
+	// login. Empty means full access.
+	Scopes []string `json:"scopes,omitempty"`
+
+	// API holds user-tunable API client settings. Unlike the credentials
+	// above, settings survive logout.
+	API APISettings `json:"api,omitzero"`
+
+	// Security holds settings that gate access to sensitive material.
+	Security SecuritySettings `json:"security,omitzero"`
+
+	// Storage controls where credentials are kept.
+	Storage StorageSettings `json:"storage,omitzero"`
+
+	// Auth holds defaults for auth login.
+	Auth AuthSettings `json:"auth,omitzero"`
+
+	// Hooks holds commands run when the session changes.
+	Hooks HookSettings `json:"hooks,omitzero"`
+
+	// Crypto holds settings for the encryption key.
+	Crypto CryptoSettings `json:"crypto,omitzero"`
+
+	// PINLockout counts incorrect PINs across runs, so that starting the
+	// CLI again doesn't reset the limit on guesses. Like the settings
+	// above, it survives logout.
+	PINLockout PINLockout `json:"pin_lockout,omitzero"`
+
+	// Accounts holds the credentials of additional named accounts. The
+	// fields above are the main account's; Load and Save swap in those of
+	// the account selected with SetAccount.
+	Accounts map[string]*Account `json:"accounts,omitempty"`
 }
 
-// Path returns the path to the config file (~/.sunday/config.json).
-func Path() string {
+// APISettings holds user-tunable options for the API client. Zero values
+// mean "use the built-in default".
+type APISettings struct {
+	// BaseURL is the API to talk to instead of the one built in, e.g. a
+	// staging server.
+	BaseURL string `json:"base_url,omitempty"`
+
+	// MaxResponseBytes caps the size of a single API response body.
+	MaxResponseBytes int64 `json:"max_response_bytes,omitempty"`
+
+	// ClientCert and ClientKey are PEM files presented for mutual TLS,
+	// e.g. to an enterprise gateway in front of the API.
+	ClientCert string `json:"client_cert,omitempty"`
+	ClientKey  string `json:"client_key,omitempty"`
+
+	// CABundle is a PEM file of extra CAs to trust, on top of the system
+	// roots.
+	CABundle string `json:"ca_bundle,omitempty"`
+
+	// PinSHA256 pins the server's public key: comma-separated base64
+	// SHA-256 hashes of its SubjectPublicKeyInfo. Connections to a server
+	// with any other key fail.
+	PinSHA256 string `json:"pin_sha256,omitempty"`
+
+	// Timeout is how long a request may take, as a duration such as
+	// "2m", e.g. for large threads on a slow link.
+	Timeout string `json:"timeout,omitempty"`
+
+	// ProxyURL is the proxy to reach the API through, e.g.
+	// "http://proxy.corp:3128" or "socks5://localhost:1080". Empty means
+	// use HTTPS_PROXY, HTTP_PROXY and NO_PROXY.
+	ProxyURL string `json:"proxy_url,omitempty"`
+
+	// MaxIdleConns is how many idle connections to the API are kept open
+	// for reuse, which matters for watch modes and batch fetches.
+	MaxIdleConns int `json:"max_idle_conns,omitempty"`
+
+	// IdleConnTimeout is how long an idle connection is kept open, and
+	// TLSHandshakeTimeout how long a TLS handshake may take, as durations
+	// such as "90s".
+	IdleConnTimeout     string `json:"idle_conn_timeout,omitempty"`
+	TLSHandshakeTimeout string `json:"tls_handshake_timeout,omitempty"`
+}
+
+// AuthSettings holds defaults for auth login. Zero values mean "use what
+// the server asks for".
+type AuthSettings struct {
+	// LoginTimeoutSeconds stops waiting for a login to be approved after
+	// this long, rather than when the device code expires.
+	LoginTimeoutSeconds int `json:"login_timeout_seconds,omitempty"`
+
+	// PollIntervalSeconds polls for device flow approval less often than
+	// the server's interval. It can't be shorter.
+	PollIntervalSeconds int `json:"poll_interval_seconds,omitempty"`
+}
+
+// HookSettings holds shell commands run when the session changes, e.g. to
+// sync other tools. They get the user and identity in the environment.
+type HookSettings struct {
+	// PostLogin runs after a successful login.
+	PostLogin string `json:"post_login,omitempty"`
+
+	// PostLogout runs after the credentials have been cleared.
+	PostLogout string `json:"post_logout,omitempty"`
+}
+
+// CryptoSettings holds settings for the encryption key.
+type CryptoSettings struct {
+	// UnlockTTL locks the encryption key after it has gone unused this
+	// long, as a duration such as "8h". KeyUsedAt plus the TTL is a hard
+	// expiry: past it, Load and Update wipe the stored private key, in
+	// the file as well, whatever command reads the config. The next
+	// command that decrypts prompts for the PIN again. Empty means the
+	// key stays unlocked until logout.
+	UnlockTTL string `json:"unlock_ttl,omitempty"`
+
+	// KeyProtector is how the private key is protected while stored:
+	// "software" (the default), "yubikey", which wraps it so that only
+	// a YubiKey can unwrap it, or "touchid", which keeps it in a Keychain
+	// item only Touch ID can read. YubiKeySlot is the YubiKey's
+	// challenge-response slot, 2 unless set.
+	KeyProtector string `json:"key_protector,omitempty"`
+	YubiKeySlot  int    `json:"yubikey_slot,omitempty"`
+}
+
+// PINLockout records how many incorrect PINs were entered in a row, and
+// until when the PIN may not be tried again.
+type PINLockout struct {
+	Failures int       `json:"failures,omitempty"`
+	Until    time.Time `json:"until,omitzero"`
+}
+
+// Operations that can be gated behind Touch ID via SecuritySettings.TouchID.
+const (
+	TouchIDRevealPassword = "reveal_password"
+	TouchIDLoadPrivateKey = "load_private_key"
+)
+
+// SecuritySettings holds options that gate access to sensitive material.
+type SecuritySettings struct {
+	// TouchID lists the operations that require Touch ID before they run
+	// (macOS only). See the TouchID* constants for valid values.
+	TouchID []string `json:"touch_id,omitempty"`
+}
+
+// RequiresTouchID reports whether op is gated behind Touch ID.
+func (s SecuritySettings) RequiresTouchID(op string) bool {
+	return slices.Contains(s.TouchID, op)
+}
+
+// KeyProtectorName returns the crypto.key_protector to store the private
+// key with. Gating load_private_key behind Touch ID means "touchid", so
+// that the Keychain enforces the prompt rather than this setting alone.
+func (c *Config) KeyProtectorName() (string, error) {
+	if !c.Security.RequiresTouchID(TouchIDLoadPrivateKey) {
+		return c.Crypto.KeyProtector, nil
+	}
+	switch c.Crypto.KeyProtector {
+	case "", "software", "touchid":
+		return "touchid", nil
+	}
+	return "", fmt.Errorf("security.touch_id %s can't be combined with crypto.key_protector %s", TouchIDLoadPrivateKey, c.Crypto.KeyProtector)
+}
+
+// hasSettings reports whether cfg holds any user settings worth keeping
+// across logout.
+func (c *Config) hasSettings() bool {
+	return c.API != (APISettings{}) || len(c.Security.TouchID) > 0 || c.Storage != (StorageSettings{}) || c.Auth != (AuthSettings{}) || c.Hooks != (HookSettings{}) || c.Crypto != (CryptoSettings{}) || c.PINLockout.Failures > 0
+}
+
+// Settings returns a config holding only cfg's user settings, and the PIN
+// lockout, without credentials. Logging in starts from it so settings
+// survive a new login.
+func (c *Config) Settings() *Config {
+	return &Config{API: c.API, Security: c.Security, Storage: c.Storage, Auth: c.Auth, Hooks: c.Hooks, Crypto: c.Crypto, PINLockout: c.PINLockout}
+}
+
+// EnvConfig names an alternate config location, like the --config flag.
+const EnvConfig = "SUNDAY_CONFIG"
+
+// location is the config location set with SetLocation. Empty means use
+// SUNDAY_CONFIG or ~/.sunday.
+var location string
+
+// SetLocation points the CLI at an alternate config location for the rest
+// of the process. A path ending in .json names the config file itself,
+// with other CLI state kept alongside it; any other path names a directory
+// used in place of ~/.sunday. An empty path restores the default.
+func SetLocation(path string) {
+	location = path
+}
+
+// resolveLocation returns the configured base directory, and the config
+// file if the location names one, or empty strings if the default
+// location is in use.
+func resolveLocation() (dir, file string) {
+	loc := location
+	if loc == "" {
+		loc = os.Getenv(EnvConfig)
+	}
+	if loc == "" {
+		return "", ""
+	}
+	if filepath.Ext(loc) == ".json" {
+		return filepath.Dir(loc), loc
+	}
+	return loc, ""
+}
+
+// Dir returns the data directory of the active profile. The config file
+// and per-account state such as contacts live here. For the default
+// profile it is BaseDir; other profiles live under BaseDir/profiles. A
+// config file named with SetLocation or SUNDAY_CONFIG disables profiles.
+func Dir() string {
+	if _, file := resolveLocation(); file != "" {
+		return filepath.Dir(file)
+	}
+	if p := ActiveProfile(); p != DefaultProfile {
+		return ProfileDir(p)
+	}
+	return BaseDir()
+}
+
+// BaseDir returns the Sunday base directory (~/.sunday, unless overridden
+// with SetLocation or SUNDAY_CONFIG). State shared by all profiles, such
+// as logs, lives here.
+func BaseDir() string {
+	if dir, _ := resolveLocation(); dir != "" {
+		return dir
+	}
+
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
+		// USERPROFILE can be unset for Windows service accounts, but
+		// APPDATA is still a per-user location.
+		if appData := os.Getenv("APPDATA"); runtime.GOOS == "windows" && appData != "" {
+			return filepath.Join(appData, configDirName)
+		}
 		// Fall back to current directory if home dir unavailable
-		return filepath.Join(".", configDirName, configFileName)
+		return filepath.Join(".", configDirName)
+	}
+	return filepath.Join(homeDir, configDirName)
+}
+
+// EnsureDir creates the Sunday data directory with restricted permissions
+// if it doesn't exist, and returns its path.
+func EnsureDir() (string, error) {
+	dir := Dir()
+	if err := os.MkdirAll(dir, configDirPerm); err != nil {
+		return "", fmt.Errorf("creating config directory: %w", err)
+	}
+	return dir, nil
+}
+
+// Path returns the path to the active profile's config file
+// (~/.sunday/config.json for the default profile, unless overridden).
+func Path() string {
+	if _, file := resolveLocation(); file != "" {
+		return file
 	}
-	return filepath.Join(homeDir, configDirName, configFileName)
+	return filepath.Join(Dir(), configFileName)
 }
 
 // Load reads the config from disk. Returns an empty config if the file doesn't exist.
+// If an account is selected with SetAccount, its credentials take the
+// place of the main account's. A private key past its crypto.unlock_ttl
+// is wiped, in the file as well.
 func Load() (*Config, error) {
-	path := Path()
+	cfg, stale, err := readFileSecrets()
+	if err != nil {
+		return nil, err
+	}
+	if stale {
+		// Written before secrets were encrypted at rest, or with the
+		// legacy key; rewrite it with the current one. Failing to is no
+		// reason to fail the command.
+		if err := withLock(sealFile); err != nil {
+			slog.Warn("encrypting config secrets", "error", err)
+		}
+	}
+	if cfg, err = activate(cfg); err != nil {
+		return nil, err
+	}
+	lockExpiredKey(cfg)
+	return cfg, nil
+}
+
+// Update loads the config, lets fn change it and saves it, holding the
+// config file's lock throughout. Unlike a Load followed by a Save, it
+// can't overwrite a change another process saves in between, such as a
+// token refresh or an incorrect PIN. If fn returns an error nothing is
+// saved. Like Load, it wipes a private key past its crypto.unlock_ttl
+// before fn sees the config.
+func Update(fn func(*Config) error) error {
+	var expired string
+	err := withLock(func() error {
+		cfg, _, err := readFileSecrets()
+		if err != nil {
+			return err
+		}
+		if cfg, err = activate(cfg); err != nil {
+			return err
+		}
+		expired = expireKey(cfg)
+		if err := fn(cfg); err != nil {
+			return err
+		}
+		return save(cfg)
+	})
+	if err == nil {
+		keyLocked(expired)
+	}
+	return err
+}
+
+// activate returns cfg, as read from the file, with the selected
+// account's credentials in place and tokens fetched from the keyring.
+func activate(cfg *Config) (*Config, error) {
+	if name := ActiveAccount(); name != "" {
+		cfg.setCredentials(cfg.Accounts[name])
+	}
+	if err := loadSecrets(cfg); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// sealFile rewrites the config file with its secrets encrypted with the
+// machine key, if any are still in plaintext or sealed with the legacy
+// key. The caller must hold the lock.
+func sealFile() error {
+	cfg, stale, err := readFileSecrets()
+	if err != nil || !stale {
+		return err
+	}
+	return writeFile(cfg)
+}
 
-	data, err := os.ReadFile(path)
+// readFile reads the config file as it is on disk, with its secrets
+// decrypted but without selecting an account or fetching tokens from the
+// keyring.
+func readFile() (*Config, error) {
+	cfg, _, err := readFileSecrets()
+	return cfg, err
+}
+
+// readFileSecrets is readFile, also reporting whether any secrets need
+// sealing again (see unsealSecrets).
+func readFileSecrets() (cfg *Config, stale bool, err error) {
+	data, err := os.ReadFile(Path())
 	if err != nil {
 		if os.IsNotExist(err) {
-			return &Config{}, nil
+			return &Config{}, false, nil
 		}
-		return nil, fmt.Errorf("reading config file: %w", err)
+		return nil, false, fmt.Errorf("reading config file: %w", err)
 	}
 
-	var cfg Config
-	if err := json.Unmarshal(data, &cfg); err != nil {
-		return nil, fmt.Errorf("parsing config file: %w", err)
+	cfg = &Config{}
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return nil, false, fmt.Errorf("parsing config file: %w", err)
 	}
-
-	return &cfg, nil
+	if stale, err = unsealSecrets(cfg); err != nil {
+		return nil, false, err
+	}
+	return cfg, stale, nil
 }
 
-// Save writes the config to disk, creating the directory if needed.
+// Save writes the config to disk, creating the directory if needed. Tokens
+// go to the OS keyring instead of the file when the token store allows it
+// (see StorageSettings).
+//
+// With an account selected by SetAccount, the credentials are saved as
+// that account's, leaving the main account's and any others untouched.
+//
+// Save replaces the whole config; to change part of it, use Update.
 func Save(cfg *Config) error {
+	return withLock(func() error { return save(cfg) })
+}
+
+// save is Save for a caller holding the lock.
+func save(cfg *Config) error {
+	onDisk, err := storeSecrets(cfg)
+	if err != nil {
+		return err
+	}
+	merged, err := mergeAccounts(onDisk)
+	if err != nil {
+		return err
+	}
+	return writeFile(merged)
+}
+
+// writeFile writes cfg to the config file as it is, apart from encrypting
+// its secrets (see sealSecrets).
+func writeFile(cfg *Config) error {
 	path := Path()
-	dir := filepath.Dir(path)
 
-	// Create config directory with restricted permissions
-	if err := os.MkdirAll(dir, configDirPerm); err != nil {
-		return fmt.Errorf("creating config directory: %w", err)
+	if _, err := EnsureDir(); err != nil {
+		return err
 	}
 
-	data, err := json.MarshalIndent(cfg, "", "  ")
+	sealed := *cfg
+	if cfg.Accounts != nil {
+		sealed.Accounts = make(map[string]*Account, len(cfg.Accounts))
+		for name, a := range cfg.Accounts {
+			if a != nil {
+				copied := *a
+				sealed.Accounts[name] = &copied
+			}
+		}
+	}
+	if err := sealSecrets(&sealed); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(&sealed, "", "  ")
 	if err != nil {
 		return fmt.Errorf("encoding config: %w", err)
 	}
 
-	if err := os.WriteFile(path, data, configFilePerm); err != nil {
+	// Write to a temporary file and rename it into place so that concurrent
+	// readers (e.g. a long-running command reloading the config) never see
+	// a partially written file.
+	tmp, err := os.CreateTemp(filepath.Dir(path), configFileName+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("writing config file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := tmp.Chmod(configFilePerm); err != nil {
+		tmp.Close()
+		return fmt.Errorf("writing config file: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("writing config file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("writing config file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
 		return fmt.Errorf("writing config file: %w", err)
 	}
 
 	return nil
 }
 
-// Clear deletes the config file. Returns nil if the file doesn't exist.
+// Clear removes stored credentials, including any in the OS keyring. User
+// settings are preserved; if there are none, the config file is deleted.
+// Returns nil if the file doesn't exist.
+//
+// With an account selected by SetAccount, only that account is removed.
 func Clear() error {
-	path := Path()
+	deleteSecrets()
+	return withLock(clearFile)
+}
+
+// clearFile is Clear's change to the config file. The caller must hold
+// the lock.
+func clearFile() error {
+	if name := ActiveAccount(); name != "" {
+		return removeAccount(name)
+	}
+	cfg, err := readFile()
+	if err == nil && (cfg.hasSettings() || len(cfg.Accounts) > 0) {
+		return save(cfg.Settings())
+	}
 
-	if err := os.Remove(path); err != nil {
+	if err := os.Remove(Path()); err != nil {
 		if os.IsNotExist(err) {
 			return nil
 		}
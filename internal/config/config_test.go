@@ -2,6 +2,7 @@ package config
 
 import (
 	"encoding/json"
+	"errors"
 	"os"
 	"path/filepath"
 	"runtime"
@@ -31,6 +32,11 @@ func withTempHome(t *testing.T) (tmpDir string, cleanup func()) {
 		t.Fatalf("Failed to set %s: %v", homeEnvVar, err)
 	}
 
+	// Keep tokens in the temp config file rather than the OS keyring.
+	t.Setenv(EnvTokenStore, TokenStoreFile)
+	t.Setenv(EnvProfile, "")
+	t.Setenv(EnvAccount, "")
+
 	cleanup = func() {
 		os.Setenv(homeEnvVar, originalHome)
 	}
@@ -434,6 +440,39 @@ func TestClear_NoFile(t *testing.T) {
 	}
 }
 
+// TestUpdate verifies that Update changes only what fn changes in the
+// config on disk, and saves nothing if fn fails.
+func TestUpdate(t *testing.T) {
+	_, cleanup := withTempHome(t)
+	defer cleanup()
+
+	if err := Save(&Config{AccessToken: "token", PublicKey: "pub"}); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	err := Update(func(cfg *Config) error {
+		cfg.PINLockout.Failures++
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	err = Update(func(cfg *Config) error {
+		cfg.PublicKey = "changed"
+		return errors.New("failed")
+	})
+	if err == nil {
+		t.Fatal("Update() error = nil, want fn's error")
+	}
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.AccessToken != "token" || cfg.PublicKey != "pub" || cfg.PINLockout.Failures != 1 {
+		t.Errorf("config = %+v, want the saved one with one PIN failure", cfg)
+	}
+}
+
 // TestConfig_JSONMarshaling verifies that Config marshals/unmarshals correctly
 func TestConfig_JSONMarshaling(t *testing.T) {
 	testCases := []struct {
@@ -620,3 +659,152 @@ func TestPath_Structure(t *testing.T) {
 		t.Errorf("Path() = %v, missing config.json after .sunday", path)
 	}
 }
+
+// TestClear_PreservesSettings verifies that Clear removes credentials but
+// keeps user settings.
+func TestClear_PreservesSettings(t *testing.T) {
+	_, cleanup := withTempHome(t)
+	defer cleanup()
+
+	testConfig := &Config{
+		AccessToken:  "to-be-deleted",
+		RefreshToken: "to-be-deleted",
+		API:          APISettings{MaxResponseBytes: 1024},
+		Security:     SecuritySettings{TouchID: []string{TouchIDRevealPassword}},
+		Auth:         AuthSettings{LoginTimeoutSeconds: 60},
+	}
+	if err := Save(testConfig); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	if err := Clear(); err != nil {
+		t.Fatalf("Clear() error = %v", err)
+	}
+
+	loaded, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if loaded.AccessToken != "" || loaded.RefreshToken != "" {
+		t.Errorf("Clear() left credentials behind: %+v", loaded)
+	}
+	if loaded.API.MaxResponseBytes != 1024 {
+		t.Errorf("API.MaxResponseBytes = %d, want 1024", loaded.API.MaxResponseBytes)
+	}
+	if !loaded.Security.RequiresTouchID(TouchIDRevealPassword) {
+		t.Errorf("Security.TouchID = %v, want it to survive Clear()", loaded.Security.TouchID)
+	}
+	if loaded.Auth.LoginTimeoutSeconds != 60 {
+		t.Errorf("Auth.LoginTimeoutSeconds = %d, want 60", loaded.Auth.LoginTimeoutSeconds)
+	}
+}
+
+// TestConfig_OmitZeroSettings verifies that unset settings are not written.
+func TestConfig_OmitZeroSettings(t *testing.T) {
+	data, err := json.Marshal(Config{AccessToken: "token"})
+	if err != nil {
+		t.Fatalf("Failed to marshal config: %v", err)
+	}
+	if strings.Contains(string(data), `"api"`) {
+		t.Errorf("Expected api settings to be omitted when empty, got: %s", data)
+	}
+	if strings.Contains(string(data), `"security"`) {
+		t.Errorf("Expected security settings to be omitted when empty, got: %s", data)
+	}
+}
+
+// TestSecuritySettings_RequiresTouchID verifies per-operation Touch ID gating.
+func TestSecuritySettings_RequiresTouchID(t *testing.T) {
+	s := SecuritySettings{TouchID: []string{TouchIDLoadPrivateKey}}
+	if !s.RequiresTouchID(TouchIDLoadPrivateKey) {
+		t.Error("RequiresTouchID(load_private_key) = false, want true")
+	}
+	if s.RequiresTouchID(TouchIDRevealPassword) {
+		t.Error("RequiresTouchID(reveal_password) = true, want false")
+	}
+}
+
+// TestConfig_KeyProtectorName verifies that gating load_private_key behind
+// Touch ID stores the key with the Touch ID protector.
+func TestConfig_KeyProtectorName(t *testing.T) {
+	touchID := SecuritySettings{TouchID: []string{TouchIDLoadPrivateKey}}
+	tests := []struct {
+		cfg     Config
+		want    string
+		wantErr bool
+	}{
+		{Config{}, "", false},
+		{Config{Crypto: CryptoSettings{KeyProtector: "yubikey"}}, "yubikey", false},
+		{Config{Security: touchID}, "touchid", false},
+		{Config{Security: touchID, Crypto: CryptoSettings{KeyProtector: "software"}}, "touchid", false},
+		{Config{Security: touchID, Crypto: CryptoSettings{KeyProtector: "yubikey"}}, "", true},
+	}
+	for _, tt := range tests {
+		got, err := tt.cfg.KeyProtectorName()
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("KeyProtectorName(%+v) = %q, %v; want %q, error %v", tt.cfg, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+// TestEnsureDir verifies that EnsureDir creates ~/.sunday with restricted permissions.
+func TestEnsureDir(t *testing.T) {
+	tmpDir, cleanup := withTempHome(t)
+	defer cleanup()
+
+	dir, err := EnsureDir()
+	if err != nil {
+		t.Fatalf("EnsureDir() error = %v", err)
+	}
+	if dir != filepath.Join(tmpDir, ".sunday") {
+		t.Errorf("EnsureDir() = %v, want %v", dir, filepath.Join(tmpDir, ".sunday"))
+	}
+	if filepath.Dir(Path()) != dir {
+		t.Errorf("Path() = %v, want inside %v", Path(), dir)
+	}
+
+	info, err := os.Stat(dir)
+	if err != nil {
+		t.Fatalf("Stat() error = %v", err)
+	}
+	if runtime.GOOS != "windows" && info.Mode().Perm() != configDirPerm {
+		t.Errorf("dir permissions = %o, want %o", info.Mode().Perm(), configDirPerm)
+	}
+}
+
+// TestSetLocation verifies directory and file overrides, and that the flag
+// takes precedence over SUNDAY_CONFIG.
+func TestSetLocation(t *testing.T) {
+	defer SetLocation("")
+	base := t.TempDir()
+
+	t.Setenv(EnvConfig, filepath.Join(base, "from-env"))
+	if got := Dir(); got != filepath.Join(base, "from-env") {
+		t.Errorf("Dir() with SUNDAY_CONFIG = %q", got)
+	}
+
+	SetLocation(filepath.Join(base, "work"))
+	if got := Dir(); got != filepath.Join(base, "work") {
+		t.Errorf("Dir() = %q, want %q", got, filepath.Join(base, "work"))
+	}
+	if got := Path(); got != filepath.Join(base, "work", configFileName) {
+		t.Errorf("Path() = %q", got)
+	}
+
+	file := filepath.Join(base, "personal", "sunday.json")
+	SetLocation(file)
+	if got := Path(); got != file {
+		t.Errorf("Path() = %q, want %q", got, file)
+	}
+	if got := Dir(); got != filepath.Dir(file) {
+		t.Errorf("Dir() = %q, want %q", got, filepath.Dir(file))
+	}
+
+	if err := Save(&Config{AccessToken: "isolated"}); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	loaded, err := Load()
+	if err != nil || loaded.AccessToken != "isolated" {
+		t.Errorf("Load() = %+v, %v; want the config saved at %s", loaded, err, file)
+	}
+}
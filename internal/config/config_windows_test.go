@@ -0,0 +1,30 @@
+//go:build windows
+
+package config
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+// TestDir_WindowsUserProfile verifies that the config lives under USERPROFILE.
+func TestDir_WindowsUserProfile(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("USERPROFILE", tmpDir)
+
+	if got, want := Dir(), filepath.Join(tmpDir, configDirName); got != want {
+		t.Errorf("Dir() = %v, want %v", got, want)
+	}
+}
+
+// TestDir_WindowsAppDataFallback verifies the APPDATA fallback when
+// USERPROFILE is unset.
+func TestDir_WindowsAppDataFallback(t *testing.T) {
+	appData := t.TempDir()
+	t.Setenv("USERPROFILE", "")
+	t.Setenv("APPDATA", appData)
+
+	if got, want := Dir(), filepath.Join(appData, configDirName); got != want {
+		t.Errorf("Dir() = %v, want %v", got, want)
+	}
+}
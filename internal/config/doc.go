@@ -6,6 +6,10 @@
 // The package provides functions to:
 //   - Load: Read existing configuration from disk
 //   - Save: Write configuration to disk with proper permissions
+//   - Update: Change part of the configuration, locked against other processes
 //   - Clear: Remove stored credentials (logout)
 //   - ConfigPath: Get the path to the configuration file
+//
+// A private key past crypto.unlock_ttl is wiped by whichever of Load and
+// Update next reads it; see CryptoSettings.UnlockTTL.
 package config
@@ -0,0 +1,40 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"sync"
+)
+
+// lockFileSuffix is added to the config file's name to get the name of
+// the file locked while it is written.
+const lockFileSuffix = ".lock"
+
+// writeMu serializes config writes within the process, such as a token
+// refresh in the background while a command saves; the lock file
+// serializes them between processes.
+var writeMu sync.Mutex
+
+// withLock runs fn holding an exclusive lock on the config file, waiting
+// for any other process to release it first. A read of the config in fn,
+// and a write based on it, then can't lose a change another process saved
+// in between.
+func withLock(fn func() error) error {
+	writeMu.Lock()
+	defer writeMu.Unlock()
+
+	if _, err := EnsureDir(); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(Path()+lockFileSuffix, os.O_RDWR|os.O_CREATE, configFilePerm)
+	if err != nil {
+		return fmt.Errorf("locking config file: %w", err)
+	}
+	defer f.Close()
+	if err := lockFile(f); err != nil {
+		return fmt.Errorf("locking config file: %w", err)
+	}
+	defer unlockFile(f)
+
+	return fn()
+}
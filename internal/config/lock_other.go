@@ -0,0 +1,12 @@
+//go:build !darwin && !windows && !linux && !freebsd && !openbsd && !netbsd && !dragonfly
+
+package config
+
+import "os"
+
+// lockFile does nothing where there is no file locking to use: config
+// writes are then only serialized within the process.
+func lockFile(*os.File) error { return nil }
+
+// unlockFile does nothing, as lockFile doesn't lock.
+func unlockFile(*os.File) error { return nil }
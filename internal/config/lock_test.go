@@ -0,0 +1,53 @@
+//go:build darwin || linux || freebsd || openbsd || netbsd || dragonfly || windows
+
+package config
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+// TestUpdate_WaitsForLock verifies that Update waits while another holder
+// of the config file's lock, as another process would be, has it.
+func TestUpdate_WaitsForLock(t *testing.T) {
+	_, cleanup := withTempHome(t)
+	defer cleanup()
+
+	if _, err := EnsureDir(); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.OpenFile(Path()+lockFileSuffix, os.O_RDWR|os.O_CREATE, configFilePerm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if err := lockFile(f); err != nil {
+		t.Fatalf("lockFile() error = %v", err)
+	}
+
+	done := make(chan error)
+	go func() {
+		done <- Update(func(cfg *Config) error {
+			cfg.AccessToken = "token"
+			return nil
+		})
+	}()
+	select {
+	case err := <-done:
+		t.Fatalf("Update() = %v while the lock was held, want it to wait", err)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	if err := unlockFile(f); err != nil {
+		t.Fatalf("unlockFile() error = %v", err)
+	}
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Update() error = %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Update() still waiting after the lock was released")
+	}
+}
@@ -0,0 +1,161 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// DefaultProfile is the profile whose config lives directly in BaseDir,
+// as it did before profiles existed.
+const DefaultProfile = "default"
+
+// EnvProfile selects the active profile, like the --profile flag.
+const EnvProfile = "SUNDAY_PROFILE"
+
+const (
+	profilesDirName   = "profiles"
+	activeProfileFile = "active_profile"
+)
+
+// profileNamePattern restricts names to ones that are safe as directory
+// names on every platform.
+var profileNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]{0,63}$`)
+
+// profile is the profile set with SetProfile. Empty means use
+// SUNDAY_PROFILE or the saved active profile.
+var profile string
+
+// SetProfile selects the profile for the rest of the process. An empty
+// name restores the default selection.
+func SetProfile(name string) {
+	profile = name
+}
+
+// ValidateProfileName reports whether name can be used as a profile name.
+func ValidateProfileName(name string) error {
+	if !profileNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid profile name %q: use letters, digits, '-' and '_'", name)
+	}
+	return nil
+}
+
+// ActiveProfile returns the profile in use: the one set with SetProfile,
+// else SUNDAY_PROFILE, else the one saved with SwitchProfile, else the
+// default profile. The name is returned as found; callers check it with
+// ValidateProfileName before using its directory.
+func ActiveProfile() string {
+	if profile != "" {
+		return profile
+	}
+	if env := os.Getenv(EnvProfile); env != "" {
+		return env
+	}
+	if data, err := os.ReadFile(filepath.Join(BaseDir(), activeProfileFile)); err == nil {
+		if name := strings.TrimSpace(string(data)); name != "" {
+			return name
+		}
+	}
+	return DefaultProfile
+}
+
+// ProfileDir returns the data directory of the named profile.
+func ProfileDir(name string) string {
+	if name == DefaultProfile {
+		return BaseDir()
+	}
+	return filepath.Join(BaseDir(), profilesDirName, name)
+}
+
+// ProfileExists reports whether the named profile has been created. The
+// default profile always exists; an invalid name never does.
+func ProfileExists(name string) bool {
+	if name == DefaultProfile {
+		return true
+	}
+	if ValidateProfileName(name) != nil {
+		return false
+	}
+	info, err := os.Stat(ProfileDir(name))
+	return err == nil && info.IsDir()
+}
+
+// CreateProfile creates the named profile's directory.
+func CreateProfile(name string) error {
+	if err := ValidateProfileName(name); err != nil {
+		return err
+	}
+	if ProfileExists(name) {
+		return fmt.Errorf("profile %q already exists", name)
+	}
+	if err := os.MkdirAll(ProfileDir(name), configDirPerm); err != nil {
+		return fmt.Errorf("creating profile: %w", err)
+	}
+	return nil
+}
+
+// SwitchProfile makes name the active profile for future invocations.
+func SwitchProfile(name string) error {
+	if err := ValidateProfileName(name); err != nil {
+		return err
+	}
+	if !ProfileExists(name) {
+		return fmt.Errorf("profile %q does not exist", name)
+	}
+	if err := os.MkdirAll(BaseDir(), configDirPerm); err != nil {
+		return fmt.Errorf("creating config directory: %w", err)
+	}
+	path := filepath.Join(BaseDir(), activeProfileFile)
+	if name == DefaultProfile {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("switching profile: %w", err)
+		}
+		return nil
+	}
+	if err := os.WriteFile(path, []byte(name+"\n"), configFilePerm); err != nil {
+		return fmt.Errorf("switching profile: %w", err)
+	}
+	return nil
+}
+
+// ProfileInfo describes a profile for listing.
+type ProfileInfo struct {
+	Name      string `json:"name"`
+	Active    bool   `json:"active"`
+	UserEmail string `json:"user_email,omitempty"`
+}
+
+// ListProfiles returns the default profile followed by every created
+// profile, sorted by name.
+func ListProfiles() ([]ProfileInfo, error) {
+	names := []string{DefaultProfile}
+	entries, err := os.ReadDir(filepath.Join(BaseDir(), profilesDirName))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("listing profiles: %w", err)
+	}
+	var created []string
+	for _, e := range entries {
+		if e.IsDir() && ValidateProfileName(e.Name()) == nil && e.Name() != DefaultProfile {
+			created = append(created, e.Name())
+		}
+	}
+	slices.Sort(created)
+	names = append(names, created...)
+
+	active := ActiveProfile()
+	profiles := make([]ProfileInfo, len(names))
+	for i, name := range names {
+		profiles[i] = ProfileInfo{Name: name, Active: name == active}
+		// Only the account email is needed, so read the file directly
+		// rather than Load, which would also fetch tokens from the keyring.
+		var cfg Config
+		if data, err := os.ReadFile(filepath.Join(ProfileDir(name), configFileName)); err == nil && json.Unmarshal(data, &cfg) == nil {
+			profiles[i].UserEmail = cfg.UserEmail
+		}
+	}
+	return profiles, nil
+}
@@ -0,0 +1,166 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestActiveProfile verifies the precedence of SetProfile, SUNDAY_PROFILE
+// and the profile saved with SwitchProfile.
+func TestActiveProfile(t *testing.T) {
+	_, cleanup := withTempHome(t)
+	defer cleanup()
+	defer SetProfile("")
+
+	if got := ActiveProfile(); got != DefaultProfile {
+		t.Errorf("ActiveProfile() = %q, want %q", got, DefaultProfile)
+	}
+
+	for _, name := range []string{"work", "personal", "ci"} {
+		if err := CreateProfile(name); err != nil {
+			t.Fatalf("CreateProfile(%q) error = %v", name, err)
+		}
+	}
+	if err := SwitchProfile("work"); err != nil {
+		t.Fatalf("SwitchProfile() error = %v", err)
+	}
+	if got := ActiveProfile(); got != "work" {
+		t.Errorf("ActiveProfile() after switch = %q, want work", got)
+	}
+
+	t.Setenv(EnvProfile, "personal")
+	if got := ActiveProfile(); got != "personal" {
+		t.Errorf("ActiveProfile() with %s = %q, want personal", EnvProfile, got)
+	}
+
+	SetProfile("ci")
+	if got := ActiveProfile(); got != "ci" {
+		t.Errorf("ActiveProfile() with SetProfile = %q, want ci", got)
+	}
+}
+
+// TestProfileDir verifies that non-default profiles keep their config in
+// their own directory while logs stay in the base directory.
+func TestProfileDir(t *testing.T) {
+	tmpDir, cleanup := withTempHome(t)
+	defer cleanup()
+	defer SetProfile("")
+
+	base := filepath.Join(tmpDir, ".sunday")
+	SetProfile("work")
+	if got, want := Dir(), filepath.Join(base, "profiles", "work"); got != want {
+		t.Errorf("Dir() = %q, want %q", got, want)
+	}
+	if got, want := Path(), filepath.Join(base, "profiles", "work", "config.json"); got != want {
+		t.Errorf("Path() = %q, want %q", got, want)
+	}
+	if got := BaseDir(); got != base {
+		t.Errorf("BaseDir() = %q, want %q", got, base)
+	}
+
+	SetProfile(DefaultProfile)
+	if got := Dir(); got != base {
+		t.Errorf("Dir() for default profile = %q, want %q", got, base)
+	}
+}
+
+// TestProfilesKeepSeparateCredentials verifies that saving in one profile
+// doesn't affect another.
+func TestProfilesKeepSeparateCredentials(t *testing.T) {
+	_, cleanup := withTempHome(t)
+	defer cleanup()
+	defer SetProfile("")
+
+	if err := Save(&Config{AccessToken: "default-token", UserEmail: "me@example.com"}); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if err := CreateProfile("work"); err != nil {
+		t.Fatalf("CreateProfile() error = %v", err)
+	}
+	SetProfile("work")
+	if err := Save(&Config{AccessToken: "work-token", UserEmail: "me@work.example"}); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.AccessToken != "work-token" {
+		t.Errorf("work AccessToken = %q, want work-token", cfg.AccessToken)
+	}
+
+	SetProfile(DefaultProfile)
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.AccessToken != "default-token" {
+		t.Errorf("default AccessToken = %q, want default-token", cfg.AccessToken)
+	}
+
+	SetProfile("")
+	profiles, err := ListProfiles()
+	if err != nil {
+		t.Fatalf("ListProfiles() error = %v", err)
+	}
+	want := []ProfileInfo{
+		{Name: DefaultProfile, Active: true, UserEmail: "me@example.com"},
+		{Name: "work", UserEmail: "me@work.example"},
+	}
+	if len(profiles) != len(want) {
+		t.Fatalf("ListProfiles() = %+v, want %+v", profiles, want)
+	}
+	for i := range want {
+		if profiles[i] != want[i] {
+			t.Errorf("ListProfiles()[%d] = %+v, want %+v", i, profiles[i], want[i])
+		}
+	}
+}
+
+// TestCreateProfile_Invalid verifies that unsafe or duplicate names are
+// rejected.
+func TestCreateProfile_Invalid(t *testing.T) {
+	_, cleanup := withTempHome(t)
+	defer cleanup()
+
+	for _, name := range []string{"", "../evil", "a/b", ".hidden", DefaultProfile} {
+		if err := CreateProfile(name); err == nil {
+			t.Errorf("CreateProfile(%q) succeeded, want error", name)
+		}
+	}
+	if err := SwitchProfile("missing"); err == nil {
+		t.Error("SwitchProfile(missing) succeeded, want error")
+	}
+	// ../.. is a directory that exists, but not a profile.
+	if ProfileExists("../..") {
+		t.Error("ProfileExists(../..) = true, want false")
+	}
+	if err := SwitchProfile("../.."); err == nil {
+		t.Error("SwitchProfile(../..) succeeded, want error")
+	}
+}
+
+// TestSwitchProfile_Default verifies that switching back to the default
+// profile removes the saved selection.
+func TestSwitchProfile_Default(t *testing.T) {
+	tmpDir, cleanup := withTempHome(t)
+	defer cleanup()
+
+	if err := CreateProfile("work"); err != nil {
+		t.Fatalf("CreateProfile() error = %v", err)
+	}
+	if err := SwitchProfile("work"); err != nil {
+		t.Fatalf("SwitchProfile(work) error = %v", err)
+	}
+	if err := SwitchProfile(DefaultProfile); err != nil {
+		t.Fatalf("SwitchProfile(default) error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, ".sunday", activeProfileFile)); !os.IsNotExist(err) {
+		t.Errorf("active profile file still exists: %v", err)
+	}
+	if got := ActiveProfile(); got != DefaultProfile {
+		t.Errorf("ActiveProfile() = %q, want %q", got, DefaultProfile)
+	}
+}
@@ -0,0 +1,208 @@
+package config
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/user"
+	"regexp"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// sealedPrefix marks a config value encrypted with the machine key.
+const sealedPrefix = "enc:v1:"
+
+// ErrSealedElsewhere is returned when secrets in the config file can't be
+// decrypted with this machine's key: the file was copied from another
+// machine or user account, or this machine's ID changed.
+var ErrSealedElsewhere = errors.New("config secrets were encrypted on another machine or user account")
+
+// machineKey returns the key that seals secrets in the config file. Tests
+// replace it.
+var machineKey = sync.OnceValues(deriveMachineKey)
+
+// legacyMachineKey returns the key earlier versions sealed secrets with,
+// to read files they wrote. Tests replace it.
+var legacyMachineKey = sync.OnceValues(deriveLegacyMachineKey)
+
+// deriveMachineKey derives a key from the OS machine ID and the user, so a
+// config file copied to another machine or account can't be decrypted.
+// The host name is left out, as it changes too easily, e.g. when macOS
+// renames the machine on another network. The key is obfuscation against
+// casual copying rather than a secret: anyone running as the user on this
+// machine can derive it too, which is why the OS keyring is preferred
+// when available.
+func deriveMachineKey() ([]byte, error) {
+	material := strings.Join([]string{machineID(), currentUser()}, "\x00")
+	return hkdf.Key(sha256.New, []byte(material), []byte("sunday-cli config"), "config secrets v2", 32)
+}
+
+// deriveLegacyMachineKey derives the key earlier versions used, which
+// also depended on the host name.
+func deriveLegacyMachineKey() ([]byte, error) {
+	host, _ := os.Hostname()
+	material := strings.Join([]string{host, machineID(), currentUser()}, "\x00")
+	return hkdf.Key(sha256.New, []byte(material), []byte("sunday-cli config"), "config secrets v1", 32)
+}
+
+// currentUser identifies the user the key is bound to, or is "" if the
+// user can't be looked up.
+func currentUser() string {
+	u, err := user.Current()
+	if err != nil {
+		return ""
+	}
+	return u.Uid + ":" + u.Username
+}
+
+// ioregUUID extracts the hardware UUID from `ioreg` output on macOS.
+var ioregUUID = regexp.MustCompile(`"IOPlatformUUID" = "([^"]+)"`)
+
+// machineID returns a stable identifier of this OS installation, or ""
+// if none can be read.
+func machineID() string {
+	switch runtime.GOOS {
+	case "linux", "freebsd", "openbsd", "netbsd":
+		for _, path := range []string{"/etc/machine-id", "/var/lib/dbus/machine-id"} {
+			if data, err := os.ReadFile(path); err == nil {
+				return strings.TrimSpace(string(data))
+			}
+		}
+	case "darwin":
+		out, err := exec.Command("/usr/sbin/ioreg", "-rd1", "-c", "IOPlatformExpertDevice").Output()
+		if err == nil {
+			if m := ioregUUID.FindSubmatch(out); m != nil {
+				return string(m[1])
+			}
+		}
+	case "windows":
+		out, err := exec.Command("reg", "query", `HKLM\SOFTWARE\Microsoft\Cryptography`, "/v", "MachineGuid").Output()
+		if err == nil {
+			if fields := strings.Fields(string(out)); len(fields) > 0 {
+				return fields[len(fields)-1]
+			}
+		}
+	}
+	return ""
+}
+
+// secretFields returns pointers to the fields of cfg that are sealed on
+// disk, keyed by name, including those of every named account.
+func secretFields(cfg *Config) map[string]*string {
+	fields := map[string]*string{
+		"access_token":   &cfg.AccessToken,
+		"refresh_token":  &cfg.RefreshToken,
+		"private_key":    &cfg.PrivateKey,
+		"signing_secret": &cfg.SigningSecret,
+	}
+	for name, a := range cfg.Accounts {
+		if a == nil {
+			continue
+		}
+		prefix := "accounts." + name + "."
+		fields[prefix+"access_token"] = &a.AccessToken
+		fields[prefix+"refresh_token"] = &a.RefreshToken
+		fields[prefix+"private_key"] = &a.PrivateKey
+		fields[prefix+"signing_secret"] = &a.SigningSecret
+	}
+	return fields
+}
+
+// sealSecrets encrypts cfg's secrets in place for writing to disk. The
+// field name is bound into each value so values can't be swapped.
+func sealSecrets(cfg *Config) error {
+	fields := secretFields(cfg)
+	if len(fields) == 0 {
+		return nil
+	}
+	aead, err := machineAEAD(machineKey)
+	if err != nil {
+		return err
+	}
+	for name, value := range fields {
+		if *value == "" || strings.HasPrefix(*value, sealedPrefix) {
+			continue
+		}
+		nonce := make([]byte, aead.NonceSize())
+		if _, err := rand.Read(nonce); err != nil {
+			return fmt.Errorf("encrypting config: %w", err)
+		}
+		sealed := aead.Seal(nonce, nonce, []byte(*value), []byte(name))
+		*value = sealedPrefix + base64.StdEncoding.EncodeToString(sealed)
+	}
+	return nil
+}
+
+// unsealSecrets decrypts cfg's secrets in place after reading it from
+// disk. It reports whether any need rewriting: stored in plaintext, by a
+// version before encryption, or sealed with the legacy key. A value that
+// can't be decrypted at all is an error wrapping ErrSealedElsewhere, rather
+// than being dropped, so that a changed machine doesn't silently log the
+// user out and lose their key.
+func unsealSecrets(cfg *Config) (stale bool, err error) {
+	var aead, legacy cipher.AEAD
+	for name, value := range secretFields(cfg) {
+		if *value == "" {
+			continue
+		}
+		if !strings.HasPrefix(*value, sealedPrefix) {
+			stale = true
+			continue
+		}
+		if aead == nil {
+			if aead, err = machineAEAD(machineKey); err != nil {
+				return false, err
+			}
+		}
+		opened, err := openSealed(aead, name, *value)
+		if err != nil {
+			if legacy == nil {
+				if legacy, err = machineAEAD(legacyMachineKey); err != nil {
+					return false, err
+				}
+			}
+			if opened, err = openSealed(legacy, name, *value); err != nil {
+				return false, fmt.Errorf("%w: can't decrypt %s in %s; run `sunday auth logout` and log in again", ErrSealedElsewhere, name, Path())
+			}
+			stale = true
+		}
+		*value = opened
+	}
+	return stale, nil
+}
+
+func openSealed(aead cipher.AEAD, name, value string) (string, error) {
+	data, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(value, sealedPrefix))
+	if err != nil {
+		return "", err
+	}
+	if len(data) < aead.NonceSize() {
+		return "", errors.New("sealed value too short")
+	}
+	opened, err := aead.Open(nil, data[:aead.NonceSize()], data[aead.NonceSize():], []byte(name))
+	if err != nil {
+		return "", err
+	}
+	return string(opened), nil
+}
+
+func machineAEAD(machineKey func() ([]byte, error)) (cipher.AEAD, error) {
+	key, err := machineKey()
+	if err != nil {
+		return nil, fmt.Errorf("deriving config key: %w", err)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("deriving config key: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
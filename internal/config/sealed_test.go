@@ -0,0 +1,177 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// withMachineKey replaces the machine key for the duration of a test.
+func withMachineKey(t *testing.T, key byte) {
+	t.Helper()
+	orig := machineKey
+	machineKey = fixedKey(key)
+	t.Cleanup(func() { machineKey = orig })
+}
+
+// withLegacyMachineKey replaces the legacy machine key for the duration of
+// a test.
+func withLegacyMachineKey(t *testing.T, key byte) {
+	t.Helper()
+	orig := legacyMachineKey
+	legacyMachineKey = fixedKey(key)
+	t.Cleanup(func() { legacyMachineKey = orig })
+}
+
+// fixedKey returns a key function that always returns a key of b bytes.
+func fixedKey(b byte) func() ([]byte, error) {
+	return func() ([]byte, error) {
+		k := make([]byte, 32)
+		for i := range k {
+			k[i] = b
+		}
+		return k, nil
+	}
+}
+
+// TestSave_EncryptsSecrets verifies that tokens and the private key never
+// reach the file in plaintext, and are decrypted again on Load.
+func TestSave_EncryptsSecrets(t *testing.T) {
+	_, cleanup := withTempHome(t)
+	defer cleanup()
+	withMachineKey(t, 1)
+
+	cfg := &Config{AccessToken: "access-secret", RefreshToken: "refresh-secret", PrivateKey: "private-secret", UserEmail: "me@example.com"}
+	if err := Save(cfg); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if cfg.AccessToken != "access-secret" {
+		t.Error("Save() modified the caller's config")
+	}
+
+	data, err := os.ReadFile(Path())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "secret") {
+		t.Errorf("config file holds a plaintext secret: %s", data)
+	}
+	if !strings.Contains(string(data), "me@example.com") {
+		t.Errorf("config file = %s, want the email left readable", data)
+	}
+
+	loaded, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if loaded.AccessToken != "access-secret" || loaded.RefreshToken != "refresh-secret" || loaded.PrivateKey != "private-secret" {
+		t.Errorf("Load() = %+v, want the secrets decrypted", loaded)
+	}
+}
+
+// TestLoad_MigratesPlaintext verifies that a config written before
+// encryption is read as is and rewritten with its secrets encrypted.
+func TestLoad_MigratesPlaintext(t *testing.T) {
+	tmpDir, cleanup := withTempHome(t)
+	defer cleanup()
+	withMachineKey(t, 1)
+
+	path := filepath.Join(tmpDir, ".sunday", "config.json")
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		t.Fatal(err)
+	}
+	plain, _ := json.Marshal(map[string]string{"access_token": "old-access", "refresh_token": "old-refresh", "private_key": "old-key"})
+	if err := os.WriteFile(path, plain, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.AccessToken != "old-access" || cfg.PrivateKey != "old-key" {
+		t.Errorf("Load() = %+v, want the plaintext values", cfg)
+	}
+
+	data, _ := os.ReadFile(path)
+	if strings.Contains(string(data), "old-") {
+		t.Errorf("config file not migrated: %s", data)
+	}
+	if cfg, err := Load(); err != nil || cfg.RefreshToken != "old-refresh" {
+		t.Errorf("Load() after migration = %+v, %v", cfg, err)
+	}
+}
+
+// TestLoad_LegacyKey verifies that secrets sealed with the legacy key,
+// which depended on the host name, are read and sealed again with the
+// machine key.
+func TestLoad_LegacyKey(t *testing.T) {
+	_, cleanup := withTempHome(t)
+	defer cleanup()
+
+	withMachineKey(t, 1)
+	if err := Save(&Config{AccessToken: "access", PrivateKey: "key"}); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	// What was the machine key is now the legacy one.
+	withLegacyMachineKey(t, 1)
+	withMachineKey(t, 2)
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.AccessToken != "access" || cfg.PrivateKey != "key" {
+		t.Errorf("Load() = %+v, want the secrets decrypted", cfg)
+	}
+
+	withLegacyMachineKey(t, 3)
+	if cfg, err := Load(); err != nil || cfg.PrivateKey != "key" {
+		t.Errorf("Load() after resealing = %+v, %v, want the secrets sealed with the machine key", cfg, err)
+	}
+}
+
+// TestLoad_OtherMachine verifies that secrets sealed with another machine's
+// key are an error that says so, rather than a session that silently reads
+// as logged out.
+func TestLoad_OtherMachine(t *testing.T) {
+	_, cleanup := withTempHome(t)
+	defer cleanup()
+
+	withMachineKey(t, 1)
+	if err := Save(&Config{AccessToken: "access", RefreshToken: "refresh", UserEmail: "me@example.com"}); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	withMachineKey(t, 2)
+	withLegacyMachineKey(t, 3)
+	if _, err := Load(); !errors.Is(err, ErrSealedElsewhere) {
+		t.Fatalf("Load() error = %v, want ErrSealedElsewhere", err)
+	}
+
+	// Logging out still works, to start again.
+	if err := Clear(); err != nil {
+		t.Fatalf("Clear() error = %v", err)
+	}
+	if _, err := Load(); err != nil {
+		t.Errorf("Load() after Clear() error = %v", err)
+	}
+}
+
+// TestSealSecrets_BindsFieldName verifies that a sealed value can't be
+// moved to another field.
+func TestSealSecrets_BindsFieldName(t *testing.T) {
+	withMachineKey(t, 1)
+
+	cfg := &Config{AccessToken: "access"}
+	if err := sealSecrets(cfg); err != nil {
+		t.Fatalf("sealSecrets() error = %v", err)
+	}
+	swapped := &Config{RefreshToken: cfg.AccessToken}
+	if _, err := unsealSecrets(swapped); err == nil {
+		t.Errorf("unsealSecrets() of a moved value = %q, want an error", swapped.RefreshToken)
+	}
+}
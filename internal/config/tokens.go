@@ -0,0 +1,154 @@
+package config
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ravi-technologies/sunday-cli/internal/keyring"
+)
+
+// Backends for StorageSettings.Tokens.
+const (
+	TokenStoreAuto    = "auto"
+	TokenStoreKeyring = "keyring"
+	TokenStoreFile    = "file"
+)
+
+// EnvTokenStore overrides StorageSettings.Tokens, e.g. to force the file
+// backend on a headless machine.
+const EnvTokenStore = "SUNDAY_TOKEN_STORE"
+
+// keyringService is the service name tokens are stored under. The account
+// is the config file path, so each config location has its own entry.
+const keyringService = "sunday-cli"
+
+// secretStore holds tokens when the keyring backend is in use. Tests
+// replace it.
+var secretStore keyring.Store = keyring.System
+
+// StorageSettings controls where credentials are kept.
+type StorageSettings struct {
+	// Tokens selects where the access token, refresh token and signing
+	// secret are stored: "auto" (the default) uses the OS keyring when
+	// one is available and the config file otherwise; "keyring" requires
+	// the keyring; "file" always uses the config file.
+	Tokens string `json:"tokens,omitempty"`
+}
+
+// keyringSecrets is the payload stored in the keyring.
+type keyringSecrets struct {
+	AccessToken   string `json:"access_token,omitempty"`
+	RefreshToken  string `json:"refresh_token,omitempty"`
+	SigningSecret string `json:"signing_secret,omitempty"`
+}
+
+// tokenStore returns the token backend in effect for cfg.
+func tokenStore(cfg *Config) string {
+	if env := os.Getenv(EnvTokenStore); env != "" {
+		return env
+	}
+	if cfg.Storage.Tokens != "" {
+		return cfg.Storage.Tokens
+	}
+	return TokenStoreAuto
+}
+
+// keyringAccount returns the keyring account for the current config
+// location and, if one is selected, the named account within it.
+func keyringAccount() string {
+	path := Path()
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	if name := ActiveAccount(); name != "" {
+		return path + "#" + name
+	}
+	return path
+}
+
+// storeSecrets moves cfg's tokens into the keyring if that backend is in
+// use, returning the config to write to disk. With the "auto" backend, a
+// keyring failure leaves the tokens in the file.
+func storeSecrets(cfg *Config) (*Config, error) {
+	store := tokenStore(cfg)
+	switch store {
+	case TokenStoreAuto, TokenStoreKeyring:
+	case TokenStoreFile:
+		// Don't leave stale tokens behind in the keyring.
+		_ = secretStore.Delete(keyringService, keyringAccount())
+		onDisk := *cfg
+		onDisk.KeyringTokens = false
+		return &onDisk, nil
+	default:
+		return nil, fmt.Errorf("invalid token store %q: must be auto, keyring, or file", store)
+	}
+
+	secrets := keyringSecrets{
+		AccessToken:   cfg.AccessToken,
+		RefreshToken:  cfg.RefreshToken,
+		SigningSecret: cfg.SigningSecret,
+	}
+	onDisk := *cfg
+	onDisk.AccessToken, onDisk.RefreshToken, onDisk.SigningSecret = "", "", ""
+	onDisk.KeyringTokens = false
+
+	if secrets == (keyringSecrets{}) {
+		_ = secretStore.Delete(keyringService, keyringAccount())
+		return &onDisk, nil
+	}
+
+	data, err := json.Marshal(secrets)
+	if err != nil {
+		return nil, fmt.Errorf("encoding tokens: %w", err)
+	}
+	if err := secretStore.Set(keyringService, keyringAccount(), base64.StdEncoding.EncodeToString(data)); err != nil {
+		if store == TokenStoreKeyring {
+			return nil, fmt.Errorf("storing tokens in keyring: %w", err)
+		}
+		fileCfg := *cfg
+		fileCfg.KeyringTokens = false
+		return &fileCfg, nil
+	}
+	onDisk.KeyringTokens = true
+	return &onDisk, nil
+}
+
+// loadSecrets fills in cfg's tokens from the keyring if they were stored
+// there.
+func loadSecrets(cfg *Config) error {
+	if !cfg.KeyringTokens {
+		return nil
+	}
+	encoded, err := secretStore.Get(keyringService, keyringAccount())
+	if errors.Is(err, keyring.ErrNotFound) {
+		// The entry was removed outside the CLI; treat it as logged out.
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("reading tokens from keyring: %w", err)
+	}
+
+	data, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return fmt.Errorf("decoding tokens from keyring: %w", err)
+	}
+	var secrets keyringSecrets
+	if err := json.Unmarshal(data, &secrets); err != nil {
+		return fmt.Errorf("decoding tokens from keyring: %w", err)
+	}
+	cfg.AccessToken = secrets.AccessToken
+	cfg.RefreshToken = secrets.RefreshToken
+	cfg.SigningSecret = secrets.SigningSecret
+	return nil
+}
+
+// deleteSecrets removes any tokens stored in the keyring for the current
+// config location.
+func deleteSecrets() {
+	_ = secretStore.Delete(keyringService, keyringAccount())
+}
@@ -0,0 +1,178 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/ravi-technologies/sunday-cli/internal/keyring"
+)
+
+// memoryStore is an in-memory keyring.Store. If err is set, every call
+// fails with it.
+type memoryStore struct {
+	secrets map[string]string
+	err     error
+}
+
+func (m *memoryStore) Get(service, account string) (string, error) {
+	if m.err != nil {
+		return "", m.err
+	}
+	s, ok := m.secrets[service+"/"+account]
+	if !ok {
+		return "", keyring.ErrNotFound
+	}
+	return s, nil
+}
+
+func (m *memoryStore) Set(service, account, secret string) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.secrets[service+"/"+account] = secret
+	return nil
+}
+
+func (m *memoryStore) Delete(service, account string) error {
+	if m.err != nil {
+		return m.err
+	}
+	if _, ok := m.secrets[service+"/"+account]; !ok {
+		return keyring.ErrNotFound
+	}
+	delete(m.secrets, service+"/"+account)
+	return nil
+}
+
+// withKeyring points the token store at an in-memory keyring and the
+// config at a temp directory.
+func withKeyring(t *testing.T, store string) *memoryStore {
+	t.Helper()
+	SetLocation(t.TempDir())
+	t.Cleanup(func() { SetLocation("") })
+	t.Setenv(EnvTokenStore, store)
+
+	mem := &memoryStore{secrets: map[string]string{}}
+	orig := secretStore
+	secretStore = mem
+	t.Cleanup(func() { secretStore = orig })
+	return mem
+}
+
+// TestSave_Keyring verifies that tokens are kept out of the config file
+// when the keyring is used, and restored on Load.
+func TestSave_Keyring(t *testing.T) {
+	mem := withKeyring(t, TokenStoreAuto)
+
+	cfg := &Config{AccessToken: "access-secret", RefreshToken: "refresh-secret", SigningSecret: "signing-secret", UserEmail: "a@example.com"}
+	if err := Save(cfg); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if len(mem.secrets) != 1 {
+		t.Fatalf("keyring has %d entries, want 1", len(mem.secrets))
+	}
+
+	data, err := os.ReadFile(Path())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "secret") {
+		t.Errorf("config file contains tokens: %s", data)
+	}
+	if cfg.AccessToken != "access-secret" {
+		t.Error("Save() modified the caller's config")
+	}
+
+	loaded, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if loaded.AccessToken != "access-secret" || loaded.RefreshToken != "refresh-secret" || loaded.SigningSecret != "signing-secret" {
+		t.Errorf("Load() tokens = %q %q %q", loaded.AccessToken, loaded.RefreshToken, loaded.SigningSecret)
+	}
+	if loaded.UserEmail != "a@example.com" {
+		t.Errorf("Load() UserEmail = %q", loaded.UserEmail)
+	}
+
+	if err := Clear(); err != nil {
+		t.Fatalf("Clear() error = %v", err)
+	}
+	if len(mem.secrets) != 0 {
+		t.Error("Clear() left tokens in the keyring")
+	}
+}
+
+// TestSave_KeyringFallback verifies that "auto" falls back to the file
+// when the keyring is unavailable, and "keyring" fails instead.
+func TestSave_KeyringFallback(t *testing.T) {
+	mem := withKeyring(t, TokenStoreAuto)
+	mem.err = keyring.ErrUnavailable
+
+	if err := Save(&Config{AccessToken: "access-secret"}); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	loaded, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if loaded.AccessToken != "access-secret" || loaded.KeyringTokens {
+		t.Errorf("Load() = %+v, want the token from the file", loaded)
+	}
+
+	t.Setenv(EnvTokenStore, TokenStoreKeyring)
+	if err := Save(&Config{AccessToken: "access-secret"}); !errors.Is(err, keyring.ErrUnavailable) {
+		t.Errorf("Save() with keyring required error = %v, want ErrUnavailable", err)
+	}
+}
+
+// TestSave_FileStore verifies that the file backend keeps tokens in the
+// file and removes any stale keyring entry.
+func TestSave_FileStore(t *testing.T) {
+	mem := withKeyring(t, TokenStoreAuto)
+	if err := Save(&Config{AccessToken: "old"}); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv(EnvTokenStore, TokenStoreFile)
+	if err := Save(&Config{AccessToken: "new"}); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if len(mem.secrets) != 0 {
+		t.Error("file backend left a stale keyring entry")
+	}
+	data, _ := os.ReadFile(Path())
+	if !strings.Contains(string(data), `"access_token": "`+sealedPrefix) {
+		t.Errorf("config file = %s, want the (encrypted) token", data)
+	}
+	if cfg, err := Load(); err != nil || cfg.AccessToken != "new" {
+		t.Errorf("Load() = %v, %v, want the token from the file", cfg, err)
+	}
+}
+
+// TestSave_InvalidTokenStore verifies that unknown backends are rejected.
+func TestSave_InvalidTokenStore(t *testing.T) {
+	withKeyring(t, "vault")
+	if err := Save(&Config{AccessToken: "x"}); err == nil {
+		t.Error("Save() with an unknown token store returned nil error")
+	}
+}
+
+// TestLoad_KeyringEntryMissing verifies that a removed keyring entry reads
+// as logged out rather than an error.
+func TestLoad_KeyringEntryMissing(t *testing.T) {
+	mem := withKeyring(t, TokenStoreAuto)
+	if err := Save(&Config{AccessToken: "x", UserEmail: "a@example.com"}); err != nil {
+		t.Fatal(err)
+	}
+	clear(mem.secrets)
+
+	loaded, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if loaded.AccessToken != "" || loaded.UserEmail != "a@example.com" {
+		t.Errorf("Load() = %+v", loaded)
+	}
+}
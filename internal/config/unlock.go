@@ -0,0 +1,70 @@
+package config
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// OnKeyLocked, if set, is called with the private key as it was stored
+// after the config wiped it for going unused longer than
+// crypto.unlock_ttl, to delete whatever else holds the key, such as a
+// Keychain item, and forget it in memory. The CLI sets it; the stored
+// formats aren't this package's.
+var OnKeyLocked func(stored string)
+
+// UnlockTTLDuration returns UnlockTTL as a duration, or zero if it is
+// unset.
+func (s CryptoSettings) UnlockTTLDuration() (time.Duration, error) {
+	if s.UnlockTTL == "" {
+		return 0, nil
+	}
+	ttl, err := time.ParseDuration(s.UnlockTTL)
+	if err != nil || ttl <= 0 {
+		return 0, fmt.Errorf("invalid crypto.unlock_ttl %q: want a positive duration such as 8h", s.UnlockTTL)
+	}
+	return ttl, nil
+}
+
+// keyExpired reports whether the private key has gone unused for longer
+// than crypto.unlock_ttl at t. KeyUsedAt plus the TTL is a hard expiry:
+// however the config is read after it, the key is gone.
+func (c *Config) keyExpired(t time.Time) bool {
+	if c.PrivateKey == "" || c.KeyUsedAt.IsZero() {
+		return false
+	}
+	ttl, err := c.Crypto.UnlockTTLDuration()
+	return err == nil && ttl > 0 && t.Sub(c.KeyUsedAt) > ttl
+}
+
+// expireKey wipes an expired private key from cfg, returning it as it was
+// stored, or "" if the key hasn't expired.
+func expireKey(cfg *Config) string {
+	if !cfg.keyExpired(time.Now()) {
+		return ""
+	}
+	stored := cfg.PrivateKey
+	cfg.PrivateKey = ""
+	cfg.KeyUsedAt = time.Time{}
+	return stored
+}
+
+// keyLocked tells OnKeyLocked that stored was wiped.
+func keyLocked(stored string) {
+	if stored != "" && OnKeyLocked != nil {
+		OnKeyLocked(stored)
+	}
+}
+
+// lockExpiredKey wipes cfg's private key, and the one in the config file,
+// once they have expired. Failing to save is no reason to fail the
+// command; the key is still not used.
+func lockExpiredKey(cfg *Config) {
+	if expireKey(cfg) == "" {
+		return
+	}
+	// Update expires the key on disk itself.
+	if err := Update(func(*Config) error { return nil }); err != nil {
+		slog.Warn("locking encryption key", "error", err)
+	}
+}
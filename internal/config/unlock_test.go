@@ -0,0 +1,59 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+// TestLoad_ExpiresKey verifies that Load wipes a private key past its
+// unlock TTL, in the file too, and tells OnKeyLocked, while a key still in
+// use or with a malformed TTL is kept.
+func TestLoad_ExpiresKey(t *testing.T) {
+	tests := []struct {
+		name     string
+		ttl      string
+		usedAgo  time.Duration
+		wantWipe bool
+	}{
+		{"expired", "1h", 2 * time.Hour, true},
+		{"in use", "1h", 30 * time.Minute, false},
+		{"no TTL", "", 2 * time.Hour, false},
+		{"malformed TTL", "soon", 2 * time.Hour, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, cleanup := withTempHome(t)
+			defer cleanup()
+			var locked []string
+			OnKeyLocked = func(stored string) { locked = append(locked, stored) }
+			defer func() { OnKeyLocked = nil }()
+
+			err := Save(&Config{
+				PrivateKey: "private",
+				PublicKey:  "public",
+				KeyUsedAt:  time.Now().Add(-tt.usedAgo),
+				Crypto:     CryptoSettings{UnlockTTL: tt.ttl},
+			})
+			if err != nil {
+				t.Fatalf("Save() error = %v", err)
+			}
+
+			cfg, err := Load()
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			onDisk, err := readFile()
+			if err != nil {
+				t.Fatalf("readFile() error = %v", err)
+			}
+			for _, c := range []*Config{cfg, onDisk} {
+				if wiped := c.PrivateKey == ""; wiped != tt.wantWipe || c.PublicKey != "public" {
+					t.Errorf("private key = %q, public key = %q; want wiped = %v", c.PrivateKey, c.PublicKey, tt.wantWipe)
+				}
+			}
+			if tt.wantWipe != (len(locked) == 1 && locked[0] == "private") {
+				t.Errorf("OnKeyLocked calls = %q, want wiped = %v", locked, tt.wantWipe)
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package config
+
+import (
+	"os"
+	"time"
+)
+
+// Watcher detects changes to the config file made by other processes, such
+// as a re-login or a token refresh in another terminal, so long-running
+// commands can pick them up without restarting.
+type Watcher struct {
+	modTime time.Time
+	size    int64
+}
+
+// NewWatcher returns a Watcher whose baseline is the config file as it is
+// on disk now.
+func NewWatcher() *Watcher {
+	w := &Watcher{}
+	w.Mark()
+	return w
+}
+
+// Mark resets the baseline to the file's current state. Call it after
+// writing the config yourself so your own write isn't reported as a change.
+func (w *Watcher) Mark() {
+	if w == nil {
+		return
+	}
+	w.modTime, w.size = statConfig()
+}
+
+// Reload returns the config from disk if the file changed since the last
+// call to Reload or Mark, or nil if it did not. A deleted file (logout)
+// counts as a change and yields an empty config.
+func (w *Watcher) Reload() (*Config, error) {
+	if w == nil {
+		return nil, nil
+	}
+	modTime, size := statConfig()
+	if modTime.Equal(w.modTime) && size == w.size {
+		return nil, nil
+	}
+
+	cfg, err := Load()
+	if err != nil {
+		return nil, err
+	}
+	w.modTime, w.size = modTime, size
+	return cfg, nil
+}
+
+// statConfig returns the config file's modification time and size, or zero
+// values if it doesn't exist.
+func statConfig() (time.Time, int64) {
+	info, err := os.Stat(Path())
+	if err != nil {
+		return time.Time{}, -1
+	}
+	return info.ModTime(), info.Size()
+}
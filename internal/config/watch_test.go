@@ -0,0 +1,72 @@
+package config
+
+import (
+	"os"
+	"slices"
+	"testing"
+)
+
+// TestWatcher_Reload verifies that the watcher reports changes written by
+// another process and ignores writes it was told about via Mark.
+func TestWatcher_Reload(t *testing.T) {
+	_, cleanup := withTempHome(t)
+	defer cleanup()
+
+	if err := Save(&Config{AccessToken: "first"}); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	w := NewWatcher()
+
+	if cfg, err := w.Reload(); err != nil || cfg != nil {
+		t.Fatalf("Reload() on unchanged file = %v, %v; want nil, nil", cfg, err)
+	}
+
+	if err := Save(&Config{AccessToken: "second-token"}); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	cfg, err := w.Reload()
+	if err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	if cfg == nil || cfg.AccessToken != "second-token" {
+		t.Fatalf("Reload() = %+v, want AccessToken second-token", cfg)
+	}
+
+	if err := Save(&Config{AccessToken: "own-write"}); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	w.Mark()
+	if cfg, _ := w.Reload(); cfg != nil {
+		t.Errorf("Reload() after Mark = %+v, want nil", cfg)
+	}
+
+	if err := os.Remove(Path()); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err = w.Reload()
+	if err != nil || cfg == nil || cfg.AccessToken != "" {
+		t.Errorf("Reload() after logout = %+v, %v; want empty config", cfg, err)
+	}
+}
+
+// TestSave_NoTempFilesLeft verifies that the atomic write cleans up after
+// itself.
+func TestSave_NoTempFilesLeft(t *testing.T) {
+	_, cleanup := withTempHome(t)
+	defer cleanup()
+
+	if err := Save(&Config{AccessToken: "token"}); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	entries, err := os.ReadDir(Dir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := []string{}
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	if want := []string{configFileName, configFileName + lockFileSuffix}; !slices.Equal(names, want) {
+		t.Errorf("config dir contains %v, want only %v", names, want)
+	}
+}
@@ -0,0 +1,153 @@
+// Package contacts is a local address book mapping names to the phone
+// numbers and email addresses a person uses. It is stored in
+// ~/.sunday/contacts.json and lets listings treat several numbers or
+// addresses as the same person.
+package contacts
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/ravi-technologies/sunday-cli/internal/config"
+	"github.com/ravi-technologies/sunday-cli/internal/phone"
+)
+
+const fileName = "contacts.json"
+
+// ErrNotFound is returned when no contact has the given name.
+var ErrNotFound = errors.New("contact not found")
+
+// Contact is a person and the numbers and addresses they use.
+type Contact struct {
+	Name   string   `json:"name"`
+	Phones []string `json:"phones,omitempty"`
+	Emails []string `json:"emails,omitempty"`
+}
+
+// Book is the set of stored contacts.
+type Book struct {
+	Contacts []Contact `json:"contacts"`
+}
+
+// Path returns the contacts file path.
+func Path() string {
+	return filepath.Join(config.Dir(), fileName)
+}
+
+// Load reads the contact book. A missing file is an empty book.
+func Load() (*Book, error) {
+	data, err := os.ReadFile(Path())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &Book{}, nil
+		}
+		return nil, fmt.Errorf("reading contacts: %w", err)
+	}
+
+	var b Book
+	if err := json.Unmarshal(data, &b); err != nil {
+		return nil, fmt.Errorf("parsing contacts: %w", err)
+	}
+	return &b, nil
+}
+
+// Save writes the contact book.
+func (b *Book) Save() error {
+	if _, err := config.EnsureDir(); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding contacts: %w", err)
+	}
+	if err := os.WriteFile(Path(), data, 0600); err != nil {
+		return fmt.Errorf("writing contacts: %w", err)
+	}
+	return nil
+}
+
+// Add records addresses (phone numbers or email addresses) for the named
+// contact, creating it if needed. Addresses containing "@" are emails.
+func (b *Book) Add(name string, addresses ...string) {
+	i := slices.IndexFunc(b.Contacts, func(c Contact) bool { return strings.EqualFold(c.Name, name) })
+	if i < 0 {
+		b.Contacts = append(b.Contacts, Contact{Name: name})
+		i = len(b.Contacts) - 1
+	}
+	c := &b.Contacts[i]
+	for _, addr := range addresses {
+		if strings.Contains(addr, "@") {
+			if !slices.ContainsFunc(c.Emails, func(e string) bool { return strings.EqualFold(e, addr) }) {
+				c.Emails = append(c.Emails, addr)
+			}
+		} else if !slices.ContainsFunc(c.Phones, func(p string) bool { return samePhone(p, addr) }) {
+			c.Phones = append(c.Phones, addr)
+		}
+	}
+}
+
+// Remove deletes the named contact.
+func (b *Book) Remove(name string) error {
+	n := len(b.Contacts)
+	b.Contacts = slices.DeleteFunc(b.Contacts, func(c Contact) bool { return strings.EqualFold(c.Name, name) })
+	if len(b.Contacts) == n {
+		return fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	return nil
+}
+
+// LookupPhone returns the contact that uses number.
+func (b *Book) LookupPhone(number string) (Contact, bool) {
+	for _, c := range b.Contacts {
+		if slices.ContainsFunc(c.Phones, func(p string) bool { return samePhone(p, number) }) {
+			return c, true
+		}
+	}
+	return Contact{}, false
+}
+
+// LookupEmail returns the contact that uses address.
+func (b *Book) LookupEmail(address string) (Contact, bool) {
+	for _, c := range b.Contacts {
+		if slices.ContainsFunc(c.Emails, func(e string) bool { return strings.EqualFold(e, address) }) {
+			return c, true
+		}
+	}
+	return Contact{}, false
+}
+
+// Lookup returns the contact that uses address, which may be a phone
+// number or an email address.
+func (b *Book) Lookup(address string) (Contact, bool) {
+	if strings.Contains(address, "@") {
+		return b.LookupEmail(address)
+	}
+	return b.LookupPhone(address)
+}
+
+// samePhone reports whether two phone numbers are equal ignoring
+// formatting such as spaces, dashes, and parentheses.
+func samePhone(a, b string) bool {
+	na, errA := phone.Normalize(a)
+	nb, errB := phone.Normalize(b)
+	if errA == nil && errB == nil {
+		return na == nb
+	}
+	return PhoneDigits(a) == PhoneDigits(b)
+}
+
+// PhoneDigits returns the digits of number, keeping a leading "+".
+func PhoneDigits(number string) string {
+	var b strings.Builder
+	for i, r := range strings.TrimSpace(number) {
+		if (r >= '0' && r <= '9') || (r == '+' && i == 0) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
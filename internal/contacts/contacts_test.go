@@ -0,0 +1,90 @@
+package contacts
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ravi-technologies/sunday-cli/internal/config"
+)
+
+// TestBook_SaveLoad verifies that contacts round-trip through disk and that
+// a missing file loads as an empty book.
+func TestBook_SaveLoad(t *testing.T) {
+	config.SetLocation(t.TempDir())
+	t.Cleanup(func() { config.SetLocation("") })
+
+	book, err := Load()
+	if err != nil {
+		t.Fatalf("Load() on missing file error = %v", err)
+	}
+	if len(book.Contacts) != 0 {
+		t.Fatalf("Load() on missing file returned %d contacts", len(book.Contacts))
+	}
+
+	book.Add("Alice", "+1 (555) 123-4567", "alice@example.com")
+	if err := book.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	loaded, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(loaded.Contacts) != 1 || loaded.Contacts[0].Name != "Alice" {
+		t.Fatalf("Load() = %+v, want Alice", loaded.Contacts)
+	}
+}
+
+// TestBook_Add verifies that adding to an existing contact merges
+// addresses, ignoring case and phone formatting.
+func TestBook_Add(t *testing.T) {
+	var book Book
+	book.Add("Alice", "+15551234567", "alice@example.com")
+	book.Add("alice", "+1 555-123-4567", "ALICE@example.com", "+15559999999")
+
+	if len(book.Contacts) != 1 {
+		t.Fatalf("got %d contacts, want 1", len(book.Contacts))
+	}
+	c := book.Contacts[0]
+	if len(c.Phones) != 2 || len(c.Emails) != 1 {
+		t.Errorf("contact = %+v, want 2 phones and 1 email", c)
+	}
+}
+
+// TestBook_Lookup verifies phone and email lookups.
+func TestBook_Lookup(t *testing.T) {
+	var book Book
+	book.Add("Alice", "+15551234567", "alice@example.com")
+
+	if c, ok := book.LookupPhone("+1 (555) 123-4567"); !ok || c.Name != "Alice" {
+		t.Errorf("LookupPhone() = %+v, %v, want Alice", c, ok)
+	}
+	if c, ok := book.LookupEmail("Alice@Example.com"); !ok || c.Name != "Alice" {
+		t.Errorf("LookupEmail() = %+v, %v, want Alice", c, ok)
+	}
+	if c, ok := book.Lookup("alice@example.com"); !ok || c.Name != "Alice" {
+		t.Errorf("Lookup(email) = %+v, %v, want Alice", c, ok)
+	}
+	if c, ok := book.Lookup("+15551234567"); !ok || c.Name != "Alice" {
+		t.Errorf("Lookup(phone) = %+v, %v, want Alice", c, ok)
+	}
+	if _, ok := book.LookupPhone("+15550000000"); ok {
+		t.Error("LookupPhone() found a contact for an unknown number")
+	}
+}
+
+// TestBook_Remove verifies removal and the error for unknown names.
+func TestBook_Remove(t *testing.T) {
+	var book Book
+	book.Add("Alice", "+15551234567")
+
+	if err := book.Remove("ALICE"); err != nil {
+		t.Fatalf("Remove() error = %v", err)
+	}
+	if len(book.Contacts) != 0 {
+		t.Errorf("Remove() left %d contacts", len(book.Contacts))
+	}
+	if err := book.Remove("Bob"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Remove(unknown) error = %v, want ErrNotFound", err)
+	}
+}
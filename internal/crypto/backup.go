@@ -0,0 +1,178 @@
+package crypto
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"unicode/utf8"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/nacl/secretbox"
+	"golang.org/x/term"
+)
+
+// backupFormat identifies a key backup file.
+const backupFormat = "sunday-key-backup"
+
+// MinPassphraseLength is the fewest characters a backup passphrase may
+// have. A backup can be attacked offline, so it needs more than a PIN.
+const MinPassphraseLength = 12
+
+// ErrWrongPassphrase is returned by OpenBackup when the passphrase doesn't
+// decrypt the backup.
+var ErrWrongPassphrase = errors.New("incorrect backup passphrase")
+
+// KeyBackup is what a backup file holds: the keypair and the salt its PIN
+// derives it with, so that a restored key can still be unlocked by the PIN
+// later.
+type KeyBackup struct {
+	KeyPair KeyPair
+	PINSalt string
+}
+
+// backupFile is the JSON form of a backup file. The key material is
+// sealed with secretbox under Argon2id(passphrase, Salt).
+type backupFile struct {
+	Format     string  `json:"format"`
+	Version    int     `json:"version"`
+	KDF        kdfJSON `json:"kdf"`
+	Salt       string  `json:"salt"`
+	Ciphertext string  `json:"ciphertext"`
+}
+
+type kdfJSON struct {
+	Algorithm string `json:"algorithm"`
+	OpsLimit  uint64 `json:"opslimit"`
+	MemLimit  uint64 `json:"memlimit"`
+}
+
+// backupContents is the plaintext sealed in a backup file.
+type backupContents struct {
+	PrivateKey string `json:"private_key"`
+	PublicKey  string `json:"public_key"`
+	PINSalt    string `json:"pin_salt"`
+}
+
+// SealBackup encrypts b with passphrase, returning the backup file's
+// contents.
+func SealBackup(b *KeyBackup, passphrase string) ([]byte, error) {
+	if utf8.RuneCountInString(passphrase) < MinPassphraseLength {
+		return nil, fmt.Errorf("the passphrase must be at least %d characters", MinPassphraseLength)
+	}
+	salt, err := NewSalt()
+	if err != nil {
+		return nil, err
+	}
+	params := DefaultKDFParams
+	key := backupKey(passphrase, salt, params)
+	defer Wipe(key[:])
+
+	plaintext, err := json.Marshal(backupContents{
+		PrivateKey: base64.StdEncoding.EncodeToString(b.KeyPair.PrivateKey[:]),
+		PublicKey:  base64.StdEncoding.EncodeToString(b.KeyPair.PublicKey[:]),
+		PINSalt:    b.PINSalt,
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer Wipe(plaintext)
+	var nonce [24]byte
+	if _, err := rand.Read(nonce[:]); err != nil {
+		return nil, fmt.Errorf("generating nonce: %w", err)
+	}
+
+	return json.MarshalIndent(backupFile{
+		Format:  backupFormat,
+		Version: 1,
+		KDF: kdfJSON{
+			Algorithm: kdfAlgorithm,
+			OpsLimit:  uint64(params.Time),
+			MemLimit:  uint64(params.MemoryKiB) * 1024,
+		},
+		Salt:       base64.StdEncoding.EncodeToString(salt),
+		Ciphertext: base64.StdEncoding.EncodeToString(secretbox.Seal(nonce[:], plaintext, &nonce, &key)),
+	}, "", "  ")
+}
+
+// OpenBackup decrypts a backup file's contents with passphrase.
+func OpenBackup(data []byte, passphrase string) (*KeyBackup, error) {
+	var f backupFile
+	if err := json.Unmarshal(data, &f); err != nil || f.Format != backupFormat {
+		return nil, errors.New("not a Sunday key backup")
+	}
+	if f.Version != 1 {
+		return nil, fmt.Errorf("unsupported key backup version %d", f.Version)
+	}
+	params, err := NewKDFParams(f.KDF.Algorithm, f.KDF.OpsLimit, f.KDF.MemLimit)
+	if err != nil {
+		return nil, fmt.Errorf("key backup: %w", err)
+	}
+	salt, err := base64.StdEncoding.DecodeString(f.Salt)
+	if err != nil {
+		return nil, fmt.Errorf("decoding key backup salt: %w", err)
+	}
+	sealed, err := base64.StdEncoding.DecodeString(f.Ciphertext)
+	if err != nil || len(sealed) < 24 {
+		return nil, errors.New("malformed key backup")
+	}
+
+	key := backupKey(passphrase, salt, params)
+	defer Wipe(key[:])
+	var nonce [24]byte
+	copy(nonce[:], sealed)
+	plaintext, ok := secretbox.Open(nil, sealed[24:], &nonce, &key)
+	if !ok {
+		return nil, ErrWrongPassphrase
+	}
+	defer Wipe(plaintext)
+
+	var c backupContents
+	if err := json.Unmarshal(plaintext, &c); err != nil {
+		return nil, errors.New("malformed key backup")
+	}
+	priv, err := SoftwareProtector{}.Unwrap(c.PrivateKey)
+	if err != nil {
+		return nil, fmt.Errorf("key backup: %w", err)
+	}
+	b := &KeyBackup{KeyPair: KeyPair{PrivateKey: priv}, PINSalt: c.PINSalt}
+	Wipe(priv[:])
+	pub, err := base64.StdEncoding.DecodeString(c.PublicKey)
+	if err != nil || len(pub) != 32 {
+		return nil, errors.New("key backup has an invalid public key")
+	}
+	copy(b.KeyPair.PublicKey[:], pub)
+	if !b.KeyPair.Consistent() {
+		return nil, errors.New("key backup's public key doesn't match its private key")
+	}
+	return b, nil
+}
+
+// backupKey derives the key a backup is sealed with.
+func backupKey(passphrase string, salt []byte, params KDFParams) [32]byte {
+	pw := []byte(passphrase)
+	defer Wipe(pw)
+	derived := argon2.IDKey(pw, salt, params.Time, params.MemoryKiB, argon2Threads, argon2KeyLen)
+	defer Wipe(derived)
+	var key [32]byte
+	copy(key[:], derived)
+	return key
+}
+
+// PromptPassphrase prompts for a passphrase with hidden input, writing
+// prompt to stderr.
+func PromptPassphrase(prompt string) (string, error) {
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return "", errors.New("passphrase prompt requires an interactive terminal (stdin is not a TTY)")
+	}
+	fmt.Fprint(os.Stderr, prompt)
+	raw, err := term.ReadPassword(int(os.Stdin.Fd()))
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", fmt.Errorf("reading passphrase: %w", err)
+	}
+	defer Wipe(raw)
+	return string(raw), nil
+}
@@ -0,0 +1,42 @@
+package crypto
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// TestBackup_RoundTrip verifies that a sealed backup opens with its
+// passphrase, and only with it.
+func TestBackup_RoundTrip(t *testing.T) {
+	kp := testKeyPair(t)
+	data, err := SealBackup(&KeyBackup{KeyPair: *kp, PINSalt: "c2FsdA=="}, "correct horse battery")
+	if err != nil {
+		t.Fatalf("SealBackup() error = %v", err)
+	}
+	if strings.Contains(string(data), "c2FsdA==") {
+		t.Error("backup holds the PIN salt in plaintext")
+	}
+
+	b, err := OpenBackup(data, "correct horse battery")
+	if err != nil {
+		t.Fatalf("OpenBackup() error = %v", err)
+	}
+	if b.KeyPair != *kp || b.PINSalt != "c2FsdA==" {
+		t.Errorf("OpenBackup() = %+v, want the backed up key and salt", b)
+	}
+	if _, err := OpenBackup(data, "wrong horse battery"); !errors.Is(err, ErrWrongPassphrase) {
+		t.Errorf("OpenBackup() with the wrong passphrase error = %v, want ErrWrongPassphrase", err)
+	}
+	if _, err := OpenBackup([]byte(`{"format":"other"}`), "correct horse battery"); err == nil {
+		t.Error("OpenBackup() of another file succeeded")
+	}
+}
+
+// TestSealBackup_ShortPassphrase verifies that short passphrases are
+// refused.
+func TestSealBackup_ShortPassphrase(t *testing.T) {
+	if _, err := SealBackup(&KeyBackup{KeyPair: *testKeyPair(t)}, "123456"); err == nil {
+		t.Error("SealBackup() with a 6-character passphrase succeeded")
+	}
+}
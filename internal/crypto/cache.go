@@ -0,0 +1,112 @@
+package crypto
+
+import (
+	"container/list"
+	"crypto/hmac"
+	"crypto/sha256"
+	"sync"
+)
+
+// DefaultDecryptCacheSize is how many decrypted fields are kept in memory.
+const DefaultDecryptCacheSize = 4096
+
+// decryptCacheKey identifies a decryption: the HMAC-SHA256 of the
+// encrypted value keyed by the private key. Only the keypair that
+// decrypted a value can look it up again; one that merely carries the same
+// public key, with a wrong or zeroed private key, gets a different key.
+type decryptCacheKey [sha256.Size]byte
+
+// decryptCacheEntry holds its plaintext as bytes, so that it can be wiped
+// when evicted or cleared.
+type decryptCacheEntry struct {
+	key       decryptCacheKey
+	plaintext []byte
+}
+
+// decryptCache is an in-memory LRU of field plaintexts, so listings that
+// are rendered repeatedly (watch modes, re-sorted or regrouped views) do
+// not redo the NaCl operations for values they have already decrypted.
+// Only successful decryptions are cached.
+type decryptCache struct {
+	mu       sync.Mutex
+	capacity int
+	order    *list.List // front is most recently used
+	items    map[decryptCacheKey]*list.Element
+}
+
+var fieldCache = newDecryptCache(DefaultDecryptCacheSize)
+
+func newDecryptCache(capacity int) *decryptCache {
+	return &decryptCache{
+		capacity: capacity,
+		order:    list.New(),
+		items:    map[decryptCacheKey]*list.Element{},
+	}
+}
+
+func cacheKey(value string, kp *KeyPair) decryptCacheKey {
+	h := hmac.New(sha256.New, kp.PrivateKey[:])
+	h.Write([]byte(value))
+	var k decryptCacheKey
+	h.Sum(k[:0])
+	return k
+}
+
+func (c *decryptCache) get(k decryptCacheKey) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.items[k]
+	if !ok {
+		return "", false
+	}
+	c.order.MoveToFront(el)
+	return string(el.Value.(*decryptCacheEntry).plaintext), true
+}
+
+func (c *decryptCache) put(k decryptCacheKey, plaintext string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.capacity <= 0 {
+		return
+	}
+	if el, ok := c.items[k]; ok {
+		c.order.MoveToFront(el)
+		return
+	}
+	c.items[k] = c.order.PushFront(&decryptCacheEntry{key: k, plaintext: []byte(plaintext)})
+	for c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		e := oldest.Value.(*decryptCacheEntry)
+		Wipe(e.plaintext)
+		delete(c.items, e.key)
+	}
+}
+
+// reset empties the cache and, if capacity is not negative, resizes it.
+func (c *decryptCache) reset(capacity int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if capacity >= 0 {
+		c.capacity = capacity
+	}
+	for el := c.order.Front(); el != nil; el = el.Next() {
+		Wipe(el.Value.(*decryptCacheEntry).plaintext)
+	}
+	c.order.Init()
+	clear(c.items)
+}
+
+// SetDecryptCacheSize changes how many decrypted fields DecryptField keeps
+// in memory and empties the cache. Zero disables caching.
+func SetDecryptCacheSize(n int) {
+	fieldCache.reset(max(n, 0))
+}
+
+// ClearDecryptCache wipes and drops every cached plaintext.
+func ClearDecryptCache() {
+	fieldCache.reset(-1)
+}
@@ -0,0 +1,145 @@
+package crypto
+
+import (
+	"encoding/base64"
+	"fmt"
+	"testing"
+)
+
+// TestDecryptCache_Eviction verifies that the least recently used entry is
+// evicted once the cache is full.
+func TestDecryptCache_Eviction(t *testing.T) {
+	c := newDecryptCache(2)
+	a, b, d := decryptCacheKey{1}, decryptCacheKey{2}, decryptCacheKey{3}
+
+	c.put(a, "a")
+	c.put(b, "b")
+	c.get(a) // a is now more recently used than b
+	c.put(d, "d")
+
+	if _, ok := c.get(b); ok {
+		t.Error("least recently used entry was not evicted")
+	}
+	if got, ok := c.get(a); !ok || got != "a" {
+		t.Errorf("get(a) = %q, %v, want a", got, ok)
+	}
+	if got, ok := c.get(d); !ok || got != "d" {
+		t.Errorf("get(d) = %q, %v, want d", got, ok)
+	}
+}
+
+// TestDecryptField_Cache verifies that decrypted values are cached per
+// keypair, and that clearing the keypair clears the cache.
+func TestDecryptField_Cache(t *testing.T) {
+	ClearDecryptCache()
+	kp := testKeyPair(t)
+	value := EncryptedPrefix + base64.StdEncoding.EncodeToString(testEncrypt(t, []byte("hello"), kp))
+
+	if _, err := DecryptField(value, kp); err != nil {
+		t.Fatalf("DecryptField() error = %v", err)
+	}
+	if got, ok := fieldCache.get(cacheKey(value, kp)); !ok || got != "hello" {
+		t.Fatalf("cache entry = %q, %v, want hello", got, ok)
+	}
+
+	other, err := DeriveKeyPair("654321", make([]byte, 16))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := DecryptField(value, other); err == nil {
+		t.Error("DecryptField() with a different keypair used the cached plaintext")
+	}
+	impostor := &KeyPair{PublicKey: kp.PublicKey}
+	if _, err := DecryptField(value, impostor); err == nil {
+		t.Error("DecryptField() with the right public key but no private key used the cached plaintext")
+	}
+
+	ClearCachedKeyPair()
+	if _, ok := fieldCache.get(cacheKey(value, kp)); ok {
+		t.Error("ClearCachedKeyPair() left plaintext in the cache")
+	}
+
+	if _, err := DecryptField(value, kp); err != nil {
+		t.Fatalf("DecryptField() error = %v", err)
+	}
+	k := cacheKey(value, kp)
+	entry := fieldCache.items[k].Value.(*decryptCacheEntry)
+	kp.Wipe()
+	if _, ok := fieldCache.get(k); ok {
+		t.Error("KeyPair.Wipe() left plaintext in the cache")
+	}
+	if string(entry.plaintext) == "hello" {
+		t.Error("the dropped plaintext was not wiped")
+	}
+}
+
+// TestSetDecryptCacheSize_Zero verifies that a zero size disables caching.
+func TestSetDecryptCacheSize_Zero(t *testing.T) {
+	SetDecryptCacheSize(0)
+	t.Cleanup(func() { SetDecryptCacheSize(DefaultDecryptCacheSize) })
+
+	kp := testKeyPair(t)
+	value := EncryptedPrefix + base64.StdEncoding.EncodeToString(testEncrypt(t, []byte("hello"), kp))
+	if _, err := DecryptField(value, kp); err != nil {
+		t.Fatalf("DecryptField() error = %v", err)
+	}
+	if _, ok := fieldCache.get(cacheKey(value, kp)); ok {
+		t.Error("value was cached with caching disabled")
+	}
+}
+
+// benchmarkDecryptFields decrypts the same 500 fields on every iteration,
+// as a re-rendered listing would.
+func benchmarkDecryptFields(b *testing.B, cacheSize int) {
+	SetDecryptCacheSize(cacheSize)
+	b.Cleanup(func() { SetDecryptCacheSize(DefaultDecryptCacheSize) })
+
+	kp := testKeyPair(b)
+	values := make([]string, 500)
+	for i := range values {
+		ct := testEncrypt(b, fmt.Appendf(nil, "message body %d", i), kp)
+		values[i] = EncryptedPrefix + base64.StdEncoding.EncodeToString(ct)
+	}
+
+	for b.Loop() {
+		for _, v := range values {
+			if _, err := DecryptField(v, kp); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+func BenchmarkDecryptField_Uncached(b *testing.B) {
+	benchmarkDecryptFields(b, 0)
+}
+
+func BenchmarkDecryptField_Cached(b *testing.B) {
+	benchmarkDecryptFields(b, DefaultDecryptCacheSize)
+}
+
+// BenchmarkDecryptFields_Parallel decrypts the same 500 fields as
+// BenchmarkDecryptField_Uncached, but with DecryptFields.
+func BenchmarkDecryptFields_Parallel(b *testing.B) {
+	SetDecryptCacheSize(0)
+	b.Cleanup(func() { SetDecryptCacheSize(DefaultDecryptCacheSize) })
+
+	kp := testKeyPair(b)
+	values := make([]string, 500)
+	for i := range values {
+		ct := testEncrypt(b, fmt.Appendf(nil, "message body %d", i), kp)
+		values[i] = EncryptedPrefix + base64.StdEncoding.EncodeToString(ct)
+	}
+
+	fields := make([]string, len(values))
+	ptrs := make([]*string, len(values))
+	for b.Loop() {
+		copy(fields, values)
+		for i := range fields {
+			ptrs[i] = &fields[i]
+		}
+		if errs := DecryptFields(ptrs, kp); len(errs) > 0 {
+			b.Fatal(errs[0])
+		}
+	}
+}
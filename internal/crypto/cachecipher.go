@@ -0,0 +1,91 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/crypto/nacl/secretbox"
+)
+
+// cacheKeyInfo separates the cache key from anything else that might one
+// day be derived from the private key.
+const cacheKeyInfo = "sunday-cli local cache v1"
+
+// cacheMagic starts every file encrypted by a CacheCipher.
+var cacheMagic = []byte("SCC1")
+
+// ErrCacheKey is returned by DecryptFile for a file that wasn't encrypted
+// with this cipher's key, such as one written before a PIN change.
+var ErrCacheKey = errors.New("cache file was not encrypted with this key")
+
+// CacheCipher encrypts files cached on disk with a symmetric key derived
+// from the private key, so that local caches never hold plaintext.
+type CacheCipher struct {
+	key [32]byte
+}
+
+// NewCacheCipher derives the cache key from kp's private key with HKDF.
+func NewCacheCipher(kp *KeyPair) (*CacheCipher, error) {
+	key, err := hkdf.Key(sha256.New, kp.PrivateKey[:], nil, cacheKeyInfo, 32)
+	if err != nil {
+		return nil, fmt.Errorf("deriving cache key: %w", err)
+	}
+	defer Wipe(key)
+
+	var c CacheCipher
+	copy(c.key[:], key)
+	return &c, nil
+}
+
+// EncryptFile writes plaintext, encrypted, to name. The file is replaced
+// atomically, so a concurrent reader never sees half of it, and is only
+// readable by the user.
+func (c *CacheCipher) EncryptFile(name string, plaintext []byte) error {
+	var nonce [24]byte
+	if _, err := rand.Read(nonce[:]); err != nil {
+		return fmt.Errorf("generating nonce: %w", err)
+	}
+	data := append(bytes.Clone(cacheMagic), nonce[:]...)
+	data = secretbox.Seal(data, plaintext, &nonce, &c.key)
+
+	tmp, err := os.CreateTemp(filepath.Dir(name), ".encrypted-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), name)
+}
+
+// DecryptFile reads and decrypts a file written by EncryptFile. It fails
+// with ErrCacheKey if the file is plaintext or was encrypted with another
+// key.
+func (c *CacheCipher) DecryptFile(name string) ([]byte, error) {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	sealed, ok := bytes.CutPrefix(data, cacheMagic)
+	if !ok || len(sealed) < 24 {
+		return nil, ErrCacheKey
+	}
+	var nonce [24]byte
+	copy(nonce[:], sealed)
+	plaintext, ok := secretbox.Open(nil, sealed[24:], &nonce, &c.key)
+	if !ok {
+		return nil, ErrCacheKey
+	}
+	return plaintext, nil
+}
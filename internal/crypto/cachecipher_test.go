@@ -0,0 +1,52 @@
+package crypto
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestCacheCipher verifies that files round-trip, aren't stored in
+// plaintext, and can't be read with another keypair's cipher.
+func TestCacheCipher(t *testing.T) {
+	kp := testKeyPair(t)
+	c, err := NewCacheCipher(kp)
+	if err != nil {
+		t.Fatalf("NewCacheCipher() error = %v", err)
+	}
+	name := filepath.Join(t.TempDir(), "cached")
+	plaintext := []byte(`{"from_email":"alice@example.com"}`)
+
+	if err := c.EncryptFile(name, plaintext); err != nil {
+		t.Fatalf("EncryptFile() error = %v", err)
+	}
+	data, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(data, []byte("alice")) {
+		t.Error("file holds plaintext")
+	}
+	if info, err := os.Stat(name); err != nil || info.Mode().Perm() != 0o600 {
+		t.Errorf("file mode = %v, %v; want 0600", info.Mode().Perm(), err)
+	}
+	if got, err := c.DecryptFile(name); err != nil || !bytes.Equal(got, plaintext) {
+		t.Errorf("DecryptFile() = %q, %v; want %q", got, err, plaintext)
+	}
+
+	other, err := NewCacheCipher(&KeyPair{PrivateKey: [32]byte{1}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := other.DecryptFile(name); !errors.Is(err, ErrCacheKey) {
+		t.Errorf("DecryptFile() with another key error = %v, want ErrCacheKey", err)
+	}
+	if err := os.WriteFile(name, plaintext, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.DecryptFile(name); !errors.Is(err, ErrCacheKey) {
+		t.Errorf("DecryptFile() of a plaintext file error = %v, want ErrCacheKey", err)
+	}
+}
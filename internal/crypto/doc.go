@@ -11,6 +11,15 @@
 //  4. Ciphertext format: "e2e::<base64-ciphertext>".
 //
 // The package also provides session helpers that prompt the user for their
-// 6-digit PIN, derive the keypair, verify it against the server-stored
+// PIN (6 digits, or a passphrase under the account's PINPolicy), derive the keypair, verify it against the server-stored
 // verifier, and cache the keypair in memory for the duration of the process.
+// Accounts without a PIN have a managed master key instead, which can't be
+// unlocked yet (see UsesManagedKey).
+// Fields shared with other users are encrypted to several public keys at
+// once, as "e2em::<base64>" (see RecipientsPrefix for the layout), which
+// is only written to servers that report supporting it.
+// Payloads too large to hold in memory whole use a chunked stream format
+// instead of a single SealedBox (see NewDecryptReader).
+// A KeyProtector decides how the private key is stored between processes:
+// as it is, or wrapped with a hardware security key.
 package crypto
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/ravi-technologies/sunday-cli/internal/timing"
 	"golang.org/x/crypto/argon2"
 	"golang.org/x/crypto/curve25519"
 	"golang.org/x/crypto/nacl/box"
@@ -24,9 +25,9 @@ const EncryptedPrefix = "e2e::"
 // verifyPlaintext is the literal string encrypted inside the verifier.
 const verifyPlaintext = "sunday-e2e-verify"
 
-// Argon2id parameters -- must match libsodium's crypto_pwhash exactly.
+// Default Argon2id parameters -- must match libsodium's crypto_pwhash exactly.
 // libsodium hardcodes parallelism=1 internally; the caller only controls
-// opslimit (time) and memlimit (memory).
+// opslimit (time) and memlimit (memory). See DefaultKDFParams.
 const (
 	argon2Time    = 3
 	argon2Memory  = 64 * 1024 // 64 MB expressed in KiB (the Go argon2 API uses KiB)
@@ -44,11 +45,37 @@ const (
 //
 // The salt must be the raw 16-byte value (base64-decoded) stored on the server.
 func DeriveKeyPair(pin string, salt []byte) (*KeyPair, error) {
-	seed := argon2.IDKey([]byte(pin), salt, argon2Time, argon2Memory, argon2Threads, argon2KeyLen)
+	return DeriveKeyPairWithParams(pin, salt, DefaultKDFParams)
+}
+
+// DeriveKeyPairWithParams is DeriveKeyPair with explicit Argon2id cost
+// parameters, which are validated first.
+func DeriveKeyPairWithParams(pin string, salt []byte, params KDFParams) (*KeyPair, error) {
+	b := []byte(pin)
+	defer Wipe(b)
+	return deriveKeyPair(b, salt, params)
+}
+
+// deriveKeyPair is DeriveKeyPairWithParams for a PIN held as bytes, which
+// unlike a string can be wiped afterwards.
+func deriveKeyPair(pin, salt []byte, params KDFParams) (*KeyPair, error) {
+	if err := params.Validate(); err != nil {
+		return nil, err
+	}
+	defer timing.Start("argon2 key derivation")()
+
+	seed := argon2.IDKey(pin, salt, params.Time, params.MemoryKiB, argon2Threads, argon2KeyLen)
+	defer Wipe(seed)
+	return keyPairFromSeed(seed)
+}
 
+// keyPairFromSeed derives the keypair from a 32-byte seed as libsodium's
+// crypto_box_seed_keypair does.
+func keyPairFromSeed(seed []byte) (*KeyPair, error) {
 	// Replicate libsodium's crypto_box_seed_keypair:
 	// 1. SHA-512 hash the seed
 	hash := sha512.Sum512(seed)
+	defer Wipe(hash[:])
 
 	// 2. Take first 32 bytes and apply Curve25519 clamping
 	var privateKey [32]byte
@@ -56,6 +83,7 @@ func DeriveKeyPair(pin string, salt []byte) (*KeyPair, error) {
 	privateKey[0] &= 248
 	privateKey[31] &= 127
 	privateKey[31] |= 64
+	defer Wipe(privateKey[:])
 
 	// 3. Derive public key via scalar base multiplication
 	publicKey, err := curve25519.X25519(privateKey[:], curve25519.Basepoint)
@@ -70,6 +98,14 @@ func DeriveKeyPair(pin string, salt []byte) (*KeyPair, error) {
 	return &kp, nil
 }
 
+// Consistent reports whether kp's public key is the one its private key
+// derives, rather than one stored alongside it that may belong to another
+// key.
+func (kp *KeyPair) Consistent() bool {
+	derived, err := curve25519.X25519(kp.PrivateKey[:], curve25519.Basepoint)
+	return err == nil && [32]byte(derived) == kp.PublicKey
+}
+
 // Decrypt decrypts a NaCl SealedBox ciphertext using the keypair.
 // The ciphertext must be the raw bytes (not base64-encoded, no prefix).
 func Decrypt(ciphertext []byte, kp *KeyPair) ([]byte, error) {
@@ -80,29 +116,45 @@ func Decrypt(ciphertext []byte, kp *KeyPair) ([]byte, error) {
 	return plaintext, nil
 }
 
-// DecryptField decrypts an "e2e::<base64>" string, returning the plaintext.
-// If the value does not carry the encrypted prefix it is returned unchanged.
+// DecryptField decrypts an "e2e::<base64>" string, or one encrypted to
+// several recipients (see RecipientsPrefix), returning the plaintext.
+// If the value does not carry an encrypted prefix it is returned unchanged.
+// Results are cached in memory, so decrypting the same value again is a
+// hash lookup.
 func DecryptField(value string, kp *KeyPair) (string, error) {
 	if !IsEncrypted(value) {
 		return value, nil
 	}
-
-	b64 := strings.TrimPrefix(value, EncryptedPrefix)
-	ciphertext, err := base64.StdEncoding.DecodeString(b64)
-	if err != nil {
-		return "", fmt.Errorf("decoding base64 ciphertext: %w", err)
+	k := cacheKey(value, kp)
+	if plaintext, ok := fieldCache.get(k); ok {
+		return plaintext, nil
 	}
-
-	plaintext, err := Decrypt(ciphertext, kp)
-	if err != nil {
-		return "", err
+	defer timing.Start("decrypt")()
+
+	var plaintext []byte
+	if strings.HasPrefix(value, RecipientsPrefix) {
+		var err error
+		if plaintext, err = decryptRecipients(value, kp); err != nil {
+			return "", err
+		}
+	} else {
+		b64 := strings.TrimPrefix(value, EncryptedPrefix)
+		ciphertext, err := base64.StdEncoding.DecodeString(b64)
+		if err != nil {
+			return "", fmt.Errorf("decoding base64 ciphertext: %w", err)
+		}
+		if plaintext, err = Decrypt(ciphertext, kp); err != nil {
+			return "", err
+		}
 	}
+	fieldCache.put(k, string(plaintext))
 	return string(plaintext), nil
 }
 
-// IsEncrypted reports whether value carries the "e2e::" prefix.
+// IsEncrypted reports whether value carries the "e2e::" prefix, or the
+// prefix of a value encrypted to several recipients.
 func IsEncrypted(value string) bool {
-	return strings.HasPrefix(value, EncryptedPrefix)
+	return strings.HasPrefix(value, EncryptedPrefix) || strings.HasPrefix(value, RecipientsPrefix)
 }
 
 // Verify checks that a keypair can decrypt the server-stored verifier.
@@ -137,17 +189,11 @@ func Encrypt(plaintext string, publicKeyB64 string) (string, error) {
 		return "", nil
 	}
 
-	pubBytes, err := base64.StdEncoding.DecodeString(publicKeyB64)
+	pubKey, err := decodePublicKey(publicKeyB64)
 	if err != nil {
-		return "", fmt.Errorf("decoding public key: %w", err)
-	}
-	if len(pubBytes) != 32 {
-		return "", fmt.Errorf("public key has invalid length %d, expected 32", len(pubBytes))
+		return "", err
 	}
 
-	var pubKey [32]byte
-	copy(pubKey[:], pubBytes)
-
 	ciphertext, err := box.SealAnonymous(nil, []byte(plaintext), &pubKey, rand.Reader)
 	if err != nil {
 		return "", fmt.Errorf("encrypting: %w", err)
@@ -155,3 +201,41 @@ func Encrypt(plaintext string, publicKeyB64 string) (string, error) {
 
 	return EncryptedPrefix + base64.StdEncoding.EncodeToString(ciphertext), nil
 }
+
+// saltLen is the length of the salt a keypair is derived with.
+const saltLen = 16
+
+// NewSalt returns a random salt to derive a keypair from a new PIN with.
+func NewSalt() ([]byte, error) {
+	salt := make([]byte, saltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("generating salt: %w", err)
+	}
+	return salt, nil
+}
+
+// ReencryptField re-encrypts an "e2e::<base64>" value from one keypair to
+// another, e.g. after a PIN change, reporting whether it changed. A value
+// that isn't encrypted, or is already encrypted to `to`, is returned
+// unchanged. A value encrypted to several recipients keeps the others.
+func ReencryptField(value string, from, to *KeyPair) (string, bool, error) {
+	if !IsEncrypted(value) {
+		return value, false, nil
+	}
+	if strings.HasPrefix(value, RecipientsPrefix) {
+		return rewrapRecipient(value, from, to)
+	}
+	plaintext, err := DecryptField(value, from)
+	if err != nil {
+		if _, err := DecryptField(value, to); err == nil {
+			return value, false, nil
+		}
+		return "", false, err
+	}
+	// Not Encrypt, which would turn an encrypted "" into a plain one.
+	ciphertext, err := box.SealAnonymous(nil, []byte(plaintext), &to.PublicKey, rand.Reader)
+	if err != nil {
+		return "", false, fmt.Errorf("encrypting: %w", err)
+	}
+	return EncryptedPrefix + base64.StdEncoding.EncodeToString(ciphertext), true, nil
+}
@@ -12,7 +12,7 @@ import (
 
 // testKeyPair derives a deterministic keypair for tests using PIN "123456"
 // and a 16-byte zero salt. This avoids repeated boilerplate in edge-case tests.
-func testKeyPair(t *testing.T) *KeyPair {
+func testKeyPair(t testing.TB) *KeyPair {
 	t.Helper()
 	kp, err := DeriveKeyPair("123456", make([]byte, 16))
 	if err != nil {
@@ -22,7 +22,7 @@ func testKeyPair(t *testing.T) *KeyPair {
 }
 
 // testEncrypt encrypts plaintext with SealAnonymous for the given keypair.
-func testEncrypt(t *testing.T, plaintext []byte, kp *KeyPair) []byte {
+func testEncrypt(t testing.TB, plaintext []byte, kp *KeyPair) []byte {
 	t.Helper()
 	ciphertext, err := box.SealAnonymous(nil, plaintext, &kp.PublicKey, rand.Reader)
 	if err != nil {
@@ -563,12 +563,16 @@ func TestClearCachedKeyPair(t *testing.T) {
 	if cachedKeyPair == nil {
 		t.Fatal("cachedKeyPair should be non-nil after assignment")
 	}
+	kp := cachedKeyPair
 
 	ClearCachedKeyPair()
 
 	if cachedKeyPair != nil {
 		t.Error("cachedKeyPair should be nil after ClearCachedKeyPair")
 	}
+	if *kp != (KeyPair{}) {
+		t.Error("ClearCachedKeyPair left the key in memory")
+	}
 }
 
 // ---------------------------------------------------------------------------
@@ -687,3 +691,59 @@ func TestEncrypt_DifferentCiphertextsEachCall(t *testing.T) {
 		t.Error("two Encrypt calls produced identical ciphertexts (expected ephemeral randomness)")
 	}
 }
+
+// TestReencryptField verifies that a field is moved to the new keypair,
+// and that plain values and fields already moved are left alone.
+func TestReencryptField(t *testing.T) {
+	from := testKeyPair(t)
+	to, err := DeriveKeyPair("654321", make([]byte, 16))
+	if err != nil {
+		t.Fatalf("DeriveKeyPair() error = %v", err)
+	}
+	field := EncryptedPrefix + base64.StdEncoding.EncodeToString(testEncrypt(t, []byte("secret"), from))
+
+	moved, changed, err := ReencryptField(field, from, to)
+	if err != nil || !changed {
+		t.Fatalf("ReencryptField() = %q, %v, %v", moved, changed, err)
+	}
+	if got, err := DecryptField(moved, to); err != nil || got != "secret" {
+		t.Errorf("DecryptField(moved) = %q, %v; want secret", got, err)
+	}
+
+	if got, changed, err := ReencryptField(moved, from, to); err != nil || changed || got != moved {
+		t.Errorf("ReencryptField(moved) = %q, %v, %v; want it unchanged", got, changed, err)
+	}
+	if got, changed, err := ReencryptField("plain", from, to); err != nil || changed || got != "plain" {
+		t.Errorf("ReencryptField(plain) = %q, %v, %v; want it unchanged", got, changed, err)
+	}
+
+	stranger, _ := DeriveKeyPair("111111", make([]byte, 16))
+	foreign := EncryptedPrefix + base64.StdEncoding.EncodeToString(testEncrypt(t, []byte("x"), stranger))
+	if _, _, err := ReencryptField(foreign, from, to); err == nil {
+		t.Error("ReencryptField() of a field for another key succeeded")
+	}
+}
+
+// TestNewSalt verifies that salts have the length the server stores and
+// differ between calls.
+func TestNewSalt(t *testing.T) {
+	a, err := NewSalt()
+	if err != nil {
+		t.Fatalf("NewSalt() error = %v", err)
+	}
+	b, _ := NewSalt()
+	if len(a) != 16 || string(a) == string(b) {
+		t.Errorf("NewSalt() = %x, %x; want two different 16-byte salts", a, b)
+	}
+}
+
+// TestKeyPairWipe verifies that Wipe zeroes a keypair, and is safe on nil.
+func TestKeyPairWipe(t *testing.T) {
+	kp := testKeyPair(t)
+	kp.Wipe()
+	if *kp != (KeyPair{}) {
+		t.Errorf("Wipe() left %x", kp.PrivateKey)
+	}
+	var nilKP *KeyPair
+	nilKP.Wipe()
+}
@@ -0,0 +1,48 @@
+package crypto
+
+import (
+	"runtime"
+	"sync"
+)
+
+// DecryptFields decrypts the "e2e::" values fields point to in place, on
+// up to GOMAXPROCS goroutines, so that long listings decrypt on every
+// core. Values without the prefix are left alone, as are those that fail
+// to decrypt; the failures' errors are returned in the order of fields.
+func DecryptFields(fields []*string, kp *KeyPair) []error {
+	var encrypted []*string
+	for _, f := range fields {
+		if IsEncrypted(*f) {
+			encrypted = append(encrypted, f)
+		}
+	}
+	errs := make([]error, len(encrypted))
+
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for range min(runtime.GOMAXPROCS(0), len(encrypted)) {
+		wg.Go(func() {
+			for i := range next {
+				plaintext, err := DecryptField(*encrypted[i], kp)
+				if err != nil {
+					errs[i] = err
+					continue
+				}
+				*encrypted[i] = plaintext
+			}
+		})
+	}
+	for i := range encrypted {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+
+	var failed []error
+	for _, err := range errs {
+		if err != nil {
+			failed = append(failed, err)
+		}
+	}
+	return failed
+}
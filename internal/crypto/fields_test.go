@@ -0,0 +1,41 @@
+package crypto
+
+import (
+	"encoding/base64"
+	"fmt"
+	"testing"
+)
+
+// TestDecryptFields verifies that encrypted values are decrypted in place,
+// plain ones left alone, and failures reported without stopping the rest.
+func TestDecryptFields(t *testing.T) {
+	kp := testKeyPair(t)
+	values := make([]string, 50)
+	for i := range values {
+		values[i] = EncryptedPrefix + base64.StdEncoding.EncodeToString(testEncrypt(t, fmt.Appendf(nil, "body %d", i), kp))
+	}
+	values[3] = "plain"
+	values[7] = EncryptedPrefix + "not base64!"
+
+	fields := make([]*string, len(values))
+	for i := range values {
+		fields[i] = &values[i]
+	}
+	errs := DecryptFields(fields, kp)
+	if len(errs) != 1 {
+		t.Fatalf("DecryptFields() errors = %v, want 1", errs)
+	}
+
+	for i, v := range values {
+		want := fmt.Sprintf("body %d", i)
+		switch i {
+		case 3:
+			want = "plain"
+		case 7:
+			want = EncryptedPrefix + "not base64!"
+		}
+		if v != want {
+			t.Errorf("field %d = %q, want %q", i, v, want)
+		}
+	}
+}
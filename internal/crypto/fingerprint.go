@@ -0,0 +1,43 @@
+package crypto
+
+import (
+	"crypto/sha256"
+	"encoding/base32"
+	"strings"
+)
+
+// fingerprintLen is how many bytes of the public key's hash a fingerprint
+// shows: 160 bits, 32 base32 characters.
+const fingerprintLen = 20
+
+// Fingerprint returns a short, human-readable fingerprint of a base64
+// public key: the base32 of the start of its SHA-256 hash, in groups of
+// four characters, such as "MFRG GZDF ...". The dashboard shows the same
+// fingerprint for the account's key, so the two can be compared.
+func Fingerprint(publicKeyB64 string) (string, error) {
+	pub, err := decodePublicKey(publicKeyB64)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(pub[:])
+	enc := base32.StdEncoding.EncodeToString(sum[:fingerprintLen])
+
+	var b strings.Builder
+	for i := 0; i < len(enc); i += 4 {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteString(enc[i : i+4])
+	}
+	return b.String(), nil
+}
+
+// FingerprintsMatch reports whether two fingerprints are the same,
+// ignoring case, spaces and dashes, as they may be written down or read
+// out differently.
+func FingerprintsMatch(a, b string) bool {
+	normalize := func(s string) string {
+		return strings.ToUpper(strings.NewReplacer(" ", "", "-", "").Replace(s))
+	}
+	return normalize(a) == normalize(b)
+}
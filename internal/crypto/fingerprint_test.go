@@ -0,0 +1,42 @@
+package crypto
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+)
+
+// TestFingerprint verifies the fingerprint's format, that it is stable
+// for a key and differs between keys, and that written-down variants of
+// it match.
+func TestFingerprint(t *testing.T) {
+	kp := testKeyPair(t)
+	pub := base64.StdEncoding.EncodeToString(kp.PublicKey[:])
+
+	fp, err := Fingerprint(pub)
+	if err != nil {
+		t.Fatalf("Fingerprint() error = %v", err)
+	}
+	groups := strings.Split(fp, " ")
+	if len(groups) != 8 || len(groups[0]) != 4 {
+		t.Errorf("Fingerprint() = %q, want 8 groups of 4", fp)
+	}
+	if again, _ := Fingerprint(pub); again != fp {
+		t.Errorf("Fingerprint() = %q then %q", fp, again)
+	}
+	other, _ := Fingerprint(base64.StdEncoding.EncodeToString(make([]byte, 32)))
+	if other == fp {
+		t.Error("two keys have the same fingerprint")
+	}
+
+	if !FingerprintsMatch(fp, strings.ToLower(strings.ReplaceAll(fp, " ", "-"))) {
+		t.Error("FingerprintsMatch() rejected the same fingerprint written differently")
+	}
+	if FingerprintsMatch(fp, other) {
+		t.Error("FingerprintsMatch() accepted a different fingerprint")
+	}
+
+	if _, err := Fingerprint("c2hvcnQ="); err == nil {
+		t.Error("Fingerprint() of a short key succeeded")
+	}
+}
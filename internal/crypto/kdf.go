@@ -0,0 +1,67 @@
+package crypto
+
+import (
+	"errors"
+	"fmt"
+)
+
+// KDFParams are the Argon2id cost parameters used to derive the keypair
+// from the PIN.
+type KDFParams struct {
+	Time      uint32 // iterations (libsodium opslimit)
+	MemoryKiB uint32 // memory in KiB (libsodium memlimit / 1024)
+}
+
+// DefaultKDFParams match the dashboard's libsodium crypto_pwhash call and
+// are used when the server doesn't advertise parameters.
+var DefaultKDFParams = KDFParams{Time: argon2Time, MemoryKiB: argon2Memory}
+
+// Upper bounds on server-supplied parameters, so a malicious or broken
+// server can't make the CLI burn minutes of CPU or gigabytes of memory.
+const (
+	maxKDFTime      = 16
+	maxKDFMemoryKiB = 1 << 20 // 1 GiB
+)
+
+// kdfAlgorithm is the only key derivation function the CLI accepts.
+const kdfAlgorithm = "argon2id"
+
+// ErrWeakKDFParams is returned for parameters weaker than DefaultKDFParams.
+// Accepting them would let a compromised server downgrade PIN hardening.
+var ErrWeakKDFParams = errors.New("KDF parameters are weaker than the minimum")
+
+// NewKDFParams validates key derivation parameters from the server's
+// encryption metadata. memLimit is in bytes, as libsodium reports it. If
+// all arguments are zero the server advertised nothing and the defaults are
+// returned.
+func NewKDFParams(algorithm string, opsLimit, memLimit uint64) (KDFParams, error) {
+	if algorithm == "" && opsLimit == 0 && memLimit == 0 {
+		return DefaultKDFParams, nil
+	}
+	if algorithm != kdfAlgorithm {
+		return KDFParams{}, fmt.Errorf("unsupported KDF algorithm %q", algorithm)
+	}
+	if opsLimit > maxKDFTime || memLimit/1024 > maxKDFMemoryKiB {
+		return KDFParams{}, fmt.Errorf("KDF parameters exceed the maximum (opslimit %d, memlimit %d MiB)",
+			maxKDFTime, maxKDFMemoryKiB/1024)
+	}
+
+	p := KDFParams{Time: uint32(opsLimit), MemoryKiB: uint32(memLimit / 1024)}
+	if err := p.Validate(); err != nil {
+		return KDFParams{}, err
+	}
+	return p, nil
+}
+
+// Validate rejects parameters weaker than DefaultKDFParams or above the
+// CLI's resource limits.
+func (p KDFParams) Validate() error {
+	if p.Time < DefaultKDFParams.Time || p.MemoryKiB < DefaultKDFParams.MemoryKiB {
+		return fmt.Errorf("%w (got opslimit %d, memlimit %d KiB)", ErrWeakKDFParams, p.Time, p.MemoryKiB)
+	}
+	if p.Time > maxKDFTime || p.MemoryKiB > maxKDFMemoryKiB {
+		return fmt.Errorf("KDF parameters exceed the maximum (opslimit %d, memlimit %d MiB)",
+			maxKDFTime, maxKDFMemoryKiB/1024)
+	}
+	return nil
+}
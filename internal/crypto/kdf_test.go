@@ -0,0 +1,48 @@
+package crypto
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestNewKDFParams verifies acceptance of the defaults and stronger
+// parameters, and rejection of downgrades, unknown algorithms, and
+// resource-exhausting values.
+func TestNewKDFParams(t *testing.T) {
+	tests := []struct {
+		name      string
+		algorithm string
+		ops, mem  uint64
+		want      KDFParams
+		wantErr   bool
+	}{
+		{"not advertised", "", 0, 0, DefaultKDFParams, false},
+		{"defaults", "argon2id", 3, 64 << 20, DefaultKDFParams, false},
+		{"stronger", "argon2id", 4, 256 << 20, KDFParams{Time: 4, MemoryKiB: 256 << 10}, false},
+		{"fewer iterations", "argon2id", 2, 64 << 20, KDFParams{}, true},
+		{"less memory", "argon2id", 3, 32 << 20, KDFParams{}, true},
+		{"wrong algorithm", "argon2i", 3, 64 << 20, KDFParams{}, true},
+		{"too much memory", "argon2id", 3, 8 << 30, KDFParams{}, true},
+		{"too many iterations", "argon2id", 1000, 64 << 20, KDFParams{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewKDFParams(tt.algorithm, tt.ops, tt.mem)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewKDFParams() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("NewKDFParams() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestDeriveKeyPairWithParams_RejectsDowngrade verifies that weak parameters
+// never reach Argon2.
+func TestDeriveKeyPairWithParams_RejectsDowngrade(t *testing.T) {
+	_, err := DeriveKeyPairWithParams("123456", make([]byte, 16), KDFParams{Time: 1, MemoryKiB: 8})
+	if !errors.Is(err, ErrWeakKDFParams) {
+		t.Errorf("DeriveKeyPairWithParams() error = %v, want ErrWeakKDFParams", err)
+	}
+}
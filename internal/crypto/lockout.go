@@ -0,0 +1,99 @@
+package crypto
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Once lockoutFreeAttempts incorrect PINs have been entered in a row, each
+// further one locks the PIN for lockoutBase, doubling every time up to
+// lockoutMax. A correct PIN resets the count.
+const (
+	lockoutFreeAttempts = maxPINAttempts
+	lockoutBase         = 30 * time.Second
+	lockoutMax          = time.Hour
+)
+
+// ErrPINLocked is returned while the PIN may not be tried, after too many
+// incorrect ones.
+var ErrPINLocked = errors.New("too many incorrect PINs")
+
+// PINLockout is how many incorrect PINs were entered in a row, and until
+// when the PIN is locked.
+type PINLockout struct {
+	Failures int
+	Until    time.Time
+}
+
+// LockoutStore keeps the PIN lockout between processes. UpdateLockout
+// applies fn to the stored lockout and saves the result, without another
+// process saving in between, so that incorrect PINs entered at the same
+// time in several terminals are all counted.
+type LockoutStore interface {
+	LoadLockout() (PINLockout, error)
+	UpdateLockout(fn func(*PINLockout)) error
+}
+
+// PINLockoutStore keeps the PIN lockout across runs, so that guessing the
+// PIN isn't a matter of running the CLI again. The CLI keeps it in the
+// config file; without one, only maxPINAttempts per process limits
+// guesses.
+var PINLockoutStore LockoutStore
+
+// CheckPINLockout returns an error wrapping ErrPINLocked, saying how long
+// to wait, if the PIN is locked.
+func CheckPINLockout() error {
+	if PINLockoutStore == nil {
+		return nil
+	}
+	l, err := PINLockoutStore.LoadLockout()
+	if err != nil {
+		return fmt.Errorf("reading PIN lockout: %w", err)
+	}
+	if wait := time.Until(l.Until); wait > 0 {
+		return fmt.Errorf("%w; try again in %s", ErrPINLocked, wait.Round(time.Second))
+	}
+	return nil
+}
+
+// RecordPINFailure counts an incorrect PIN, locking the PIN if it was one
+// too many. An error means the failure couldn't be recorded, and the
+// caller should give up rather than let the PIN be tried again.
+func RecordPINFailure() error {
+	if PINLockoutStore == nil {
+		return nil
+	}
+	err := PINLockoutStore.UpdateLockout(func(l *PINLockout) {
+		l.Failures++
+		if l.Failures >= lockoutFreeAttempts {
+			l.Until = time.Now().Add(lockoutDelay(l.Failures))
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("recording incorrect PIN: %w", err)
+	}
+	return nil
+}
+
+// ResetPINLockout clears the count after a correct PIN. Failing to is no
+// reason to fail: the count just lingers until the next correct PIN.
+func ResetPINLockout() {
+	if PINLockoutStore == nil {
+		return
+	}
+	if l, err := PINLockoutStore.LoadLockout(); err == nil && l.Failures == 0 {
+		return
+	}
+	_ = PINLockoutStore.UpdateLockout(func(l *PINLockout) { *l = PINLockout{} })
+}
+
+// lockoutDelay is how long the PIN is locked after failures incorrect PINs
+// in a row.
+func lockoutDelay(failures int) time.Duration {
+	d := lockoutBase
+	for i := lockoutFreeAttempts; i < failures && d < lockoutMax; i++ {
+		d *= 2
+	}
+	return min(d, lockoutMax)
+}
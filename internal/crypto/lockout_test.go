@@ -0,0 +1,85 @@
+package crypto
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// memLockout is a LockoutStore in memory.
+type memLockout struct{ l PINLockout }
+
+func (m *memLockout) LoadLockout() (PINLockout, error) { return m.l, nil }
+
+func (m *memLockout) UpdateLockout(fn func(*PINLockout)) error {
+	fn(&m.l)
+	return nil
+}
+
+// withLockoutStore sets PINLockoutStore for a test.
+func withLockoutStore(t *testing.T) *memLockout {
+	t.Helper()
+	store := &memLockout{}
+	PINLockoutStore = store
+	t.Cleanup(func() { PINLockoutStore = nil })
+	return store
+}
+
+// TestLockoutDelay verifies that the lockout doubles with each failure
+// past the free ones, up to the maximum.
+func TestLockoutDelay(t *testing.T) {
+	tests := []struct {
+		failures int
+		want     time.Duration
+	}{
+		{lockoutFreeAttempts, 30 * time.Second},
+		{lockoutFreeAttempts + 1, time.Minute},
+		{lockoutFreeAttempts + 3, 4 * time.Minute},
+		{lockoutFreeAttempts + 7, lockoutMax},
+		{1000, lockoutMax},
+	}
+	for _, tt := range tests {
+		if got := lockoutDelay(tt.failures); got != tt.want {
+			t.Errorf("lockoutDelay(%d) = %v, want %v", tt.failures, got, tt.want)
+		}
+	}
+}
+
+// TestGetOrPromptKeyPair_Lockout verifies that incorrect PINs are counted
+// across calls, as across runs, that the PIN is refused while locked even
+// if correct, and that a correct PIN afterwards resets the count.
+func TestGetOrPromptKeyPair_Lockout(t *testing.T) {
+	store := withLockoutStore(t)
+	withPINSource(t, false, "", "654321")
+	t.Cleanup(ClearCachedKeyPair)
+
+	kp := testKeyPair(t)
+	verifier, err := CreateVerifier(kp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	const salt = "AAAAAAAAAAAAAAAAAAAAAA=="
+
+	for range lockoutFreeAttempts {
+		if _, err := GetOrPromptKeyPair(salt, verifier, DefaultKDFParams, DefaultPINPolicy); err == nil || errors.Is(err, ErrPINLocked) {
+			t.Fatalf("GetOrPromptKeyPair() error = %v, want an incorrect PIN", err)
+		}
+	}
+	if store.l.Failures != lockoutFreeAttempts || time.Until(store.l.Until) <= 0 {
+		t.Fatalf("lockout = %+v, want %d failures and locked", store.l, lockoutFreeAttempts)
+	}
+
+	t.Setenv(EnvPIN, "123456")
+	pinSource.loaded = false
+	if _, err := GetOrPromptKeyPair(salt, verifier, DefaultKDFParams, DefaultPINPolicy); !errors.Is(err, ErrPINLocked) {
+		t.Fatalf("GetOrPromptKeyPair() while locked error = %v, want ErrPINLocked", err)
+	}
+
+	store.l.Until = time.Now().Add(-time.Second)
+	if got, err := GetOrPromptKeyPair(salt, verifier, DefaultKDFParams, DefaultPINPolicy); err != nil || got.PublicKey != kp.PublicKey {
+		t.Fatalf("GetOrPromptKeyPair() after the lockout = %v, %v; want the key", got, err)
+	}
+	if store.l.Failures != 0 || !store.l.Until.IsZero() {
+		t.Errorf("lockout after a correct PIN = %+v, want it reset", store.l)
+	}
+}
@@ -0,0 +1,17 @@
+package crypto
+
+import "errors"
+
+// ErrManagedKey is returned for an account whose key is a managed master
+// key: the seed of its keypair, held by Sunday for accounts that opted out
+// of a PIN. How the server hands the key to clients isn't settled, so the
+// CLI can't unlock it yet.
+var ErrManagedKey = errors.New("this account's encryption key is managed by Sunday, which the CLI can't unlock yet; set a PIN on the dashboard to decrypt here")
+
+// UsesManagedKey reports whether an account's key is a managed master key
+// rather than derived from a PIN, from its encryption metadata: it has one,
+// and neither the public key nor the verifier of a PIN-derived key. An
+// account with both unlocks with its PIN, as if it had no managed key.
+func UsesManagedKey(managed, publicKey, verifier string) bool {
+	return managed != "" && publicKey == "" && verifier == ""
+}
@@ -0,0 +1,24 @@
+package crypto
+
+import "testing"
+
+// TestUsesManagedKey verifies that only an account without a PIN-derived
+// key is treated as having a managed master key.
+func TestUsesManagedKey(t *testing.T) {
+	tests := []struct {
+		name                         string
+		managed, publicKey, verifier string
+		want                         bool
+	}{
+		{"managed only", "bWFzdGVy", "", "", true},
+		{"PIN and managed", "bWFzdGVy", "cHVibGlj", "dmVyaWZpZXI=", false},
+		{"managed with verifier", "bWFzdGVy", "", "dmVyaWZpZXI=", false},
+		{"PIN only", "", "cHVibGlj", "dmVyaWZpZXI=", false},
+		{"not set up", "", "", "", false},
+	}
+	for _, tt := range tests {
+		if got := UsesManagedKey(tt.managed, tt.publicKey, tt.verifier); got != tt.want {
+			t.Errorf("%s: UsesManagedKey() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
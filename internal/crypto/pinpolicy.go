@@ -0,0 +1,95 @@
+package crypto
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"regexp"
+	"unicode/utf8"
+)
+
+// pinPattern matches exactly 6 ASCII digits.
+var pinPattern = regexp.MustCompile(`^\d{6}$`)
+
+// minPINPassphraseLength is the fewest characters a passphrase policy may
+// ask for, whatever the server says. Anything shorter is weaker than the
+// 6-digit PIN it replaces once letters are allowed.
+const minPINPassphraseLength = 8
+
+// PINPolicy says what the secret the key is derived from may be: a 6-digit
+// PIN, which is the zero value and what every account had before
+// passphrases, or a passphrase of at least MinLength characters.
+type PINPolicy struct {
+	Passphrase bool
+	MinLength  int
+}
+
+// DefaultPINPolicy is the policy for servers that don't advertise one.
+var DefaultPINPolicy = PINPolicy{}
+
+// NewPINPolicy builds a PINPolicy from server metadata. An empty kind means
+// the server doesn't advertise a policy, so DefaultPINPolicy is returned.
+func NewPINPolicy(kind string, minLength int) (PINPolicy, error) {
+	switch kind {
+	case "", "digits":
+		return DefaultPINPolicy, nil
+	case "passphrase":
+		return PINPolicy{Passphrase: true, MinLength: max(minLength, minPINPassphraseLength)}, nil
+	default:
+		return PINPolicy{}, fmt.Errorf("unsupported PIN policy %q", kind)
+	}
+}
+
+// Noun is what the policy calls the secret: "PIN" or "passphrase".
+func (p PINPolicy) Noun() string {
+	if p.Passphrase {
+		return "passphrase"
+	}
+	return "PIN"
+}
+
+// Requirement describes a secret the policy accepts, for prompts such as
+// "Choose a 6-digit PIN".
+func (p PINPolicy) Requirement() string {
+	if p.Passphrase {
+		return fmt.Sprintf("passphrase of at least %d characters", p.MinLength)
+	}
+	return "6-digit PIN"
+}
+
+// CheckNew reports whether pin may be chosen as the account's new secret.
+func (p PINPolicy) CheckNew(pin string) error {
+	if !p.Passphrase {
+		if err := p.check([]byte(pin)); err != nil {
+			return fmt.Errorf("the PIN %w", err)
+		}
+		return nil
+	}
+	if utf8.RuneCountInString(pin) < p.MinLength {
+		return fmt.Errorf("the passphrase must be at least %d characters", p.MinLength)
+	}
+	return nil
+}
+
+// normalize returns pin as the policy reads it: a 6-digit PIN with any
+// surrounding whitespace trimmed, as it can't contain any, but a
+// passphrase exactly as given, since its spaces may be part of it.
+func (p PINPolicy) normalize(pin []byte) []byte {
+	if p.Passphrase {
+		return pin
+	}
+	return bytes.TrimSpace(pin)
+}
+
+// check reports whether pin could unlock the account's key. It is looser
+// than CheckNew for passphrases, so that a PIN chosen under an earlier
+// policy, such as a 6-digit one, still unlocks the key.
+func (p PINPolicy) check(pin []byte) error {
+	switch {
+	case !p.Passphrase && !pinPattern.Match(pin):
+		return errors.New("must be exactly 6 digits")
+	case len(pin) == 0:
+		return errors.New("must not be empty")
+	}
+	return nil
+}
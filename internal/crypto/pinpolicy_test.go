@@ -0,0 +1,74 @@
+package crypto
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestNewPINPolicy verifies that server policies are parsed, that a
+// passphrase policy never asks for fewer than the minimum, and that
+// unknown kinds are rejected.
+func TestNewPINPolicy(t *testing.T) {
+	tests := []struct {
+		kind      string
+		minLength int
+		want      PINPolicy
+		wantErr   bool
+	}{
+		{kind: "", want: DefaultPINPolicy},
+		{kind: "digits", want: DefaultPINPolicy},
+		{kind: "passphrase", minLength: 14, want: PINPolicy{Passphrase: true, MinLength: 14}},
+		{kind: "passphrase", minLength: 4, want: PINPolicy{Passphrase: true, MinLength: minPINPassphraseLength}},
+		{kind: "pattern", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := NewPINPolicy(tt.kind, tt.minLength)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("NewPINPolicy(%q, %d) = %+v, %v; want %+v", tt.kind, tt.minLength, got, err, tt.want)
+		}
+	}
+}
+
+// TestPINPolicyCheckNew verifies which new PINs each policy accepts.
+func TestPINPolicyCheckNew(t *testing.T) {
+	passphrase := PINPolicy{Passphrase: true, MinLength: 10}
+	tests := []struct {
+		policy PINPolicy
+		pin    string
+		ok     bool
+	}{
+		{DefaultPINPolicy, "123456", true},
+		{DefaultPINPolicy, "12345", false},
+		{DefaultPINPolicy, "correct horse", false},
+		{passphrase, "correct horse", true},
+		{passphrase, "123456", false},
+		{passphrase, "éééééééééé", true},
+	}
+	for _, tt := range tests {
+		if err := tt.policy.CheckNew(tt.pin); (err == nil) != tt.ok {
+			t.Errorf("%+v.CheckNew(%q) = %v, want ok %v", tt.policy, tt.pin, err, tt.ok)
+		}
+	}
+}
+
+// TestPromptPIN_Passphrase verifies that under a passphrase policy any PIN
+// is read, including a 6-digit one chosen before the policy.
+func TestPromptPIN_Passphrase(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pin")
+	if err := os.WriteFile(path, []byte("correct horse battery\n123456\n12ab56\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	withPINSource(t, false, path, "")
+
+	policy := PINPolicy{Passphrase: true, MinLength: 12}
+	for _, want := range []string{"correct horse battery", "123456"} {
+		if pin, err := PromptPIN("Passphrase: ", policy); err != nil || pin != want {
+			t.Errorf("PromptPIN() = %q, %v; want %q", pin, err, want)
+		}
+	}
+	if _, err := PromptPIN("PIN: ", DefaultPINPolicy); err == nil || !strings.Contains(err.Error(), "must be exactly 6 digits") {
+		t.Errorf("PromptPIN() error = %v, want a malformed PIN error", err)
+	}
+}
@@ -0,0 +1,105 @@
+package crypto
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+)
+
+// EnvPIN names the environment variable holding the PIN for runs without
+// a terminal, such as cron jobs and agents.
+const EnvPIN = "SUNDAY_PIN"
+
+// Other sources of the PIN for runs without a terminal. The CLI sets
+// PINStdin for --pin-stdin and PINFile for --pin-file. Each line read from
+// them answers one prompt, in order, so a command that asks for several
+// PINs (such as pin change) takes one per line.
+var (
+	PINStdin bool
+	PINFile  string
+)
+
+// pinStdin is where --pin-stdin reads from. Tests replace it.
+var pinStdin io.Reader = os.Stdin
+
+// pinSource holds the PINs read from a non-interactive source.
+var pinSource struct {
+	sync.Mutex
+	loaded bool
+	name   string
+	lines  []string
+}
+
+// nonInteractivePIN returns the next PIN from --pin-stdin, --pin-file or
+// $SUNDAY_PIN, in that order, with the source's name. ok is false if none
+// is in use, and the PIN should be prompted for.
+func nonInteractivePIN() (pin, source string, ok bool, err error) {
+	pinSource.Lock()
+	defer pinSource.Unlock()
+
+	if !pinSource.loaded {
+		if err := loadPINSource(); err != nil {
+			return "", "", true, err
+		}
+		pinSource.loaded = true
+	}
+	switch {
+	case pinSource.name == "":
+		return "", "", false, nil
+	case pinSource.name == EnvPIN:
+		pin = pinSource.lines[0]
+	case len(pinSource.lines) == 0:
+		return "", pinSource.name, true, fmt.Errorf("no more PINs in %s", pinSource.name)
+	default:
+		pin, pinSource.lines = pinSource.lines[0], pinSource.lines[1:]
+	}
+	return pin, pinSource.name, true, nil
+}
+
+// loadPINSource reads the PINs from the source in use, warning on stderr
+// about the risks each carries. The caller holds pinSource.
+func loadPINSource() error {
+	switch {
+	case PINStdin:
+		lines, err := readPINLines(pinStdin)
+		if err != nil {
+			return fmt.Errorf("reading PIN from stdin: %w", err)
+		}
+		pinSource.name, pinSource.lines = "stdin", lines
+	case PINFile != "":
+		f, err := os.Open(PINFile)
+		if err != nil {
+			return fmt.Errorf("reading PIN file: %w", err)
+		}
+		defer f.Close()
+		if info, err := f.Stat(); err == nil && info.Mode().Perm()&0o077 != 0 {
+			fmt.Fprintf(os.Stderr, "Warning: PIN file %s can be read by other users; restrict it with chmod 600.\n", PINFile)
+		}
+		lines, err := readPINLines(f)
+		if err != nil {
+			return fmt.Errorf("reading PIN file: %w", err)
+		}
+		pinSource.name, pinSource.lines = PINFile, lines
+	case os.Getenv(EnvPIN) != "":
+		fmt.Fprintf(os.Stderr, "Warning: using the PIN in $%s. Environment variables can leak to other processes and logs; prefer --pin-file where you can.\n", EnvPIN)
+		pinSource.name, pinSource.lines = EnvPIN, []string{os.Getenv(EnvPIN)}
+	}
+	return nil
+}
+
+// readPINLines returns the non-blank lines of r. Only the line endings
+// are removed: whether the rest is trimmed is up to the PIN policy, as a
+// passphrase may begin or end with a space.
+func readPINLines(r io.Reader) ([]string, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		if line := strings.TrimSuffix(scanner.Text(), "\r"); strings.TrimSpace(line) != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines, scanner.Err()
+}
@@ -0,0 +1,107 @@
+package crypto
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// withPINSource resets the non-interactive PIN source for a test.
+func withPINSource(t *testing.T, stdin bool, file, env string) {
+	t.Helper()
+	t.Setenv(EnvPIN, env)
+	PINStdin, PINFile = stdin, file
+	t.Cleanup(func() {
+		PINStdin, PINFile = false, ""
+		pinSource.loaded, pinSource.name, pinSource.lines = false, "", nil
+		pinStdin = os.Stdin
+	})
+}
+
+// TestPromptPIN_Stdin verifies that --pin-stdin answers one prompt per
+// line, and fails once the lines run out.
+func TestPromptPIN_Stdin(t *testing.T) {
+	withPINSource(t, true, "", "999999")
+	pinStdin = strings.NewReader("123456\n\n654321\n")
+
+	for _, want := range []string{"123456", "654321"} {
+		if pin, err := PromptPIN("PIN: ", DefaultPINPolicy); err != nil || pin != want {
+			t.Errorf("PromptPIN() = %q, %v; want %s", pin, err, want)
+		}
+	}
+	if _, err := PromptPIN("PIN: ", DefaultPINPolicy); err == nil || !strings.Contains(err.Error(), "no more PINs in stdin") {
+		t.Errorf("PromptPIN() error = %v, want no more PINs", err)
+	}
+}
+
+// TestPromptPIN_File verifies that --pin-file is read, and that a
+// malformed PIN in it is rejected.
+func TestPromptPIN_File(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pin")
+	if err := os.WriteFile(path, []byte("123456\n12ab56\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	withPINSource(t, false, path, "")
+
+	if pin, err := PromptPIN("PIN: ", DefaultPINPolicy); err != nil || pin != "123456" {
+		t.Errorf("PromptPIN() = %q, %v; want 123456", pin, err)
+	}
+	if _, err := PromptPIN("PIN: ", DefaultPINPolicy); err == nil || !strings.Contains(err.Error(), "must be exactly 6 digits") {
+		t.Errorf("PromptPIN() error = %v, want a malformed PIN error", err)
+	}
+}
+
+// TestPromptPIN_Whitespace verifies that whitespace around a 6-digit PIN
+// is trimmed, but a passphrase is taken exactly as given, from a file and
+// from the environment alike.
+func TestPromptPIN_Whitespace(t *testing.T) {
+	passphrase := PINPolicy{Passphrase: true, MinLength: 8}
+	path := filepath.Join(t.TempDir(), "pin")
+	if err := os.WriteFile(path, []byte(" 123456 \r\n  correct horse \r\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	withPINSource(t, false, path, "")
+
+	if pin, err := PromptPIN("PIN: ", DefaultPINPolicy); err != nil || pin != "123456" {
+		t.Errorf("PromptPIN() = %q, %v; want 123456", pin, err)
+	}
+	if pin, err := PromptPIN("Passphrase: ", passphrase); err != nil || pin != "  correct horse " {
+		t.Errorf("PromptPIN(passphrase) = %q, %v; want %q", pin, err, "  correct horse ")
+	}
+
+	withPINSource(t, false, "", " battery staple ")
+	pinSource.loaded = false
+	if pin, err := PromptPIN("Passphrase: ", passphrase); err != nil || pin != " battery staple " {
+		t.Errorf("PromptPIN(passphrase) from %s = %q, %v; want %q", EnvPIN, pin, err, " battery staple ")
+	}
+}
+
+// TestGetOrPromptKeyPair_WrongPINFromEnv verifies that a wrong PIN from
+// $SUNDAY_PIN fails at once rather than being retried.
+func TestGetOrPromptKeyPair_WrongPINFromEnv(t *testing.T) {
+	withPINSource(t, false, "", "654321")
+	t.Cleanup(ClearCachedKeyPair)
+
+	salt := make([]byte, 16)
+	kp, err := DeriveKeyPair("123456", salt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	verifier, err := CreateVerifier(kp)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = GetOrPromptKeyPair("AAAAAAAAAAAAAAAAAAAAAA==", verifier, DefaultKDFParams, DefaultPINPolicy)
+	if err == nil || err.Error() != "incorrect PIN from "+EnvPIN {
+		t.Errorf("GetOrPromptKeyPair() error = %v, want incorrect PIN from %s", err, EnvPIN)
+	}
+
+	t.Setenv(EnvPIN, "123456")
+	pinSource.loaded = false
+	got, err := GetOrPromptKeyPair("AAAAAAAAAAAAAAAAAAAAAA==", verifier, DefaultKDFParams, DefaultPINPolicy)
+	if err != nil || got.PublicKey != kp.PublicKey {
+		t.Errorf("GetOrPromptKeyPair() = %v, %v; want the key", got, err)
+	}
+}
@@ -0,0 +1,277 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/ravi-technologies/sunday-cli/internal/biometric"
+	"golang.org/x/crypto/nacl/secretbox"
+)
+
+// KeyProtector guards the private key while it is stored, so that the
+// software mode (the key itself, unlocked by the PIN at login) and
+// hardware-backed modes can coexist. Each form of stored key is
+// recognisable, so a key wrapped one way can be unwrapped whatever the
+// current setting.
+type KeyProtector interface {
+	// Wrap returns priv in the form it is stored in.
+	Wrap(priv [32]byte) (string, error)
+
+	// Unwrap recovers the private key from what Wrap returned.
+	Unwrap(stored string) ([32]byte, error)
+}
+
+// Key protector names, for the crypto.key_protector setting.
+const (
+	// ProtectorSoftware stores the key as it is (base64), protected only
+	// by the config file's permissions. It is the default.
+	ProtectorSoftware = "software"
+
+	// ProtectorYubiKey wraps the key with a secret only a YubiKey's
+	// HMAC-SHA1 challenge-response slot can produce, so unwrapping it
+	// needs the key present (and touched, if the slot requires it).
+	ProtectorYubiKey = "yubikey"
+
+	// ProtectorTouchID keeps the key in a macOS Keychain item whose access
+	// control requires Touch ID to read it; only a reference to the item
+	// is stored. Unlike a prompt shown before using a stored key, this
+	// can't be skipped by editing the config file.
+	ProtectorTouchID = "touchid"
+)
+
+// DefaultYubiKeySlot is the YubiKey slot used unless configured otherwise.
+// Slot 1 usually holds the factory OTP credential.
+const DefaultYubiKeySlot = 2
+
+// NewKeyProtector returns the protector named by the crypto.key_protector
+// setting; slot is the YubiKey slot (0 means DefaultYubiKeySlot).
+func NewKeyProtector(name string, slot int) (KeyProtector, error) {
+	switch name {
+	case "", ProtectorSoftware:
+		return SoftwareProtector{}, nil
+	case ProtectorYubiKey:
+		if slot == 0 {
+			slot = DefaultYubiKeySlot
+		}
+		if slot != 1 && slot != 2 {
+			return nil, fmt.Errorf("invalid crypto.yubikey_slot %d: must be 1 or 2", slot)
+		}
+		return YubiKeyProtector{Slot: slot}, nil
+	case ProtectorTouchID:
+		return TouchIDProtector{}, nil
+	}
+	return nil, fmt.Errorf("invalid crypto.key_protector %q: must be %s, %s or %s", name, ProtectorSoftware, ProtectorYubiKey, ProtectorTouchID)
+}
+
+// ProtectorFor returns the protector that wrapped stored.
+func ProtectorFor(stored string) KeyProtector {
+	if slot, ok := yubiKeySlot(stored); ok {
+		return YubiKeyProtector{Slot: slot}
+	}
+	if strings.HasPrefix(stored, touchIDPrefix) {
+		return TouchIDProtector{}
+	}
+	return SoftwareProtector{}
+}
+
+// DiscardWrapped deletes whatever holds the key stored as stored, once it
+// is no longer needed: the Keychain item of a key wrapped by
+// TouchIDProtector. Other forms hold nothing outside the config file.
+func DiscardWrapped(stored string) error {
+	account, ok := strings.CutPrefix(stored, touchIDPrefix)
+	if !ok {
+		return nil
+	}
+	return touchIDDelete(account)
+}
+
+// SoftwareProtector stores the key as base64.
+type SoftwareProtector struct{}
+
+// Wrap implements KeyProtector.
+func (SoftwareProtector) Wrap(priv [32]byte) (string, error) {
+	return base64.StdEncoding.EncodeToString(priv[:]), nil
+}
+
+// Unwrap implements KeyProtector.
+func (SoftwareProtector) Unwrap(stored string) ([32]byte, error) {
+	var priv [32]byte
+	b, err := base64.StdEncoding.DecodeString(stored)
+	if err != nil {
+		return priv, fmt.Errorf("decoding private key: %w", err)
+	}
+	defer Wipe(b)
+	if len(b) != len(priv) {
+		return priv, fmt.Errorf("private key has invalid length %d, expected 32", len(b))
+	}
+	copy(priv[:], b)
+	return priv, nil
+}
+
+// yubiKeyPrefix starts a key wrapped by YubiKeyProtector, which is stored
+// as "yubikey:v1:<slot>:<base64 challenge>:<base64 nonce and box>".
+const yubiKeyPrefix = "yubikey:v1:"
+
+// YubiKeyProtector wraps the key with secretbox under the SHA-256 of the
+// YubiKey's HMAC-SHA1 response to a random challenge. The challenge is
+// stored with the wrapped key; the response never is.
+type YubiKeyProtector struct {
+	Slot int
+}
+
+// Wrap implements KeyProtector.
+func (p YubiKeyProtector) Wrap(priv [32]byte) (string, error) {
+	challenge := make([]byte, 32)
+	if _, err := rand.Read(challenge); err != nil {
+		return "", fmt.Errorf("generating challenge: %w", err)
+	}
+	key, err := p.key(challenge)
+	if err != nil {
+		return "", err
+	}
+	defer Wipe(key[:])
+	var nonce [24]byte
+	if _, err := rand.Read(nonce[:]); err != nil {
+		return "", fmt.Errorf("generating nonce: %w", err)
+	}
+	sealed := secretbox.Seal(nonce[:], priv[:], &nonce, &key)
+
+	return fmt.Sprintf("%s%d:%s:%s", yubiKeyPrefix, p.Slot,
+		base64.StdEncoding.EncodeToString(challenge), base64.StdEncoding.EncodeToString(sealed)), nil
+}
+
+// Unwrap implements KeyProtector.
+func (p YubiKeyProtector) Unwrap(stored string) ([32]byte, error) {
+	var priv [32]byte
+	parts := strings.Split(strings.TrimPrefix(stored, yubiKeyPrefix), ":")
+	if !strings.HasPrefix(stored, yubiKeyPrefix) || len(parts) != 3 {
+		return priv, errors.New("malformed YubiKey-wrapped private key")
+	}
+	challenge, err := base64.StdEncoding.DecodeString(parts[1])
+	if err != nil {
+		return priv, fmt.Errorf("decoding challenge: %w", err)
+	}
+	sealed, err := base64.StdEncoding.DecodeString(parts[2])
+	if err != nil || len(sealed) < 24 {
+		return priv, errors.New("malformed YubiKey-wrapped private key")
+	}
+
+	key, err := p.key(challenge)
+	if err != nil {
+		return priv, err
+	}
+	defer Wipe(key[:])
+	var nonce [24]byte
+	copy(nonce[:], sealed)
+	opened, ok := secretbox.Open(nil, sealed[24:], &nonce, &key)
+	defer Wipe(opened)
+	if !ok || len(opened) != len(priv) {
+		return priv, errors.New("unwrapping private key failed: is this the YubiKey it was wrapped with?")
+	}
+	copy(priv[:], opened)
+	return priv, nil
+}
+
+// key returns the wrapping key for challenge.
+func (p YubiKeyProtector) key(challenge []byte) ([32]byte, error) {
+	fmt.Fprintln(os.Stderr, "Touch your YubiKey if it flashes...")
+	response, err := challengeResponse(p.Slot, challenge)
+	if err != nil {
+		return [32]byte{}, err
+	}
+	defer Wipe(response)
+	return sha256.Sum256(response), nil
+}
+
+// yubiKeySlot returns the slot a YubiKey-wrapped key was wrapped with.
+func yubiKeySlot(stored string) (int, bool) {
+	rest, ok := strings.CutPrefix(stored, yubiKeyPrefix)
+	if !ok {
+		return 0, false
+	}
+	s, _, _ := strings.Cut(rest, ":")
+	slot, err := strconv.Atoi(s)
+	return slot, err == nil
+}
+
+// touchIDPrefix starts a key stored by TouchIDProtector, which is stored
+// as "touchid:v1:<Keychain account>".
+const touchIDPrefix = "touchid:v1:"
+
+// TouchIDProtector stores the key in a Keychain item that only Touch ID
+// can read, under a random account name. The key itself never reaches the
+// config file.
+type TouchIDProtector struct{}
+
+// Wrap implements KeyProtector.
+func (TouchIDProtector) Wrap(priv [32]byte) (string, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", fmt.Errorf("generating Keychain account: %w", err)
+	}
+	account := "private-key-" + hex.EncodeToString(id)
+	if err := touchIDStore(account, priv[:]); err != nil {
+		return "", fmt.Errorf("storing private key in the Keychain: %w", err)
+	}
+	return touchIDPrefix + account, nil
+}
+
+// Unwrap implements KeyProtector. It shows the Touch ID prompt.
+func (TouchIDProtector) Unwrap(stored string) ([32]byte, error) {
+	var priv [32]byte
+	account, ok := strings.CutPrefix(stored, touchIDPrefix)
+	if !ok || account == "" {
+		return priv, errors.New("malformed Touch ID-protected private key")
+	}
+	b, err := touchIDLoad(account, "unlock your encryption key")
+	if err != nil {
+		return priv, fmt.Errorf("Touch ID is required to unlock your encryption key: %w", err)
+	}
+	defer Wipe(b)
+	if len(b) != len(priv) {
+		return priv, fmt.Errorf("private key has invalid length %d, expected 32", len(b))
+	}
+	copy(priv[:], b)
+	return priv, nil
+}
+
+// The Keychain functions TouchIDProtector uses. Tests replace them.
+var (
+	touchIDStore  = biometric.StoreSecret
+	touchIDLoad   = biometric.LoadSecret
+	touchIDDelete = biometric.DeleteSecret
+)
+
+// challengeResponse sends challenge to the YubiKey's slot and returns its
+// HMAC-SHA1 response, using ykchalresp from the YubiKey personalization
+// tools. Tests replace it.
+var challengeResponse = func(slot int, challenge []byte) ([]byte, error) {
+	path, err := exec.LookPath("ykchalresp")
+	if err != nil {
+		return nil, errors.New("the yubikey key protector needs ykchalresp, from the YubiKey personalization tools (yubikey-personalization)")
+	}
+	var stderr bytes.Buffer
+	cmd := exec.Command(path, "-"+strconv.Itoa(slot), "-x", hex.EncodeToString(challenge))
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("YubiKey challenge-response failed: %s", msg)
+		}
+		return nil, fmt.Errorf("YubiKey challenge-response failed: %w", err)
+	}
+	response, err := hex.DecodeString(strings.TrimSpace(string(out)))
+	if err != nil {
+		return nil, fmt.Errorf("reading YubiKey response: %w", err)
+	}
+	return response, nil
+}
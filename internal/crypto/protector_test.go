@@ -0,0 +1,159 @@
+package crypto
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ravi-technologies/sunday-cli/internal/biometric"
+)
+
+// withYubiKey replaces the YubiKey with one answering challenges with an
+// HMAC-SHA1 under secret.
+func withYubiKey(t *testing.T, secret string) {
+	t.Helper()
+	orig := challengeResponse
+	challengeResponse = func(slot int, challenge []byte) ([]byte, error) {
+		mac := hmac.New(sha1.New, []byte(secret))
+		mac.Write(challenge)
+		return mac.Sum(nil), nil
+	}
+	t.Cleanup(func() { challengeResponse = orig })
+}
+
+// withKeychain replaces the Touch ID-protected Keychain with a map, which
+// it returns. If the prompt fails, reading from it fails like a cancelled
+// Touch ID prompt.
+func withKeychain(t *testing.T, promptFails *bool) map[string][]byte {
+	t.Helper()
+	items := map[string][]byte{}
+	origStore, origLoad, origDelete := touchIDStore, touchIDLoad, touchIDDelete
+	touchIDStore = func(account string, secret []byte) error {
+		items[account] = append([]byte(nil), secret...)
+		return nil
+	}
+	touchIDLoad = func(account, reason string) ([]byte, error) {
+		if promptFails != nil && *promptFails {
+			return nil, biometric.ErrFailed
+		}
+		b, ok := items[account]
+		if !ok {
+			return nil, biometric.ErrNotFound
+		}
+		return append([]byte(nil), b...), nil
+	}
+	touchIDDelete = func(account string) error {
+		delete(items, account)
+		return nil
+	}
+	t.Cleanup(func() { touchIDStore, touchIDLoad, touchIDDelete = origStore, origLoad, origDelete })
+	return items
+}
+
+// TestKeyProtector_RoundTrip verifies that each protector unwraps what it
+// wrapped, and that ProtectorFor recognises which wrapped a key.
+func TestKeyProtector_RoundTrip(t *testing.T) {
+	withYubiKey(t, "secret")
+	withKeychain(t, nil)
+	var priv [32]byte
+	copy(priv[:], "0123456789abcdef0123456789abcdef")
+
+	for _, p := range []KeyProtector{SoftwareProtector{}, YubiKeyProtector{Slot: 1}, TouchIDProtector{}} {
+		stored, err := p.Wrap(priv)
+		if err != nil {
+			t.Fatalf("%T.Wrap() error = %v", p, err)
+		}
+		if got := ProtectorFor(stored); got != p {
+			t.Errorf("ProtectorFor(%q) = %#v, want %#v", stored, got, p)
+		}
+		got, err := p.Unwrap(stored)
+		if err != nil || got != priv {
+			t.Errorf("%T.Unwrap() = %x, %v; want %x", p, got, err, priv)
+		}
+	}
+}
+
+// TestYubiKeyProtector_WrongKey verifies that a key wrapped with one
+// YubiKey can't be unwrapped with another.
+func TestYubiKeyProtector_WrongKey(t *testing.T) {
+	withYubiKey(t, "secret")
+	stored, err := YubiKeyProtector{Slot: 2}.Wrap([32]byte{1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	withYubiKey(t, "other secret")
+	if _, err := (YubiKeyProtector{Slot: 2}).Unwrap(stored); err == nil || !strings.Contains(err.Error(), "unwrapping private key failed") {
+		t.Errorf("Unwrap() error = %v, want an unwrapping error", err)
+	}
+	if _, err := (YubiKeyProtector{Slot: 2}).Unwrap("yubikey:v1:2:garbage"); err == nil {
+		t.Error("Unwrap() of a malformed key succeeded")
+	}
+}
+
+// TestTouchIDProtector verifies that only a reference to the Keychain item
+// is stored, that the key can't be unwrapped without passing Touch ID, and
+// that DiscardWrapped deletes the item.
+func TestTouchIDProtector(t *testing.T) {
+	promptFails := false
+	items := withKeychain(t, &promptFails)
+	priv := [32]byte{1, 2, 3}
+
+	stored, err := TouchIDProtector{}.Wrap(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(stored, base64.StdEncoding.EncodeToString(priv[:])) {
+		t.Errorf("Wrap() = %q, which holds the key itself", stored)
+	}
+	if len(items) != 1 {
+		t.Fatalf("Keychain holds %d items, want 1", len(items))
+	}
+
+	promptFails = true
+	if _, err := (TouchIDProtector{}).Unwrap(stored); !errors.Is(err, biometric.ErrFailed) {
+		t.Errorf("Unwrap() with a failed prompt error = %v, want ErrFailed", err)
+	}
+	promptFails = false
+
+	if err := DiscardWrapped(stored); err != nil {
+		t.Fatalf("DiscardWrapped() error = %v", err)
+	}
+	if len(items) != 0 {
+		t.Errorf("Keychain holds %d items after DiscardWrapped, want 0", len(items))
+	}
+	if _, err := (TouchIDProtector{}).Unwrap(stored); !errors.Is(err, biometric.ErrNotFound) {
+		t.Errorf("Unwrap() of a discarded key error = %v, want ErrNotFound", err)
+	}
+	if err := DiscardWrapped("c29mdHdhcmU="); err != nil {
+		t.Errorf("DiscardWrapped() of a software key error = %v", err)
+	}
+}
+
+// TestNewKeyProtector verifies the crypto.key_protector and
+// crypto.yubikey_slot settings are checked.
+func TestNewKeyProtector(t *testing.T) {
+	tests := []struct {
+		name    string
+		slot    int
+		want    KeyProtector
+		wantErr bool
+	}{
+		{"", 0, SoftwareProtector{}, false},
+		{ProtectorSoftware, 0, SoftwareProtector{}, false},
+		{ProtectorYubiKey, 0, YubiKeyProtector{Slot: DefaultYubiKeySlot}, false},
+		{ProtectorYubiKey, 1, YubiKeyProtector{Slot: 1}, false},
+		{ProtectorYubiKey, 3, nil, true},
+		{ProtectorTouchID, 0, TouchIDProtector{}, false},
+		{"tpm", 0, nil, true},
+	}
+	for _, tt := range tests {
+		got, err := NewKeyProtector(tt.name, tt.slot)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("NewKeyProtector(%q, %d) = %#v, %v; want %#v, error %v", tt.name, tt.slot, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,205 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/nacl/box"
+	"golang.org/x/crypto/nacl/secretbox"
+)
+
+// RecipientsPrefix is the prefix of a field encrypted to several public
+// keys by EncryptForRecipients, such as a shared password entry. It is the
+// wire format servers reporting the vault_sharing feature (and their
+// dashboards) agree to store and read; it must not be written to a server
+// that doesn't report it.
+//
+// After the prefix comes the standard, padded base64 of:
+//
+//	count (1 byte, 1 to 255)
+//	count × (recipient public key (32) || SealedBox of the content key (80))
+//	nonce (24) || secretbox of the plaintext under the content key
+//
+// The content key is 32 random bytes, fresh for every field; SealedBox is
+// crypto_box_seal (X25519-XSalsa20-Poly1305) as for "e2e::" fields, and
+// secretbox is crypto_secretbox_easy (XSalsa20-Poly1305). A public key
+// appears at most once. Each recipient finds their slot by public key and
+// opens the content key with their private key. An empty plaintext is
+// stored as an empty field, not encrypted.
+const RecipientsPrefix = "e2em::"
+
+// MaxRecipients is the most public keys a field can be encrypted to.
+const MaxRecipients = 255
+
+// recipientSlotLen is the length of one recipient's slot: their public key
+// and the content key sealed to it.
+const recipientSlotLen = 32 + box.AnonymousOverhead + 32
+
+// EncryptForRecipients encrypts plaintext so that any of publicKeys
+// (base64, as Encrypt takes) can decrypt it with DecryptField. Like
+// Encrypt, empty plaintext returns an empty string.
+func EncryptForRecipients(plaintext string, publicKeys []string) (string, error) {
+	if plaintext == "" {
+		return "", nil
+	}
+	switch {
+	case len(publicKeys) == 0:
+		return "", errors.New("no recipients to encrypt to")
+	case len(publicKeys) > MaxRecipients:
+		return "", fmt.Errorf("too many recipients: %d, the most is %d", len(publicKeys), MaxRecipients)
+	}
+
+	var key [32]byte
+	if _, err := rand.Read(key[:]); err != nil {
+		return "", fmt.Errorf("generating content key: %w", err)
+	}
+	defer Wipe(key[:])
+
+	data := []byte{byte(len(publicKeys))}
+	seen := make(map[[32]byte]bool, len(publicKeys))
+	for _, pubB64 := range publicKeys {
+		pub, err := decodePublicKey(pubB64)
+		if err != nil {
+			return "", err
+		}
+		if seen[pub] {
+			return "", errors.New("a recipient is listed twice")
+		}
+		seen[pub] = true
+		data = append(data, pub[:]...)
+		if data, err = box.SealAnonymous(data, key[:], &pub, rand.Reader); err != nil {
+			return "", fmt.Errorf("encrypting: %w", err)
+		}
+	}
+
+	var nonce [24]byte
+	if _, err := rand.Read(nonce[:]); err != nil {
+		return "", fmt.Errorf("generating nonce: %w", err)
+	}
+	data = append(data, nonce[:]...)
+	data = secretbox.Seal(data, []byte(plaintext), &nonce, &key)
+	return RecipientsPrefix + base64.StdEncoding.EncodeToString(data), nil
+}
+
+// Recipients returns the public keys (base64) a field from
+// EncryptForRecipients is encrypted to.
+func Recipients(value string) ([]string, error) {
+	slots, _, err := parseRecipients(value)
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]string, len(slots))
+	for i, slot := range slots {
+		keys[i] = base64.StdEncoding.EncodeToString(slot[:32])
+	}
+	return keys, nil
+}
+
+// decryptRecipients decrypts a field from EncryptForRecipients with kp.
+func decryptRecipients(value string, kp *KeyPair) ([]byte, error) {
+	slots, sealed, err := parseRecipients(value)
+	if err != nil {
+		return nil, err
+	}
+	for _, slot := range slots {
+		if !bytes.Equal(slot[:32], kp.PublicKey[:]) {
+			continue
+		}
+		k, err := Decrypt(slot[32:], kp)
+		if err != nil {
+			return nil, err
+		}
+		var key [32]byte
+		copy(key[:], k)
+		Wipe(k)
+		defer Wipe(key[:])
+
+		var nonce [24]byte
+		copy(nonce[:], sealed)
+		plaintext, ok := secretbox.Open(nil, sealed[24:], &nonce, &key)
+		if !ok {
+			return nil, errors.New("decryption failed: invalid ciphertext")
+		}
+		return plaintext, nil
+	}
+	return nil, errors.New("decryption failed: not encrypted to this key")
+}
+
+// rewrapRecipient returns value with from's slot given to to instead,
+// leaving the other recipients and the ciphertext as they are. changed is
+// false if to already has a slot.
+func rewrapRecipient(value string, from, to *KeyPair) (result string, changed bool, err error) {
+	slots, _, err := parseRecipients(value)
+	if err != nil {
+		return "", false, err
+	}
+	mine := -1
+	for i, slot := range slots {
+		switch {
+		case bytes.Equal(slot[:32], to.PublicKey[:]):
+			return value, false, nil
+		case bytes.Equal(slot[:32], from.PublicKey[:]):
+			mine = i
+		}
+	}
+	if mine < 0 {
+		return "", false, errors.New("decryption failed: not encrypted to this key")
+	}
+
+	key, err := Decrypt(slots[mine][32:], from)
+	if err != nil {
+		return "", false, err
+	}
+	defer Wipe(key)
+	slot := append([]byte(nil), to.PublicKey[:]...)
+	if slot, err = box.SealAnonymous(slot, key, &to.PublicKey, rand.Reader); err != nil {
+		return "", false, fmt.Errorf("encrypting: %w", err)
+	}
+
+	data, _ := base64.StdEncoding.DecodeString(strings.TrimPrefix(value, RecipientsPrefix))
+	copy(data[1+mine*recipientSlotLen:], slot)
+	return RecipientsPrefix + base64.StdEncoding.EncodeToString(data), true, nil
+}
+
+// parseRecipients splits a field from EncryptForRecipients into its
+// recipient slots and the sealed plaintext, nonce first.
+func parseRecipients(value string) (slots [][]byte, sealed []byte, err error) {
+	b64, ok := strings.CutPrefix(value, RecipientsPrefix)
+	if !ok {
+		return nil, nil, fmt.Errorf("value is not encrypted to recipients: it should start with %q", RecipientsPrefix)
+	}
+	data, err := base64.StdEncoding.DecodeString(b64)
+	if err != nil {
+		return nil, nil, fmt.Errorf("decoding base64 ciphertext: %w", err)
+	}
+	if len(data) < 1 {
+		return nil, nil, errors.New("malformed multi-recipient ciphertext")
+	}
+	n := int(data[0])
+	data = data[1:]
+	if n == 0 || len(data) < n*recipientSlotLen+24+secretbox.Overhead {
+		return nil, nil, errors.New("malformed multi-recipient ciphertext")
+	}
+	for i := range n {
+		slots = append(slots, data[i*recipientSlotLen:(i+1)*recipientSlotLen])
+	}
+	return slots, data[n*recipientSlotLen:], nil
+}
+
+// decodePublicKey decodes a base64 public key.
+func decodePublicKey(publicKeyB64 string) ([32]byte, error) {
+	var pub [32]byte
+	b, err := base64.StdEncoding.DecodeString(publicKeyB64)
+	if err != nil {
+		return pub, fmt.Errorf("decoding public key: %w", err)
+	}
+	if len(b) != 32 {
+		return pub, fmt.Errorf("public key has invalid length %d, expected 32", len(b))
+	}
+	copy(pub[:], b)
+	return pub, nil
+}
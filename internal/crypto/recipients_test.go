@@ -0,0 +1,88 @@
+package crypto
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"slices"
+	"testing"
+
+	"golang.org/x/crypto/nacl/box"
+)
+
+// randomKeyPair returns a fresh keypair, without Argon2's cost.
+func randomKeyPair(t *testing.T) *KeyPair {
+	t.Helper()
+	pub, priv, err := box.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &KeyPair{PublicKey: *pub, PrivateKey: *priv}
+}
+
+func publicKeyB64(kp *KeyPair) string {
+	return base64.StdEncoding.EncodeToString(kp.PublicKey[:])
+}
+
+// TestEncryptForRecipients verifies that every recipient can decrypt the
+// value, and nobody else can.
+func TestEncryptForRecipients(t *testing.T) {
+	alice, bob, eve := randomKeyPair(t), randomKeyPair(t), randomKeyPair(t)
+
+	value, err := EncryptForRecipients("hunter2", []string{publicKeyB64(alice), publicKeyB64(bob)})
+	if err != nil {
+		t.Fatalf("EncryptForRecipients() error = %v", err)
+	}
+	if !IsEncrypted(value) {
+		t.Errorf("IsEncrypted(%q) = false", value)
+	}
+	for _, kp := range []*KeyPair{alice, bob} {
+		if got, err := DecryptField(value, kp); err != nil || got != "hunter2" {
+			t.Errorf("DecryptField() = %q, %v; want hunter2", got, err)
+		}
+	}
+	if _, err := DecryptField(value, eve); err == nil {
+		t.Error("DecryptField() with a non-recipient's key succeeded")
+	}
+
+	got, err := Recipients(value)
+	if want := []string{publicKeyB64(alice), publicKeyB64(bob)}; err != nil || !slices.Equal(got, want) {
+		t.Errorf("Recipients() = %v, %v; want %v", got, err, want)
+	}
+
+	if v, err := EncryptForRecipients("", []string{publicKeyB64(alice)}); v != "" || err != nil {
+		t.Errorf("EncryptForRecipients(\"\") = %q, %v; want \"\"", v, err)
+	}
+	if _, err := EncryptForRecipients("x", nil); err == nil {
+		t.Error("EncryptForRecipients() with no recipients succeeded")
+	}
+	if _, err := EncryptForRecipients("x", []string{publicKeyB64(alice), publicKeyB64(alice)}); err == nil {
+		t.Error("EncryptForRecipients() with a duplicate recipient succeeded")
+	}
+}
+
+// TestReencryptField_Recipients verifies that re-encrypting a shared value
+// after a key change moves only the changed recipient's slot.
+func TestReencryptField_Recipients(t *testing.T) {
+	oldKP, newKP, bob := randomKeyPair(t), randomKeyPair(t), randomKeyPair(t)
+
+	value, err := EncryptForRecipients("hunter2", []string{publicKeyB64(oldKP), publicKeyB64(bob)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	moved, changed, err := ReencryptField(value, oldKP, newKP)
+	if err != nil || !changed {
+		t.Fatalf("ReencryptField() = _, %v, %v; want changed", changed, err)
+	}
+	for _, kp := range []*KeyPair{newKP, bob} {
+		if got, err := DecryptField(moved, kp); err != nil || got != "hunter2" {
+			t.Errorf("DecryptField() = %q, %v; want hunter2", got, err)
+		}
+	}
+	if _, err := DecryptField(moved, oldKP); err == nil {
+		t.Error("the old key still decrypts the re-encrypted value")
+	}
+
+	if again, changed, err := ReencryptField(moved, oldKP, newKP); err != nil || changed || again != moved {
+		t.Errorf("second ReencryptField() = _, %v, %v; want it unchanged", changed, err)
+	}
+}
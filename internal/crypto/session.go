@@ -4,15 +4,12 @@ import (
 	"encoding/base64"
 	"fmt"
 	"os"
-	"regexp"
-	"strings"
+	"time"
 
+	"github.com/briandowns/spinner"
 	"golang.org/x/term"
 )
 
-// pinPattern matches exactly 6 ASCII digits.
-var pinPattern = regexp.MustCompile(`^\d{6}$`)
-
 // maxPINAttempts is the number of times the user may re-enter their PIN
 // before the operation is aborted.
 const maxPINAttempts = 3
@@ -25,10 +22,16 @@ var cachedKeyPair *KeyPair
 //
 // saltB64 is the base64-encoded 16-byte salt from the server.
 // verifierB64 is the base64-encoded SealedBox ciphertext of "sunday-e2e-verify".
-func GetOrPromptKeyPair(saltB64, verifierB64 string) (*KeyPair, error) {
+// params are the Argon2id costs from the server metadata (see NewKDFParams),
+// and policy what the PIN may be (see NewPINPolicy). Incorrect PINs count
+// towards the lockout kept by PINLockoutStore.
+func GetOrPromptKeyPair(saltB64, verifierB64 string, params KDFParams, policy PINPolicy) (*KeyPair, error) {
 	if cachedKeyPair != nil {
 		return cachedKeyPair, nil
 	}
+	if err := params.Validate(); err != nil {
+		return nil, err
+	}
 
 	salt, err := base64.StdEncoding.DecodeString(saltB64)
 	if err != nil {
@@ -36,41 +39,113 @@ func GetOrPromptKeyPair(saltB64, verifierB64 string) (*KeyPair, error) {
 	}
 
 	for attempt := 1; attempt <= maxPINAttempts; attempt++ {
-		pin, err := PromptPIN("Enter your 6-digit encryption PIN: ")
+		if err := CheckPINLockout(); err != nil {
+			return nil, err
+		}
+		pin, source, err := readPIN(promptFor(policy), policy)
 		if err != nil {
 			return nil, err
 		}
 
-		kp, err := DeriveKeyPair(pin, salt)
+		kp, err := deriveWithProgress(pin, salt, params)
+		Wipe(pin)
 		if err != nil {
 			return nil, fmt.Errorf("deriving keypair: %w", err)
 		}
 
 		if Verify(kp, verifierB64) {
+			ResetPINLockout()
 			cachedKeyPair = kp
 			return kp, nil
 		}
+		if err := RecordPINFailure(); err != nil {
+			return nil, err
+		}
+		if source != "" {
+			// Asking again would get the same answer.
+			return nil, fmt.Errorf("incorrect %s from %s", policy.Noun(), source)
+		}
 
+		if err := CheckPINLockout(); err != nil {
+			return nil, err
+		}
 		remaining := maxPINAttempts - attempt
 		if remaining > 0 {
-			fmt.Fprintf(os.Stderr, "Incorrect PIN. %d attempt(s) remaining.\n", remaining)
+			fmt.Fprintf(os.Stderr, "Incorrect %s. %d attempt(s) remaining.\n", policy.Noun(), remaining)
 		}
 	}
 
-	return nil, fmt.Errorf("maximum PIN attempts exceeded")
+	return nil, fmt.Errorf("maximum %s attempts exceeded", policy.Noun())
 }
 
-// ClearCachedKeyPair discards the in-memory keypair (e.g. on logout).
+// promptFor is the prompt for the PIN that unlocks the key under policy.
+func promptFor(policy PINPolicy) string {
+	if policy.Passphrase {
+		return "Enter your encryption passphrase: "
+	}
+	return "Enter your 6-digit encryption PIN: "
+}
+
+// deriveWithProgress derives the keypair, showing a "Deriving key..."
+// spinner on stderr while Argon2 runs if stderr is a terminal.
+func deriveWithProgress(pin, salt []byte, params KDFParams) (*KeyPair, error) {
+	s := spinner.New(spinner.CharSets[9], 100*time.Millisecond, spinner.WithWriterFile(os.Stderr))
+	s.Suffix = " Deriving key..."
+	s.Start() // no-op unless stderr is a terminal
+	defer s.Stop()
+
+	return deriveKeyPair(pin, salt, params)
+}
+
+// ClearCachedKeyPair wipes and discards the in-memory keypair (e.g. on
+// logout), along with any plaintext cached by DecryptField.
 func ClearCachedKeyPair() {
+	cachedKeyPair.Wipe()
 	cachedKeyPair = nil
+	ClearDecryptCache()
+}
+
+// PromptPIN prompts the user for a PIN with hidden input, rejecting one
+// that policy rules out: anything but 6 digits by default, and an empty
+// passphrase otherwise. The prompt string is written to stderr so it
+// appears even when stdout is redirected. With --pin-stdin, --pin-file or
+// $SUNDAY_PIN the PIN is read from there instead, without prompting.
+func PromptPIN(prompt string, policy PINPolicy) (string, error) {
+	pin, _, err := readPIN(prompt, policy)
+	defer Wipe(pin)
+	return string(pin), err
 }
 
-// PromptPIN prompts the user for a 6-digit PIN with hidden input.
-// The prompt string is written to stderr so it appears even when stdout is
-// redirected.
-func PromptPIN(prompt string) (string, error) {
+// readPIN is PromptPIN, returning the PIN as bytes for the caller to wipe
+// and the name of the non-interactive source it came from, or "" if it
+// was typed at the terminal.
+func readPIN(prompt string, policy PINPolicy) (pin []byte, source string, err error) {
+	if s, source, ok, err := nonInteractivePIN(); ok {
+		if err != nil {
+			return nil, source, err
+		}
+		pin = policy.normalize([]byte(s))
+		if err := policy.check(pin); err != nil {
+			Wipe(pin)
+			return nil, source, fmt.Errorf("%s from %s %w", policy.Noun(), source, err)
+		}
+		return pin, source, nil
+	}
+	pin, err = promptTerminalPIN(prompt)
+	if err == nil {
+		pin = policy.normalize(pin)
+		if err = policy.check(pin); err != nil {
+			Wipe(pin)
+			return nil, "", fmt.Errorf("%s %w", policy.Noun(), err)
+		}
+	}
+	return pin, "", err
+}
+
+// promptTerminalPIN prompts for the PIN at the terminal.
+func promptTerminalPIN(prompt string) ([]byte, error) {
 	if !term.IsTerminal(int(os.Stdin.Fd())) {
-		return "", fmt.Errorf("PIN prompt requires an interactive terminal (stdin is not a TTY)")
+		return nil, fmt.Errorf("PIN prompt requires an interactive terminal (stdin is not a TTY); use --pin-stdin, --pin-file or %s to supply it", EnvPIN)
 	}
 
 	fmt.Fprint(os.Stderr, prompt)
@@ -80,13 +155,9 @@ func PromptPIN(prompt string) (string, error) {
 	// fresh line.
 	fmt.Fprintln(os.Stderr)
 	if err != nil {
-		return "", fmt.Errorf("reading PIN: %w", err)
-	}
-
-	pin := strings.TrimSpace(string(raw))
-	if !pinPattern.MatchString(pin) {
-		return "", fmt.Errorf("PIN must be exactly 6 digits")
+		Wipe(raw)
+		return nil, fmt.Errorf("reading PIN: %w", err)
 	}
 
-	return pin, nil
+	return raw, nil
 }
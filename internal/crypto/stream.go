@@ -0,0 +1,216 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"golang.org/x/crypto/nacl/box"
+	"golang.org/x/crypto/nacl/secretbox"
+)
+
+// A SealedBox must be opened whole, so large payloads such as attachments
+// use a chunked stream format instead, which can be decrypted a chunk at
+// a time:
+//
+//	"SES1"
+//	SealedBox(32-byte stream key || 16-byte nonce prefix)
+//	chunks: 4-byte big-endian length || secretbox(chunk)
+//
+// Each chunk's nonce is the prefix followed by its 8-byte big-endian
+// index, with the top bit set on the last chunk, so chunks can't be
+// reordered, dropped or appended to undetected. The high bit of the length
+// marks the last chunk too, so the reader knows to expect it.
+
+// StreamChunkSize is the most plaintext a stream chunk holds.
+const StreamChunkSize = 64 * 1024
+
+// streamMagic starts every encrypted stream.
+var streamMagic = []byte("SES1")
+
+const (
+	streamPrefixLen = 16
+	streamSecretLen = 32 + streamPrefixLen
+	streamHeaderLen = box.AnonymousOverhead + streamSecretLen
+	streamLastChunk = 1 << 31
+	streamLastIndex = 1 << 63
+)
+
+// ErrStreamTruncated is returned when an encrypted stream ends before its
+// last chunk.
+var ErrStreamTruncated = errors.New("encrypted stream is truncated")
+
+// streamNonce returns the nonce of chunk index.
+func streamNonce(prefix []byte, index uint64, last bool) *[24]byte {
+	var nonce [24]byte
+	copy(nonce[:], prefix)
+	if last {
+		index |= streamLastIndex
+	}
+	binary.BigEndian.PutUint64(nonce[streamPrefixLen:], index)
+	return &nonce
+}
+
+// streamWriter encrypts what is written to it as a stream.
+type streamWriter struct {
+	w      io.Writer
+	key    [32]byte
+	prefix []byte
+	index  uint64
+	buf    []byte
+	closed bool
+}
+
+// NewEncryptWriter returns a writer that encrypts to publicKey what is
+// written to it, writing the stream to w. Close must be called to write
+// the last chunk; it doesn't close w.
+func NewEncryptWriter(w io.Writer, publicKey *[32]byte) (io.WriteCloser, error) {
+	secret := make([]byte, streamSecretLen)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, fmt.Errorf("generating stream key: %w", err)
+	}
+	header, err := box.SealAnonymous(append([]byte(nil), streamMagic...), secret, publicKey, rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("sealing stream key: %w", err)
+	}
+	if _, err := w.Write(header); err != nil {
+		return nil, err
+	}
+
+	sw := &streamWriter{w: w, prefix: secret[32:], buf: make([]byte, 0, StreamChunkSize)}
+	copy(sw.key[:], secret)
+	Wipe(secret[:32])
+	return sw, nil
+}
+
+func (sw *streamWriter) Write(p []byte) (int, error) {
+	if sw.closed {
+		return 0, errors.New("write to closed encrypted stream")
+	}
+	n := 0
+	for len(p) > 0 {
+		// Only write a full chunk once more follows, so the last chunk
+		// is always written by Close.
+		if len(sw.buf) == StreamChunkSize {
+			if err := sw.writeChunk(false); err != nil {
+				return n, err
+			}
+		}
+		k := min(len(p), StreamChunkSize-len(sw.buf))
+		sw.buf = append(sw.buf, p[:k]...)
+		p = p[k:]
+		n += k
+	}
+	return n, nil
+}
+
+// Close writes the last chunk.
+func (sw *streamWriter) Close() error {
+	if sw.closed {
+		return nil
+	}
+	sw.closed = true
+	defer Wipe(sw.key[:])
+	return sw.writeChunk(true)
+}
+
+func (sw *streamWriter) writeChunk(last bool) error {
+	length := uint32(len(sw.buf) + secretbox.Overhead)
+	if last {
+		length |= streamLastChunk
+	}
+	chunk := binary.BigEndian.AppendUint32(nil, length)
+	chunk = secretbox.Seal(chunk, sw.buf, streamNonce(sw.prefix, sw.index, last), &sw.key)
+	sw.index++
+	sw.buf = sw.buf[:0]
+	_, err := sw.w.Write(chunk)
+	return err
+}
+
+// streamReader decrypts a stream a chunk at a time.
+type streamReader struct {
+	r      io.Reader
+	key    [32]byte
+	prefix []byte
+	index  uint64
+	buf    *bytes.Reader
+	done   bool
+	err    error
+}
+
+// NewDecryptReader returns a reader of the plaintext of the encrypted
+// stream read from r, which holds no more than a chunk in memory. The
+// stream's header is read, and its key unsealed, before it returns.
+// Reading fails with ErrStreamTruncated if the stream ends early.
+func NewDecryptReader(r io.Reader, kp *KeyPair) (io.Reader, error) {
+	header := make([]byte, len(streamMagic)+streamHeaderLen)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, fmt.Errorf("reading stream header: %w", err)
+	}
+	if !bytes.HasPrefix(header, streamMagic) {
+		return nil, errors.New("not an encrypted stream")
+	}
+	secret, err := Decrypt(header[len(streamMagic):], kp)
+	if err != nil {
+		return nil, err
+	}
+
+	sr := &streamReader{r: r, prefix: secret[32:], buf: bytes.NewReader(nil)}
+	copy(sr.key[:], secret)
+	Wipe(secret[:32])
+	return sr, nil
+}
+
+func (sr *streamReader) Read(p []byte) (int, error) {
+	for sr.buf.Len() == 0 {
+		if sr.err != nil {
+			return 0, sr.err
+		}
+		if sr.done {
+			return 0, io.EOF
+		}
+		sr.err = sr.readChunk()
+	}
+	return sr.buf.Read(p)
+}
+
+func (sr *streamReader) readChunk() error {
+	var lenBuf [4]byte
+	if _, err := io.ReadFull(sr.r, lenBuf[:]); err != nil {
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return ErrStreamTruncated
+		}
+		return err
+	}
+	length := binary.BigEndian.Uint32(lenBuf[:])
+	last := length&streamLastChunk != 0
+	length &^= streamLastChunk
+	if length < secretbox.Overhead || length > StreamChunkSize+secretbox.Overhead {
+		return fmt.Errorf("encrypted stream chunk has invalid length %d", length)
+	}
+
+	sealed := make([]byte, length)
+	if _, err := io.ReadFull(sr.r, sealed); err != nil {
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return ErrStreamTruncated
+		}
+		return err
+	}
+	plaintext, ok := secretbox.Open(nil, sealed, streamNonce(sr.prefix, sr.index, last), &sr.key)
+	if !ok {
+		return fmt.Errorf("decryption of stream chunk %d failed: invalid ciphertext", sr.index)
+	}
+	sr.index++
+	sr.buf.Reset(plaintext)
+	if last {
+		sr.done = true
+		Wipe(sr.key[:])
+		if n, _ := sr.r.Read(make([]byte, 1)); n > 0 {
+			return errors.New("encrypted stream has data after its last chunk")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"io"
+	"testing"
+)
+
+// encryptStream encrypts plaintext to kp as a stream.
+func encryptStream(t *testing.T, plaintext []byte, kp *KeyPair) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w, err := NewEncryptWriter(&buf, &kp.PublicKey)
+	if err != nil {
+		t.Fatalf("NewEncryptWriter() error = %v", err)
+	}
+	if _, err := w.Write(plaintext); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	return buf.Bytes()
+}
+
+// TestStream_RoundTrip verifies that streams of various sizes, around the
+// chunk size, decrypt to what was encrypted.
+func TestStream_RoundTrip(t *testing.T) {
+	kp := testKeyPair(t)
+	for _, size := range []int{0, 1, StreamChunkSize, StreamChunkSize + 1, 3*StreamChunkSize + 7} {
+		plaintext := make([]byte, size)
+		rand.Read(plaintext)
+
+		r, err := NewDecryptReader(bytes.NewReader(encryptStream(t, plaintext, kp)), kp)
+		if err != nil {
+			t.Fatalf("NewDecryptReader(%d bytes) error = %v", size, err)
+		}
+		got, err := io.ReadAll(r)
+		if err != nil || !bytes.Equal(got, plaintext) {
+			t.Errorf("decrypting %d bytes = %d bytes, %v", size, len(got), err)
+		}
+	}
+}
+
+// TestStream_Tampered verifies that truncated, altered and extended
+// streams, and the wrong key, are all rejected.
+func TestStream_Tampered(t *testing.T) {
+	kp := testKeyPair(t)
+	plaintext := make([]byte, 2*StreamChunkSize+10)
+	stream := encryptStream(t, plaintext, kp)
+	firstChunkEnd := len(streamMagic) + streamHeaderLen + 4 + StreamChunkSize + 16
+
+	decrypt := func(stream []byte, kp *KeyPair) error {
+		r, err := NewDecryptReader(bytes.NewReader(stream), kp)
+		if err != nil {
+			return err
+		}
+		_, err = io.ReadAll(r)
+		return err
+	}
+
+	if err := decrypt(stream[:firstChunkEnd], kp); !errors.Is(err, ErrStreamTruncated) {
+		t.Errorf("truncated stream error = %v, want ErrStreamTruncated", err)
+	}
+	altered := bytes.Clone(stream)
+	altered[firstChunkEnd-1] ^= 1
+	if err := decrypt(altered, kp); err == nil {
+		t.Error("altered stream decrypted")
+	}
+	if err := decrypt(append(bytes.Clone(stream), stream[len(stream)-30:]...), kp); err == nil {
+		t.Error("stream with data after its last chunk decrypted")
+	}
+	other, err := DeriveKeyPair("654321", make([]byte, 16))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := decrypt(stream, other); err == nil {
+		t.Error("stream decrypted with the wrong key")
+	}
+	if err := decrypt([]byte("not a stream at all, but long enough to hold a header of the right size for one................"), kp); err == nil {
+		t.Error("garbage decrypted")
+	}
+}
@@ -0,0 +1,18 @@
+package crypto
+
+// Wipe overwrites b with zeros, so that a secret doesn't linger in memory
+// once it has been used.
+func Wipe(b []byte) {
+	clear(b)
+}
+
+// Wipe zeroes the keypair, and the plaintexts DecryptField cached, which
+// may have been decrypted with it. It must not be used afterwards.
+func (kp *KeyPair) Wipe() {
+	if kp == nil {
+		return
+	}
+	ClearDecryptCache()
+	Wipe(kp.PrivateKey[:])
+	Wipe(kp.PublicKey[:])
+}
@@ -0,0 +1,126 @@
+// Package inbox normalizes email and SMS messages into a single entry type
+// so commands that merge several sources (such as `sunday inbox all`) can
+// sort and deduplicate them uniformly.
+//
+// The same message can be returned more than once when sources overlap,
+// for example a flat message listing and a thread listing, or two pages
+// fetched while new mail arrived. Merge removes those duplicates by each
+// message's stable server ID so counts and exports are not inflated.
+package inbox
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ravi-technologies/sunday-cli/internal/api"
+)
+
+// Entry kinds.
+const (
+	KindEmail = "email"
+	KindSMS   = "sms"
+)
+
+// previewLength is how many characters of an email body are kept as its
+// preview.
+const previewLength = 100
+
+// Entry is a single email or SMS message in a unified inbox view.
+type Entry struct {
+	Kind      string    `json:"type"`
+	ID        int       `json:"id"`
+	From      string    `json:"from"`
+	To        string    `json:"to"`
+	Subject   string    `json:"subject,omitempty"`
+	Preview   string    `json:"preview"`
+	Direction string    `json:"direction"`
+	IsRead    bool      `json:"is_read"`
+	ThreadID  string    `json:"thread_id,omitempty"`
+	CreatedDt time.Time `json:"created_dt"`
+}
+
+// Key returns the entry's stable identity: its kind plus server ID. Email
+// and SMS IDs come from separate sequences, so the kind is part of the key.
+func (e Entry) Key() string {
+	return e.Kind + ":" + strconv.Itoa(e.ID)
+}
+
+// FromEmail converts email messages to entries.
+func FromEmail(messages []api.SundayEmailMessage) []Entry {
+	entries := make([]Entry, len(messages))
+	for i, m := range messages {
+		entries[i] = Entry{
+			Kind:      KindEmail,
+			ID:        m.ID,
+			From:      m.FromEmail,
+			To:        m.ToEmail,
+			Subject:   m.Subject,
+			Preview:   preview(m.TextContent),
+			Direction: m.Direction,
+			IsRead:    m.IsRead,
+			ThreadID:  m.ThreadID,
+			CreatedDt: m.CreatedDt,
+		}
+	}
+	return entries
+}
+
+// FromSMS converts SMS messages to entries.
+func FromSMS(messages []api.SundayPhoneMessage) []Entry {
+	entries := make([]Entry, len(messages))
+	for i, m := range messages {
+		entries[i] = Entry{
+			Kind:      KindSMS,
+			ID:        m.ID,
+			From:      m.FromNumber,
+			To:        m.ToNumber,
+			Preview:   m.Body,
+			Direction: m.Direction,
+			IsRead:    m.IsRead,
+			CreatedDt: m.CreatedDt,
+		}
+	}
+	return entries
+}
+
+// Dedupe returns entries with later duplicates (by Key) removed, keeping
+// the original order.
+func Dedupe(entries []Entry) []Entry {
+	seen := make(map[string]bool, len(entries))
+	result := make([]Entry, 0, len(entries))
+	for _, e := range entries {
+		if seen[e.Key()] {
+			continue
+		}
+		seen[e.Key()] = true
+		result = append(result, e)
+	}
+	return result
+}
+
+// Merge combines sources into one deduplicated list, newest first. Entries
+// with the same timestamp are ordered by key so output is stable.
+func Merge(sources ...[]Entry) []Entry {
+	entries := Dedupe(slices.Concat(sources...))
+	slices.SortStableFunc(entries, func(a, b Entry) int {
+		if c := b.CreatedDt.Compare(a.CreatedDt); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Key(), b.Key())
+	})
+	return entries
+}
+
+// preview returns the first line of text, cut to previewLength runes.
+func preview(text string) string {
+	text = strings.TrimSpace(text)
+	if i := strings.IndexByte(text, '\n'); i >= 0 {
+		text = strings.TrimSpace(text[:i])
+	}
+	if r := []rune(text); len(r) > previewLength {
+		text = string(r[:previewLength])
+	}
+	return text
+}
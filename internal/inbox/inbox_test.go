@@ -0,0 +1,76 @@
+package inbox
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ravi-technologies/sunday-cli/internal/api"
+)
+
+func keys(entries []Entry) []string {
+	result := make([]string, len(entries))
+	for i, e := range entries {
+		result[i] = e.Key()
+	}
+	return result
+}
+
+// TestDedupe verifies that later duplicates are dropped and order is kept.
+func TestDedupe(t *testing.T) {
+	entries := []Entry{
+		{Kind: KindEmail, ID: 1, Preview: "first"},
+		{Kind: KindSMS, ID: 1},
+		{Kind: KindEmail, ID: 2},
+		{Kind: KindEmail, ID: 1, Preview: "second"},
+	}
+
+	got := Dedupe(entries)
+	want := []string{"email:1", "sms:1", "email:2"}
+	if !slices.Equal(keys(got), want) {
+		t.Fatalf("Dedupe() keys = %v, want %v", keys(got), want)
+	}
+	if got[0].Preview != "first" {
+		t.Errorf("Dedupe() kept %q, want the first occurrence", got[0].Preview)
+	}
+}
+
+// TestMerge verifies that overlapping sources are merged newest first
+// without duplicates.
+func TestMerge(t *testing.T) {
+	base := time.Date(2026, 1, 10, 12, 0, 0, 0, time.UTC)
+	email := FromEmail([]api.SundayEmailMessage{
+		{ID: 1, CreatedDt: base},
+		{ID: 2, CreatedDt: base.Add(2 * time.Hour)},
+	})
+	sms := FromSMS([]api.SundayPhoneMessage{
+		{ID: 1, CreatedDt: base.Add(time.Hour)},
+		{ID: 2, CreatedDt: base},
+	})
+	// A second listing that overlaps the first.
+	again := FromEmail([]api.SundayEmailMessage{{ID: 2, CreatedDt: base.Add(2 * time.Hour)}})
+
+	got := Merge(email, sms, again)
+	want := []string{"email:2", "sms:1", "email:1", "sms:2"}
+	if !slices.Equal(keys(got), want) {
+		t.Errorf("Merge() keys = %v, want %v", keys(got), want)
+	}
+}
+
+// TestFromEmail_Preview verifies that the preview is the first line of the
+// text body, truncated.
+func TestFromEmail_Preview(t *testing.T) {
+	long := strings.Repeat("x", 150)
+	entries := FromEmail([]api.SundayEmailMessage{
+		{TextContent: "\n  Hello there  \nSecond line"},
+		{TextContent: long},
+	})
+
+	if entries[0].Preview != "Hello there" {
+		t.Errorf("Preview = %q, want %q", entries[0].Preview, "Hello there")
+	}
+	if len(entries[1].Preview) != previewLength {
+		t.Errorf("len(Preview) = %d, want %d", len(entries[1].Preview), previewLength)
+	}
+}
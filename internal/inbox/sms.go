@@ -0,0 +1,125 @@
+package inbox
+
+import (
+	"slices"
+
+	"github.com/ravi-technologies/sunday-cli/internal/api"
+	"github.com/ravi-technologies/sunday-cli/internal/contacts"
+)
+
+// SMSThread is one logical SMS conversation with a counterpart. The server
+// groups SMS by (Sunday phone, counterpart number); a thread joins every
+// such conversation with the same counterpart, including someone who
+// texts from several numbers (per the contact book) or conversations that
+// span a change of Sunday phone.
+//
+// The embedded conversation is the thread's most recent one, with
+// MessageCount and UnreadCount summed over the whole thread, so the JSON
+// form is a superset of api.SMSConversation.
+type SMSThread struct {
+	api.SMSConversation
+	Contact         string   `json:"contact,omitempty"`
+	ConversationIDs []string `json:"conversation_ids"`
+}
+
+// SMSThreadMessage is a message in a merged SMS thread, labelled with the
+// numbers of the conversation it came from.
+type SMSThreadMessage struct {
+	api.SMSMessage
+	FromNumber  string `json:"from_number"`
+	SundayPhone string `json:"sunday_phone"`
+}
+
+// SMSThreadDetail is the full message history of an SMS thread, oldest
+// first. Its JSON form is a superset of api.SMSConversationDetail.
+type SMSThreadDetail struct {
+	ConversationID  string             `json:"conversation_id"`
+	FromNumber      string             `json:"from_number"`
+	SundayPhone     string             `json:"sunday_phone"`
+	Contact         string             `json:"contact,omitempty"`
+	ConversationIDs []string           `json:"conversation_ids"`
+	MessageCount    int                `json:"message_count"`
+	Messages        []SMSThreadMessage `json:"messages"`
+}
+
+// smsThreadKey identifies the thread a conversation belongs to: its
+// contact if the counterpart number is in book, otherwise the number.
+func smsThreadKey(c api.SMSConversation, book *contacts.Book) (key, contact string) {
+	if ct, ok := book.LookupPhone(c.FromNumber); ok {
+		return "contact:" + ct.Name, ct.Name
+	}
+	return "number:" + contacts.PhoneDigits(c.FromNumber), ""
+}
+
+// ThreadSMS groups conversations into threads by counterpart, newest
+// first. book may be empty, in which case conversations are grouped by
+// counterpart number alone.
+func ThreadSMS(conversations []api.SMSConversation, book *contacts.Book) []SMSThread {
+	var threads []SMSThread
+	index := map[string]int{}
+	for _, c := range conversations {
+		key, contact := smsThreadKey(c, book)
+		i, ok := index[key]
+		if !ok {
+			index[key] = len(threads)
+			threads = append(threads, SMSThread{SMSConversation: c, Contact: contact, ConversationIDs: []string{c.ConversationID}})
+			continue
+		}
+		t := &threads[i]
+		t.ConversationIDs = append(t.ConversationIDs, c.ConversationID)
+		messages, unread := t.MessageCount+c.MessageCount, t.UnreadCount+c.UnreadCount
+		if c.LatestMessageDt.After(t.LatestMessageDt) {
+			t.SMSConversation = c
+		}
+		t.MessageCount, t.UnreadCount = messages, unread
+	}
+
+	slices.SortStableFunc(threads, func(a, b SMSThread) int {
+		return b.LatestMessageDt.Compare(a.LatestMessageDt)
+	})
+	return threads
+}
+
+// FindThread returns the thread containing conversationID.
+func FindThread(conversations []api.SMSConversation, book *contacts.Book, conversationID string) (SMSThread, bool) {
+	for _, t := range ThreadSMS(conversations, book) {
+		if slices.Contains(t.ConversationIDs, conversationID) {
+			return t, true
+		}
+	}
+	return SMSThread{}, false
+}
+
+// MergeSMSDetails combines the conversations of one thread into a single
+// history. The first detail is the one the user asked for and supplies the
+// thread's ID and numbers. Messages are deduplicated by ID and sorted
+// oldest first.
+func MergeSMSDetails(contact string, details []*api.SMSConversationDetail) *SMSThreadDetail {
+	merged := &SMSThreadDetail{Contact: contact}
+	if len(details) > 0 {
+		merged.ConversationID = details[0].ConversationID
+		merged.FromNumber = details[0].FromNumber
+		merged.SundayPhone = details[0].SundayPhone
+	}
+
+	seen := map[int]bool{}
+	for _, d := range details {
+		merged.ConversationIDs = append(merged.ConversationIDs, d.ConversationID)
+		for _, m := range d.Messages {
+			if seen[m.ID] {
+				continue
+			}
+			seen[m.ID] = true
+			merged.Messages = append(merged.Messages, SMSThreadMessage{
+				SMSMessage:  m,
+				FromNumber:  d.FromNumber,
+				SundayPhone: d.SundayPhone,
+			})
+		}
+	}
+	slices.SortStableFunc(merged.Messages, func(a, b SMSThreadMessage) int {
+		return a.CreatedDt.Compare(b.CreatedDt)
+	})
+	merged.MessageCount = len(merged.Messages)
+	return merged
+}
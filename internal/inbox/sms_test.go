@@ -0,0 +1,83 @@
+package inbox
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/ravi-technologies/sunday-cli/internal/api"
+	"github.com/ravi-technologies/sunday-cli/internal/contacts"
+)
+
+// TestThreadSMS verifies that conversations are merged by contact and by
+// counterpart number across Sunday phones.
+func TestThreadSMS(t *testing.T) {
+	base := time.Date(2026, 1, 10, 12, 0, 0, 0, time.UTC)
+	conversations := []api.SMSConversation{
+		{ConversationID: "1_+15551111111", FromNumber: "+15551111111", MessageCount: 2, UnreadCount: 1, LatestMessageDt: base},
+		{ConversationID: "1_+15552222222", FromNumber: "+15552222222", MessageCount: 3, LatestMessageDt: base.Add(time.Hour)},
+		{ConversationID: "1_+15553333333", FromNumber: "+15553333333", MessageCount: 1, LatestMessageDt: base.Add(-time.Hour)},
+		// Same counterpart after a Sunday phone change.
+		{ConversationID: "2_+15553333333", FromNumber: "+15553333333", MessageCount: 4, UnreadCount: 2, LatestMessageDt: base.Add(2 * time.Hour)},
+	}
+	book := &contacts.Book{}
+	book.Add("Alice", "+15551111111", "+15552222222")
+
+	threads := ThreadSMS(conversations, book)
+	if len(threads) != 2 {
+		t.Fatalf("ThreadSMS() returned %d threads, want 2", len(threads))
+	}
+
+	// Newest first: the +15553333333 thread, then Alice.
+	number := threads[0]
+	if number.Contact != "" || number.ConversationID != "2_+15553333333" || number.MessageCount != 5 || number.UnreadCount != 2 {
+		t.Errorf("threads[0] = %+v", number)
+	}
+	if !slices.Equal(number.ConversationIDs, []string{"1_+15553333333", "2_+15553333333"}) {
+		t.Errorf("threads[0].ConversationIDs = %v", number.ConversationIDs)
+	}
+
+	alice := threads[1]
+	if alice.Contact != "Alice" || alice.ConversationID != "1_+15552222222" || alice.MessageCount != 5 || alice.UnreadCount != 1 {
+		t.Errorf("threads[1] = %+v", alice)
+	}
+
+	if thread, ok := FindThread(conversations, book, "1_+15551111111"); !ok || thread.Contact != "Alice" {
+		t.Errorf("FindThread() = %+v, %v, want Alice's thread", thread, ok)
+	}
+	if _, ok := FindThread(conversations, book, "9_+15550000000"); ok {
+		t.Error("FindThread() found a thread for an unknown conversation")
+	}
+}
+
+// TestMergeSMSDetails verifies that messages from several conversations
+// are combined oldest first and labelled with their numbers.
+func TestMergeSMSDetails(t *testing.T) {
+	base := time.Date(2026, 1, 10, 12, 0, 0, 0, time.UTC)
+	details := []*api.SMSConversationDetail{
+		{ConversationID: "1_+15551111111", FromNumber: "+15551111111", SundayPhone: "+15550000001", Messages: []api.SMSMessage{
+			{ID: 1, CreatedDt: base},
+			{ID: 3, CreatedDt: base.Add(2 * time.Hour)},
+		}},
+		{ConversationID: "1_+15552222222", FromNumber: "+15552222222", SundayPhone: "+15550000001", Messages: []api.SMSMessage{
+			{ID: 2, CreatedDt: base.Add(time.Hour)},
+			{ID: 1, CreatedDt: base}, // duplicate
+		}},
+	}
+
+	merged := MergeSMSDetails("Alice", details)
+	if merged.ConversationID != "1_+15551111111" || merged.Contact != "Alice" || merged.MessageCount != 3 {
+		t.Errorf("merged = %+v", merged)
+	}
+
+	var ids []int
+	for _, m := range merged.Messages {
+		ids = append(ids, m.ID)
+	}
+	if !slices.Equal(ids, []int{1, 2, 3}) {
+		t.Errorf("message IDs = %v, want [1 2 3]", ids)
+	}
+	if merged.Messages[1].FromNumber != "+15552222222" {
+		t.Errorf("Messages[1].FromNumber = %q, want +15552222222", merged.Messages[1].FromNumber)
+	}
+}
@@ -0,0 +1,31 @@
+// Package keyring stores small secrets in the operating system's secret
+// store: the macOS Keychain, the Windows Credential Manager, or a
+// libsecret-compatible service (GNOME Keyring, KWallet) on Linux and the
+// BSDs.
+//
+// No third-party libraries are used. macOS and Linux go through the
+// system's own command-line tools (security and secret-tool), Windows
+// calls advapi32 directly. Secrets are never passed on a command line.
+package keyring
+
+import "errors"
+
+var (
+	// ErrNotFound is returned when no secret is stored for the service
+	// and account.
+	ErrNotFound = errors.New("secret not found in keyring")
+
+	// ErrUnavailable is returned when the system has no usable secret
+	// store, e.g. a headless Linux machine without a session bus.
+	ErrUnavailable = errors.New("no system keyring available")
+)
+
+// Store is a secret store keyed by service and account.
+type Store interface {
+	Get(service, account string) (string, error)
+	Set(service, account, secret string) error
+	Delete(service, account string) error
+}
+
+// System is the operating system's secret store.
+var System Store = systemStore{}
@@ -0,0 +1,64 @@
+package keyring
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+const securityPath = "/usr/bin/security"
+
+// errSecItemNotFound is the exit status security(1) uses when the item
+// does not exist.
+const errSecItemNotFound = 44
+
+// systemStore uses the login Keychain through security(1).
+type systemStore struct{}
+
+func (systemStore) Get(service, account string) (string, error) {
+	out, err := exec.Command(securityPath, "find-generic-password", "-s", service, "-a", account, "-w").Output()
+	if err != nil {
+		return "", keychainError(err)
+	}
+	return strings.TrimSuffix(string(out), "\n"), nil
+}
+
+func (systemStore) Set(service, account, secret string) error {
+	// Run security in interactive mode and pass the command on stdin, with
+	// the secret hex-encoded (-X), so it never appears in the process list.
+	cmd := exec.Command(securityPath, "-i")
+	cmd.Stdin = strings.NewReader(fmt.Sprintf("add-generic-password -U -s %s -a %s -X %s\n",
+		quote(service), quote(account), hex.EncodeToString([]byte(secret))))
+	if out, err := cmd.CombinedOutput(); err != nil || len(out) > 0 {
+		if err == nil {
+			err = errors.New(strings.TrimSpace(string(out)))
+		}
+		return fmt.Errorf("writing to keychain: %w", err)
+	}
+	return nil
+}
+
+func (systemStore) Delete(service, account string) error {
+	if err := exec.Command(securityPath, "delete-generic-password", "-s", service, "-a", account).Run(); err != nil {
+		return keychainError(err)
+	}
+	return nil
+}
+
+func keychainError(err error) error {
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == errSecItemNotFound {
+		return ErrNotFound
+	}
+	if errors.Is(err, exec.ErrNotFound) {
+		return ErrUnavailable
+	}
+	return fmt.Errorf("keychain: %w", err)
+}
+
+// quote single-quotes s for security(1)'s interactive command parser.
+func quote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
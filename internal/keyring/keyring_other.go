@@ -0,0 +1,12 @@
+//go:build !darwin && !windows && !linux && !freebsd && !openbsd && !netbsd && !dragonfly
+
+package keyring
+
+// systemStore reports that no keyring is available on this platform.
+type systemStore struct{}
+
+func (systemStore) Get(service, account string) (string, error) { return "", ErrUnavailable }
+
+func (systemStore) Set(service, account, secret string) error { return ErrUnavailable }
+
+func (systemStore) Delete(service, account string) error { return ErrUnavailable }
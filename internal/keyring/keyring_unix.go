@@ -0,0 +1,75 @@
+//go:build linux || freebsd || openbsd || netbsd || dragonfly
+
+package keyring
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// lookPath is exec.LookPath, replaceable in tests.
+var lookPath = exec.LookPath
+
+// systemStore uses the Secret Service API through libsecret's secret-tool.
+type systemStore struct{}
+
+// secretTool returns the path to secret-tool, or ErrUnavailable if it is
+// missing or there is no session bus for it to talk to.
+func secretTool() (string, error) {
+	if os.Getenv("DBUS_SESSION_BUS_ADDRESS") == "" {
+		return "", ErrUnavailable
+	}
+	path, err := lookPath("secret-tool")
+	if err != nil {
+		return "", ErrUnavailable
+	}
+	return path, nil
+}
+
+func (systemStore) Get(service, account string) (string, error) {
+	tool, err := secretTool()
+	if err != nil {
+		return "", err
+	}
+	out, err := exec.Command(tool, "lookup", "service", service, "account", account).Output()
+	if err != nil {
+		// secret-tool exits 1 with no output when nothing matches.
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && len(out) == 0 && len(exitErr.Stderr) == 0 {
+			return "", ErrNotFound
+		}
+		return "", fmt.Errorf("secret-tool lookup: %w", err)
+	}
+	return string(out), nil
+}
+
+func (systemStore) Set(service, account, secret string) error {
+	tool, err := secretTool()
+	if err != nil {
+		return err
+	}
+	// secret-tool reads the secret from stdin when it isn't a terminal.
+	cmd := exec.Command(tool, "store", "--label="+service+" ("+account+")", "service", service, "account", account)
+	cmd.Stdin = strings.NewReader(secret)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("secret-tool store: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+func (systemStore) Delete(service, account string) error {
+	tool, err := secretTool()
+	if err != nil {
+		return err
+	}
+	if _, err := (systemStore{}).Get(service, account); err != nil {
+		return err
+	}
+	if out, err := exec.Command(tool, "clear", "service", service, "account", account).CombinedOutput(); err != nil {
+		return fmt.Errorf("secret-tool clear: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
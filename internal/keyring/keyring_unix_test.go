@@ -0,0 +1,25 @@
+//go:build linux || freebsd || openbsd || netbsd || dragonfly
+
+package keyring
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestSystemStore_Unavailable verifies that a missing session bus or
+// secret-tool binary is reported as ErrUnavailable.
+func TestSystemStore_Unavailable(t *testing.T) {
+	t.Setenv("DBUS_SESSION_BUS_ADDRESS", "")
+	if _, err := System.Get("svc", "acct"); !errors.Is(err, ErrUnavailable) {
+		t.Errorf("Get() without a session bus error = %v, want ErrUnavailable", err)
+	}
+
+	t.Setenv("DBUS_SESSION_BUS_ADDRESS", "unix:path=/nonexistent")
+	orig := lookPath
+	lookPath = func(string) (string, error) { return "", errors.New("not found") }
+	t.Cleanup(func() { lookPath = orig })
+	if err := System.Set("svc", "acct", "secret"); !errors.Is(err, ErrUnavailable) {
+		t.Errorf("Set() without secret-tool error = %v, want ErrUnavailable", err)
+	}
+}
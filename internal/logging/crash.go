@@ -0,0 +1,38 @@
+package logging
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/ravi-technologies/sunday-cli/internal/version"
+)
+
+// WriteCrashReport records a recovered panic, with the goroutine stack and
+// build information, in the log directory and returns the report's path.
+// args must already be sanitized; they are written verbatim.
+func WriteCrashReport(value any, stack []byte, args []string) (string, error) {
+	dir := filepath.Dir(Path())
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", fmt.Errorf("creating log directory: %w", err)
+	}
+
+	now := time.Now()
+	path := filepath.Join(dir, fmt.Sprintf("crash-%s-%d.txt", now.UTC().Format("20060102-150405"), os.Getpid()))
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Sunday CLI crash report\n\n")
+	fmt.Fprintf(&b, "Time:     %s\n", now.UTC().Format(time.RFC3339))
+	fmt.Fprintf(&b, "Version:  %s\n", version.Info())
+	fmt.Fprintf(&b, "Platform: %s/%s (%s)\n", runtime.GOOS, runtime.GOARCH, runtime.Version())
+	fmt.Fprintf(&b, "Args:     %s\n\n", strings.Join(args, " "))
+	fmt.Fprintf(&b, "panic: %v\n\n%s", value, stack)
+
+	if err := os.WriteFile(path, []byte(b.String()), 0600); err != nil {
+		return "", fmt.Errorf("writing crash report: %w", err)
+	}
+	return path, nil
+}
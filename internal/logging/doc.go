@@ -0,0 +1,15 @@
+// Package logging writes leveled, structured (JSON lines) logs to
+// ~/.sunday/logs/cli.log with size-based rotation.
+//
+// Logs exist for support: when a user reports an intermittent failure, the
+// log shows which commands ran, which API calls they made, and how those
+// calls ended. Request and response bodies and headers are never logged.
+//
+// Example:
+//
+//	closeLog, err := logging.Init(logging.LevelFromEnv())
+//	if err == nil {
+//	    defer closeLog()
+//	}
+//	slog.Info("command started", "command", "inbox email")
+package logging
@@ -0,0 +1,56 @@
+package logging
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ravi-technologies/sunday-cli/internal/config"
+)
+
+const (
+	logDirName  = "logs"
+	logFileName = "cli.log"
+
+	// MaxFileSize is the size at which the log file is rotated.
+	MaxFileSize = 5 << 20 // 5 MiB
+
+	// MaxBackups is the number of rotated files kept (cli.log.1 ... cli.log.N).
+	MaxBackups = 3
+)
+
+// EnvLevel sets the minimum level written to the log file: debug, info,
+// warn, or error. The default is info.
+const EnvLevel = "SUNDAY_LOG_LEVEL"
+
+// Path returns the path of the current log file.
+func Path() string {
+	return filepath.Join(config.BaseDir(), logDirName, logFileName)
+}
+
+// LevelFromEnv returns the level named by SUNDAY_LOG_LEVEL, or info.
+func LevelFromEnv() slog.Level {
+	switch strings.ToLower(os.Getenv(EnvLevel)) {
+	case "debug":
+		return slog.LevelDebug
+	case "warn", "warning":
+		return slog.LevelWarn
+	case "error":
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
+
+// Init opens the log file and installs a JSON logger writing to it at level
+// as the slog default. The returned function closes the file.
+func Init(level slog.Level) (func() error, error) {
+	w, err := openRotatingFile(Path(), MaxFileSize, MaxBackups)
+	if err != nil {
+		return nil, err
+	}
+	logger := slog.New(slog.NewJSONHandler(w, &slog.HandlerOptions{Level: level}))
+	slog.SetDefault(logger.With("pid", os.Getpid()))
+	return w.Close, nil
+}
@@ -0,0 +1,155 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ravi-technologies/sunday-cli/internal/config"
+)
+
+// withTempConfig points the config (and so the log) directory at a temp dir.
+func withTempConfig(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	config.SetLocation(dir)
+	t.Cleanup(func() { config.SetLocation("") })
+	return dir
+}
+
+// TestRotatingFile verifies size-based rotation and that old backups beyond
+// the limit are dropped.
+func TestRotatingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "cli.log")
+	w, err := openRotatingFile(path, 10, 2)
+	if err != nil {
+		t.Fatalf("openRotatingFile() error = %v", err)
+	}
+	defer w.Close()
+
+	for _, line := range []string{"first\n", "second\n", "third\n", "fourth\n"} {
+		if _, err := w.Write([]byte(line)); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+	}
+
+	for file, want := range map[string]string{
+		path:        "fourth\n",
+		path + ".1": "third\n",
+		path + ".2": "second\n",
+	} {
+		got, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatalf("ReadFile(%s) error = %v", file, err)
+		}
+		if string(got) != want {
+			t.Errorf("%s = %q, want %q", filepath.Base(file), got, want)
+		}
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Errorf("expected no third backup, stat error = %v", err)
+	}
+}
+
+// TestLastLines verifies tail line selection.
+func TestLastLines(t *testing.T) {
+	data := []byte("a\nb\nc\n")
+	tests := []struct {
+		n    int
+		want string
+	}{
+		{0, ""},
+		{1, "c\n"},
+		{2, "b\nc\n"},
+		{10, "a\nb\nc\n"},
+	}
+	for _, tt := range tests {
+		if got := string(lastLines(data, tt.n)); got != tt.want {
+			t.Errorf("lastLines(%d) = %q, want %q", tt.n, got, tt.want)
+		}
+	}
+}
+
+// TestInit_WritesJSONLines verifies that Init installs a leveled JSON logger
+// and that Tail reads it back.
+func TestInit_WritesJSONLines(t *testing.T) {
+	withTempConfig(t)
+	orig := slog.Default()
+	defer slog.SetDefault(orig)
+
+	closeLog, err := Init(slog.LevelInfo)
+	if err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+	slog.Debug("hidden")
+	slog.Info("visible", "command", "inbox email")
+	closeLog()
+
+	var buf bytes.Buffer
+	if err := Tail(&buf, 10); err != nil {
+		t.Fatalf("Tail() error = %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("log has %d lines, want 1 (debug filtered): %q", len(lines), buf.String())
+	}
+	var entry map[string]any
+	if err := json.Unmarshal([]byte(lines[0]), &entry); err != nil {
+		t.Fatalf("log line is not JSON: %v", err)
+	}
+	if entry["msg"] != "visible" || entry["command"] != "inbox email" || entry["level"] != "INFO" {
+		t.Errorf("unexpected log entry %v", entry)
+	}
+}
+
+// TestTransport_LogsWithoutSecrets verifies API calls are logged without
+// query strings or headers.
+func TestTransport_LogsWithoutSecrets(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	var buf bytes.Buffer
+	orig := slog.Default()
+	defer slog.SetDefault(orig)
+	slog.SetDefault(slog.New(slog.NewJSONHandler(&buf, nil)))
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL+"/api/vault/?token=secret", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	resp, err := Transport(nil).RoundTrip(req)
+	if err != nil {
+		t.Fatalf("RoundTrip() error = %v", err)
+	}
+	resp.Body.Close()
+
+	logged := buf.String()
+	if !strings.Contains(logged, `"status":502`) || !strings.Contains(logged, `"path":"/api/vault/"`) {
+		t.Errorf("log missing request details: %s", logged)
+	}
+	if strings.Contains(logged, "secret") {
+		t.Errorf("log leaked a secret: %s", logged)
+	}
+}
+
+// TestLevelFromEnv verifies SUNDAY_LOG_LEVEL parsing.
+func TestLevelFromEnv(t *testing.T) {
+	for value, want := range map[string]slog.Level{
+		"":      slog.LevelInfo,
+		"debug": slog.LevelDebug,
+		"WARN":  slog.LevelWarn,
+		"error": slog.LevelError,
+		"bogus": slog.LevelInfo,
+	} {
+		t.Setenv(EnvLevel, value)
+		if got := LevelFromEnv(); got != want {
+			t.Errorf("LevelFromEnv() with %q = %v, want %v", value, got, want)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// followInterval is how often Follow polls the log file for new lines.
+const followInterval = 500 * time.Millisecond
+
+// Tail writes the last n lines of the log file to w.
+func Tail(w io.Writer, n int) error {
+	data, err := os.ReadFile(Path())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("reading log file: %w", err)
+	}
+	_, err = w.Write(lastLines(data, n))
+	return err
+}
+
+// lastLines returns the final n newline-terminated lines of data.
+func lastLines(data []byte, n int) []byte {
+	if n <= 0 {
+		return nil
+	}
+	start := len(data)
+	if start > 0 && data[start-1] == '\n' {
+		start--
+	}
+	for ; n > 0; n-- {
+		idx := bytes.LastIndexByte(data[:start], '\n')
+		if idx < 0 {
+			return data
+		}
+		start = idx
+	}
+	return data[start+1:]
+}
+
+// Follow writes lines appended to the log file to w until ctx is cancelled,
+// starting from the current end of the file. It keeps following across
+// rotations.
+func Follow(ctx context.Context, w io.Writer) error {
+	var offset int64
+	if info, err := os.Stat(Path()); err == nil {
+		offset = info.Size()
+	}
+
+	ticker := time.NewTicker(followInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		info, err := os.Stat(Path())
+		if err != nil {
+			continue
+		}
+		if info.Size() < offset {
+			// The file was rotated; start again from the top.
+			offset = 0
+		}
+		if info.Size() == offset {
+			continue
+		}
+
+		f, err := os.Open(Path())
+		if err != nil {
+			continue
+		}
+		n, err := io.Copy(w, io.NewSectionReader(f, offset, info.Size()-offset))
+		f.Close()
+		offset += n
+		if err != nil {
+			return err
+		}
+	}
+}
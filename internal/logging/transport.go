@@ -0,0 +1,44 @@
+package logging
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// Transport wraps base so that every API call is logged with its method,
+// path, status, and duration. Headers, query strings, and bodies are left
+// out because they can carry credentials and decrypted content.
+func Transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &transport{base: base}
+}
+
+type transport struct {
+	base http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req)
+	elapsed := time.Since(start)
+
+	if err != nil {
+		slog.Warn("api request failed",
+			"method", req.Method, "path", req.URL.Path, "request_id", req.Header.Get("X-Request-ID"), "duration_ms", elapsed.Milliseconds(), "error", err)
+		return nil, err
+	}
+
+	level := slog.LevelDebug
+	if resp.StatusCode >= http.StatusInternalServerError {
+		level = slog.LevelWarn
+	} else if resp.StatusCode >= http.StatusBadRequest {
+		level = slog.LevelInfo
+	}
+	slog.Log(req.Context(), level, "api request",
+		"method", req.Method, "path", req.URL.Path, "request_id", req.Header.Get("X-Request-ID"), "status", resp.StatusCode, "duration_ms", elapsed.Milliseconds())
+	return resp, nil
+}
@@ -0,0 +1,39 @@
+//go:build windows
+
+package output
+
+import (
+	"os"
+
+	"github.com/fatih/color"
+	"golang.org/x/sys/windows"
+)
+
+// utf8CodePage is the Windows code page identifier for UTF-8.
+const utf8CodePage = 65001
+
+// init prepares the Windows console for our output: UTF-8 so em dashes and
+// box characters don't render as garbage, and virtual terminal processing on
+// both stdout and stderr so ANSI colors work in cmd.exe. If the console
+// can't interpret ANSI sequences, colors are disabled instead.
+func init() {
+	_ = windows.SetConsoleOutputCP(utf8CodePage)
+
+	for _, f := range []*os.File{os.Stdout, os.Stderr} {
+		if !enableVirtualTerminal(windows.Handle(f.Fd())) {
+			color.NoColor = true
+		}
+	}
+}
+
+// enableVirtualTerminal turns on ANSI escape processing for a console
+// handle. Handles that aren't consoles (pipes, files) report true since
+// they never interpret escapes themselves.
+func enableVirtualTerminal(h windows.Handle) bool {
+	var mode uint32
+	if err := windows.GetConsoleMode(h, &mode); err != nil {
+		return true
+	}
+	mode |= windows.ENABLE_PROCESSED_OUTPUT | windows.ENABLE_VIRTUAL_TERMINAL_PROCESSING
+	return windows.SetConsoleMode(h, mode) == nil
+}
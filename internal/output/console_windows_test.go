@@ -0,0 +1,37 @@
+//go:build windows
+
+package output
+
+import (
+	"os"
+	"testing"
+
+	"golang.org/x/sys/windows"
+)
+
+// TestEnableVirtualTerminal_NonConsole verifies that non-console handles
+// (such as the pipes used by `go test`) are treated as ANSI-safe.
+func TestEnableVirtualTerminal_NonConsole(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("os.Pipe() error = %v", err)
+	}
+	defer r.Close()
+	defer w.Close()
+
+	if !enableVirtualTerminal(windows.Handle(w.Fd())) {
+		t.Error("enableVirtualTerminal(pipe) = false, want true")
+	}
+}
+
+// TestConsoleOutputCP verifies that the console output code page is UTF-8
+// when attached to a console.
+func TestConsoleOutputCP(t *testing.T) {
+	cp, err := windows.GetConsoleOutputCP()
+	if err != nil {
+		t.Skipf("no console attached: %v", err)
+	}
+	if cp != utf8CodePage {
+		t.Errorf("console output code page = %d, want %d", cp, utf8CodePage)
+	}
+}
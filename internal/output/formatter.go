@@ -1,6 +1,8 @@
 // Package output provides formatters for CLI output in human-readable and JSON formats.
 package output
 
+import "errors"
+
 // Formatter defines the interface for outputting data in different formats.
 type Formatter interface {
 	// Print outputs data to stdout
@@ -11,6 +13,62 @@ type Formatter interface {
 	PrintMessage(msg string)
 	// PrintTable outputs tabular data with headers
 	PrintTable(headers []string, rows [][]string)
+	// PrintSections outputs tabular data split into titled sections
+	PrintSections(headers []string, sections []TableSection)
+}
+
+// TableSection is a titled group of table rows.
+type TableSection struct {
+	Title string     `json:"title"`
+	Rows  [][]string `json:"rows"`
+}
+
+// Hinter is implemented by errors that carry remediation guidance for the
+// user. Formatters print the hint alongside the error message.
+type Hinter interface {
+	Hint() string
+}
+
+// errorHint returns the remediation hint attached to err, if any.
+func errorHint(err error) string {
+	var h Hinter
+	if errors.As(err, &h) {
+		return h.Hint()
+	}
+	return ""
+}
+
+// RequestIdentifier is implemented by errors from a failed API request
+// that carry its request ID. The JSON formatter reports the ID in a field
+// of its own, for scripts filing support tickets.
+type RequestIdentifier interface {
+	RequestIdentifier() string
+}
+
+// errorRequestID returns the request ID attached to err, if any.
+func errorRequestID(err error) string {
+	var r RequestIdentifier
+	if errors.As(err, &r) {
+		return r.RequestIdentifier()
+	}
+	return ""
+}
+
+// ErrorDetailer is implemented by errors that carry structured details,
+// such as the status and body of a failed API request. The JSON formatter
+// reports them as an object under "details", so scripts needn't parse the
+// message.
+type ErrorDetailer interface {
+	ErrorDetails() any
+}
+
+// errorDetails returns the structured details attached to err, if any.
+func errorDetails(err error) any {
+	var d ErrorDetailer
+	if errors.As(err, &d) {
+		return d.ErrorDetails()
+	}
+	return nil
 }
 
 // Current is the global formatter, set based on --json flag.
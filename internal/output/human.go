@@ -6,8 +6,10 @@ import (
 	"reflect"
 	"strings"
 	"text/tabwriter"
+	"unicode/utf8"
 
 	"github.com/fatih/color"
+	"github.com/ravi-technologies/sunday-cli/internal/timing"
 )
 
 // HumanFormatter outputs data in human-readable format.
@@ -15,6 +17,8 @@ type HumanFormatter struct{}
 
 // Print outputs data with pretty formatting for structs.
 func (f *HumanFormatter) Print(data interface{}) error {
+	defer timing.Start("render")()
+
 	if data == nil {
 		return nil
 	}
@@ -110,6 +114,10 @@ func (f *HumanFormatter) printMap(v reflect.Value) {
 func (f *HumanFormatter) PrintError(err error) {
 	red := color.New(color.FgRed).SprintFunc()
 	fmt.Fprintln(os.Stderr, red("Error:"), err.Error())
+	if hint := errorHint(err); hint != "" {
+		yellow := color.New(color.FgYellow).SprintFunc()
+		fmt.Fprintln(os.Stderr, yellow("Hint:"), hint)
+	}
 }
 
 // PrintMessage outputs a simple message to stdout.
@@ -119,6 +127,8 @@ func (f *HumanFormatter) PrintMessage(msg string) {
 
 // PrintTable outputs tabular data with aligned columns.
 func (f *HumanFormatter) PrintTable(headers []string, rows [][]string) {
+	defer timing.Start("render")()
+
 	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
 
 	// Print headers
@@ -138,3 +148,51 @@ func (f *HumanFormatter) PrintTable(headers []string, rows [][]string) {
 
 	w.Flush()
 }
+
+// PrintSections outputs tabular data with a bold title above each section.
+// Columns are aligned across all sections.
+func (f *HumanFormatter) PrintSections(headers []string, sections []TableSection) {
+	defer timing.Start("render")()
+
+	widths := make([]int, len(headers))
+	measure := func(row []string) {
+		for i, cell := range row {
+			if i < len(widths) {
+				widths[i] = max(widths[i], utf8.RuneCountInString(cell))
+			}
+		}
+	}
+	measure(headers)
+	for _, s := range sections {
+		for _, row := range s.Rows {
+			measure(row)
+		}
+	}
+
+	printRow := func(row []string) {
+		var b strings.Builder
+		for i, cell := range row {
+			b.WriteString(cell)
+			if i < len(row)-1 {
+				b.WriteString(strings.Repeat(" ", widths[i]-utf8.RuneCountInString(cell)+2))
+			}
+		}
+		fmt.Println(b.String())
+	}
+
+	printRow(headers)
+	separators := make([]string, len(headers))
+	for i, h := range headers {
+		separators[i] = strings.Repeat("-", len(h))
+	}
+	printRow(separators)
+
+	bold := color.New(color.Bold).SprintFunc()
+	for _, s := range sections {
+		fmt.Println()
+		fmt.Println(bold(s.Title))
+		for _, row := range s.Rows {
+			printRow(row)
+		}
+	}
+}
@@ -7,6 +7,8 @@ import (
 	"os"
 	"strings"
 	"testing"
+
+	"github.com/fatih/color"
 )
 
 // captureStdout captures stdout output from a function.
@@ -136,6 +138,21 @@ func TestHumanFormatter_PrintError(t *testing.T) {
 	}
 }
 
+func TestHumanFormatter_PrintError_WithHint(t *testing.T) {
+	formatter := &HumanFormatter{}
+
+	output := captureStderr(func() {
+		formatter.PrintError(hintedTestError{})
+	})
+
+	if !strings.Contains(output, "session expired") {
+		t.Errorf("PrintError() should contain error message, got: %s", output)
+	}
+	if !strings.Contains(output, "Hint:") || !strings.Contains(output, "sunday auth login") {
+		t.Errorf("PrintError() should contain hint, got: %s", output)
+	}
+}
+
 func TestHumanFormatter_PrintMessage(t *testing.T) {
 	formatter := &HumanFormatter{}
 	msg := "Operation completed successfully"
@@ -333,3 +350,36 @@ func TestHumanFormatter_Print_NilPointer(t *testing.T) {
 		t.Errorf("Print(nil pointer) should produce no output, got: %q", output)
 	}
 }
+
+// TestHumanFormatter_PrintSections verifies that section titles are printed
+// and columns line up across sections.
+func TestHumanFormatter_PrintSections(t *testing.T) {
+	noColor := color.NoColor
+	color.NoColor = true
+	defer func() { color.NoColor = noColor }()
+
+	formatter := &HumanFormatter{}
+
+	headers := []string{"ID", "NAME", "DATE"}
+	sections := []TableSection{
+		{Title: "Today", Rows: [][]string{{"1", "Al", "Jan 02"}}},
+		{Title: "Yesterday", Rows: [][]string{{"22", "Bernadette", "Jan 01"}}},
+	}
+
+	output := captureStdout(func() {
+		formatter.PrintSections(headers, sections)
+	})
+
+	want := "" +
+		"ID  NAME        DATE\n" +
+		"--  ----        ----\n" +
+		"\n" +
+		"Today\n" +
+		"1   Al          Jan 02\n" +
+		"\n" +
+		"Yesterday\n" +
+		"22  Bernadette  Jan 01\n"
+	if output != want {
+		t.Errorf("PrintSections() output =\n%s\nwant\n%s", output, want)
+	}
+}
@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"log"
 	"os"
+
+	"github.com/ravi-technologies/sunday-cli/internal/timing"
 )
 
 // JSONFormatter outputs data in JSON format.
@@ -13,6 +15,8 @@ type JSONFormatter struct{}
 
 // Print marshals data to indented JSON and outputs to stdout.
 func (f *JSONFormatter) Print(data interface{}) error {
+	defer timing.Start("render")()
+
 	output, err := marshalJSON(data)
 	if err != nil {
 		return fmt.Errorf("failed to marshal JSON: %w", err)
@@ -23,9 +27,18 @@ func (f *JSONFormatter) Print(data interface{}) error {
 
 // PrintError outputs an error as JSON to stderr.
 func (f *JSONFormatter) PrintError(err error) {
-	output := map[string]string{
+	output := map[string]any{
 		"error": err.Error(),
 	}
+	if hint := errorHint(err); hint != "" {
+		output["hint"] = hint
+	}
+	if id := errorRequestID(err); id != "" {
+		output["request_id"] = id
+	}
+	if details := errorDetails(err); details != nil {
+		output["details"] = details
+	}
 	data, marshalErr := marshalJSON(output)
 	if marshalErr != nil {
 		log.Printf("failed to marshal error JSON: %v", marshalErr)
@@ -55,6 +68,8 @@ type TableOutput struct {
 
 // PrintTable outputs tabular data as JSON to stdout.
 func (f *JSONFormatter) PrintTable(headers []string, rows [][]string) {
+	defer timing.Start("render")()
+
 	output := TableOutput{
 		Headers: headers,
 		Rows:    rows,
@@ -67,6 +82,25 @@ func (f *JSONFormatter) PrintTable(headers []string, rows [][]string) {
 	fmt.Println(string(data))
 }
 
+// SectionedTableOutput represents the JSON structure for sectioned table
+// data.
+type SectionedTableOutput struct {
+	Headers  []string       `json:"headers"`
+	Sections []TableSection `json:"sections"`
+}
+
+// PrintSections outputs sectioned tabular data as JSON to stdout.
+func (f *JSONFormatter) PrintSections(headers []string, sections []TableSection) {
+	defer timing.Start("render")()
+
+	data, err := marshalJSON(SectionedTableOutput{Headers: headers, Sections: sections})
+	if err != nil {
+		log.Printf("failed to marshal table JSON: %v", err)
+		return
+	}
+	fmt.Println(string(data))
+}
+
 // marshalJSON encodes data as indented JSON without escaping HTML characters.
 // Go's json.Marshal escapes <, >, & as \u003c, \u003e, \u0026 by default,
 // which breaks round-tripping of values like email Message-ID thread IDs.
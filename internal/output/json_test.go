@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"os"
 	"strings"
@@ -164,6 +165,82 @@ func TestJSONFormatter_PrintError(t *testing.T) {
 	}
 }
 
+// hintedTestError is an error carrying a remediation hint.
+type hintedTestError struct{}
+
+func (hintedTestError) Error() string { return "session expired" }
+func (hintedTestError) Hint() string  { return "run `sunday auth login`" }
+
+func TestJSONFormatter_PrintError_WithHint(t *testing.T) {
+	formatter := &JSONFormatter{}
+
+	output := captureStderrJSON(func() {
+		formatter.PrintError(hintedTestError{})
+	})
+
+	var result map[string]string
+	if err := json.Unmarshal([]byte(strings.TrimSpace(output)), &result); err != nil {
+		t.Fatalf("Failed to unmarshal PrintError() output: %v", err)
+	}
+
+	if result["error"] != "session expired" {
+		t.Errorf("PrintError() error = %q, want %q", result["error"], "session expired")
+	}
+	if result["hint"] != "run `sunday auth login`" {
+		t.Errorf("PrintError() hint = %q, want %q", result["hint"], "run `sunday auth login`")
+	}
+}
+
+// requestIDTestError is an error from a request with an ID.
+type requestIDTestError struct{}
+
+func (requestIDTestError) Error() string             { return "API error: boom (request ID req-123)" }
+func (requestIDTestError) RequestIdentifier() string { return "req-123" }
+
+// TestJSONFormatter_PrintError_WithRequestID verifies that the request ID
+// of a failed API call gets a field of its own.
+func TestJSONFormatter_PrintError_WithRequestID(t *testing.T) {
+	formatter := &JSONFormatter{}
+
+	output := captureStderrJSON(func() {
+		formatter.PrintError(fmt.Errorf("listing threads: %w", requestIDTestError{}))
+	})
+
+	var result map[string]string
+	if err := json.Unmarshal([]byte(strings.TrimSpace(output)), &result); err != nil {
+		t.Fatalf("Failed to unmarshal PrintError() output: %v", err)
+	}
+	if result["request_id"] != "req-123" {
+		t.Errorf("PrintError() request_id = %q, want %q", result["request_id"], "req-123")
+	}
+}
+
+type detailedTestError struct{}
+
+func (detailedTestError) Error() string     { return "API error: boom" }
+func (detailedTestError) ErrorDetails() any { return map[string]int{"status": 500} }
+
+// TestJSONFormatter_PrintError_WithDetails verifies that structured error
+// details are reported as an object rather than flattened into the message.
+func TestJSONFormatter_PrintError_WithDetails(t *testing.T) {
+	formatter := &JSONFormatter{}
+
+	output := captureStderrJSON(func() {
+		formatter.PrintError(fmt.Errorf("listing threads: %w", detailedTestError{}))
+	})
+
+	var result struct {
+		Error   string         `json:"error"`
+		Details map[string]int `json:"details"`
+	}
+	if err := json.Unmarshal([]byte(strings.TrimSpace(output)), &result); err != nil {
+		t.Fatalf("Failed to unmarshal PrintError() output: %v", err)
+	}
+	if result.Error != "listing threads: API error: boom" || result.Details["status"] != 500 {
+		t.Errorf("PrintError() = %s, want the message and a details object", output)
+	}
+}
+
 func TestJSONFormatter_PrintMessage(t *testing.T) {
 	formatter := &JSONFormatter{}
 	msg := "Operation completed successfully"
@@ -329,3 +406,20 @@ func TestJSONFormatter_Print_Indented(t *testing.T) {
 		t.Errorf("Print() output should be indented with spaces, got: %s", output)
 	}
 }
+
+// TestJSONFormatter_PrintSections verifies that sections are output as JSON.
+func TestJSONFormatter_PrintSections(t *testing.T) {
+	formatter := &JSONFormatter{}
+
+	output := captureStdoutJSON(func() {
+		formatter.PrintSections([]string{"ID"}, []TableSection{{Title: "Today", Rows: [][]string{{"1"}}}})
+	})
+
+	var result SectionedTableOutput
+	if err := json.Unmarshal([]byte(output), &result); err != nil {
+		t.Fatalf("Failed to unmarshal PrintSections() output: %v", err)
+	}
+	if len(result.Sections) != 1 || result.Sections[0].Title != "Today" || result.Sections[0].Rows[0][0] != "1" {
+		t.Errorf("PrintSections() sections = %+v", result.Sections)
+	}
+}
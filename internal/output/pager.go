@@ -0,0 +1,134 @@
+package output
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// EnvPager selects the pager for long human output, overriding PAGER.
+// Set it to "builtin" to force the built-in pager.
+const EnvPager = "SUNDAY_PAGER"
+
+// builtinPager is the EnvPager value that selects the built-in pager.
+const builtinPager = "builtin"
+
+// lookPager is exec.LookPath, replaceable in tests.
+var lookPager = exec.LookPath
+
+// Page writes content to stdout. When stdout and stdin are terminals and
+// content is taller than the screen, it is shown through $SUNDAY_PAGER,
+// $PAGER, or less, falling back to a built-in "--More--" pager when none of
+// those is available.
+func Page(content []byte) error {
+	fd := int(os.Stdout.Fd())
+	_, height, err := term.GetSize(fd)
+	if err != nil || !term.IsTerminal(int(os.Stdin.Fd())) || bytes.Count(content, []byte("\n")) < height {
+		_, err := os.Stdout.Write(content)
+		return err
+	}
+
+	if argv := pagerCommand(); argv != nil {
+		cmd := exec.Command(argv[0], argv[1:]...)
+		cmd.Stdin = bytes.NewReader(content)
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		if os.Getenv("LESS") == "" {
+			// Quit if one screen, pass colors through, don't clear on exit.
+			cmd.Env = append(os.Environ(), "LESS=FRX")
+		}
+		if err := cmd.Run(); err == nil {
+			return nil
+		}
+		// Fall through to the built-in pager if the external one failed.
+	}
+
+	state, err := term.MakeRaw(int(os.Stdin.Fd()))
+	if err != nil {
+		_, err := os.Stdout.Write(content)
+		return err
+	}
+	defer term.Restore(int(os.Stdin.Fd()), state)
+	return runMore(splitLines(content), height, os.Stdin, os.Stdout)
+}
+
+// pagerCommand returns the external pager to run, or nil for the built-in
+// one.
+func pagerCommand() []string {
+	for _, env := range []string{EnvPager, "PAGER"} {
+		value := strings.TrimSpace(os.Getenv(env))
+		if value == "" {
+			continue
+		}
+		if value == builtinPager {
+			return nil
+		}
+		argv := strings.Fields(value)
+		if _, err := lookPager(argv[0]); err == nil {
+			return argv
+		}
+	}
+	if path, err := lookPager("less"); err == nil {
+		return []string{path}
+	}
+	return nil
+}
+
+func splitLines(content []byte) []string {
+	return strings.Split(strings.TrimSuffix(string(content), "\n"), "\n")
+}
+
+// runMore is the built-in pager. It shows a screen at a time and reads
+// single keys from in (which must be in raw mode):
+//
+//	space, f     next page
+//	enter, j     next line
+//	b            previous page
+//	q, Ctrl+C    quit
+//
+// Output uses \r\n because raw mode disables newline translation.
+func runMore(lines []string, height int, in io.Reader, out io.Writer) error {
+	page := max(height-1, 1)
+
+	printRange := func(from, to int) {
+		for _, line := range lines[from:min(to, len(lines))] {
+			fmt.Fprint(out, line, "\r\n")
+		}
+	}
+
+	printRange(0, page)
+	shown := min(page, len(lines)) // index just past the last line on screen
+	key := make([]byte, 1)
+	for shown < len(lines) {
+		fmt.Fprintf(out, "--More-- (%d%%)", shown*100/len(lines))
+		if _, err := in.Read(key); err != nil {
+			fmt.Fprint(out, "\r\x1b[K")
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		fmt.Fprint(out, "\r\x1b[K") // erase the prompt
+
+		switch key[0] {
+		case ' ', 'f':
+			printRange(shown, shown+page)
+			shown = min(shown+page, len(lines))
+		case '\r', '\n', 'j':
+			printRange(shown, shown+1)
+			shown++
+		case 'b':
+			start := max(shown-2*page, 0)
+			printRange(start, start+page)
+			shown = min(start+page, len(lines))
+		case 'q', 'Q', 3: // 3 is Ctrl+C in raw mode
+			return nil
+		}
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package output
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func numberedLines(n int) []string {
+	lines := make([]string, n)
+	for i := range lines {
+		lines[i] = fmt.Sprintf("line %d", i+1)
+	}
+	return lines
+}
+
+var morePrompt = regexp.MustCompile(`--More-- \(\d+%\)\r\x1b\[K`)
+
+// shownLines returns the content lines written by runMore, without prompts.
+func shownLines(out string) []string {
+	out = morePrompt.ReplaceAllString(out, "")
+	return strings.Split(strings.TrimSuffix(out, "\r\n"), "\r\n")
+}
+
+// TestRunMore_Navigation verifies paging forward, by line, and backward.
+func TestRunMore_Navigation(t *testing.T) {
+	var out strings.Builder
+	// height 4 gives 3-line pages: show 1-3, space 4-6, enter 7, b 2-4, q.
+	if err := runMore(numberedLines(10), 4, strings.NewReader(" \rbq"), &out); err != nil {
+		t.Fatalf("runMore() error = %v", err)
+	}
+
+	want := []string{
+		"line 1", "line 2", "line 3",
+		"line 4", "line 5", "line 6",
+		"line 7",
+		"line 2", "line 3", "line 4",
+	}
+	if got := shownLines(out.String()); !slices.Equal(got, want) {
+		t.Errorf("shown lines = %q, want %q", got, want)
+	}
+	if !strings.Contains(out.String(), "--More-- (30%)") {
+		t.Errorf("output missing progress prompt: %q", out.String())
+	}
+}
+
+// TestRunMore_EOF verifies that running out of input stops the pager
+// without an error.
+func TestRunMore_EOF(t *testing.T) {
+	var out strings.Builder
+	if err := runMore(numberedLines(10), 4, strings.NewReader(""), &out); err != nil {
+		t.Fatalf("runMore() error = %v", err)
+	}
+	if got := shownLines(out.String()); len(got) != 3 {
+		t.Errorf("shown lines = %q, want the first page only", got)
+	}
+}
+
+// TestRunMore_ToEnd verifies that paging past the end stops without a
+// trailing prompt.
+func TestRunMore_ToEnd(t *testing.T) {
+	var out strings.Builder
+	if err := runMore(numberedLines(5), 4, strings.NewReader("  "), &out); err != nil {
+		t.Fatalf("runMore() error = %v", err)
+	}
+	if got := shownLines(out.String()); !slices.Equal(got, numberedLines(5)) {
+		t.Errorf("shown lines = %q, want all lines", got)
+	}
+	if strings.HasSuffix(out.String(), "%)") {
+		t.Error("output ends with a prompt after the last page")
+	}
+}
+
+// TestPagerCommand verifies the pager lookup order.
+func TestPagerCommand(t *testing.T) {
+	orig := lookPager
+	t.Cleanup(func() { lookPager = orig })
+
+	installed := map[string]bool{"less": true, "most": true}
+	lookPager = func(name string) (string, error) {
+		if installed[name] {
+			return name, nil
+		}
+		return "", errors.New("not found")
+	}
+
+	tests := []struct {
+		name        string
+		sundayPager string
+		pager       string
+		lessMissing bool
+		want        []string
+	}{
+		{name: "default less", want: []string{"less"}},
+		{name: "PAGER", pager: "most -s", want: []string{"most", "-s"}},
+		{name: "SUNDAY_PAGER wins", sundayPager: "most", pager: "less", want: []string{"most"}},
+		{name: "builtin", sundayPager: "builtin", want: nil},
+		{name: "missing PAGER falls back", pager: "nope", want: []string{"less"}},
+		{name: "nothing installed", pager: "nope", lessMissing: true, want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(EnvPager, tt.sundayPager)
+			t.Setenv("PAGER", tt.pager)
+			installed["less"] = !tt.lessMissing
+			if got := pagerCommand(); !slices.Equal(got, tt.want) {
+				t.Errorf("pagerCommand() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
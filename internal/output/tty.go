@@ -0,0 +1,15 @@
+package output
+
+import (
+	"os"
+
+	"golang.org/x/term"
+)
+
+// IsTerminal reports whether f is connected to an interactive terminal.
+// Spinners and prompts should fall back to plain output when it isn't, so
+// logs captured from automation stay readable. Colors need no extra
+// handling: fatih/color already disables itself when stdout is redirected.
+func IsTerminal(f *os.File) bool {
+	return term.IsTerminal(int(f.Fd()))
+}
@@ -0,0 +1,175 @@
+// Package phone parses, validates, and formats phone numbers. Numbers are
+// exchanged with the API in E.164 form (+15551234567); the pretty forms
+// are for human output only.
+//
+// This is not a full numbering-plan library: country calling codes are
+// split correctly, national numbers are formatted in full for North America
+// (+1) and in simple groups elsewhere.
+package phone
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrInvalid is returned for input that is not a plausible phone number.
+var ErrInvalid = errors.New("invalid phone number")
+
+// E.164 numbers have at most 15 digits including the country code; the
+// shortest in practical use have 8.
+const (
+	minDigits = 8
+	maxDigits = 15
+)
+
+// Normalize converts number to E.164. Spaces, dashes, dots and
+// parentheses are ignored, and a leading "00" international prefix is
+// accepted in place of "+". Numbers without a country code are accepted
+// only in the North American 10-digit form, which is assumed to be +1.
+func Normalize(number string) (string, error) {
+	s := strings.TrimSpace(number)
+	international := strings.HasPrefix(s, "+")
+	if international {
+		s = s[1:]
+	}
+
+	var digits strings.Builder
+	for _, r := range s {
+		switch {
+		case r >= '0' && r <= '9':
+			digits.WriteRune(r)
+		case r == ' ' || r == '-' || r == '.' || r == '(' || r == ')':
+		default:
+			return "", fmt.Errorf("%w: %q", ErrInvalid, number)
+		}
+	}
+
+	d := digits.String()
+	switch {
+	case international:
+	case strings.HasPrefix(d, "00"):
+		d = d[2:]
+	case len(d) == 10 && d[0] >= '2':
+		d = "1" + d
+	case len(d) == 11 && d[0] == '1':
+	default:
+		return "", fmt.Errorf("%w: %q (include the country code, e.g. +44...)", ErrInvalid, number)
+	}
+
+	if len(d) < minDigits || len(d) > maxDigits || d[0] == '0' {
+		return "", fmt.Errorf("%w: %q", ErrInvalid, number)
+	}
+	if d[0] == '1' && len(d) != 11 {
+		return "", fmt.Errorf("%w: %q (North American numbers have 10 digits after +1)", ErrInvalid, number)
+	}
+	return "+" + d, nil
+}
+
+// Validate reports whether number is a valid destination.
+func Validate(number string) error {
+	_, err := Normalize(number)
+	return err
+}
+
+// twoDigitCodes are the two-digit country calling codes. "1" and "7" are
+// the only one-digit codes; every other code has three digits.
+var twoDigitCodes = map[string]bool{
+	"20": true, "27": true, "30": true, "31": true, "32": true, "33": true,
+	"34": true, "36": true, "39": true, "40": true, "41": true, "43": true,
+	"44": true, "45": true, "46": true, "47": true, "48": true, "49": true,
+	"51": true, "52": true, "53": true, "54": true, "55": true, "56": true,
+	"57": true, "58": true, "60": true, "61": true, "62": true, "63": true,
+	"64": true, "65": true, "66": true, "81": true, "82": true, "84": true,
+	"86": true, "90": true, "91": true, "92": true, "93": true, "94": true,
+	"95": true, "98": true,
+}
+
+// split returns the country calling code and national number of an E.164
+// number's digits.
+func split(digits string) (code, national string) {
+	switch {
+	case digits[0] == '1' || digits[0] == '7':
+		return digits[:1], digits[1:]
+	case twoDigitCodes[digits[:2]]:
+		return digits[:2], digits[2:]
+	}
+	return digits[:3], digits[3:]
+}
+
+// groupDigits splits a national number into groups of three, with a final
+// group of up to four.
+func groupDigits(national string) string {
+	var groups []string
+	for len(national) > 4 {
+		groups = append(groups, national[:3])
+		national = national[3:]
+	}
+	return strings.Join(append(groups, national), " ")
+}
+
+// FormatInternational returns number in readable international form, e.g.
+// "+1 555-123-4567" or "+44 207 946 0958". Numbers that can't be parsed are
+// returned unchanged.
+func FormatInternational(number string) string {
+	e164, err := Normalize(number)
+	if err != nil {
+		return number
+	}
+	code, national := split(e164[1:])
+	if code == "1" {
+		return "+1 " + national[:3] + "-" + national[3:6] + "-" + national[6:]
+	}
+	return "+" + code + " " + groupDigits(national)
+}
+
+// FormatNational returns number in readable national form, e.g.
+// "(555) 123-4567", or the international form for numbers outside North
+// America, whose national formats vary too much to render without a
+// numbering-plan database. Numbers that can't be parsed are returned
+// unchanged.
+func FormatNational(number string) string {
+	e164, err := Normalize(number)
+	if err != nil {
+		return number
+	}
+	code, national := split(e164[1:])
+	if code == "1" {
+		return "(" + national[:3] + ") " + national[3:6] + "-" + national[6:]
+	}
+	return FormatInternational(e164)
+}
+
+// Format returns number in national form if it shares home's country code,
+// and in international form otherwise. home is typically the user's Sunday
+// number and may be empty.
+func Format(number, home string) string {
+	n, err := Normalize(number)
+	if err != nil {
+		return number
+	}
+	if h, err := Normalize(home); err == nil {
+		nc, _ := split(n[1:])
+		hc, _ := split(h[1:])
+		if nc == hc {
+			return FormatNational(n)
+		}
+	}
+	return FormatInternational(n)
+}
+
+// NormalizeConversationID rewrites the number in an SMS conversation ID
+// ("{phone_id}_{from_number}") to E.164, so users can type IDs with
+// formatted numbers such as "1_(555) 123-4567". IDs that don't have that
+// shape, or whose number can't be parsed, are returned unchanged.
+func NormalizeConversationID(id string) string {
+	phoneID, number, ok := strings.Cut(id, "_")
+	if !ok || phoneID == "" {
+		return id
+	}
+	e164, err := Normalize(number)
+	if err != nil {
+		return id
+	}
+	return phoneID + "_" + e164
+}
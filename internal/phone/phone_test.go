@@ -0,0 +1,90 @@
+package phone
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestNormalize verifies conversion of common input forms to E.164.
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"+15551234567", "+15551234567"},
+		{"+1 (555) 123-4567", "+15551234567"},
+		{"555.123.4567", "+15551234567"},
+		{"1-555-123-4567", "+15551234567"},
+		{"0044 20 7946 0958", "+442079460958"},
+		{"+44 20 7946 0958", "+442079460958"},
+	}
+	for _, tt := range tests {
+		got, err := Normalize(tt.in)
+		if err != nil || got != tt.want {
+			t.Errorf("Normalize(%q) = %q, %v, want %q", tt.in, got, err, tt.want)
+		}
+	}
+}
+
+// TestNormalize_Invalid verifies that implausible numbers are rejected.
+func TestNormalize_Invalid(t *testing.T) {
+	for _, in := range []string{
+		"",
+		"hello",
+		"+1555123",           // too short
+		"+1234567890123456",  // too long
+		"+155512345678",      // NANP with 11 national digits
+		"020 7946 0958",      // no country code
+		"+0123456789",        // country codes don't start with 0
+		"+1 555 123 4567 x2", // extension
+	} {
+		if _, err := Normalize(in); !errors.Is(err, ErrInvalid) {
+			t.Errorf("Normalize(%q) error = %v, want ErrInvalid", in, err)
+		}
+	}
+	if err := Validate("+15551234567"); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+}
+
+// TestFormat verifies the national and international pretty forms.
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{"international NANP", FormatInternational("+15551234567"), "+1 555-123-4567"},
+		{"international UK", FormatInternational("+442079460958"), "+44 207 946 0958"},
+		{"international 3-digit code", FormatInternational("+353851234567"), "+353 851 234 567"},
+		{"national NANP", FormatNational("+15551234567"), "(555) 123-4567"},
+		{"national non-NANP", FormatNational("+442079460958"), "+44 207 946 0958"},
+		{"unparseable", FormatInternational("short"), "short"},
+		{"same country as home", Format("+15551234567", "+15559876543"), "(555) 123-4567"},
+		{"different country from home", Format("+15551234567", "+442079460958"), "+1 555-123-4567"},
+		{"no home", Format("+15551234567", ""), "+1 555-123-4567"},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, tt.got, tt.want)
+		}
+	}
+}
+
+// TestNormalizeConversationID verifies that the number part of a
+// conversation ID is rewritten to E.164.
+func TestNormalizeConversationID(t *testing.T) {
+	tests := map[string]string{
+		"1_+15551234567":      "1_+15551234567",
+		"1_(555) 123-4567":    "1_+15551234567",
+		"12_+44 20 7946 0958": "12_+442079460958",
+		"1_not-a-number":      "1_not-a-number",
+		"abc":                 "abc",
+		"_+15551234567":       "_+15551234567",
+	}
+	for in, want := range tests {
+		if got := NormalizeConversationID(in); got != want {
+			t.Errorf("NormalizeConversationID(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,181 @@
+package qr
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// TestReedSolomon verifies the error correction codewords against the
+// worked "HELLO WORLD" 1-M example from the QR code specification.
+func TestReedSolomon(t *testing.T) {
+	data := []byte{32, 91, 11, 120, 209, 114, 220, 77, 67, 64, 236, 17, 236, 17, 236, 17}
+	want := []byte{196, 35, 39, 119, 235, 215, 231, 226, 93, 23}
+	if got := reedSolomon(data, 10); !bytes.Equal(got, want) {
+		t.Errorf("reedSolomon() = %v, want %v", got, want)
+	}
+}
+
+// TestEncode_RoundTrip verifies that the data read back out of the symbol
+// matches the input for every supported version.
+func TestEncode_RoundTrip(t *testing.T) {
+	for _, n := range []int{1, 14, 26, 42, 62, 84, 106, 122, 152, 180, 213} {
+		text := strings.Repeat("https://sunday.example/device?user_code=ABCD-1234", 5)[:n]
+		c, err := Encode(text)
+		if err != nil {
+			t.Fatalf("Encode(%d bytes) error = %v", n, err)
+		}
+		version := (c.Size - 17) / 4
+		if got := decode(t, c, version); got != text {
+			t.Errorf("version %d: decoded %q, want %q", version, got, text)
+		}
+	}
+}
+
+// TestEncode_Version verifies that the smallest version is chosen.
+func TestEncode_Version(t *testing.T) {
+	tests := []struct {
+		n       int
+		version int
+	}{
+		{14, 1},
+		{15, 2},
+		{62, 4},
+		{213, 10},
+	}
+	for _, tt := range tests {
+		c, err := Encode(strings.Repeat("a", tt.n))
+		if err != nil {
+			t.Fatalf("Encode(%d bytes) error = %v", tt.n, err)
+		}
+		if got := (c.Size - 17) / 4; got != tt.version {
+			t.Errorf("Encode(%d bytes) version = %d, want %d", tt.n, got, tt.version)
+		}
+	}
+}
+
+// TestEncode_TooLong verifies that text beyond the largest version is
+// rejected.
+func TestEncode_TooLong(t *testing.T) {
+	if _, err := Encode(strings.Repeat("a", 214)); !errors.Is(err, ErrTooLong) {
+		t.Errorf("Encode(214 bytes) error = %v, want ErrTooLong", err)
+	}
+}
+
+// TestTerminal verifies the rendered size: two module rows per line plus
+// the quiet zone.
+func TestTerminal(t *testing.T) {
+	c, err := Encode("hello")
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	lines := strings.Split(strings.TrimSuffix(c.Terminal(), "\n"), "\n")
+	width := c.Size + 2*quietZone
+	if want := (width + 1) / 2; len(lines) != want {
+		t.Errorf("Terminal() has %d lines, want %d", len(lines), want)
+	}
+	for i, line := range lines {
+		if n := len([]rune(line)); n != width {
+			t.Errorf("line %d has %d columns, want %d", i, n, width)
+		}
+	}
+	// The top quiet zone is light, drawn as full blocks.
+	if !strings.HasPrefix(lines[0], strings.Repeat("█", width)) {
+		t.Errorf("first line = %q, want all light", lines[0])
+	}
+}
+
+// decode reads the data back out of c: it recovers the mask from the
+// format information, unmasks, reads the codewords in placement order,
+// de-interleaves the data codewords and parses the byte mode segment.
+func decode(t *testing.T, c *Code, version int) string {
+	t.Helper()
+
+	format := 0
+	for i := range 6 {
+		format |= bitOf(c.Dark(8, i)) << i
+	}
+	format |= bitOf(c.Dark(8, 7))<<6 | bitOf(c.Dark(8, 8))<<7 | bitOf(c.Dark(7, 8))<<8
+	for i := 9; i < 15; i++ {
+		format |= bitOf(c.Dark(14-i, 8)) << i
+	}
+	format ^= 0x5412
+	if ecl := format >> 13; ecl != eccFormatBits {
+		t.Fatalf("format error correction level = %d, want M", ecl)
+	}
+	mask := format >> 10 & 7
+
+	plain := newCode(version)
+	var raw bitBuffer
+	for right := c.Size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5
+		}
+		upward := (right+1)&2 == 0
+		for vert := range c.Size {
+			y := vert
+			if upward {
+				y = c.Size - 1 - vert
+			}
+			for j := range 2 {
+				x := right - j
+				if !plain.function[y][x] {
+					raw = append(raw, c.Dark(x, y) != maskBit(mask, x, y))
+				}
+			}
+		}
+	}
+	codewords := raw[:len(raw)/8*8].bytes()
+
+	spec := levelM[version]
+	nblocks := spec.count1 + spec.count2
+	blocks := make([][]byte, nblocks)
+	i := 0
+	for col := range spec.data1 + 1 {
+		for b := range nblocks {
+			if col < spec.data1 || b >= spec.count1 {
+				blocks[b] = append(blocks[b], codewords[i])
+				i++
+			}
+		}
+	}
+	for b, block := range blocks {
+		ecc := make([]byte, 0, spec.ecc)
+		for k := range spec.ecc {
+			ecc = append(ecc, codewords[spec.dataCodewords()+k*nblocks+b])
+		}
+		if !bytes.Equal(ecc, reedSolomon(block, spec.ecc)) {
+			t.Errorf("block %d error correction does not match", b)
+		}
+	}
+	data := bytes.Join(blocks, nil)
+
+	var bits bitBuffer
+	for _, b := range data {
+		bits.append(int(b), 8)
+	}
+	read := func(n int) int {
+		v := 0
+		for range n {
+			v = v<<1 | bitOf(bits[0])
+			bits = bits[1:]
+		}
+		return v
+	}
+	if mode := read(4); mode != 0b0100 {
+		t.Fatalf("mode = %04b, want byte mode", mode)
+	}
+	out := make([]byte, read(countBits(version)))
+	for k := range out {
+		out[k] = byte(read(8))
+	}
+	return string(out)
+}
+
+func bitOf(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
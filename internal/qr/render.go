@@ -0,0 +1,47 @@
+package qr
+
+import (
+	"io"
+	"strings"
+)
+
+// quietZone is the light border, in modules, around a rendered code.
+// The standard asks for 4; 2 scans reliably on screens and saves space.
+const quietZone = 2
+
+// Terminal renders the code with Unicode half blocks, two rows of modules
+// per line of text. Light modules are drawn in the foreground colour, so
+// the code reads correctly on the usual light-on-dark terminal.
+func (c *Code) Terminal() string {
+	var b strings.Builder
+	for y := -quietZone; y < c.Size+quietZone; y += 2 {
+		for x := -quietZone; x < c.Size+quietZone; x++ {
+			top, bottom := !c.Dark(x, y), !c.Dark(x, y+1)
+			if y+1 >= c.Size+quietZone {
+				bottom = false
+			}
+			switch {
+			case top && bottom:
+				b.WriteString("█")
+			case top:
+				b.WriteString("▀")
+			case bottom:
+				b.WriteString("▄")
+			default:
+				b.WriteString(" ")
+			}
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// WriteTerminal encodes text and writes it to w as rendered by Terminal.
+func WriteTerminal(w io.Writer, text string) error {
+	c, err := Encode(text)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, c.Terminal())
+	return err
+}
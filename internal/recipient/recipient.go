@@ -0,0 +1,110 @@
+// Package recipient checks email addresses before they are used as
+// recipients: syntax validation, and suggestions for likely typos in
+// common mail domains (gamil.com → gmail.com).
+package recipient
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+)
+
+// ErrInvalidEmail is returned for addresses that are not syntactically
+// valid.
+var ErrInvalidEmail = errors.New("invalid email address")
+
+// commonDomains are popular mail domains that typos are checked against.
+var commonDomains = []string{
+	"gmail.com", "googlemail.com", "yahoo.com", "hotmail.com", "outlook.com",
+	"live.com", "icloud.com", "me.com", "aol.com", "protonmail.com",
+	"proton.me", "gmx.com", "yandex.com", "mail.com", "email.com", "fastmail.com",
+}
+
+// knownTypos maps frequent misspellings that edit distance alone would
+// miss or get wrong.
+var knownTypos = map[string]string{
+	"gmail.co":   "gmail.com",
+	"gmail.cm":   "gmail.com",
+	"gmail.con":  "gmail.com",
+	"gmail.om":   "gmail.com",
+	"gmai.com":   "gmail.com",
+	"yahoo.co":   "yahoo.com",
+	"hotmail.co": "hotmail.com",
+	"outlook.co": "outlook.com",
+	"icloud.co":  "icloud.com",
+}
+
+// ValidateEmail reports whether address is a bare, syntactically valid
+// email address with a dotted domain.
+func ValidateEmail(address string) error {
+	parsed, err := mail.ParseAddress(address)
+	if err != nil || parsed.Address != address || parsed.Name != "" {
+		return fmt.Errorf("%w: %q", ErrInvalidEmail, address)
+	}
+	_, domain, _ := strings.Cut(address, "@")
+	if !strings.Contains(domain, ".") || strings.HasPrefix(domain, ".") || strings.HasSuffix(domain, ".") {
+		return fmt.Errorf("%w: %q", ErrInvalidEmail, address)
+	}
+	return nil
+}
+
+// Suggest returns a corrected address if address's domain looks like a
+// typo of a common mail domain.
+func Suggest(address string) (string, bool) {
+	local, domain, ok := strings.Cut(address, "@")
+	if !ok {
+		return "", false
+	}
+	domain = strings.ToLower(domain)
+
+	if fix, ok := knownTypos[domain]; ok {
+		return local + "@" + fix, true
+	}
+	for _, d := range commonDomains {
+		if domain == d {
+			return "", false
+		}
+	}
+	for _, d := range commonDomains {
+		if distance(domain, d) == 1 || isTransposition(domain, d) {
+			return local + "@" + d, true
+		}
+	}
+	return "", false
+}
+
+// isTransposition reports whether a and b differ only by two adjacent
+// characters being swapped (gmial.com vs gmail.com).
+func isTransposition(a, b string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := 0; i < len(a)-1; i++ {
+		if a[i] != b[i] {
+			return a[i] == b[i+1] && a[i+1] == b[i] && a[i+2:] == b[i+2:]
+		}
+	}
+	return false
+}
+
+// distance returns the Levenshtein edit distance between a and b.
+func distance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,50 @@
+package recipient
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestValidateEmail verifies syntax checks on recipient addresses.
+func TestValidateEmail(t *testing.T) {
+	valid := []string{"alice@example.com", "a.b+tag@sub.example.co.uk"}
+	for _, addr := range valid {
+		if err := ValidateEmail(addr); err != nil {
+			t.Errorf("ValidateEmail(%q) error = %v", addr, err)
+		}
+	}
+
+	invalid := []string{"", "alice", "alice@", "@example.com", "alice@localhost", "alice@example.", "Alice <alice@example.com>", "alice@@example.com"}
+	for _, addr := range invalid {
+		if err := ValidateEmail(addr); !errors.Is(err, ErrInvalidEmail) {
+			t.Errorf("ValidateEmail(%q) error = %v, want ErrInvalidEmail", addr, err)
+		}
+	}
+}
+
+// TestSuggest verifies typo suggestions for common mail domains.
+func TestSuggest(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"bob@gamil.com", "bob@gmail.com"},
+		{"bob@gmial.com", "bob@gmail.com"},
+		{"bob@gmail.con", "bob@gmail.com"},
+		{"bob@Hotmial.com", "bob@hotmail.com"},
+		{"bob@yahooo.com", "bob@yahoo.com"},
+		{"bob@outlok.com", "bob@outlook.com"},
+	}
+	for _, tt := range tests {
+		got, ok := Suggest(tt.in)
+		if !ok || got != tt.want {
+			t.Errorf("Suggest(%q) = %q, %v, want %q", tt.in, got, ok, tt.want)
+		}
+	}
+
+	for _, addr := range []string{"bob@gmail.com", "bob@example.com", "bob@company.io", "not-an-address"} {
+		if got, ok := Suggest(addr); ok {
+			t.Errorf("Suggest(%q) = %q, want no suggestion", addr, got)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+// Package timing records how long each phase of a command takes (API calls,
+// token refresh, key derivation, decryption, rendering) for the --timing
+// flag. Recording is a no-op until Enable is called.
+package timing
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+// phase accumulates the calls and total duration recorded under one name.
+type phase struct {
+	name  string
+	calls int
+	total time.Duration
+}
+
+var (
+	mu      sync.Mutex
+	enabled bool
+	started time.Time
+	phases  []*phase
+	byName  map[string]*phase
+)
+
+// Enable starts recording. The report's total is measured from this call.
+func Enable() {
+	mu.Lock()
+	defer mu.Unlock()
+	enabled = true
+	started = time.Now()
+	phases = nil
+	byName = map[string]*phase{}
+}
+
+// Start begins timing a phase and returns a function that ends it. Repeated
+// phases with the same name are summed. Usage:
+//
+//	defer timing.Start("decrypt")()
+func Start(name string) func() {
+	mu.Lock()
+	on := enabled
+	mu.Unlock()
+	if !on {
+		return func() {}
+	}
+
+	begin := time.Now()
+	return func() {
+		record(name, time.Since(begin))
+	}
+}
+
+func record(name string, d time.Duration) {
+	mu.Lock()
+	defer mu.Unlock()
+	if !enabled {
+		return
+	}
+	p, ok := byName[name]
+	if !ok {
+		p = &phase{name: name}
+		byName[name] = p
+		phases = append(phases, p)
+	}
+	p.calls++
+	p.total += d
+}
+
+// Report writes the recorded phases, in the order they first ran, followed
+// by the total elapsed time. It writes nothing if recording is disabled.
+func Report(w io.Writer) {
+	mu.Lock()
+	defer mu.Unlock()
+	if !enabled {
+		return
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Timing:")
+	for _, p := range phases {
+		fmt.Fprintf(tw, "  %s\t%dx\t%s\n", p.name, p.calls, round(p.total))
+	}
+	fmt.Fprintf(tw, "  total\t\t%s\n", round(time.Since(started)))
+	tw.Flush()
+}
+
+func round(d time.Duration) time.Duration {
+	if d < time.Millisecond {
+		return d.Round(time.Microsecond)
+	}
+	return d.Round(time.Millisecond)
+}
+
+// Transport wraps base so that each API call, including reading the
+// response body, is recorded as a phase named by its method and path.
+func Transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &transport{base: base}
+}
+
+type transport struct {
+	base http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	stop := Start("api " + req.Method + " " + req.URL.Path)
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		stop()
+		return nil, err
+	}
+	resp.Body = &timedBody{ReadCloser: resp.Body, stop: stop}
+	return resp, nil
+}
+
+// timedBody ends its phase when the response body is closed.
+type timedBody struct {
+	io.ReadCloser
+	stop func()
+	once sync.Once
+}
+
+func (b *timedBody) Close() error {
+	b.once.Do(b.stop)
+	return b.ReadCloser.Close()
+}
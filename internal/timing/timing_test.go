@@ -0,0 +1,90 @@
+package timing
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// reset disables recording after a test.
+func reset() {
+	mu.Lock()
+	defer mu.Unlock()
+	enabled = false
+	phases = nil
+	byName = nil
+}
+
+// TestStart_Disabled verifies that nothing is recorded or reported until
+// Enable is called.
+func TestStart_Disabled(t *testing.T) {
+	defer reset()
+
+	Start("decrypt")()
+	var buf bytes.Buffer
+	Report(&buf)
+	if buf.Len() != 0 {
+		t.Errorf("Report() while disabled wrote %q, want nothing", buf.String())
+	}
+}
+
+// TestReport verifies that repeated phases are summed and listed in the
+// order they first ran.
+func TestReport(t *testing.T) {
+	defer reset()
+	Enable()
+
+	Start("api GET /api/vault/")()
+	for i := 0; i < 3; i++ {
+		Start("decrypt")()
+	}
+	Start("render")()
+
+	var buf bytes.Buffer
+	Report(&buf)
+	out := buf.String()
+
+	api := strings.Index(out, "api GET /api/vault/")
+	decrypt := strings.Index(out, "decrypt")
+	render := strings.Index(out, "render")
+	if api < 0 || decrypt < api || render < decrypt {
+		t.Errorf("phases missing or out of order:\n%s", out)
+	}
+	if !strings.Contains(out, "3x") {
+		t.Errorf("decrypt calls not summed:\n%s", out)
+	}
+	if !strings.Contains(out, "total") {
+		t.Errorf("report has no total:\n%s", out)
+	}
+}
+
+// TestTransport verifies that an API call is recorded once its body is
+// closed.
+func TestTransport(t *testing.T) {
+	defer reset()
+	Enable()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[]`))
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: Transport(nil)}
+	resp, err := client.Get(server.URL + "/api/email-inbox/")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	io.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body.Close()
+
+	mu.Lock()
+	p := byName["api GET /api/email-inbox/"]
+	mu.Unlock()
+	if p == nil || p.calls != 1 {
+		t.Errorf("recorded phase = %+v, want one call", p)
+	}
+}
@@ -9,4 +9,5 @@
 //   - APIBaseURL: The backend API base URL (required)
 //   - GetAPIBaseURL(): Returns the API URL or error if not set
 //   - GetVersion(): Returns version or "dev" if not set
+//   - UserAgent(): Returns the User-Agent sent with API requests
 package version
@@ -3,6 +3,7 @@ package version
 import (
 	"errors"
 	"fmt"
+	"runtime"
 )
 
 // Build-time information injected via ldflags.
@@ -13,7 +14,7 @@ var (
 	Version    = "dev"
 	Commit     = "unknown"
 	BuildDate  = "unknown"
-	APIBaseURL = "" // Required, no default - must be set at build time
+	APIBaseURL = "" // The default API; SUNDAY_API_URL or api.base_url can override it at runtime
 )
 
 // Info returns formatted version information for display.
@@ -29,3 +30,13 @@ func GetAPIBaseURL() (string, error) {
 	}
 	return APIBaseURL, nil
 }
+
+// UserAgent returns the User-Agent sent with API requests, such as
+// "sunday-cli/1.0.0 (darwin/arm64)".
+func UserAgent() string {
+	v := Version
+	if v == "" {
+		v = "dev"
+	}
+	return fmt.Sprintf("sunday-cli/%s (%s/%s)", v, runtime.GOOS, runtime.GOARCH)
+}
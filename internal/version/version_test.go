@@ -1,6 +1,7 @@
 package version
 
 import (
+	"runtime"
 	"strings"
 	"testing"
 )
@@ -125,6 +126,24 @@ func TestInfo_Complete(t *testing.T) {
 	}
 }
 
+// TestUserAgent verifies that UserAgent names the CLI, its version and
+// the platform, falling back to "dev" when no version was injected.
+func TestUserAgent(t *testing.T) {
+	original := Version
+	defer func() { Version = original }()
+
+	Version = "1.2.3"
+	want := "sunday-cli/1.2.3 (" + runtime.GOOS + "/" + runtime.GOARCH + ")"
+	if got := UserAgent(); got != want {
+		t.Errorf("UserAgent() = %v, want %v", got, want)
+	}
+
+	Version = ""
+	if got := UserAgent(); !strings.HasPrefix(got, "sunday-cli/dev (") {
+		t.Errorf("UserAgent() = %v, want the dev version", got)
+	}
+}
+
 // GetVersion returns the current version string.
 // If Version is empty, it returns "dev" as a fallback.
 // This helper function is needed because the package only exposes Info(),
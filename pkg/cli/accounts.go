@@ -0,0 +1,79 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/ravi-technologies/sunday-cli/internal/config"
+	"github.com/ravi-technologies/sunday-cli/internal/output"
+	"github.com/spf13/cobra"
+)
+
+// accountName is the --account flag.
+var accountName string
+
+var accountsCmd = &cobra.Command{
+	Use:   "accounts",
+	Short: "List the accounts logged in to this profile",
+	Long: `List the accounts logged in to this profile.
+
+Besides the main account, a profile can stay logged in to other Sunday
+accounts at once. Log in to one with "sunday auth login --account <name>",
+then pick it for any command with --account <name> (or SUNDAY_ACCOUNT).
+"sunday auth logout --account <name>" logs out of just that account.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		names, err := config.ListAccounts()
+		if err != nil {
+			return err
+		}
+		active := config.ActiveAccount()
+
+		if jsonOutput {
+			return output.Current.Print(map[string]interface{}{"accounts": names, "active": active})
+		}
+
+		if len(names) == 0 {
+			output.Current.PrintMessage("No named accounts; only the main account is in use")
+			return nil
+		}
+		for _, name := range names {
+			marker := " "
+			if name == active {
+				marker = "*"
+			}
+			fmt.Printf("%s %s\n", marker, name)
+		}
+		return nil
+	},
+}
+
+// selectAccount applies the --account flag and checks that the selected
+// account is logged in, so a typo doesn't silently act as a logged-out
+// account. "auth login" may name an account that doesn't exist yet.
+func selectAccount(cmd *cobra.Command) error {
+	if accountName != "" {
+		config.SetAccount(accountName)
+	}
+	name := config.ActiveAccount()
+	if name == "" {
+		return nil
+	}
+	if err := config.ValidateAccountName(name); err != nil {
+		return err
+	}
+	if cmd == loginCmd || cmd == accountsCmd {
+		return nil
+	}
+	exists, err := config.AccountExists(name)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf("account %q is not logged in (log in with: sunday auth login --account %s)", name, name)
+	}
+	return nil
+}
+
+func init() {
+	authCmd.AddCommand(accountsCmd)
+}
@@ -0,0 +1,44 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ravi-technologies/sunday-cli/internal/config"
+)
+
+// TestSelectAccount verifies that --account selects a logged-in account
+// and that unknown or invalid names are rejected, except when logging in.
+func TestSelectAccount(t *testing.T) {
+	_, cleanup := withTempHome(t)
+	defer cleanup()
+	defer func() {
+		accountName = ""
+		config.SetAccount("")
+	}()
+
+	if err := selectAccount(statusCmd); err != nil {
+		t.Fatalf("selectAccount() with the main account error = %v", err)
+	}
+
+	accountName = "work"
+	err := selectAccount(statusCmd)
+	if err == nil || !strings.Contains(err.Error(), "sunday auth login --account work") {
+		t.Errorf("selectAccount() for missing account error = %v, want login hint", err)
+	}
+	if err := selectAccount(loginCmd); err != nil {
+		t.Errorf("selectAccount() for auth login error = %v", err)
+	}
+
+	if err := config.Save(&config.Config{AccessToken: "work-token", RefreshToken: "work-refresh"}); err != nil {
+		t.Fatalf("config.Save() error = %v", err)
+	}
+	if err := selectAccount(statusCmd); err != nil {
+		t.Errorf("selectAccount() for logged-in account error = %v", err)
+	}
+
+	accountName = "../work"
+	if err := selectAccount(statusCmd); err == nil {
+		t.Error("selectAccount() with invalid name succeeded, want error")
+	}
+}
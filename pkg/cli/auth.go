@@ -2,7 +2,11 @@ package cli
 
 import (
 	"fmt"
+	"os"
+	"strings"
+	"time"
 
+	"github.com/fatih/color"
 	"github.com/ravi-technologies/sunday-cli/internal/api"
 	"github.com/ravi-technologies/sunday-cli/internal/auth"
 	"github.com/ravi-technologies/sunday-cli/internal/config"
@@ -10,6 +14,26 @@ import (
 	"github.com/spf13/cobra"
 )
 
+// Login flows accepted by --flow.
+const (
+	loginFlowDevice  = "device"
+	loginFlowBrowser = "browser"
+)
+
+// Flags of auth login, auth status and auth token.
+var (
+	loginFlow       string
+	loginNoBrowser  bool
+	loginIdP        string
+	loginWait       time.Duration
+	loginInterval   time.Duration
+	loginDeviceCode string
+	loginIdentity   string
+	loginScopes     []string
+	statusCheck     bool
+	tokenRefresh    bool
+)
+
 var authCmd = &cobra.Command{
 	Use:   "auth",
 	Short: "Manage authentication",
@@ -18,24 +42,159 @@ var authCmd = &cobra.Command{
 var loginCmd = &cobra.Command{
 	Use:   "login",
 	Short: "Authenticate with Sunday",
-	Long:  "Start the device code flow to authenticate with your Sunday account.",
+	Long: `Authenticate with your Sunday account.
+
+By default this starts the device code flow: visit the URL shown and enter
+the code. On a desktop, --flow browser is quicker: it opens the Sunday
+login page and receives the result on a temporary server on 127.0.0.1, so
+there is no code to type. It needs a browser on the same machine.
+
+On a remote session (e.g. over SSH), --no-browser skips opening a browser
+and also shows the verification URL as a QR code to scan with a phone.
+
+If your organisation logs in through SSO, --idp names the identity
+provider so the login page can pre-select it.
+
+--wait gives up waiting for approval sooner than the server would, and
+--interval polls less often in the device flow. Defaults for both can be
+set in the config (auth.login_timeout_seconds, auth.poll_interval_seconds).
+
+For provisioning, --device-code (or SUNDAY_DEVICE_CODE) takes a device
+code that was issued and approved elsewhere; login then only collects the
+tokens, without showing a URL or opening a browser.
+
+If you have several identities, --identity picks one by name or UUID
+instead of prompting, for scripted logins.
+
+For agents and scripts, --scope asks for a least-privilege token limited to
+the given scopes (read:inbox, read:passwords, write:passwords). The granted
+scopes are saved, and commands outside them fail before calling the API.`,
+	Example: `  sunday auth login
+  sunday auth login --flow browser
+  sunday auth login --no-browser
+  sunday auth login --idp okta
+  sunday auth login --wait 2m --interval 15s
+  sunday auth login --device-code "$CODE" --identity Work
+  sunday auth login --scope read:inbox,read:passwords`,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if api.APIKeyFromEnv() != "" {
+			yellow := color.New(color.FgYellow).SprintFunc()
+			fmt.Fprintf(cmd.ErrOrStderr(), "%s %s is set and takes precedence over this login for API requests.\n", yellow("Warning:"), api.EnvAPIKey)
+		}
+		// --timeout used to be login's own flag for what is now --wait.
+		if f := cmd.Flag("timeout"); f != nil && f.Changed {
+			yellow := color.New(color.FgYellow).SprintFunc()
+			fmt.Fprintf(cmd.ErrOrStderr(), "%s --timeout now limits each API request, as for other commands; use --wait for how long to wait for approval.\n", yellow("Note:"))
+		}
+		if err := runLoginFlow(cmd); err != nil {
+			return err
+		}
+		runLoginHook(cmd.ErrOrStderr())
+		return nil
+	},
+}
+
+// runLoginFlow logs in with the flow and options chosen by the flags.
+func runLoginFlow(cmd *cobra.Command) error {
+	timeout, interval, err := loginWaitSettings(cmd)
+	if err != nil {
+		return err
+	}
+	deviceCode := loginDeviceCode
+	if deviceCode == "" {
+		deviceCode = os.Getenv(auth.EnvDeviceCode)
+	}
+	for _, scope := range loginScopes {
+		if scope == "" || strings.ContainsAny(scope, " \t") {
+			return fmt.Errorf("invalid scope %q", scope)
+		}
+	}
+	if len(loginScopes) > 0 && deviceCode != "" {
+		return fmt.Errorf("--scope can't be used with a pre-issued device code; its scopes were set when it was issued")
+	}
+	switch loginFlow {
+	case loginFlowDevice:
 		flow, err := auth.NewDeviceFlow()
 		if err != nil {
 			return err
 		}
-		return flow.Run()
-	},
+		flow.NoBrowser = loginNoBrowser
+		flow.IdentityProvider = loginIdP
+		flow.Timeout = timeout
+		flow.Interval = interval
+		flow.DeviceCode = deviceCode
+		flow.Identity = loginIdentity
+		flow.Scopes = loginScopes
+		return flow.Run(cmd.Context())
+	case loginFlowBrowser:
+		if loginNoBrowser {
+			return fmt.Errorf("--no-browser needs the device flow; the browser flow must run on a machine with a browser")
+		}
+		if deviceCode != "" {
+			return fmt.Errorf("a pre-issued device code needs the device flow")
+		}
+		if cmd.Flags().Changed("interval") {
+			return fmt.Errorf("--interval only applies to the device flow; the browser flow doesn't poll")
+		}
+		if len(loginScopes) > 0 {
+			return fmt.Errorf("--scope needs the device flow")
+		}
+		flow, err := auth.NewBrowserFlow()
+		if err != nil {
+			return err
+		}
+		flow.IdentityProvider = loginIdP
+		flow.Identity = loginIdentity
+		if timeout > 0 {
+			flow.Timeout = timeout
+		}
+		return flow.Run(cmd.Context())
+	default:
+		return fmt.Errorf("invalid --flow %q: must be %s or %s", loginFlow, loginFlowDevice, loginFlowBrowser)
+	}
+}
+
+// loginWaitSettings returns the login timeout and poll interval from the
+// flags, falling back to the config defaults. Zero means use the server's
+// (or the flow's) own value.
+func loginWaitSettings(cmd *cobra.Command) (timeout, interval time.Duration, err error) {
+	timeout, interval = loginWait, loginInterval
+	if cfg, err := config.Load(); err == nil {
+		if !cmd.Flags().Changed("wait") {
+			timeout = time.Duration(cfg.Auth.LoginTimeoutSeconds) * time.Second
+		}
+		if !cmd.Flags().Changed("interval") {
+			interval = time.Duration(cfg.Auth.PollIntervalSeconds) * time.Second
+		}
+	}
+	if timeout < 0 {
+		return 0, 0, fmt.Errorf("invalid login timeout %s: must be positive", timeout)
+	}
+	if interval != 0 && interval < time.Second {
+		return 0, 0, fmt.Errorf("invalid poll interval %s: must be at least 1s", interval)
+	}
+	return timeout, interval, nil
 }
 
 var logoutCmd = &cobra.Command{
 	Use:   "logout",
 	Short: "Clear stored credentials",
 	RunE: func(cmd *cobra.Command, args []string) error {
+		// Keep the session details for the post-logout hook.
+		session, loadErr := config.Load()
 		if err := config.Clear(); err != nil {
 			return fmt.Errorf("failed to clear credentials: %w", err)
 		}
+		if loadErr == nil {
+			discardPrivateKey(session.PrivateKey)
+		}
+		if err := api.ClearCache(); err != nil {
+			return fmt.Errorf("failed to clear cached responses: %w", err)
+		}
 		output.Current.PrintMessage("Logged out successfully")
+		if loadErr == nil {
+			runHook(cmd.ErrOrStderr(), hookPostLogout, session)
+		}
 		return nil
 	},
 }
@@ -43,12 +202,28 @@ var logoutCmd = &cobra.Command{
 var statusCmd = &cobra.Command{
 	Use:   "status",
 	Short: "Show authentication status",
+	Long: `Show whether you are logged in, from the local config.
+
+With --check nothing is printed: the exit status is 0 if logged in with a
+session that hasn't expired, and 1 otherwise.`,
+	Example: `  sunday auth status
+  sunday auth status --check || sunday auth login`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		client, err := api.NewClient(nil)
 		if err != nil {
+			if statusCheck {
+				return ErrSilentExit
+			}
 			return err
 		}
 
+		if statusCheck {
+			if !sessionActive(client) {
+				return ErrSilentExit
+			}
+			return nil
+		}
+
 		if client.IsAuthenticated() {
 			result := map[string]interface{}{
 				"authenticated": true,
@@ -59,6 +234,15 @@ var statusCmd = &cobra.Command{
 			if identity := client.GetIdentityName(); identity != "" {
 				result["identity"] = identity
 			}
+			if client.UsesAPIKey() {
+				result["method"] = "api_key"
+			}
+			if name := config.ActiveAccount(); name != "" {
+				result["account"] = name
+			}
+			if scopes := client.GetScopes(); len(scopes) > 0 && !client.UsesAPIKey() {
+				result["scopes"] = scopes
+			}
 			output.Current.Print(result)
 		} else {
 			output.Current.Print(map[string]interface{}{
@@ -69,9 +253,188 @@ var statusCmd = &cobra.Command{
 	},
 }
 
+// sessionActive reports whether client is logged in with a session that
+// can still be refreshed. A refresh token's expiry is only known when it
+// is a JWT; otherwise the session is assumed to be live.
+func sessionActive(client *api.Client) bool {
+	if !client.IsAuthenticated() {
+		return false
+	}
+	exp := client.SessionExpiry()
+	return exp.IsZero() || time.Now().Before(exp)
+}
+
+// whoamiResult is the output of auth whoami.
+type whoamiResult struct {
+	Email          string `json:"email,omitempty"`
+	Name           string `json:"name,omitempty"`
+	Identity       string `json:"identity,omitempty"`
+	TokenExpiresAt string `json:"token_expires_at,omitempty"`
+	Method         string `json:"method"`
+}
+
+var whoamiCmd = &cobra.Command{
+	Use:   "whoami",
+	Short: "Show who you are logged in as, checked with the server",
+	Long: `Show the user and identity you are logged in as.
+
+Unlike "auth status", which only reads the local config, this asks the
+server, so it fails if the token has been revoked or the session expired.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := api.NewClient(nil)
+		if err != nil {
+			return err
+		}
+		if !client.IsAuthenticated() {
+			return errNotAuthenticated
+		}
+
+		owner, err := client.GetOwnerContext(cmd.Context())
+		if err != nil {
+			return err
+		}
+
+		result := whoamiResult{
+			Email:    owner.Email,
+			Name:     strings.TrimSpace(owner.FirstName + " " + owner.LastName),
+			Identity: client.GetIdentityName(),
+			Method:   "login",
+		}
+		if result.Email == "" {
+			result.Email = client.GetUserEmail()
+		}
+		if owner.Identity != nil {
+			result.Identity = identityLabel(*owner.Identity)
+		}
+		if client.UsesAPIKey() {
+			result.Method = "api_key"
+		}
+		if expiry := client.TokenExpiry(); !expiry.IsZero() {
+			result.TokenExpiresAt = expiry.UTC().Format(time.RFC3339)
+		}
+
+		if jsonOutput {
+			return output.Current.Print(result)
+		}
+
+		user := result.Email
+		if result.Name != "" {
+			user = fmt.Sprintf("%s <%s>", result.Name, result.Email)
+		}
+		fmt.Printf("User:     %s\n", user)
+		if result.Identity != "" {
+			fmt.Printf("Identity: %s\n", result.Identity)
+		}
+		if result.Method == "api_key" {
+			fmt.Printf("Auth:     API key ($%s)\n", api.EnvAPIKey)
+		}
+		if result.TokenExpiresAt != "" {
+			expiry := client.TokenExpiry()
+			fmt.Printf("Token:    valid, refreshes at %s\n", expiry.Local().Format("Jan 02 15:04:05"))
+		}
+		return nil
+	},
+}
+
+var tokenCmd = &cobra.Command{
+	Use:   "token",
+	Short: "Print the access token",
+	Long: `Print a current access token, for tools that call the Sunday API
+directly. The token is refreshed first if it has expired, or always with
+--refresh. Only the token is printed, so it can be used as:
+
+  curl -H "Authorization: Bearer $(sunday auth token)" ...`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := api.NewClient(nil)
+		if err != nil {
+			return err
+		}
+		if !client.IsAuthenticated() {
+			return errNotAuthenticated
+		}
+
+		token, err := client.AccessTokenContext(cmd.Context(), tokenRefresh)
+		if err != nil {
+			return err
+		}
+
+		if jsonOutput {
+			return output.Current.Print(map[string]string{
+				"access_token": token,
+				"expires_at":   client.TokenExpiry().UTC().Format(time.RFC3339),
+			})
+		}
+		fmt.Println(token)
+		return nil
+	},
+}
+
+// identityLabel returns "Name (email or phone)" for an identity.
+func identityLabel(id api.Identity) string {
+	detail := id.SundayEmail
+	if detail == "" {
+		detail = id.SundayPhone
+	}
+	if detail == "" {
+		return id.Name
+	}
+	return fmt.Sprintf("%s (%s)", id.Name, detail)
+}
+
+var refreshCmd = &cobra.Command{
+	Use:   "refresh",
+	Short: "Refresh the access token now",
+	Long: `Refresh the access token now rather than when it next expires.
+
+Useful before a batch of calls in a long-running script. Prints when the
+new token expires.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := api.NewClient(nil)
+		if err != nil {
+			return err
+		}
+		if !client.IsAuthenticated() {
+			return errNotAuthenticated
+		}
+
+		if err := client.RefreshAccessTokenContext(cmd.Context()); err != nil {
+			return fmt.Errorf("token refresh failed: %w", err)
+		}
+
+		expiresAt := client.TokenExpiry()
+		if jsonOutput {
+			return output.Current.Print(map[string]interface{}{
+				"status":     "refreshed",
+				"expires_at": expiresAt.UTC().Format(time.RFC3339),
+			})
+		}
+
+		output.Current.PrintMessage(fmt.Sprintf("Token refreshed. Expires at %s (in %s).",
+			expiresAt.Local().Format("Jan 02 15:04:05"), time.Until(expiresAt).Round(time.Second)))
+		return nil
+	},
+}
+
 func init() {
+	loginCmd.Flags().StringVar(&loginFlow, "flow", loginFlowDevice, "Login flow: device or browser (authorization code + PKCE via a localhost callback)")
+	statusCmd.Flags().BoolVar(&statusCheck, "check", false, "Print nothing; exit 0 if logged in, 1 if not")
+	tokenCmd.Flags().BoolVar(&tokenRefresh, "refresh", false, "Refresh the token even if it hasn't expired")
+	loginCmd.Flags().BoolVar(&loginNoBrowser, "no-browser", false, "Don't open a browser; also show the login URL as a QR code (for SSH sessions)")
+	loginCmd.Flags().StringVar(&loginIdentity, "identity", "", "Identity to bind, by name or UUID, instead of prompting")
+	loginCmd.Flags().StringSliceVar(&loginScopes, "scope", nil, "Limit the token to these scopes (repeatable or comma-separated), e.g. read:inbox")
+	loginCmd.Flags().StringVar(&loginIdP, "idp", "", "SSO identity provider for the login page to pre-select")
+	loginCmd.Flags().DurationVar(&loginWait, "wait", 0, "Give up waiting for approval after this long (default: until the login expires)")
+	loginCmd.Flags().StringVar(&loginDeviceCode, "device-code", "", "Complete a login pre-approved elsewhere with this device code (or set "+auth.EnvDeviceCode+")")
+	loginCmd.Flags().DurationVar(&loginInterval, "interval", 0, "Poll for device flow approval this often; can't be shorter than the server's interval")
+	markSensitiveFlags(loginCmd, "device-code")
 	authCmd.AddCommand(loginCmd)
 	authCmd.AddCommand(logoutCmd)
 	authCmd.AddCommand(statusCmd)
+	authCmd.AddCommand(refreshCmd)
+	authCmd.AddCommand(whoamiCmd)
+	authCmd.AddCommand(tokenCmd)
 	rootCmd.AddCommand(authCmd)
 }
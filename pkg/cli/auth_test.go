@@ -2,7 +2,14 @@ package cli
 
 import (
 	"bytes"
+	"context"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"runtime"
@@ -10,8 +17,10 @@ import (
 	"testing"
 	"time"
 
+	"github.com/ravi-technologies/sunday-cli/internal/api"
 	"github.com/ravi-technologies/sunday-cli/internal/config"
 	"github.com/ravi-technologies/sunday-cli/internal/output"
+	"github.com/ravi-technologies/sunday-cli/internal/version"
 	"github.com/spf13/cobra"
 )
 
@@ -36,6 +45,12 @@ func withTempHome(t *testing.T) (tmpDir string, cleanup func()) {
 		t.Fatalf("Failed to set %s: %v", homeEnvVar, err)
 	}
 
+	// Keep tokens in the temp config file rather than the OS keyring.
+	t.Setenv(config.EnvTokenStore, config.TokenStoreFile)
+	t.Setenv(config.EnvProfile, "")
+	t.Setenv(config.EnvAccount, "")
+	t.Setenv(api.EnvAPIKey, "")
+
 	cleanup = func() {
 		os.Setenv(homeEnvVar, originalHome)
 	}
@@ -285,3 +300,232 @@ func TestAuthLogout_ClearsConfig(t *testing.T) {
 		t.Errorf("Expected empty RefreshToken after logout, got %q", loadedConfig.RefreshToken)
 	}
 }
+
+// TestAuthRefresh verifies that auth refresh renews the stored token and
+// reports the new expiry as JSON.
+func TestAuthRefresh(t *testing.T) {
+	_, cleanup := withTempHome(t)
+	defer cleanup()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != api.PathTokenRefresh {
+			t.Errorf("unexpected request %s", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"access":"new-access","refresh":"new-refresh"}`))
+	}))
+	defer server.Close()
+	origURL := version.APIBaseURL
+	version.APIBaseURL = server.URL
+	defer func() { version.APIBaseURL = origURL }()
+
+	if err := config.Save(&config.Config{AccessToken: "old", RefreshToken: "old-refresh", ExpiresAt: time.Now().Add(time.Minute)}); err != nil {
+		t.Fatalf("config.Save() error = %v", err)
+	}
+
+	jsonOutput = true
+	output.SetJSON(true)
+	defer func() {
+		jsonOutput = false
+		output.SetJSON(false)
+	}()
+
+	oldStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+	refreshCmd.SetContext(context.Background())
+	err := refreshCmd.RunE(refreshCmd, nil)
+	w.Close()
+	os.Stdout = oldStdout
+	out, _ := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("auth refresh error = %v", err)
+	}
+
+	var result map[string]string
+	if err := json.Unmarshal(out, &result); err != nil {
+		t.Fatalf("output is not JSON: %v\n%s", err, out)
+	}
+	expiresAt, err := time.Parse(time.RFC3339, result["expires_at"])
+	if err != nil || time.Until(expiresAt) < 3*time.Minute {
+		t.Errorf("expires_at = %q, want about %s from now", result["expires_at"], api.TokenExpiryBuffer)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("config.Load() error = %v", err)
+	}
+	if cfg.AccessToken != "new-access" || cfg.RefreshToken != "new-refresh" {
+		t.Errorf("stored tokens = %q/%q, want refreshed", cfg.AccessToken, cfg.RefreshToken)
+	}
+}
+
+// TestAuthRefresh_NotAuthenticated verifies that auth refresh without a
+// login fails with the not-authenticated error.
+func TestAuthRefresh_NotAuthenticated(t *testing.T) {
+	_, cleanup := withTempHome(t)
+	defer cleanup()
+	origURL := version.APIBaseURL
+	version.APIBaseURL = "https://sunday.example"
+	defer func() { version.APIBaseURL = origURL }()
+
+	if err := refreshCmd.RunE(refreshCmd, nil); !errors.Is(err, errNotAuthenticated) {
+		t.Errorf("auth refresh error = %v, want errNotAuthenticated", err)
+	}
+}
+
+// TestAuthWhoami verifies that whoami reports the server's view of the
+// user and fails once the server rejects the session.
+func TestAuthWhoami(t *testing.T) {
+	_, cleanup := withTempHome(t)
+	defer cleanup()
+
+	revoked := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if revoked {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"detail":"Token is invalid or expired"}`))
+			return
+		}
+		if r.URL.Path != api.PathOwner {
+			t.Errorf("unexpected request %s", r.URL.Path)
+		}
+		w.Write([]byte(`{"first_name":"Ada","last_name":"Lovelace","email":"ada@example.com","identity":{"name":"Work","sunday_email":"ada@sunday.app"}}`))
+	}))
+	defer server.Close()
+	origURL := version.APIBaseURL
+	version.APIBaseURL = server.URL
+	defer func() { version.APIBaseURL = origURL }()
+
+	if err := config.Save(&config.Config{AccessToken: "a", RefreshToken: "r", ExpiresAt: time.Now().Add(time.Hour), UserEmail: "ada@example.com"}); err != nil {
+		t.Fatalf("config.Save() error = %v", err)
+	}
+
+	jsonOutput = true
+	output.SetJSON(true)
+	defer func() {
+		jsonOutput = false
+		output.SetJSON(false)
+	}()
+
+	oldStdout := os.Stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+	whoamiCmd.SetContext(context.Background())
+	err := whoamiCmd.RunE(whoamiCmd, nil)
+	w.Close()
+	os.Stdout = oldStdout
+	out, _ := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("auth whoami error = %v", err)
+	}
+
+	var got whoamiResult
+	if err := json.Unmarshal(out, &got); err != nil {
+		t.Fatalf("output is not JSON: %v\n%s", err, out)
+	}
+	if got.Email != "ada@example.com" || got.Name != "Ada Lovelace" || got.Identity != "Work (ada@sunday.app)" || got.Method != "login" || got.TokenExpiresAt == "" {
+		t.Errorf("auth whoami = %+v", got)
+	}
+
+	revoked = true
+	if err := whoamiCmd.RunE(whoamiCmd, nil); err == nil {
+		t.Error("auth whoami with a revoked token succeeded, want error")
+	}
+}
+
+// TestLoginWaitSettings verifies that --wait and --interval override
+// the config defaults, and that a too-short interval is rejected.
+func TestLoginWaitSettings(t *testing.T) {
+	_, cleanup := withTempHome(t)
+	defer cleanup()
+
+	cfg := &config.Config{Auth: config.AuthSettings{LoginTimeoutSeconds: 120, PollIntervalSeconds: 10}}
+	if err := config.Save(cfg); err != nil {
+		t.Fatalf("config.Save() error = %v", err)
+	}
+
+	newCmd := func() *cobra.Command {
+		cmd := &cobra.Command{Use: "login"}
+		cmd.Flags().DurationVar(&loginWait, "wait", 0, "")
+		cmd.Flags().DurationVar(&loginInterval, "interval", 0, "")
+		return cmd
+	}
+
+	timeout, interval, err := loginWaitSettings(newCmd())
+	if err != nil {
+		t.Fatalf("loginWaitSettings() error = %v", err)
+	}
+	if timeout != 2*time.Minute || interval != 10*time.Second {
+		t.Errorf("config defaults = %s, %s, want 2m0s, 10s", timeout, interval)
+	}
+
+	cmd := newCmd()
+	cmd.Flags().Set("wait", "30s")
+	timeout, interval, err = loginWaitSettings(cmd)
+	if err != nil {
+		t.Fatalf("loginWaitSettings() error = %v", err)
+	}
+	if timeout != 30*time.Second || interval != 10*time.Second {
+		t.Errorf("with --wait = %s, %s, want 30s, 10s", timeout, interval)
+	}
+
+	cmd = newCmd()
+	cmd.Flags().Set("interval", "100ms")
+	if _, _, err := loginWaitSettings(cmd); err == nil {
+		t.Error("loginWaitSettings() with --interval 100ms error = nil, want an error")
+	}
+	loginWait, loginInterval = 0, 0
+}
+
+// TestAuthStatus_Check verifies that auth status --check signals the login
+// state through its exit status alone.
+func TestAuthStatus_Check(t *testing.T) {
+	_, cleanup := withTempHome(t)
+	defer cleanup()
+
+	origURL := version.APIBaseURL
+	version.APIBaseURL = "https://sunday.example"
+	defer func() { version.APIBaseURL = origURL }()
+
+	statusCheck = true
+	defer func() { statusCheck = false }()
+
+	refreshJWT := func(exp time.Time) string {
+		payload := fmt.Sprintf(`{"exp":%d}`, exp.Unix())
+		return "eyJhbGciOiJIUzI1NiJ9." + base64.RawURLEncoding.EncodeToString([]byte(payload)) + ".sig"
+	}
+	tests := []struct {
+		name    string
+		cfg     *config.Config
+		wantErr error
+	}{
+		{"logged out", &config.Config{}, ErrSilentExit},
+		{"opaque refresh token", &config.Config{AccessToken: "a", RefreshToken: "r"}, nil},
+		{"live session", &config.Config{AccessToken: "a", RefreshToken: refreshJWT(time.Now().Add(time.Hour))}, nil},
+		{"expired session", &config.Config{AccessToken: "a", RefreshToken: refreshJWT(time.Now().Add(-time.Hour))}, ErrSilentExit},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := config.Save(tt.cfg); err != nil {
+				t.Fatalf("config.Save() error = %v", err)
+			}
+
+			oldStdout := os.Stdout
+			r, w, _ := os.Pipe()
+			os.Stdout = w
+			err := statusCmd.RunE(statusCmd, nil)
+			w.Close()
+			os.Stdout = oldStdout
+			out, _ := io.ReadAll(r)
+
+			if !errors.Is(err, tt.wantErr) || (tt.wantErr == nil && err != nil) {
+				t.Errorf("auth status --check error = %v, want %v", err, tt.wantErr)
+			}
+			if len(out) != 0 {
+				t.Errorf("auth status --check printed %q, want nothing", out)
+			}
+		})
+	}
+}
@@ -0,0 +1,137 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/ravi-technologies/sunday-cli/internal/contacts"
+	"github.com/ravi-technologies/sunday-cli/internal/output"
+	"github.com/ravi-technologies/sunday-cli/internal/phone"
+	"github.com/ravi-technologies/sunday-cli/internal/recipient"
+	"github.com/spf13/cobra"
+)
+
+var contactsCmd = &cobra.Command{
+	Use:   "contacts",
+	Short: "Manage local contacts",
+	Long: `Manage the local contact book (~/.sunday/contacts.json).
+
+Contacts name the people you message and list every number or address
+they use, so SMS from several numbers is shown as one thread.`,
+}
+
+var contactsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List contacts",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		book, err := contacts.Load()
+		if err != nil {
+			return err
+		}
+
+		if jsonOutput {
+			return output.Current.Print(book.Contacts)
+		}
+
+		if len(book.Contacts) == 0 {
+			output.Current.PrintMessage("No contacts found")
+			return nil
+		}
+
+		headers := []string{"NAME", "PHONES", "EMAILS"}
+		rows := make([][]string, len(book.Contacts))
+		for i, c := range book.Contacts {
+			rows[i] = []string{c.Name, strings.Join(c.Phones, ", "), strings.Join(c.Emails, ", ")}
+		}
+		output.Current.PrintTable(headers, rows)
+		return nil
+	},
+}
+
+var contactsAddCmd = &cobra.Command{
+	Use:   "add <name> <phone-or-email>...",
+	Short: "Add a contact, or more numbers and addresses to one",
+	Long: `Add a contact, or more numbers and addresses to an existing one.
+
+Phone numbers are stored in E.164 form (+15551234567). Include the country
+code for numbers outside North America.`,
+	Example: `  sunday contacts add "Alice Smith" +15551234567 +15557654321
+  sunday contacts add "Alice Smith" alice@example.com`,
+	Args: cobra.MinimumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		book, err := contacts.Load()
+		if err != nil {
+			return err
+		}
+
+		addresses := args[1:]
+		for i, addr := range addresses {
+			if strings.Contains(addr, "@") {
+				if err := recipient.ValidateEmail(addr); err != nil {
+					return err
+				}
+				warnEmailTypo(cmd, addr)
+				continue
+			}
+			if addresses[i], err = phone.Normalize(addr); err != nil {
+				return err
+			}
+		}
+
+		book.Add(args[0], addresses...)
+		if err := book.Save(); err != nil {
+			return err
+		}
+
+		if jsonOutput {
+			return output.Current.Print(map[string]string{"status": "saved"})
+		}
+
+		fmt.Printf("Contact %q saved.\n", args[0])
+		return nil
+	},
+}
+
+var contactsRemoveCmd = &cobra.Command{
+	Use:   "remove <name>",
+	Short: "Remove a contact",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		book, err := contacts.Load()
+		if err != nil {
+			return err
+		}
+
+		if err := book.Remove(args[0]); err != nil {
+			return err
+		}
+		if err := book.Save(); err != nil {
+			return err
+		}
+
+		if jsonOutput {
+			return output.Current.Print(map[string]string{"status": "deleted"})
+		}
+
+		fmt.Printf("Contact %q removed.\n", args[0])
+		return nil
+	},
+}
+
+// warnEmailTypo prints a warning if address looks like a typo of a common
+// mail domain.
+func warnEmailTypo(cmd *cobra.Command, address string) {
+	if suggestion, ok := recipient.Suggest(address); ok {
+		yellow := color.New(color.FgYellow).SprintFunc()
+		fmt.Fprintf(cmd.ErrOrStderr(), "%s %s looks like a typo; did you mean %s?\n", yellow("Warning:"), address, suggestion)
+	}
+}
+
+func init() {
+	contactsCmd.AddCommand(contactsListCmd)
+	contactsCmd.AddCommand(contactsAddCmd)
+	contactsCmd.AddCommand(contactsRemoveCmd)
+	rootCmd.AddCommand(contactsCmd)
+}
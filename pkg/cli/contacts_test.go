@@ -0,0 +1,27 @@
+package cli
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+// TestWarnEmailTypo verifies that likely typos produce a suggestion and
+// other addresses produce nothing.
+func TestWarnEmailTypo(t *testing.T) {
+	var buf bytes.Buffer
+	cmd := &cobra.Command{}
+	cmd.SetErr(&buf)
+
+	warnEmailTypo(cmd, "bob@example.com")
+	if buf.Len() != 0 {
+		t.Errorf("warnEmailTypo(valid) wrote %q", buf.String())
+	}
+
+	warnEmailTypo(cmd, "bob@gamil.com")
+	if !strings.Contains(buf.String(), "did you mean bob@gmail.com?") {
+		t.Errorf("warnEmailTypo(typo) wrote %q", buf.String())
+	}
+}
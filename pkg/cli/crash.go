@@ -0,0 +1,160 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"strings"
+
+	"github.com/ravi-technologies/sunday-cli/internal/api"
+	"github.com/ravi-technologies/sunday-cli/internal/logging"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// Process exit codes returned by ExitCode. Besides ExitCodeError they
+// follow sysexits.h, so scripts can tell the common failures apart.
+const (
+	// ExitCodeError is returned for ordinary command failures.
+	ExitCodeError = 1
+
+	// ExitCodeNotFound signals that the requested item doesn't exist
+	// (EX_NOINPUT).
+	ExitCodeNotFound = 66
+
+	// ExitCodeUnavailable signals a server-side failure (EX_UNAVAILABLE).
+	ExitCodeUnavailable = 69
+
+	// ExitCodeCrash signals an internal error (EX_SOFTWARE from sysexits.h).
+	ExitCodeCrash = 70
+
+	// ExitCodeRateLimited signals that the API is rate limiting and the
+	// command may succeed later (EX_TEMPFAIL).
+	ExitCodeRateLimited = 75
+
+	// ExitCodeAuth signals missing, expired or rejected credentials
+	// (EX_NOPERM).
+	ExitCodeAuth = 77
+)
+
+// ErrSilentExit fails a command with ExitCodeError without an error
+// message, for commands such as auth status --check whose exit status is
+// their only output.
+var ErrSilentExit = errors.New("exit status 1")
+
+// annotationSensitive marks a flag whose value must never appear in crash
+// reports. Set it with markSensitiveFlags where the flag is defined.
+const annotationSensitive = "sunday.sensitive"
+
+// markSensitiveFlags marks the named local or persistent flags of cmd as
+// sensitive, so crash reports redact their values.
+func markSensitiveFlags(cmd *cobra.Command, names ...string) {
+	for _, name := range names {
+		f := cmd.Flags().Lookup(name)
+		if f == nil {
+			f = cmd.PersistentFlags().Lookup(name)
+		}
+		if f == nil {
+			panic(fmt.Sprintf("markSensitiveFlags: no flag --%s on %q", name, cmd.CommandPath()))
+		}
+		if f.Annotations == nil {
+			f.Annotations = map[string][]string{}
+		}
+		f.Annotations[annotationSensitive] = []string{"true"}
+	}
+}
+
+// sensitiveFlags returns the flags of cmd and its subcommands marked
+// sensitive, as they are written on the command line: "--name", and
+// "-n" for those with a shorthand.
+func sensitiveFlags(cmd *cobra.Command) map[string]bool {
+	names := map[string]bool{}
+	add := func(f *pflag.Flag) {
+		if _, ok := f.Annotations[annotationSensitive]; !ok {
+			return
+		}
+		names["--"+f.Name] = true
+		if f.Shorthand != "" {
+			names["-"+f.Shorthand] = true
+		}
+	}
+	cmd.Flags().VisitAll(add)
+	cmd.PersistentFlags().VisitAll(add)
+	for _, c := range cmd.Commands() {
+		maps.Copy(names, sensitiveFlags(c))
+	}
+	return names
+}
+
+// crashError reports a recovered panic to the user.
+type crashError struct {
+	value      any
+	reportPath string
+}
+
+func (e *crashError) Error() string {
+	return fmt.Sprintf("internal error: %v", e.value)
+}
+
+func (e *crashError) Hint() string {
+	if e.reportPath == "" {
+		return "This is a bug in sunday. Please report it along with the command you ran."
+	}
+	return fmt.Sprintf("This is a bug in sunday. A crash report was written to %s; please attach it when reporting the issue.", e.reportPath)
+}
+
+// ExitCode returns the process exit code for an error returned by Execute.
+func ExitCode(err error) int {
+	var crash *crashError
+	switch {
+	case errors.As(err, &crash):
+		return ExitCodeCrash
+	case errors.Is(err, errNotAuthenticated), errors.Is(err, api.ErrSessionExpired), errors.Is(err, api.ErrUnauthorized):
+		return ExitCodeAuth
+	case errors.Is(err, api.ErrNotFound):
+		return ExitCodeNotFound
+	case errors.Is(err, api.ErrRateLimited):
+		return ExitCodeRateLimited
+	case errors.Is(err, api.ErrServer), errors.Is(err, api.ErrCircuitOpen):
+		return ExitCodeUnavailable
+	}
+	return ExitCodeError
+}
+
+// newCrashError writes a crash report for a recovered panic and returns the
+// error to show the user.
+func newCrashError(value any, stack []byte, args []string) error {
+	slog.Error("panic", "value", fmt.Sprint(value), "stack", string(stack))
+
+	path, err := logging.WriteCrashReport(value, stack, sanitizeArgs(args))
+	if err != nil {
+		slog.Error("writing crash report failed", "error", err)
+		path = ""
+	}
+	return &crashError{value: value, reportPath: path}
+}
+
+// sanitizeArgs redacts the values of flags marked sensitive, in both
+// "--flag value" and "--flag=value" forms.
+func sanitizeArgs(args []string) []string {
+	sensitive := sensitiveFlags(rootCmd)
+	out := make([]string, len(args))
+	redactNext := false
+	for i, arg := range args {
+		switch {
+		case redactNext:
+			out[i] = "REDACTED"
+			redactNext = false
+		case sensitive[arg]:
+			out[i] = arg
+			redactNext = true
+		default:
+			out[i] = arg
+			if name, _, ok := strings.Cut(arg, "="); ok && sensitive[name] {
+				out[i] = name + "=REDACTED"
+			}
+		}
+	}
+	return out
+}
@@ -0,0 +1,103 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/ravi-technologies/sunday-cli/internal/api"
+	"github.com/ravi-technologies/sunday-cli/internal/config"
+	"github.com/ravi-technologies/sunday-cli/internal/output"
+)
+
+// TestSanitizeArgs verifies that sensitive flag values are redacted in both
+// flag forms.
+func TestSanitizeArgs(t *testing.T) {
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{
+			[]string{"sunday", "vault", "create", "x.com", "--password", "hunter2", "--notes=secret", "--json"},
+			"sunday vault create x.com --password REDACTED --notes=REDACTED --json",
+		},
+		{
+			[]string{"sunday", "crypto", "encrypt", "--value", "hunter2"},
+			"sunday crypto encrypt --value REDACTED",
+		},
+		{
+			[]string{"sunday", "auth", "login", "--device-code=dc", "--identity", "Work"},
+			"sunday auth login --device-code=REDACTED --identity Work",
+		},
+	}
+	for _, tt := range tests {
+		if got := sanitizeArgs(tt.args); strings.Join(got, " ") != tt.want {
+			t.Errorf("sanitizeArgs() = %q, want %q", strings.Join(got, " "), tt.want)
+		}
+	}
+}
+
+// TestNewCrashError verifies that a panic produces a crash report, a hint
+// pointing at it, and the dedicated exit code.
+func TestNewCrashError(t *testing.T) {
+	config.SetLocation(t.TempDir())
+	defer config.SetLocation("")
+
+	err := newCrashError("boom", []byte("goroutine 1 [running]:\n"), []string{"sunday", "--password", "hunter2"})
+
+	if code := ExitCode(err); code != ExitCodeCrash {
+		t.Errorf("ExitCode() = %d, want %d", code, ExitCodeCrash)
+	}
+	if code := ExitCode(fmt.Errorf("wrapped: %w", errors.New("plain"))); code != ExitCodeError {
+		t.Errorf("ExitCode(plain) = %d, want %d", code, ExitCodeError)
+	}
+
+	var crash *crashError
+	if !errors.As(err, &crash) || crash.reportPath == "" {
+		t.Fatalf("newCrashError() = %v, want crashError with a report path", err)
+	}
+	var h output.Hinter
+	if !errors.As(Annotate(err), &h) || !strings.Contains(h.Hint(), crash.reportPath) {
+		t.Errorf("hint does not mention the report path %s", crash.reportPath)
+	}
+
+	report, readErr := os.ReadFile(crash.reportPath)
+	if readErr != nil {
+		t.Fatalf("reading crash report: %v", readErr)
+	}
+	for _, want := range []string{"panic: boom", "goroutine 1", "--password REDACTED"} {
+		if !strings.Contains(string(report), want) {
+			t.Errorf("crash report missing %q:\n%s", want, report)
+		}
+	}
+	if strings.Contains(string(report), "hunter2") {
+		t.Error("crash report leaked a password")
+	}
+}
+
+// TestExitCode verifies that common API failures get distinct exit codes,
+// even when wrapped.
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{errNotAuthenticated, ExitCodeAuth},
+		{fmt.Errorf("token refresh failed: %w", api.ErrSessionExpired), ExitCodeAuth},
+		{&api.APIError{StatusCode: http.StatusUnauthorized}, ExitCodeAuth},
+		{fmt.Errorf("getting thread: %w", &api.APIError{StatusCode: http.StatusNotFound}), ExitCodeNotFound},
+		{&api.APIError{StatusCode: http.StatusTooManyRequests}, ExitCodeRateLimited},
+		{&api.APIError{StatusCode: http.StatusBadGateway}, ExitCodeUnavailable},
+		{api.ErrCircuitOpen, ExitCodeUnavailable},
+		{&api.APIError{StatusCode: http.StatusBadRequest}, ExitCodeError},
+		{ErrSilentExit, ExitCodeError},
+	}
+	for _, tt := range tests {
+		if got := ExitCode(tt.err); got != tt.want {
+			t.Errorf("ExitCode(%v) = %d, want %d", tt.err, got, tt.want)
+		}
+	}
+}
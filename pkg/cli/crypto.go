@@ -0,0 +1,355 @@
+package cli
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/ravi-technologies/sunday-cli/internal/api"
+	"github.com/ravi-technologies/sunday-cli/internal/config"
+	"github.com/ravi-technologies/sunday-cli/internal/crypto"
+	"github.com/ravi-technologies/sunday-cli/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var cryptoCmd = &cobra.Command{
+	Use:   "crypto",
+	Short: "Manage your encryption key",
+}
+
+var cryptoRotateCmd = &cobra.Command{
+	Use:   "rotate",
+	Short: "Replace your encryption key with a fresh one",
+	Long: `Replace your encryption key with a fresh one, keeping your PIN.
+
+The new key is derived from your PIN with a new salt and registered with
+the server as pending, then everything stored encrypted (passwords, emails
+and SMS messages) is re-encrypted to it before the server's key record is
+replaced. If it is interrupted, run the command again to finish; the old
+key keeps working until it does.
+
+Other machines logged in to the account must log in again afterwards.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := api.NewClient(nil)
+		if err != nil {
+			return err
+		}
+		if !client.IsAuthenticated() {
+			return errNotAuthenticated
+		}
+		return rotateKey(cmd.Context(), client)
+	},
+}
+
+var cryptoSetupCmd = &cobra.Command{
+	Use:   "setup",
+	Short: "Set up encryption by choosing a PIN",
+	Long: `Set up end-to-end encryption for an account that hasn't yet, without
+the dashboard: choose a 6-digit PIN, or a passphrase if the account's
+policy asks for one, and the key derived from it is registered with
+Sunday and stored for this machine.
+
+The PIN can't be recovered, and without it nothing encrypted can be read.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := api.NewClient(nil)
+		if err != nil {
+			return err
+		}
+		if !client.IsAuthenticated() {
+			return errNotAuthenticated
+		}
+		return setupEncryption(cmd.Context(), client)
+	},
+}
+
+// setupEncryption creates the account's key from a new PIN and registers
+// it with the server.
+func setupEncryption(ctx context.Context, client *api.Client) error {
+	meta, err := client.GetEncryptionMetaContext(ctx)
+	if err != nil {
+		return fmt.Errorf("fetching encryption metadata: %w", err)
+	}
+	if crypto.UsesManagedKey(meta.ManagedMasterKey, meta.PublicKey, meta.Verifier) {
+		return crypto.ErrManagedKey
+	}
+	if meta.PublicKey != "" {
+		return errors.New("encryption is already set up for this account; run `sunday auth login` to unlock it here")
+	}
+	params, err := crypto.NewKDFParams(meta.KDF.Algorithm, meta.KDF.OpsLimit, meta.KDF.MemLimit)
+	if err != nil {
+		return fmt.Errorf("rejecting server key derivation parameters: %w", err)
+	}
+
+	policy, err := crypto.NewPINPolicy(meta.PINPolicy.Kind, meta.PINPolicy.MinLength)
+	if err != nil {
+		return fmt.Errorf("rejecting server PIN policy: %w", err)
+	}
+
+	pin, err := promptNewPIN("", policy)
+	if err != nil {
+		return err
+	}
+
+	salt, err := crypto.NewSalt()
+	if err != nil {
+		return err
+	}
+	kp, err := crypto.DeriveKeyPairWithParams(pin, salt, params)
+	if err != nil {
+		return fmt.Errorf("deriving keypair: %w", err)
+	}
+	defer kp.Wipe()
+	verifier, err := crypto.CreateVerifier(kp)
+	if err != nil {
+		return err
+	}
+	saltB64 := base64.StdEncoding.EncodeToString(salt)
+	publicKey := base64.StdEncoding.EncodeToString(kp.PublicKey[:])
+	err = client.UpdateEncryptionMetaContext(ctx, map[string]string{
+		"salt":       saltB64,
+		"verifier":   verifier,
+		"public_key": publicKey,
+	})
+	if err != nil {
+		return fmt.Errorf("registering the key: %w", err)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	cfg.PINSalt, cfg.PublicKey = saltB64, publicKey
+	cfg.KeyUsedAt = now()
+	if err := storePrivateKey(cfg, kp.PrivateKey); err != nil {
+		return err
+	}
+
+	output.Current.PrintMessage(fmt.Sprintf("Encryption set up. Keep your %s safe: it can't be recovered.", policy.Noun()))
+	return nil
+}
+
+var cryptoValue string
+
+var cryptoEncryptCmd = &cobra.Command{
+	Use:   "encrypt",
+	Short: "Encrypt a value to your key",
+	Long: `Encrypt a value to your public key as an "e2e::" string, the form the
+dashboard stores encrypted fields in. The value is taken from --value, or
+read from stdin without it. No PIN is needed.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		value, err := cryptoInput(cmd)
+		if err != nil {
+			return err
+		}
+		cfg, err := config.Load()
+		if err != nil {
+			return fmt.Errorf("loading config: %w", err)
+		}
+		if cfg.PublicKey == "" {
+			if cfg.AccessToken == "" && api.APIKeyFromEnv() == "" {
+				return errNotAuthenticated
+			}
+			return errEncryptionNotSetUp
+		}
+		encrypted, err := crypto.Encrypt(value, cfg.PublicKey)
+		if err != nil {
+			return err
+		}
+		return printCryptoResult(cmd, encrypted)
+	},
+}
+
+var cryptoDecryptCmd = &cobra.Command{
+	Use:   "decrypt",
+	Short: "Decrypt an \"e2e::\" value",
+	Long: `Decrypt an "e2e::" string encrypted to your key, such as one printed by
+sunday crypto encrypt or copied from an API response. The value is taken
+from --value, or read from stdin without it. If the key is locked, the
+PIN is asked for.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		value, err := cryptoInput(cmd)
+		if err != nil {
+			return err
+		}
+		if !crypto.IsEncrypted(value) {
+			return fmt.Errorf("value is not encrypted: it should start with %q", crypto.EncryptedPrefix)
+		}
+		kp, err := ensureKeyPair()
+		if err != nil {
+			return err
+		}
+		plaintext, err := crypto.DecryptField(value, kp)
+		if err != nil {
+			return err
+		}
+		return printCryptoResult(cmd, plaintext)
+	},
+}
+
+var fingerprintVerify string
+
+var cryptoFingerprintCmd = &cobra.Command{
+	Use:   "fingerprint [email]",
+	Short: "Show the fingerprint of your encryption key",
+	Long: `Show the fingerprint of your public key: a short code that is the same
+wherever the key is. Compare it with the one the dashboard shows to check
+that this machine unlocked the same key before trusting what it decrypts.
+
+With an email, show the fingerprint of that user's key instead, to compare
+with them over another channel, such as a call, before sharing with them.
+
+With --verify, compare the fingerprint with one you were given, failing
+if they differ.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := api.NewClient(nil)
+		if err != nil {
+			return err
+		}
+		if !client.IsAuthenticated() {
+			return errNotAuthenticated
+		}
+		var email string
+		if len(args) == 1 {
+			email = args[0]
+		}
+		fingerprint, err := keyFingerprint(cmd.Context(), client, email)
+		if err != nil {
+			return err
+		}
+
+		if fingerprintVerify != "" {
+			if !crypto.FingerprintsMatch(fingerprint, fingerprintVerify) {
+				return fmt.Errorf("fingerprints don't match: the key's is %s; don't trust it until you know why", fingerprint)
+			}
+			output.Current.PrintMessage("Fingerprint matches")
+			return nil
+		}
+		if jsonOutput {
+			return output.Current.Print(map[string]string{"fingerprint": fingerprint})
+		}
+		fmt.Fprintln(cmd.OutOrStdout(), fingerprint)
+		return nil
+	},
+}
+
+// keyFingerprint returns the fingerprint of the key this machine decrypts
+// with, once it is known to be the account's current key, or with an
+// email, that of the user's key.
+func keyFingerprint(ctx context.Context, client *api.Client, email string) (string, error) {
+	if email != "" {
+		key, err := client.GetRecipientKeyContext(ctx, email)
+		if err != nil {
+			return "", fmt.Errorf("fetching the public key of %s: %w", email, err)
+		}
+		return crypto.Fingerprint(key.PublicKey)
+	}
+
+	// Fingerprint the key that decrypts, not the public key stored next
+	// to it, which could be another's.
+	kp, err := ensureKeyPair()
+	if err != nil {
+		return "", err
+	}
+	defer kp.Wipe()
+	if !kp.Consistent() {
+		return "", errors.New("this machine's private key doesn't match its public key; run `sunday auth login` again")
+	}
+	publicKey := encodePublicKey(kp)
+
+	cfg, err := config.Load()
+	if err != nil {
+		return "", err
+	}
+	meta, err := client.GetEncryptionMetaContext(ctx)
+	if err != nil {
+		return "", fmt.Errorf("fetching encryption metadata: %w", err)
+	}
+	if publicKey != cfg.PublicKey || publicKey != meta.PublicKey {
+		return "", errors.New("this machine's key isn't the account's current key; run `sunday auth login` again")
+	}
+	return crypto.Fingerprint(publicKey)
+}
+
+// cryptoInput returns the value given with --value, or else stdin with a
+// trailing newline trimmed.
+func cryptoInput(cmd *cobra.Command) (string, error) {
+	if cmd.Flags().Changed("value") {
+		return cryptoValue, nil
+	}
+	data, err := io.ReadAll(cmd.InOrStdin())
+	if err != nil {
+		return "", fmt.Errorf("reading stdin: %w", err)
+	}
+	return strings.TrimSuffix(strings.TrimSuffix(string(data), "\n"), "\r"), nil
+}
+
+// printCryptoResult prints the result of encrypt or decrypt alone on a
+// line, for scripts, or as {"value": ...} with --json.
+func printCryptoResult(cmd *cobra.Command, value string) error {
+	if jsonOutput {
+		return output.Current.Print(map[string]string{"value": value})
+	}
+	fmt.Fprintln(cmd.OutOrStdout(), value)
+	return nil
+}
+
+// rotateKey moves the account to a fresh key derived from the same PIN.
+func rotateKey(ctx context.Context, client *api.Client) error {
+	cur, err := verifyCurrentPIN(ctx, client)
+	if err != nil {
+		return err
+	}
+	defer cur.kp.Wipe()
+
+	progress := printReencryptProgress()
+	n, err := moveKey(ctx, client, cur.kp, cur.pin, cur.params, "sunday crypto rotate", progress)
+	progress("", 0, 0)
+	if err != nil {
+		return err
+	}
+	output.Current.PrintMessage(fmt.Sprintf("Encryption key rotated; %d items re-encrypted", n))
+	return nil
+}
+
+// printReencryptProgress returns a reencryptProgress that keeps a line
+// such as "Re-encrypting emails: 12/40" up to date on stderr, if stderr
+// is a terminal. Calling it with an empty kind ends the line.
+func printReencryptProgress() reencryptProgress {
+	if !output.IsTerminal(os.Stderr) {
+		return func(string, int, int) {}
+	}
+	printed := false
+	return func(kind string, done, total int) {
+		if kind == "" {
+			if printed {
+				fmt.Fprintln(os.Stderr)
+			}
+			return
+		}
+		fmt.Fprintf(os.Stderr, "\r\033[KRe-encrypting %s: %d/%d", kind, done, total)
+		printed = true
+	}
+}
+
+func init() {
+	for _, c := range []*cobra.Command{cryptoEncryptCmd, cryptoDecryptCmd} {
+		c.Flags().StringVar(&cryptoValue, "value", "", "The value (default: read from stdin)")
+		markSensitiveFlags(c, "value")
+	}
+	cryptoCmd.AddCommand(cryptoSetupCmd)
+	cryptoCmd.AddCommand(cryptoRotateCmd)
+	cryptoCmd.AddCommand(cryptoEncryptCmd)
+	cryptoCmd.AddCommand(cryptoDecryptCmd)
+	cryptoFingerprintCmd.Flags().StringVar(&fingerprintVerify, "verify", "", "Fingerprint to compare with, e.g. the one the dashboard shows")
+	cryptoCmd.AddCommand(cryptoFingerprintCmd)
+	rootCmd.AddCommand(cryptoCmd)
+}
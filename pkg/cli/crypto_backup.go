@@ -0,0 +1,156 @@
+package cli
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/ravi-technologies/sunday-cli/internal/api"
+	"github.com/ravi-technologies/sunday-cli/internal/config"
+	"github.com/ravi-technologies/sunday-cli/internal/crypto"
+	"github.com/ravi-technologies/sunday-cli/internal/output"
+	"github.com/spf13/cobra"
+)
+
+// promptPassphrase reads a backup passphrase from the terminal. Tests
+// replace it.
+var promptPassphrase = crypto.PromptPassphrase
+
+var backupOut string
+
+var cryptoBackupCmd = &cobra.Command{
+	Use:   "backup",
+	Short: "Back up and restore your encryption key",
+}
+
+var cryptoBackupExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Write your encryption key to a passphrase-protected file",
+	Long: `Write your encryption key to a file encrypted with a passphrase of your
+choice, of at least 12 characters. With the file and the passphrase,
+sunday crypto backup import restores access to your encrypted data on
+another machine.
+
+Keep the file and the passphrase apart: together they unlock everything
+encrypted to your key.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if backupOut == "" {
+			return errors.New("--out is required")
+		}
+		return exportKeyBackup(backupOut)
+	},
+}
+
+var cryptoBackupImportCmd = &cobra.Command{
+	Use:   "import <file>",
+	Short: "Restore your encryption key from a backup file",
+	Long: `Restore your encryption key from a file written by sunday crypto backup
+export, storing it for this machine. You must be logged in to the account
+the key belongs to.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := api.NewClient(nil)
+		if err != nil {
+			return err
+		}
+		if !client.IsAuthenticated() {
+			return errNotAuthenticated
+		}
+		return importKeyBackup(cmd.Context(), client, args[0])
+	},
+}
+
+// exportKeyBackup writes the stored key to a new file at path, encrypted
+// with a passphrase.
+func exportKeyBackup(path string) error {
+	kp, err := ensureKeyPair()
+	if err != nil {
+		return err
+	}
+	defer kp.Wipe()
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+
+	passphrase, err := promptPassphrase(fmt.Sprintf("Choose a backup passphrase (at least %d characters): ", crypto.MinPassphraseLength))
+	if err != nil {
+		return err
+	}
+	confirm, err := promptPassphrase("Confirm passphrase: ")
+	if err != nil {
+		return err
+	}
+	if confirm != passphrase {
+		return errors.New("the passphrases don't match")
+	}
+
+	data, err := crypto.SealBackup(&crypto.KeyBackup{KeyPair: *kp, PINSalt: cfg.PINSalt}, passphrase)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return fmt.Errorf("writing backup: %w", err)
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return fmt.Errorf("writing backup: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("writing backup: %w", err)
+	}
+
+	output.Current.PrintMessage(fmt.Sprintf("Encryption key backed up to %s", path))
+	return nil
+}
+
+// importKeyBackup restores the key in the backup file at path, once it is
+// known to be the account's current key.
+func importKeyBackup(ctx context.Context, client *api.Client, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("reading backup: %w", err)
+	}
+	passphrase, err := promptPassphrase("Backup passphrase: ")
+	if err != nil {
+		return err
+	}
+	backup, err := crypto.OpenBackup(data, passphrase)
+	if err != nil {
+		return err
+	}
+	defer backup.KeyPair.Wipe()
+
+	meta, err := client.GetEncryptionMetaContext(ctx)
+	if err != nil {
+		return fmt.Errorf("fetching encryption metadata: %w", err)
+	}
+	publicKey := base64.StdEncoding.EncodeToString(backup.KeyPair.PublicKey[:])
+	if publicKey != meta.PublicKey || !crypto.Verify(&backup.KeyPair, meta.Verifier) {
+		return errors.New("the backup isn't of this account's current key; was it exported before a PIN change or key rotation?")
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	cfg.PINSalt, cfg.PublicKey = meta.Salt, publicKey
+	cfg.KeyUsedAt = now()
+	if err := storePrivateKey(cfg, backup.KeyPair.PrivateKey); err != nil {
+		return err
+	}
+
+	output.Current.PrintMessage("Encryption key restored")
+	return nil
+}
+
+func init() {
+	cryptoBackupExportCmd.Flags().StringVar(&backupOut, "out", "", "File to write the backup to; it must not exist")
+	cryptoBackupCmd.AddCommand(cryptoBackupExportCmd)
+	cryptoBackupCmd.AddCommand(cryptoBackupImportCmd)
+	cryptoCmd.AddCommand(cryptoBackupCmd)
+}
@@ -0,0 +1,280 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/ravi-technologies/sunday-cli/internal/api"
+	"github.com/ravi-technologies/sunday-cli/internal/config"
+	"github.com/ravi-technologies/sunday-cli/internal/crypto"
+	"github.com/ravi-technologies/sunday-cli/pkg/sunday"
+	"github.com/ravi-technologies/sunday-cli/pkg/sundaytest"
+	"github.com/spf13/cobra"
+)
+
+// TestRotateKey verifies that rotating moves stored data and the server's
+// key record to a fresh key derived from the same PIN.
+func TestRotateKey(t *testing.T) {
+	tmpDir, cleanup := withTempHome(t)
+	defer cleanup()
+
+	server := sundaytest.NewServer(t)
+	server.AddPassword(sunday.PasswordEntry{Domain: "example.com", Password: server.Encrypt("hunter2")})
+	creds := server.Credentials()
+	saveTestConfig(t, tmpDir, &config.Config{AccessToken: creds.AccessToken})
+	client := api.NewClientForURL(server.URL, &config.Config{AccessToken: creds.AccessToken}, nil)
+	oldMeta, _ := client.GetEncryptionMeta()
+
+	withPINs(t, sundaytest.PIN)
+	if err := rotateKey(context.Background(), client); err != nil {
+		t.Fatalf("rotateKey() error = %v", err)
+	}
+
+	meta, err := client.GetEncryptionMeta()
+	if err != nil {
+		t.Fatalf("GetEncryptionMeta() error = %v", err)
+	}
+	if meta.Salt == oldMeta.Salt || meta.PublicKey == oldMeta.PublicKey {
+		t.Error("server's key record wasn't replaced")
+	}
+	salt, _ := base64.StdEncoding.DecodeString(meta.Salt)
+	kp, err := crypto.DeriveKeyPair(sundaytest.PIN, salt)
+	if err != nil {
+		t.Fatalf("DeriveKeyPair() error = %v", err)
+	}
+	if !crypto.Verify(kp, meta.Verifier) {
+		t.Error("the PIN doesn't unlock the new key")
+	}
+	if got, err := crypto.DecryptField(server.Passwords()[0].Password, kp); err != nil || got != "hunter2" {
+		t.Errorf("password = %q, %v; want hunter2 under the new key", got, err)
+	}
+}
+
+// TestReencryptAll_Progress verifies that progress is reported for each
+// kind of item, ending with all of them done.
+func TestReencryptAll_Progress(t *testing.T) {
+	server := sundaytest.NewServer(t)
+	server.AddPassword(sunday.PasswordEntry{Domain: "example.com", Password: server.Encrypt("hunter2")})
+	server.AddSMS(sunday.SundayPhoneMessage{Body: server.Encrypt("one")})
+	server.AddSMS(sunday.SundayPhoneMessage{Body: server.Encrypt("two")})
+	client := api.NewClientForURL(server.URL, &config.Config{AccessToken: server.Credentials().AccessToken}, nil)
+	to, _, _ := deriveTestKeyPair(t)
+
+	var got []string
+	_, err := reencryptAll(context.Background(), client, (*crypto.KeyPair)(server.KeyPair()), to, func(kind string, done, total int) {
+		got = append(got, fmt.Sprintf("%s %d/%d", kind, done, total))
+	})
+	if err != nil {
+		t.Fatalf("reencryptAll() error = %v", err)
+	}
+	want := []string{"passwords 0/1", "passwords 1/1", "emails 0/0", "SMS messages 0/2", "SMS messages 1/2", "SMS messages 2/2"}
+	if !slices.Equal(got, want) {
+		t.Errorf("progress = %q, want %q", got, want)
+	}
+}
+
+// TestCryptoEncryptDecrypt verifies that encrypt's output, read by decrypt
+// from stdin, decrypts to the original value.
+func TestCryptoEncryptDecrypt(t *testing.T) {
+	tmpDir, cleanup := withTempHome(t)
+	defer cleanup()
+	_, privB64, pubB64 := deriveTestKeyPair(t)
+	saveTestConfig(t, tmpDir, &config.Config{PrivateKey: privB64, PublicKey: pubB64})
+
+	run := func(cmd *cobra.Command, stdin string, value ...string) string {
+		t.Helper()
+		var out bytes.Buffer
+		cmd.SetIn(strings.NewReader(stdin))
+		cmd.SetOut(&out)
+		defer cmd.SetIn(nil)
+		defer cmd.SetOut(nil)
+		cryptoValue = ""
+		cmd.Flags().Lookup("value").Changed = len(value) > 0
+		if len(value) > 0 {
+			cryptoValue = value[0]
+		}
+		if err := cmd.RunE(cmd, nil); err != nil {
+			t.Fatalf("%s error = %v", cmd.Name(), err)
+		}
+		return strings.TrimSpace(out.String())
+	}
+
+	encrypted := run(cryptoEncryptCmd, "", "hunter2")
+	if !crypto.IsEncrypted(encrypted) {
+		t.Fatalf("encrypt printed %q, want an e2e:: value", encrypted)
+	}
+	if got := run(cryptoDecryptCmd, encrypted+"\n"); got != "hunter2" {
+		t.Errorf("decrypt printed %q, want hunter2", got)
+	}
+
+	cryptoDecryptCmd.Flags().Set("value", "plain")
+	t.Cleanup(func() {
+		cryptoValue = ""
+		cryptoDecryptCmd.Flags().Lookup("value").Changed = false
+	})
+	if err := cryptoDecryptCmd.RunE(cryptoDecryptCmd, nil); err == nil || !strings.Contains(err.Error(), "not encrypted") {
+		t.Errorf("decrypt of a plain value error = %v", err)
+	}
+}
+
+// TestSetupEncryption verifies that setup registers a key derived from the
+// chosen PIN and stores it, and refuses an account already set up.
+func TestSetupEncryption(t *testing.T) {
+	tmpDir, cleanup := withTempHome(t)
+	defer cleanup()
+
+	server := sundaytest.NewServer(t)
+	server.ClearEncryption()
+	creds := server.Credentials()
+	saveTestConfig(t, tmpDir, &config.Config{AccessToken: creds.AccessToken})
+	client := api.NewClientForURL(server.URL, &config.Config{AccessToken: creds.AccessToken}, nil)
+
+	withPINs(t, "246810", "246810")
+	if err := setupEncryption(context.Background(), client); err != nil {
+		t.Fatalf("setupEncryption() error = %v", err)
+	}
+
+	meta, err := client.GetEncryptionMeta()
+	if err != nil {
+		t.Fatalf("GetEncryptionMeta() error = %v", err)
+	}
+	salt, _ := base64.StdEncoding.DecodeString(meta.Salt)
+	kp, err := crypto.DeriveKeyPair("246810", salt)
+	if err != nil {
+		t.Fatalf("DeriveKeyPair() error = %v", err)
+	}
+	if !crypto.Verify(kp, meta.Verifier) {
+		t.Error("server's key record doesn't match the chosen PIN")
+	}
+	stored, err := ensureKeyPair()
+	if err != nil || *stored != *kp {
+		t.Errorf("ensureKeyPair() = %v, %v; want the chosen PIN's key", stored, err)
+	}
+
+	withPINs(t)
+	if err := setupEncryption(context.Background(), client); err == nil || !strings.Contains(err.Error(), "already set up") {
+		t.Errorf("second setupEncryption() error = %v, want already set up", err)
+	}
+}
+
+// TestSetupEncryption_Passphrase verifies that setup follows a passphrase
+// policy advertised by the server, refusing a passphrase that is too short.
+func TestSetupEncryption_Passphrase(t *testing.T) {
+	tmpDir, cleanup := withTempHome(t)
+	defer cleanup()
+
+	server := sundaytest.NewServer(t)
+	server.ClearEncryption()
+	server.SetPINPolicy(sunday.PINPolicyMeta{Kind: "passphrase", MinLength: 12})
+	creds := server.Credentials()
+	saveTestConfig(t, tmpDir, &config.Config{AccessToken: creds.AccessToken})
+	client := api.NewClientForURL(server.URL, &config.Config{AccessToken: creds.AccessToken}, nil)
+
+	withPINs(t, "246810")
+	if err := setupEncryption(context.Background(), client); err == nil || !strings.Contains(err.Error(), "at least 12 characters") {
+		t.Fatalf("setupEncryption() error = %v, want the passphrase rejected", err)
+	}
+
+	const passphrase = "correct horse battery"
+	withPINs(t, passphrase, passphrase)
+	if err := setupEncryption(context.Background(), client); err != nil {
+		t.Fatalf("setupEncryption() error = %v", err)
+	}
+	meta, err := client.GetEncryptionMeta()
+	if err != nil {
+		t.Fatalf("GetEncryptionMeta() error = %v", err)
+	}
+	salt, _ := base64.StdEncoding.DecodeString(meta.Salt)
+	kp, err := crypto.DeriveKeyPair(passphrase, salt)
+	if err != nil {
+		t.Fatalf("DeriveKeyPair() error = %v", err)
+	}
+	if !crypto.Verify(kp, meta.Verifier) {
+		t.Error("server's key record doesn't match the chosen passphrase")
+	}
+}
+
+// TestKeyBackup verifies that a key exported to a backup file can be
+// imported on a machine without it, and that export won't overwrite a
+// file.
+func TestKeyBackup(t *testing.T) {
+	tmpDir, cleanup := withTempHome(t)
+	defer cleanup()
+
+	server := sundaytest.NewServer(t)
+	kp := (*crypto.KeyPair)(server.KeyPair())
+	creds := server.Credentials()
+	cfg := &config.Config{
+		AccessToken: creds.AccessToken,
+		PrivateKey:  base64.StdEncoding.EncodeToString(kp.PrivateKey[:]),
+		PublicKey:   base64.StdEncoding.EncodeToString(kp.PublicKey[:]),
+	}
+	saveTestConfig(t, tmpDir, cfg)
+	client := api.NewClientForURL(server.URL, &config.Config{AccessToken: creds.AccessToken}, nil)
+
+	orig := promptPassphrase
+	t.Cleanup(func() { promptPassphrase = orig })
+	promptPassphrase = func(string) (string, error) { return "correct horse battery", nil }
+
+	path := filepath.Join(t.TempDir(), "backup.sunday")
+	if err := exportKeyBackup(path); err != nil {
+		t.Fatalf("exportKeyBackup() error = %v", err)
+	}
+	if err := exportKeyBackup(path); err == nil {
+		t.Error("exportKeyBackup() overwrote an existing file")
+	}
+
+	saveTestConfig(t, tmpDir, &config.Config{AccessToken: creds.AccessToken})
+	if err := importKeyBackup(context.Background(), client, path); err != nil {
+		t.Fatalf("importKeyBackup() error = %v", err)
+	}
+	restored, err := ensureKeyPair()
+	if err != nil || *restored != *kp {
+		t.Errorf("ensureKeyPair() after import = %v, %v; want the backed up key", restored, err)
+	}
+}
+
+// TestKeyFingerprint verifies that the fingerprint is of the stored key,
+// that a key the server no longer has or a public key stored with another
+// private key is refused, and that another user's key can be
+// fingerprinted by email.
+func TestKeyFingerprint(t *testing.T) {
+	tmpDir, cleanup := withTempHome(t)
+	defer cleanup()
+
+	server := sundaytest.NewServer(t)
+	kp := (*crypto.KeyPair)(server.KeyPair())
+	privateKey := base64.StdEncoding.EncodeToString(kp.PrivateKey[:])
+	publicKey := base64.StdEncoding.EncodeToString(kp.PublicKey[:])
+	creds := server.Credentials()
+	saveTestConfig(t, tmpDir, &config.Config{AccessToken: creds.AccessToken, PrivateKey: privateKey, PublicKey: publicKey})
+	client := api.NewClientForURL(server.URL, &config.Config{AccessToken: creds.AccessToken}, nil)
+
+	want, _ := crypto.Fingerprint(publicKey)
+	if got, err := keyFingerprint(context.Background(), client, ""); err != nil || got != want {
+		t.Errorf("keyFingerprint() = %q, %v; want %q", got, err, want)
+	}
+
+	_, otherPriv, otherPub := deriveTestKeyPair(t)
+	server.AddUser("bob@example.com", otherPub)
+	wantBob, _ := crypto.Fingerprint(otherPub)
+	if got, err := keyFingerprint(context.Background(), client, "bob@example.com"); err != nil || got != wantBob {
+		t.Errorf("keyFingerprint(bob) = %q, %v; want %q", got, err, wantBob)
+	}
+
+	saveTestConfig(t, tmpDir, &config.Config{AccessToken: creds.AccessToken, PrivateKey: otherPriv, PublicKey: otherPub})
+	if _, err := keyFingerprint(context.Background(), client, ""); err == nil || !strings.Contains(err.Error(), "isn't the account's current key") {
+		t.Errorf("keyFingerprint() with a stale key error = %v", err)
+	}
+
+	saveTestConfig(t, tmpDir, &config.Config{AccessToken: creds.AccessToken, PrivateKey: otherPriv, PublicKey: publicKey})
+	if _, err := keyFingerprint(context.Background(), client, ""); err == nil || !strings.Contains(err.Error(), "doesn't match its public key") {
+		t.Errorf("keyFingerprint() with a mismatched keypair error = %v", err)
+	}
+}
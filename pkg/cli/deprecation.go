@@ -0,0 +1,129 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/ravi-technologies/sunday-cli/internal/config"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// Annotation keys used to mark commands and flags as deprecated.
+const (
+	annotationDeprecatedReplacement = "sunday.deprecated.replacement"
+	annotationDeprecatedRemovedIn   = "sunday.deprecated.removed-in"
+)
+
+// deprecationStateFile records which deprecation warnings have already been
+// shown, so each one is printed only once.
+const deprecationStateFile = "deprecations.json"
+
+// envNoDeprecationWarnings suppresses deprecation warnings entirely.
+const envNoDeprecationWarnings = "SUNDAY_NO_DEPRECATION_WARNINGS"
+
+// deprecateCommand marks cmd as deprecated. replacement tells users what to
+// use instead (may be empty) and removedIn is the version that will drop it.
+func deprecateCommand(cmd *cobra.Command, replacement, removedIn string) {
+	if cmd.Annotations == nil {
+		cmd.Annotations = map[string]string{}
+	}
+	cmd.Annotations[annotationDeprecatedReplacement] = replacement
+	cmd.Annotations[annotationDeprecatedRemovedIn] = removedIn
+	cmd.Short = "[deprecated] " + cmd.Short
+}
+
+// deprecateFlag marks the named local or persistent flag of cmd as deprecated.
+func deprecateFlag(cmd *cobra.Command, name, replacement, removedIn string) {
+	f := cmd.Flags().Lookup(name)
+	if f == nil {
+		f = cmd.PersistentFlags().Lookup(name)
+	}
+	if f == nil {
+		panic(fmt.Sprintf("deprecateFlag: no flag --%s on %q", name, cmd.CommandPath()))
+	}
+	if f.Annotations == nil {
+		f.Annotations = map[string][]string{}
+	}
+	f.Annotations[annotationDeprecatedReplacement] = []string{replacement}
+	f.Annotations[annotationDeprecatedRemovedIn] = []string{removedIn}
+	f.Usage += " (deprecated)"
+}
+
+// deprecationWarnings returns a warning for each deprecated command in cmd's
+// ancestry and each deprecated flag set on this invocation, keyed by a
+// stable identifier.
+func deprecationWarnings(cmd *cobra.Command) map[string]string {
+	warnings := map[string]string{}
+
+	for c := cmd; c != nil; c = c.Parent() {
+		removedIn, ok := c.Annotations[annotationDeprecatedRemovedIn]
+		if !ok {
+			continue
+		}
+		msg := fmt.Sprintf("%q is deprecated and will be removed in %s", c.CommandPath(), removedIn)
+		if r := c.Annotations[annotationDeprecatedReplacement]; r != "" {
+			msg += fmt.Sprintf("; use %s instead", r)
+		}
+		warnings["command:"+c.CommandPath()] = msg
+	}
+
+	cmd.Flags().Visit(func(f *pflag.Flag) {
+		removedIn, ok := f.Annotations[annotationDeprecatedRemovedIn]
+		if !ok {
+			return
+		}
+		msg := fmt.Sprintf("flag --%s is deprecated and will be removed in %s", f.Name, removedIn[0])
+		if r := f.Annotations[annotationDeprecatedReplacement]; len(r) > 0 && r[0] != "" {
+			msg += fmt.Sprintf("; use %s instead", r[0])
+		}
+		warnings["flag:"+cmd.CommandPath()+" --"+f.Name] = msg
+	})
+
+	return warnings
+}
+
+// warnDeprecated prints each deprecation warning for this invocation to
+// stderr the first time it is encountered. Warnings are never printed in
+// --json mode or when SUNDAY_NO_DEPRECATION_WARNINGS is set.
+func warnDeprecated(cmd *cobra.Command) {
+	if jsonOutput || os.Getenv(envNoDeprecationWarnings) != "" {
+		return
+	}
+
+	warnings := deprecationWarnings(cmd)
+	if len(warnings) == 0 {
+		return
+	}
+
+	statePath := filepath.Join(config.BaseDir(), deprecationStateFile)
+	shown := map[string]time.Time{}
+	if data, err := os.ReadFile(statePath); err == nil {
+		_ = json.Unmarshal(data, &shown)
+	}
+
+	yellow := color.New(color.FgYellow).SprintFunc()
+	changed := false
+	for key, msg := range warnings {
+		if _, ok := shown[key]; ok {
+			continue
+		}
+		fmt.Fprintln(cmd.ErrOrStderr(), yellow("Warning:"), msg)
+		shown[key] = time.Now()
+		changed = true
+	}
+
+	if !changed {
+		return
+	}
+	if _, err := config.EnsureDir(); err != nil {
+		return
+	}
+	if data, err := json.MarshalIndent(shown, "", "  "); err == nil {
+		_ = os.WriteFile(statePath, data, 0600)
+	}
+}
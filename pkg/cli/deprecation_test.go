@@ -0,0 +1,107 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+// newDeprecationTestTree builds a root with one deprecated subcommand and
+// one command carrying a deprecated flag.
+func newDeprecationTestTree() (root, oldCmd, newCmd *cobra.Command) {
+	root = &cobra.Command{Use: "sunday"}
+	oldCmd = &cobra.Command{Use: "old", Short: "Old command", Run: func(cmd *cobra.Command, args []string) {}}
+	newCmd = &cobra.Command{Use: "new", Short: "New command", Run: func(cmd *cobra.Command, args []string) {}}
+	newCmd.Flags().Bool("legacy", false, "Legacy behaviour")
+	newCmd.Flags().Bool("modern", false, "Modern behaviour")
+	root.AddCommand(oldCmd, newCmd)
+
+	deprecateCommand(oldCmd, "`sunday new`", "v2.0.0")
+	deprecateFlag(newCmd, "legacy", "--modern", "v2.0.0")
+	return root, oldCmd, newCmd
+}
+
+// TestDeprecationWarnings_Command verifies the warning for a deprecated command.
+func TestDeprecationWarnings_Command(t *testing.T) {
+	_, oldCmd, _ := newDeprecationTestTree()
+
+	warnings := deprecationWarnings(oldCmd)
+	msg, ok := warnings["command:sunday old"]
+	if !ok {
+		t.Fatalf("deprecationWarnings() = %v, want entry for sunday old", warnings)
+	}
+	if !strings.Contains(msg, "v2.0.0") || !strings.Contains(msg, "`sunday new`") {
+		t.Errorf("warning = %q, want removal version and replacement", msg)
+	}
+	if !strings.HasPrefix(oldCmd.Short, "[deprecated]") {
+		t.Errorf("Short = %q, want [deprecated] prefix", oldCmd.Short)
+	}
+}
+
+// TestDeprecationWarnings_FlagOnlyWhenSet verifies that deprecated flags
+// warn only when used.
+func TestDeprecationWarnings_FlagOnlyWhenSet(t *testing.T) {
+	_, _, newCmd := newDeprecationTestTree()
+
+	if w := deprecationWarnings(newCmd); len(w) != 0 {
+		t.Errorf("deprecationWarnings() without flag = %v, want none", w)
+	}
+
+	newCmd.Flags().Set("legacy", "true")
+	w := deprecationWarnings(newCmd)
+	if msg := w["flag:sunday new --legacy"]; !strings.Contains(msg, "--modern") {
+		t.Errorf("deprecationWarnings() = %v, want --legacy warning suggesting --modern", w)
+	}
+}
+
+// TestWarnDeprecated_OneTime verifies that each warning is printed once and
+// then remembered in the state file.
+func TestWarnDeprecated_OneTime(t *testing.T) {
+	_, cleanup := withTempHome(t)
+	defer cleanup()
+	os.Unsetenv(envNoDeprecationWarnings)
+
+	_, oldCmd, _ := newDeprecationTestTree()
+
+	stderr := &bytes.Buffer{}
+	oldCmd.SetErr(stderr)
+
+	warnDeprecated(oldCmd)
+	if !strings.Contains(stderr.String(), "deprecated") {
+		t.Fatalf("first run stderr = %q, want deprecation warning", stderr.String())
+	}
+
+	stderr.Reset()
+	warnDeprecated(oldCmd)
+	if stderr.Len() != 0 {
+		t.Errorf("second run stderr = %q, want no repeated warning", stderr.String())
+	}
+}
+
+// TestWarnDeprecated_Suppressed verifies that warnings are skipped in JSON
+// mode and when the suppression env var is set.
+func TestWarnDeprecated_Suppressed(t *testing.T) {
+	_, cleanup := withTempHome(t)
+	defer cleanup()
+
+	_, oldCmd, _ := newDeprecationTestTree()
+	stderr := &bytes.Buffer{}
+	oldCmd.SetErr(stderr)
+
+	t.Setenv(envNoDeprecationWarnings, "1")
+	warnDeprecated(oldCmd)
+	if stderr.Len() != 0 {
+		t.Errorf("stderr with %s = %q, want empty", envNoDeprecationWarnings, stderr.String())
+	}
+	os.Unsetenv(envNoDeprecationWarnings)
+
+	jsonOutput = true
+	defer func() { jsonOutput = false }()
+	warnDeprecated(oldCmd)
+	if stderr.Len() != 0 {
+		t.Errorf("stderr in --json mode = %q, want empty", stderr.String())
+	}
+}
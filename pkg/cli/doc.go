@@ -1,9 +1,15 @@
 // Package cli defines the Cobra command structure for the Sunday CLI.
 //
 // Commands are organized hierarchically:
-//   - root: Base command with global flags (--json)
-//   - auth: Authentication subcommands (login, logout, status)
-//   - inbox: Message viewing subcommands (list, email, sms)
+//   - root: Base command with global flags (--json, --har, --config, --no-cache, --offline, --retries, --retry-delay, --timeout, --api-url, --no-pager, --timing, --debug, --pin-stdin, --pin-file, --profile, --account)
+//   - auth: Authentication subcommands (login, logout, status, whoami, token, refresh, sessions, accounts)
+//   - identity: Identity selection (list, switch)
+//   - inbox: Message viewing subcommands (list, email, sms, watch)
+//   - contacts: Local contact book (list, add, remove)
+//   - profile: Named profiles (list, create, switch)
+//   - pin: Encryption PIN management (change)
+//   - crypto: Encryption key management (setup, rotate, backup, encrypt, decrypt, fingerprint)
+//   - doctor: Config, proxy and API connectivity checks
 //
 // All commands respect the --json flag for machine-parseable output
 // and use the output package formatters for consistent display.
@@ -0,0 +1,113 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ravi-technologies/sunday-cli/internal/api"
+	"github.com/ravi-technologies/sunday-cli/internal/config"
+	"github.com/ravi-technologies/sunday-cli/internal/output"
+	"github.com/spf13/cobra"
+)
+
+// doctorCheck is the outcome of one doctor check.
+type doctorCheck struct {
+	Name   string `json:"name"`
+	OK     bool   `json:"ok"`
+	Detail string `json:"detail"`
+}
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check the config and the connection to the API",
+	Long: `Check that the config can be read, that the proxy in use (from
+api.proxy_url, or HTTPS_PROXY and HTTP_PROXY) accepts connections, and
+that the API is reachable through it.
+
+The exit status is 1 if any check fails.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		checks := runDoctor(cmd.Context())
+
+		failed := 0
+		for _, c := range checks {
+			if !c.OK {
+				failed++
+			}
+		}
+
+		if jsonOutput {
+			if err := output.Current.Print(checks); err != nil {
+				return err
+			}
+		} else {
+			rows := make([][]string, len(checks))
+			for i, c := range checks {
+				status := "ok"
+				if !c.OK {
+					status = "FAIL"
+				}
+				rows[i] = []string{c.Name, status, c.Detail}
+			}
+			output.Current.PrintTable([]string{"CHECK", "STATUS", "DETAIL"}, rows)
+		}
+
+		if failed > 0 {
+			return fmt.Errorf("%d of %d checks failed", failed, len(checks))
+		}
+		return nil
+	},
+}
+
+// runDoctor runs the doctor checks in order, skipping those that depend
+// on one that failed.
+func runDoctor(ctx context.Context) []doctorCheck {
+	cfg, err := config.Load()
+	if err != nil {
+		return []doctorCheck{{Name: "config", Detail: err.Error()}}
+	}
+	checks := []doctorCheck{{Name: "config", OK: true, Detail: config.Path()}}
+
+	baseURL, err := api.BaseURL(cfg)
+	if err != nil {
+		return append(checks, doctorCheck{Name: "api", Detail: err.Error()})
+	}
+
+	proxy := doctorProxy(ctx, cfg, baseURL)
+	checks = append(checks, proxy)
+	if !proxy.OK {
+		return checks
+	}
+
+	client, err := api.NewClient(nil)
+	if err == nil {
+		err = client.PingContext(ctx)
+	}
+	if err != nil {
+		return append(checks, doctorCheck{Name: "api", Detail: err.Error()})
+	}
+	detail := baseURL + " is reachable"
+	if info, err := client.GetServerInfoContext(ctx); err == nil && info.Version != "" {
+		detail += " (server " + info.Version + ")"
+	}
+	return append(checks, doctorCheck{Name: "api", OK: true, Detail: detail})
+}
+
+// doctorProxy checks the proxy that requests to baseURL go through.
+func doctorProxy(ctx context.Context, cfg *config.Config, baseURL string) doctorCheck {
+	proxy, err := api.ProxyFor(cfg, baseURL)
+	if err != nil {
+		return doctorCheck{Name: "proxy", Detail: err.Error()}
+	}
+	if proxy == nil {
+		return doctorCheck{Name: "proxy", OK: true, Detail: "none (direct connection)"}
+	}
+	if err := api.DialProxy(ctx, proxy); err != nil {
+		return doctorCheck{Name: "proxy", Detail: err.Error()}
+	}
+	return doctorCheck{Name: "proxy", OK: true, Detail: proxy.Redacted() + " is reachable"}
+}
+
+func init() {
+	rootCmd.AddCommand(doctorCmd)
+}
@@ -0,0 +1,70 @@
+package cli
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ravi-technologies/sunday-cli/internal/config"
+	"github.com/ravi-technologies/sunday-cli/internal/version"
+)
+
+// TestRunDoctor verifies the doctor checks with no proxy, a reachable
+// proxy and an unreachable one.
+func TestRunDoctor(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	// A port nothing listens on.
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	closedAddr := ln.Addr().String()
+	ln.Close()
+
+	origURL := version.APIBaseURL
+	version.APIBaseURL = server.URL
+	defer func() { version.APIBaseURL = origURL }()
+
+	tests := []struct {
+		name     string
+		proxyURL string
+		want     []string // "name=ok" or "name=FAIL"
+		detail   string
+	}{
+		{name: "direct", want: []string{"config=ok", "proxy=ok", "api=ok"}, detail: "direct connection"},
+		// The API server answers proxied requests too.
+		{name: "reachable proxy", proxyURL: server.URL, want: []string{"config=ok", "proxy=ok", "api=ok"}, detail: "is reachable"},
+		{name: "unreachable proxy", proxyURL: "socks5://" + closedAddr, want: []string{"config=ok", "proxy=FAIL"}, detail: "unreachable"},
+		{name: "invalid proxy", proxyURL: "ftp://proxy", want: []string{"config=ok", "proxy=FAIL"}, detail: "invalid api.proxy_url"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir, cleanup := withTempHome(t)
+			defer cleanup()
+			saveTestConfig(t, tmpDir, &config.Config{API: config.APISettings{ProxyURL: tt.proxyURL}})
+
+			checks := runDoctor(context.Background())
+			var got []string
+			for _, c := range checks {
+				status := "ok"
+				if !c.OK {
+					status = "FAIL"
+				}
+				got = append(got, c.Name+"="+status)
+			}
+			if strings.Join(got, " ") != strings.Join(tt.want, " ") {
+				t.Fatalf("checks = %v, want %v (%+v)", got, tt.want, checks)
+			}
+			if !strings.Contains(checks[1].Detail, tt.detail) {
+				t.Errorf("proxy detail = %q, want it to contain %q", checks[1].Detail, tt.detail)
+			}
+		})
+	}
+}
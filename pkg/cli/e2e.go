@@ -2,36 +2,81 @@ package cli
 
 import (
 	"encoding/base64"
+	"errors"
 	"fmt"
+	"log/slog"
 	"os"
+	"time"
 
+	"github.com/ravi-technologies/sunday-cli/internal/api"
 	"github.com/ravi-technologies/sunday-cli/internal/config"
 	"github.com/ravi-technologies/sunday-cli/internal/crypto"
 )
 
+// Sentinel errors returned when the decryption keypair is unavailable. The
+// remediation hints for these live in hints.go.
+var (
+	errNotAuthenticated   = errors.New("not authenticated")
+	errEncryptionNotSetUp = errors.New("encryption not set up")
+	errAPIKeyNoKeyPair    = errors.New("no decryption key for this API key session")
+	errKeyLocked          = errors.New("encryption key locked after inactivity")
+)
+
+// keyUseResolution is how out of date KeyUsedAt may get before a command
+// using the key records the time again, so that not every command
+// rewrites the config file.
+const keyUseResolution = time.Minute
+
 // ensureKeyPair loads the persisted decryption keypair from the config file.
 // The private key is stored during login (after PIN verification) so that
-// subsequent commands never need to re-prompt for the PIN.
+// subsequent commands never need to re-prompt for the PIN, unless
+// crypto.unlock_ttl locks it after inactivity.
 func ensureKeyPair() (*crypto.KeyPair, error) {
 	cfg, err := config.Load()
 	if err != nil {
 		return nil, fmt.Errorf("loading config: %w", err)
 	}
 
+	// config.Load wiped the key if crypto.unlock_ttl expired it.
+	if _, err := cfg.Crypto.UnlockTTLDuration(); err != nil {
+		return nil, err
+	}
+	if cfg.PrivateKey == "" && cfg.PublicKey != "" && cfg.AccessToken != "" && api.APIKeyFromEnv() == "" {
+		if err := unlockKeyPair(cfg); err != nil {
+			return nil, err
+		}
+	}
+
 	if cfg.PrivateKey == "" || cfg.PublicKey == "" {
+		if api.APIKeyFromEnv() != "" {
+			return nil, errAPIKeyNoKeyPair
+		}
 		if cfg.AccessToken != "" {
-			return nil, fmt.Errorf("encryption not set up — complete PIN setup on the dashboard first")
+			return nil, errEncryptionNotSetUp
 		}
-		return nil, fmt.Errorf("not authenticated — run `sunday auth login` first")
+		return nil, errNotAuthenticated
 	}
+	touchKey(cfg, now())
 
-	privBytes, err := base64.StdEncoding.DecodeString(cfg.PrivateKey)
+	protector, err := keyProtector(cfg)
 	if err != nil {
-		return nil, fmt.Errorf("decoding private key: %w", err)
+		return nil, err
+	}
+	stored := crypto.ProtectorFor(cfg.PrivateKey)
+	if _, ok := stored.(crypto.TouchIDProtector); ok {
+		// Reading the Keychain item shows the Touch ID prompt, whatever
+		// the config file says.
+		touchIDVerified = true
+	} else if err := requireTouchID(cfg, config.TouchIDLoadPrivateKey, "unlock your encryption key"); err != nil {
+		// The key predates the setting; it is moved to the Keychain below.
+		return nil, err
 	}
-	if len(privBytes) != 32 {
-		return nil, fmt.Errorf("private key has invalid length %d, expected 32", len(privBytes))
+	priv, err := stored.Unwrap(cfg.PrivateKey)
+	if err != nil {
+		touchIDVerified = false
+		return nil, err
 	}
+	defer crypto.Wipe(priv[:])
 
 	pubBytes, err := base64.StdEncoding.DecodeString(cfg.PublicKey)
 	if err != nil {
@@ -41,13 +86,117 @@ func ensureKeyPair() (*crypto.KeyPair, error) {
 		return nil, fmt.Errorf("public key has invalid length %d, expected 32", len(pubBytes))
 	}
 
-	var kp crypto.KeyPair
-	copy(kp.PrivateKey[:], privBytes)
+	kp := crypto.KeyPair{PrivateKey: priv}
 	copy(kp.PublicKey[:], pubBytes)
 
+	if stored != protector {
+		// crypto.key_protector has changed since the key was stored.
+		if err := storePrivateKey(cfg, kp.PrivateKey); err != nil {
+			return nil, err
+		}
+	}
 	return &kp, nil
 }
 
+// keyProtector returns the protector cfg stores the private key with.
+func keyProtector(cfg *config.Config) (crypto.KeyProtector, error) {
+	name, err := cfg.KeyProtectorName()
+	if err != nil {
+		return nil, err
+	}
+	return crypto.NewKeyProtector(name, cfg.Crypto.YubiKeySlot)
+}
+
+// storePrivateKey wraps priv with cfg's key protector, saves it in cfg and
+// then discards the key it replaces, such as its Keychain item.
+func storePrivateKey(cfg *config.Config, priv [32]byte) error {
+	protector, err := keyProtector(cfg)
+	if err != nil {
+		return err
+	}
+	wrapped, err := protector.Wrap(priv)
+	if err != nil {
+		return err
+	}
+	old := cfg.PrivateKey
+	cfg.PrivateKey = wrapped
+	if err := config.Save(cfg); err != nil {
+		return err
+	}
+	discardPrivateKey(old)
+	return nil
+}
+
+// discardPrivateKey deletes whatever holds a private key that is no longer
+// stored. Failing to is no reason to fail the command.
+func discardPrivateKey(stored string) {
+	if err := crypto.DiscardWrapped(stored); err != nil {
+		slog.Warn("deleting stored private key", "error", err)
+	}
+}
+
+// lockedKey forgets a private key config.Load wiped for going unused
+// longer than crypto.unlock_ttl, as config.OnKeyLocked.
+func lockedKey(stored string) {
+	crypto.ClearCachedKeyPair()
+	discardPrivateKey(stored)
+}
+
+// unlockKeyPair prompts for the PIN to unlock a locked key, and stores
+// the private key again.
+func unlockKeyPair(cfg *config.Config) error {
+	client, err := api.NewClient(cfg)
+	if err != nil {
+		return err
+	}
+	meta, err := client.GetEncryptionMeta()
+	if err != nil {
+		return fmt.Errorf("fetching encryption metadata: %w", err)
+	}
+	params, err := crypto.NewKDFParams(meta.KDF.Algorithm, meta.KDF.OpsLimit, meta.KDF.MemLimit)
+	if err != nil {
+		return fmt.Errorf("rejecting server key derivation parameters: %w", err)
+	}
+	policy, err := crypto.NewPINPolicy(meta.PINPolicy.Kind, meta.PINPolicy.MinLength)
+	if err != nil {
+		return fmt.Errorf("rejecting server PIN policy: %w", err)
+	}
+	if crypto.UsesManagedKey(meta.ManagedMasterKey, meta.PublicKey, meta.Verifier) {
+		return fmt.Errorf("%w: %w", errKeyLocked, crypto.ErrManagedKey)
+	}
+	kp, err := crypto.GetOrPromptKeyPair(meta.Salt, meta.Verifier, params, policy)
+	if err != nil {
+		return fmt.Errorf("%w: %w", errKeyLocked, err)
+	}
+	if base64.StdEncoding.EncodeToString(kp.PublicKey[:]) != cfg.PublicKey {
+		return fmt.Errorf("derived public key does not match the stored one; run `sunday auth login` again")
+	}
+	// Unlocking updated the PIN lockout on disk; don't write back the one
+	// cfg was loaded with.
+	if onDisk, err := config.Load(); err == nil {
+		cfg.PINLockout = onDisk.PINLockout
+	}
+
+	cfg.KeyUsedAt = now()
+	return storePrivateKey(cfg, kp.PrivateKey)
+}
+
+// touchKey records that the key was used at t, for crypto.unlock_ttl.
+// Failing to is no reason to fail the command; the key just locks sooner.
+func touchKey(cfg *config.Config, t time.Time) {
+	if cfg.Crypto.UnlockTTL == "" || t.Sub(cfg.KeyUsedAt) < keyUseResolution {
+		return
+	}
+	cfg.KeyUsedAt = t
+	err := config.Update(func(onDisk *config.Config) error {
+		onDisk.KeyUsedAt = t
+		return nil
+	})
+	if err != nil {
+		slog.Warn("recording encryption key use", "error", err)
+	}
+}
+
 // tryDecrypt attempts to decrypt an E2E-encrypted field. If the value is not
 // encrypted it is returned as-is. On decryption failure a warning is printed
 // to stderr and the original (encrypted) value is returned so the caller
@@ -60,3 +209,16 @@ func tryDecrypt(value string, kp *crypto.KeyPair) string {
 	}
 	return result
 }
+
+// tryDecryptAll decrypts the E2E-encrypted fields in place, in parallel,
+// warning like tryDecrypt about those that can't be and leaving them as
+// they are.
+func tryDecryptAll(fields []*string, kp *crypto.KeyPair) {
+	for _, err := range crypto.DecryptFields(fields, kp) {
+		fmt.Fprintf(os.Stderr, "Warning: could not decrypt field: %v\n", err)
+	}
+}
+
+func init() {
+	config.OnKeyLocked = lockedKey
+}
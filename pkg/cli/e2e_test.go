@@ -4,13 +4,17 @@ import (
 	"crypto/rand"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/ravi-technologies/sunday-cli/internal/api"
 	"github.com/ravi-technologies/sunday-cli/internal/config"
 	"github.com/ravi-technologies/sunday-cli/internal/crypto"
+	"github.com/ravi-technologies/sunday-cli/pkg/sundaytest"
 	"golang.org/x/crypto/nacl/box"
 )
 
@@ -289,3 +293,70 @@ func TestEnsureKeyPair_BothKeysInvalid(t *testing.T) {
 		t.Errorf("ensureKeyPair() error = %v, want error containing 'decoding private key'", err)
 	}
 }
+
+// TestEnsureKeyPair_LocksWhenIdle verifies that a key unused for longer
+// than crypto.unlock_ttl is wiped from the config, and that unlocking it
+// again needs the PIN.
+func TestEnsureKeyPair_LocksWhenIdle(t *testing.T) {
+	tmpDir, cleanup := withTempHome(t)
+	defer cleanup()
+
+	server := sundaytest.NewServer(t)
+	t.Setenv(api.EnvAPIURL, server.URL)
+	kp := (*crypto.KeyPair)(server.KeyPair())
+	saveTestConfig(t, tmpDir, &config.Config{
+		AccessToken: server.Credentials().AccessToken,
+		PrivateKey:  base64.StdEncoding.EncodeToString(kp.PrivateKey[:]),
+		PublicKey:   base64.StdEncoding.EncodeToString(kp.PublicKey[:]),
+		KeyUsedAt:   time.Now().Add(-2 * time.Hour),
+		Crypto:      config.CryptoSettings{UnlockTTL: "1h"},
+	})
+
+	// Tests have no terminal to enter the PIN at.
+	_, err := ensureKeyPair()
+	if !errors.Is(err, errKeyLocked) {
+		t.Fatalf("ensureKeyPair() error = %v, want errKeyLocked", err)
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("config.Load() error = %v", err)
+	}
+	if cfg.PrivateKey != "" || cfg.PublicKey == "" {
+		t.Errorf("after locking, private key = %q, public key = %q; want only the public key", cfg.PrivateKey, cfg.PublicKey)
+	}
+}
+
+// TestEnsureKeyPair_UnlockTTL verifies that a key in use stays unlocked
+// and its use is recorded, and that a malformed TTL is reported.
+func TestEnsureKeyPair_UnlockTTL(t *testing.T) {
+	tmpDir, cleanup := withTempHome(t)
+	defer cleanup()
+
+	_, privB64, pubB64 := deriveTestKeyPair(t)
+	usedAt := time.Now().Add(-30 * time.Minute)
+	saveTestConfig(t, tmpDir, &config.Config{
+		PrivateKey: privB64,
+		PublicKey:  pubB64,
+		KeyUsedAt:  usedAt,
+		Crypto:     config.CryptoSettings{UnlockTTL: "1h"},
+	})
+
+	if _, err := ensureKeyPair(); err != nil {
+		t.Fatalf("ensureKeyPair() error = %v", err)
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("config.Load() error = %v", err)
+	}
+	if !cfg.KeyUsedAt.After(usedAt) {
+		t.Errorf("KeyUsedAt = %v, want it updated", cfg.KeyUsedAt)
+	}
+
+	cfg.Crypto.UnlockTTL = "soon"
+	if err := config.Save(cfg); err != nil {
+		t.Fatalf("config.Save() error = %v", err)
+	}
+	if _, err := ensureKeyPair(); err == nil || !strings.Contains(err.Error(), "invalid crypto.unlock_ttl") {
+		t.Errorf("ensureKeyPair() error = %v, want invalid crypto.unlock_ttl", err)
+	}
+}
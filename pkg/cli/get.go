@@ -22,7 +22,7 @@ var getPhoneCmd = &cobra.Command{
 			return err
 		}
 
-		phone, err := client.GetPhone()
+		phone, err := client.GetPhoneContext(cmd.Context())
 		if err != nil {
 			return err
 		}
@@ -42,7 +42,7 @@ var getOwnerCmd = &cobra.Command{
 			return err
 		}
 
-		owner, err := client.GetOwner()
+		owner, err := client.GetOwnerContext(cmd.Context())
 		if err != nil {
 			return err
 		}
@@ -62,7 +62,7 @@ var getEmailCmd = &cobra.Command{
 			return err
 		}
 
-		email, err := client.GetEmail()
+		email, err := client.GetEmailContext(cmd.Context())
 		if err != nil {
 			return err
 		}
@@ -0,0 +1,123 @@
+package cli
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ravi-technologies/sunday-cli/internal/output"
+	"github.com/spf13/cobra"
+)
+
+// Values accepted by --group-by.
+const (
+	groupByDay    = "day"
+	groupByWeek   = "week"
+	groupBySender = "sender"
+)
+
+// groupBy splits human table output into sections. It has no effect on
+// --json output.
+var groupBy string
+
+// now is time.Now, replaceable in tests.
+var now = time.Now
+
+// addGroupByFlag adds --group-by to a listing command.
+func addGroupByFlag(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&groupBy, "group-by", "", "Group rows under headers by day, week, or sender")
+}
+
+// validateGroupBy checks the --group-by value.
+func validateGroupBy() error {
+	switch groupBy {
+	case "", groupByDay, groupByWeek, groupBySender:
+		return nil
+	}
+	return fmt.Errorf("invalid --group-by %q: must be day, week, or sender", groupBy)
+}
+
+// groupedRow is a table row with the values it can be grouped by.
+type groupedRow struct {
+	cells  []string
+	date   time.Time
+	sender string
+}
+
+// printGroupedTable prints rows as a table, split into sections when
+// --group-by is set. Rows are expected newest first; sections keep the
+// order in which they first appear.
+func printGroupedTable(headers []string, rows []groupedRow) {
+	if groupBy == "" {
+		cells := make([][]string, len(rows))
+		for i, r := range rows {
+			cells[i] = r.cells
+		}
+		output.Current.PrintTable(headers, cells)
+		return
+	}
+
+	var sections []output.TableSection
+	index := map[string]int{}
+	today := now()
+	for _, r := range rows {
+		var title string
+		switch groupBy {
+		case groupByDay:
+			title = dayLabel(today, r.date)
+		case groupByWeek:
+			title = weekLabel(today, r.date)
+		case groupBySender:
+			title = r.sender
+		}
+		i, ok := index[title]
+		if !ok {
+			i = len(sections)
+			index[title] = i
+			sections = append(sections, output.TableSection{Title: title})
+		}
+		sections[i].Rows = append(sections[i].Rows, r.cells)
+	}
+	output.Current.PrintSections(headers, sections)
+}
+
+// startOfDay returns midnight at the start of t's day in local time.
+func startOfDay(t time.Time) time.Time {
+	y, m, d := t.Local().Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.Local)
+}
+
+// dayLabel names t's day relative to today: "Today", "Yesterday", the
+// weekday within the last week, or the date.
+func dayLabel(today, t time.Time) string {
+	days := int(startOfDay(today).Sub(startOfDay(t)).Hours()+12) / 24
+	switch {
+	case days == 0:
+		return "Today"
+	case days == 1:
+		return "Yesterday"
+	case days > 1 && days < 7:
+		return t.Local().Weekday().String()
+	}
+	return t.Local().Format("Mon, Jan 02 2006")
+}
+
+// startOfWeek returns midnight on the Monday of t's week in local time.
+func startOfWeek(t time.Time) time.Time {
+	day := startOfDay(t)
+	offset := (int(day.Weekday()) + 6) % 7 // days since Monday
+	return day.AddDate(0, 0, -offset)
+}
+
+// weekLabel names t's week relative to today: "This week", "Last week", or
+// the date of the week's Monday.
+func weekLabel(today, t time.Time) string {
+	this := startOfWeek(today)
+	week := startOfWeek(t)
+	switch {
+	case week.Equal(this):
+		return "This week"
+	case week.Equal(this.AddDate(0, 0, -7)):
+		return "Last week"
+	}
+	return "Week of " + week.Format("Jan 02, 2006")
+}
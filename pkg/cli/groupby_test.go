@@ -0,0 +1,105 @@
+package cli
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ravi-technologies/sunday-cli/internal/output"
+)
+
+// TestDayLabel verifies relative day section titles.
+func TestDayLabel(t *testing.T) {
+	today := time.Date(2026, 3, 12, 9, 0, 0, 0, time.Local) // Thursday
+
+	tests := []struct {
+		date time.Time
+		want string
+	}{
+		{time.Date(2026, 3, 12, 0, 5, 0, 0, time.Local), "Today"},
+		{time.Date(2026, 3, 11, 23, 59, 0, 0, time.Local), "Yesterday"},
+		{time.Date(2026, 3, 9, 12, 0, 0, 0, time.Local), "Monday"},
+		{time.Date(2026, 3, 5, 12, 0, 0, 0, time.Local), "Thu, Mar 05 2026"},
+	}
+	for _, tt := range tests {
+		if got := dayLabel(today, tt.date); got != tt.want {
+			t.Errorf("dayLabel(%v) = %q, want %q", tt.date, got, tt.want)
+		}
+	}
+}
+
+// TestWeekLabel verifies relative week section titles, with weeks starting
+// on Monday.
+func TestWeekLabel(t *testing.T) {
+	today := time.Date(2026, 3, 15, 9, 0, 0, 0, time.Local) // Sunday
+
+	tests := []struct {
+		date time.Time
+		want string
+	}{
+		{time.Date(2026, 3, 9, 0, 0, 0, 0, time.Local), "This week"},
+		{time.Date(2026, 3, 8, 23, 0, 0, 0, time.Local), "Last week"},
+		{time.Date(2026, 3, 2, 8, 0, 0, 0, time.Local), "Last week"},
+		{time.Date(2026, 2, 28, 8, 0, 0, 0, time.Local), "Week of Feb 23, 2026"},
+	}
+	for _, tt := range tests {
+		if got := weekLabel(today, tt.date); got != tt.want {
+			t.Errorf("weekLabel(%v) = %q, want %q", tt.date, got, tt.want)
+		}
+	}
+}
+
+// TestValidateGroupBy verifies that only known --group-by values are
+// accepted.
+func TestValidateGroupBy(t *testing.T) {
+	t.Cleanup(func() { groupBy = "" })
+
+	for _, value := range []string{"", "day", "week", "sender"} {
+		groupBy = value
+		if err := validateGroupBy(); err != nil {
+			t.Errorf("validateGroupBy(%q) error = %v", value, err)
+		}
+	}
+	groupBy = "month"
+	if err := validateGroupBy(); err == nil {
+		t.Error("validateGroupBy(\"month\") returned nil error")
+	}
+}
+
+// sectionRecorder is an output.Formatter that records PrintSections calls.
+type sectionRecorder struct {
+	output.HumanFormatter
+	sections []output.TableSection
+}
+
+func (r *sectionRecorder) PrintSections(headers []string, sections []output.TableSection) {
+	r.sections = sections
+}
+
+// TestPrintGroupedTable_BySender verifies that rows are grouped in the order
+// their section first appears.
+func TestPrintGroupedTable_BySender(t *testing.T) {
+	rec := &sectionRecorder{}
+	origFormatter := output.Current
+	output.Current = rec
+	groupBy = groupBySender
+	t.Cleanup(func() {
+		output.Current = origFormatter
+		groupBy = ""
+	})
+
+	printGroupedTable([]string{"ID"}, []groupedRow{
+		{cells: []string{"1"}, sender: "bob"},
+		{cells: []string{"2"}, sender: "alice"},
+		{cells: []string{"3"}, sender: "bob"},
+	})
+
+	if len(rec.sections) != 2 {
+		t.Fatalf("got %d sections, want 2", len(rec.sections))
+	}
+	if rec.sections[0].Title != "bob" || len(rec.sections[0].Rows) != 2 || rec.sections[0].Rows[1][0] != "3" {
+		t.Errorf("sections[0] = %+v, want bob with rows 1 and 3", rec.sections[0])
+	}
+	if rec.sections[1].Title != "alice" {
+		t.Errorf("sections[1].Title = %q, want alice", rec.sections[1].Title)
+	}
+}
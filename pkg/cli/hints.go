@@ -0,0 +1,87 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"syscall"
+
+	"github.com/ravi-technologies/sunday-cli/internal/api"
+	"github.com/ravi-technologies/sunday-cli/internal/config"
+	"github.com/ravi-technologies/sunday-cli/internal/output"
+)
+
+// hintedError attaches remediation guidance to an error. It implements
+// output.Hinter so formatters can render the hint separately.
+type hintedError struct {
+	err  error
+	hint string
+}
+
+func (e *hintedError) Error() string { return e.err.Error() }
+func (e *hintedError) Unwrap() error { return e.err }
+func (e *hintedError) Hint() string  { return e.hint }
+
+// Annotate wraps common failures with actionable remediation hints. Errors
+// that already carry a hint, or that match no known failure, are returned
+// unchanged.
+func Annotate(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var h output.Hinter
+	if errors.As(err, &h) {
+		return err
+	}
+
+	if hint := remediationHint(err); hint != "" {
+		return &hintedError{err: err, hint: hint}
+	}
+	return err
+}
+
+// remediationHint maps an error to a short suggestion for fixing it.
+func remediationHint(err error) string {
+	var dnsErr *net.DNSError
+	var netErr net.Error
+
+	switch {
+	case errors.Is(err, errNotAuthenticated):
+		return "Run `sunday auth login` to sign in."
+	case errors.Is(err, errEncryptionNotSetUp):
+		return "Run `sunday crypto setup` to choose a PIN, or complete PIN setup on the dashboard and run `sunday auth login` to unlock encryption."
+	case errors.Is(err, errKeyLocked):
+		return "Your encryption key locks after going unused for the crypto.unlock_ttl setting. Run the command in a terminal to enter your PIN and unlock it."
+	case errors.Is(err, api.ErrSessionExpired):
+		return "Your session has expired. Run `sunday auth login` to sign in again."
+	case errors.Is(err, errAPIKeyNoKeyPair):
+		return "An API key can call the API but not decrypt your data. Run `sunday auth login` once for this profile to store your encryption key; the API key is still used for requests."
+	case errors.Is(err, api.ErrUnauthorized) && api.APIKeyFromEnv() != "":
+		return "The API key in $SUNDAY_API_KEY was rejected. Check that it is correct and hasn't been revoked."
+	case errors.Is(err, api.ErrUnauthorized):
+		return "Your session is no longer valid. Run `sunday auth login` to sign in again."
+	case errors.Is(err, api.ErrOffline):
+		return "Only data fetched before can be shown with --offline. Drop --offline once you are back online."
+	case errors.Is(err, api.ErrCircuitOpen):
+		return "The Sunday API is failing repeatedly. Wait a moment before retrying."
+	case errors.As(err, &dnsErr):
+		return fmt.Sprintf("Could not resolve %s. Check your network connection and the API URL (%s).", dnsErr.Name, apiBaseURL())
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return fmt.Sprintf("Could not connect to the Sunday API. Check your network connection and the API URL (%s).", apiBaseURL())
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return "The request timed out. Check your network connection and try again."
+	}
+	return ""
+}
+
+// apiBaseURL returns the API URL requests go to, for hints about reaching
+// it.
+func apiBaseURL() string {
+	cfg, err := config.Load()
+	if err != nil {
+		cfg = nil
+	}
+	u, _ := api.BaseURL(cfg)
+	return u
+}
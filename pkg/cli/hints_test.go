@@ -0,0 +1,96 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/ravi-technologies/sunday-cli/internal/api"
+	"github.com/ravi-technologies/sunday-cli/internal/output"
+)
+
+// TestAnnotate_Hints verifies that common failures are mapped to remediation hints.
+func TestAnnotate_Hints(t *testing.T) {
+	t.Setenv(api.EnvAPIKey, "")
+
+	testCases := []struct {
+		name         string
+		err          error
+		wantContains string
+	}{
+		{
+			name:         "unauthorized API error",
+			err:          fmt.Errorf("token refresh failed: %w", &api.APIError{StatusCode: 401, Detail: "Token is invalid"}),
+			wantContains: "sunday auth login",
+		},
+		{
+			name:         "not authenticated",
+			err:          errNotAuthenticated,
+			wantContains: "sunday auth login",
+		},
+		{
+			name:         "encryption not set up",
+			err:          errEncryptionNotSetUp,
+			wantContains: "PIN setup",
+		},
+		{
+			name:         "expired session",
+			err:          fmt.Errorf("token refresh failed: %w", api.ErrSessionExpired),
+			wantContains: "session has expired",
+		},
+		{
+			name:         "API key without a decryption key",
+			err:          errAPIKeyNoKeyPair,
+			wantContains: "encryption key",
+		},
+		{
+			name:         "DNS failure",
+			err:          &net.DNSError{Name: "api.sunday.invalid", Err: "no such host", IsNotFound: true},
+			wantContains: "api.sunday.invalid",
+		},
+		{
+			name:         "circuit open",
+			err:          fmt.Errorf("%w after 5 consecutive failures", api.ErrCircuitOpen),
+			wantContains: "failing repeatedly",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			annotated := Annotate(tc.err)
+
+			var h output.Hinter
+			if !errors.As(annotated, &h) {
+				t.Fatalf("Annotate(%v) has no hint", tc.err)
+			}
+			if !strings.Contains(h.Hint(), tc.wantContains) {
+				t.Errorf("Hint() = %q, want containing %q", h.Hint(), tc.wantContains)
+			}
+			if annotated.Error() != tc.err.Error() {
+				t.Errorf("Error() = %q, want unchanged %q", annotated.Error(), tc.err.Error())
+			}
+			if !errors.Is(annotated, tc.err) {
+				t.Error("annotated error should wrap the original")
+			}
+		})
+	}
+}
+
+// TestAnnotate_NoHint verifies that unknown errors pass through unchanged.
+func TestAnnotate_NoHint(t *testing.T) {
+	err := errors.New("something unexpected")
+	if got := Annotate(err); got != err {
+		t.Errorf("Annotate() = %v, want original error", got)
+	}
+
+	if got := Annotate(nil); got != nil {
+		t.Errorf("Annotate(nil) = %v, want nil", got)
+	}
+
+	notFound := &api.APIError{StatusCode: 404, Detail: "Not found."}
+	if got := Annotate(notFound); got != error(notFound) {
+		t.Errorf("Annotate(404) = %v, want original error", got)
+	}
+}
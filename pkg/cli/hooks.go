@@ -0,0 +1,69 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+
+	"github.com/fatih/color"
+	"github.com/ravi-technologies/sunday-cli/internal/config"
+)
+
+// Hook events, passed to the hook as SUNDAY_HOOK_EVENT.
+const (
+	hookPostLogin  = "post_login"
+	hookPostLogout = "post_logout"
+)
+
+// hookCommand returns the process that runs a hook's shell command.
+// Tests replace it.
+var hookCommand = func(command string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		return exec.Command("cmd", "/C", command)
+	}
+	return exec.Command("/bin/sh", "-c", command)
+}
+
+// runHook runs the hook configured in cfg for event, if any. The hook gets
+// the session's user and identity in SUNDAY_USER_EMAIL, SUNDAY_IDENTITY
+// and SUNDAY_IDENTITY_UUID. Its output goes to w, keeping stdout clean for
+// --json. A failing hook is reported as a warning: the login or logout it
+// follows has already happened.
+func runHook(w io.Writer, event string, cfg *config.Config) {
+	command := cfg.Hooks.PostLogin
+	if event == hookPostLogout {
+		command = cfg.Hooks.PostLogout
+	}
+	if command == "" {
+		return
+	}
+
+	c := hookCommand(command)
+	c.Env = append(os.Environ(),
+		"SUNDAY_HOOK_EVENT="+event,
+		"SUNDAY_USER_EMAIL="+cfg.UserEmail,
+		"SUNDAY_IDENTITY="+cfg.IdentityName,
+		"SUNDAY_IDENTITY_UUID="+cfg.IdentityUUID,
+		config.EnvProfile+"="+config.ActiveProfile(),
+	)
+	if name := config.ActiveAccount(); name != "" {
+		c.Env = append(c.Env, config.EnvAccount+"="+name)
+	}
+	c.Stdout = w
+	c.Stderr = w
+	if err := c.Run(); err != nil {
+		yellow := color.New(color.FgYellow).SprintFunc()
+		fmt.Fprintf(w, "%s hooks.%s failed: %v\n", yellow("Warning:"), event, err)
+	}
+}
+
+// runLoginHook runs the post-login hook for the session just saved.
+func runLoginHook(w io.Writer) {
+	cfg, err := config.Load()
+	if err != nil {
+		return
+	}
+	runHook(w, hookPostLogin, cfg)
+}
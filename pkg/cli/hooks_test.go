@@ -0,0 +1,64 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/ravi-technologies/sunday-cli/internal/config"
+)
+
+// TestRunHook verifies that a hook runs with the session details in its
+// environment, and that only the hook for the event runs.
+func TestRunHook(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("hook test uses /bin/sh")
+	}
+	_, cleanup := withTempHome(t)
+	defer cleanup()
+
+	out := filepath.Join(t.TempDir(), "hook.out")
+	cfg := &config.Config{
+		UserEmail:    "me@example.com",
+		IdentityName: "Work",
+		IdentityUUID: "uuid-1",
+		Hooks: config.HookSettings{
+			PostLogin:  `echo "$SUNDAY_HOOK_EVENT $SUNDAY_USER_EMAIL $SUNDAY_IDENTITY $SUNDAY_IDENTITY_UUID" > ` + out,
+			PostLogout: "exit 3",
+		},
+	}
+
+	var stderr bytes.Buffer
+	runHook(&stderr, hookPostLogin, cfg)
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("post_login hook did not run: %v", err)
+	}
+	if got, want := strings.TrimSpace(string(data)), "post_login me@example.com Work uuid-1"; got != want {
+		t.Errorf("hook saw %q, want %q", got, want)
+	}
+	if stderr.Len() != 0 {
+		t.Errorf("successful hook wrote %q", stderr.String())
+	}
+
+	runHook(&stderr, hookPostLogout, cfg)
+	if !strings.Contains(stderr.String(), "hooks.post_logout failed") {
+		t.Errorf("failing hook output = %q, want a warning", stderr.String())
+	}
+}
+
+// TestRunHook_Unset verifies that nothing runs without a configured hook.
+func TestRunHook_Unset(t *testing.T) {
+	orig := hookCommand
+	defer func() { hookCommand = orig }()
+	hookCommand = func(string) *exec.Cmd {
+		t.Error("hook ran without being configured")
+		return orig("true")
+	}
+
+	runHook(&bytes.Buffer{}, hookPostLogin, &config.Config{})
+}
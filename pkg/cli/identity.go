@@ -0,0 +1,126 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/ravi-technologies/sunday-cli/internal/api"
+	"github.com/ravi-technologies/sunday-cli/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var identityCmd = &cobra.Command{
+	Use:   "identity",
+	Short: "List identities and switch between them",
+	Long: `List your identities and switch the one this CLI session is bound to.
+
+Each identity has its own Sunday email address and phone number. Switching
+rebinds the current session, so there is no need to log in again.`,
+}
+
+// identityRow is an identity as shown by identity list.
+type identityRow struct {
+	api.Identity
+	Current bool `json:"current"`
+}
+
+var identityListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List your identities",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := api.NewClient(nil)
+		if err != nil {
+			return err
+		}
+		if !client.IsAuthenticated() {
+			return errNotAuthenticated
+		}
+
+		identities, err := client.ListIdentitiesContext(cmd.Context())
+		if err != nil {
+			return err
+		}
+
+		rows := make([]identityRow, len(identities))
+		for i, id := range identities {
+			rows[i] = identityRow{Identity: id, Current: isCurrentIdentity(client, id)}
+		}
+
+		if jsonOutput {
+			return output.Current.Print(rows)
+		}
+
+		if len(rows) == 0 {
+			output.Current.PrintMessage("No identities found")
+			return nil
+		}
+
+		headers := []string{"", "UUID", "NAME", "EMAIL", "PHONE"}
+		table := make([][]string, len(rows))
+		for i, r := range rows {
+			marker := ""
+			if r.Current {
+				marker = "*"
+			}
+			table[i] = []string{marker, r.UUID, truncate(r.Name, 25), r.SundayEmail, r.SundayPhone}
+		}
+		output.Current.PrintTable(headers, table)
+		return nil
+	},
+}
+
+var identitySwitchCmd = &cobra.Command{
+	Use:   "switch <uuid>",
+	Short: "Bind this session to another identity",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := api.NewClient(nil)
+		if err != nil {
+			return err
+		}
+		if !client.IsAuthenticated() {
+			return errNotAuthenticated
+		}
+
+		identities, err := client.ListIdentitiesContext(cmd.Context())
+		if err != nil {
+			return err
+		}
+		var target *api.Identity
+		for i := range identities {
+			if identities[i].UUID == args[0] {
+				target = &identities[i]
+			}
+		}
+		if target == nil {
+			return fmt.Errorf("no identity with UUID %q (see `sunday identity list`)", args[0])
+		}
+
+		if err := client.SwitchIdentityContext(cmd.Context(), *target); err != nil {
+			return err
+		}
+
+		if jsonOutput {
+			return output.Current.Print(map[string]string{"status": "switched", "identity": target.UUID, "name": target.Name})
+		}
+
+		fmt.Printf("Switched to identity: %s\n", identityLabel(*target))
+		return nil
+	},
+}
+
+// isCurrentIdentity reports whether id is the identity the client's
+// session is bound to. Configs saved before the UUID was recorded only
+// have the name to go on.
+func isCurrentIdentity(client *api.Client, id api.Identity) bool {
+	if uuid := client.GetIdentityUUID(); uuid != "" {
+		return uuid == id.UUID
+	}
+	return client.GetIdentityName() != "" && client.GetIdentityName() == id.Name
+}
+
+func init() {
+	identityCmd.AddCommand(identityListCmd)
+	identityCmd.AddCommand(identitySwitchCmd)
+	rootCmd.AddCommand(identityCmd)
+}
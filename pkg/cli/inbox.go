@@ -1,9 +1,16 @@
 package cli
 
 import (
+	"strings"
+
+	"github.com/ravi-technologies/sunday-cli/internal/contacts"
+	"github.com/ravi-technologies/sunday-cli/internal/phone"
 	"github.com/spf13/cobra"
 )
 
+// inboxWide shows raw addresses and numbers next to contact names.
+var inboxWide bool
+
 var inboxCmd = &cobra.Command{
 	Use:   "inbox",
 	Short: "Access your inbox",
@@ -19,6 +26,40 @@ func truncate(s string, max int) string {
 	return s[:max-3] + "..."
 }
 
+// contactName returns the name of the contact that uses address (a phone
+// number or email address). If there is none, it returns the address, with
+// phone numbers pretty-printed (in national form when they share home's
+// country code).
+func contactName(book *contacts.Book, address, home string) string {
+	if c, ok := book.Lookup(address); ok {
+		return c.Name
+	}
+	if strings.Contains(address, "@") {
+		return address
+	}
+	return phone.Format(address, home)
+}
+
+// counterpartHeaders returns the table headers for a counterpart column
+// named header: just header, or header plus its raw address with --wide.
+func counterpartHeaders(header, rawHeader string) []string {
+	if inboxWide {
+		return []string{header, rawHeader}
+	}
+	return []string{header}
+}
+
+// counterpartCells returns the cells matching counterpartHeaders: name
+// (the contact name, or the address when there is none) and, with --wide,
+// the raw address.
+func counterpartCells(name, address string, max int) []string {
+	if inboxWide {
+		return []string{truncate(name, max), address}
+	}
+	return []string{truncate(name, max)}
+}
+
 func init() {
+	inboxCmd.PersistentFlags().BoolVar(&inboxWide, "wide", false, "Show raw addresses and numbers next to contact names")
 	rootCmd.AddCommand(inboxCmd)
 }
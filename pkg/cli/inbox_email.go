@@ -1,15 +1,23 @@
 package cli
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/ravi-technologies/sunday-cli/internal/api"
+	"github.com/ravi-technologies/sunday-cli/internal/contacts"
+	"github.com/ravi-technologies/sunday-cli/internal/crypto"
 	"github.com/ravi-technologies/sunday-cli/internal/output"
 	"github.com/spf13/cobra"
 )
 
-var emailUnread bool
+var (
+	emailUnread bool
+	emailIDs    []string
+)
 
 var emailCmd = &cobra.Command{
 	Use:   "email [thread_id]",
@@ -17,38 +25,64 @@ var emailCmd = &cobra.Command{
 	Long: `List email threads or view a specific thread.
 
 Without arguments, lists all email threads.
-With a thread_id argument, shows the full thread conversation.`,
+With a thread_id argument, shows the full thread conversation.
+With --ids, shows several threads, fetched concurrently. Threads that
+can't be fetched are reported at the end and the exit status is 1.`,
+	Example: `  sunday inbox email
+  sunday inbox email <thread_id>
+  sunday inbox email --ids <thread_id>,<thread_id> --json`,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := validateGroupBy(); err != nil {
+			return err
+		}
+
 		client, err := api.NewClient(nil)
 		if err != nil {
 			return err
 		}
 
+		if len(emailIDs) > 0 {
+			if len(args) > 0 {
+				return fmt.Errorf("give either a thread_id or --ids, not both")
+			}
+			return showEmailThreads(cmd.Context(), client, emailIDs)
+		}
+
 		// If thread_id provided, show thread detail
 		if len(args) > 0 {
-			return showEmailThread(client, args[0])
+			return showEmailThread(cmd.Context(), client, args[0])
 		}
 
 		// Otherwise, list threads
-		return listEmailThreads(client)
+		return listEmailThreads(cmd, client)
 	},
 }
 
-func listEmailThreads(client *api.Client) error {
-	threads, err := client.ListEmailThreads(emailUnread)
+func listEmailThreads(cmd *cobra.Command, client *api.Client) error {
+	threads, more, err := fetchList(cmd.Context(),
+		func(ctx context.Context) ([]api.EmailThread, error) {
+			return client.ListEmailThreadsContext(ctx, emailUnread)
+		},
+		func(ctx context.Context, opts api.PageOptions) (*api.Page[api.EmailThread], error) {
+			return client.ListEmailThreadsPage(ctx, emailUnread, opts)
+		})
 	if err != nil {
 		return err
 	}
+	if more {
+		defer printMoreHint(cmd)
+	}
 
 	kp, err := ensureKeyPair()
 	if err != nil {
 		return err
 	}
 
+	var fields []*string
 	for i := range threads {
-		threads[i].Subject = tryDecrypt(threads[i].Subject, kp)
-		threads[i].Preview = tryDecrypt(threads[i].Preview, kp)
+		fields = append(fields, &threads[i].Subject, &threads[i].Preview)
 	}
+	tryDecryptAll(fields, kp)
 
 	if jsonOutput {
 		return output.Current.Print(threads)
@@ -59,24 +93,40 @@ func listEmailThreads(client *api.Client) error {
 		return nil
 	}
 
-	headers := []string{"THREAD ID", "FROM", "SUBJECT", "MSGS", "UNREAD", "DATE"}
-	rows := make([][]string, len(threads))
+	book, err := contacts.Load()
+	if err != nil {
+		return err
+	}
+
+	headers := slices.Concat(
+		[]string{"THREAD ID"},
+		counterpartHeaders("FROM", "FROM ADDRESS"),
+		[]string{"SUBJECT", "MSGS", "UNREAD", "DATE"},
+	)
+	rows := make([]groupedRow, len(threads))
 	for i, t := range threads {
-		rows[i] = []string{
-			truncate(t.ThreadID, 20),
-			truncate(t.FromEmail, 25),
-			truncate(t.Subject, 30),
-			fmt.Sprintf("%d", t.MessageCount),
-			fmt.Sprintf("%d", t.UnreadCount),
-			t.LatestMessageDt.Format("Jan 02 15:04"),
+		from := contactName(book, t.FromEmail, "")
+		rows[i] = groupedRow{
+			cells: slices.Concat(
+				[]string{truncate(t.ThreadID, 20)},
+				counterpartCells(from, t.FromEmail, 25),
+				[]string{
+					truncate(t.Subject, 30),
+					fmt.Sprintf("%d", t.MessageCount),
+					fmt.Sprintf("%d", t.UnreadCount),
+					t.LatestMessageDt.Format("Jan 02 15:04"),
+				},
+			),
+			date:   t.LatestMessageDt,
+			sender: from,
 		}
 	}
-	output.Current.PrintTable(headers, rows)
+	printGroupedTable(headers, rows)
 	return nil
 }
 
-func showEmailThread(client *api.Client, threadID string) error {
-	thread, err := client.GetEmailThread(threadID)
+func showEmailThread(ctx context.Context, client *api.Client, threadID string) error {
+	thread, err := client.GetEmailThreadContext(ctx, threadID)
 	if err != nil {
 		return err
 	}
@@ -85,19 +135,63 @@ func showEmailThread(client *api.Client, threadID string) error {
 	if err != nil {
 		return err
 	}
+	decryptEmailThread(thread, kp)
 
-	thread.Subject = tryDecrypt(thread.Subject, kp)
-	for i := range thread.Messages {
-		thread.Messages[i].Subject = tryDecrypt(thread.Messages[i].Subject, kp)
-		thread.Messages[i].TextContent = tryDecrypt(thread.Messages[i].TextContent, kp)
-		thread.Messages[i].HTMLContent = tryDecrypt(thread.Messages[i].HTMLContent, kp)
+	if jsonOutput {
+		return output.Current.Print(thread)
+	}
+	printEmailThread(thread)
+	return nil
+}
+
+// showEmailThreads shows the threads with the given IDs. Threads that
+// can't be fetched don't stop the others being shown; the returned
+// *api.BatchError lists them.
+func showEmailThreads(ctx context.Context, client *api.Client, threadIDs []string) error {
+	fetched, fetchErr := client.GetEmailThreadsContext(ctx, threadIDs)
+	var batchErr *api.BatchError
+	if fetchErr != nil && !errors.As(fetchErr, &batchErr) {
+		return fetchErr
+	}
+
+	kp, err := ensureKeyPair()
+	if err != nil {
+		return err
+	}
+	threads := make([]*api.EmailThreadDetail, 0, len(fetched))
+	for _, thread := range fetched {
+		if thread != nil {
+			decryptEmailThread(thread, kp)
+			threads = append(threads, thread)
+		}
 	}
 
 	if jsonOutput {
-		return output.Current.Print(thread)
+		if err := output.Current.Print(threads); err != nil {
+			return err
+		}
+		return fetchErr
 	}
+	for i, thread := range threads {
+		if i > 0 {
+			fmt.Println()
+		}
+		printEmailThread(thread)
+	}
+	return fetchErr
+}
 
-	// Human-readable thread display
+// decryptEmailThread decrypts the encrypted fields of thread in place.
+func decryptEmailThread(thread *api.EmailThreadDetail, kp *crypto.KeyPair) {
+	fields := []*string{&thread.Subject}
+	for i := range thread.Messages {
+		fields = append(fields, &thread.Messages[i].Subject, &thread.Messages[i].TextContent, &thread.Messages[i].HTMLContent)
+	}
+	tryDecryptAll(fields, kp)
+}
+
+// printEmailThread prints a thread and its messages for humans.
+func printEmailThread(thread *api.EmailThreadDetail) {
 	fmt.Printf("Thread: %s\n", thread.ThreadID)
 	fmt.Printf("Subject: %s\n", thread.Subject)
 	fmt.Printf("Messages: %d\n", thread.MessageCount)
@@ -129,11 +223,14 @@ func showEmailThread(client *api.Client, threadID string) error {
 		fmt.Println(content)
 		fmt.Println(strings.Repeat("-", 60))
 	}
-
-	return nil
 }
 
 func init() {
 	emailCmd.Flags().BoolVar(&emailUnread, "unread", false, "Only show threads with unread messages")
+	emailCmd.Flags().StringSliceVar(&emailIDs, "ids", nil, "Show these threads (comma-separated IDs), fetched concurrently")
+	addGroupByFlag(emailCmd)
+	enablePaging(emailCmd)
+	requireScope(scopeReadInbox, emailCmd)
+	addPaginationFlags(emailCmd)
 	inboxCmd.AddCommand(emailCmd)
 }
@@ -0,0 +1,165 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/ravi-technologies/sunday-cli/internal/api"
+	"github.com/ravi-technologies/sunday-cli/internal/contacts"
+	"github.com/ravi-technologies/sunday-cli/internal/inbox"
+	"github.com/ravi-technologies/sunday-cli/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var (
+	listUnread    bool
+	listType      string
+	listDirection string
+)
+
+var inboxListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List email and SMS messages together",
+	Long: `List email and SMS messages together, newest first.
+
+Messages returned by more than one source are shown once.
+
+--limit and --page select a page of email and of SMS messages, which are
+then merged, so a page can hold up to twice --limit messages.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		switch listType {
+		case "", inbox.KindEmail, inbox.KindSMS:
+		default:
+			return fmt.Errorf("invalid --type %q: must be email or sms", listType)
+		}
+		switch listDirection {
+		case "", "incoming", "outgoing":
+		default:
+			return fmt.Errorf("invalid --direction %q: must be incoming or outgoing", listDirection)
+		}
+		if err := validateGroupBy(); err != nil {
+			return err
+		}
+
+		client, err := api.NewClient(nil)
+		if err != nil {
+			return err
+		}
+		return listInbox(cmd, client)
+	},
+}
+
+func listInbox(cmd *cobra.Command, client *api.Client) error {
+	kp, err := ensureKeyPair()
+	if err != nil {
+		return err
+	}
+
+	var sources [][]inbox.Entry
+	moreResults := false
+	if listType != inbox.KindSMS {
+		emails, more, err := fetchList(cmd.Context(),
+			func(ctx context.Context) ([]api.SundayEmailMessage, error) {
+				return client.ListEmailMessagesContext(ctx, listUnread)
+			},
+			func(ctx context.Context, opts api.PageOptions) (*api.Page[api.SundayEmailMessage], error) {
+				return client.ListEmailMessagesPage(ctx, listUnread, opts)
+			})
+		if err != nil {
+			return err
+		}
+		moreResults = moreResults || more
+		var fields []*string
+		for i := range emails {
+			fields = append(fields, &emails[i].Subject, &emails[i].TextContent)
+		}
+		tryDecryptAll(fields, kp)
+		sources = append(sources, inbox.FromEmail(emails))
+	}
+	if listType != inbox.KindEmail {
+		sms, more, err := fetchList(cmd.Context(),
+			func(ctx context.Context) ([]api.SundayPhoneMessage, error) {
+				return client.ListSMSMessagesContext(ctx, listUnread)
+			},
+			func(ctx context.Context, opts api.PageOptions) (*api.Page[api.SundayPhoneMessage], error) {
+				return client.ListSMSMessagesPage(ctx, listUnread, opts)
+			})
+		if err != nil {
+			return err
+		}
+		moreResults = moreResults || more
+		var fields []*string
+		for i := range sms {
+			fields = append(fields, &sms[i].Body)
+		}
+		tryDecryptAll(fields, kp)
+		sources = append(sources, inbox.FromSMS(sms))
+	}
+
+	if moreResults {
+		defer printMoreHint(cmd)
+	}
+
+	entries := inbox.Merge(sources...)
+	if listDirection != "" {
+		entries = slices.DeleteFunc(entries, func(e inbox.Entry) bool {
+			return e.Direction != listDirection
+		})
+	}
+
+	if jsonOutput {
+		return output.Current.Print(entries)
+	}
+
+	if len(entries) == 0 {
+		output.Current.PrintMessage("No messages found")
+		return nil
+	}
+
+	book, err := contacts.Load()
+	if err != nil {
+		return err
+	}
+
+	headers := slices.Concat(
+		[]string{"TYPE", "ID"},
+		counterpartHeaders("FROM", "FROM ADDRESS"),
+		[]string{"SUBJECT / PREVIEW", "UNREAD", "DATE"},
+	)
+	rows := make([]groupedRow, len(entries))
+	for i, e := range entries {
+		summary := e.Preview
+		if e.Subject != "" {
+			summary = e.Subject
+		}
+		unread := ""
+		if !e.IsRead {
+			unread = "*"
+		}
+		from := contactName(book, e.From, e.To)
+		rows[i] = groupedRow{
+			cells: slices.Concat(
+				[]string{e.Kind, fmt.Sprintf("%d", e.ID)},
+				counterpartCells(from, e.From, 25),
+				[]string{truncate(summary, 35), unread, e.CreatedDt.Format("Jan 02 15:04")},
+			),
+			date:   e.CreatedDt,
+			sender: from,
+		}
+	}
+	printGroupedTable(headers, rows)
+	return nil
+}
+
+func init() {
+	inboxListCmd.Flags().BoolVar(&listUnread, "unread", false, "Only show unread messages")
+	inboxListCmd.Flags().StringVar(&listType, "type", "", "Only show one message type (email or sms)")
+	inboxListCmd.Flags().StringVar(&listDirection, "direction", "", "Only show incoming or outgoing messages")
+	addGroupByFlag(inboxListCmd)
+	enablePaging(inboxListCmd)
+	requireScope(scopeReadInbox, inboxListCmd)
+	addPaginationFlags(inboxListCmd)
+	inboxCmd.AddCommand(inboxListCmd)
+}
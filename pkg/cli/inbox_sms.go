@@ -1,15 +1,26 @@
 package cli
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/ravi-technologies/sunday-cli/internal/api"
+	"github.com/ravi-technologies/sunday-cli/internal/contacts"
+	"github.com/ravi-technologies/sunday-cli/internal/crypto"
+	"github.com/ravi-technologies/sunday-cli/internal/inbox"
 	"github.com/ravi-technologies/sunday-cli/internal/output"
+	"github.com/ravi-technologies/sunday-cli/internal/phone"
 	"github.com/spf13/cobra"
 )
 
-var smsUnread bool
+var (
+	smsUnread bool
+	smsRaw    bool
+	smsIDs    []string
+)
 
 var smsCmd = &cobra.Command{
 	Use:   "sms [conversation_id]",
@@ -19,67 +30,157 @@ var smsCmd = &cobra.Command{
 Without arguments, lists all SMS conversations.
 With a conversation_id argument, shows the full conversation.
 
+Conversations with the same person are shown as one thread, even when
+they texted from several numbers (see "sunday contacts") or your Sunday
+number changed. Use --raw to see the server's per-number conversations.
+
+With --ids, shows several of the server's conversations, fetched
+concurrently. Conversations that can't be fetched are reported at the end
+and the exit status is 1.
+
 Conversation IDs are in the format: {phone_id}_{from_number}
-Example: 1_+15551234567`,
+Example: 1_+15551234567 (formatted numbers such as "1_(555) 123-4567" are
+also accepted)`,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := validateGroupBy(); err != nil {
+			return err
+		}
+
 		client, err := api.NewClient(nil)
 		if err != nil {
 			return err
 		}
 
+		if len(smsIDs) > 0 {
+			if len(args) > 0 {
+				return fmt.Errorf("give either a conversation_id or --ids, not both")
+			}
+			ids := make([]string, len(smsIDs))
+			for i, id := range smsIDs {
+				ids[i] = phone.NormalizeConversationID(id)
+			}
+			return showSMSConversations(cmd.Context(), client, ids)
+		}
+
 		// If conversation_id provided, show conversation detail
 		if len(args) > 0 {
-			return showSMSConversation(client, args[0])
+			conversationID := phone.NormalizeConversationID(args[0])
+			if smsRaw {
+				return showSMSConversation(cmd.Context(), client, conversationID)
+			}
+			return showSMSThread(cmd.Context(), client, conversationID)
 		}
 
 		// Otherwise, list conversations
-		return listSMSConversations(client)
+		return listSMSConversations(cmd, client)
 	},
 }
 
-func listSMSConversations(client *api.Client) error {
-	conversations, err := client.ListSMSConversations(smsUnread)
+func listSMSConversations(cmd *cobra.Command, client *api.Client) error {
+	conversations, more, err := fetchList(cmd.Context(),
+		func(ctx context.Context) ([]api.SMSConversation, error) {
+			return client.ListSMSConversationsContext(ctx, smsUnread)
+		},
+		func(ctx context.Context, opts api.PageOptions) (*api.Page[api.SMSConversation], error) {
+			return client.ListSMSConversationsPage(ctx, smsUnread, opts)
+		})
 	if err != nil {
 		return err
 	}
+	if more {
+		defer printMoreHint(cmd)
+	}
 
 	kp, err := ensureKeyPair()
 	if err != nil {
 		return err
 	}
 
+	var fields []*string
 	for i := range conversations {
-		conversations[i].Preview = tryDecrypt(conversations[i].Preview, kp)
+		fields = append(fields, &conversations[i].Preview)
 	}
+	tryDecryptAll(fields, kp)
 
-	if jsonOutput {
+	if smsRaw && jsonOutput {
 		return output.Current.Print(conversations)
 	}
 
-	if len(conversations) == 0 {
+	book, err := contacts.Load()
+	if err != nil {
+		return err
+	}
+
+	var threads []inbox.SMSThread
+	if smsRaw {
+		for _, c := range conversations {
+			threads = append(threads, inbox.SMSThread{SMSConversation: c, ConversationIDs: []string{c.ConversationID}})
+		}
+	} else {
+		threads = inbox.ThreadSMS(conversations, book)
+		if jsonOutput {
+			return output.Current.Print(threads)
+		}
+	}
+
+	if len(threads) == 0 {
 		output.Current.PrintMessage("No SMS conversations found")
 		return nil
 	}
 
-	headers := []string{"CONVERSATION ID", "FROM", "YOUR NUMBER", "PREVIEW", "MSGS", "UNREAD", "DATE"}
-	rows := make([][]string, len(conversations))
-	for i, c := range conversations {
-		rows[i] = []string{
-			truncate(c.ConversationID, 20),
-			c.FromNumber,
-			c.SundayPhoneNumber,
-			truncate(c.Preview, 25),
-			fmt.Sprintf("%d", c.MessageCount),
-			fmt.Sprintf("%d", c.UnreadCount),
-			c.LatestMessageDt.Format("Jan 02 15:04"),
+	headers := slices.Concat(
+		[]string{"CONVERSATION ID"},
+		counterpartHeaders("FROM", "FROM NUMBER"),
+		[]string{"YOUR NUMBER", "PREVIEW", "MSGS", "UNREAD", "DATE"},
+	)
+	rows := make([]groupedRow, len(threads))
+	for i, t := range threads {
+		from := contactName(book, t.FromNumber, t.SundayPhoneNumber)
+		rows[i] = groupedRow{
+			cells: slices.Concat(
+				[]string{truncate(t.ConversationID, 20)},
+				counterpartCells(from, t.FromNumber, 25),
+				[]string{
+					phone.FormatNational(t.SundayPhoneNumber),
+					truncate(t.Preview, 25),
+					fmt.Sprintf("%d", t.MessageCount),
+					fmt.Sprintf("%d", t.UnreadCount),
+					t.LatestMessageDt.Format("Jan 02 15:04"),
+				},
+			),
+			date:   t.LatestMessageDt,
+			sender: from,
 		}
 	}
-	output.Current.PrintTable(headers, rows)
+	printGroupedTable(headers, rows)
 	return nil
 }
 
-func showSMSConversation(client *api.Client, conversationID string) error {
-	conversation, err := client.GetSMSConversation(conversationID)
+// showSMSThread shows every conversation in the same thread as
+// conversationID as a single history.
+func showSMSThread(ctx context.Context, client *api.Client, conversationID string) error {
+	conversations, err := client.ListSMSConversationsContext(ctx, false)
+	if err != nil {
+		return err
+	}
+	book, err := contacts.Load()
+	if err != nil {
+		return err
+	}
+
+	thread, ok := inbox.FindThread(conversations, book, conversationID)
+	ids := thread.ConversationIDs
+	if !ok {
+		ids = []string{conversationID}
+	}
+	// Fetch the requested conversation first so it names the thread.
+	for i, id := range ids {
+		if id == conversationID {
+			ids[0], ids[i] = ids[i], ids[0]
+		}
+	}
+
+	details, err := client.GetSMSConversationsContext(ctx, ids)
 	if err != nil {
 		return err
 	}
@@ -89,44 +190,145 @@ func showSMSConversation(client *api.Client, conversationID string) error {
 		return err
 	}
 
-	for i := range conversation.Messages {
-		conversation.Messages[i].Body = tryDecrypt(conversation.Messages[i].Body, kp)
+	merged := inbox.MergeSMSDetails(thread.Contact, details)
+	var fields []*string
+	for i := range merged.Messages {
+		fields = append(fields, &merged.Messages[i].Body)
 	}
+	tryDecryptAll(fields, kp)
 
 	if jsonOutput {
-		return output.Current.Print(conversation)
+		return output.Current.Print(merged)
 	}
 
 	// Human-readable conversation display
+	fmt.Printf("Conversation: %s\n", merged.ConversationID)
+	if merged.Contact != "" {
+		fmt.Printf("Contact: %s\n", merged.Contact)
+	}
+	if len(merged.ConversationIDs) > 1 {
+		fmt.Printf("Merged: %s\n", strings.Join(merged.ConversationIDs, ", "))
+	}
+	fmt.Printf("From: %s\n", phone.Format(merged.FromNumber, merged.SundayPhone))
+	fmt.Printf("Your Number: %s\n", phone.FormatNational(merged.SundayPhone))
+	fmt.Printf("Messages: %d\n", merged.MessageCount)
+	fmt.Println(strings.Repeat("-", 60))
+
+	for _, msg := range merged.Messages {
+		printSMSMessage(msg.SMSMessage, msg.FromNumber, msg.SundayPhone)
+	}
+
+	return nil
+}
+
+func showSMSConversation(ctx context.Context, client *api.Client, conversationID string) error {
+	conversation, err := client.GetSMSConversationContext(ctx, conversationID)
+	if err != nil {
+		return err
+	}
+
+	kp, err := ensureKeyPair()
+	if err != nil {
+		return err
+	}
+
+	decryptSMSConversation(conversation, kp)
+
+	if jsonOutput {
+		return output.Current.Print(conversation)
+	}
+	printSMSConversation(conversation)
+	return nil
+}
+
+// showSMSConversations shows the server's conversations with the given
+// IDs. Conversations that can't be fetched don't stop the others being
+// shown; the returned *api.BatchError lists them.
+func showSMSConversations(ctx context.Context, client *api.Client, conversationIDs []string) error {
+	fetched, fetchErr := client.GetSMSConversationsContext(ctx, conversationIDs)
+	var batchErr *api.BatchError
+	if fetchErr != nil && !errors.As(fetchErr, &batchErr) {
+		return fetchErr
+	}
+
+	kp, err := ensureKeyPair()
+	if err != nil {
+		return err
+	}
+	conversations := make([]*api.SMSConversationDetail, 0, len(fetched))
+	for _, conversation := range fetched {
+		if conversation != nil {
+			decryptSMSConversation(conversation, kp)
+			conversations = append(conversations, conversation)
+		}
+	}
+
+	if jsonOutput {
+		if err := output.Current.Print(conversations); err != nil {
+			return err
+		}
+		return fetchErr
+	}
+	for i, conversation := range conversations {
+		if i > 0 {
+			fmt.Println()
+		}
+		printSMSConversation(conversation)
+	}
+	return fetchErr
+}
+
+// decryptSMSConversation decrypts the message bodies of conversation in
+// place.
+func decryptSMSConversation(conversation *api.SMSConversationDetail, kp *crypto.KeyPair) {
+	var fields []*string
+	for i := range conversation.Messages {
+		fields = append(fields, &conversation.Messages[i].Body)
+	}
+	tryDecryptAll(fields, kp)
+}
+
+// printSMSConversation prints a conversation and its messages for humans.
+func printSMSConversation(conversation *api.SMSConversationDetail) {
 	fmt.Printf("Conversation: %s\n", conversation.ConversationID)
-	fmt.Printf("From: %s\n", conversation.FromNumber)
-	fmt.Printf("Your Number: %s\n", conversation.SundayPhone)
+	fmt.Printf("From: %s\n", phone.Format(conversation.FromNumber, conversation.SundayPhone))
+	fmt.Printf("Your Number: %s\n", phone.FormatNational(conversation.SundayPhone))
 	fmt.Printf("Messages: %d\n", conversation.MessageCount)
 	fmt.Println(strings.Repeat("-", 60))
 
 	for _, msg := range conversation.Messages {
-		direction := "->"
-		sender := conversation.SundayPhone
-		if msg.Direction == "incoming" {
-			direction = "<-"
-			sender = conversation.FromNumber
-		}
-		readStatus := ""
-		if !msg.IsRead {
-			readStatus = " [UNREAD]"
-		}
+		printSMSMessage(msg, conversation.FromNumber, conversation.SundayPhone)
+	}
+}
 
-		fmt.Printf("\n%s %s%s\n", direction, sender, readStatus)
-		fmt.Printf("  %s\n", msg.CreatedDt.Format("Jan 02, 2006 3:04 PM"))
-		fmt.Println()
-		fmt.Println(msg.Body)
-		fmt.Println(strings.Repeat("-", 60))
+// printSMSMessage prints one message of a conversation between fromNumber
+// and the user's sundayPhone.
+func printSMSMessage(msg api.SMSMessage, fromNumber, sundayPhone string) {
+	direction := "->"
+	sender := phone.FormatNational(sundayPhone)
+	if msg.Direction == "incoming" {
+		direction = "<-"
+		sender = phone.Format(fromNumber, sundayPhone)
+	}
+	readStatus := ""
+	if !msg.IsRead {
+		readStatus = " [UNREAD]"
 	}
 
-	return nil
+	fmt.Printf("\n%s %s%s\n", direction, sender, readStatus)
+	fmt.Printf("  %s\n", msg.CreatedDt.Format("Jan 02, 2006 3:04 PM"))
+	fmt.Println()
+	fmt.Println(msg.Body)
+	fmt.Println(strings.Repeat("-", 60))
 }
 
 func init() {
 	smsCmd.Flags().BoolVar(&smsUnread, "unread", false, "Only show conversations with unread messages")
+	smsCmd.Flags().BoolVar(&smsRaw, "raw", false, "Show the server's per-number conversations without merging threads")
+	smsCmd.Flags().StringSliceVar(&smsIDs, "ids", nil, "Show these conversations (comma-separated IDs), fetched concurrently")
+	addGroupByFlag(smsCmd)
+	enablePaging(smsCmd)
+	requireScope(scopeReadInbox, smsCmd)
+	addPaginationFlags(smsCmd)
 	inboxCmd.AddCommand(smsCmd)
 }
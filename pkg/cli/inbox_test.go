@@ -1,6 +1,11 @@
 package cli
 
-import "testing"
+import (
+	"slices"
+	"testing"
+
+	"github.com/ravi-technologies/sunday-cli/internal/contacts"
+)
 
 // TestTruncate_Short verifies that the truncate function returns the original
 // string unchanged when it is shorter than the maximum length.
@@ -277,3 +282,43 @@ func TestTruncate_Comprehensive(t *testing.T) {
 		}
 	})
 }
+
+// TestContactName verifies that known addresses resolve to contact names
+// and unknown ones are shown as-is, with phone numbers pretty-printed.
+func TestContactName(t *testing.T) {
+	book := &contacts.Book{}
+	book.Add("Alice", "+15551234567", "alice@example.com")
+
+	tests := map[string]string{
+		"+1 555 123 4567":   "Alice",
+		"alice@example.com": "Alice",
+		"bob@example.com":   "bob@example.com",
+		"+15559876543":      "(555) 987-6543",
+		"+442079460958":     "+44 207 946 0958",
+	}
+	for address, want := range tests {
+		if got := contactName(book, address, "+15550000000"); got != want {
+			t.Errorf("contactName(%q) = %q, want %q", address, got, want)
+		}
+	}
+}
+
+// TestCounterpartColumns verifies that --wide adds the raw address column.
+func TestCounterpartColumns(t *testing.T) {
+	t.Cleanup(func() { inboxWide = false })
+
+	if got := counterpartHeaders("FROM", "FROM ADDRESS"); !slices.Equal(got, []string{"FROM"}) {
+		t.Errorf("counterpartHeaders() = %v, want [FROM]", got)
+	}
+	if got := counterpartCells("Alice", "alice@example.com", 25); !slices.Equal(got, []string{"Alice"}) {
+		t.Errorf("counterpartCells() = %v, want [Alice]", got)
+	}
+
+	inboxWide = true
+	if got := counterpartHeaders("FROM", "FROM ADDRESS"); !slices.Equal(got, []string{"FROM", "FROM ADDRESS"}) {
+		t.Errorf("counterpartHeaders() with --wide = %v", got)
+	}
+	if got := counterpartCells("Alice", "alice@example.com", 25); !slices.Equal(got, []string{"Alice", "alice@example.com"}) {
+		t.Errorf("counterpartCells() with --wide = %v", got)
+	}
+}
@@ -0,0 +1,114 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+
+	"github.com/ravi-technologies/sunday-cli/internal/api"
+	"github.com/ravi-technologies/sunday-cli/internal/contacts"
+	"github.com/ravi-technologies/sunday-cli/internal/crypto"
+	"github.com/ravi-technologies/sunday-cli/internal/inbox"
+	"github.com/spf13/cobra"
+)
+
+var watchType string
+
+var inboxWatchCmd = &cobra.Command{
+	Use:   "watch",
+	Short: "Print new email and SMS messages as they arrive",
+	Long: `Print new email and SMS messages as they arrive, until interrupted.
+
+The server pushes messages over a live stream, so they appear as soon as
+they are received, without polling. With --json each message is printed
+as one JSON object per line.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		switch watchType {
+		case "", inbox.KindEmail, inbox.KindSMS:
+		default:
+			return fmt.Errorf("invalid --type %q: must be email or sms", watchType)
+		}
+
+		client, err := api.NewClient(nil)
+		if err != nil {
+			return err
+		}
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+		return watchInbox(ctx, cmd, client)
+	},
+}
+
+func watchInbox(ctx context.Context, cmd *cobra.Command, client *api.Client) error {
+	info, err := client.GetServerInfoContext(ctx)
+	if err != nil {
+		return err
+	}
+	if !info.Supports(api.FeatureInboxEvents) {
+		return errors.New("this Sunday server can't stream new messages; run `sunday inbox list` to check for them instead")
+	}
+
+	kp, err := ensureKeyPair()
+	if err != nil {
+		return err
+	}
+	book, err := contacts.Load()
+	if err != nil {
+		return err
+	}
+	client.StartTokenRefresher(ctx)
+
+	w := cmd.OutOrStdout()
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	for msg, err := range client.SubscribeInbox(ctx) {
+		if err != nil {
+			return err
+		}
+		e, ok := watchEntry(msg, kp)
+		if !ok || watchType != "" && e.Kind != watchType {
+			continue
+		}
+
+		if jsonOutput {
+			if err := enc.Encode(e); err != nil {
+				return err
+			}
+			continue
+		}
+		summary := e.Preview
+		if e.Subject != "" {
+			summary = e.Subject
+		}
+		fmt.Fprintf(w, "%s  %-5s  %-25s  %s\n", e.CreatedDt.Local().Format("Jan 02 15:04"), e.Kind,
+			truncate(contactName(book, e.From, e.To), 25), truncate(summary, 60))
+	}
+	return nil
+}
+
+// watchEntry decrypts a streamed message and converts it to an inbox
+// entry, reporting false for a message of a type it doesn't know.
+func watchEntry(msg api.InboxMessage, kp *crypto.KeyPair) (inbox.Entry, bool) {
+	switch {
+	case msg.Email != nil:
+		m := *msg.Email
+		m.Subject = tryDecrypt(m.Subject, kp)
+		m.TextContent = tryDecrypt(m.TextContent, kp)
+		return inbox.FromEmail([]api.SundayEmailMessage{m})[0], true
+	case msg.SMS != nil:
+		m := *msg.SMS
+		m.Body = tryDecrypt(m.Body, kp)
+		return inbox.FromSMS([]api.SundayPhoneMessage{m})[0], true
+	}
+	return inbox.Entry{}, false
+}
+
+func init() {
+	inboxWatchCmd.Flags().StringVar(&watchType, "type", "", "Only show one message type (email or sms)")
+	requireScope(scopeReadInbox, inboxWatchCmd)
+	inboxCmd.AddCommand(inboxWatchCmd)
+}
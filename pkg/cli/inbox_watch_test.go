@@ -0,0 +1,24 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/ravi-technologies/sunday-cli/internal/api"
+	"github.com/ravi-technologies/sunday-cli/internal/inbox"
+)
+
+// TestWatchEntry verifies that streamed messages become inbox entries and
+// that messages of an unknown type are skipped.
+func TestWatchEntry(t *testing.T) {
+	e, ok := watchEntry(api.InboxMessage{Type: "email", Email: &api.SundayEmailMessage{ID: 1, FromEmail: "a@example.com", Subject: "Hi"}}, nil)
+	if !ok || e.Kind != inbox.KindEmail || e.From != "a@example.com" || e.Subject != "Hi" {
+		t.Errorf("watchEntry(email) = %+v, %v", e, ok)
+	}
+	e, ok = watchEntry(api.InboxMessage{Type: "sms", SMS: &api.SundayPhoneMessage{ID: 2, Body: "code 123"}}, nil)
+	if !ok || e.Kind != inbox.KindSMS || e.Preview != "code 123" {
+		t.Errorf("watchEntry(sms) = %+v, %v", e, ok)
+	}
+	if _, ok := watchEntry(api.InboxMessage{Type: "fax"}, nil); ok {
+		t.Error("watchEntry(fax) ok = true, want false")
+	}
+}
@@ -0,0 +1,66 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+
+	"github.com/ravi-technologies/sunday-cli/internal/logging"
+	"github.com/spf13/cobra"
+)
+
+// Flag variables for logs commands
+var (
+	logsLines  int
+	logsFollow bool
+)
+
+var logsCmd = &cobra.Command{
+	Use:   "logs",
+	Short: "Inspect the CLI log file",
+	Long: `The CLI keeps a structured log of commands and API calls in
+~/.sunday/logs/cli.log (rotated at 5 MiB, 3 backups kept). Set
+SUNDAY_LOG_LEVEL=debug to include every API request.`,
+}
+
+var logsTailCmd = &cobra.Command{
+	Use:   "tail",
+	Short: "Show recent log entries",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if _, err := os.Stat(logging.Path()); os.IsNotExist(err) && !logsFollow {
+			fmt.Fprintf(cmd.ErrOrStderr(), "No log file yet at %s\n", logging.Path())
+			return nil
+		}
+		if err := logging.Tail(cmd.OutOrStdout(), logsLines); err != nil {
+			return err
+		}
+		if !logsFollow {
+			return nil
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+		return logging.Follow(ctx, cmd.OutOrStdout())
+	},
+}
+
+// isLogsCommand reports whether cmd is part of `sunday logs`, whose own
+// runs are kept out of the log so tailing shows only other activity.
+func isLogsCommand(cmd *cobra.Command) bool {
+	for c := cmd; c != nil; c = c.Parent() {
+		if c == logsCmd {
+			return true
+		}
+	}
+	return false
+}
+
+func init() {
+	logsTailCmd.Flags().IntVarP(&logsLines, "lines", "n", 50, "Number of lines to show")
+	logsTailCmd.Flags().BoolVarP(&logsFollow, "follow", "f", false, "Keep printing new entries until interrupted")
+
+	logsCmd.AddCommand(logsTailCmd)
+	rootCmd.AddCommand(logsCmd)
+}
@@ -0,0 +1,49 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ravi-technologies/sunday-cli/internal/config"
+	"github.com/ravi-technologies/sunday-cli/internal/logging"
+)
+
+// TestLogsTail verifies that `logs tail` prints the last --lines entries.
+func TestLogsTail(t *testing.T) {
+	config.SetLocation(t.TempDir())
+	defer config.SetLocation("")
+
+	if err := os.MkdirAll(filepath.Dir(logging.Path()), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(logging.Path(), []byte("one\ntwo\nthree\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	origLines, origFollow := logsLines, logsFollow
+	defer func() { logsLines, logsFollow = origLines, origFollow }()
+	logsLines, logsFollow = 2, false
+
+	var out bytes.Buffer
+	logsTailCmd.SetOut(&out)
+	defer logsTailCmd.SetOut(nil)
+	if err := logsTailCmd.RunE(logsTailCmd, nil); err != nil {
+		t.Fatalf("logs tail error = %v", err)
+	}
+	if out.String() != "two\nthree\n" {
+		t.Errorf("logs tail output = %q, want %q", out.String(), "two\nthree\n")
+	}
+}
+
+// TestIsLogsCommand verifies that only the logs command tree is excluded
+// from logging.
+func TestIsLogsCommand(t *testing.T) {
+	if !isLogsCommand(logsTailCmd) {
+		t.Error("isLogsCommand(logs tail) = false, want true")
+	}
+	if isLogsCommand(statusCmd) {
+		t.Error("isLogsCommand(auth status) = true, want false")
+	}
+}
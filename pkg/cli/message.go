@@ -35,7 +35,7 @@ With a message ID, shows the specific message details.`,
 
 		// If message ID provided, fetch that specific message
 		if len(args) > 0 {
-			message, err := client.GetSMSMessage(args[0])
+			message, err := client.GetSMSMessageContext(cmd.Context(), args[0])
 			if err != nil {
 				return err
 			}
@@ -46,14 +46,16 @@ With a message ID, shows the specific message details.`,
 		}
 
 		// Otherwise list all messages
-		messages, err := client.ListSMSMessages(messageUnreadOnly)
+		messages, err := client.ListSMSMessagesContext(cmd.Context(), messageUnreadOnly)
 		if err != nil {
 			return err
 		}
 
+		var fields []*string
 		for i := range messages {
-			messages[i].Body = tryDecrypt(messages[i].Body, kp)
+			fields = append(fields, &messages[i].Body)
 		}
+		tryDecryptAll(fields, kp)
 
 		output.Current.Print(messages)
 		return nil
@@ -81,7 +83,7 @@ With a message ID, shows the specific message details.`,
 
 		// If message ID provided, fetch that specific message
 		if len(args) > 0 {
-			message, err := client.GetEmailMessage(args[0])
+			message, err := client.GetEmailMessageContext(cmd.Context(), args[0])
 			if err != nil {
 				return err
 			}
@@ -95,16 +97,16 @@ With a message ID, shows the specific message details.`,
 		}
 
 		// Otherwise list all messages
-		messages, err := client.ListEmailMessages(messageUnreadOnly)
+		messages, err := client.ListEmailMessagesContext(cmd.Context(), messageUnreadOnly)
 		if err != nil {
 			return err
 		}
 
+		var fields []*string
 		for i := range messages {
-			messages[i].Subject = tryDecrypt(messages[i].Subject, kp)
-			messages[i].TextContent = tryDecrypt(messages[i].TextContent, kp)
-			messages[i].HTMLContent = tryDecrypt(messages[i].HTMLContent, kp)
+			fields = append(fields, &messages[i].Subject, &messages[i].TextContent, &messages[i].HTMLContent)
 		}
+		tryDecryptAll(fields, kp)
 
 		output.Current.Print(messages)
 		return nil
@@ -115,6 +117,8 @@ func init() {
 	messageSMSCmd.Flags().BoolVar(&messageUnreadOnly, "unread", false, "Show only unread messages")
 	messageEmailCmd.Flags().BoolVar(&messageUnreadOnly, "unread", false, "Show only unread messages")
 
+	enablePaging(messageSMSCmd, messageEmailCmd)
+	requireScope(scopeReadInbox, messageSMSCmd, messageEmailCmd)
 	messageCmd.AddCommand(messageSMSCmd)
 	messageCmd.AddCommand(messageEmailCmd)
 	rootCmd.AddCommand(messageCmd)
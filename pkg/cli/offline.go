@@ -0,0 +1,42 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/ravi-technologies/sunday-cli/internal/api"
+)
+
+// warnOffline tells the user how old the data shown by an --offline
+// command may be. It prints nothing if nothing came from the cache.
+func warnOffline(w io.Writer, now time.Time) {
+	since := api.OfflineSince()
+	if since.IsZero() {
+		return
+	}
+	yellow := color.New(color.FgYellow).SprintFunc()
+	fmt.Fprintf(w, "%s offline: showing data cached %s (%s).\n",
+		yellow("Warning:"), formatAge(now.Sub(since)), since.Local().Format("2006-01-02 15:04"))
+}
+
+// formatAge describes a duration coarsely, as "5 minutes ago".
+func formatAge(d time.Duration) string {
+	plural := func(n int, unit string) string {
+		if n == 1 {
+			return fmt.Sprintf("1 %s ago", unit)
+		}
+		return fmt.Sprintf("%d %ss ago", n, unit)
+	}
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return plural(int(d/time.Minute), "minute")
+	case d < 48*time.Hour:
+		return plural(int(d/time.Hour), "hour")
+	default:
+		return plural(int(d/(24*time.Hour)), "day")
+	}
+}
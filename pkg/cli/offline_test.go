@@ -0,0 +1,26 @@
+package cli
+
+import (
+	"testing"
+	"time"
+)
+
+// TestFormatAge verifies the coarse ages shown for offline data.
+func TestFormatAge(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{30 * time.Second, "just now"},
+		{time.Minute, "1 minute ago"},
+		{45 * time.Minute, "45 minutes ago"},
+		{5 * time.Hour, "5 hours ago"},
+		{47 * time.Hour, "47 hours ago"},
+		{72 * time.Hour, "3 days ago"},
+	}
+	for _, tt := range tests {
+		if got := formatAge(tt.d); got != tt.want {
+			t.Errorf("formatAge(%v) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
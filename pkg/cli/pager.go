@@ -0,0 +1,72 @@
+package cli
+
+import (
+	"bytes"
+	"io"
+	"os"
+
+	"github.com/ravi-technologies/sunday-cli/internal/output"
+	"github.com/spf13/cobra"
+)
+
+// annotationPaged marks commands whose human output may be long enough to
+// page.
+const annotationPaged = "sunday.paged"
+
+// noPager disables paging for this invocation.
+var noPager bool
+
+// pagedOutput captures stdout while a paged command runs.
+var pagedOutput struct {
+	stdout *os.File
+	w      *os.File
+	buf    bytes.Buffer
+	done   chan struct{}
+}
+
+// enablePaging marks cmds as producing pageable output.
+func enablePaging(cmds ...*cobra.Command) {
+	for _, cmd := range cmds {
+		if cmd.Annotations == nil {
+			cmd.Annotations = map[string]string{}
+		}
+		cmd.Annotations[annotationPaged] = "true"
+	}
+}
+
+// startPaging redirects stdout into a buffer if cmd's output should be
+// paged: it is marked as paged, the output is for humans, and both stdin
+// and stdout are terminals.
+func startPaging(cmd *cobra.Command) {
+	if cmd.Annotations[annotationPaged] == "" || noPager || jsonOutput ||
+		!output.IsTerminal(os.Stdout) || !output.IsTerminal(os.Stdin) {
+		return
+	}
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		return
+	}
+	pagedOutput.stdout, pagedOutput.w = os.Stdout, w
+	pagedOutput.buf.Reset()
+	pagedOutput.done = make(chan struct{})
+	go func() {
+		io.Copy(&pagedOutput.buf, r)
+		r.Close()
+		close(pagedOutput.done)
+	}()
+	os.Stdout = w
+}
+
+// finishPaging restores stdout and shows the captured output, through a
+// pager if it doesn't fit on screen.
+func finishPaging() error {
+	if pagedOutput.w == nil {
+		return nil
+	}
+	pagedOutput.w.Close()
+	<-pagedOutput.done
+	os.Stdout = pagedOutput.stdout
+	pagedOutput.w = nil
+	return output.Page(pagedOutput.buf.Bytes())
+}
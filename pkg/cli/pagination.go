@@ -0,0 +1,62 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ravi-technologies/sunday-cli/internal/api"
+	"github.com/spf13/cobra"
+)
+
+// Flags of the paginated list commands.
+var (
+	pageLimit  int
+	pageNumber int
+	pageAll    bool
+)
+
+// addPaginationFlags adds --limit, --page and --all to cmds.
+func addPaginationFlags(cmds ...*cobra.Command) {
+	for _, cmd := range cmds {
+		cmd.Flags().IntVar(&pageLimit, "limit", 0, "Fetch at most this many items per page")
+		cmd.Flags().IntVar(&pageNumber, "page", 0, "Fetch this page of results, counting from 1")
+		cmd.Flags().BoolVar(&pageAll, "all", false, "Fetch every page")
+	}
+}
+
+// fetchList returns the items a list command shows. Without pagination
+// flags everything comes from all in one request; otherwise page fetches
+// the selected page, or every page with --all. more reports whether the
+// server has items beyond those returned.
+func fetchList[T any](ctx context.Context, all func(context.Context) ([]T, error), page func(context.Context, api.PageOptions) (*api.Page[T], error)) (items []T, more bool, err error) {
+	if pageLimit < 0 || pageNumber < 0 {
+		return nil, false, fmt.Errorf("--limit and --page can't be negative")
+	}
+	if pageAll && pageNumber > 0 {
+		return nil, false, fmt.Errorf("--all fetches every page, so it can't be combined with --page")
+	}
+	if pageLimit == 0 && pageNumber == 0 && !pageAll {
+		items, err := all(ctx)
+		return items, false, err
+	}
+
+	opts := api.PageOptions{Limit: pageLimit, Page: pageNumber}
+	if pageAll {
+		items, err := api.NewPaginator(page, opts).All(ctx)
+		return items, false, err
+	}
+	p, err := page(ctx, opts)
+	if err != nil {
+		return nil, false, err
+	}
+	return p.Items, p.Next != "", nil
+}
+
+// printMoreHint tells the user how to see the rest of a partial list.
+// Nothing is printed with --json, where stdout must stay parseable.
+func printMoreHint(cmd *cobra.Command) {
+	if jsonOutput {
+		return
+	}
+	fmt.Fprintf(cmd.ErrOrStderr(), "More results available: use --page %d, or --all to fetch them all.\n", max(pageNumber, 1)+1)
+}
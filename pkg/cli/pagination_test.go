@@ -0,0 +1,64 @@
+package cli
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/ravi-technologies/sunday-cli/internal/api"
+)
+
+// TestFetchList verifies which request the pagination flags select.
+func TestFetchList(t *testing.T) {
+	defer func() { pageLimit, pageNumber, pageAll = 0, 0, false }()
+
+	var calls []string
+	all := func(ctx context.Context) ([]int, error) {
+		calls = append(calls, "all")
+		return []int{1, 2, 3}, nil
+	}
+	page := func(ctx context.Context, opts api.PageOptions) (*api.Page[int], error) {
+		calls = append(calls, "page")
+		if opts.Cursor == "" {
+			return &api.Page[int]{Items: []int{1, 2}, Next: "/next"}, nil
+		}
+		return &api.Page[int]{Items: []int{3}}, nil
+	}
+
+	tests := []struct {
+		name      string
+		limit     int
+		page      int
+		all       bool
+		want      []int
+		wantMore  bool
+		wantCalls []string
+		wantErr   bool
+	}{
+		{name: "no flags", want: []int{1, 2, 3}, wantCalls: []string{"all"}},
+		{name: "limit", limit: 2, want: []int{1, 2}, wantMore: true, wantCalls: []string{"page"}},
+		{name: "all pages", limit: 2, all: true, want: []int{1, 2, 3}, wantCalls: []string{"page", "page"}},
+		{name: "all with page", page: 2, all: true, wantErr: true},
+		{name: "negative", limit: -1, wantErr: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			calls = nil
+			pageLimit, pageNumber, pageAll = tc.limit, tc.page, tc.all
+			items, more, err := fetchList(context.Background(), all, page)
+			if tc.wantErr {
+				if err == nil {
+					t.Error("fetchList() error = nil, want error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("fetchList() error = %v", err)
+			}
+			if !slices.Equal(items, tc.want) || more != tc.wantMore || !slices.Equal(calls, tc.wantCalls) {
+				t.Errorf("fetchList() = %v, more %v, calls %v; want %v, more %v, calls %v",
+					items, more, calls, tc.want, tc.wantMore, tc.wantCalls)
+			}
+		})
+	}
+}
@@ -1,10 +1,17 @@
 package cli
 
 import (
+	"bufio"
+	"context"
 	"encoding/base64"
+	"errors"
 	"fmt"
+	"os"
+	"slices"
+	"strings"
 
 	"github.com/ravi-technologies/sunday-cli/internal/api"
+	"github.com/ravi-technologies/sunday-cli/internal/config"
 	"github.com/ravi-technologies/sunday-cli/internal/crypto"
 	"github.com/ravi-technologies/sunday-cli/internal/output"
 	"github.com/spf13/cobra"
@@ -21,6 +28,10 @@ var (
 	pwPassword     string
 	pwNotes        string
 	pwDomain       string
+
+	// pwFingerprints pins recipients' key fingerprints, as
+	// email=FINGERPRINT, instead of asking to confirm them.
+	pwFingerprints []string
 )
 
 var vaultCmd = &cobra.Command{
@@ -37,19 +48,24 @@ var pwListCmd = &cobra.Command{
 			return err
 		}
 
-		entries, err := client.ListPasswords()
+		entries, more, err := fetchList(cmd.Context(), client.ListPasswordsContext, client.ListPasswordsPage)
 		if err != nil {
 			return err
 		}
+		if more {
+			defer printMoreHint(cmd)
+		}
 
 		kp, err := ensureKeyPair()
 		if err != nil {
 			return err
 		}
 
+		var fields []*string
 		for i := range entries {
-			entries[i].Username = tryDecrypt(entries[i].Username, kp)
+			fields = append(fields, &entries[i].Username)
 		}
+		tryDecryptAll(fields, kp)
 
 		if jsonOutput {
 			return output.Current.Print(entries)
@@ -80,12 +96,20 @@ var pwGetCmd = &cobra.Command{
 	Short: "Show a stored password",
 	Args:  cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.Load()
+		if err != nil {
+			return fmt.Errorf("loading config: %w", err)
+		}
+		if err := requireTouchID(cfg, config.TouchIDRevealPassword, "reveal a stored password"); err != nil {
+			return err
+		}
+
 		client, err := api.NewClient(nil)
 		if err != nil {
 			return err
 		}
 
-		entry, err := client.GetPassword(args[0])
+		entry, err := client.GetPasswordContext(cmd.Context(), args[0])
 		if err != nil {
 			return err
 		}
@@ -139,7 +163,7 @@ var pwCreateCmd = &cobra.Command{
 				NoSpecial:    pwNoSpecial,
 				ExcludeChars: pwExcludeChars,
 			}
-			gen, err := client.GeneratePassword(opts)
+			gen, err := client.GeneratePasswordContext(cmd.Context(), opts)
 			if err != nil {
 				return fmt.Errorf("generating password: %w", err)
 			}
@@ -169,7 +193,7 @@ var pwCreateCmd = &cobra.Command{
 			Notes:    encNotes,
 		}
 
-		result, err := client.CreatePassword(entry)
+		result, err := client.CreatePasswordContext(cmd.Context(), entry)
 		if err != nil {
 			return err
 		}
@@ -197,28 +221,47 @@ var pwEditCmd = &cobra.Command{
 		if err != nil {
 			return err
 		}
-		pubKeyB64 := encodePublicKey(kp)
+		// A shared entry's fields stay readable by everyone it is shared
+		// with.
+		encrypt := func(plaintext string) (string, error) {
+			return crypto.Encrypt(plaintext, encodePublicKey(kp))
+		}
+		if cmd.Flags().Changed("username") || cmd.Flags().Changed("password") || cmd.Flags().Changed("notes") {
+			entry, err := client.GetPasswordContext(cmd.Context(), args[0])
+			if err != nil {
+				return err
+			}
+			if len(entry.SharedWith) > 0 {
+				keys, err := recipientKeys(cmd.Context(), client, kp, entry.SharedWith, sharedKeys(entry))
+				if err != nil {
+					return err
+				}
+				encrypt = func(plaintext string) (string, error) {
+					return crypto.EncryptForRecipients(plaintext, keys)
+				}
+			}
+		}
 
 		fields := map[string]interface{}{}
 		if cmd.Flags().Changed("domain") {
 			fields["domain"] = pwDomain
 		}
 		if cmd.Flags().Changed("username") {
-			enc, err := crypto.Encrypt(pwUsername, pubKeyB64)
+			enc, err := encrypt(pwUsername)
 			if err != nil {
 				return fmt.Errorf("encrypting username: %w", err)
 			}
 			fields["username"] = enc
 		}
 		if cmd.Flags().Changed("password") {
-			enc, err := crypto.Encrypt(pwPassword, pubKeyB64)
+			enc, err := encrypt(pwPassword)
 			if err != nil {
 				return fmt.Errorf("encrypting password: %w", err)
 			}
 			fields["password"] = enc
 		}
 		if cmd.Flags().Changed("notes") {
-			enc, err := crypto.Encrypt(pwNotes, pubKeyB64)
+			enc, err := encrypt(pwNotes)
 			if err != nil {
 				return fmt.Errorf("encrypting notes: %w", err)
 			}
@@ -229,7 +272,7 @@ var pwEditCmd = &cobra.Command{
 			return fmt.Errorf("no fields specified to update")
 		}
 
-		result, err := client.UpdatePassword(args[0], fields)
+		result, err := client.UpdatePasswordContext(cmd.Context(), args[0], fields)
 		if err != nil {
 			return err
 		}
@@ -253,7 +296,7 @@ var pwDeleteCmd = &cobra.Command{
 			return err
 		}
 
-		if err := client.DeletePassword(args[0]); err != nil {
+		if err := client.DeletePasswordContext(cmd.Context(), args[0]); err != nil {
 			return err
 		}
 
@@ -282,7 +325,7 @@ var pwGenerateCmd = &cobra.Command{
 			ExcludeChars: pwExcludeChars,
 		}
 
-		gen, err := client.GeneratePassword(opts)
+		gen, err := client.GeneratePasswordContext(cmd.Context(), opts)
 		if err != nil {
 			return err
 		}
@@ -296,6 +339,184 @@ var pwGenerateCmd = &cobra.Command{
 	},
 }
 
+var pwShareCmd = &cobra.Command{
+	Use:   "share <uuid> <email>...",
+	Short: "Share a stored password entry with other users",
+	Long: `Share a stored password entry with other Sunday users, by email.
+
+The entry's username, password and notes are re-encrypted so that you,
+everyone it was already shared with and the new recipients can each
+decrypt them with their own key. Recipients must have set up encryption,
+and the server must support sharing.
+
+Before anything is encrypted to a recipient's key, its fingerprint is shown
+for you to confirm: compare it with the one they see with
+` + "`sunday crypto fingerprint`" + `, so that a key substituted by the server
+isn't trusted. In scripts, pin the fingerprints with --fingerprint instead.
+Keys the entry is already encrypted to aren't asked about again.`,
+	Args: cobra.MinimumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := api.NewClient(nil)
+		if err != nil {
+			return err
+		}
+
+		result, err := sharePassword(cmd.Context(), client, args[0], args[1:])
+		if err != nil {
+			return err
+		}
+
+		if jsonOutput {
+			return output.Current.Print(result)
+		}
+
+		fmt.Printf("Password entry for %s shared with %s\n", result.Domain, strings.Join(result.SharedWith, ", "))
+		return nil
+	},
+}
+
+// sharePassword shares the entry uuid with emails, on top of anyone it is
+// already shared with, re-encrypting its fields to all of them and to the
+// owner.
+func sharePassword(ctx context.Context, client *api.Client, uuid string, emails []string) (*api.PasswordEntry, error) {
+	// Only a server that supports sharing can read what it writes.
+	info, err := client.GetServerInfoContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !info.Supports(api.FeatureVaultSharing) {
+		return nil, errors.New("this server doesn't support sharing password entries")
+	}
+
+	entry, err := client.GetPasswordContext(ctx, uuid)
+	if err != nil {
+		return nil, err
+	}
+	kp, err := ensureKeyPair()
+	if err != nil {
+		return nil, err
+	}
+
+	recipients := slices.Clone(entry.SharedWith)
+	for _, email := range emails {
+		if !slices.Contains(recipients, email) {
+			recipients = append(recipients, email)
+		}
+	}
+	keys, err := recipientKeys(ctx, client, kp, recipients, sharedKeys(entry))
+	if err != nil {
+		return nil, err
+	}
+
+	share := api.PasswordShare{Recipients: recipients}
+	for _, f := range []struct {
+		name     string
+		from, to *string
+	}{
+		{"username", &entry.Username, &share.Username},
+		{"password", &entry.Password, &share.Password},
+		{"notes", &entry.Notes, &share.Notes},
+	} {
+		plaintext, err := crypto.DecryptField(*f.from, kp)
+		if err != nil {
+			return nil, fmt.Errorf("decrypting %s: %w", f.name, err)
+		}
+		if *f.to, err = crypto.EncryptForRecipients(plaintext, keys); err != nil {
+			return nil, fmt.Errorf("encrypting %s: %w", f.name, err)
+		}
+	}
+
+	return client.SharePasswordContext(ctx, uuid, share)
+}
+
+// recipientKeys returns the public keys a shared entry's fields are
+// encrypted to: the owner's, from kp, and each recipient's. The server
+// hands out the recipients' keys, so any not in trusted, the keys the
+// entry is already encrypted to, must be confirmed first.
+func recipientKeys(ctx context.Context, client *api.Client, kp *crypto.KeyPair, recipients, trusted []string) ([]string, error) {
+	pins, err := parseFingerprintPins(pwFingerprints)
+	if err != nil {
+		return nil, err
+	}
+	keys := []string{encodePublicKey(kp)}
+	for _, email := range recipients {
+		key, err := client.GetRecipientKeyContext(ctx, email)
+		if err != nil {
+			return nil, fmt.Errorf("fetching the public key of %s: %w", email, err)
+		}
+		if !slices.Contains(trusted, key.PublicKey) {
+			if err := confirmRecipientKey(email, key.PublicKey, pins); err != nil {
+				return nil, err
+			}
+		}
+		keys = append(keys, key.PublicKey)
+	}
+	return keys, nil
+}
+
+// sharedKeys returns the public keys entry's fields are already encrypted
+// to, or nil if it isn't shared.
+func sharedKeys(entry *api.PasswordEntry) []string {
+	for _, v := range []string{entry.Password, entry.Username, entry.Notes} {
+		if keys, err := crypto.Recipients(v); err == nil {
+			return keys
+		}
+	}
+	return nil
+}
+
+// Seams for confirmRecipientKey, replaced in tests.
+var (
+	canConfirmRecipient = func() bool { return output.IsTerminal(os.Stdin) && output.IsTerminal(os.Stderr) }
+	readConfirmation    = func() bool {
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil {
+			return false
+		}
+		answer := strings.ToLower(strings.TrimSpace(line))
+		return answer == "y" || answer == "yes"
+	}
+)
+
+// confirmRecipientKey checks the key the server returned for email against
+// the fingerprint pinned for it, or else asks the user to compare its
+// fingerprint with the recipient's. Without a pin or a terminal to ask at,
+// the key is refused.
+func confirmRecipientKey(email, publicKey string, pins map[string]string) error {
+	fingerprint, err := crypto.Fingerprint(publicKey)
+	if err != nil {
+		return fmt.Errorf("the public key of %s: %w", email, err)
+	}
+	if pin, ok := pins[email]; ok {
+		if !crypto.FingerprintsMatch(fingerprint, pin) {
+			return fmt.Errorf("the key of %s has fingerprint %s, not the pinned %s; don't share with it until you know why", email, fingerprint, pin)
+		}
+		return nil
+	}
+	if !canConfirmRecipient() {
+		return fmt.Errorf("can't confirm the key of %s without a terminal: pass --fingerprint %s=<fingerprint>, which they can get with `sunday crypto fingerprint`", email, email)
+	}
+
+	fmt.Fprintf(os.Stderr, "Key fingerprint of %s:\n  %s\nCheck it with them (they can run `sunday crypto fingerprint`). Encrypt to this key? [y/N] ", email, fingerprint)
+	if !readConfirmation() {
+		return fmt.Errorf("the key of %s wasn't confirmed", email)
+	}
+	return nil
+}
+
+// parseFingerprintPins parses --fingerprint values, email=FINGERPRINT.
+func parseFingerprintPins(values []string) (map[string]string, error) {
+	pins := make(map[string]string, len(values))
+	for _, v := range values {
+		email, fingerprint, ok := strings.Cut(v, "=")
+		if !ok || email == "" || fingerprint == "" {
+			return nil, fmt.Errorf("invalid --fingerprint %q: want email=FINGERPRINT", v)
+		}
+		pins[email] = fingerprint
+	}
+	return pins, nil
+}
+
 // encodePublicKey converts a KeyPair's public key to base64.
 func encodePublicKey(kp *crypto.KeyPair) string {
 	return base64.StdEncoding.EncodeToString(kp.PublicKey[:])
@@ -311,12 +532,18 @@ func init() {
 	pwCreateCmd.Flags().StringVar(&pwExcludeChars, "exclude-chars", "", "Exclude specific characters")
 	pwCreateCmd.Flags().StringVar(&pwUsername, "username", "", "Username (defaults to identity email)")
 	pwCreateCmd.Flags().StringVar(&pwNotes, "notes", "", "Optional notes")
+	markSensitiveFlags(pwCreateCmd, "password", "username", "notes")
 
 	// Edit flags
 	pwEditCmd.Flags().StringVar(&pwDomain, "domain", "", "New domain")
 	pwEditCmd.Flags().StringVar(&pwUsername, "username", "", "New username")
 	pwEditCmd.Flags().StringVar(&pwPassword, "password", "", "New password")
 	pwEditCmd.Flags().StringVar(&pwNotes, "notes", "", "New notes")
+	pwEditCmd.Flags().StringArrayVar(&pwFingerprints, "fingerprint", nil, "Expected key fingerprint of a recipient of a shared entry, as email=FINGERPRINT (repeatable)")
+	markSensitiveFlags(pwEditCmd, "password", "username", "notes")
+
+	// Share flags
+	pwShareCmd.Flags().StringArrayVar(&pwFingerprints, "fingerprint", nil, "Expected key fingerprint of a recipient, as email=FINGERPRINT (repeatable), instead of confirming it")
 
 	// Generate flags
 	pwGenerateCmd.Flags().IntVar(&pwLength, "length", 16, "Password length")
@@ -325,11 +552,16 @@ func init() {
 	pwGenerateCmd.Flags().StringVar(&pwExcludeChars, "exclude-chars", "", "Exclude specific characters")
 
 	// Wire up command tree
+	enablePaging(pwListCmd)
+	addPaginationFlags(pwListCmd)
+	requireScope(scopeReadPasswords, pwListCmd, pwGetCmd)
+	requireScope(scopeWritePasswords, pwCreateCmd, pwEditCmd, pwDeleteCmd, pwShareCmd)
 	vaultCmd.AddCommand(pwListCmd)
 	vaultCmd.AddCommand(pwGetCmd)
 	vaultCmd.AddCommand(pwCreateCmd)
 	vaultCmd.AddCommand(pwEditCmd)
 	vaultCmd.AddCommand(pwDeleteCmd)
 	vaultCmd.AddCommand(pwGenerateCmd)
+	vaultCmd.AddCommand(pwShareCmd)
 	rootCmd.AddCommand(vaultCmd)
 }
@@ -0,0 +1,189 @@
+package cli
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/ravi-technologies/sunday-cli/internal/api"
+	"github.com/ravi-technologies/sunday-cli/internal/config"
+	"github.com/ravi-technologies/sunday-cli/internal/crypto"
+	"github.com/ravi-technologies/sunday-cli/pkg/sunday"
+	"github.com/ravi-technologies/sunday-cli/pkg/sundaytest"
+	"golang.org/x/crypto/nacl/box"
+)
+
+// addTeammate registers a user with a new key with server, pins their
+// key's fingerprint for the rest of the test, and returns the key.
+func addTeammate(t *testing.T, server *sundaytest.Server, email string) *crypto.KeyPair {
+	t.Helper()
+	pub, priv, err := box.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pubB64 := base64.StdEncoding.EncodeToString(pub[:])
+	server.AddUser(email, pubB64)
+	fingerprint, err := crypto.Fingerprint(pubB64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	orig := pwFingerprints
+	pwFingerprints = append(slices.Clone(pwFingerprints), email+"="+fingerprint)
+	t.Cleanup(func() { pwFingerprints = orig })
+	return &crypto.KeyPair{PublicKey: *pub, PrivateKey: *priv}
+}
+
+// withRecipientConfirmation replaces the confirmation prompt for recipient
+// keys with one answering answer, or with no terminal if prompt is false,
+// and returns a pointer to the prompt count.
+func withRecipientConfirmation(t *testing.T, prompt, answer bool) *int {
+	t.Helper()
+	calls := 0
+	origCan, origRead := canConfirmRecipient, readConfirmation
+	canConfirmRecipient = func() bool { return prompt }
+	readConfirmation = func() bool {
+		calls++
+		return answer
+	}
+	t.Cleanup(func() { canConfirmRecipient, readConfirmation = origCan, origRead })
+	return &calls
+}
+
+// TestSharePassword verifies that sharing an entry re-encrypts it so that
+// the owner and every recipient, old and new, can decrypt it.
+func TestSharePassword(t *testing.T) {
+	tmpDir, cleanup := withTempHome(t)
+	defer cleanup()
+
+	withRecipientConfirmation(t, false, false)
+	server := sundaytest.NewServer(t)
+	server.SetFeatures(sunday.FeatureVaultSharing)
+	kp := (*crypto.KeyPair)(server.KeyPair())
+	creds := server.Credentials()
+	saveTestConfig(t, tmpDir, &config.Config{
+		AccessToken: creds.AccessToken,
+		PrivateKey:  base64.StdEncoding.EncodeToString(kp.PrivateKey[:]),
+		PublicKey:   base64.StdEncoding.EncodeToString(kp.PublicKey[:]),
+	})
+	client := api.NewClientForURL(server.URL, &config.Config{AccessToken: creds.AccessToken}, nil)
+
+	teammates := map[string]*crypto.KeyPair{}
+	for _, email := range []string{"bob@example.com", "carol@example.com"} {
+		teammates[email] = addTeammate(t, server, email)
+	}
+	entry := server.AddPassword(sunday.PasswordEntry{Domain: "example.com", Username: "agent", Password: server.Encrypt("hunter2")})
+
+	if _, err := sharePassword(context.Background(), client, entry.UUID, []string{"bob@example.com"}); err != nil {
+		t.Fatalf("sharePassword() error = %v", err)
+	}
+	shared, err := sharePassword(context.Background(), client, entry.UUID, []string{"carol@example.com"})
+	if err != nil {
+		t.Fatalf("second sharePassword() error = %v", err)
+	}
+	if want := []string{"bob@example.com", "carol@example.com"}; !slices.Equal(shared.SharedWith, want) {
+		t.Errorf("SharedWith = %v, want %v", shared.SharedWith, want)
+	}
+
+	for _, reader := range []*crypto.KeyPair{kp, teammates["bob@example.com"], teammates["carol@example.com"]} {
+		if got, err := crypto.DecryptField(shared.Password, reader); err != nil || got != "hunter2" {
+			t.Errorf("DecryptField(password) = %q, %v; want hunter2", got, err)
+		}
+		if got, err := crypto.DecryptField(shared.Username, reader); err != nil || got != "agent" {
+			t.Errorf("DecryptField(username) = %q, %v; want agent", got, err)
+		}
+	}
+
+	if _, err := sharePassword(context.Background(), client, entry.UUID, []string{"mallory@example.com"}); err == nil {
+		t.Error("sharePassword() with an unknown recipient succeeded")
+	}
+}
+
+// TestSharePassword_ConfirmsKeys verifies that a recipient's key is only
+// used once its fingerprint is pinned or confirmed, and that a key the
+// entry is already encrypted to isn't asked about again.
+func TestSharePassword_ConfirmsKeys(t *testing.T) {
+	tmpDir, cleanup := withTempHome(t)
+	defer cleanup()
+
+	server := sundaytest.NewServer(t)
+	server.SetFeatures(sunday.FeatureVaultSharing)
+	kp := (*crypto.KeyPair)(server.KeyPair())
+	creds := server.Credentials()
+	saveTestConfig(t, tmpDir, &config.Config{
+		AccessToken: creds.AccessToken,
+		PrivateKey:  base64.StdEncoding.EncodeToString(kp.PrivateKey[:]),
+		PublicKey:   base64.StdEncoding.EncodeToString(kp.PublicKey[:]),
+	})
+	client := api.NewClientForURL(server.URL, &config.Config{AccessToken: creds.AccessToken}, nil)
+	entry := server.AddPassword(sunday.PasswordEntry{Domain: "example.com", Password: server.Encrypt("hunter2")})
+
+	pub, _, err := box.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server.AddUser("bob@example.com", base64.StdEncoding.EncodeToString(pub[:]))
+	share := func() error {
+		_, err := sharePassword(context.Background(), client, entry.UUID, []string{"bob@example.com"})
+		return err
+	}
+
+	withRecipientConfirmation(t, false, false)
+	if err := share(); err == nil || !strings.Contains(err.Error(), "--fingerprint bob@example.com=") {
+		t.Errorf("sharePassword() without a terminal or pin error = %v, want one suggesting --fingerprint", err)
+	}
+
+	orig := pwFingerprints
+	t.Cleanup(func() { pwFingerprints = orig })
+	pwFingerprints = []string{"bob@example.com=AAAA AAAA"}
+	if err := share(); err == nil || !strings.Contains(err.Error(), "not the pinned") {
+		t.Errorf("sharePassword() with a wrong pin error = %v, want a mismatch", err)
+	}
+	pwFingerprints = nil
+
+	calls := withRecipientConfirmation(t, true, false)
+	if err := share(); err == nil || *calls != 1 {
+		t.Errorf("sharePassword() declined = %v after %d prompts, want an error after 1", err, *calls)
+	}
+
+	calls = withRecipientConfirmation(t, true, true)
+	if err := share(); err != nil || *calls != 1 {
+		t.Fatalf("sharePassword() confirmed = %v after %d prompts, want nil after 1", err, *calls)
+	}
+	if err := share(); err != nil || *calls != 1 {
+		t.Errorf("sharePassword() again = %v after %d prompts, want no new prompt", err, *calls)
+	}
+}
+
+// TestSharePassword_Unsupported verifies that nothing is shared with a
+// server that doesn't report supporting it, since it couldn't read the
+// re-encrypted entry.
+func TestSharePassword_Unsupported(t *testing.T) {
+	tmpDir, cleanup := withTempHome(t)
+	defer cleanup()
+
+	server := sundaytest.NewServer(t)
+	kp := (*crypto.KeyPair)(server.KeyPair())
+	creds := server.Credentials()
+	saveTestConfig(t, tmpDir, &config.Config{
+		AccessToken: creds.AccessToken,
+		PrivateKey:  base64.StdEncoding.EncodeToString(kp.PrivateKey[:]),
+		PublicKey:   base64.StdEncoding.EncodeToString(kp.PublicKey[:]),
+	})
+	client := api.NewClientForURL(server.URL, &config.Config{AccessToken: creds.AccessToken}, nil)
+	addTeammate(t, server, "bob@example.com")
+	entry := server.AddPassword(sunday.PasswordEntry{Domain: "example.com", Password: server.Encrypt("hunter2")})
+
+	if _, err := sharePassword(context.Background(), client, entry.UUID, []string{"bob@example.com"}); err == nil || !strings.Contains(err.Error(), "doesn't support sharing") {
+		t.Errorf("sharePassword() error = %v, want one saying the server doesn't support sharing", err)
+	}
+	got, err := client.GetPassword(entry.UUID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Password != entry.Password {
+		t.Errorf("password = %q after a refused share, want it unchanged", got.Password)
+	}
+}
@@ -0,0 +1,381 @@
+package cli
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ravi-technologies/sunday-cli/internal/api"
+	"github.com/ravi-technologies/sunday-cli/internal/config"
+	"github.com/ravi-technologies/sunday-cli/internal/crypto"
+	"github.com/ravi-technologies/sunday-cli/internal/output"
+	"github.com/spf13/cobra"
+)
+
+// promptPIN reads a PIN from the terminal. Tests replace it.
+var promptPIN = crypto.PromptPIN
+
+var pinCmd = &cobra.Command{
+	Use:   "pin",
+	Short: "Manage your encryption PIN",
+}
+
+var pinChangeCmd = &cobra.Command{
+	Use:   "change",
+	Short: "Change your encryption PIN",
+	Long: `Change the PIN your encryption key is derived from: 6 digits, or a
+passphrase if your account's policy asks for one.
+
+A new PIN means a new key, so everything stored encrypted is re-encrypted
+to it: passwords, emails and SMS messages. The new key is registered with
+the server first, and replaces the old one once everything has been
+re-encrypted. This can take a while for a large inbox. If it is
+interrupted, run the command again with the same new PIN, on any machine,
+to finish; the old PIN keeps working until it does.
+
+Other machines logged in to the account must log in again afterwards.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := api.NewClient(nil)
+		if err != nil {
+			return err
+		}
+		if !client.IsAuthenticated() {
+			return errNotAuthenticated
+		}
+		return changePIN(cmd.Context(), client)
+	},
+}
+
+// changePIN moves the account's encryption key to one derived from a new
+// PIN.
+func changePIN(ctx context.Context, client *api.Client) error {
+	cur, err := verifyCurrentPIN(ctx, client)
+	if err != nil {
+		return err
+	}
+	defer cur.kp.Wipe()
+
+	newPIN, err := promptNewPIN("new ", cur.policy)
+	if err != nil {
+		return err
+	}
+	if newPIN == cur.pin {
+		return fmt.Errorf("the new %s is the same as the current one", cur.policy.Noun())
+	}
+
+	n, err := moveKey(ctx, client, cur.kp, newPIN, cur.params, "sunday pin change", nil)
+	if err != nil {
+		return err
+	}
+	noun := cur.policy.Noun()
+	output.Current.PrintMessage(fmt.Sprintf("%s%s changed; %d items re-encrypted", strings.ToUpper(noun[:1]), noun[1:], n))
+	return nil
+}
+
+// promptNewPIN asks for a PIN that policy accepts, then for it again to
+// confirm it. adj, such as "new ", qualifies the PIN in the prompts.
+func promptNewPIN(adj string, policy crypto.PINPolicy) (string, error) {
+	pin, err := promptPIN(fmt.Sprintf("Choose a %s%s: ", adj, policy.Requirement()), policy)
+	if err != nil {
+		return "", err
+	}
+	if err := policy.CheckNew(pin); err != nil {
+		return "", err
+	}
+	again, err := promptPIN(fmt.Sprintf("Confirm %s%s: ", adj, policy.Noun()), policy)
+	if err != nil {
+		return "", err
+	}
+	if again != pin {
+		return "", fmt.Errorf("the %s%ss don't match", adj, policy.Noun())
+	}
+	return pin, nil
+}
+
+// currentKey is the account's key, unlocked by its current PIN, with the
+// server's settings for deriving another.
+type currentKey struct {
+	kp     *crypto.KeyPair
+	pin    string
+	params crypto.KDFParams
+	policy crypto.PINPolicy
+}
+
+// verifyCurrentPIN prompts for the current PIN and checks it against the
+// server's key record, returning the key it unlocks.
+func verifyCurrentPIN(ctx context.Context, client *api.Client) (*currentKey, error) {
+	meta, err := client.GetEncryptionMetaContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("fetching encryption metadata: %w", err)
+	}
+	if crypto.UsesManagedKey(meta.ManagedMasterKey, meta.PublicKey, meta.Verifier) {
+		return nil, errors.New("this account's encryption key is managed by Sunday, not derived from a PIN")
+	}
+	if meta.PublicKey == "" {
+		return nil, errEncryptionNotSetUp
+	}
+	params, err := crypto.NewKDFParams(meta.KDF.Algorithm, meta.KDF.OpsLimit, meta.KDF.MemLimit)
+	if err != nil {
+		return nil, fmt.Errorf("rejecting server key derivation parameters: %w", err)
+	}
+	policy, err := crypto.NewPINPolicy(meta.PINPolicy.Kind, meta.PINPolicy.MinLength)
+	if err != nil {
+		return nil, fmt.Errorf("rejecting server PIN policy: %w", err)
+	}
+	salt, err := base64.StdEncoding.DecodeString(meta.Salt)
+	if err != nil {
+		return nil, fmt.Errorf("decoding salt: %w", err)
+	}
+
+	if err := crypto.CheckPINLockout(); err != nil {
+		return nil, err
+	}
+	pin, err := promptPIN(fmt.Sprintf("Current %s: ", policy.Noun()), policy)
+	if err != nil {
+		return nil, err
+	}
+	kp, err := crypto.DeriveKeyPairWithParams(pin, salt, params)
+	if err != nil {
+		return nil, fmt.Errorf("deriving keypair: %w", err)
+	}
+	if !crypto.Verify(kp, meta.Verifier) {
+		if err := crypto.RecordPINFailure(); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("incorrect %s", policy.Noun())
+	}
+	crypto.ResetPINLockout()
+	return &currentKey{kp: kp, pin: pin, params: params, policy: policy}, nil
+}
+
+// moveKey moves everything encrypted from oldKP to the key newPIN derives
+// with a fresh salt, returning how many items it re-encrypted. The new key
+// is registered with the server as pending before anything is re-encrypted
+// to it, so items moved to it are never encrypted to a key only this
+// machine knows, and the key record is only replaced by it once everything
+// has been, in one update. An interrupted move can be finished by running
+// retry again, from any machine.
+func moveKey(ctx context.Context, client *api.Client, oldKP *crypto.KeyPair, newPIN string, params crypto.KDFParams, retry string, progress reencryptProgress) (int, error) {
+	pending, newKP, err := pendingKeyPair(ctx, client, newPIN, params)
+	if err != nil {
+		return 0, err
+	}
+	defer newKP.Wipe()
+
+	n, err := reencryptAll(ctx, client, oldKP, newKP, progress)
+	if err != nil {
+		return n, fmt.Errorf("re-encrypting to the new key (run `%s` again to finish): %w", retry, err)
+	}
+
+	err = client.UpdateEncryptionMetaContext(ctx, map[string]string{
+		"salt":               pending.PendingSalt,
+		"verifier":           pending.PendingVerifier,
+		"public_key":         pending.PendingPublicKey,
+		"pending_salt":       "",
+		"pending_verifier":   "",
+		"pending_public_key": "",
+	})
+	if err != nil {
+		return n, fmt.Errorf("updating encryption metadata (run `%s` again to finish): %w", retry, err)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return n, err
+	}
+	cfg.PINSalt, cfg.PublicKey = pending.PendingSalt, pending.PendingPublicKey
+	cfg.KeyUsedAt = now()
+	if err := storePrivateKey(cfg, newKP.PrivateKey); err != nil {
+		return n, err
+	}
+	crypto.ClearCachedKeyPair()
+	return n, nil
+}
+
+// pendingKeyPair returns the key for newPIN, registering it with the server
+// as the pending key, and the server's record of it. The salt is new unless
+// an earlier change didn't finish, in which case newPIN must be the PIN
+// that one was to.
+func pendingKeyPair(ctx context.Context, client *api.Client, newPIN string, params crypto.KDFParams) (*api.EncryptionMeta, *crypto.KeyPair, error) {
+	meta, err := client.GetEncryptionMetaContext(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("fetching encryption metadata: %w", err)
+	}
+
+	saltB64 := meta.PendingSalt
+	var salt []byte
+	if saltB64 != "" {
+		salt, err = base64.StdEncoding.DecodeString(saltB64)
+	} else {
+		salt, err = crypto.NewSalt()
+		saltB64 = base64.StdEncoding.EncodeToString(salt)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	kp, err := crypto.DeriveKeyPairWithParams(newPIN, salt, params)
+	if err != nil {
+		return nil, nil, fmt.Errorf("deriving keypair: %w", err)
+	}
+
+	publicKey := base64.StdEncoding.EncodeToString(kp.PublicKey[:])
+	if meta.PendingPublicKey != "" {
+		if meta.PendingPublicKey != publicKey {
+			kp.Wipe()
+			return nil, nil, errors.New("an earlier PIN change to a different PIN didn't finish; enter that new PIN to finish it")
+		}
+		return meta, kp, nil
+	}
+
+	verifier, err := crypto.CreateVerifier(kp)
+	if err != nil {
+		kp.Wipe()
+		return nil, nil, err
+	}
+	err = client.UpdateEncryptionMetaContext(ctx, map[string]string{
+		"pending_salt":       saltB64,
+		"pending_verifier":   verifier,
+		"pending_public_key": publicKey,
+	})
+	if err != nil {
+		kp.Wipe()
+		return nil, nil, fmt.Errorf("registering the new key: %w", err)
+	}
+	// Nothing may be encrypted to the new key unless the server has it.
+	if meta, err = client.GetEncryptionMetaContext(ctx); err != nil {
+		kp.Wipe()
+		return nil, nil, fmt.Errorf("fetching encryption metadata: %w", err)
+	}
+	if meta.PendingPublicKey != publicKey {
+		kp.Wipe()
+		return nil, nil, errors.New("the server didn't record the new key, so nothing was re-encrypted; it may not support changing the key")
+	}
+	return meta, kp, nil
+}
+
+// reencryptProgress is told how many items of a kind ("passwords",
+// "emails" or "SMS messages") have been checked so far, out of total.
+type reencryptProgress func(kind string, done, total int)
+
+// reencryptAll re-encrypts every stored password, email and SMS from one
+// key to the other, returning how many it updated. Those already moved to
+// the new key are skipped. progress may be nil.
+func reencryptAll(ctx context.Context, client *api.Client, from, to *crypto.KeyPair, progress reencryptProgress) (int, error) {
+	if progress == nil {
+		progress = func(string, int, int) {}
+	}
+	updated := 0
+
+	entries, err := client.ListPasswordsContext(ctx)
+	if err != nil {
+		return updated, err
+	}
+	for i, e := range entries {
+		progress("passwords", i, len(entries))
+		fields, err := reencryptFields(from, to, map[string]string{
+			"username": e.Username,
+			"password": e.Password,
+			"notes":    e.Notes,
+		})
+		if err != nil {
+			return updated, fmt.Errorf("password for %s: %w", e.Domain, err)
+		}
+		if len(fields) > 0 {
+			if _, err := client.UpdatePasswordContext(ctx, e.UUID, fields); err != nil {
+				return updated, err
+			}
+			updated++
+		}
+	}
+
+	emails, err := client.ListEmailMessagesContext(ctx, false)
+	if err != nil {
+		return updated, err
+	}
+	progress("passwords", len(entries), len(entries))
+	for i, m := range emails {
+		progress("emails", i, len(emails))
+		fields, err := reencryptFields(from, to, map[string]string{
+			"subject":      m.Subject,
+			"text_content": m.TextContent,
+			"html_content": m.HTMLContent,
+		})
+		if err != nil {
+			return updated, fmt.Errorf("email %d: %w", m.ID, err)
+		}
+		if len(fields) > 0 {
+			if _, err := client.UpdateEmailMessageContext(ctx, strconv.Itoa(m.ID), fields); err != nil {
+				return updated, err
+			}
+			updated++
+		}
+	}
+
+	sms, err := client.ListSMSMessagesContext(ctx, false)
+	if err != nil {
+		return updated, err
+	}
+	progress("emails", len(emails), len(emails))
+	for i, m := range sms {
+		progress("SMS messages", i, len(sms))
+		fields, err := reencryptFields(from, to, map[string]string{"body": m.Body})
+		if err != nil {
+			return updated, fmt.Errorf("SMS %d: %w", m.ID, err)
+		}
+		if len(fields) > 0 {
+			if _, err := client.UpdateSMSMessageContext(ctx, strconv.Itoa(m.ID), fields); err != nil {
+				return updated, err
+			}
+			updated++
+		}
+	}
+	progress("SMS messages", len(sms), len(sms))
+	return updated, nil
+}
+
+// reencryptFields re-encrypts the named field values from one key to the
+// other, returning those that changed.
+func reencryptFields(from, to *crypto.KeyPair, values map[string]string) (map[string]interface{}, error) {
+	fields := map[string]interface{}{}
+	for name, value := range values {
+		moved, changed, err := crypto.ReencryptField(value, from, to)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		if changed {
+			fields[name] = moved
+		}
+	}
+	return fields, nil
+}
+
+// configPINLockout keeps the PIN lockout in the config file, as
+// crypto.PINLockoutStore.
+type configPINLockout struct{}
+
+func (configPINLockout) LoadLockout() (crypto.PINLockout, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return crypto.PINLockout{}, err
+	}
+	return crypto.PINLockout(cfg.PINLockout), nil
+}
+
+func (configPINLockout) UpdateLockout(fn func(*crypto.PINLockout)) error {
+	return config.Update(func(cfg *config.Config) error {
+		l := crypto.PINLockout(cfg.PINLockout)
+		fn(&l)
+		cfg.PINLockout = config.PINLockout(l)
+		return nil
+	})
+}
+
+func init() {
+	crypto.PINLockoutStore = configPINLockout{}
+	pinCmd.AddCommand(pinChangeCmd)
+	rootCmd.AddCommand(pinCmd)
+}
@@ -0,0 +1,243 @@
+package cli
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ravi-technologies/sunday-cli/internal/api"
+	"github.com/ravi-technologies/sunday-cli/internal/config"
+	"github.com/ravi-technologies/sunday-cli/internal/crypto"
+	"github.com/ravi-technologies/sunday-cli/pkg/sunday"
+	"github.com/ravi-technologies/sunday-cli/pkg/sundaytest"
+)
+
+// withPINs makes promptPIN answer with pins in turn.
+func withPINs(t *testing.T, pins ...string) {
+	t.Helper()
+	orig := promptPIN
+	t.Cleanup(func() { promptPIN = orig })
+	promptPIN = func(string, crypto.PINPolicy) (string, error) {
+		if len(pins) == 0 {
+			t.Fatal("prompted for more PINs than expected")
+		}
+		pin := pins[0]
+		pins = pins[1:]
+		return pin, nil
+	}
+}
+
+// TestChangePIN verifies that changing the PIN re-encrypts stored data to
+// the new key, replaces the server's key record and stores the new key.
+func TestChangePIN(t *testing.T) {
+	tmpDir, cleanup := withTempHome(t)
+	defer cleanup()
+
+	server := sundaytest.NewServer(t)
+	server.AddPassword(sunday.PasswordEntry{Domain: "example.com", Password: server.Encrypt("hunter2")})
+	server.AddEmail(sunday.SundayEmailMessage{Subject: server.Encrypt("Hello"), TextContent: "plain"})
+	server.AddSMS(sunday.SundayPhoneMessage{Body: server.Encrypt("Your code is 1234")})
+	creds := server.Credentials()
+	saveTestConfig(t, tmpDir, &config.Config{AccessToken: creds.AccessToken, RefreshToken: creds.RefreshToken, ExpiresAt: creds.ExpiresAt})
+	client := api.NewClientForURL(server.URL, &config.Config{AccessToken: creds.AccessToken, RefreshToken: creds.RefreshToken, ExpiresAt: creds.ExpiresAt}, nil)
+
+	withPINs(t, sundaytest.PIN, "654321", "654321")
+	if err := changePIN(context.Background(), client); err != nil {
+		t.Fatalf("changePIN() error = %v", err)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("config.Load() error = %v", err)
+	}
+	salt, _ := base64.StdEncoding.DecodeString(cfg.PINSalt)
+	kp, err := crypto.DeriveKeyPair("654321", salt)
+	if err != nil {
+		t.Fatalf("DeriveKeyPair() error = %v", err)
+	}
+	if got := base64.StdEncoding.EncodeToString(kp.PrivateKey[:]); got != cfg.PrivateKey {
+		t.Error("stored private key isn't the one the new PIN derives")
+	}
+
+	meta, err := client.GetEncryptionMeta()
+	if err != nil {
+		t.Fatalf("GetEncryptionMeta() error = %v", err)
+	}
+	if !crypto.Verify(kp, meta.Verifier) || meta.Salt != cfg.PINSalt {
+		t.Error("server's key record doesn't match the new PIN")
+	}
+	if meta.PendingSalt != "" || meta.PendingPublicKey != "" {
+		t.Error("pending key left on the server")
+	}
+
+	decrypt := func(field string) string {
+		t.Helper()
+		plaintext, err := crypto.DecryptField(field, kp)
+		if err != nil {
+			t.Errorf("DecryptField(%q) error = %v", field, err)
+		}
+		return plaintext
+	}
+	if got := decrypt(server.Passwords()[0].Password); got != "hunter2" {
+		t.Errorf("password = %q, want hunter2", got)
+	}
+	emails, _ := client.ListEmailMessages(false)
+	if got := decrypt(emails[0].Subject); got != "Hello" || emails[0].TextContent != "plain" {
+		t.Errorf("email = %q, %q; want Hello, plain", got, emails[0].TextContent)
+	}
+	sms, _ := client.ListSMSMessages(false)
+	if got := decrypt(sms[0].Body); got != "Your code is 1234" {
+		t.Errorf("SMS body = %q", got)
+	}
+}
+
+// TestChangePIN_Resume verifies that a change that didn't finish is
+// picked up from the pending key registered with the server, whichever
+// machine started it, and only with the PIN it was to.
+func TestChangePIN_Resume(t *testing.T) {
+	tmpDir, cleanup := withTempHome(t)
+	defer cleanup()
+
+	server := sundaytest.NewServer(t)
+	creds := server.Credentials()
+	saveTestConfig(t, tmpDir, &config.Config{AccessToken: creds.AccessToken})
+	client := api.NewClientForURL(server.URL, &config.Config{AccessToken: creds.AccessToken}, nil)
+
+	// Another machine registered the key for 654321, then stopped.
+	salt, err := crypto.NewSalt()
+	if err != nil {
+		t.Fatal(err)
+	}
+	pending, err := crypto.DeriveKeyPair("654321", salt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	verifier, err := crypto.CreateVerifier(pending)
+	if err != nil {
+		t.Fatal(err)
+	}
+	saltB64 := base64.StdEncoding.EncodeToString(salt)
+	err = client.UpdateEncryptionMeta(map[string]string{
+		"pending_salt":       saltB64,
+		"pending_verifier":   verifier,
+		"pending_public_key": base64.StdEncoding.EncodeToString(pending.PublicKey[:]),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	withPINs(t, sundaytest.PIN, "111111", "111111")
+	if err := changePIN(context.Background(), client); err == nil || !strings.Contains(err.Error(), "different PIN") {
+		t.Errorf("changePIN() to another PIN error = %v, want one about the unfinished change", err)
+	}
+	withPINs(t, sundaytest.PIN, "654321", "654321")
+	if err := changePIN(context.Background(), client); err != nil {
+		t.Fatalf("changePIN() error = %v", err)
+	}
+	meta, err := client.GetEncryptionMeta()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if meta.Salt != saltB64 || !crypto.Verify(pending, meta.Verifier) || meta.PendingSalt != "" {
+		t.Error("server's key record isn't the pending key")
+	}
+}
+
+// TestChangePIN_PendingNotRecorded verifies that nothing is re-encrypted
+// if the server doesn't record the new key as pending.
+func TestChangePIN_PendingNotRecorded(t *testing.T) {
+	tmpDir, cleanup := withTempHome(t)
+	defer cleanup()
+
+	server := sundaytest.NewServer(t)
+	server.AddPassword(sunday.PasswordEntry{Domain: "example.com", Password: server.Encrypt("hunter2")})
+	// A server that ignores the pending key, as one without support for
+	// it would.
+	ignoring := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPatch && r.URL.Path == api.PathEncryption {
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte("{}"))
+			return
+		}
+		server.Config.Handler.ServeHTTP(w, r)
+	}))
+	defer ignoring.Close()
+	creds := server.Credentials()
+	saveTestConfig(t, tmpDir, &config.Config{AccessToken: creds.AccessToken})
+	client := api.NewClientForURL(ignoring.URL, &config.Config{AccessToken: creds.AccessToken}, nil)
+
+	before := server.Passwords()[0].Password
+	withPINs(t, sundaytest.PIN, "654321", "654321")
+	if err := changePIN(context.Background(), client); err == nil || !strings.Contains(err.Error(), "didn't record the new key") {
+		t.Errorf("changePIN() error = %v, want one saying the key wasn't recorded", err)
+	}
+	if server.Passwords()[0].Password != before {
+		t.Error("password re-encrypted to a key the server doesn't have")
+	}
+}
+
+// TestChangePIN_Rejected verifies that a wrong current PIN or a mistyped
+// confirmation changes nothing.
+func TestChangePIN_Rejected(t *testing.T) {
+	tmpDir, cleanup := withTempHome(t)
+	defer cleanup()
+
+	server := sundaytest.NewServer(t)
+	creds := server.Credentials()
+	saveTestConfig(t, tmpDir, &config.Config{AccessToken: creds.AccessToken})
+	client := api.NewClientForURL(server.URL, &config.Config{AccessToken: creds.AccessToken}, nil)
+
+	withPINs(t, "000000")
+	if err := changePIN(context.Background(), client); err == nil || err.Error() != "incorrect PIN" {
+		t.Errorf("changePIN() with the wrong PIN error = %v", err)
+	}
+	withPINs(t, sundaytest.PIN, "654321", "654320")
+	if err := changePIN(context.Background(), client); err == nil || err.Error() != "the new PINs don't match" {
+		t.Errorf("changePIN() with a mistyped PIN error = %v", err)
+	}
+
+	meta, _ := client.GetEncryptionMeta()
+	if !crypto.Verify((*crypto.KeyPair)(server.KeyPair()), meta.Verifier) {
+		t.Error("server's key record changed")
+	}
+}
+
+// TestChangePIN_Lockout verifies that incorrect PINs are counted in the
+// config file, surviving logout, and that once too many have been entered
+// even the correct PIN is refused.
+func TestChangePIN_Lockout(t *testing.T) {
+	tmpDir, cleanup := withTempHome(t)
+	defer cleanup()
+
+	server := sundaytest.NewServer(t)
+	creds := server.Credentials()
+	saveTestConfig(t, tmpDir, &config.Config{AccessToken: creds.AccessToken})
+	client := api.NewClientForURL(server.URL, &config.Config{AccessToken: creds.AccessToken}, nil)
+
+	for range 3 {
+		withPINs(t, "000000")
+		if err := changePIN(context.Background(), client); err == nil || err.Error() != "incorrect PIN" {
+			t.Fatalf("changePIN() with the wrong PIN error = %v", err)
+		}
+	}
+	if err := config.Clear(); err != nil {
+		t.Fatalf("config.Clear() error = %v", err)
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("config.Load() error = %v", err)
+	}
+	if cfg.PINLockout.Failures != 3 || !cfg.PINLockout.Until.After(time.Now()) {
+		t.Errorf("PINLockout after logout = %+v, want 3 failures and locked", cfg.PINLockout)
+	}
+
+	withPINs(t)
+	if err := changePIN(context.Background(), client); !errors.Is(err, crypto.ErrPINLocked) {
+		t.Errorf("changePIN() while locked error = %v, want crypto.ErrPINLocked", err)
+	}
+}
@@ -0,0 +1,63 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"runtime"
+	"runtime/pprof"
+)
+
+// Profiling flags. They are hidden from help: they exist so maintainers can
+// ask users for profiles of slow commands without shipping a custom build.
+var (
+	cpuProfilePath string
+	memProfilePath string
+
+	// cpuProfileFile is the open CPU profile, closed by stopProfiling.
+	cpuProfileFile *os.File
+)
+
+// startProfiling begins CPU profiling if --cpuprofile is set.
+func startProfiling() error {
+	if cpuProfilePath == "" {
+		return nil
+	}
+	f, err := os.Create(cpuProfilePath)
+	if err != nil {
+		return fmt.Errorf("creating CPU profile: %w", err)
+	}
+	if err := pprof.StartCPUProfile(f); err != nil {
+		f.Close()
+		return fmt.Errorf("starting CPU profile: %w", err)
+	}
+	cpuProfileFile = f
+	return nil
+}
+
+// stopProfiling finishes the CPU profile and writes the heap profile if
+// --memprofile is set.
+func stopProfiling() error {
+	var errs []error
+	if cpuProfileFile != nil {
+		pprof.StopCPUProfile()
+		if err := cpuProfileFile.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("writing CPU profile: %w", err))
+		}
+		cpuProfileFile = nil
+	}
+
+	if memProfilePath != "" {
+		f, err := os.Create(memProfilePath)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("creating memory profile: %w", err))
+		} else {
+			runtime.GC() // get up-to-date statistics
+			if err := pprof.WriteHeapProfile(f); err != nil {
+				errs = append(errs, fmt.Errorf("writing memory profile: %w", err))
+			}
+			f.Close()
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,40 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestProfiling verifies that both profiles are written when requested.
+func TestProfiling(t *testing.T) {
+	dir := t.TempDir()
+	origCPU, origMem := cpuProfilePath, memProfilePath
+	defer func() { cpuProfilePath, memProfilePath = origCPU, origMem }()
+	cpuProfilePath = filepath.Join(dir, "cpu.pprof")
+	memProfilePath = filepath.Join(dir, "mem.pprof")
+
+	if err := startProfiling(); err != nil {
+		t.Fatalf("startProfiling() error = %v", err)
+	}
+	if err := stopProfiling(); err != nil {
+		t.Fatalf("stopProfiling() error = %v", err)
+	}
+
+	for _, path := range []string{cpuProfilePath, memProfilePath} {
+		info, err := os.Stat(path)
+		if err != nil || info.Size() == 0 {
+			t.Errorf("profile %s missing or empty (err = %v)", filepath.Base(path), err)
+		}
+	}
+}
+
+// TestProfilingFlagsHidden verifies that the profiling flags stay out of help.
+func TestProfilingFlagsHidden(t *testing.T) {
+	for _, name := range []string{"cpuprofile", "memprofile"} {
+		f := rootCmd.PersistentFlags().Lookup(name)
+		if f == nil || !f.Hidden {
+			t.Errorf("--%s should exist and be hidden", name)
+		}
+	}
+}
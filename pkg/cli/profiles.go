@@ -0,0 +1,128 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/ravi-technologies/sunday-cli/internal/config"
+	"github.com/ravi-technologies/sunday-cli/internal/output"
+	"github.com/spf13/cobra"
+)
+
+// profileName is the --profile flag.
+var profileName string
+
+var profilesCmd = &cobra.Command{
+	Use:   "profile",
+	Short: "Manage named profiles",
+	Long: `Manage named profiles.
+
+Each profile has its own login, keys and contacts, so you can switch
+between Sunday accounts. The default profile lives in ~/.sunday and other
+profiles in ~/.sunday/profiles/<name>.
+
+The active profile is chosen by --profile, then $SUNDAY_PROFILE, then
+"sunday profile switch".`,
+}
+
+var profilesListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List profiles",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		profiles, err := config.ListProfiles()
+		if err != nil {
+			return err
+		}
+
+		if jsonOutput {
+			return output.Current.Print(profiles)
+		}
+
+		headers := []string{"", "NAME", "ACCOUNT"}
+		rows := make([][]string, len(profiles))
+		for i, p := range profiles {
+			marker := ""
+			if p.Active {
+				marker = "*"
+			}
+			account := p.UserEmail
+			if account == "" {
+				account = "(not logged in)"
+			}
+			rows[i] = []string{marker, p.Name, account}
+		}
+		output.Current.PrintTable(headers, rows)
+		return nil
+	},
+}
+
+var profilesCreateCmd = &cobra.Command{
+	Use:   "create <name>",
+	Short: "Create a profile",
+	Example: `  sunday profile create work
+  sunday --profile work auth login`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := config.CreateProfile(args[0]); err != nil {
+			return err
+		}
+
+		if jsonOutput {
+			return output.Current.Print(map[string]string{"status": "created", "profile": args[0]})
+		}
+
+		fmt.Printf("Profile %q created. Log in with: sunday --profile %s auth login\n", args[0], args[0])
+		return nil
+	},
+}
+
+var profilesSwitchCmd = &cobra.Command{
+	Use:   "switch <name>",
+	Short: "Make a profile the active one",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := config.SwitchProfile(args[0]); err != nil {
+			return err
+		}
+
+		if jsonOutput {
+			return output.Current.Print(map[string]string{"status": "switched", "profile": args[0]})
+		}
+
+		fmt.Printf("Switched to profile %q.\n", args[0])
+		return nil
+	},
+}
+
+// selectProfile applies the --profile flag and checks that the active
+// profile is a valid name, so SUNDAY_PROFILE or the saved profile can't
+// point outside the profiles directory, and that it exists, so a typo
+// doesn't silently act as a logged-out account. "profile create" may name
+// a profile that doesn't exist yet, and "profile switch" replaces the
+// saved profile, so neither needs the active one.
+func selectProfile(cmd *cobra.Command) error {
+	if profileName != "" {
+		if err := config.ValidateProfileName(profileName); err != nil {
+			return err
+		}
+		config.SetProfile(profileName)
+	}
+	if cmd == profilesCreateCmd || cmd == profilesSwitchCmd {
+		return nil
+	}
+	name := config.ActiveProfile()
+	if err := config.ValidateProfileName(name); err != nil {
+		return fmt.Errorf("active profile: %w (set by --profile, %s or sunday profile switch)", err, config.EnvProfile)
+	}
+	if !config.ProfileExists(name) {
+		return fmt.Errorf("profile %q does not exist (create it with: sunday profile create %s)", name, name)
+	}
+	return nil
+}
+
+func init() {
+	profilesCmd.AddCommand(profilesListCmd)
+	profilesCmd.AddCommand(profilesCreateCmd)
+	profilesCmd.AddCommand(profilesSwitchCmd)
+	rootCmd.AddCommand(profilesCmd)
+}
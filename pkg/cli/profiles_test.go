@@ -0,0 +1,58 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ravi-technologies/sunday-cli/internal/config"
+)
+
+// TestSelectProfile verifies that --profile selects an existing profile
+// and that unknown or invalid names are rejected, except when creating.
+func TestSelectProfile(t *testing.T) {
+	_, cleanup := withTempHome(t)
+	defer cleanup()
+	defer func() {
+		profileName = ""
+		config.SetProfile("")
+	}()
+
+	if err := selectProfile(profilesListCmd); err != nil {
+		t.Fatalf("selectProfile() with default profile error = %v", err)
+	}
+
+	profileName = "work"
+	err := selectProfile(profilesListCmd)
+	if err == nil || !strings.Contains(err.Error(), "sunday profile create work") {
+		t.Errorf("selectProfile() for missing profile error = %v, want create hint", err)
+	}
+	if err := selectProfile(profilesCreateCmd); err != nil {
+		t.Errorf("selectProfile() for profile create error = %v", err)
+	}
+
+	if err := config.CreateProfile("work"); err != nil {
+		t.Fatalf("CreateProfile() error = %v", err)
+	}
+	if err := selectProfile(profilesListCmd); err != nil {
+		t.Errorf("selectProfile() for existing profile error = %v", err)
+	}
+	if got := config.ActiveProfile(); got != "work" {
+		t.Errorf("ActiveProfile() = %q, want work", got)
+	}
+
+	profileName = "../work"
+	if err := selectProfile(profilesListCmd); err == nil {
+		t.Error("selectProfile() with invalid name succeeded, want error")
+	}
+
+	// SUNDAY_PROFILE and the saved profile are checked as well as --profile.
+	profileName = ""
+	config.SetProfile("")
+	t.Setenv(config.EnvProfile, "../..")
+	if err := selectProfile(profilesListCmd); err == nil || !strings.Contains(err.Error(), "invalid profile name") {
+		t.Errorf("selectProfile() with %s=../.. error = %v, want invalid name", config.EnvProfile, err)
+	}
+	if err := selectProfile(profilesSwitchCmd); err != nil {
+		t.Errorf("selectProfile() for profile switch error = %v, want the saved profile replaceable", err)
+	}
+}
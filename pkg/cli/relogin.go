@@ -0,0 +1,58 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ravi-technologies/sunday-cli/internal/api"
+	"github.com/ravi-technologies/sunday-cli/internal/auth"
+	"github.com/ravi-technologies/sunday-cli/internal/output"
+)
+
+// errLoggedInAgain is returned after a command failed on an expired
+// session and the user logged in again; the command itself did not run.
+var errLoggedInAgain = errors.New("logged in again; run the command again to continue")
+
+// Seams for offerRelogin, replaced in tests.
+var (
+	canPromptRelogin = func() bool { return output.IsTerminal(os.Stdin) && output.IsTerminal(os.Stderr) }
+	confirmRelogin   = func() bool {
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil {
+			return false
+		}
+		answer := strings.ToLower(strings.TrimSpace(line))
+		return answer == "" || answer == "y" || answer == "yes"
+	}
+	runRelogin = func(ctx context.Context) error {
+		flow, err := auth.NewDeviceFlow()
+		if err != nil {
+			return err
+		}
+		return flow.Run(ctx)
+	}
+)
+
+// offerRelogin handles a command that failed because the session expired:
+// at an interactive terminal it offers to run the device flow again. In
+// --json mode, or without a terminal, err is returned unchanged and the
+// error hint tells the user to run `sunday auth login`.
+func offerRelogin(err error) error {
+	if !errors.Is(err, api.ErrSessionExpired) || jsonOutput || !canPromptRelogin() {
+		return err
+	}
+
+	fmt.Fprint(os.Stderr, "Your session has expired. Log in again now? [Y/n] ")
+	if !confirmRelogin() {
+		return err
+	}
+	if loginErr := runRelogin(context.Background()); loginErr != nil {
+		return fmt.Errorf("logging in again: %w", loginErr)
+	}
+	runLoginHook(os.Stderr)
+	return errLoggedInAgain
+}
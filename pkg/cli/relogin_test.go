@@ -0,0 +1,64 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/ravi-technologies/sunday-cli/internal/api"
+)
+
+// withReloginSeams replaces the prompt and login used by offerRelogin.
+func withReloginSeams(t *testing.T, interactive, confirm bool, login func(context.Context) error) {
+	t.Helper()
+	origCan, origConfirm, origRun := canPromptRelogin, confirmRelogin, runRelogin
+	canPromptRelogin = func() bool { return interactive }
+	confirmRelogin = func() bool { return confirm }
+	runRelogin = login
+	t.Cleanup(func() { canPromptRelogin, confirmRelogin, runRelogin = origCan, origConfirm, origRun })
+}
+
+// TestOfferRelogin verifies when an expired session leads to a new login.
+func TestOfferRelogin(t *testing.T) {
+	expired := fmt.Errorf("token refresh failed: %w", api.ErrSessionExpired)
+
+	t.Run("accepted", func(t *testing.T) {
+		ran := false
+		withReloginSeams(t, true, true, func(context.Context) error { ran = true; return nil })
+		if err := offerRelogin(expired); !errors.Is(err, errLoggedInAgain) || !ran {
+			t.Errorf("offerRelogin() = %v, ran login = %v; want errLoggedInAgain after login", err, ran)
+		}
+	})
+
+	t.Run("declined", func(t *testing.T) {
+		withReloginSeams(t, true, false, func(context.Context) error { t.Error("login ran after decline"); return nil })
+		if err := offerRelogin(expired); err != expired {
+			t.Errorf("offerRelogin() = %v, want original error", err)
+		}
+	})
+
+	t.Run("not a terminal", func(t *testing.T) {
+		withReloginSeams(t, false, true, func(context.Context) error { t.Error("login ran without a terminal"); return nil })
+		if err := offerRelogin(expired); err != expired {
+			t.Errorf("offerRelogin() = %v, want original error", err)
+		}
+	})
+
+	t.Run("json output", func(t *testing.T) {
+		withReloginSeams(t, true, true, func(context.Context) error { t.Error("login ran in --json mode"); return nil })
+		jsonOutput = true
+		defer func() { jsonOutput = false }()
+		if err := offerRelogin(expired); err != expired {
+			t.Errorf("offerRelogin() = %v, want original error", err)
+		}
+	})
+
+	t.Run("other errors", func(t *testing.T) {
+		withReloginSeams(t, true, true, func(context.Context) error { t.Error("login ran for an unrelated error"); return nil })
+		other := errors.New("boom")
+		if err := offerRelogin(other); err != other {
+			t.Errorf("offerRelogin() = %v, want original error", err)
+		}
+	})
+}
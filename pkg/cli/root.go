@@ -1,13 +1,47 @@
 package cli
 
 import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"runtime/debug"
+	"strconv"
+	"time"
+
+	"github.com/ravi-technologies/sunday-cli/internal/api"
+	"github.com/ravi-technologies/sunday-cli/internal/config"
+	"github.com/ravi-technologies/sunday-cli/internal/crypto"
+	"github.com/ravi-technologies/sunday-cli/internal/logging"
 	"github.com/ravi-technologies/sunday-cli/internal/output"
+	"github.com/ravi-technologies/sunday-cli/internal/timing"
 	"github.com/ravi-technologies/sunday-cli/internal/version"
 	"github.com/spf13/cobra"
 )
 
 var (
 	jsonOutput bool
+	harPath    string
+	configPath string
+	noCache    bool
+	offline    bool
+	showTiming bool
+	retries    int
+	retryDelay time.Duration
+	debugHTTP  bool
+	apiURL     string
+	pinStdin   bool
+	pinFile    string
+
+	// requestTimeout is --timeout.
+	requestTimeout time.Duration
+
+	// harRecorder captures API traffic when --har is set. It is written out
+	// by Execute once the command has finished.
+	harRecorder *api.HARRecorder
+
+	// closeLog closes the log file opened for this invocation, if any.
+	closeLog func() error
 )
 
 // rootCmd is the base command
@@ -16,8 +50,61 @@ var rootCmd = &cobra.Command{
 	Short: "Sunday CLI - Access your inbox programmatically",
 	Long: `Sunday CLI provides command-line access to your Sunday inbox,
 including emails and SMS messages. Designed for AI agents and automation.`,
-	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
 		output.SetJSON(jsonOutput)
+		if configPath != "" {
+			config.SetLocation(configPath)
+		}
+		if err := selectProfile(cmd); err != nil {
+			return err
+		}
+		if err := selectAccount(cmd); err != nil {
+			return err
+		}
+		if err := checkScope(cmd); err != nil {
+			return err
+		}
+		if offline && noCache {
+			return fmt.Errorf("--offline reads from the cache, so it can't be used with --no-cache")
+		}
+		api.DisableCache, api.Offline = noCache, offline
+		if noCache {
+			crypto.SetDecryptCacheSize(0)
+		}
+		if retries < 0 || retryDelay < 0 {
+			return fmt.Errorf("--retries and --retry-delay can't be negative")
+		}
+		api.MaxRetries, api.RetryDelay = retries, retryDelay
+		if requestTimeout < 0 {
+			return fmt.Errorf("--timeout can't be negative")
+		}
+		api.Timeout = requestTimeout
+		api.APIURL = apiURL
+		if pinStdin && pinFile != "" {
+			return fmt.Errorf("--pin-stdin and --pin-file can't be used together")
+		}
+		crypto.PINStdin, crypto.PINFile = pinStdin, pinFile
+		if !isLogsCommand(cmd) {
+			if closer, err := logging.Init(logging.LevelFromEnv()); err == nil {
+				closeLog = closer
+				api.DefaultTransport = logging.Transport(api.DefaultTransport)
+				slog.Info("command started", "command", cmd.CommandPath(), "version", version.Version)
+			}
+		}
+		if showTiming {
+			timing.Enable()
+			api.DefaultTransport = timing.Transport(api.DefaultTransport)
+		}
+		if harPath != "" {
+			harRecorder = api.NewHARRecorder(api.DefaultTransport)
+			api.DefaultTransport = harRecorder
+		}
+		if debugHTTP || debugFromEnv() {
+			api.DefaultTransport = api.NewDebugTransport(api.DefaultTransport, cmd.ErrOrStderr())
+		}
+		warnDeprecated(cmd)
+		startPaging(cmd)
+		return startProfiling()
 	},
 	SilenceUsage:  true,
 	SilenceErrors: true,
@@ -25,11 +112,70 @@ including emails and SMS messages. Designed for AI agents and automation.`,
 
 // Execute runs the root command
 func Execute() error {
+	err := executeRoot()
+	if pageErr := finishPaging(); pageErr != nil {
+		err = errors.Join(err, pageErr)
+	}
+	err = offerRelogin(err)
+	if profErr := stopProfiling(); profErr != nil {
+		err = errors.Join(err, profErr)
+	}
+	warnOffline(os.Stderr, time.Now())
+	timing.Report(os.Stderr)
+	if closeLog != nil {
+		if err != nil {
+			slog.Error("command failed", "error", err)
+		} else {
+			slog.Info("command finished")
+		}
+		closeLog()
+	}
+	if harRecorder != nil {
+		if harErr := harRecorder.WriteFile(harPath); harErr != nil {
+			err = errors.Join(err, harErr)
+		}
+	}
+	return err
+}
+
+// debugFromEnv reports whether SUNDAY_DEBUG asks for request tracing.
+func debugFromEnv() bool {
+	on, _ := strconv.ParseBool(os.Getenv(api.EnvDebug))
+	return on
+}
+
+// executeRoot runs the root command, turning a panic into a crashError
+// with a crash report on disk instead of a raw Go stack dump.
+func executeRoot() (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = newCrashError(r, debug.Stack(), os.Args)
+		}
+	}()
 	return rootCmd.Execute()
 }
 
 func init() {
 	rootCmd.PersistentFlags().BoolVar(&jsonOutput, "json", false, "Output in JSON format")
+	rootCmd.PersistentFlags().StringVar(&harPath, "har", "", "Record API traffic (redacted) to a HAR file")
+	rootCmd.PersistentFlags().BoolVar(&showTiming, "timing", false, "Print how long each phase of the command took (to stderr)")
+	rootCmd.PersistentFlags().BoolVar(&debugHTTP, "debug", false, "Trace API requests and responses, with credentials redacted, to stderr (or set "+api.EnvDebug+"=1)")
+	rootCmd.PersistentFlags().StringVar(&cpuProfilePath, "cpuprofile", "", "Write a CPU profile to this file")
+	rootCmd.PersistentFlags().StringVar(&memProfilePath, "memprofile", "", "Write a heap profile to this file on exit")
+	_ = rootCmd.PersistentFlags().MarkHidden("cpuprofile")
+	_ = rootCmd.PersistentFlags().MarkHidden("memprofile")
+	rootCmd.PersistentFlags().BoolVar(&noPager, "no-pager", false, "Never page long output")
+	rootCmd.PersistentFlags().BoolVar(&noCache, "no-cache", false, "Bypass local caches and fetch fresh data")
+	rootCmd.PersistentFlags().BoolVar(&offline, "offline", false, "Show data from the local cache without contacting the API")
+	rootCmd.PersistentFlags().IntVar(&retries, "retries", api.DefaultMaxRetries, "Retry read-only and other idempotent requests this many times after a transient failure")
+	rootCmd.PersistentFlags().DurationVar(&retryDelay, "retry-delay", api.DefaultRetryDelay, "Wait before the first retry; doubles for each later one, with jitter")
+	rootCmd.PersistentFlags().DurationVar(&requestTimeout, "timeout", 0, "Give up on an API request after this long (default 30s, or the api.timeout setting)")
+	rootCmd.PersistentFlags().StringVar(&apiURL, "api-url", "", "API to talk to instead of the built-in one, e.g. staging (or $"+api.EnvAPIURL+", or the api.base_url setting)")
+	rootCmd.PersistentFlags().BoolVar(&pinStdin, "pin-stdin", false, "Read the encryption PIN from stdin instead of prompting, one line per PIN asked for")
+	rootCmd.PersistentFlags().StringVar(&pinFile, "pin-file", "", "Read the encryption PIN from this file instead of prompting (or set "+crypto.EnvPIN+")")
+	rootCmd.PersistentFlags().StringVar(&configPath, "config", "", "Config directory, or config file if it ends in .json (default ~/.sunday, or $SUNDAY_CONFIG)")
+	rootCmd.PersistentFlags().StringVar(&profileName, "profile", "", "Named profile to use (default the active profile, or $SUNDAY_PROFILE)")
+	rootCmd.PersistentFlags().StringVar(&accountName, "account", "", "Named account within the profile to use (default the main account, or $SUNDAY_ACCOUNT)")
 
 	// Add version command
 	rootCmd.AddCommand(&cobra.Command{
@@ -0,0 +1,48 @@
+package cli
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/ravi-technologies/sunday-cli/internal/api"
+	"github.com/ravi-technologies/sunday-cli/internal/config"
+	"github.com/spf13/cobra"
+)
+
+// annotationScope names the token scope a command needs.
+const annotationScope = "sunday.scope"
+
+// Token scopes that commands need. A session logged in with --scope can
+// only run the commands its scopes cover.
+const (
+	scopeReadInbox      = "read:inbox"
+	scopeReadPasswords  = "read:passwords"
+	scopeWritePasswords = "write:passwords"
+)
+
+// requireScope marks cmds as needing scope.
+func requireScope(scope string, cmds ...*cobra.Command) {
+	for _, cmd := range cmds {
+		if cmd.Annotations == nil {
+			cmd.Annotations = map[string]string{}
+		}
+		cmd.Annotations[annotationScope] = scope
+	}
+}
+
+// checkScope fails fast when cmd needs a scope the saved session wasn't
+// granted, rather than letting the server reject the request. Sessions
+// without recorded scopes have full access, and API keys are left to the
+// server.
+func checkScope(cmd *cobra.Command) error {
+	scope := cmd.Annotations[annotationScope]
+	if scope == "" || api.APIKeyFromEnv() != "" {
+		return nil
+	}
+	cfg, err := config.Load()
+	if err != nil || len(cfg.Scopes) == 0 || slices.Contains(cfg.Scopes, scope) {
+		return nil
+	}
+	return fmt.Errorf("%q needs the %s scope, which this session wasn't granted; run `sunday auth login --scope %s` to get it",
+		cmd.CommandPath(), scope, scope)
+}
@@ -0,0 +1,42 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ravi-technologies/sunday-cli/internal/api"
+	"github.com/ravi-technologies/sunday-cli/internal/config"
+)
+
+// TestCheckScope verifies that a scoped session can only run commands its
+// scopes cover, and that full-access sessions and API keys are unaffected.
+func TestCheckScope(t *testing.T) {
+	_, cleanup := withTempHome(t)
+	defer cleanup()
+
+	if err := config.Save(&config.Config{AccessToken: "access", RefreshToken: "refresh"}); err != nil {
+		t.Fatalf("config.Save() error = %v", err)
+	}
+	if err := checkScope(pwListCmd); err != nil {
+		t.Errorf("checkScope() with full access error = %v", err)
+	}
+
+	if err := config.Save(&config.Config{AccessToken: "access", RefreshToken: "refresh", Scopes: []string{scopeReadInbox}}); err != nil {
+		t.Fatalf("config.Save() error = %v", err)
+	}
+	if err := checkScope(inboxListCmd); err != nil {
+		t.Errorf("checkScope(inbox list) with read:inbox error = %v", err)
+	}
+	if err := checkScope(statusCmd); err != nil {
+		t.Errorf("checkScope(auth status) error = %v, want nil for a command needing no scope", err)
+	}
+	err := checkScope(pwDeleteCmd)
+	if err == nil || !strings.Contains(err.Error(), scopeWritePasswords) {
+		t.Errorf("checkScope(vault delete) error = %v, want missing %s", err, scopeWritePasswords)
+	}
+
+	t.Setenv(api.EnvAPIKey, "sk_test")
+	if err := checkScope(pwDeleteCmd); err != nil {
+		t.Errorf("checkScope() with an API key error = %v", err)
+	}
+}
@@ -0,0 +1,126 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/ravi-technologies/sunday-cli/internal/api"
+	"github.com/ravi-technologies/sunday-cli/internal/config"
+	"github.com/ravi-technologies/sunday-cli/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var sessionsCmd = &cobra.Command{
+	Use:   "sessions",
+	Short: "List and revoke CLI sessions",
+	Long: `List the machines logged in to your account with the CLI, and revoke
+sessions you no longer use. A revoked machine must log in again.`,
+}
+
+var sessionsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List active CLI sessions",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := api.NewClient(nil)
+		if err != nil {
+			return err
+		}
+		if !client.IsAuthenticated() {
+			return errNotAuthenticated
+		}
+
+		sessions, err := client.ListSessionsContext(cmd.Context())
+		if err != nil {
+			return err
+		}
+
+		if jsonOutput {
+			return output.Current.Print(sessions)
+		}
+
+		if len(sessions) == 0 {
+			output.Current.PrintMessage("No sessions found")
+			return nil
+		}
+
+		headers := []string{"", "ID", "DEVICE", "IDENTITY", "IP ADDRESS", "LAST USED"}
+		table := make([][]string, len(sessions))
+		for i, s := range sessions {
+			marker := ""
+			if s.Current {
+				marker = "*"
+			}
+			lastUsed := ""
+			if !s.LastUsedDt.IsZero() {
+				lastUsed = s.LastUsedDt.Local().Format("Jan 02 15:04")
+			}
+			table[i] = []string{marker, s.ID, truncate(s.DeviceName, 25), truncate(s.Identity, 20), s.IPAddress, lastUsed}
+		}
+		output.Current.PrintTable(headers, table)
+		return nil
+	},
+}
+
+var sessionsRevokeCmd = &cobra.Command{
+	Use:   "revoke <id>",
+	Short: "Revoke a CLI session",
+	Long: `Revoke a CLI session, so the machine holding it must log in again.
+
+Revoking the current session also clears the credentials stored here, like
+auth logout.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := api.NewClient(nil)
+		if err != nil {
+			return err
+		}
+		if !client.IsAuthenticated() {
+			return errNotAuthenticated
+		}
+
+		sessions, err := client.ListSessionsContext(cmd.Context())
+		if err != nil {
+			return err
+		}
+		var target *api.Session
+		for i := range sessions {
+			if sessions[i].ID == args[0] {
+				target = &sessions[i]
+			}
+		}
+		if target == nil {
+			return fmt.Errorf("no session with ID %q (see `sunday auth sessions list`)", args[0])
+		}
+
+		if err := client.RevokeSessionContext(cmd.Context(), target.ID); err != nil {
+			return err
+		}
+		if target.Current {
+			session, loadErr := config.Load()
+			if err := config.Clear(); err != nil {
+				return fmt.Errorf("session revoked, but failed to clear credentials: %w", err)
+			}
+			if loadErr == nil {
+				discardPrivateKey(session.PrivateKey)
+				runHook(cmd.ErrOrStderr(), hookPostLogout, session)
+			}
+		}
+
+		if jsonOutput {
+			return output.Current.Print(map[string]interface{}{"status": "revoked", "id": target.ID, "current": target.Current})
+		}
+
+		if target.Current {
+			fmt.Printf("Revoked session %s (this machine); you are now logged out\n", target.ID)
+		} else {
+			fmt.Printf("Revoked session %s\n", target.ID)
+		}
+		return nil
+	},
+}
+
+func init() {
+	sessionsCmd.AddCommand(sessionsListCmd)
+	sessionsCmd.AddCommand(sessionsRevokeCmd)
+	authCmd.AddCommand(sessionsCmd)
+}
@@ -0,0 +1,29 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/ravi-technologies/sunday-cli/internal/biometric"
+	"github.com/ravi-technologies/sunday-cli/internal/config"
+)
+
+// biometricAuthenticate shows the Touch ID prompt. Tests replace it.
+var biometricAuthenticate = biometric.Authenticate
+
+// touchIDVerified records a successful Touch ID prompt so a command that
+// hits several gated operations only prompts once.
+var touchIDVerified bool
+
+// requireTouchID prompts for Touch ID if cfg gates op behind it. reason is
+// shown in the system prompt and completes the sentence "Sunday is trying
+// to ...". When Touch ID is required but unavailable the operation fails.
+func requireTouchID(cfg *config.Config, op, reason string) error {
+	if touchIDVerified || !cfg.Security.RequiresTouchID(op) {
+		return nil
+	}
+	if err := biometricAuthenticate(reason); err != nil {
+		return fmt.Errorf("Touch ID is required to %s: %w", reason, err)
+	}
+	touchIDVerified = true
+	return nil
+}
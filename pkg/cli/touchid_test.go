@@ -0,0 +1,80 @@
+package cli
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ravi-technologies/sunday-cli/internal/biometric"
+	"github.com/ravi-technologies/sunday-cli/internal/config"
+)
+
+// withFakeTouchID replaces the Touch ID prompt with fn for the duration of
+// the test and returns a pointer to the prompt count.
+func withFakeTouchID(t *testing.T, fn func(string) error) *int {
+	t.Helper()
+	calls := 0
+	origAuth, origVerified := biometricAuthenticate, touchIDVerified
+	biometricAuthenticate = func(reason string) error {
+		calls++
+		return fn(reason)
+	}
+	touchIDVerified = false
+	t.Cleanup(func() {
+		biometricAuthenticate, touchIDVerified = origAuth, origVerified
+	})
+	return &calls
+}
+
+// TestRequireTouchID_NotConfigured verifies that ungated operations never
+// prompt.
+func TestRequireTouchID_NotConfigured(t *testing.T) {
+	calls := withFakeTouchID(t, func(string) error { return nil })
+
+	if err := requireTouchID(&config.Config{}, config.TouchIDRevealPassword, "reveal"); err != nil {
+		t.Fatalf("requireTouchID() error = %v, want nil", err)
+	}
+	if *calls != 0 {
+		t.Errorf("prompt calls = %d, want 0", *calls)
+	}
+}
+
+// TestRequireTouchID_PromptsOnce verifies that a successful prompt covers
+// every gated operation for the rest of the process.
+func TestRequireTouchID_PromptsOnce(t *testing.T) {
+	calls := withFakeTouchID(t, func(string) error { return nil })
+	cfg := &config.Config{Security: config.SecuritySettings{
+		TouchID: []string{config.TouchIDLoadPrivateKey, config.TouchIDRevealPassword},
+	}}
+
+	for _, op := range []string{config.TouchIDRevealPassword, config.TouchIDLoadPrivateKey} {
+		if err := requireTouchID(cfg, op, "test"); err != nil {
+			t.Fatalf("requireTouchID(%s) error = %v, want nil", op, err)
+		}
+	}
+	if *calls != 1 {
+		t.Errorf("prompt calls = %d, want 1", *calls)
+	}
+}
+
+// TestEnsureKeyPair_TouchIDFailsClosed verifies that the private key is not
+// loaded when Touch ID is required but unavailable.
+func TestEnsureKeyPair_TouchIDFailsClosed(t *testing.T) {
+	tmpDir, cleanup := withTempHome(t)
+	defer cleanup()
+	withFakeTouchID(t, func(string) error { return biometric.ErrUnavailable })
+
+	_, privB64, pubB64 := deriveTestKeyPair(t)
+	saveTestConfig(t, tmpDir, &config.Config{
+		PrivateKey: privB64,
+		PublicKey:  pubB64,
+		Security:   config.SecuritySettings{TouchID: []string{config.TouchIDLoadPrivateKey}},
+	})
+
+	kp, err := ensureKeyPair()
+	if !errors.Is(err, biometric.ErrUnavailable) {
+		t.Fatalf("ensureKeyPair() error = %v, want ErrUnavailable", err)
+	}
+	if kp != nil {
+		t.Error("ensureKeyPair() returned a keypair despite failed Touch ID")
+	}
+}
@@ -0,0 +1,288 @@
+package sunday
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/ravi-technologies/sunday-cli/internal/api"
+	"github.com/ravi-technologies/sunday-cli/internal/config"
+)
+
+// Client is an authenticated Sunday API client. It refreshes its access
+// token as it expires, handing the new tokens to Options.SaveCredentials.
+type Client struct {
+	api *api.Client
+}
+
+// Credentials holds the token pair used to authenticate a Client.
+type Credentials struct {
+	AccessToken  string
+	RefreshToken string
+
+	// ExpiresAt is when AccessToken expires. A zero time counts as
+	// expired, so the first call refreshes the tokens.
+	ExpiresAt time.Time
+
+	// UserEmail is the email of the account the tokens belong to, as
+	// DeviceTokenResponse.User reports it. Optional.
+	UserEmail string
+}
+
+// config returns the CLI config the internal client takes for c.
+func (c *Credentials) config() *config.Config {
+	if c == nil {
+		return nil
+	}
+	return &config.Config{
+		AccessToken:  c.AccessToken,
+		RefreshToken: c.RefreshToken,
+		ExpiresAt:    c.ExpiresAt,
+		UserEmail:    c.UserEmail,
+	}
+}
+
+// credentialsOf returns the Credentials in cfg.
+func credentialsOf(cfg *config.Config) *Credentials {
+	return &Credentials{
+		AccessToken:  cfg.AccessToken,
+		RefreshToken: cfg.RefreshToken,
+		ExpiresAt:    cfg.ExpiresAt,
+		UserEmail:    cfg.UserEmail,
+	}
+}
+
+// APIError is returned when the backend responds with a 4xx or 5xx status.
+// It matches ErrUnauthorized and the other status errors below with
+// errors.Is.
+type APIError struct {
+	StatusCode int
+
+	// Code is the machine-readable error code the server gave, such as
+	// "token_not_valid", if any; Detail is its human-readable message.
+	Code   string
+	Detail string
+
+	// Body is the raw response body.
+	Body string
+
+	// RetryAfter is how long a 429 response asked the client to wait, if
+	// it said.
+	RetryAfter time.Duration
+
+	// RequestID identifies the failed request to the server's support.
+	RequestID string
+
+	err error
+}
+
+// Error implements the error interface.
+func (e *APIError) Error() string {
+	if e.err != nil {
+		return e.err.Error()
+	}
+	return fmt.Sprintf("API error (status %d)", e.StatusCode)
+}
+
+// Unwrap returns the error the APIError was made from.
+func (e *APIError) Unwrap() error {
+	return e.err
+}
+
+// apiError returns err as an *APIError if it holds an internal one, so
+// that callers can errors.As it, and otherwise as it is.
+func apiError(err error) error {
+	var ae *api.APIError
+	if !errors.As(err, &ae) {
+		return err
+	}
+	return &APIError{
+		StatusCode: ae.StatusCode,
+		Code:       ae.Code,
+		Detail:     ae.Detail,
+		Body:       ae.Body,
+		RetryAfter: ae.RetryAfter,
+		RequestID:  ae.RequestID,
+		err:        err,
+	}
+}
+
+// result returns v, converted with conv unless err is set, and err as
+// apiError returns it.
+func result[From, To any](v From, err error, conv func(From) To) (To, error) {
+	if err != nil {
+		var zero To
+		return zero, apiError(err)
+	}
+	return conv(v), nil
+}
+
+// ErrSessionExpired is returned (wrapped) when the refresh token is no
+// longer accepted; the user must authorize again.
+var ErrSessionExpired = api.ErrSessionExpired
+
+// Errors an APIError matches with errors.Is, by status: 401, 404, 429 and
+// any 5xx.
+var (
+	ErrUnauthorized = api.ErrUnauthorized
+	ErrNotFound     = api.ErrNotFound
+	ErrRateLimited  = api.ErrRateLimited
+	ErrServer       = api.ErrServer
+)
+
+// Options configures a Client created with NewClient.
+type Options struct {
+	// BaseURL is the Sunday API root, e.g. "https://api.sunday.app". Required.
+	BaseURL string
+
+	// Credentials holds the access and refresh tokens. May be nil for
+	// unauthenticated calls such as RequestDeviceCode. The Client keeps
+	// its own copy.
+	Credentials *Credentials
+
+	// SaveCredentials is called with the new tokens after the client
+	// refreshes its access token. Nil keeps refreshed tokens in memory
+	// only.
+	SaveCredentials func(*Credentials) error
+
+	// Timeout is how long a request may take, including reading the
+	// response. Zero means 30 seconds. Use WithTimeout to change it for
+	// one call.
+	Timeout time.Duration
+}
+
+// NewClient creates a Client from explicit options. Unlike the CLI it never
+// reads or writes ~/.sunday/config.json.
+func NewClient(opts Options) (*Client, error) {
+	if opts.BaseURL == "" {
+		return nil, errors.New("sunday: BaseURL is required")
+	}
+	var save func(*config.Config) error
+	if opts.SaveCredentials != nil {
+		save = func(cfg *config.Config) error { return opts.SaveCredentials(credentialsOf(cfg)) }
+	}
+	c := api.NewClientForURL(opts.BaseURL, opts.Credentials.config(), save)
+	if opts.Timeout > 0 {
+		c.SetTimeout(opts.Timeout)
+	}
+	return &Client{api: c}, nil
+}
+
+// WithTimeout returns a context whose calls use timeout d instead of the
+// Client's, e.g. a longer one for a large export. Zero means no timeout.
+func WithTimeout(ctx context.Context, d time.Duration) context.Context {
+	return api.WithTimeout(ctx, d)
+}
+
+// IsAuthenticated reports whether the client has tokens to call the API
+// with.
+func (c *Client) IsAuthenticated() bool {
+	return c.api.IsAuthenticated()
+}
+
+// OnRequestDone sets hook to be called after each request the client
+// sends, e.g. to record latency and error rates. A nil hook removes it.
+func (c *Client) OnRequestDone(hook RequestHook) {
+	c.api.OnRequestDone(api.RequestHook(hook))
+}
+
+// RateLimit returns the quota reported with the client's most recent
+// response, and false if no response has carried one.
+func (c *Client) RateLimit() (RateLimit, bool) {
+	rl, ok := c.api.RateLimit()
+	return RateLimit(rl), ok
+}
+
+// StartTokenRefresher refreshes the access token in the background
+// shortly before it expires, for long-running callers such as
+// SubscribeInbox's. It returns at once and stops when ctx is done.
+func (c *Client) StartTokenRefresher(ctx context.Context) {
+	c.api.StartTokenRefresher(ctx)
+}
+
+// Ping checks that the API is reachable.
+func (c *Client) Ping() error {
+	return c.PingContext(context.Background())
+}
+
+// PingContext is Ping with a context that cancels the request.
+func (c *Client) PingContext(ctx context.Context) error {
+	return apiError(c.api.PingContext(ctx))
+}
+
+// GetServerInfo returns the server's version and the optional features it
+// supports. The result is fetched once per client.
+func (c *Client) GetServerInfo() (*ServerInfo, error) {
+	return c.GetServerInfoContext(context.Background())
+}
+
+// GetServerInfoContext is GetServerInfo with a context that cancels the
+// request.
+func (c *Client) GetServerInfoContext(ctx context.Context) (*ServerInfo, error) {
+	info, err := c.api.GetServerInfoContext(ctx)
+	return result(info, err, func(i *api.ServerInfo) *ServerInfo {
+		return &ServerInfo{Version: i.Version, Features: slices.Clone(i.Features)}
+	})
+}
+
+// GetOwner fetches the account owner's profile.
+func (c *Client) GetOwner() (*Owner, error) {
+	return c.GetOwnerContext(context.Background())
+}
+
+// GetOwnerContext is GetOwner with a context that cancels the request.
+func (c *Client) GetOwnerContext(ctx context.Context) (*Owner, error) {
+	owner, err := c.api.GetOwnerContext(ctx)
+	return result(owner, err, ownerOf)
+}
+
+// GetEmail fetches the account's Sunday email address.
+func (c *Client) GetEmail() (*SundayEmail, error) {
+	return c.GetEmailContext(context.Background())
+}
+
+// GetEmailContext is GetEmail with a context that cancels the request.
+func (c *Client) GetEmailContext(ctx context.Context) (*SundayEmail, error) {
+	email, err := c.api.GetEmailContext(ctx)
+	return result(email, err, func(e *api.SundayEmail) *SundayEmail { return (*SundayEmail)(e) })
+}
+
+// GetPhone fetches the account's Sunday phone number.
+func (c *Client) GetPhone() (*SundayPhone, error) {
+	return c.GetPhoneContext(context.Background())
+}
+
+// GetPhoneContext is GetPhone with a context that cancels the request.
+func (c *Client) GetPhoneContext(ctx context.Context) (*SundayPhone, error) {
+	phone, err := c.api.GetPhoneContext(ctx)
+	return result(phone, err, func(p *api.SundayPhone) *SundayPhone { return (*SundayPhone)(p) })
+}
+
+// ListIdentities lists the account's identities.
+func (c *Client) ListIdentities() ([]Identity, error) {
+	return c.ListIdentitiesContext(context.Background())
+}
+
+// ListIdentitiesContext is ListIdentities with a context that cancels the
+// request.
+func (c *Client) ListIdentitiesContext(ctx context.Context) ([]Identity, error) {
+	identities, err := c.api.ListIdentitiesContext(ctx)
+	return result(identities, err, func(ids []api.Identity) []Identity {
+		return convertSlice(ids, func(id api.Identity) Identity { return Identity(id) })
+	})
+}
+
+// GetEncryptionMeta fetches the account's encryption metadata, for
+// UnlockKeyPair.
+func (c *Client) GetEncryptionMeta() (*EncryptionMeta, error) {
+	return c.GetEncryptionMetaContext(context.Background())
+}
+
+// GetEncryptionMetaContext is GetEncryptionMeta with a context that
+// cancels the request.
+func (c *Client) GetEncryptionMetaContext(ctx context.Context) (*EncryptionMeta, error) {
+	meta, err := c.api.GetEncryptionMetaContext(ctx)
+	return result(meta, err, encryptionMetaOf)
+}
@@ -0,0 +1,132 @@
+package sunday
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ravi-technologies/sunday-cli/internal/api"
+)
+
+// TestNewClient_RequiresBaseURL verifies that BaseURL is mandatory.
+func TestNewClient_RequiresBaseURL(t *testing.T) {
+	if _, err := NewClient(Options{}); err == nil {
+		t.Fatal("NewClient() error = nil, want error for missing BaseURL")
+	}
+}
+
+// TestNewClient_ListEmailThreads verifies that an SDK client authenticates
+// with the supplied credentials.
+func TestNewClient_ListEmailThreads(t *testing.T) {
+	var gotAuth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+		json.NewEncoder(w).Encode([]EmailThread{{ThreadID: "t1", Subject: "Hello"}})
+	}))
+	defer server.Close()
+
+	client, err := NewClient(Options{
+		BaseURL: server.URL,
+		Credentials: &Credentials{
+			AccessToken:  "sdk-access",
+			RefreshToken: "sdk-refresh",
+			ExpiresAt:    time.Now().Add(time.Hour),
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	threads, err := client.ListEmailThreads(false)
+	if err != nil {
+		t.Fatalf("ListEmailThreads() error = %v", err)
+	}
+	if len(threads) != 1 || threads[0].ThreadID != "t1" {
+		t.Errorf("threads = %+v, want one thread t1", threads)
+	}
+	if gotAuth != "Bearer sdk-access" {
+		t.Errorf("Authorization = %q, want Bearer sdk-access", gotAuth)
+	}
+}
+
+// TestNewClient_SaveCredentials verifies that refreshed tokens are handed to
+// the SaveCredentials hook.
+func TestNewClient_SaveCredentials(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case api.PathTokenRefresh:
+			json.NewEncoder(w).Encode(api.RefreshResponse{Access: "fresh-access", Refresh: "fresh-refresh"})
+		default:
+			json.NewEncoder(w).Encode(Owner{FirstName: "Ada"})
+		}
+	}))
+	defer server.Close()
+
+	var saved *Credentials
+	client, err := NewClient(Options{
+		BaseURL: server.URL,
+		Credentials: &Credentials{
+			AccessToken:  "stale-access",
+			RefreshToken: "stale-refresh",
+			ExpiresAt:    time.Now().Add(-time.Minute),
+		},
+		SaveCredentials: func(c *Credentials) error {
+			saved = c
+			return nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	if _, err := client.GetOwner(); err != nil {
+		t.Fatalf("GetOwner() error = %v", err)
+	}
+	if saved == nil || saved.AccessToken != "fresh-access" || saved.RefreshToken != "fresh-refresh" {
+		t.Errorf("saved credentials = %+v, want refreshed tokens", saved)
+	}
+}
+
+// TestClient_ConvertsNestedTypes verifies that a response with nested
+// types reaches the caller whole.
+func TestClient_ConvertsNestedTypes(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(EmailThreadDetail{
+			ThreadID: "t1",
+			Messages: []EmailMessage{{ID: 1, Subject: "Hello"}, {ID: 2, Subject: "Re: Hello"}},
+		})
+	}))
+	defer server.Close()
+
+	client, _ := NewClient(Options{BaseURL: server.URL, Credentials: &Credentials{AccessToken: "a", ExpiresAt: time.Now().Add(time.Hour)}})
+	thread, err := client.GetEmailThread("t1")
+	if err != nil {
+		t.Fatalf("GetEmailThread() error = %v", err)
+	}
+	if len(thread.Messages) != 2 || thread.Messages[1].Subject != "Re: Hello" {
+		t.Errorf("thread = %+v, want both messages", thread)
+	}
+}
+
+// TestClient_APIError verifies that an error response is an *APIError
+// that matches the status errors.
+func TestClient_APIError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"detail": "Not found."}`))
+	}))
+	defer server.Close()
+
+	client, _ := NewClient(Options{BaseURL: server.URL, Credentials: &Credentials{AccessToken: "a", ExpiresAt: time.Now().Add(time.Hour)}})
+	_, err := client.GetPassword("missing")
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound || apiErr.Detail != "Not found." {
+		t.Fatalf("GetPassword() error = %#v, want a 404 *APIError", err)
+	}
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("errors.Is(%v, ErrNotFound) = false, want true", err)
+	}
+}
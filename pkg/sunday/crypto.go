@@ -0,0 +1,89 @@
+package sunday
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+
+	"github.com/ravi-technologies/sunday-cli/internal/crypto"
+)
+
+// KeyPair is the Curve25519 keypair used to decrypt E2E-encrypted fields.
+type KeyPair struct {
+	PublicKey  [32]byte
+	PrivateKey [32]byte
+}
+
+// Wipe zeroes the keypair, and the plaintexts DecryptField cached, which
+// may have been decrypted with it. It must not be used afterwards.
+func (kp *KeyPair) Wipe() {
+	kp.internal().Wipe()
+}
+
+// internal returns kp as the internal crypto package's keypair.
+func (kp *KeyPair) internal() *crypto.KeyPair {
+	return (*crypto.KeyPair)(kp)
+}
+
+// ErrWrongPIN is returned by UnlockKeyPair when the PIN does not match the
+// server-stored verifier.
+var ErrWrongPIN = errors.New("sunday: incorrect PIN")
+
+// DeriveKeyPair derives the E2E keypair from a PIN and the raw salt bytes
+// using the same Argon2id parameters as the dashboard.
+func DeriveKeyPair(pin string, salt []byte) (*KeyPair, error) {
+	kp, err := crypto.DeriveKeyPair(pin, salt)
+	return (*KeyPair)(kp), err
+}
+
+// UnlockKeyPair derives the keypair for pin from the user's encryption
+// metadata and checks it against the server-stored verifier.
+func UnlockKeyPair(pin string, meta *EncryptionMeta) (*KeyPair, error) {
+	if meta.PublicKey == "" {
+		return nil, errors.New("sunday: encryption is not set up for this account")
+	}
+
+	salt, err := base64.StdEncoding.DecodeString(meta.Salt)
+	if err != nil {
+		return nil, fmt.Errorf("sunday: decoding salt: %w", err)
+	}
+
+	params, err := crypto.NewKDFParams(meta.KDF.Algorithm, meta.KDF.OpsLimit, meta.KDF.MemLimit)
+	if err != nil {
+		return nil, fmt.Errorf("sunday: %w", err)
+	}
+	kp, err := crypto.DeriveKeyPairWithParams(pin, salt, params)
+	if err != nil {
+		return nil, err
+	}
+	if !crypto.Verify(kp, meta.Verifier) {
+		return nil, ErrWrongPIN
+	}
+	return (*KeyPair)(kp), nil
+}
+
+// IsEncrypted reports whether value is an E2E-encrypted ("e2e::") field.
+func IsEncrypted(value string) bool {
+	return crypto.IsEncrypted(value)
+}
+
+// DecryptField decrypts an "e2e::" field. Values without the prefix are
+// returned unchanged.
+func DecryptField(value string, kp *KeyPair) (string, error) {
+	return crypto.DecryptField(value, kp.internal())
+}
+
+// EncryptField encrypts plaintext to the base64-encoded public key,
+// producing an "e2e::" field value.
+func EncryptField(plaintext, publicKeyB64 string) (string, error) {
+	return crypto.Encrypt(plaintext, publicKeyB64)
+}
+
+// EncryptFieldForRecipients encrypts plaintext so that each of the
+// base64-encoded public keys can decrypt it, as a shared password entry's
+// fields are. DecryptField decrypts it with any recipient's key. Only
+// servers that report FeatureVaultSharing can read the result; don't
+// store it on any other.
+func EncryptFieldForRecipients(plaintext string, publicKeysB64 []string) (string, error) {
+	return crypto.EncryptForRecipients(plaintext, publicKeysB64)
+}
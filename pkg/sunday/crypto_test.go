@@ -0,0 +1,67 @@
+package sunday
+
+import (
+	"encoding/base64"
+	"errors"
+	"testing"
+
+	"github.com/ravi-technologies/sunday-cli/internal/crypto"
+)
+
+// newTestMeta derives a keypair for pin and returns matching encryption metadata.
+func newTestMeta(t *testing.T, pin string) (*EncryptionMeta, *KeyPair) {
+	t.Helper()
+
+	salt := []byte("0123456789abcdef")
+	kp, err := DeriveKeyPair(pin, salt)
+	if err != nil {
+		t.Fatalf("DeriveKeyPair() error = %v", err)
+	}
+	verifier, err := crypto.CreateVerifier(kp.internal())
+	if err != nil {
+		t.Fatalf("CreateVerifier() error = %v", err)
+	}
+
+	return &EncryptionMeta{
+		Salt:      base64.StdEncoding.EncodeToString(salt),
+		Verifier:  verifier,
+		PublicKey: base64.StdEncoding.EncodeToString(kp.PublicKey[:]),
+	}, kp
+}
+
+// TestUnlockKeyPair verifies PIN unlock and a decrypt round trip.
+func TestUnlockKeyPair(t *testing.T) {
+	meta, _ := newTestMeta(t, "123456")
+
+	kp, err := UnlockKeyPair("123456", meta)
+	if err != nil {
+		t.Fatalf("UnlockKeyPair() error = %v", err)
+	}
+
+	enc, err := EncryptField("secret", meta.PublicKey)
+	if err != nil {
+		t.Fatalf("EncryptField() error = %v", err)
+	}
+	if !IsEncrypted(enc) {
+		t.Errorf("IsEncrypted(%q) = false, want true", enc)
+	}
+
+	plain, err := DecryptField(enc, kp)
+	if err != nil {
+		t.Fatalf("DecryptField() error = %v", err)
+	}
+	if plain != "secret" {
+		t.Errorf("DecryptField() = %q, want secret", plain)
+	}
+
+	if _, err := UnlockKeyPair("654321", meta); !errors.Is(err, ErrWrongPIN) {
+		t.Errorf("UnlockKeyPair(wrong pin) error = %v, want ErrWrongPIN", err)
+	}
+}
+
+// TestUnlockKeyPair_NotSetUp verifies the error for accounts without E2E.
+func TestUnlockKeyPair_NotSetUp(t *testing.T) {
+	if _, err := UnlockKeyPair("123456", &EncryptionMeta{}); err == nil {
+		t.Fatal("UnlockKeyPair() error = nil, want error when encryption is not set up")
+	}
+}
@@ -0,0 +1,60 @@
+package sunday
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ravi-technologies/sunday-cli/internal/api"
+	"github.com/ravi-technologies/sunday-cli/internal/auth"
+)
+
+// ErrDeviceCodeExpired is returned by WaitForDeviceToken when the user did
+// not authorize the device before the code expired.
+var ErrDeviceCodeExpired = errors.New("sunday: device code expired")
+
+// RequestDeviceCode starts a device login: show the user the returned
+// VerificationURI and UserCode, then call WaitForDeviceToken.
+func (c *Client) RequestDeviceCode() (*DeviceCodeResponse, error) {
+	return c.RequestDeviceCodeContext(context.Background())
+}
+
+// RequestDeviceCodeContext is RequestDeviceCode with a context that
+// cancels the request.
+func (c *Client) RequestDeviceCodeContext(ctx context.Context) (*DeviceCodeResponse, error) {
+	code, err := c.api.RequestDeviceCodeWithContext(ctx, api.DeviceCodeRequest{})
+	return result(code, err, func(c *api.DeviceCodeResponse) *DeviceCodeResponse { return (*DeviceCodeResponse)(c) })
+}
+
+// PollForToken asks once for the tokens of a device code. Until the user
+// authorizes it the tokens are nil and the error code is
+// "authorization_pending" (or "slow_down" if polled too often). Most
+// callers want WaitForDeviceToken instead.
+func (c *Client) PollForToken(deviceCode string) (*DeviceTokenResponse, string, error) {
+	return c.PollForTokenContext(context.Background(), deviceCode)
+}
+
+// PollForTokenContext is PollForToken with a context that cancels the
+// request.
+func (c *Client) PollForTokenContext(ctx context.Context, deviceCode string) (*DeviceTokenResponse, string, error) {
+	tok, code, err := c.api.PollForTokenContext(ctx, deviceCode)
+	return deviceTokenOf(tok), code, apiError(err)
+}
+
+// WaitForDeviceToken polls until the user authorizes the device code
+// returned by Client.RequestDeviceCode, the code expires, or ctx is done.
+// It polls as the CLI login does, but performs no terminal I/O.
+func WaitForDeviceToken(ctx context.Context, c *Client, code *DeviceCodeResponse) (*DeviceTokenResponse, error) {
+	interval := max(time.Duration(code.Interval)*time.Second, time.Second)
+	expiry := time.Duration(code.ExpiresIn) * time.Second
+
+	tok, err := auth.PollDeviceToken(ctx, c.api, code.DeviceCode, interval, expiry)
+	switch {
+	case errors.Is(err, auth.ErrDeviceCodeExpired), errors.Is(err, auth.ErrAuthorizationTimeout):
+		return nil, ErrDeviceCodeExpired
+	case err != nil:
+		return nil, fmt.Errorf("sunday: %w", err)
+	}
+	return deviceTokenOf(tok), nil
+}
@@ -0,0 +1,88 @@
+package sunday
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// newDeviceServer returns a server whose token endpoint replies with the
+// given error codes in order, then succeeds.
+func newDeviceServer(t *testing.T, codes ...string) *httptest.Server {
+	t.Helper()
+	polls := 0
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if polls < len(codes) {
+			code := codes[polls]
+			polls++
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": code})
+			return
+		}
+		json.NewEncoder(w).Encode(DeviceTokenResponse{Access: "a", Refresh: "r", User: User{Email: "agent@sunday.app"}})
+	}))
+}
+
+// TestWaitForDeviceToken_Success verifies polling through pending responses.
+func TestWaitForDeviceToken_Success(t *testing.T) {
+	server := newDeviceServer(t, "authorization_pending")
+	defer server.Close()
+
+	client, _ := NewClient(Options{BaseURL: server.URL})
+	code := &DeviceCodeResponse{DeviceCode: "dc", ExpiresIn: 30}
+
+	tok, err := WaitForDeviceToken(context.Background(), client, code)
+	if err != nil {
+		t.Fatalf("WaitForDeviceToken() error = %v", err)
+	}
+	if tok.User.Email != "agent@sunday.app" {
+		t.Errorf("User.Email = %q, want agent@sunday.app", tok.User.Email)
+	}
+}
+
+// TestWaitForDeviceToken_Expired verifies the expired_token error.
+func TestWaitForDeviceToken_Expired(t *testing.T) {
+	server := newDeviceServer(t, "expired_token")
+	defer server.Close()
+
+	client, _ := NewClient(Options{BaseURL: server.URL})
+	code := &DeviceCodeResponse{DeviceCode: "dc", ExpiresIn: 30}
+
+	_, err := WaitForDeviceToken(context.Background(), client, code)
+	if !errors.Is(err, ErrDeviceCodeExpired) {
+		t.Errorf("WaitForDeviceToken() error = %v, want ErrDeviceCodeExpired", err)
+	}
+}
+
+// TestWaitForDeviceToken_Cancelled verifies that context cancellation stops polling.
+func TestWaitForDeviceToken_Cancelled(t *testing.T) {
+	server := newDeviceServer(t, "authorization_pending", "authorization_pending", "authorization_pending")
+	defer server.Close()
+
+	client, _ := NewClient(Options{BaseURL: server.URL})
+	code := &DeviceCodeResponse{DeviceCode: "dc", ExpiresIn: 30, Interval: 5}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := WaitForDeviceToken(ctx, client, code)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("WaitForDeviceToken() error = %v, want context.Canceled", err)
+	}
+}
+
+// TestWaitForDeviceToken_Denied verifies that unknown error codes are fatal.
+func TestWaitForDeviceToken_Denied(t *testing.T) {
+	server := newDeviceServer(t, "access_denied")
+	defer server.Close()
+
+	client, _ := NewClient(Options{BaseURL: server.URL})
+	code := &DeviceCodeResponse{DeviceCode: "dc", ExpiresIn: 30}
+
+	if _, err := WaitForDeviceToken(context.Background(), client, code); err == nil {
+		t.Fatal("WaitForDeviceToken() error = nil, want error for access_denied")
+	}
+}
@@ -0,0 +1,30 @@
+// Package sunday is the public Go SDK for the Sunday API.
+//
+// It wraps a curated, stable subset of the CLI's internals so other Go
+// programs can integrate with Sunday without copying code:
+//   - Client: authenticated HTTP client with automatic token refresh
+//   - Types: inbox, message, vault, and identity response structures
+//   - Device flow: RequestDeviceCode plus WaitForDeviceToken polling
+//   - E2E helpers: keypair derivation, PIN unlock, and field decryption
+//
+// Every Client method that calls the API has a ...Context variant taking a
+// context.Context first, for cancellation and per-call deadlines.
+//
+// Compatibility: the exported identifiers of this package follow semantic
+// versioning. They will not change incompatibly within a major version of
+// the module. Everything under internal/ may change at any time, which is
+// why this package declares its own types rather than exporting those.
+//
+// Example usage:
+//
+//	client, err := sunday.NewClient(sunday.Options{
+//	    BaseURL:     "https://api.sunday.app",
+//	    Credentials: &sunday.Credentials{AccessToken: at, RefreshToken: rt},
+//	})
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+//	defer cancel()
+//	threads, err := client.ListEmailThreadsContext(ctx, false)
+package sunday
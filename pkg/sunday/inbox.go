@@ -0,0 +1,175 @@
+package sunday
+
+import (
+	"context"
+	"iter"
+
+	"github.com/ravi-technologies/sunday-cli/internal/api"
+)
+
+// ListEmailThreads lists the account's email threads, only those with
+// unread messages if unreadOnly.
+func (c *Client) ListEmailThreads(unreadOnly bool) ([]EmailThread, error) {
+	return c.ListEmailThreadsContext(context.Background(), unreadOnly)
+}
+
+// ListEmailThreadsContext is ListEmailThreads with a context that cancels
+// the request.
+func (c *Client) ListEmailThreadsContext(ctx context.Context, unreadOnly bool) ([]EmailThread, error) {
+	threads, err := c.api.ListEmailThreadsContext(ctx, unreadOnly)
+	return result(threads, err, func(t []api.EmailThread) []EmailThread { return convertSlice(t, emailThreadOf) })
+}
+
+// ListEmailThreadsPage fetches one page of email threads.
+func (c *Client) ListEmailThreadsPage(ctx context.Context, unreadOnly bool, opts PageOptions) (*Page[EmailThread], error) {
+	page, err := c.api.ListEmailThreadsPage(ctx, unreadOnly, api.PageOptions(opts))
+	return pageOf(page, err, emailThreadOf)
+}
+
+// GetEmailThread fetches an email thread and its messages by ID.
+func (c *Client) GetEmailThread(threadID string) (*EmailThreadDetail, error) {
+	return c.GetEmailThreadContext(context.Background(), threadID)
+}
+
+// GetEmailThreadContext is GetEmailThread with a context that cancels the
+// request.
+func (c *Client) GetEmailThreadContext(ctx context.Context, threadID string) (*EmailThreadDetail, error) {
+	thread, err := c.api.GetEmailThreadContext(ctx, threadID)
+	return result(thread, err, emailThreadDetailOf)
+}
+
+// GetEmailThreads fetches several email threads by ID, in that order.
+func (c *Client) GetEmailThreads(threadIDs []string) ([]*EmailThreadDetail, error) {
+	return c.GetEmailThreadsContext(context.Background(), threadIDs)
+}
+
+// GetEmailThreadsContext is GetEmailThreads with a context that cancels
+// the requests.
+func (c *Client) GetEmailThreadsContext(ctx context.Context, threadIDs []string) ([]*EmailThreadDetail, error) {
+	threads, err := c.api.GetEmailThreadsContext(ctx, threadIDs)
+	return result(threads, err, func(t []*api.EmailThreadDetail) []*EmailThreadDetail { return convertSlice(t, emailThreadDetailOf) })
+}
+
+// ListEmailMessages lists the account's email messages, not grouped by
+// thread, only unread ones if unreadOnly.
+func (c *Client) ListEmailMessages(unreadOnly bool) ([]SundayEmailMessage, error) {
+	return c.ListEmailMessagesContext(context.Background(), unreadOnly)
+}
+
+// ListEmailMessagesContext is ListEmailMessages with a context that
+// cancels the request.
+func (c *Client) ListEmailMessagesContext(ctx context.Context, unreadOnly bool) ([]SundayEmailMessage, error) {
+	messages, err := c.api.ListEmailMessagesContext(ctx, unreadOnly)
+	return result(messages, err, func(m []api.SundayEmailMessage) []SundayEmailMessage { return convertSlice(m, emailMessageOf) })
+}
+
+// ListEmailMessagesPage fetches one page of email messages.
+func (c *Client) ListEmailMessagesPage(ctx context.Context, unreadOnly bool, opts PageOptions) (*Page[SundayEmailMessage], error) {
+	page, err := c.api.ListEmailMessagesPage(ctx, unreadOnly, api.PageOptions(opts))
+	return pageOf(page, err, emailMessageOf)
+}
+
+// GetEmailMessage fetches an email message by ID.
+func (c *Client) GetEmailMessage(messageID string) (*SundayEmailMessage, error) {
+	return c.GetEmailMessageContext(context.Background(), messageID)
+}
+
+// GetEmailMessageContext is GetEmailMessage with a context that cancels
+// the request.
+func (c *Client) GetEmailMessageContext(ctx context.Context, messageID string) (*SundayEmailMessage, error) {
+	message, err := c.api.GetEmailMessageContext(ctx, messageID)
+	return result(message, err, func(m *api.SundayEmailMessage) *SundayEmailMessage { return (*SundayEmailMessage)(m) })
+}
+
+// ListSMSConversations lists the account's SMS conversations, only those
+// with unread messages if unreadOnly.
+func (c *Client) ListSMSConversations(unreadOnly bool) ([]SMSConversation, error) {
+	return c.ListSMSConversationsContext(context.Background(), unreadOnly)
+}
+
+// ListSMSConversationsContext is ListSMSConversations with a context that
+// cancels the request.
+func (c *Client) ListSMSConversationsContext(ctx context.Context, unreadOnly bool) ([]SMSConversation, error) {
+	conversations, err := c.api.ListSMSConversationsContext(ctx, unreadOnly)
+	return result(conversations, err, func(cs []api.SMSConversation) []SMSConversation { return convertSlice(cs, smsConversationOf) })
+}
+
+// ListSMSConversationsPage fetches one page of SMS conversations.
+func (c *Client) ListSMSConversationsPage(ctx context.Context, unreadOnly bool, opts PageOptions) (*Page[SMSConversation], error) {
+	page, err := c.api.ListSMSConversationsPage(ctx, unreadOnly, api.PageOptions(opts))
+	return pageOf(page, err, smsConversationOf)
+}
+
+// GetSMSConversation fetches an SMS conversation and its messages by ID.
+func (c *Client) GetSMSConversation(conversationID string) (*SMSConversationDetail, error) {
+	return c.GetSMSConversationContext(context.Background(), conversationID)
+}
+
+// GetSMSConversationContext is GetSMSConversation with a context that
+// cancels the request.
+func (c *Client) GetSMSConversationContext(ctx context.Context, conversationID string) (*SMSConversationDetail, error) {
+	conversation, err := c.api.GetSMSConversationContext(ctx, conversationID)
+	return result(conversation, err, smsConversationDetailOf)
+}
+
+// GetSMSConversations fetches several SMS conversations by ID, in that
+// order.
+func (c *Client) GetSMSConversations(conversationIDs []string) ([]*SMSConversationDetail, error) {
+	return c.GetSMSConversationsContext(context.Background(), conversationIDs)
+}
+
+// GetSMSConversationsContext is GetSMSConversations with a context that
+// cancels the requests.
+func (c *Client) GetSMSConversationsContext(ctx context.Context, conversationIDs []string) ([]*SMSConversationDetail, error) {
+	conversations, err := c.api.GetSMSConversationsContext(ctx, conversationIDs)
+	return result(conversations, err, func(cs []*api.SMSConversationDetail) []*SMSConversationDetail {
+		return convertSlice(cs, smsConversationDetailOf)
+	})
+}
+
+// ListSMSMessages lists the account's SMS messages, not grouped by
+// conversation, only unread ones if unreadOnly.
+func (c *Client) ListSMSMessages(unreadOnly bool) ([]SundayPhoneMessage, error) {
+	return c.ListSMSMessagesContext(context.Background(), unreadOnly)
+}
+
+// ListSMSMessagesContext is ListSMSMessages with a context that cancels
+// the request.
+func (c *Client) ListSMSMessagesContext(ctx context.Context, unreadOnly bool) ([]SundayPhoneMessage, error) {
+	messages, err := c.api.ListSMSMessagesContext(ctx, unreadOnly)
+	return result(messages, err, func(m []api.SundayPhoneMessage) []SundayPhoneMessage { return convertSlice(m, phoneMessageOf) })
+}
+
+// ListSMSMessagesPage fetches one page of SMS messages.
+func (c *Client) ListSMSMessagesPage(ctx context.Context, unreadOnly bool, opts PageOptions) (*Page[SundayPhoneMessage], error) {
+	page, err := c.api.ListSMSMessagesPage(ctx, unreadOnly, api.PageOptions(opts))
+	return pageOf(page, err, phoneMessageOf)
+}
+
+// GetSMSMessage fetches an SMS message by ID.
+func (c *Client) GetSMSMessage(messageID string) (*SundayPhoneMessage, error) {
+	return c.GetSMSMessageContext(context.Background(), messageID)
+}
+
+// GetSMSMessageContext is GetSMSMessage with a context that cancels the
+// request.
+func (c *Client) GetSMSMessageContext(ctx context.Context, messageID string) (*SundayPhoneMessage, error) {
+	message, err := c.api.GetSMSMessageContext(ctx, messageID)
+	return result(message, err, func(m *api.SundayPhoneMessage) *SundayPhoneMessage { return (*SundayPhoneMessage)(m) })
+}
+
+// SubscribeInbox yields each email and SMS message as it arrives, until
+// ctx is done. A dropped stream is reopened, resuming where it left off;
+// only a failure to open it, or an error response, is yielded, after
+// which the sequence ends. Long-running callers should also call
+// StartTokenRefresher. It needs a server that supports
+// FeatureInboxEvents.
+func (c *Client) SubscribeInbox(ctx context.Context) iter.Seq2[InboxMessage, error] {
+	return func(yield func(InboxMessage, error) bool) {
+		for msg, err := range c.api.SubscribeInbox(ctx) {
+			if !yield(inboxMessageOf(msg), apiError(err)) {
+				return
+			}
+		}
+	}
+}
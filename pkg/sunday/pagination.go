@@ -0,0 +1,73 @@
+package sunday
+
+import (
+	"context"
+
+	"github.com/ravi-technologies/sunday-cli/internal/api"
+)
+
+// PageOptions selects a page of a list; see the Client's ...Page methods.
+type PageOptions struct {
+	// Limit is the page size. Zero means the server's default.
+	Limit int
+
+	// Page is the page number, counting from 1. Zero means the first.
+	Page int
+
+	// Cursor continues from a previous page's Next and takes precedence
+	// over Page.
+	Cursor string
+}
+
+// Page is one page of a list.
+type Page[T any] struct {
+	Items []T
+
+	// Count is the total number of items across all pages, or -1 if the
+	// server didn't say.
+	Count int
+
+	// Next is the cursor for the following page, or "" on the last one.
+	Next string
+}
+
+// Paginator walks a list page by page, following each page's Next cursor.
+type Paginator[T any] struct {
+	p *api.Paginator[T]
+}
+
+// NewPaginator returns a Paginator over one of the Client's ...Page
+// methods, starting at the page opts selects:
+//
+//	p := sunday.NewPaginator(client.ListPasswordsPage, sunday.PageOptions{Limit: 50})
+//	entries, err := p.All(ctx)
+func NewPaginator[T any](fetch func(context.Context, PageOptions) (*Page[T], error), opts PageOptions) *Paginator[T] {
+	return &Paginator[T]{p: api.NewPaginator(func(ctx context.Context, opts api.PageOptions) (*api.Page[T], error) {
+		page, err := fetch(ctx, PageOptions(opts))
+		if err != nil || page == nil {
+			return nil, err
+		}
+		return (*api.Page[T])(page), nil
+	}, api.PageOptions(opts))}
+}
+
+// Next fetches the next page. It returns nil once the last page has been
+// returned.
+func (p *Paginator[T]) Next(ctx context.Context) (*Page[T], error) {
+	page, err := p.p.Next(ctx)
+	return (*Page[T])(page), err
+}
+
+// All fetches every remaining page and returns their items together.
+func (p *Paginator[T]) All(ctx context.Context) ([]T, error) {
+	return p.p.All(ctx)
+}
+
+// pageOf converts a page from the internal client, and its error, with
+// conv.
+func pageOf[From, To any](page *api.Page[From], err error, conv func(From) To) (*Page[To], error) {
+	if err != nil {
+		return nil, apiError(err)
+	}
+	return &Page[To]{Items: convertSlice(page.Items, conv), Count: page.Count, Next: page.Next}, nil
+}
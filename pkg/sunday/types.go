@@ -0,0 +1,425 @@
+package sunday
+
+import (
+	"slices"
+	"time"
+
+	"github.com/ravi-technologies/sunday-cli/internal/api"
+)
+
+// The types below mirror the API's JSON, and are declared here rather than
+// borrowed from the CLI's internals so that they only change when this
+// package's version says so. Each is converted from its internal
+// counterpart where the Client returns it.
+
+// DeviceCodeResponse starts a device login; see Client.RequestDeviceCode.
+type DeviceCodeResponse struct {
+	DeviceCode              string `json:"device_code"`
+	UserCode                string `json:"user_code"`
+	VerificationURI         string `json:"verification_uri"`
+	VerificationURIComplete string `json:"verification_uri_complete,omitempty"`
+	ExpiresIn               int    `json:"expires_in"`
+	Interval                int    `json:"interval"`
+}
+
+// DeviceTokenResponse holds the tokens of an authorized device login.
+type DeviceTokenResponse struct {
+	Access  string `json:"access"`
+	Refresh string `json:"refresh"`
+	User    User   `json:"user"`
+
+	// SigningSecret is a per-device HMAC key. It is only issued when the
+	// backend has request signing enabled.
+	SigningSecret string `json:"signing_secret,omitempty"`
+
+	// Scope lists the space-separated scopes granted, when the token is
+	// limited. It is empty for a full-access token.
+	Scope string `json:"scope,omitempty"`
+}
+
+// User is the account a device login authorized.
+type User struct {
+	ID        int    `json:"id"`
+	Email     string `json:"email"`
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+}
+
+// EmailThread summarizes an email thread in a list.
+type EmailThread struct {
+	ThreadID        string    `json:"thread_id"`
+	Subject         string    `json:"subject"`
+	Preview         string    `json:"preview"`
+	FromEmail       string    `json:"from_email"`
+	SundayEmail     string    `json:"sunday_email"`
+	MessageCount    int       `json:"message_count"`
+	UnreadCount     int       `json:"unread_count"`
+	LatestMessageDt time.Time `json:"latest_message_dt"`
+	OldestMessageDt time.Time `json:"oldest_message_dt"`
+}
+
+// EmailThreadDetail is an email thread with its messages.
+type EmailThreadDetail struct {
+	ThreadID     string         `json:"thread_id"`
+	Subject      string         `json:"subject"`
+	MessageCount int            `json:"message_count"`
+	Messages     []EmailMessage `json:"messages"`
+}
+
+// EmailMessage is a message within an EmailThreadDetail.
+type EmailMessage struct {
+	ID          int       `json:"id"`
+	FromEmail   string    `json:"from_email"`
+	ToEmail     string    `json:"to_email"`
+	CC          string    `json:"cc"`
+	Subject     string    `json:"subject"`
+	TextContent string    `json:"text_content"`
+	HTMLContent string    `json:"html_content"`
+	Direction   string    `json:"direction"`
+	IsRead      bool      `json:"is_read"`
+	CreatedDt   time.Time `json:"created_dt"`
+}
+
+// SMSConversation summarizes an SMS conversation in a list.
+type SMSConversation struct {
+	ConversationID    string    `json:"conversation_id"`
+	FromNumber        string    `json:"from_number"`
+	SundayPhone       string    `json:"sunday_phone"`
+	SundayPhoneNumber string    `json:"sunday_phone_number"`
+	Preview           string    `json:"preview"`
+	MessageCount      int       `json:"message_count"`
+	UnreadCount       int       `json:"unread_count"`
+	LatestMessageDt   time.Time `json:"latest_message_dt"`
+}
+
+// SMSConversationDetail is an SMS conversation with its messages.
+type SMSConversationDetail struct {
+	ConversationID string       `json:"conversation_id"`
+	FromNumber     string       `json:"from_number"`
+	SundayPhone    string       `json:"sunday_phone"`
+	MessageCount   int          `json:"message_count"`
+	Messages       []SMSMessage `json:"messages"`
+}
+
+// SMSMessage is a message within an SMSConversationDetail.
+type SMSMessage struct {
+	ID        int       `json:"id"`
+	Body      string    `json:"body"`
+	Direction string    `json:"direction"`
+	IsRead    bool      `json:"is_read"`
+	CreatedDt time.Time `json:"created_dt"`
+}
+
+// SundayEmailMessage is an email message on its own, not grouped by thread.
+type SundayEmailMessage struct {
+	ID          int       `json:"id"`
+	URL         string    `json:"url"`
+	FromEmail   string    `json:"from_email"`
+	ToEmail     string    `json:"to_email"`
+	CC          string    `json:"cc"`
+	Subject     string    `json:"subject"`
+	TextContent string    `json:"text_content"`
+	HTMLContent string    `json:"html_content"`
+	Direction   string    `json:"direction"`
+	IsRead      bool      `json:"is_read"`
+	MessageID   string    `json:"message_id"`
+	ThreadID    string    `json:"thread_id"`
+	CreatedDt   time.Time `json:"created_dt"`
+}
+
+// SundayPhoneMessage is an SMS message on its own, not grouped by
+// conversation.
+type SundayPhoneMessage struct {
+	ID          int       `json:"id"`
+	URL         string    `json:"url"`
+	FromNumber  string    `json:"from_number"`
+	ToNumber    string    `json:"to_number"`
+	Body        string    `json:"body"`
+	MessageSID  string    `json:"message_sid"`
+	SundayPhone string    `json:"sunday_phone"`
+	Direction   string    `json:"direction"`
+	IsRead      bool      `json:"is_read"`
+	CreatedDt   time.Time `json:"created_dt"`
+}
+
+// Types of InboxMessage.
+const (
+	InboxEventEmail = "email"
+	InboxEventSMS   = "sms"
+)
+
+// InboxMessage is a message yielded by SubscribeInbox: Email for an
+// InboxEventEmail, SMS for an InboxEventSMS.
+type InboxMessage struct {
+	Type  string              `json:"type"`
+	Email *SundayEmailMessage `json:"email,omitempty"`
+	SMS   *SundayPhoneMessage `json:"sms,omitempty"`
+}
+
+// Owner is the account owner's profile.
+type Owner struct {
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+
+	// Email and Identity are only returned by servers that report the
+	// session's account and bound identity.
+	Email    string    `json:"email,omitempty"`
+	Identity *Identity `json:"identity,omitempty"`
+}
+
+// Identity is one of the account's identities, each with its own email
+// address and phone number.
+type Identity struct {
+	UUID        string `json:"uuid"`
+	Name        string `json:"name"`
+	SundayEmail string `json:"sunday_email"`
+	SundayPhone string `json:"sunday_phone"`
+	CreatedDt   string `json:"created_dt"`
+	UpdatedDt   string `json:"updated_dt"`
+}
+
+// SundayEmail is the account's Sunday email address.
+type SundayEmail struct {
+	ID        int       `json:"id"`
+	Email     string    `json:"email"`
+	CreatedDt time.Time `json:"created_dt"`
+}
+
+// SundayPhone is the account's Sunday phone number.
+type SundayPhone struct {
+	ID          int       `json:"id"`
+	PhoneNumber string    `json:"phone_number"`
+	Provider    string    `json:"provider"`
+	CreatedDt   time.Time `json:"created_dt"`
+}
+
+// EncryptionMeta is the account's encryption metadata, for UnlockKeyPair.
+type EncryptionMeta struct {
+	ID               int    `json:"id"`
+	Salt             string `json:"salt"`
+	Verifier         string `json:"verifier"`
+	PublicKey        string `json:"public_key"`
+	ManagedMasterKey string `json:"managed_master_key"`
+
+	// PendingSalt, PendingVerifier and PendingPublicKey describe the new
+	// key of a PIN change or key rotation that is under way. Empty when
+	// none is.
+	PendingSalt      string `json:"pending_salt,omitempty"`
+	PendingVerifier  string `json:"pending_verifier,omitempty"`
+	PendingPublicKey string `json:"pending_public_key,omitempty"`
+
+	// KDF holds the Argon2id parameters used for PIN derivation. It is
+	// zero when the server doesn't advertise them.
+	KDF KDFMeta `json:"kdf,omitzero"`
+	// PINPolicy says what a PIN chosen for the account may be. It is zero
+	// when the server doesn't advertise one, meaning a 6-digit PIN.
+	PINPolicy PINPolicyMeta `json:"pin_policy,omitzero"`
+}
+
+// KDFMeta is the key derivation function a PIN is stretched with.
+type KDFMeta struct {
+	Algorithm string `json:"algorithm"`
+	OpsLimit  uint64 `json:"opslimit"`
+	MemLimit  uint64 `json:"memlimit"`
+}
+
+// PINPolicyMeta is the server's policy for PINs.
+type PINPolicyMeta struct {
+	Kind      string `json:"kind"`
+	MinLength int    `json:"min_length,omitempty"`
+}
+
+// RequestHook is told about each request a Client sends; see
+// Client.OnRequestDone.
+type RequestHook func(method, path string, status int, d time.Duration)
+
+// ServerInfo is the server's version and optional features, from
+// GetServerInfo.
+type ServerInfo struct {
+	Version  string   `json:"version"`
+	Features []string `json:"features"`
+}
+
+// Supports reports whether the server supports feature, one of the
+// Feature constants.
+func (i *ServerInfo) Supports(feature string) bool {
+	return slices.Contains(i.Features, feature)
+}
+
+// Optional features a server may support (see ServerInfo.Supports).
+const (
+	FeatureInboxEvents  = "inbox_events"
+	FeaturePagination   = "pagination"
+	FeatureSearch       = "search"
+	FeatureVaultSharing = "vault_sharing"
+)
+
+// PasswordEntry is a password vault entry. Its Username, Password and
+// Notes are E2E-encrypted fields.
+type PasswordEntry struct {
+	UUID      string `json:"uuid"`
+	Identity  int    `json:"identity,omitempty"`
+	Domain    string `json:"domain"`
+	Username  string `json:"username"`
+	Password  string `json:"password"`
+	Notes     string `json:"notes"`
+	CreatedDt string `json:"created_dt"`
+	UpdatedDt string `json:"updated_dt"`
+	// SharedWith lists the emails of the users the entry is shared with,
+	// whose keys its fields are encrypted to as well as the owner's.
+	SharedWith []string `json:"shared_with,omitempty"`
+}
+
+// PasswordGenOpts configures GeneratePassword.
+type PasswordGenOpts struct {
+	Length       int
+	NoUppercase  bool
+	NoLowercase  bool
+	NoDigits     bool
+	NoSpecial    bool
+	ExcludeChars string
+}
+
+// GeneratedPassword is a password from GeneratePassword.
+type GeneratedPassword struct {
+	Password string `json:"password"`
+}
+
+// PasswordShare shares a password entry: the users to share it with, by
+// email, and its fields encrypted to them and the owner with
+// EncryptFieldForRecipients.
+type PasswordShare struct {
+	Recipients []string `json:"recipients"`
+	Username   string   `json:"username"`
+	Password   string   `json:"password"`
+	Notes      string   `json:"notes"`
+}
+
+// RecipientKey is another user's public key, from GetRecipientKey.
+type RecipientKey struct {
+	Email     string `json:"email"`
+	PublicKey string `json:"public_key"`
+}
+
+// RateLimit is the request quota the server last reported; see
+// Client.RateLimit.
+type RateLimit struct {
+	Limit     int
+	Remaining int
+
+	// Reset is when the quota refills. It is zero if the server didn't say.
+	Reset time.Time
+}
+
+// Conversions from the internal types. Structs without nested types
+// convert directly, which only compiles while their fields match; the
+// others are copied field by field.
+
+func deviceTokenOf(t *api.DeviceTokenResponse) *DeviceTokenResponse {
+	if t == nil {
+		return nil
+	}
+	return &DeviceTokenResponse{
+		Access:        t.Access,
+		Refresh:       t.Refresh,
+		User:          User(t.User),
+		SigningSecret: t.SigningSecret,
+		Scope:         t.Scope,
+	}
+}
+
+func emailThreadDetailOf(d *api.EmailThreadDetail) *EmailThreadDetail {
+	if d == nil {
+		return nil
+	}
+	return &EmailThreadDetail{
+		ThreadID:     d.ThreadID,
+		Subject:      d.Subject,
+		MessageCount: d.MessageCount,
+		Messages:     convertSlice(d.Messages, func(m api.EmailMessage) EmailMessage { return EmailMessage(m) }),
+	}
+}
+
+func smsConversationDetailOf(d *api.SMSConversationDetail) *SMSConversationDetail {
+	if d == nil {
+		return nil
+	}
+	return &SMSConversationDetail{
+		ConversationID: d.ConversationID,
+		FromNumber:     d.FromNumber,
+		SundayPhone:    d.SundayPhone,
+		MessageCount:   d.MessageCount,
+		Messages:       convertSlice(d.Messages, func(m api.SMSMessage) SMSMessage { return SMSMessage(m) }),
+	}
+}
+
+func inboxMessageOf(m api.InboxMessage) InboxMessage {
+	return InboxMessage{
+		Type:  m.Type,
+		Email: (*SundayEmailMessage)(m.Email),
+		SMS:   (*SundayPhoneMessage)(m.SMS),
+	}
+}
+
+func ownerOf(o *api.Owner) *Owner {
+	if o == nil {
+		return nil
+	}
+	return &Owner{
+		FirstName: o.FirstName,
+		LastName:  o.LastName,
+		Email:     o.Email,
+		Identity:  (*Identity)(o.Identity),
+	}
+}
+
+func encryptionMetaOf(m *api.EncryptionMeta) *EncryptionMeta {
+	if m == nil {
+		return nil
+	}
+	return &EncryptionMeta{
+		ID:               m.ID,
+		Salt:             m.Salt,
+		Verifier:         m.Verifier,
+		PublicKey:        m.PublicKey,
+		ManagedMasterKey: m.ManagedMasterKey,
+		PendingSalt:      m.PendingSalt,
+		PendingVerifier:  m.PendingVerifier,
+		PendingPublicKey: m.PendingPublicKey,
+		KDF:              KDFMeta(m.KDF),
+		PINPolicy:        PINPolicyMeta(m.PINPolicy),
+	}
+}
+
+// convertSlice converts each element of in with conv. A nil slice stays
+// nil.
+func convertSlice[From, To any](in []From, conv func(From) To) []To {
+	if in == nil {
+		return nil
+	}
+	out := make([]To, len(in))
+	for i, v := range in {
+		out[i] = conv(v)
+	}
+	return out
+}
+
+func emailThreadOf(t api.EmailThread) EmailThread {
+	return EmailThread(t)
+}
+
+func smsConversationOf(c api.SMSConversation) SMSConversation {
+	return SMSConversation(c)
+}
+
+func emailMessageOf(m api.SundayEmailMessage) SundayEmailMessage {
+	return SundayEmailMessage(m)
+}
+
+func phoneMessageOf(m api.SundayPhoneMessage) SundayPhoneMessage {
+	return SundayPhoneMessage(m)
+}
+
+func passwordEntryOf(e api.PasswordEntry) PasswordEntry {
+	return PasswordEntry(e)
+}
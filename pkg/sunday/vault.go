@@ -0,0 +1,113 @@
+package sunday
+
+import (
+	"context"
+
+	"github.com/ravi-technologies/sunday-cli/internal/api"
+)
+
+// ListPasswords lists the account's password entries.
+func (c *Client) ListPasswords() ([]PasswordEntry, error) {
+	return c.ListPasswordsContext(context.Background())
+}
+
+// ListPasswordsContext is ListPasswords with a context that cancels the
+// request.
+func (c *Client) ListPasswordsContext(ctx context.Context) ([]PasswordEntry, error) {
+	entries, err := c.api.ListPasswordsContext(ctx)
+	return result(entries, err, func(e []api.PasswordEntry) []PasswordEntry { return convertSlice(e, passwordEntryOf) })
+}
+
+// ListPasswordsPage fetches one page of password entries.
+func (c *Client) ListPasswordsPage(ctx context.Context, opts PageOptions) (*Page[PasswordEntry], error) {
+	page, err := c.api.ListPasswordsPage(ctx, api.PageOptions(opts))
+	return pageOf(page, err, passwordEntryOf)
+}
+
+// GetPassword fetches a password entry by UUID.
+func (c *Client) GetPassword(uuid string) (*PasswordEntry, error) {
+	return c.GetPasswordContext(context.Background(), uuid)
+}
+
+// GetPasswordContext is GetPassword with a context that cancels the
+// request.
+func (c *Client) GetPasswordContext(ctx context.Context, uuid string) (*PasswordEntry, error) {
+	return passwordEntryResult(c.api.GetPasswordContext(ctx, uuid))
+}
+
+// CreatePassword creates a password entry. Encrypt its secret fields with
+// EncryptField first.
+func (c *Client) CreatePassword(entry PasswordEntry) (*PasswordEntry, error) {
+	return c.CreatePasswordContext(context.Background(), entry)
+}
+
+// CreatePasswordContext is CreatePassword with a context that cancels the
+// request.
+func (c *Client) CreatePasswordContext(ctx context.Context, entry PasswordEntry) (*PasswordEntry, error) {
+	return passwordEntryResult(c.api.CreatePasswordContext(ctx, api.PasswordEntry(entry)))
+}
+
+// UpdatePassword changes the given fields of a password entry by UUID.
+func (c *Client) UpdatePassword(uuid string, fields map[string]interface{}) (*PasswordEntry, error) {
+	return c.UpdatePasswordContext(context.Background(), uuid, fields)
+}
+
+// UpdatePasswordContext is UpdatePassword with a context that cancels the
+// request.
+func (c *Client) UpdatePasswordContext(ctx context.Context, uuid string, fields map[string]interface{}) (*PasswordEntry, error) {
+	return passwordEntryResult(c.api.UpdatePasswordContext(ctx, uuid, fields))
+}
+
+// DeletePassword deletes a password entry by UUID.
+func (c *Client) DeletePassword(uuid string) error {
+	return c.DeletePasswordContext(context.Background(), uuid)
+}
+
+// DeletePasswordContext is DeletePassword with a context that cancels the
+// request.
+func (c *Client) DeletePasswordContext(ctx context.Context, uuid string) error {
+	return apiError(c.api.DeletePasswordContext(ctx, uuid))
+}
+
+// SharePassword shares a password entry by UUID, replacing its fields with
+// ones from EncryptFieldForRecipients. It needs a server that supports
+// FeatureVaultSharing.
+func (c *Client) SharePassword(uuid string, share PasswordShare) (*PasswordEntry, error) {
+	return c.SharePasswordContext(context.Background(), uuid, share)
+}
+
+// SharePasswordContext is SharePassword with a context that cancels the
+// request.
+func (c *Client) SharePasswordContext(ctx context.Context, uuid string, share PasswordShare) (*PasswordEntry, error) {
+	return passwordEntryResult(c.api.SharePasswordContext(ctx, uuid, api.PasswordShare(share)))
+}
+
+// GetRecipientKey fetches the public key of the user with the given email,
+// to share entries with them.
+func (c *Client) GetRecipientKey(email string) (*RecipientKey, error) {
+	return c.GetRecipientKeyContext(context.Background(), email)
+}
+
+// GetRecipientKeyContext is GetRecipientKey with a context that cancels
+// the request.
+func (c *Client) GetRecipientKeyContext(ctx context.Context, email string) (*RecipientKey, error) {
+	key, err := c.api.GetRecipientKeyContext(ctx, email)
+	return result(key, err, func(k *api.RecipientKey) *RecipientKey { return (*RecipientKey)(k) })
+}
+
+// GeneratePassword asks the server to generate a password.
+func (c *Client) GeneratePassword(opts PasswordGenOpts) (*GeneratedPassword, error) {
+	return c.GeneratePasswordContext(context.Background(), opts)
+}
+
+// GeneratePasswordContext is GeneratePassword with a context that cancels
+// the request.
+func (c *Client) GeneratePasswordContext(ctx context.Context, opts PasswordGenOpts) (*GeneratedPassword, error) {
+	generated, err := c.api.GeneratePasswordContext(ctx, api.PasswordGenOpts(opts))
+	return result(generated, err, func(g *api.GeneratedPassword) *GeneratedPassword { return (*GeneratedPassword)(g) })
+}
+
+// passwordEntryResult converts an entry returned by the internal client.
+func passwordEntryResult(entry *api.PasswordEntry, err error) (*PasswordEntry, error) {
+	return result(entry, err, func(e *api.PasswordEntry) *PasswordEntry { return (*PasswordEntry)(e) })
+}
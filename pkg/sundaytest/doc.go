@@ -0,0 +1,19 @@
+// Package sundaytest provides an in-memory fake of the Sunday API for
+// testing programs built on package sunday, or the CLI itself.
+//
+// A Server speaks the same JSON as the real backend for the device flow,
+// token refresh, the inbox, individual messages and the password vault.
+// Its data is whatever the test adds, and E2E-encrypted fields can be made
+// with Encrypt, which seals them to an account key unlocked by PIN:
+//
+//	srv := sundaytest.NewServer(t)
+//	srv.AddEmail(sunday.SundayEmailMessage{
+//	    FromEmail: "noreply@example.com",
+//	    Subject:   srv.Encrypt("Your code is 123456"),
+//	})
+//	client := srv.Client()
+//	threads, err := client.ListEmailThreads(false)
+//
+// Access tokens are checked like the real server checks them: ExpireToken
+// makes the next request fail with 401 so the client has to refresh.
+package sundaytest
@@ -0,0 +1,631 @@
+package sundaytest
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ravi-technologies/sunday-cli/pkg/sunday"
+)
+
+// routes returns the server's handler. Paths match the real API's, which
+// end in a slash.
+func (s *Server) routes() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/meta/{$}", s.serverInfo)
+	mux.HandleFunc("POST /api/auth/device/{$}", s.deviceCode)
+	mux.HandleFunc("POST /api/auth/device/token/{$}", s.deviceToken)
+	mux.HandleFunc("POST /api/auth/token/refresh/{$}", s.refreshToken)
+
+	mux.HandleFunc("GET /api/me/{$}", s.authed(s.owner))
+	mux.HandleFunc("GET /api/encryption/{$}", s.authed(s.encryption))
+	mux.HandleFunc("PATCH /api/encryption/{$}", s.authed(s.updateEncryption))
+	mux.HandleFunc("GET /api/encryption/public-keys/{$}", s.authed(s.publicKey))
+	mux.HandleFunc("GET /api/email-inbox/{$}", s.authed(s.emailThreads))
+	mux.HandleFunc("GET /api/email-inbox/{id}/{$}", s.authed(s.emailThread))
+	mux.HandleFunc("GET /api/sms-inbox/{$}", s.authed(s.smsConversations))
+	mux.HandleFunc("GET /api/sms-inbox/{id}/{$}", s.authed(s.smsConversation))
+	mux.HandleFunc("GET /api/email-messages/{$}", s.authed(s.emailMessages))
+	mux.HandleFunc("GET /api/email-messages/{id}/{$}", s.authed(s.emailMessage))
+	mux.HandleFunc("PATCH /api/email-messages/{id}/{$}", s.authed(s.updateEmailMessage))
+	mux.HandleFunc("GET /api/messages/{$}", s.authed(s.smsMessages))
+	mux.HandleFunc("GET /api/messages/{id}/{$}", s.authed(s.smsMessage))
+	mux.HandleFunc("PATCH /api/messages/{id}/{$}", s.authed(s.updateSMSMessage))
+
+	mux.HandleFunc("GET /api/vault/{$}", s.authed(s.listPasswords))
+	mux.HandleFunc("POST /api/vault/{$}", s.authed(s.createPassword))
+	mux.HandleFunc("GET /api/vault/generate-password/{$}", s.authed(s.generatePassword))
+	mux.HandleFunc("GET /api/vault/{uuid}/{$}", s.authed(s.getPassword))
+	mux.HandleFunc("PATCH /api/vault/{uuid}/{$}", s.authed(s.updatePassword))
+	mux.HandleFunc("DELETE /api/vault/{uuid}/{$}", s.authed(s.deletePassword))
+	mux.HandleFunc("POST /api/vault/{uuid}/share/{$}", s.authed(s.sharePassword))
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		writeError(w, http.StatusNotFound, "Not found.", "not_found")
+	})
+	return mux
+}
+
+// authed rejects requests without the current access token, as the real
+// server does once a token has expired.
+func (s *Server) authed(h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		ok := r.Header.Get("Authorization") == "Bearer "+s.accessToken
+		s.mu.Unlock()
+		if !ok {
+			writeError(w, http.StatusUnauthorized, "Given token not valid for any token type", "token_not_valid")
+			return
+		}
+		h(w, r)
+	}
+}
+
+// serverInfo describes the fake, with the optional features set with
+// SetFeatures.
+func (s *Server) serverInfo(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	features := append([]string{}, s.features...)
+	s.mu.Unlock()
+	writeJSON(w, http.StatusOK, sunday.ServerInfo{Version: "sundaytest", Features: features})
+}
+
+func (s *Server) deviceCode(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	code := fmt.Sprintf("device-code-%d", s.nextID())
+	s.devices[code] = true
+	s.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, sunday.DeviceCodeResponse{
+		DeviceCode:              code,
+		UserCode:                "ABCD-1234",
+		VerificationURI:         s.URL + "/device",
+		VerificationURIComplete: s.URL + "/device?code=ABCD-1234",
+		ExpiresIn:               600,
+		Interval:                1,
+	})
+}
+
+func (s *Server) deviceToken(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		DeviceCode string `json:"device_code"`
+	}
+	json.NewDecoder(r.Body).Decode(&req)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch {
+	case !s.devices[req.DeviceCode]:
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "expired_token"})
+	case !s.approved:
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "authorization_pending"})
+	default:
+		delete(s.devices, req.DeviceCode)
+		writeJSON(w, http.StatusOK, sunday.DeviceTokenResponse{
+			Access:  s.accessToken,
+			Refresh: s.refresh,
+			User:    sunday.User{ID: 1, Email: Email, FirstName: "Test", LastName: "Agent"},
+		})
+	}
+}
+
+func (s *Server) refreshToken(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Refresh string `json:"refresh"`
+	}
+	json.NewDecoder(r.Body).Decode(&req)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if req.Refresh != s.refresh {
+		writeError(w, http.StatusUnauthorized, "Token is invalid or expired", "token_not_valid")
+		return
+	}
+	s.accessToken = s.newAccessToken()
+	writeJSON(w, http.StatusOK, map[string]string{"access": s.accessToken})
+}
+
+func (s *Server) owner(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, sunday.Owner{FirstName: "Test", LastName: "Agent", Email: Email})
+}
+
+func (s *Server) encryption(w http.ResponseWriter, r *http.Request) {
+	s.initKey()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	writeJSON(w, http.StatusOK, s.meta)
+}
+
+// updateEncryption replaces the key record, as a PIN change does when it
+// finishes, or registers the pending key of one that is starting. The
+// pending key is cleared unless given.
+func (s *Server) updateEncryption(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Salt             string `json:"salt"`
+		Verifier         string `json:"verifier"`
+		PublicKey        string `json:"public_key"`
+		PendingSalt      string `json:"pending_salt"`
+		PendingVerifier  string `json:"pending_verifier"`
+		PendingPublicKey string `json:"pending_public_key"`
+	}
+	err := json.NewDecoder(r.Body).Decode(&req)
+	record := req.Salt != "" && req.Verifier != "" && req.PublicKey != ""
+	pending := req.PendingSalt != "" && req.PendingVerifier != "" && req.PendingPublicKey != ""
+	if err != nil || (!record && !pending) {
+		writeError(w, http.StatusBadRequest, "salt, verifier and public_key, or pending_salt, pending_verifier and pending_public_key, are required.", "invalid")
+		return
+	}
+
+	s.initKey()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if record {
+		s.meta.Salt, s.meta.Verifier, s.meta.PublicKey = req.Salt, req.Verifier, req.PublicKey
+	}
+	s.meta.PendingSalt, s.meta.PendingVerifier, s.meta.PendingPublicKey = req.PendingSalt, req.PendingVerifier, req.PendingPublicKey
+	writeJSON(w, http.StatusOK, s.meta)
+}
+
+// publicKey returns the public key of the user added with AddUser whose
+// email is given.
+func (s *Server) publicKey(w http.ResponseWriter, r *http.Request) {
+	email := r.URL.Query().Get("email")
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key, ok := s.users[email]
+	if !ok {
+		writeError(w, http.StatusNotFound, "No user with that email has set up encryption.", "not_found")
+		return
+	}
+	writeJSON(w, http.StatusOK, sunday.RecipientKey{Email: email, PublicKey: key})
+}
+
+// emailThreads lists one summary per thread, most recent first.
+func (s *Server) emailThreads(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var threads []sunday.EmailThread
+	for _, id := range s.threadIDs() {
+		t := s.emailThreadSummary(id)
+		if r.URL.Query().Get("has_unread") == "true" && t.UnreadCount == 0 {
+			continue
+		}
+		threads = append(threads, t)
+	}
+	slices.SortStableFunc(threads, func(a, b sunday.EmailThread) int {
+		return b.LatestMessageDt.Compare(a.LatestMessageDt)
+	})
+	writeJSON(w, http.StatusOK, nonNil(threads))
+}
+
+func (s *Server) emailThread(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := r.PathValue("id")
+	detail := sunday.EmailThreadDetail{ThreadID: id}
+	for _, m := range s.emails {
+		if m.ThreadID != id {
+			continue
+		}
+		if detail.Subject == "" {
+			detail.Subject = m.Subject
+		}
+		detail.Messages = append(detail.Messages, sunday.EmailMessage{
+			ID:          m.ID,
+			FromEmail:   m.FromEmail,
+			ToEmail:     m.ToEmail,
+			CC:          m.CC,
+			Subject:     m.Subject,
+			TextContent: m.TextContent,
+			HTMLContent: m.HTMLContent,
+			Direction:   m.Direction,
+			IsRead:      m.IsRead,
+			CreatedDt:   m.CreatedDt,
+		})
+	}
+	if len(detail.Messages) == 0 {
+		writeError(w, http.StatusNotFound, "Not found.", "not_found")
+		return
+	}
+	detail.MessageCount = len(detail.Messages)
+	writeJSON(w, http.StatusOK, detail)
+}
+
+// threadIDs returns the email thread IDs in the order first seen.
+func (s *Server) threadIDs() []string {
+	var ids []string
+	for _, m := range s.emails {
+		if !slices.Contains(ids, m.ThreadID) {
+			ids = append(ids, m.ThreadID)
+		}
+	}
+	return ids
+}
+
+func (s *Server) emailThreadSummary(id string) sunday.EmailThread {
+	t := sunday.EmailThread{ThreadID: id}
+	for _, m := range s.emails {
+		if m.ThreadID != id {
+			continue
+		}
+		if t.MessageCount == 0 {
+			t.Subject, t.OldestMessageDt = m.Subject, m.CreatedDt
+		}
+		t.MessageCount++
+		if !m.IsRead {
+			t.UnreadCount++
+		}
+		if !m.CreatedDt.Before(t.LatestMessageDt) {
+			t.LatestMessageDt, t.Preview = m.CreatedDt, m.TextContent
+		}
+		if m.Direction == "incoming" {
+			t.FromEmail, t.SundayEmail = m.FromEmail, m.ToEmail
+		}
+	}
+	return t
+}
+
+// smsConversations lists one summary per conversation, most recent first.
+func (s *Server) smsConversations(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	byID := map[string]*sunday.SMSConversation{}
+	var convs []*sunday.SMSConversation
+	for _, m := range s.sms {
+		id, number, sundayNumber := conversation(m)
+		c := byID[id]
+		if c == nil {
+			c = &sunday.SMSConversation{ConversationID: id, FromNumber: number, SundayPhone: m.SundayPhone, SundayPhoneNumber: sundayNumber}
+			byID[id] = c
+			convs = append(convs, c)
+		}
+		c.MessageCount++
+		if !m.IsRead {
+			c.UnreadCount++
+		}
+		if !m.CreatedDt.Before(c.LatestMessageDt) {
+			c.LatestMessageDt, c.Preview = m.CreatedDt, m.Body
+		}
+	}
+
+	list := []sunday.SMSConversation{}
+	for _, c := range convs {
+		if r.URL.Query().Get("has_unread") == "true" && c.UnreadCount == 0 {
+			continue
+		}
+		list = append(list, *c)
+	}
+	slices.SortStableFunc(list, func(a, b sunday.SMSConversation) int {
+		return b.LatestMessageDt.Compare(a.LatestMessageDt)
+	})
+	writeJSON(w, http.StatusOK, list)
+}
+
+func (s *Server) smsConversation(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := r.PathValue("id")
+	detail := sunday.SMSConversationDetail{ConversationID: id}
+	for _, m := range s.sms {
+		if cid, number, _ := conversation(m); cid == id {
+			detail.FromNumber, detail.SundayPhone = number, m.SundayPhone
+			detail.Messages = append(detail.Messages, sunday.SMSMessage{
+				ID:        m.ID,
+				Body:      m.Body,
+				Direction: m.Direction,
+				IsRead:    m.IsRead,
+				CreatedDt: m.CreatedDt,
+			})
+		}
+	}
+	if len(detail.Messages) == 0 {
+		writeError(w, http.StatusNotFound, "Not found.", "not_found")
+		return
+	}
+	detail.MessageCount = len(detail.Messages)
+	writeJSON(w, http.StatusOK, detail)
+}
+
+// conversation returns the conversation an SMS belongs to, "<Sunday phone
+// ID>_<their number>", with the two numbers.
+func conversation(m sunday.SundayPhoneMessage) (id, theirs, ours string) {
+	theirs, ours = m.FromNumber, m.ToNumber
+	if m.Direction == "outgoing" {
+		theirs, ours = ours, theirs
+	}
+	return m.SundayPhone + "_" + theirs, theirs, ours
+}
+
+func (s *Server) emailMessages(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	writeJSON(w, http.StatusOK, newestFirst(s.emails, r, func(m sunday.SundayEmailMessage) (bool, int64) {
+		return m.IsRead, m.CreatedDt.UnixNano()
+	}))
+}
+
+func (s *Server) emailMessage(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id, _ := strconv.Atoi(r.PathValue("id"))
+	if i := slices.IndexFunc(s.emails, func(m sunday.SundayEmailMessage) bool { return m.ID == id }); i >= 0 {
+		writeJSON(w, http.StatusOK, s.emails[i])
+		return
+	}
+	writeError(w, http.StatusNotFound, "Not found.", "not_found")
+}
+
+// updateEmailMessage applies a PATCH to an email, such as its content
+// re-encrypted after a PIN change.
+func (s *Server) updateEmailMessage(w http.ResponseWriter, r *http.Request) {
+	var fields map[string]json.RawMessage
+	if err := json.NewDecoder(r.Body).Decode(&fields); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid JSON.", "parse_error")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id, _ := strconv.Atoi(r.PathValue("id"))
+	i := slices.IndexFunc(s.emails, func(m sunday.SundayEmailMessage) bool { return m.ID == id })
+	if i < 0 {
+		writeError(w, http.StatusNotFound, "Not found.", "not_found")
+		return
+	}
+	m, err := patch(s.emails[i], fields)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error(), "invalid")
+		return
+	}
+	m.ID, m.CreatedDt = s.emails[i].ID, s.emails[i].CreatedDt
+	s.emails[i] = m
+	writeJSON(w, http.StatusOK, m)
+}
+
+func (s *Server) smsMessages(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	writeJSON(w, http.StatusOK, newestFirst(s.sms, r, func(m sunday.SundayPhoneMessage) (bool, int64) {
+		return m.IsRead, m.CreatedDt.UnixNano()
+	}))
+}
+
+func (s *Server) smsMessage(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id, _ := strconv.Atoi(r.PathValue("id"))
+	if i := slices.IndexFunc(s.sms, func(m sunday.SundayPhoneMessage) bool { return m.ID == id }); i >= 0 {
+		writeJSON(w, http.StatusOK, s.sms[i])
+		return
+	}
+	writeError(w, http.StatusNotFound, "Not found.", "not_found")
+}
+
+// updateSMSMessage applies a PATCH to an SMS, like updateEmailMessage.
+func (s *Server) updateSMSMessage(w http.ResponseWriter, r *http.Request) {
+	var fields map[string]json.RawMessage
+	if err := json.NewDecoder(r.Body).Decode(&fields); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid JSON.", "parse_error")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id, _ := strconv.Atoi(r.PathValue("id"))
+	i := slices.IndexFunc(s.sms, func(m sunday.SundayPhoneMessage) bool { return m.ID == id })
+	if i < 0 {
+		writeError(w, http.StatusNotFound, "Not found.", "not_found")
+		return
+	}
+	m, err := patch(s.sms[i], fields)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error(), "invalid")
+		return
+	}
+	m.ID, m.CreatedDt = s.sms[i].ID, s.sms[i].CreatedDt
+	s.sms[i] = m
+	writeJSON(w, http.StatusOK, m)
+}
+
+// newestFirst returns messages sorted newest first, keeping only unread
+// ones if the request asks with is_read=false.
+func newestFirst[T any](messages []T, r *http.Request, key func(T) (read bool, created int64)) []T {
+	unread := r.URL.Query().Get("is_read") == "false"
+	list := []T{}
+	for _, m := range messages {
+		if read, _ := key(m); !unread || !read {
+			list = append(list, m)
+		}
+	}
+	slices.SortStableFunc(list, func(a, b T) int {
+		_, ta := key(a)
+		_, tb := key(b)
+		return cmp.Compare(tb, ta)
+	})
+	return list
+}
+
+func (s *Server) listPasswords(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	writeJSON(w, http.StatusOK, nonNil(s.passwords))
+}
+
+func (s *Server) createPassword(w http.ResponseWriter, r *http.Request) {
+	var e sunday.PasswordEntry
+	if err := json.NewDecoder(r.Body).Decode(&e); err != nil || e.Domain == "" {
+		writeError(w, http.StatusBadRequest, "A domain is required.", "invalid")
+		return
+	}
+	e.UUID, e.CreatedDt, e.UpdatedDt = "", "", ""
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	writeJSON(w, http.StatusCreated, s.addPassword(e))
+}
+
+func (s *Server) getPassword(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if i := s.passwordIndex(r); i >= 0 {
+		writeJSON(w, http.StatusOK, s.passwords[i])
+		return
+	}
+	writeError(w, http.StatusNotFound, "Not found.", "not_found")
+}
+
+func (s *Server) updatePassword(w http.ResponseWriter, r *http.Request) {
+	var fields map[string]json.RawMessage
+	if err := json.NewDecoder(r.Body).Decode(&fields); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid JSON.", "parse_error")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := s.passwordIndex(r)
+	if i < 0 {
+		writeError(w, http.StatusNotFound, "Not found.", "not_found")
+		return
+	}
+	// Keep what the client may not change.
+	e := s.passwords[i]
+	updated, err := patch(e, fields)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error(), "invalid")
+		return
+	}
+	updated.UUID, updated.CreatedDt = e.UUID, e.CreatedDt
+	updated.UpdatedDt = time.Now().UTC().Format(time.RFC3339)
+	s.passwords[i] = updated
+	writeJSON(w, http.StatusOK, updated)
+}
+
+func (s *Server) deletePassword(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := s.passwordIndex(r)
+	if i < 0 {
+		writeError(w, http.StatusNotFound, "Not found.", "not_found")
+		return
+	}
+	s.passwords = slices.Delete(s.passwords, i, i+1)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// sharePassword replaces an entry's fields with ones encrypted to its
+// recipients, who must have been added with AddUser.
+func (s *Server) sharePassword(w http.ResponseWriter, r *http.Request) {
+	var share sunday.PasswordShare
+	if err := json.NewDecoder(r.Body).Decode(&share); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid JSON.", "parse_error")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	i := s.passwordIndex(r)
+	if i < 0 {
+		writeError(w, http.StatusNotFound, "Not found.", "not_found")
+		return
+	}
+	for _, email := range share.Recipients {
+		if _, ok := s.users[email]; !ok {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("Unknown recipient %s.", email), "invalid")
+			return
+		}
+	}
+	e := &s.passwords[i]
+	e.Username, e.Password, e.Notes = share.Username, share.Password, share.Notes
+	e.SharedWith = share.Recipients
+	e.UpdatedDt = time.Now().UTC().Format(time.RFC3339)
+	writeJSON(w, http.StatusOK, *e)
+}
+
+func (s *Server) passwordIndex(r *http.Request) int {
+	uuid := r.PathValue("uuid")
+	return slices.IndexFunc(s.passwords, func(e sunday.PasswordEntry) bool { return e.UUID == uuid })
+}
+
+// generatePassword returns a password of the requested length built from
+// the enabled character classes. It is predictable, which suits tests.
+func (s *Server) generatePassword(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	length := 16
+	if n, err := strconv.Atoi(q.Get("length")); err == nil && n > 0 {
+		length = n
+	}
+	var classes []string
+	for _, c := range []struct{ param, chars string }{
+		{"uppercase", "ABCDEFGHJKLMNPQRSTUVWXYZ"},
+		{"lowercase", "abcdefghijkmnopqrstuvwxyz"},
+		{"digits", "23456789"},
+		{"special", "!@#$%^&*-_"},
+	} {
+		if q.Get(c.param) == "false" {
+			continue
+		}
+		chars := strings.Map(func(r rune) rune {
+			if strings.ContainsRune(q.Get("exclude_chars"), r) {
+				return -1
+			}
+			return r
+		}, c.chars)
+		if chars != "" {
+			classes = append(classes, chars)
+		}
+	}
+	if len(classes) == 0 {
+		writeError(w, http.StatusBadRequest, "At least one character class is required.", "invalid")
+		return
+	}
+
+	var b strings.Builder
+	for i := range length {
+		chars := classes[i%len(classes)]
+		b.WriteByte(chars[(i/len(classes))%len(chars)])
+	}
+	writeJSON(w, http.StatusOK, sunday.GeneratedPassword{Password: b.String()})
+}
+
+// patch applies the fields of a PATCH request over stored, keeping what
+// they don't name.
+func patch[T any](stored T, fields map[string]json.RawMessage) (T, error) {
+	current, _ := json.Marshal(stored)
+	var merged map[string]json.RawMessage
+	json.Unmarshal(current, &merged)
+	for k, v := range fields {
+		merged[k] = v
+	}
+	data, _ := json.Marshal(merged)
+	var updated T
+	err := json.Unmarshal(data, &updated)
+	return updated, err
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, detail, code string) {
+	writeJSON(w, status, map[string]string{"detail": detail, "code": code})
+}
+
+// nonNil returns s, or an empty slice if it is nil, so it encodes as [].
+func nonNil[T any](s []T) []T {
+	if s == nil {
+		return []T{}
+	}
+	return s
+}
@@ -0,0 +1,287 @@
+package sundaytest
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ravi-technologies/sunday-cli/internal/crypto"
+	"github.com/ravi-technologies/sunday-cli/pkg/sunday"
+)
+
+// The fake account.
+const (
+	// Email is the account's login.
+	Email = "agent@example.com"
+
+	// SundayEmail and SundayPhone are the account's inbox address and
+	// number, the default recipients of added messages.
+	SundayEmail = "agent@sunday.example"
+	SundayPhone = "+15550100000"
+
+	// PIN unlocks the account's encryption key (see sunday.UnlockKeyPair).
+	PIN = "123456"
+)
+
+// salt is the account's fixed PIN salt, so the key is the same in every
+// test.
+var salt = []byte("sundaytest-salt!")
+
+// Server is a fake Sunday API. Its methods may be called while clients
+// are using it.
+type Server struct {
+	*httptest.Server
+
+	t testing.TB
+
+	mu          sync.Mutex
+	accessToken string
+	refresh     string
+	tokens      int
+	approved    bool
+	devices     map[string]bool
+	emails      []sunday.SundayEmailMessage
+	sms         []sunday.SundayPhoneMessage
+	passwords   []sunday.PasswordEntry
+	users       map[string]string
+	features    []string
+	lastID      int
+
+	keyOnce sync.Once
+	kp      *sunday.KeyPair
+	meta    sunday.EncryptionMeta
+}
+
+// NewServer starts a fake server, which is closed when the test ends.
+func NewServer(t testing.TB) *Server {
+	t.Helper()
+	s := &Server{t: t, refresh: "refresh-token", devices: map[string]bool{}, users: map[string]string{}}
+	s.accessToken = s.newAccessToken()
+	s.Server = httptest.NewServer(s.routes())
+	t.Cleanup(s.Close)
+	return s
+}
+
+// Credentials returns a valid session for the account.
+func (s *Server) Credentials() *sunday.Credentials {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return &sunday.Credentials{
+		AccessToken:  s.accessToken,
+		RefreshToken: s.refresh,
+		ExpiresAt:    time.Now().Add(time.Hour),
+		UserEmail:    Email,
+	}
+}
+
+// Client returns an SDK client logged in to the account.
+func (s *Server) Client() *sunday.Client {
+	s.t.Helper()
+	c, err := sunday.NewClient(sunday.Options{BaseURL: s.URL, Credentials: s.Credentials()})
+	if err != nil {
+		s.t.Fatalf("sundaytest: %v", err)
+	}
+	return c
+}
+
+// Approve authorizes every device code, issued before or after, so
+// polling for a token succeeds. Until then polls are pending.
+func (s *Server) Approve() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.approved = true
+}
+
+// ExpireToken revokes the current access token, so the next request with
+// it fails with 401 until the client refreshes.
+func (s *Server) ExpireToken() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.accessToken = s.newAccessToken()
+}
+
+// AddEmail adds an email to the inbox and returns it as stored. Zero
+// fields are filled in: the ID, the thread (one per message), the
+// recipient, the direction ("incoming") and the date (now).
+func (s *Server) AddEmail(m sunday.SundayEmailMessage) sunday.SundayEmailMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if m.ID == 0 {
+		m.ID = s.nextID()
+	}
+	if m.ThreadID == "" {
+		m.ThreadID = fmt.Sprintf("thread-%d", m.ID)
+	}
+	if m.Direction == "" {
+		m.Direction = "incoming"
+	}
+	if m.ToEmail == "" && m.Direction == "incoming" {
+		m.ToEmail = SundayEmail
+	}
+	if m.CreatedDt.IsZero() {
+		m.CreatedDt = time.Now()
+	}
+	s.emails = append(s.emails, m)
+	return m
+}
+
+// AddSMS adds an SMS to the inbox and returns it as stored, filling in
+// zero fields like AddEmail.
+func (s *Server) AddSMS(m sunday.SundayPhoneMessage) sunday.SundayPhoneMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if m.ID == 0 {
+		m.ID = s.nextID()
+	}
+	if m.Direction == "" {
+		m.Direction = "incoming"
+	}
+	if m.ToNumber == "" && m.Direction == "incoming" {
+		m.ToNumber = SundayPhone
+	}
+	if m.SundayPhone == "" {
+		m.SundayPhone = "1"
+	}
+	if m.CreatedDt.IsZero() {
+		m.CreatedDt = time.Now()
+	}
+	s.sms = append(s.sms, m)
+	return m
+}
+
+// AddPassword adds an entry to the vault and returns it as stored, with a
+// UUID and timestamps if it had none.
+func (s *Server) AddPassword(e sunday.PasswordEntry) sunday.PasswordEntry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.addPassword(e)
+}
+
+// Passwords returns the vault's entries, for checking what a client saved.
+func (s *Server) Passwords() []sunday.PasswordEntry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]sunday.PasswordEntry(nil), s.passwords...)
+}
+
+// KeyPair returns the account's encryption key, the one PIN unlocks. A
+// PIN change made through the API replaces the server's record of the key
+// but not this.
+func (s *Server) KeyPair() *sunday.KeyPair {
+	s.initKey()
+	return s.kp
+}
+
+// ClearEncryption removes the account's key record, as for an account
+// that never set up encryption on the dashboard.
+func (s *Server) ClearEncryption() {
+	s.initKey()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.meta.Salt, s.meta.Verifier, s.meta.PublicKey = "", "", ""
+}
+
+// AddUser registers another user, with the base64 public key entries
+// shared with them are encrypted to.
+func (s *Server) AddUser(email, publicKey string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.users[email] = publicKey
+}
+
+// SetFeatures makes the server report features, the sunday.Feature*
+// constants, as supported. By default it reports none.
+func (s *Server) SetFeatures(features ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.features = features
+}
+
+// SetPINPolicy makes the server advertise policy for the PINs the account
+// accepts. By default it advertises none, meaning a 6-digit PIN.
+func (s *Server) SetPINPolicy(policy sunday.PINPolicyMeta) {
+	s.initKey()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.meta.PINPolicy = policy
+}
+
+// Encrypt seals plaintext to the account's current key, giving an "e2e::" field
+// as the dashboard would store it.
+func (s *Server) Encrypt(plaintext string) string {
+	s.t.Helper()
+	s.initKey()
+	s.mu.Lock()
+	publicKey := s.meta.PublicKey
+	s.mu.Unlock()
+	field, err := crypto.Encrypt(plaintext, publicKey)
+	if err != nil {
+		s.t.Fatalf("sundaytest: %v", err)
+	}
+	return field
+}
+
+// initKey derives the account's key on first use, since Argon2id takes a
+// moment and most tests never need it.
+func (s *Server) initKey() {
+	s.keyOnce.Do(func() {
+		// Errorf rather than Fatalf: this may run on a handler goroutine.
+		kp, err := crypto.DeriveKeyPair(PIN, salt)
+		if err != nil {
+			s.t.Errorf("sundaytest: %v", err)
+			return
+		}
+		verifier, err := crypto.CreateVerifier(kp)
+		if err != nil {
+			s.t.Errorf("sundaytest: %v", err)
+			return
+		}
+		s.kp = (*sunday.KeyPair)(kp)
+		s.meta = sunday.EncryptionMeta{
+			ID:        1,
+			Salt:      base64.StdEncoding.EncodeToString(salt),
+			Verifier:  verifier,
+			PublicKey: base64.StdEncoding.EncodeToString(kp.PublicKey[:]),
+			// Advertised, as the real server does, so clients take the
+			// negotiated path rather than falling back to the defaults.
+			KDF: sunday.KDFMeta{
+				Algorithm: "argon2id",
+				OpsLimit:  uint64(crypto.DefaultKDFParams.Time),
+				MemLimit:  uint64(crypto.DefaultKDFParams.MemoryKiB) * 1024,
+			},
+		}
+	})
+}
+
+// addPassword stores e. The caller holds s.mu.
+func (s *Server) addPassword(e sunday.PasswordEntry) sunday.PasswordEntry {
+	if e.UUID == "" {
+		e.UUID = fmt.Sprintf("00000000-0000-4000-8000-%012d", s.nextID())
+	}
+	now := time.Now().UTC().Format(time.RFC3339)
+	if e.CreatedDt == "" {
+		e.CreatedDt = now
+	}
+	if e.UpdatedDt == "" {
+		e.UpdatedDt = now
+	}
+	s.passwords = append(s.passwords, e)
+	return e
+}
+
+// nextID returns a new ID, unique across all kinds of data. The caller
+// holds s.mu.
+func (s *Server) nextID() int {
+	s.lastID++
+	return s.lastID
+}
+
+// newAccessToken returns a new, distinct access token. The caller holds
+// s.mu, or has yet to share s.
+func (s *Server) newAccessToken() string {
+	s.tokens++
+	return fmt.Sprintf("access-token-%d", s.tokens)
+}
@@ -0,0 +1,112 @@
+package sundaytest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ravi-technologies/sunday-cli/pkg/sunday"
+)
+
+// TestServer_DeviceFlow verifies that polling is pending until Approve and
+// then returns the account's tokens.
+func TestServer_DeviceFlow(t *testing.T) {
+	srv := NewServer(t)
+	client, _ := sunday.NewClient(sunday.Options{BaseURL: srv.URL})
+
+	code, err := client.RequestDeviceCode()
+	if err != nil {
+		t.Fatalf("RequestDeviceCode() error = %v", err)
+	}
+	if _, errCode, err := client.PollForToken(code.DeviceCode); err != nil || errCode != "authorization_pending" {
+		t.Fatalf("PollForToken() before Approve = %q, %v; want authorization_pending", errCode, err)
+	}
+
+	srv.Approve()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	tokens, err := sunday.WaitForDeviceToken(ctx, client, code)
+	if err != nil {
+		t.Fatalf("WaitForDeviceToken() error = %v", err)
+	}
+	if tokens.Access != srv.Credentials().AccessToken || tokens.User.Email != Email {
+		t.Errorf("WaitForDeviceToken() = %+v, want the account's session", tokens)
+	}
+}
+
+// TestServer_Inbox verifies that added messages are served as threads,
+// conversations and messages, and that encrypted fields decrypt with the
+// key PIN unlocks.
+func TestServer_Inbox(t *testing.T) {
+	srv := NewServer(t)
+	srv.AddEmail(sunday.SundayEmailMessage{FromEmail: "noreply@example.com", Subject: srv.Encrypt("Your code is 123456"), ThreadID: "t1"})
+	srv.AddEmail(sunday.SundayEmailMessage{FromEmail: "noreply@example.com", Subject: "Re: code", ThreadID: "t1", IsRead: true})
+	srv.AddSMS(sunday.SundayPhoneMessage{FromNumber: "+15551234567", Body: "hello"})
+	client := srv.Client()
+
+	threads, err := client.ListEmailThreads(false)
+	if err != nil {
+		t.Fatalf("ListEmailThreads() error = %v", err)
+	}
+	if len(threads) != 1 || threads[0].MessageCount != 2 || threads[0].UnreadCount != 1 {
+		t.Fatalf("ListEmailThreads() = %+v, want one thread of two messages", threads)
+	}
+
+	meta, err := client.GetEncryptionMeta()
+	if err != nil {
+		t.Fatalf("GetEncryptionMeta() error = %v", err)
+	}
+	kp, err := sunday.UnlockKeyPair(PIN, meta)
+	if err != nil {
+		t.Fatalf("UnlockKeyPair() error = %v", err)
+	}
+	if subject, err := sunday.DecryptField(threads[0].Subject, kp); err != nil || subject != "Your code is 123456" {
+		t.Errorf("DecryptField(subject) = %q, %v", subject, err)
+	}
+
+	convs, err := client.ListSMSConversations(true)
+	if err != nil || len(convs) != 1 || convs[0].SundayPhoneNumber != SundayPhone {
+		t.Fatalf("ListSMSConversations() = %+v, %v; want one conversation", convs, err)
+	}
+	conv, err := client.GetSMSConversation(convs[0].ConversationID)
+	if err != nil || len(conv.Messages) != 1 || conv.Messages[0].Body != "hello" {
+		t.Errorf("GetSMSConversation() = %+v, %v", conv, err)
+	}
+	if _, err := client.GetEmailThread("missing"); !errors.Is(err, sunday.ErrNotFound) {
+		t.Errorf("GetEmailThread(missing) error = %v, want ErrNotFound", err)
+	}
+}
+
+// TestServer_Passwords verifies the vault endpoints, and that a client
+// whose token was expired refreshes it and carries on.
+func TestServer_Passwords(t *testing.T) {
+	srv := NewServer(t)
+	client := srv.Client()
+
+	created, err := client.CreatePassword(sunday.PasswordEntry{Domain: "example.com", Username: "agent", Password: "secret"})
+	if err != nil {
+		t.Fatalf("CreatePassword() error = %v", err)
+	}
+
+	srv.ExpireToken()
+	updated, err := client.UpdatePassword(created.UUID, map[string]interface{}{"password": "new-secret"})
+	if err != nil {
+		t.Fatalf("UpdatePassword() after ExpireToken error = %v", err)
+	}
+	if updated.Password != "new-secret" || updated.Username != "agent" {
+		t.Errorf("UpdatePassword() = %+v, want the new password and the old username", updated)
+	}
+
+	generated, err := client.GeneratePassword(sunday.PasswordGenOpts{Length: 24, NoSpecial: true})
+	if err != nil || len(generated.Password) != 24 {
+		t.Errorf("GeneratePassword() = %+v, %v; want 24 characters", generated, err)
+	}
+
+	if err := client.DeletePassword(created.UUID); err != nil {
+		t.Fatalf("DeletePassword() error = %v", err)
+	}
+	if got := srv.Passwords(); len(got) != 0 {
+		t.Errorf("Passwords() after delete = %+v, want none", got)
+	}
+}